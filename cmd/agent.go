@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/klauern/gopher-tower/internal/agent"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/workspace"
+)
+
+// Environment variables configuring the agent
+const (
+	envAgentServer = "GOPHER_TOWER_SERVER"
+	envAgentName   = "GOPHER_TOWER_AGENT_NAME"
+	envAgentLabels = "GOPHER_TOWER_AGENT_LABELS"
+)
+
+// defaultAgentServer is used when neither -server nor GOPHER_TOWER_SERVER
+// is set
+const defaultAgentServer = "http://localhost:8080"
+
+// version is reported by agents when they register
+var version = "dev"
+
+// parseAgentConfig reads the agent settings from the flags of
+// "gopher-tower agent", falling back to the environment
+func parseAgentConfig(args []string, getenv func(string) string) (agent.Config, error) {
+	hostname, _ := os.Hostname()
+	name := getenv(envAgentName)
+	if name == "" {
+		name = hostname
+	}
+	server := getenv(envAgentServer)
+	if server == "" {
+		server = defaultAgentServer
+	}
+
+	cfg := agent.Config{Hostname: hostname, Version: version}
+	var labels string
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	fs.StringVar(&cfg.Server, "server", server, "base URL of the Gopher Tower server")
+	fs.StringVar(&cfg.Token, "token", getenv(envAgentToken), "agent registration token of the server")
+	fs.StringVar(&cfg.Name, "name", name, "name of the agent")
+	fs.StringVar(&labels, "labels", getenv(envAgentLabels), "comma-separated labels offered by the agent")
+	fs.DurationVar(&cfg.PollWait, "poll-wait", 0, "how long each poll for runs waits")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	for _, label := range strings.Split(labels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			cfg.Labels = append(cfg.Labels, label)
+		}
+	}
+	if cfg.Token == "" {
+		return cfg, errors.New("a registration token is required (-token or " + envAgentToken + ")")
+	}
+	if cfg.Name == "" {
+		return cfg, errors.New("an agent name is required (-name or " + envAgentName + ")")
+	}
+	return cfg, nil
+}
+
+// runAgent runs "gopher-tower agent" until it is interrupted
+func runAgent(args []string, getenv func(string) string) error {
+	cfg, err := parseAgentConfig(args, getenv)
+	if err != nil {
+		return err
+	}
+	// Workspace, source cache, cache store and secret store settings are
+	// shared with the server
+	serverCfg, err := loadConfig(getenv)
+	if err != nil {
+		return err
+	}
+
+	// Agents resolve secret references from their own secret store
+	secrets, err := openSecrets(context.Background(), serverCfg.Secrets)
+	if err != nil {
+		return err
+	}
+	if secrets != nil {
+		defer secrets.Close()
+	}
+	plugins, err := newPlugins(secrets)
+	if err != nil {
+		return err
+	}
+	workspaces, err := workspace.NewManager(serverCfg.Workspace)
+	if err != nil {
+		return fmt.Errorf("failed to initialize workspaces: %w", err)
+	}
+	if cfg.Sources, err = source.NewCache(serverCfg.SourceCache); err != nil {
+		return fmt.Errorf("failed to initialize source cache: %w", err)
+	}
+	if cfg.Caches, err = cache.NewStore(serverCfg.CacheRoot, serverCfg.CacheMaxBytes); err != nil {
+		return fmt.Errorf("failed to initialize cache store: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go workspaces.Run(ctx)
+
+	log.Printf("Starting agent %s against %s", cfg.Name, cfg.Server)
+	return agent.New(cfg, plugins, workspaces).Run(ctx)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAgentConfig(t *testing.T) {
+	hostname, _ := os.Hostname()
+
+	tests := []struct {
+		name       string
+		args       []string
+		env        map[string]string
+		wantServer string
+		wantName   string
+		wantLabels []string
+		wantWait   time.Duration
+		wantErr    bool
+	}{
+		{
+			name:       "defaults from the environment",
+			env:        map[string]string{envAgentToken: "s3cret", envAgentLabels: "linux, docker,"},
+			wantServer: defaultAgentServer,
+			wantName:   hostname,
+			wantLabels: []string{"linux", "docker"},
+		},
+		{
+			name: "flags override the environment",
+			args: []string{"-server", "https://tower.example.com", "-token", "flag", "-name", "build-1", "-labels", "gpu", "-poll-wait", "5s"},
+			env: map[string]string{
+				envAgentServer: "http://ignored",
+				envAgentToken:  "s3cret",
+				envAgentName:   "ignored",
+			},
+			wantServer: "https://tower.example.com",
+			wantName:   "build-1",
+			wantLabels: []string{"gpu"},
+			wantWait:   5 * time.Second,
+		},
+		{
+			name:    "missing token",
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-token", "s3cret", "-verbose"},
+			wantErr: true,
+		},
+		{
+			name:    "extra arguments",
+			args:    []string{"-token", "s3cret", "now"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseAgentConfig(tt.args, func(key string) string { return tt.env[key] })
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantServer, cfg.Server)
+			assert.Equal(t, tt.wantName, cfg.Name)
+			assert.Equal(t, tt.wantLabels, cfg.Labels)
+			assert.Equal(t, tt.wantWait, cfg.PollWait)
+			assert.NotEmpty(t, cfg.Token)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/coder/websocket"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+)
+
+// attachMessage is a control message of the attach protocol, sent as a
+// WebSocket text message
+type attachMessage struct {
+	Type     string `json:"type"`
+	Cols     int    `json:"cols,omitempty"`
+	Rows     int    `json:"rows,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+}
+
+// attachCommand returns the jobs attach subcommand
+func attachCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "attach",
+		Usage: "Attach the terminal to a running interactive job",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "id",
+				Usage:    "Job ID to attach to",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:  "run",
+				Usage: "Run number to attach to (default: the latest running run)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			// Get server URL from root command
+			root := cmd.Root()
+			if root == nil {
+				return fmt.Errorf("root command not found")
+			}
+			serverURL := root.String("server")
+			if serverURL == "" {
+				return fmt.Errorf("server URL not provided")
+			}
+
+			target, err := attachURL(serverURL, cmd.String("id"), cmd.Int("run"))
+			if err != nil {
+				return err
+			}
+			return attach(ctx, target, os.Stdin, os.Stdout)
+		},
+	}
+}
+
+// attachURL returns the WebSocket URL of a job's terminal
+func attachURL(serverURL, jobID string, run int64) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("invalid server URL: unsupported scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/jobs/" + url.PathEscape(jobID) + "/attach"
+	if run > 0 {
+		u.RawQuery = url.Values{"run": {strconv.FormatInt(run, 10)}}.Encode()
+	}
+	return u.String(), nil
+}
+
+// attach connects in and out to the terminal at target until the run
+// finishes. When in is a terminal it is put into raw mode and its size is
+// kept in sync. A run that failed is reported as a cli.ExitCoder carrying
+// its exit code.
+func attach(ctx context.Context, target string, in *os.File, out io.Writer) error {
+	conn, resp, err := websocket.Dial(ctx, target, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode != 0 {
+			return fmt.Errorf("server returned error: %s", resp.Status)
+		}
+		return fmt.Errorf("failed to attach: %w", err)
+	}
+	defer conn.CloseNow()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if fd := int(in.Fd()); term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("failed to configure terminal: %w", err)
+		}
+		defer term.Restore(fd, state)
+
+		resize := func() {
+			if cols, rows, err := term.GetSize(fd); err == nil {
+				_ = writeMessage(ctx, conn, attachMessage{Type: "resize", Cols: cols, Rows: rows})
+			}
+		}
+		resize()
+		defer notifyResize(resize)()
+	}
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 && conn.Write(ctx, websocket.MessageBinary, buf[:n]) != nil {
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return fmt.Errorf("connection closed: %w", err)
+		}
+		if typ == websocket.MessageBinary {
+			if _, err := out.Write(data); err != nil {
+				return err
+			}
+			continue
+		}
+		var msg attachMessage
+		if json.Unmarshal(data, &msg) != nil || msg.Type != "exit" {
+			continue
+		}
+		conn.Close(websocket.StatusNormalClosure, "")
+		if msg.ExitCode != nil && *msg.ExitCode != 0 {
+			return cli.Exit("", *msg.ExitCode)
+		}
+		return nil
+	}
+}
+
+func writeMessage(ctx context.Context, conn *websocket.Conn, msg attachMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
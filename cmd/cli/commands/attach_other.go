@@ -0,0 +1,8 @@
+//go:build !unix
+
+package commands
+
+// notifyResize is a no-op: the terminal keeps the size it was attached with
+func notifyResize(func()) func() {
+	return func() {}
+}
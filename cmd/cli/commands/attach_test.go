@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestAttachURL(t *testing.T) {
+	got, err := attachURL("https://tower.example.com/", "job-1", 4)
+	require.NoError(t, err)
+	assert.Equal(t, "wss://tower.example.com/api/jobs/job-1/attach?run=4", got)
+
+	got, err = attachURL("http://localhost:8080", "job-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "ws://localhost:8080/api/jobs/job-1/attach", got)
+
+	_, err = attachURL("ftp://localhost", "job-1", 0)
+	assert.Error(t, err)
+}
+
+// echoTerminal greets the client, echoes one line of input and exits with
+// exitCode
+func echoTerminal(exitCode string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/jobs/job-1/attach" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+		if conn.Write(ctx, websocket.MessageBinary, []byte("name? ")) != nil {
+			return
+		}
+		typ, data, err := conn.Read(ctx)
+		if err != nil || typ != websocket.MessageBinary {
+			return
+		}
+		_ = conn.Write(ctx, websocket.MessageBinary, []byte("hello "+string(data)))
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"type": "exit", "exit_code": `+exitCode+`}`))
+		conn.Close(websocket.StatusNormalClosure, "run finished")
+	}))
+}
+
+func TestAttach(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	run := func(t *testing.T, server *httptest.Server, path string) (string, error) {
+		t.Helper()
+		in, input, err := os.Pipe()
+		require.NoError(t, err)
+		defer in.Close()
+		defer input.Close()
+		_, err = input.WriteString("gopher\n")
+		require.NoError(t, err)
+
+		var out strings.Builder
+		err = attach(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+path, in, &out)
+		return out.String(), err
+	}
+
+	t.Run("successful run", func(t *testing.T) {
+		server := echoTerminal("0")
+		defer server.Close()
+		out, err := run(t, server, "/api/jobs/job-1/attach")
+		require.NoError(t, err)
+		assert.Equal(t, "name? hello gopher\n", out)
+	})
+
+	t.Run("failed run", func(t *testing.T) {
+		server := echoTerminal("3")
+		defer server.Close()
+		_, err := run(t, server, "/api/jobs/job-1/attach")
+		var exitErr cli.ExitCoder
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		server := echoTerminal("0")
+		defer server.Close()
+		_, err := run(t, server, "/api/jobs/job-2/attach")
+		assert.ErrorContains(t, err, "404")
+	})
+}
@@ -31,6 +31,8 @@ type JobResponse struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	OwnerID     string     `json:"owner_id,omitempty"`
+	// Match is where a search found the job; only set in search results
+	Match *SearchMatch `json:"match,omitempty"`
 }
 
 // JobListResponse represents the response from listing jobs
@@ -203,6 +205,9 @@ func JobsCommand() *cli.Command {
 					return nil
 				},
 			},
+			searchCommand(),
+			runCommand(),
+			attachCommand(),
 		},
 	}
 }
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// RunRequest represents a request to run a job
+type RunRequest struct {
+	Inputs json.RawMessage `json:"inputs,omitempty"`
+}
+
+// RunResponse represents a started run
+type RunResponse struct {
+	ID        string `json:"id"`
+	RunNumber int64  `json:"run_number"`
+	Status    string `json:"status"`
+}
+
+// FieldError is a problem with a field of a job or run request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// DryRunResponse describes how a run would be executed
+type DryRunResponse struct {
+	Valid  bool         `json:"valid"`
+	Errors []FieldError `json:"errors"`
+	Plugin string       `json:"plugin"`
+	Plan   *struct {
+		Command []string               `json:"command"`
+		Dir     string                 `json:"dir"`
+		Env     map[string]string      `json:"env"`
+		Details map[string]interface{} `json:"details"`
+	} `json:"plan"`
+	Workspace struct {
+		Path    string `json:"path"`
+		WorkDir string `json:"work_dir"`
+	} `json:"workspace"`
+	AgentLabels      []string `json:"agent_labels"`
+	RequiresApproval bool     `json:"requires_approval"`
+}
+
+// runCommand returns the jobs run subcommand
+func runCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "run",
+		Usage: "Run a job, or show how it would run with --dry-run",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "id",
+				Usage:    "Job ID to run",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "inputs",
+				Usage: "Inputs of the run as a JSON object",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Validate and resolve the run without executing it",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			// Get server URL from root command
+			root := cmd.Root()
+			if root == nil {
+				return fmt.Errorf("root command not found")
+			}
+			serverURL := root.String("server")
+			if serverURL == "" {
+				return fmt.Errorf("server URL not provided")
+			}
+
+			var req RunRequest
+			if inputs := cmd.String("inputs"); inputs != "" {
+				if !json.Valid([]byte(inputs)) {
+					return fmt.Errorf("inputs are not valid JSON")
+				}
+				req.Inputs = json.RawMessage(inputs)
+			}
+			jobURL := strings.TrimSuffix(serverURL, "/") + "/api/jobs/" + url.PathEscape(cmd.String("id"))
+			if cmd.Bool("dry-run") {
+				return dryRun(ctx, jobURL, req, os.Stdout)
+			}
+			return startRun(ctx, jobURL, req, os.Stdout)
+		},
+	}
+}
+
+// postJSON posts v to target and decodes the response into out
+func postJSON(ctx context.Context, target string, v, out interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned error: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// getJSON gets target and decodes the response into out
+func getJSON(ctx context.Context, target string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned error: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// startRun starts a run of the job at jobURL
+func startRun(ctx context.Context, jobURL string, req RunRequest, out io.Writer) error {
+	var run RunResponse
+	if err := postJSON(ctx, jobURL+"/runs", req, &run); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Started run %d (%s)\n", run.RunNumber, run.Status)
+	fmt.Fprintf(out, "ID: %s\n", run.ID)
+	return nil
+}
+
+// dryRun prints how a run of the job at jobURL would be executed. A run
+// that could not be started is reported as a cli.ExitCoder.
+func dryRun(ctx context.Context, jobURL string, req RunRequest, out io.Writer) error {
+	var d DryRunResponse
+	if err := postJSON(ctx, jobURL+"/dry-run", req, &d); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Plugin: %s\n", d.Plugin)
+	if len(d.AgentLabels) > 0 {
+		fmt.Fprintf(out, "Agent labels: %s\n", strings.Join(d.AgentLabels, ", "))
+	}
+	if d.RequiresApproval {
+		fmt.Fprintln(out, "Requires approval: yes")
+	}
+	fmt.Fprintf(out, "Workspace: %s\n", d.Workspace.Path)
+	if d.Plan != nil {
+		if len(d.Plan.Command) > 0 {
+			fmt.Fprintf(out, "Command: %s\n", commandLine(d.Plan.Command))
+		}
+		if d.Plan.Dir != "" {
+			fmt.Fprintf(out, "Directory: %s\n", d.Plan.Dir)
+		}
+		if len(d.Plan.Env) > 0 {
+			fmt.Fprintln(out, "Environment:")
+			for _, k := range slices.Sorted(maps.Keys(d.Plan.Env)) {
+				fmt.Fprintf(out, "  %s=%s\n", k, d.Plan.Env[k])
+			}
+		}
+		if len(d.Plan.Details) > 0 {
+			details, err := json.MarshalIndent(d.Plan.Details, "  ", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to format details: %w", err)
+			}
+			fmt.Fprintf(out, "Details:\n  %s\n", details)
+		}
+	}
+
+	if d.Valid {
+		fmt.Fprintln(out, "Valid: yes")
+		return nil
+	}
+	fmt.Fprintln(out, "Valid: no")
+	for _, e := range d.Errors {
+		fmt.Fprintf(out, "  %s: %s\n", e.Field, e.Message)
+	}
+	return cli.Exit(fmt.Sprintf("dry run found %d problem(s)", len(d.Errors)), 1)
+}
+
+// commandLine renders a command line, quoting arguments a shell would split
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestDryRun(t *testing.T) {
+	var got RunRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/jobs/job-1/dry-run":
+			w.Write([]byte(`{
+				"valid": true,
+				"plugin": "script",
+				"plan": {
+					"command": ["/bin/sh", "-e", "/ws/work/.script-*/script.sh", "two words"],
+					"dir": "/ws/work",
+					"env": {"TMPDIR": "/ws/tmp", "HOME": "/ws/home"},
+					"details": {"body": "echo hi"}
+				},
+				"workspace": {"path": "/ws", "work_dir": "/ws/work"}
+			}`))
+		case "/api/jobs/job-2/dry-run":
+			w.Write([]byte(`{"valid": false, "plugin": "http", "errors": [{"field": "config.url", "message": "is required"}], "workspace": {"path": "/ws"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("valid", func(t *testing.T) {
+		var out strings.Builder
+		err := dryRun(ctx, server.URL+"/api/jobs/job-1", RunRequest{Inputs: json.RawMessage(`{"region":"eu-west-1"}`)}, &out)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"region":"eu-west-1"}`, string(got.Inputs))
+		assert.Contains(t, out.String(), `Command: /bin/sh -e /ws/work/.script-*/script.sh "two words"`)
+		assert.Contains(t, out.String(), "Environment:\n  HOME=/ws/home\n  TMPDIR=/ws/tmp\n")
+		assert.Contains(t, out.String(), `"body": "echo hi"`)
+		assert.Contains(t, out.String(), "Valid: yes")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var out strings.Builder
+		err := dryRun(ctx, server.URL+"/api/jobs/job-2", RunRequest{}, &out)
+		var exitErr cli.ExitCoder
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 1, exitErr.ExitCode())
+		assert.Contains(t, out.String(), "Valid: no\n  config.url: is required\n")
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		err := dryRun(ctx, server.URL+"/api/jobs/job-3", RunRequest{}, &strings.Builder{})
+		assert.ErrorContains(t, err, "404")
+	})
+}
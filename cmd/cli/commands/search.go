@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+)
+
+// SearchMatch is where a search found a job
+type SearchMatch struct {
+	// RunNumber is the run whose output matched; nil when the job's name
+	// or description did
+	RunNumber *int64  `json:"run_number,omitempty"`
+	Snippet   string  `json:"snippet"`
+	Score     float64 `json:"score"`
+}
+
+// searchCommand returns the jobs search subcommand
+func searchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "Search job names, descriptions and run output, best match first",
+		ArgsUsage: "<words>...",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "page",
+				Usage:   "Page number",
+				Value:   1,
+				Aliases: []string{"p"},
+			},
+			&cli.IntFlag{
+				Name:    "page-size",
+				Usage:   "Number of items per page",
+				Value:   10,
+				Aliases: []string{"s"},
+			},
+			&cli.StringFlag{
+				Name:  "status",
+				Usage: "Filter by status (pending, active, complete, failed)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			// Get server URL from root command
+			root := cmd.Root()
+			if root == nil {
+				return fmt.Errorf("root command not found")
+			}
+			serverURL := root.String("server")
+			if serverURL == "" {
+				return fmt.Errorf("server URL not provided")
+			}
+
+			query := strings.TrimSpace(strings.Join(cmd.Args().Slice(), " "))
+			if query == "" {
+				return fmt.Errorf("search words not provided")
+			}
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("page", strconv.FormatInt(cmd.Int("page"), 10))
+			params.Set("page_size", strconv.FormatInt(cmd.Int("page-size"), 10))
+			if status := cmd.String("status"); status != "" {
+				params.Set("status", status)
+			}
+
+			target := strings.TrimSuffix(serverURL, "/") + "/api/jobs?" + params.Encode()
+			return searchJobs(ctx, target, term.IsTerminal(int(os.Stdout.Fd())), os.Stdout)
+		},
+	}
+}
+
+// searchJobs prints the jobs found by the search at target. Matches in
+// snippets are shown in reverse video when highlight is set.
+func searchJobs(ctx context.Context, target string, highlight bool, out io.Writer) error {
+	var listResp JobListResponse
+	if err := getJSON(ctx, target, &listResp); err != nil {
+		return fmt.Errorf("failed to search jobs: %w", err)
+	}
+	if len(listResp.Jobs) == 0 {
+		fmt.Fprintln(out, "No matching jobs")
+		return nil
+	}
+
+	marks := strings.NewReplacer("<mark>", "", "</mark>", "")
+	if highlight {
+		marks = strings.NewReplacer("<mark>", "\x1b[7m", "</mark>", "\x1b[0m")
+	}
+	for _, job := range listResp.Jobs {
+		fmt.Fprintf(out, "ID: %s\n", job.ID)
+		fmt.Fprintf(out, "Name: %s\n", job.Name)
+		fmt.Fprintf(out, "Status: %s\n", job.Status)
+		if job.Match != nil {
+			if job.Match.RunNumber != nil {
+				fmt.Fprintf(out, "Matched: output of run %d\n", *job.Match.RunNumber)
+			} else {
+				fmt.Fprintln(out, "Matched: name or description")
+			}
+			for _, line := range strings.Split(marks.Replace(job.Match.Snippet), "\n") {
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+		}
+		fmt.Fprintln(out, "---")
+	}
+	return nil
+}
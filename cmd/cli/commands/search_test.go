@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestSearchJobs(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/jobs" {
+			http.NotFound(w, r)
+			return
+		}
+		got = r.URL.RawQuery
+		if r.URL.Query().Get("q") == "nothing" {
+			w.Write([]byte(`{"jobs": [], "total_count": 0, "page": 1, "page_size": 10}`))
+			return
+		}
+		w.Write([]byte(`{
+			"jobs": [
+				{"id": "job-1", "name": "backup", "status": "active", "match": {"run_number": 7, "snippet": "dial tcp: <mark>connection</mark> <mark>refused</mark>\nretrying", "score": 2.5}},
+				{"id": "job-2", "name": "connection check", "status": "pending", "match": {"snippet": "<mark>connection</mark> check", "score": 1}}
+			],
+			"total_count": 2, "page": 1, "page_size": 10
+		}`))
+	}))
+	defer server.Close()
+
+	app := &cli.Command{
+		Name: "test",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "server",
+				Value: server.URL,
+			},
+		},
+		Commands: []*cli.Command{JobsCommand()},
+	}
+
+	t.Run("command", func(t *testing.T) {
+		err := app.Run(context.Background(), []string{"test", "jobs", "search", "--status", "active", "connection", "refused"})
+		require.NoError(t, err)
+		assert.Equal(t, "page=1&page_size=10&q=connection+refused&status=active", got)
+	})
+
+	t.Run("missing words", func(t *testing.T) {
+		err := app.Run(context.Background(), []string{"test", "jobs", "search"})
+		assert.Error(t, err)
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		var out strings.Builder
+		err := searchJobs(context.Background(), server.URL+"/api/jobs?q=connection", false, &out)
+		require.NoError(t, err)
+		assert.Equal(t, "ID: job-1\nName: backup\nStatus: active\nMatched: output of run 7\n"+
+			"  dial tcp: connection refused\n  retrying\n---\n"+
+			"ID: job-2\nName: connection check\nStatus: pending\nMatched: name or description\n"+
+			"  connection check\n---\n", out.String())
+	})
+
+	t.Run("highlighted", func(t *testing.T) {
+		var out strings.Builder
+		err := searchJobs(context.Background(), server.URL+"/api/jobs?q=connection", true, &out)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "  dial tcp: \x1b[7mconnection\x1b[0m \x1b[7mrefused\x1b[0m\n")
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		var out strings.Builder
+		err := searchJobs(context.Background(), server.URL+"/api/jobs?q=nothing", false, &out)
+		require.NoError(t, err)
+		assert.Equal(t, "No matching jobs\n", out.String())
+	})
+}
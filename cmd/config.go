@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/keymanager"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/workspace"
+)
+
+// Environment variables configuring the server
+const (
+	envWorkspaceRoot     = "GOPHER_TOWER_WORKSPACE_ROOT"
+	envWorkspacePolicy   = "GOPHER_TOWER_WORKSPACE_POLICY"
+	envWorkspaceKeepLast = "GOPHER_TOWER_WORKSPACE_KEEP_LAST"
+	envWorkspaceMaxBytes = "GOPHER_TOWER_WORKSPACE_MAX_BYTES"
+	envJanitorInterval   = "GOPHER_TOWER_WORKSPACE_JANITOR_INTERVAL"
+	envDefaultLimits     = "GOPHER_TOWER_DEFAULT_LIMITS"
+	envMaxLimits         = "GOPHER_TOWER_MAX_LIMITS"
+	envAgentToken        = "GOPHER_TOWER_AGENT_TOKEN"
+	envLeaseTTL          = "GOPHER_TOWER_LEASE_TTL"
+	envExecutorID        = "GOPHER_TOWER_EXECUTOR_ID"
+	envDeadLetterAlert   = "GOPHER_TOWER_DEAD_LETTER_ALERT_THRESHOLD"
+	envApprovalTTL       = "GOPHER_TOWER_APPROVAL_TTL"
+	envSourceCache       = "GOPHER_TOWER_SOURCE_CACHE"
+	envCacheRoot         = "GOPHER_TOWER_CACHE_ROOT"
+	envCacheMaxBytes     = "GOPHER_TOWER_CACHE_MAX_BYTES"
+	envAttachmentRoot    = "GOPHER_TOWER_ATTACHMENT_ROOT"
+	envAttachmentMax     = "GOPHER_TOWER_ATTACHMENT_MAX_BYTES"
+	envSecretsPath       = "GOPHER_TOWER_SECRETS_PATH"
+	envSecretsPassword   = "GOPHER_TOWER_SECRETS_PASSWORD"
+)
+
+// defaultWorkspaceRoot is used when GOPHER_TOWER_WORKSPACE_ROOT is unset
+const defaultWorkspaceRoot = "workspaces"
+
+// defaultSourceCache is used when GOPHER_TOWER_SOURCE_CACHE is unset
+const defaultSourceCache = "source-cache"
+
+// defaultCacheRoot is used when GOPHER_TOWER_CACHE_ROOT is unset
+const defaultCacheRoot = "caches"
+
+// defaultAttachmentRoot is used when GOPHER_TOWER_ATTACHMENT_ROOT is unset
+const defaultAttachmentRoot = "attachments"
+
+// serverConfig holds the settings read from the environment
+type serverConfig struct {
+	Workspace     workspace.Config
+	DefaultLimits limits.Limits
+	MaxLimits     limits.Limits
+	// AgentToken is the token agents register with; agent registration is
+	// disabled when empty
+	AgentToken string
+	// LeaseTTL is how long a run stays claimed without a heartbeat
+	LeaseTTL time.Duration
+	// ExecutorID names the server as the owner of its run leases
+	ExecutorID string
+	// DeadLetterThreshold is the number of open dead letters that raises
+	// an alert; alerts are disabled when zero
+	DeadLetterThreshold int
+	// ApprovalTTL is how long runs await approval before they expire
+	ApprovalTTL time.Duration
+	// SourceCache is the directory holding the mirrors of job sources
+	SourceCache string
+	// CacheRoot is the directory of the store holding job caches
+	CacheRoot string
+	// CacheMaxBytes caps the size of the cache store; the store's default
+	// when zero
+	CacheMaxBytes int64
+	// AttachmentRoot is the directory of the store holding job attachments
+	AttachmentRoot string
+	// AttachmentMaxBytes caps the size of an attachment; the store's
+	// default when zero
+	AttachmentMaxBytes int64
+	// Secrets configures the secret store; it is disabled without a master
+	// password, and kept in the key manager's default directory without a
+	// storage path
+	Secrets keymanager.Config
+}
+
+// loadConfig reads the server configuration from the environment
+func loadConfig(getenv func(string) string) (serverConfig, error) {
+	var (
+		cfg serverConfig
+		err error
+	)
+
+	cfg.Workspace.Root = getenv(envWorkspaceRoot)
+	if cfg.Workspace.Root == "" {
+		cfg.Workspace.Root = defaultWorkspaceRoot
+	}
+	cfg.Workspace.Policy = workspace.Policy(getenv(envWorkspacePolicy))
+	if v := getenv(envWorkspaceKeepLast); v != "" {
+		if cfg.Workspace.KeepLast, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", envWorkspaceKeepLast, err)
+		}
+	}
+	if v := getenv(envWorkspaceMaxBytes); v != "" {
+		if cfg.Workspace.MaxBytes, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", envWorkspaceMaxBytes, err)
+		}
+	}
+	if v := getenv(envJanitorInterval); v != "" {
+		if cfg.Workspace.JanitorInterval, err = time.ParseDuration(v); err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", envJanitorInterval, err)
+		}
+	}
+	if cfg.DefaultLimits, err = parseLimits(getenv, envDefaultLimits); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxLimits, err = parseLimits(getenv, envMaxLimits); err != nil {
+		return cfg, err
+	}
+	cfg.SourceCache = getenv(envSourceCache)
+	if cfg.SourceCache == "" {
+		cfg.SourceCache = defaultSourceCache
+	}
+	cfg.CacheRoot = getenv(envCacheRoot)
+	if cfg.CacheRoot == "" {
+		cfg.CacheRoot = defaultCacheRoot
+	}
+	if v := getenv(envCacheMaxBytes); v != "" {
+		if cfg.CacheMaxBytes, err = strconv.ParseInt(v, 10, 64); err != nil || cfg.CacheMaxBytes < 0 {
+			return cfg, fmt.Errorf("invalid %s: %q", envCacheMaxBytes, v)
+		}
+	}
+	cfg.AttachmentRoot = getenv(envAttachmentRoot)
+	if cfg.AttachmentRoot == "" {
+		cfg.AttachmentRoot = defaultAttachmentRoot
+	}
+	if v := getenv(envAttachmentMax); v != "" {
+		if cfg.AttachmentMaxBytes, err = strconv.ParseInt(v, 10, 64); err != nil || cfg.AttachmentMaxBytes < 0 {
+			return cfg, fmt.Errorf("invalid %s: %q", envAttachmentMax, v)
+		}
+	}
+	cfg.Secrets.StoragePath = getenv(envSecretsPath)
+	cfg.Secrets.MasterPassword = getenv(envSecretsPassword)
+	cfg.AgentToken = getenv(envAgentToken)
+	if v := getenv(envLeaseTTL); v != "" {
+		if cfg.LeaseTTL, err = time.ParseDuration(v); err != nil || cfg.LeaseTTL <= 0 {
+			return cfg, fmt.Errorf("invalid %s: %q", envLeaseTTL, v)
+		}
+	}
+	cfg.ExecutorID = getenv(envExecutorID)
+	if v := getenv(envDeadLetterAlert); v != "" {
+		if cfg.DeadLetterThreshold, err = strconv.Atoi(v); err != nil || cfg.DeadLetterThreshold < 0 {
+			return cfg, fmt.Errorf("invalid %s: %q", envDeadLetterAlert, v)
+		}
+	}
+	if v := getenv(envApprovalTTL); v != "" {
+		if cfg.ApprovalTTL, err = time.ParseDuration(v); err != nil || cfg.ApprovalTTL <= 0 {
+			return cfg, fmt.Errorf("invalid %s: %q", envApprovalTTL, v)
+		}
+	}
+	return cfg, nil
+}
+
+// parseLimits reads resource limits given as JSON, e.g.
+// {"timeout":"1h","memory_bytes":1073741824}
+func parseLimits(getenv func(string) string, key string) (limits.Limits, error) {
+	var l limits.Limits
+	v := getenv(key)
+	if v == "" {
+		return l, nil
+	}
+	if err := json.Unmarshal([]byte(v), &l); err != nil {
+		return l, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if err := l.Validate(); err != nil {
+		return l, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return l, nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/keymanager"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    serverConfig
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+			want: serverConfig{Workspace: workspace.Config{Root: defaultWorkspaceRoot}, SourceCache: defaultSourceCache, CacheRoot: defaultCacheRoot, AttachmentRoot: defaultAttachmentRoot},
+		},
+		{
+			name: "workspace settings",
+			env: map[string]string{
+				envWorkspaceRoot:     "/var/lib/gopher-tower/workspaces",
+				envWorkspacePolicy:   "keep_last",
+				envWorkspaceKeepLast: "3",
+				envWorkspaceMaxBytes: "1073741824",
+				envJanitorInterval:   "1m",
+			},
+			want: serverConfig{Workspace: workspace.Config{
+				Root:            "/var/lib/gopher-tower/workspaces",
+				Policy:          workspace.PolicyKeepLast,
+				KeepLast:        3,
+				MaxBytes:        1 << 30,
+				JanitorInterval: time.Minute,
+			}, SourceCache: defaultSourceCache, CacheRoot: defaultCacheRoot, AttachmentRoot: defaultAttachmentRoot},
+		},
+		{
+			name: "resource limits",
+			env: map[string]string{
+				envDefaultLimits: `{"timeout":"30m","memory_bytes":536870912}`,
+				envMaxLimits:     `{"timeout":"2h","output_bytes":10485760}`,
+			},
+			want: serverConfig{
+				Workspace:      workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:    defaultSourceCache,
+				CacheRoot:      defaultCacheRoot,
+				AttachmentRoot: defaultAttachmentRoot,
+				DefaultLimits: limits.Limits{
+					Timeout:     limits.Duration(30 * time.Minute),
+					MemoryBytes: 512 << 20,
+				},
+				MaxLimits: limits.Limits{
+					Timeout:     limits.Duration(2 * time.Hour),
+					OutputBytes: 10 << 20,
+				},
+			},
+		},
+		{
+			name: "secret store",
+			env: map[string]string{
+				envSecretsPath:     "/var/lib/gopher-tower/secrets",
+				envSecretsPassword: "hunter2",
+			},
+			want: serverConfig{
+				Workspace:      workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:    defaultSourceCache,
+				CacheRoot:      defaultCacheRoot,
+				AttachmentRoot: defaultAttachmentRoot,
+				Secrets:        keymanager.Config{StoragePath: "/var/lib/gopher-tower/secrets", MasterPassword: "hunter2"},
+			},
+		},
+		{
+			name: "agents and leases",
+			env: map[string]string{
+				envAgentToken: "s3cret",
+				envLeaseTTL:   "45s",
+				envExecutorID: "tower-1",
+			},
+			want: serverConfig{
+				Workspace:      workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:    defaultSourceCache,
+				CacheRoot:      defaultCacheRoot,
+				AttachmentRoot: defaultAttachmentRoot,
+				AgentToken:     "s3cret",
+				LeaseTTL:       45 * time.Second,
+				ExecutorID:     "tower-1",
+			},
+		},
+		{
+			name: "dead letter alerts",
+			env:  map[string]string{envDeadLetterAlert: "5"},
+			want: serverConfig{
+				Workspace:           workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:         defaultSourceCache,
+				CacheRoot:           defaultCacheRoot,
+				AttachmentRoot:      defaultAttachmentRoot,
+				DeadLetterThreshold: 5,
+			},
+		},
+		{
+			name:    "invalid dead letter threshold",
+			env:     map[string]string{envDeadLetterAlert: "-1"},
+			wantErr: true,
+		},
+		{
+			name: "approval ttl",
+			env:  map[string]string{envApprovalTTL: "4h"},
+			want: serverConfig{
+				Workspace:      workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:    defaultSourceCache,
+				CacheRoot:      defaultCacheRoot,
+				AttachmentRoot: defaultAttachmentRoot,
+				ApprovalTTL:    4 * time.Hour,
+			},
+		},
+		{
+			name: "source cache",
+			env:  map[string]string{envSourceCache: "/var/cache/gopher-tower/sources"},
+			want: serverConfig{
+				Workspace:      workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:    "/var/cache/gopher-tower/sources",
+				CacheRoot:      defaultCacheRoot,
+				AttachmentRoot: defaultAttachmentRoot,
+			},
+		},
+		{
+			name: "cache store",
+			env: map[string]string{
+				envCacheRoot:     "/var/cache/gopher-tower/caches",
+				envCacheMaxBytes: "1073741824",
+			},
+			want: serverConfig{
+				Workspace:      workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:    defaultSourceCache,
+				CacheRoot:      "/var/cache/gopher-tower/caches",
+				AttachmentRoot: defaultAttachmentRoot,
+				CacheMaxBytes:  1 << 30,
+			},
+		},
+		{
+			name: "attachment store",
+			env: map[string]string{
+				envAttachmentRoot: "/var/lib/gopher-tower/attachments",
+				envAttachmentMax:  "1048576",
+			},
+			want: serverConfig{
+				Workspace:          workspace.Config{Root: defaultWorkspaceRoot},
+				SourceCache:        defaultSourceCache,
+				CacheRoot:          defaultCacheRoot,
+				AttachmentRoot:     "/var/lib/gopher-tower/attachments",
+				AttachmentMaxBytes: 1 << 20,
+			},
+		},
+		{
+			name:    "invalid attachment size",
+			env:     map[string]string{envAttachmentMax: "1MB"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cache size",
+			env:     map[string]string{envCacheMaxBytes: "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid approval ttl",
+			env:     map[string]string{envApprovalTTL: "-1h"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid lease ttl",
+			env:     map[string]string{envLeaseTTL: "0s"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid limits json",
+			env:     map[string]string{envDefaultLimits: "timeout=1h"},
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			env:     map[string]string{envMaxLimits: `{"open_files":-1}`},
+			wantErr: true,
+		},
+		{
+			name:    "invalid quota",
+			env:     map[string]string{envWorkspaceMaxBytes: "1GB"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid interval",
+			env:     map[string]string{envJanitorInterval: "often"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(func(key string) string { return tt.env[key] })
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	_ "embed"
 	"encoding/json"
@@ -14,10 +15,28 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/klauern/gopher-tower/internal/api/agents"
+	"github.com/klauern/gopher-tower/internal/api/approvals"
+	"github.com/klauern/gopher-tower/internal/api/attach"
+	"github.com/klauern/gopher-tower/internal/api/deadletters"
 	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/klauern/gopher-tower/internal/api/runs"
+	"github.com/klauern/gopher-tower/internal/api/schedules"
+	"github.com/klauern/gopher-tower/internal/api/templates"
+	"github.com/klauern/gopher-tower/internal/api/testresults"
+	"github.com/klauern/gopher-tower/internal/api/triggers"
+	"github.com/klauern/gopher-tower/internal/api/uploads"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/cache"
 	"github.com/klauern/gopher-tower/internal/db"
 	"github.com/klauern/gopher-tower/internal/db/migrate"
 	"github.com/klauern/gopher-tower/internal/db/migrations"
+	"github.com/klauern/gopher-tower/internal/events"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/schedule"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/watch"
+	"github.com/klauern/gopher-tower/internal/workspace"
 	_ "modernc.org/sqlite"
 )
 
@@ -50,8 +69,27 @@ type Event struct {
 }
 
 func main() {
-	// Initialize SQLite database
-	dbConn, err := sql.Open("sqlite", "gopher-tower.db")
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		if err := runAgent(os.Args[2:], os.Getenv); err != nil {
+			log.Fatalf("Agent failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "secrets" {
+		if err := runSecrets(os.Args[2:], os.Getenv, os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("Secrets failed: %v", err)
+		}
+		return
+	}
+
+	cfg, err := loadConfig(os.Getenv)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Initialize SQLite database. Agents report concurrently with local
+	// runs, so writers wait for the lock instead of failing.
+	dbConn, err := sql.Open("sqlite", "gopher-tower.db?_pragma=busy_timeout(5000)")
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
@@ -80,10 +118,89 @@ func main() {
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(60 * time.Second)) // Example timeout
 
-	// Initialize jobs service and handler
-	jobService := jobs.NewService(queries)
+	// Register the built-in plugins. Secret references in HTTP headers are
+	// resolved from the secret store, and rejected when it is disabled.
+	secrets, err := openSecrets(context.Background(), cfg.Secrets)
+	if err != nil {
+		log.Fatalf("Failed to open secret store: %v", err)
+	}
+	if secrets != nil {
+		defer secrets.Close()
+	}
+	plugins, err := newPlugins(secrets)
+	if err != nil {
+		log.Fatalf("Failed to initialize plugins: %v", err)
+	}
+
+	// Every run gets its own workspace; the janitor enforces retention
+	workspaces, err := workspace.NewManager(cfg.Workspace)
+	if err != nil {
+		log.Fatalf("Failed to initialize workspaces: %v", err)
+	}
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	go workspaces.Run(janitorCtx)
+
+	// Repositories of jobs with a source are mirrored between runs
+	sources, err := source.NewCache(cfg.SourceCache)
+	if err != nil {
+		log.Fatalf("Failed to initialize source cache: %v", err)
+	}
+	// Job caches, such as dependency directories, are kept between runs
+	caches, err := cache.NewStore(cfg.CacheRoot, cfg.CacheMaxBytes)
+	if err != nil {
+		log.Fatalf("Failed to initialize cache store: %v", err)
+	}
+	// Files uploaded for jobs are handed to runs as stdin and input files
+	attachmentStore, err := attachments.NewStore(cfg.AttachmentRoot, cfg.AttachmentMaxBytes)
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment store: %v", err)
+	}
+
+	// Alerts such as dead letter thresholds are streamed on /api/events
+	bus := events.NewBus()
+
+	jobExecutor := executor.New(queries, plugins, workspaces, executor.Config{
+		DefaultLimits:       cfg.DefaultLimits,
+		MaxLimits:           cfg.MaxLimits,
+		ID:                  cfg.ExecutorID,
+		LeaseTTL:            cfg.LeaseTTL,
+		Events:              bus,
+		DeadLetterThreshold: cfg.DeadLetterThreshold,
+		ApprovalTTL:         cfg.ApprovalTTL,
+		Sources:             sources,
+		Caches:              caches,
+		Attachments:         attachmentStore,
+	})
+	defer jobExecutor.Shutdown()
+
+	// Initialize jobs service and handler; status changes made through the
+	// API fire completion triggers like finished runs do
+	jobService := jobs.NewService(queries, jobExecutor)
 	jobHandler := jobs.NewHandler(jobService)
 
+	runHandler := runs.NewHandler(runs.NewService(queries, jobExecutor, workspaces))
+	deadLetterHandler := deadletters.NewHandler(deadletters.NewService(queries, jobExecutor))
+	templateHandler := templates.NewHandler(templates.NewService(queries, templates.NewTransactor(dbConn), jobService, jobExecutor))
+	approvalHandler := approvals.NewHandler(approvals.NewService(queries, jobExecutor))
+	attachHandler := attach.NewHandler(attach.NewService(queries, jobExecutor))
+	uploadHandler := uploads.NewHandler(uploads.NewService(queries, attachmentStore))
+	scheduleHandler := schedules.NewHandler(schedules.NewService(queries))
+	triggerHandler := triggers.NewHandler(triggers.NewService(queries))
+	testResultHandler := testresults.NewHandler(testresults.NewService(queries))
+
+	// Remote agents pull runs of jobs with agent labels; the reaper
+	// requeues runs of executors and agents that stopped heartbeating and
+	// expires runs left awaiting approval
+	go jobExecutor.RunReaper(janitorCtx)
+	// Jobs with a schedule run at its fire times
+	go schedule.NewScheduler(queries, jobExecutor, schedule.DefaultInterval).Run(janitorCtx)
+	// Jobs with a file trigger run when files settle in their directory
+	go watch.NewManager(queries, jobExecutor, watch.DefaultSyncInterval).Run(janitorCtx)
+	agentHandler := agents.NewHandler(agents.NewService(queries, jobExecutor, agents.Config{
+		RegistrationToken: cfg.AgentToken,
+	}))
+
 	// Mount API routes under /api
 	router.Route("/api", func(r chi.Router) {
 		// Add CORS middleware specifically for API routes
@@ -103,7 +220,17 @@ func main() {
 		})
 
 		jobHandler.RegisterRoutes(r)
-		r.Get("/events", handleSSE) // Keep SSE handler under /api
+		runHandler.RegisterRoutes(r)
+		agentHandler.RegisterRoutes(r)
+		deadLetterHandler.RegisterRoutes(r)
+		templateHandler.RegisterRoutes(r)
+		approvalHandler.RegisterRoutes(r)
+		attachHandler.RegisterRoutes(r)
+		uploadHandler.RegisterRoutes(r)
+		scheduleHandler.RegisterRoutes(r)
+		triggerHandler.RegisterRoutes(r)
+		testResultHandler.RegisterRoutes(r)
+		r.Get("/events", sseHandler(bus)) // Keep SSE handler under /api
 	})
 
 	// --- Serve Static Frontend Files ---
@@ -140,6 +267,18 @@ func main() {
 }
 
 func handleSSE(w http.ResponseWriter, r *http.Request) {
+	serveEvents(w, r, nil)
+}
+
+// sseHandler streams the events published on the bus, such as dead letter
+// alerts, along with the test events
+func sseHandler(bus *events.Bus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveEvents(w, r, bus)
+	}
+}
+
+func serveEvents(w http.ResponseWriter, r *http.Request, bus *events.Bus) {
 	// Only allow GET requests for SSE
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -163,6 +302,14 @@ func handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Forward server events published once the connection is established
+	var published <-chan events.Event
+	if bus != nil {
+		var unsubscribe func()
+		published, unsubscribe = bus.Subscribe()
+		defer unsubscribe()
+	}
+
 	// Send an initial comment to establish connection
 	fmt.Fprintf(w, "retry: 1000\n") // Tell client to retry every 1 second
 	fmt.Fprintf(w, ": ping\n\n")
@@ -189,6 +336,18 @@ func handleSSE(w http.ResponseWriter, r *http.Request) {
 		case <-ctx.Done():
 			connected = false
 			return
+		case event := <-published:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error marshaling event: %v\n", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				log.Printf("Error writing to client %s: %v\n", r.RemoteAddr, err)
+				connected = false
+				return
+			}
+			flusher.Flush()
 		case <-ticker.C:
 			counter++
 			var event Event
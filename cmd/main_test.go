@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/klauern/gopher-tower/internal/events"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -114,6 +115,40 @@ func TestHandleSSE(t *testing.T) {
 	}
 }
 
+func TestSSEHandler_ForwardsBusEvents(t *testing.T) {
+	bus := events.NewBus()
+	server := httptest.NewServer(sseHandler(bus))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == ": ping" {
+			// Subscribed before the ping was sent
+			bus.Publish(events.Event{
+				Type:    events.TypeDeadLetterThreshold,
+				Payload: events.ThresholdPayload{Open: 3, Threshold: 3},
+			})
+			continue
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var event Event
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		if event.Type != events.TypeDeadLetterThreshold {
+			continue // A test event
+		}
+		assert.Equal(t, map[string]interface{}{"open": float64(3), "threshold": float64(3)}, event.Payload)
+		return
+	}
+	t.Fatal("stream ended before the bus event arrived")
+}
+
 func TestEvent_MarshalJSON(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauern/gopher-tower/internal/keymanager"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/plugin/httpreq"
+	"github.com/klauern/gopher-tower/internal/plugin/script"
+	"github.com/klauern/gopher-tower/internal/plugin/sqlquery"
+)
+
+// maxSecretBytes bounds the size of a secret read by "gopher-tower secrets set"
+const maxSecretBytes = 64 << 10
+
+// openSecrets opens the secret store ${secret:NAME} references are resolved
+// from. It returns nil when no master password is configured: without one
+// the store could not read secrets stored by earlier processes.
+func openSecrets(ctx context.Context, cfg keymanager.Config) (keymanager.SecretManager, error) {
+	if cfg.MasterPassword == "" {
+		return nil, nil
+	}
+	secrets, err := keymanager.NewTinkManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := secrets.Initialize(ctx, cfg); err != nil {
+		return nil, fmt.Errorf("failed to initialize secret store: %w", err)
+	}
+	return secrets, nil
+}
+
+// newPlugins returns a registry of the built-in plugins. Secret references
+// in HTTP headers are resolved through secrets and rejected when it is nil.
+func newPlugins(secrets keymanager.SecretManager) (plugin.PluginRegistry, error) {
+	var resolver plugin.SecretResolver
+	if secrets != nil {
+		resolver = secrets
+	}
+	plugins := plugin.NewRegistry()
+	for _, p := range []plugin.Plugin{script.New(), httpreq.New(resolver), sqlquery.New()} {
+		if err := plugins.Register(p); err != nil {
+			return nil, fmt.Errorf("failed to register plugin %s: %w", p.Name(), err)
+		}
+	}
+	return plugins, nil
+}
+
+// runSecrets runs "gopher-tower secrets set NAME", which stores the value
+// read from stdin, and "gopher-tower secrets delete NAME"
+func runSecrets(args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 2 || (args[0] != "set" && args[0] != "delete") {
+		return errors.New("usage: gopher-tower secrets set|delete NAME")
+	}
+	cfg, err := loadConfig(getenv)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	secrets, err := openSecrets(ctx, cfg.Secrets)
+	if err != nil {
+		return err
+	}
+	if secrets == nil {
+		return errors.New("the secret store requires " + envSecretsPassword)
+	}
+	defer secrets.Close()
+
+	name := args[1]
+	if args[0] == "delete" {
+		if err := secrets.DeleteSecret(ctx, name); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Deleted secret %s\n", name)
+		return nil
+	}
+
+	value, err := io.ReadAll(io.LimitReader(stdin, maxSecretBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+	if len(value) > maxSecretBytes {
+		return fmt.Errorf("secret is larger than %d bytes", maxSecretBytes)
+	}
+	if err := secrets.SetSecret(ctx, name, strings.TrimRight(string(value), "\r\n")); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Stored secret %s\n", name)
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/plugin/httpreq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsResolveThroughRegistry(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+	config := map[string]interface{}{
+		"url":     server.URL,
+		"headers": map[string]interface{}{"Authorization": "Bearer ${secret:inventory-token}"},
+	}
+
+	env := map[string]string{
+		envSecretsPath:     filepath.Join(t.TempDir(), "secrets"),
+		envSecretsPassword: "hunter2",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	var out strings.Builder
+	require.NoError(t, runSecrets([]string{"set", "inventory-token"}, getenv, strings.NewReader("abc123\n"), &out))
+	assert.Equal(t, "Stored secret inventory-token\n", out.String())
+
+	t.Run("store", func(t *testing.T) {
+		cfg, err := loadConfig(getenv)
+		require.NoError(t, err)
+		secrets, err := openSecrets(ctx, cfg.Secrets)
+		require.NoError(t, err)
+		require.NotNil(t, secrets)
+		defer secrets.Close()
+
+		plugins, err := newPlugins(secrets)
+		require.NoError(t, err)
+		p, err := plugins.Get(httpreq.Name)
+		require.NoError(t, err)
+		result, err := p.Execute(ctx, config)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer abc123", result.Output)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		secrets, err := openSecrets(ctx, (serverConfig{}).Secrets)
+		require.NoError(t, err)
+		assert.Nil(t, secrets)
+
+		plugins, err := newPlugins(secrets)
+		require.NoError(t, err)
+		p, err := plugins.Get(httpreq.Name)
+		require.NoError(t, err)
+		_, err = p.Execute(ctx, config)
+		assert.ErrorIs(t, err, plugin.ErrSecretsUnavailable)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, runSecrets([]string{"delete", "inventory-token"}, getenv, nil, &out))
+		assert.Equal(t, "Deleted secret inventory-token\n", out.String())
+	})
+}
+
+func TestRunSecrets_Errors(t *testing.T) {
+	env := map[string]string{envSecretsPath: filepath.Join(t.TempDir(), "secrets")}
+	getenv := func(key string) string { return env[key] }
+
+	assert.Error(t, runSecrets(nil, getenv, strings.NewReader(""), &strings.Builder{}))
+	assert.Error(t, runSecrets([]string{"get", "token"}, getenv, strings.NewReader(""), &strings.Builder{}))
+	err := runSecrets([]string{"set", "token"}, getenv, strings.NewReader("value"), &strings.Builder{})
+	assert.ErrorContains(t, err, envSecretsPassword)
+
+	env[envSecretsPassword] = "hunter2"
+	err = runSecrets([]string{"set", "token"}, getenv, strings.NewReader(strings.Repeat("x", maxSecretBytes+1)), &strings.Builder{})
+	assert.Error(t, err)
+	err = runSecrets([]string{"set", "../token"}, getenv, strings.NewReader("value"), &strings.Builder{})
+	assert.Error(t, err)
+}
@@ -54,30 +54,319 @@ WHERE owner_id = ?
 ORDER BY created_at DESC
 LIMIT ? OFFSET ?;
 
+-- name: SearchJobs :many
+-- Best match per job in job_search, best first; run_number is NULL when
+-- the job matched on its name or description rather than a run's output
+WITH hits AS (
+  SELECT job_id, run_number,
+    CAST(bm25(job_search, 0.0, 0.0, 0.0, 10.0, 5.0, 1.0) AS REAL) AS score,
+    CAST(snippet(job_search, -1, '<mark>', '</mark>', '...', 16) AS TEXT) AS snippet
+  FROM job_search
+  WHERE job_search MATCH sqlc.arg(query)
+), best AS (
+  SELECT job_id, run_number, score, snippet,
+    ROW_NUMBER() OVER (PARTITION BY job_id ORDER BY score) AS position
+  FROM hits
+)
+SELECT sqlc.embed(jobs), best.run_number, best.score, best.snippet
+FROM best
+JOIN jobs ON jobs.id = best.job_id
+WHERE best.position = 1
+ORDER BY best.score, jobs.created_at DESC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
 -- name: CreateJob :one
 INSERT INTO jobs (
-  id, name, description, status, start_date, end_date, owner_id
+  id, name, description, status, start_date, end_date, owner_id, plugin, plugin_config, sandbox, limits, agent_labels, max_retries,
+  template_id, template_version, template_params, input_schema, matrix, requires_approval, approver_roles,
+  source, caches, interactive, schedule, file_trigger, test_reports
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
 -- name: UpdateJob :one
 UPDATE jobs
 SET
-  name = ?,
-  description = ?,
-  status = ?,
-  start_date = ?,
-  end_date = ?,
+  name = sqlc.arg(name),
+  description = sqlc.arg(description),
+  status = sqlc.arg(status),
+  start_date = sqlc.arg(start_date),
+  end_date = sqlc.arg(end_date),
+  plugin = sqlc.arg(plugin),
+  plugin_config = sqlc.arg(plugin_config),
+  sandbox = sqlc.arg(sandbox),
+  limits = sqlc.arg(limits),
+  agent_labels = sqlc.arg(agent_labels),
+  max_retries = sqlc.arg(max_retries),
+  input_schema = sqlc.arg(input_schema),
+  matrix = sqlc.arg(matrix),
+  requires_approval = sqlc.arg(requires_approval),
+  approver_roles = sqlc.arg(approver_roles),
+  source = sqlc.arg(source),
+  caches = sqlc.arg(caches),
+  interactive = sqlc.arg(interactive),
+  -- A changed schedule starts over: fire times of the old one are neither
+  -- caught up nor queued
+  schedule_checked_at = CASE WHEN schedule IS sqlc.arg(schedule) THEN schedule_checked_at END,
+  schedule_queued_for = CASE WHEN schedule IS sqlc.arg(schedule) THEN schedule_queued_for END,
+  schedule = sqlc.arg(schedule),
+  file_trigger = sqlc.arg(file_trigger),
+  test_reports = sqlc.arg(test_reports),
   updated_at = CURRENT_TIMESTAMP
-WHERE id = ?
+WHERE id = sqlc.arg(id)
+  -- Callers acting on status changes pass the status they read, so the
+  -- update finds no row when another one changed it in between
+  AND (status = sqlc.narg(previous_status) OR sqlc.narg(previous_status) IS NULL)
 RETURNING *;
 
+-- name: UpdateJobStatus :exec
+UPDATE jobs
+SET
+  status = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
 -- name: DeleteJob :exec
 DELETE FROM jobs
 WHERE id = ?;
 
+-- name: GetJobRun :one
+SELECT * FROM job_runs
+WHERE id = ? LIMIT 1;
+
+-- name: GetJobRunByNumber :one
+SELECT * FROM job_runs
+WHERE job_id = ? AND run_number = ? LIMIT 1;
+
+-- name: ListJobRunsByJob :many
+SELECT * FROM job_runs
+WHERE job_id = ?
+ORDER BY run_number DESC
+LIMIT ? OFFSET ?;
+
+-- name: GetNextRunNumber :one
+SELECT CAST(COALESCE(MAX(run_number), 0) + 1 AS INTEGER) AS next_run_number FROM job_runs
+WHERE job_id = ?;
+
+-- name: CreateJobRun :one
+INSERT INTO job_runs (
+  id, job_id, run_number, status, agent_labels, inputs,
+  parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at,
+  stdin, input_files, scheduled_for, caught_up, triggered_by_run_id
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: StartJobRun :one
+UPDATE job_runs
+SET
+  workspace_path = ?,
+  sandbox_profile = ?,
+  limits = ?,
+  started_at = CURRENT_TIMESTAMP
+WHERE id = ? AND lease_owner = ? AND status = 'running'
+RETURNING *;
+
+-- name: ListQueuedLocalRuns :many
+SELECT * FROM job_runs
+WHERE status = 'pending' AND agent_labels IS NULL AND lease_owner IS NULL
+  AND child_count = 0
+  AND (max_parallel = 0 OR (
+    SELECT COUNT(*) FROM job_runs AS sibling
+    WHERE sibling.parent_run_id = job_runs.parent_run_id AND sibling.status = 'running'
+  ) < max_parallel)
+ORDER BY created_at, rowid
+LIMIT ?;
+
+-- name: ListQueuedAgentRuns :many
+SELECT * FROM job_runs
+WHERE status = 'pending' AND agent_labels IS NOT NULL AND lease_owner IS NULL
+  AND child_count = 0
+  AND (max_parallel = 0 OR (
+    SELECT COUNT(*) FROM job_runs AS sibling
+    WHERE sibling.parent_run_id = job_runs.parent_run_id AND sibling.status = 'running'
+  ) < max_parallel)
+ORDER BY created_at, rowid
+LIMIT ?;
+
+-- name: ClaimJobRun :one
+UPDATE job_runs
+SET
+  status = ?,
+  lease_owner = ?,
+  agent_id = ?,
+  lease_expires_at = ?,
+  limits = ?,
+  attempt = attempt + 1,
+  started_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'pending' AND lease_owner IS NULL
+  AND child_count = 0
+  AND (max_parallel = 0 OR (
+    SELECT COUNT(*) FROM job_runs AS sibling
+    WHERE sibling.parent_run_id = job_runs.parent_run_id AND sibling.status = 'running'
+  ) < max_parallel)
+RETURNING *;
+
+-- name: ListChildRuns :many
+SELECT * FROM job_runs
+WHERE parent_run_id = ?
+ORDER BY run_number;
+
+-- name: CancelChildRuns :many
+UPDATE job_runs
+SET
+  status = 'cancelled',
+  finished_at = CURRENT_TIMESTAMP
+WHERE parent_run_id = ? AND finished_at IS NULL
+RETURNING *;
+
+-- name: UpdateParentRun :execrows
+UPDATE job_runs
+SET
+  status = sqlc.arg(status),
+  started_at = COALESCE(started_at, sqlc.arg(started_at)),
+  finished_at = sqlc.narg(finished_at)
+WHERE id = sqlc.arg(id) AND child_count > 0 AND finished_at IS NULL;
+
+-- name: DecideJobRun :execrows
+UPDATE job_runs
+SET
+  status = sqlc.arg(status),
+  approval_expires_at = NULL,
+  finished_at = sqlc.narg(finished_at)
+WHERE CAST(sqlc.arg(id) AS TEXT) IN (id, parent_run_id) AND status = 'awaiting_approval';
+
+-- name: ListExpiredApprovals :many
+SELECT * FROM job_runs
+WHERE status = 'awaiting_approval' AND parent_run_id IS NULL AND approval_expires_at < ?;
+
+-- name: RenewJobRunLease :execrows
+UPDATE job_runs
+SET lease_expires_at = ?
+WHERE id = ? AND lease_owner = ? AND status = 'running';
+
+-- name: RenewLeases :execrows
+UPDATE job_runs
+SET lease_expires_at = ?
+WHERE lease_owner = ? AND status = 'running';
+
+-- name: ListExpiredJobRuns :many
+SELECT * FROM job_runs
+WHERE status = 'running' AND lease_owner IS NOT NULL AND lease_expires_at < ?;
+
+-- name: RequeueJobRun :execrows
+UPDATE job_runs
+SET
+  status = 'pending',
+  lease_owner = NULL,
+  agent_id = NULL,
+  lease_expires_at = NULL,
+  workspace_path = NULL,
+  sandbox_profile = NULL,
+  stdout = NULL,
+  stderr = NULL,
+  started_at = NULL
+WHERE id = ? AND lease_owner = ? AND status = 'running' AND lease_expires_at < ?;
+
+-- name: AppendJobRunLogs :execrows
+UPDATE job_runs
+SET
+  stdout = COALESCE(stdout, '') || sqlc.arg(stdout),
+  stderr = COALESCE(stderr, '') || sqlc.arg(stderr)
+WHERE id = sqlc.arg(id) AND agent_id = sqlc.arg(agent_id) AND status = 'running';
+
+-- name: RetryJobRun :execrows
+UPDATE job_runs
+SET
+  status = 'pending',
+  lease_owner = NULL,
+  agent_id = NULL,
+  lease_expires_at = NULL,
+  workspace_path = NULL,
+  sandbox_profile = NULL,
+  stdout = NULL,
+  stderr = NULL,
+  started_at = NULL
+WHERE id = ? AND lease_owner = ? AND finished_at IS NULL;
+
+-- name: CreateJobRunAttempt :exec
+INSERT INTO job_run_attempts (
+  run_id, attempt, status, exit_code, error, failure_reason, lease_owner, started_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListJobRunAttempts :many
+SELECT * FROM job_run_attempts
+WHERE run_id = ?
+ORDER BY attempt, id;
+
+-- name: SetJobRunSandboxProfile :exec
+UPDATE job_runs
+SET sandbox_profile = ?
+WHERE id = ?;
+
+-- name: SetJobRunSourceSHA :exec
+UPDATE job_runs
+SET source_sha = ?
+WHERE id = ?;
+
+-- name: SetJobRunCacheResults :exec
+UPDATE job_runs
+SET cache_results = ?
+WHERE id = ?;
+
+-- name: SetJobRunPlan :exec
+UPDATE job_runs
+SET plan = ?
+WHERE id = ?;
+
+-- name: FinishJobRun :one
+UPDATE job_runs
+SET
+  status = ?,
+  exit_code = ?,
+  stdout = ?,
+  stderr = ?,
+  metadata = ?,
+  failure_reason = ?,
+  finished_at = CURRENT_TIMESTAMP
+WHERE id = ? AND lease_owner IS ? AND finished_at IS NULL
+RETURNING *;
+
+-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (
+  id, run_id, job_id, attempts, error, failure_reason
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetDeadLetter :one
+SELECT * FROM dead_letters
+WHERE id = ? LIMIT 1;
+
+-- name: ListOpenDeadLetters :many
+SELECT * FROM dead_letters
+WHERE resolution IS NULL
+ORDER BY created_at DESC, rowid DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountOpenDeadLetters :one
+SELECT COUNT(*) FROM dead_letters
+WHERE resolution IS NULL;
+
+-- name: ResolveDeadLetter :one
+UPDATE dead_letters
+SET
+  resolution = ?,
+  requeued_run_id = ?,
+  resolved_at = CURRENT_TIMESTAMP
+WHERE id = ? AND resolution IS NULL
+RETURNING *;
+
 -- name: GetTask :one
 SELECT * FROM tasks
 WHERE id = ? LIMIT 1;
@@ -177,9 +466,9 @@ ORDER BY created_at DESC;
 
 -- name: CreateAttachment :one
 INSERT INTO attachments (
-  id, filename, file_path, file_size, mime_type, task_id, job_id
+  id, filename, file_path, file_size, mime_type, task_id, job_id, sha256
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
@@ -226,6 +515,35 @@ WHERE user_id = ? AND is_read = 0;
 DELETE FROM notifications
 WHERE id = ?;
 
+-- name: CreateAgent :one
+INSERT INTO agents (
+  id, name, labels, hostname, version, token_hash
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetAgent :one
+SELECT * FROM agents
+WHERE id = ? LIMIT 1;
+
+-- name: GetAgentByTokenHash :one
+SELECT * FROM agents
+WHERE token_hash = ? LIMIT 1;
+
+-- name: ListAgents :many
+SELECT * FROM agents
+ORDER BY name, id;
+
+-- name: RecordAgentHeartbeat :exec
+UPDATE agents
+SET last_heartbeat_at = ?
+WHERE id = ?;
+
+-- name: DeleteAgent :exec
+DELETE FROM agents
+WHERE id = ?;
+
 -- name: CreateActivityLog :one
 INSERT INTO activity_logs (
   id, action, entity_type, entity_id, details, user_id
@@ -245,3 +563,198 @@ SELECT * FROM activity_logs
 WHERE user_id = ?
 ORDER BY created_at DESC
 LIMIT ? OFFSET ?;
+
+-- name: CreateJobTemplate :one
+INSERT INTO job_templates (
+  id, name, description, owner_id
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetJobTemplate :one
+SELECT * FROM job_templates
+WHERE id = ? LIMIT 1;
+
+-- name: ListJobTemplates :many
+SELECT * FROM job_templates
+ORDER BY name
+LIMIT ? OFFSET ?;
+
+-- name: CountJobTemplates :one
+SELECT COUNT(*) FROM job_templates;
+
+-- name: UpdateJobTemplate :one
+UPDATE job_templates
+SET
+  name = ?,
+  description = ?,
+  latest_version = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: CreateJobTemplateVersion :one
+INSERT INTO job_template_versions (
+  template_id, version, parameters, body
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetJobTemplateVersion :one
+SELECT * FROM job_template_versions
+WHERE template_id = ? AND version = ? LIMIT 1;
+
+-- name: ListJobTemplateVersions :many
+SELECT * FROM job_template_versions
+WHERE template_id = ?
+ORDER BY version DESC;
+
+-- name: ListScheduledJobs :many
+SELECT id, schedule, schedule_checked_at, schedule_queued_for FROM jobs
+WHERE schedule IS NOT NULL
+ORDER BY id;
+
+-- name: AdvanceJobSchedule :execrows
+UPDATE jobs
+SET schedule_checked_at = sqlc.arg(checked_at)
+WHERE id = sqlc.arg(id) AND schedule = sqlc.arg(schedule)
+  AND schedule_checked_at IS sqlc.narg(previous);
+
+-- name: SetJobScheduleQueued :execrows
+UPDATE jobs
+SET schedule_queued_for = sqlc.narg(queued_for)
+WHERE id = sqlc.arg(id) AND schedule_queued_for IS sqlc.narg(previous);
+
+-- name: CountActiveJobRuns :one
+SELECT COUNT(*) FROM job_runs
+WHERE job_id = ? AND parent_run_id IS NULL AND finished_at IS NULL;
+
+-- name: CancelJobRuns :many
+UPDATE job_runs
+SET
+  status = 'cancelled',
+  finished_at = CURRENT_TIMESTAMP
+WHERE job_id = ? AND finished_at IS NULL
+RETURNING *;
+
+-- name: CreateSkippedJobRun :one
+INSERT INTO job_runs (
+  id, job_id, run_number, status, failure_reason, scheduled_for, caught_up, dropped_fire_times, finished_at
+) VALUES (
+  ?, ?, ?, 'skipped', ?, ?, ?, ?, CURRENT_TIMESTAMP
+)
+RETURNING *;
+
+-- name: CreateCalendar :one
+INSERT INTO calendars (
+  name, description, timezone, windows
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetCalendar :one
+SELECT * FROM calendars
+WHERE name = ? LIMIT 1;
+
+-- name: ListCalendars :many
+SELECT * FROM calendars
+ORDER BY name;
+
+-- name: UpdateCalendar :one
+UPDATE calendars
+SET
+  description = ?,
+  timezone = ?,
+  windows = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE name = ?
+RETURNING *;
+
+-- name: DeleteCalendar :execrows
+DELETE FROM calendars
+WHERE name = ?;
+
+-- name: CountJobsUsingCalendar :one
+SELECT COUNT(*) FROM jobs
+WHERE schedule IS NOT NULL
+  AND EXISTS (SELECT 1 FROM json_each(jobs.schedule, '$.calendars') WHERE value = sqlc.arg(name));
+
+-- name: ListFileTriggers :many
+SELECT id, file_trigger FROM jobs
+WHERE file_trigger IS NOT NULL;
+
+-- name: ClaimTriggeredFile :one
+INSERT INTO triggered_files (
+  job_id, path, size, mod_time
+) VALUES (
+  ?, ?, ?, ?
+)
+ON CONFLICT (job_id, path) DO UPDATE SET
+  size = excluded.size,
+  mod_time = excluded.mod_time,
+  run_id = NULL,
+  triggered_at = CURRENT_TIMESTAMP
+WHERE triggered_files.size != excluded.size OR triggered_files.mod_time != excluded.mod_time
+RETURNING *;
+
+-- name: SetTriggeredFileRun :exec
+UPDATE triggered_files
+SET run_id = ?
+WHERE job_id = ? AND path = ?;
+
+-- name: DeleteTriggeredFile :exec
+DELETE FROM triggered_files
+WHERE job_id = ? AND path = ?;
+
+-- name: ListTriggeredFiles :many
+SELECT * FROM triggered_files
+WHERE job_id = ?
+ORDER BY triggered_at DESC, path;
+
+-- name: CreateJobTrigger :one
+INSERT INTO job_triggers (
+  id, source_job_id, target_job_id, on_status
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetJobTrigger :one
+SELECT * FROM job_triggers
+WHERE id = ? LIMIT 1;
+
+-- name: ListJobTriggersBySource :many
+SELECT * FROM job_triggers
+WHERE source_job_id = ?
+ORDER BY created_at, id;
+
+-- name: DeleteJobTrigger :exec
+DELETE FROM job_triggers
+WHERE id = ?;
+
+-- name: CreateTestResult :exec
+INSERT INTO test_results (
+  run_id, job_id, suite, name, classname, duration, status, message
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeleteTestResultsByRun :exec
+DELETE FROM test_results
+WHERE run_id = ?;
+
+-- name: ListTestResultsByRun :many
+SELECT * FROM test_results
+WHERE run_id = ?
+ORDER BY suite, name, id;
+
+-- name: ListTestResultsByJob :many
+SELECT job_runs.run_number, test_results.suite, test_results.name, test_results.classname,
+  test_results.duration, test_results.status, test_results.message
+FROM test_results
+JOIN job_runs ON job_runs.id = test_results.run_id
+WHERE test_results.job_id = ? AND job_runs.run_number >= ?
+ORDER BY job_runs.run_number, test_results.suite, test_results.name;
@@ -33,7 +33,7 @@ CREATE TABLE jobs (
   end_date TIMESTAMP,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-  owner_id TEXT, command TEXT, arguments TEXT, stdout TEXT, stderr TEXT,
+  owner_id TEXT, command TEXT, arguments TEXT, stdout TEXT, stderr TEXT, plugin TEXT, plugin_config TEXT, sandbox BOOLEAN NOT NULL DEFAULT 0, limits TEXT, agent_labels TEXT, max_retries INTEGER NOT NULL DEFAULT 0, template_id TEXT REFERENCES job_templates(id) ON DELETE SET NULL, template_version INTEGER, template_params TEXT, input_schema TEXT, matrix TEXT, requires_approval BOOLEAN NOT NULL DEFAULT 0, approver_roles TEXT, source TEXT, caches TEXT, interactive BOOLEAN NOT NULL DEFAULT 0, schedule TEXT, schedule_checked_at TIMESTAMP, schedule_queued_for TIMESTAMP, file_trigger TEXT, test_reports TEXT,
   FOREIGN KEY (owner_id) REFERENCES users(id)
 );
 CREATE INDEX idx_jobs_status ON jobs(status);
@@ -72,7 +72,7 @@ CREATE TABLE attachments (
   mime_type TEXT NOT NULL,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   task_id TEXT,
-  job_id TEXT,
+  job_id TEXT, sha256 TEXT,
   FOREIGN KEY (task_id) REFERENCES tasks(id),
   FOREIGN KEY (job_id) REFERENCES jobs(id)
 );
@@ -148,3 +148,177 @@ CREATE TABLE env_secrets (
 );
 CREATE INDEX idx_env_vars_environment_id ON env_vars(environment_id);
 CREATE INDEX idx_env_secrets_env_var_id ON env_secrets(env_var_id);
+CREATE TABLE job_runs (
+  id TEXT PRIMARY KEY,
+  job_id TEXT NOT NULL,
+  -- Sequential run number per job, starting at 1
+  run_number INTEGER NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  exit_code INTEGER,
+  stdout TEXT,
+  stderr TEXT,
+  -- JSON-encoded plugin metadata and artifacts
+  metadata TEXT,
+  -- Workspace directory used by the run
+  workspace_path TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP, sandbox_profile TEXT, limits TEXT, failure_reason TEXT, agent_labels TEXT, agent_id TEXT REFERENCES agents(id) ON DELETE SET NULL, lease_expires_at TIMESTAMP, lease_owner TEXT, attempt INTEGER NOT NULL DEFAULT 0, inputs TEXT, parent_run_id TEXT REFERENCES job_runs(id) ON DELETE CASCADE, matrix_values TEXT, child_count INTEGER NOT NULL DEFAULT 0, max_parallel INTEGER NOT NULL DEFAULT 0, approval_expires_at DATETIME, source_sha TEXT, cache_results TEXT, stdin TEXT, input_files TEXT, scheduled_for TIMESTAMP, caught_up BOOLEAN NOT NULL DEFAULT 0, triggered_by_run_id TEXT REFERENCES job_runs(id) ON DELETE SET NULL, plan TEXT, dropped_fire_times INTEGER NOT NULL DEFAULT 0,
+  FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE,
+  UNIQUE(job_id, run_number)
+);
+CREATE INDEX idx_job_runs_job_id ON job_runs(job_id);
+CREATE INDEX idx_job_runs_status ON job_runs(status);
+CREATE TABLE agents (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  -- JSON array of labels offered by the agent
+  labels TEXT NOT NULL DEFAULT '[]',
+  hostname TEXT,
+  version TEXT,
+  -- SHA-256 hex digest of the agent's access token
+  token_hash TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_heartbeat_at TIMESTAMP
+);
+CREATE INDEX idx_job_runs_agent_id ON job_runs(agent_id);
+CREATE INDEX idx_job_runs_status_lease ON job_runs(status, lease_owner);
+CREATE TABLE job_run_attempts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id TEXT NOT NULL REFERENCES job_runs(id) ON DELETE CASCADE,
+  attempt INTEGER NOT NULL,
+  status TEXT NOT NULL,
+  exit_code INTEGER,
+  error TEXT,
+  failure_reason TEXT,
+  lease_owner TEXT,
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_job_run_attempts_run_id ON job_run_attempts(run_id);
+CREATE TABLE dead_letters (
+  id TEXT PRIMARY KEY,
+  run_id TEXT NOT NULL UNIQUE REFERENCES job_runs(id) ON DELETE CASCADE,
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  attempts INTEGER NOT NULL,
+  -- Error and failure reason of the last attempt
+  error TEXT,
+  failure_reason TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  -- requeued or discarded; NULL while the dead letter is open
+  resolution TEXT,
+  resolved_at TIMESTAMP,
+  requeued_run_id TEXT REFERENCES job_runs(id) ON DELETE SET NULL
+);
+CREATE INDEX idx_dead_letters_resolution ON dead_letters(resolution);
+CREATE TABLE job_templates (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  description TEXT,
+  owner_id TEXT REFERENCES users(id),
+  -- Version instantiated unless another one is asked for
+  latest_version INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE job_template_versions (
+  template_id TEXT NOT NULL REFERENCES job_templates(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  -- JSON array of parameter declarations
+  parameters TEXT NOT NULL DEFAULT '[]',
+  -- JSON job definition with ${{ params.x }} placeholders
+  body TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (template_id, version)
+);
+CREATE INDEX idx_jobs_template_id ON jobs(template_id);
+CREATE INDEX idx_job_runs_parent_run_id ON job_runs(parent_run_id);
+CREATE INDEX idx_job_runs_approval_expires_at ON job_runs(approval_expires_at);
+CREATE TABLE calendars (
+  name TEXT PRIMARY KEY,
+  description TEXT,
+  timezone TEXT,
+  windows TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE triggered_files (
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  path TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  -- Modification time in nanoseconds since the Unix epoch
+  mod_time INTEGER NOT NULL,
+  run_id TEXT REFERENCES job_runs(id) ON DELETE SET NULL,
+  triggered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (job_id, path)
+);
+CREATE TABLE job_triggers (
+  id TEXT PRIMARY KEY,
+  source_job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  target_job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  on_status TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (source_job_id, target_job_id, on_status)
+);
+CREATE INDEX idx_job_triggers_target_job_id ON job_triggers(target_job_id);
+CREATE TABLE test_results (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id TEXT NOT NULL REFERENCES job_runs(id) ON DELETE CASCADE,
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  suite TEXT NOT NULL,
+  name TEXT NOT NULL,
+  classname TEXT,
+  duration REAL NOT NULL DEFAULT 0,
+  status TEXT NOT NULL,
+  message TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_test_results_run_id ON test_results(run_id);
+CREATE INDEX idx_test_results_job_id ON test_results(job_id);
+CREATE VIRTUAL TABLE job_search USING fts5(
+  job_id UNINDEXED,
+  run_id UNINDEXED,
+  run_number UNINDEXED,
+  name,
+  description,
+  output
+)
+/* job_search(job_id,run_id,run_number,name,description,output) */;
+CREATE TABLE IF NOT EXISTS 'job_search_data'(id INTEGER PRIMARY KEY, block BLOB);
+CREATE TABLE IF NOT EXISTS 'job_search_idx'(segid, term, pgno, PRIMARY KEY(segid, term)) WITHOUT ROWID;
+CREATE TABLE IF NOT EXISTS 'job_search_content'(id INTEGER PRIMARY KEY, c0, c1, c2, c3, c4, c5);
+CREATE TABLE IF NOT EXISTS 'job_search_docsize'(id INTEGER PRIMARY KEY, sz BLOB);
+CREATE TABLE IF NOT EXISTS 'job_search_config'(k PRIMARY KEY, v) WITHOUT ROWID;
+CREATE TRIGGER jobs_search_insert AFTER INSERT ON jobs
+BEGIN
+  INSERT INTO job_search (job_id, name, description)
+  VALUES (new.id, new.name, new.description);
+END;
+CREATE TRIGGER jobs_search_update AFTER UPDATE OF name, description ON jobs
+BEGIN
+  UPDATE job_search SET name = new.name, description = new.description
+  WHERE job_id = old.id AND run_id IS NULL;
+END;
+CREATE TRIGGER jobs_search_delete AFTER DELETE ON jobs
+BEGIN
+  DELETE FROM job_search WHERE job_id = old.id;
+END;
+CREATE TRIGGER job_runs_search_insert AFTER INSERT ON job_runs
+WHEN new.finished_at IS NOT NULL
+BEGIN
+  INSERT INTO job_search (job_id, run_id, run_number, output)
+  VALUES (new.job_id, new.id, new.run_number, COALESCE(new.stdout, '') || char(10) || COALESCE(new.stderr, ''));
+END;
+CREATE TRIGGER job_runs_search_update AFTER UPDATE OF stdout, stderr, finished_at ON job_runs
+WHEN old.finished_at IS NOT NULL OR new.finished_at IS NOT NULL
+BEGIN
+  DELETE FROM job_search WHERE old.finished_at IS NOT NULL AND run_id = old.id;
+  INSERT INTO job_search (job_id, run_id, run_number, output)
+  SELECT new.job_id, new.id, new.run_number, COALESCE(new.stdout, '') || char(10) || COALESCE(new.stderr, '')
+  WHERE new.finished_at IS NOT NULL;
+END;
+CREATE TRIGGER job_runs_search_delete AFTER DELETE ON job_runs
+WHEN old.finished_at IS NOT NULL
+BEGIN
+  DELETE FROM job_search WHERE run_id = old.id;
+END;
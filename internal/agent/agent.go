@@ -0,0 +1,373 @@
+/*
+Package agent implements the remote execution agent (gopher-tower agent).
+
+An agent registers with a Gopher Tower server, then long-polls it for runs
+of jobs whose agent labels it offers and executes them with its own
+plugins and workspaces. Output is streamed back while a run is in
+progress and the result is reported when it finishes. The standard input
+and input files of a run are downloaded from the server before it starts.
+All traffic is initiated by the agent over plain HTTP, so agents need no
+inbound access.
+
+A heartbeat keeps the leases of the agent's runs alive; if the agent stops
+heartbeating, the server requeues its runs. A run whose lease was lost is
+cancelled on the agent.
+*/
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/klauern/gopher-tower/internal/api/agents"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/workspace"
+)
+
+// Defaults for Config fields left zero
+const (
+	DefaultLogFlushInterval = time.Second
+	defaultHeartbeat        = 10 * time.Second
+)
+
+// Retry bounds after failed polls
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// reportTimeout bounds how long reporting a result may take, including
+// after the agent was asked to stop
+const reportTimeout = 30 * time.Second
+
+// Config holds the agent settings
+type Config struct {
+	// Server is the base URL of the Gopher Tower server
+	Server string
+	// Token is the server's agent registration token
+	Token    string
+	Name     string
+	Labels   []string
+	Hostname string
+	Version  string
+	// PollWait is how long each poll waits for a run;
+	// agents.DefaultPollWait when zero
+	PollWait time.Duration
+	// HeartbeatInterval overrides the interval suggested by the server
+	HeartbeatInterval time.Duration
+	// LogFlushInterval is how often output is streamed to the server;
+	// DefaultLogFlushInterval when zero
+	LogFlushInterval time.Duration
+	// HTTPClient is used for all requests; http.DefaultClient when nil
+	HTTPClient *http.Client
+	// Sources mirrors the repositories of jobs with a source; such runs
+	// fail when nil
+	Sources *source.Cache
+	// Caches stores the caches of jobs between runs; jobs run without
+	// their caches when nil
+	Caches *cache.Store
+}
+
+// Agent pulls runs from a server and executes them
+type Agent struct {
+	cfg        Config
+	client     *Client
+	plugins    plugin.PluginRegistry
+	workspaces *workspace.Manager
+}
+
+// New creates an agent executing runs with the given plugins in workspaces
+// managed by workspaces
+func New(cfg Config, plugins plugin.PluginRegistry, workspaces *workspace.Manager) *Agent {
+	if cfg.PollWait <= 0 {
+		cfg.PollWait = agents.DefaultPollWait
+	}
+	if cfg.LogFlushInterval <= 0 {
+		cfg.LogFlushInterval = DefaultLogFlushInterval
+	}
+	return &Agent{
+		cfg:        cfg,
+		client:     NewClient(cfg.Server, cfg.HTTPClient),
+		plugins:    plugins,
+		workspaces: workspaces,
+	}
+}
+
+// Run registers the agent and executes runs one at a time until ctx is
+// cancelled. It fails when registration fails or the server revokes the
+// agent's token.
+func (a *Agent) Run(ctx context.Context) error {
+	reg, err := a.client.Register(ctx, a.cfg.Token, agents.RegisterRequest{
+		Name:     a.cfg.Name,
+		Labels:   a.cfg.Labels,
+		Hostname: a.cfg.Hostname,
+		Version:  a.cfg.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register agent: %w", err)
+	}
+	log.Printf("Registered agent %s (%s) with labels %v", reg.Agent.Name, reg.Agent.ID, reg.Agent.Labels)
+
+	interval := a.cfg.HeartbeatInterval
+	if interval <= 0 {
+		interval = time.Duration(reg.HeartbeatIntervalMS) * time.Millisecond
+	}
+	if interval <= 0 {
+		interval = defaultHeartbeat
+	}
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	defer stopHeartbeat()
+	go a.heartbeat(heartbeatCtx, interval)
+
+	backoff := minBackoff
+	for ctx.Err() == nil {
+		assignment, err := a.client.Poll(ctx, a.cfg.PollWait)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if errors.Is(err, ErrUnauthorized) {
+				return err
+			}
+			log.Printf("Failed to poll for runs, retrying in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		backoff = minBackoff
+		if assignment != nil {
+			a.execute(ctx, assignment)
+		}
+	}
+	return nil
+}
+
+// heartbeat renews the agent's leases until ctx is cancelled
+func (a *Agent) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := a.client.Heartbeat(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to send heartbeat: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// execute runs an assignment and reports its result
+func (a *Agent) execute(ctx context.Context, as *agents.Assignment) {
+	log.Printf("Starting run %d of job %s (%s)", as.RunNumber, as.JobID, as.RunID)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream := &logStream{client: a.client, runID: as.RunID, lost: cancel}
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		stream.run(runCtx, a.cfg.LogFlushInterval)
+	}()
+
+	result := a.run(runCtx, as, stream)
+	cancel()
+	<-streamDone
+
+	reportCtx, cancelReport := context.WithTimeout(context.WithoutCancel(ctx), reportTimeout)
+	defer cancelReport()
+	if err := a.client.Complete(reportCtx, as.RunID, result); err != nil {
+		log.Printf("Failed to report result of run %s: %v", as.RunID, err)
+		return
+	}
+	if result.Error != "" {
+		log.Printf("Run %s failed: %s", as.RunID, result.Error)
+		return
+	}
+	log.Printf("Run %s complete", as.RunID)
+}
+
+// run executes an assignment in a fresh workspace
+func (a *Agent) run(ctx context.Context, as *agents.Assignment, stream *logStream) agents.RunResult {
+	p, err := a.plugins.Get(as.Plugin)
+	if err != nil {
+		return failed(err)
+	}
+	ws, err := a.workspaces.Create(as.JobID, as.RunID)
+	if err != nil {
+		return failed(err)
+	}
+
+	execution := &plugin.Execution{
+		WorkDir: ws.Dir(),
+		Env:     ws.Env(),
+		Stdout:  stream.writer(&stream.stdout),
+		Stderr:  stream.writer(&stream.stderr),
+	}
+	if as.Limits != nil {
+		execution.Limits = *as.Limits
+	}
+	if err := executor.ApplyInputs(execution, ws, as.Inputs); err != nil {
+		if err := a.workspaces.Release(ws, false); err != nil {
+			log.Printf("Failed to release workspace of run %s: %v", as.RunID, err)
+		}
+		return failed(err)
+	}
+	executor.ApplyMatrix(execution, as.Matrix)
+	sha, err := executor.ApplySource(ctx, a.cfg.Sources, as.Source, execution)
+	if err != nil {
+		if err := a.workspaces.Release(ws, false); err != nil {
+			log.Printf("Failed to release workspace of run %s: %v", as.RunID, err)
+		}
+		result := failed(err)
+		result.FailureReason = executor.ReasonCheckoutFailed
+		return result
+	}
+	open := func(src attachments.Source) (io.ReadCloser, error) {
+		return a.client.OpenAttachment(ctx, as.RunID, src.Attachment)
+	}
+	if err := executor.ApplyAttachments(execution, ws, as.Stdin, as.Files, open); err != nil {
+		if err := a.workspaces.Release(ws, false); err != nil {
+			log.Printf("Failed to release workspace of run %s: %v", as.RunID, err)
+		}
+		return failed(err)
+	}
+	var profile *sandbox.Profile
+	if as.Sandbox {
+		execution.Sandbox = &sandbox.Policy{Writable: []string{ws.Path}}
+		plan := sandbox.Plan(*execution.Sandbox)
+		profile = &plan
+	}
+
+	caches := executor.RestoreCaches(a.cfg.Caches, as.JobID, as.Caches, execution)
+	var plan *plugin.Plan
+	if planned, err := executor.PlanRun(ctx, p, as.Config, execution); err == nil {
+		plan = &planned
+	} else {
+		log.Printf("Failed to plan run %s: %v", as.RunID, err)
+	}
+	res, runErr := executor.RunPlugin(ctx, p, as.Config, execution)
+	tests := executor.CollectTestReports(as.RunID, as.TestReports, execution)
+	if runErr == nil && caches != nil {
+		executor.SaveCaches(a.cfg.Caches, as.JobID, as.Caches, caches, execution)
+	}
+	if err := a.workspaces.Release(ws, runErr == nil); err != nil {
+		log.Printf("Failed to release workspace of run %s: %v", as.RunID, err)
+	}
+
+	result := agents.RunResult{
+		ExitCode:    res.ExitCode,
+		Stdout:      res.Output,
+		Stderr:      res.Error,
+		Metadata:    res.Metadata,
+		Artifacts:   res.Artifacts,
+		Sandbox:     profile,
+		SourceSHA:   sha,
+		Caches:      caches,
+		TestResults: tests,
+		Plan:        plan,
+	}
+	if runErr != nil {
+		result.Error = runErr.Error()
+		result.FailureReason = limits.ReasonOf(runErr)
+	}
+	return result
+}
+
+// failed is the result of a run that could not be started
+func failed(err error) agents.RunResult {
+	return agents.RunResult{ExitCode: -1, Stderr: err.Error(), Error: err.Error()}
+}
+
+// logStream buffers a run's output and periodically sends it to the
+// server. Sending is best effort: the final result carries the complete
+// output. lost is called when the server reports that the run's lease is
+// gone.
+type logStream struct {
+	client *Client
+	runID  string
+	lost   func()
+
+	mu             sync.Mutex
+	stdout, stderr bytes.Buffer
+}
+
+// writer returns a writer appending to one of the stream's buffers
+func (s *logStream) writer(buf *bytes.Buffer) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return buf.Write(p)
+	})
+}
+
+// run flushes the stream every interval until ctx is cancelled
+func (s *logStream) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush sends the buffered output
+func (s *logStream) flush(ctx context.Context) {
+	s.mu.Lock()
+	chunk := agents.LogChunk{Stdout: takeComplete(&s.stdout), Stderr: takeComplete(&s.stderr)}
+	s.mu.Unlock()
+	if chunk.Stdout == "" && chunk.Stderr == "" {
+		return
+	}
+
+	err := s.client.SendLogs(ctx, s.runID, chunk)
+	switch {
+	case errors.Is(err, ErrLeaseLost):
+		log.Printf("Lost the lease on run %s, cancelling it", s.runID)
+		s.lost()
+	case err != nil && ctx.Err() == nil:
+		log.Printf("Failed to stream output of run %s: %v", s.runID, err)
+	}
+}
+
+// takeComplete drains buf except for a trailing incomplete UTF-8 sequence,
+// which is kept for the next chunk
+func takeComplete(buf *bytes.Buffer) string {
+	b := buf.Bytes()
+	n := len(b)
+	for i := n - 1; i >= 0 && i >= n-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				n = i
+			}
+			break
+		}
+	}
+	return string(buf.Next(n))
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/api/agents"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/db/migrate"
+	"github.com/klauern/gopher-tower/internal/db/migrations"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/plugin/script"
+	"github.com/klauern/gopher-tower/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+const registrationToken = "s3cret"
+
+// testServer is an in-process Gopher Tower server with the agent API
+type testServer struct {
+	queries     *db.Queries
+	executor    *executor.Executor
+	attachments *attachments.Store
+	url         string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	require.NoError(t, migrate.MigrateDB(path, migrations.Files))
+	conn, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	workspaces, err := workspace.NewManager(workspace.Config{Root: filepath.Join(t.TempDir(), "server")})
+	require.NoError(t, err)
+	store, err := attachments.NewStore(filepath.Join(t.TempDir(), "attachments"), 0)
+	require.NoError(t, err)
+	queries := db.New(conn)
+	e := executor.New(queries, newRegistry(t), workspaces, executor.Config{LeaseTTL: time.Second, Attachments: store})
+	t.Cleanup(e.Shutdown)
+
+	router := chi.NewRouter()
+	router.Route("/api", func(r chi.Router) {
+		agents.NewHandler(agents.NewService(queries, e, agents.Config{RegistrationToken: registrationToken})).RegisterRoutes(r)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return &testServer{queries: queries, executor: e, attachments: store, url: server.URL}
+}
+
+func newRegistry(t *testing.T) plugin.PluginRegistry {
+	t.Helper()
+	registry := plugin.NewRegistry()
+	require.NoError(t, registry.Register(script.New()))
+	return registry
+}
+
+// startRun queues a run of a new script job requiring labels
+func (s *testServer) startRun(t *testing.T, body string, labels string) db.JobRun {
+	t.Helper()
+	ctx := context.Background()
+	job, err := s.queries.CreateJob(ctx, db.CreateJobParams{
+		ID:           uuid.New().String(),
+		Name:         "remote",
+		Status:       "pending",
+		Plugin:       db.StringToNullString("script"),
+		PluginConfig: db.StringToNullString(`{"interpreter": "sh", "body": ` + body + `}`),
+		AgentLabels:  db.StringToNullString(labels),
+	})
+	require.NoError(t, err)
+	run, err := s.executor.Start(ctx, job.ID)
+	require.NoError(t, err)
+	return run
+}
+
+func (s *testServer) getRun(t *testing.T, id string) db.JobRun {
+	t.Helper()
+	run, err := s.queries.GetJobRun(context.Background(), id)
+	require.NoError(t, err)
+	return run
+}
+
+// startAgent runs an agent against the server until the test ends
+func (s *testServer) startAgent(t *testing.T, labels ...string) {
+	t.Helper()
+	workspaces, err := workspace.NewManager(workspace.Config{
+		Root:   filepath.Join(t.TempDir(), "agent"),
+		Policy: workspace.PolicyDelete,
+	})
+	require.NoError(t, err)
+	a := New(Config{
+		Server:            s.url,
+		Token:             registrationToken,
+		Name:              "test-agent",
+		Labels:            labels,
+		PollWait:          time.Second,
+		HeartbeatInterval: 100 * time.Millisecond,
+		LogFlushInterval:  20 * time.Millisecond,
+	}, newRegistry(t), workspaces)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestAgent(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	t.Run("passes inputs", func(t *testing.T) {
+		s := newTestServer(t)
+		s.startAgent(t, "linux")
+		ctx := context.Background()
+		job, err := s.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "remote",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("script"),
+			PluginConfig: db.StringToNullString(`{"interpreter": "sh", "body": "echo $INPUT_REGION; cat $GOPHER_TOWER_INPUTS_FILE"}`),
+			AgentLabels:  db.StringToNullString(`["linux"]`),
+			InputSchema:  db.StringToNullString(`{"type": "object", "properties": {"region": {"type": "string"}}}`),
+		})
+		require.NoError(t, err)
+		run, err := s.executor.StartWithInputs(ctx, job.ID, []byte(`{"region": "eu-west-1"}`))
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return s.getRun(t, run.ID).FinishedAt.Valid
+		}, 10*time.Second, 20*time.Millisecond)
+		done := s.getRun(t, run.ID)
+		assert.Equal(t, executor.StatusComplete, done.Status)
+		assert.Equal(t, "eu-west-1\n{\"region\":\"eu-west-1\"}", done.Stdout.String)
+	})
+
+	t.Run("passes stdin and input files", func(t *testing.T) {
+		s := newTestServer(t)
+		s.startAgent(t, "linux")
+		ctx := context.Background()
+		job, err := s.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "remote",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("script"),
+			PluginConfig: db.StringToNullString(`{"interpreter": "sh", "body": "cat; cat data/input.txt"}`),
+			AgentLabels:  db.StringToNullString(`["linux"]`),
+		})
+		require.NoError(t, err)
+		id := uuid.New().String()
+		size, sum, err := s.attachments.Save(id, strings.NewReader("from a file\n"))
+		require.NoError(t, err)
+		_, err = s.queries.CreateAttachment(ctx, db.CreateAttachmentParams{
+			ID:       id,
+			Filename: "input.txt",
+			FilePath: id,
+			FileSize: size,
+			MimeType: "text/plain",
+			JobID:    db.StringToNullString(job.ID),
+			Sha256:   db.StringToNullString(sum),
+		})
+		require.NoError(t, err)
+		content := "from stdin\n"
+		run, err := s.executor.StartRun(ctx, job.ID, executor.RunOptions{
+			Stdin: &attachments.Stdin{Content: &content},
+			Files: []attachments.File{{Attachment: id, Path: "data/input.txt"}},
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return s.getRun(t, run.ID).FinishedAt.Valid
+		}, 10*time.Second, 20*time.Millisecond)
+		done := s.getRun(t, run.ID)
+		assert.Equal(t, executor.StatusComplete, done.Status, done.Stderr.String)
+		assert.Equal(t, "from stdin\nfrom a file\n", done.Stdout.String)
+	})
+
+	t.Run("runs matching jobs", func(t *testing.T) {
+		s := newTestServer(t)
+		s.startAgent(t, "linux", "docker")
+		run := s.startRun(t, `"echo \"hello from $(basename $PWD)\""`, `["linux"]`)
+
+		require.Eventually(t, func() bool {
+			return s.getRun(t, run.ID).FinishedAt.Valid
+		}, 10*time.Second, 20*time.Millisecond)
+		done := s.getRun(t, run.ID)
+		assert.Equal(t, executor.StatusComplete, done.Status)
+		assert.Equal(t, "hello from work\n", done.Stdout.String)
+		assert.True(t, done.AgentID.Valid)
+
+		job, err := s.queries.GetJob(context.Background(), run.JobID)
+		require.NoError(t, err)
+		assert.Equal(t, "complete", job.Status)
+
+		registered, err := s.queries.ListAgents(context.Background())
+		require.NoError(t, err)
+		require.Len(t, registered, 1)
+		assert.Equal(t, done.AgentID.String, registered[0].ID)
+		assert.True(t, registered[0].LastHeartbeatAt.Valid)
+	})
+
+	t.Run("streams output while running", func(t *testing.T) {
+		s := newTestServer(t)
+		s.startAgent(t, "linux")
+		run := s.startRun(t, `"echo first; sleep 1; echo second"`, `["linux"]`)
+
+		require.Eventually(t, func() bool {
+			r := s.getRun(t, run.ID)
+			return r.Status == executor.StatusRunning && r.Stdout.String == "first\n"
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool {
+			return s.getRun(t, run.ID).FinishedAt.Valid
+		}, 10*time.Second, 20*time.Millisecond)
+		assert.Equal(t, "first\nsecond\n", s.getRun(t, run.ID).Stdout.String)
+	})
+
+	t.Run("reports failures", func(t *testing.T) {
+		s := newTestServer(t)
+		s.startAgent(t, "linux")
+		run := s.startRun(t, `"echo oops >&2; exit 3"`, `["linux"]`)
+
+		require.Eventually(t, func() bool {
+			return s.getRun(t, run.ID).FinishedAt.Valid
+		}, 10*time.Second, 20*time.Millisecond)
+		done := s.getRun(t, run.ID)
+		assert.Equal(t, executor.StatusFailed, done.Status)
+		assert.Equal(t, int64(3), done.ExitCode.Int64)
+		assert.Equal(t, "oops\n", done.Stderr.String)
+	})
+
+	t.Run("leaves jobs it cannot run", func(t *testing.T) {
+		s := newTestServer(t)
+		s.startAgent(t, "linux")
+		run := s.startRun(t, `"true"`, `["windows"]`)
+
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, executor.StatusPending, s.getRun(t, run.ID).Status)
+	})
+
+	t.Run("invalid registration token", func(t *testing.T) {
+		s := newTestServer(t)
+		workspaces, err := workspace.NewManager(workspace.Config{Root: t.TempDir()})
+		require.NoError(t, err)
+		a := New(Config{Server: s.url, Token: "guess", Name: "intruder"}, newRegistry(t), workspaces)
+		err = a.Run(context.Background())
+		assert.True(t, errors.Is(err, ErrUnauthorized), "got %v", err)
+	})
+}
+
+func TestTakeComplete(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("héllo ")
+	buf.Write([]byte("wörld")[:2]) // "w" and the first byte of "ö"
+	assert.Equal(t, "héllo w", takeComplete(&buf))
+	assert.Equal(t, 1, buf.Len())
+
+	buf.Write([]byte("wörld")[2:])
+	assert.Equal(t, "örld", takeComplete(&buf))
+	assert.Zero(t, buf.Len())
+}
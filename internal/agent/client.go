@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/api/agents"
+)
+
+var (
+	ErrUnauthorized = errors.New("server rejected the agent token")
+	ErrLeaseLost    = errors.New("run is no longer leased to this agent")
+)
+
+// Client talks to the agent API of a Gopher Tower server
+type Client struct {
+	server string
+	http   *http.Client
+	token  string
+}
+
+// NewClient creates a client for the server at serverURL. A nil httpClient
+// uses http.DefaultClient.
+func NewClient(serverURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{server: strings.TrimRight(serverURL, "/"), http: httpClient}
+}
+
+// Register registers the agent using the server's registration token. The
+// returned access token is used for all further requests.
+func (c *Client) Register(ctx context.Context, registrationToken string, req agents.RegisterRequest) (*agents.RegisterResponse, error) {
+	var resp agents.RegisterResponse
+	if _, err := c.do(ctx, http.MethodPost, "/api/agents/register", registrationToken, req, &resp); err != nil {
+		return nil, err
+	}
+	c.token = resp.Token
+	return &resp, nil
+}
+
+// Heartbeat renews the leases of the agent's runs
+func (c *Client) Heartbeat(ctx context.Context) (*agents.HeartbeatResponse, error) {
+	var resp agents.HeartbeatResponse
+	if _, err := c.do(ctx, http.MethodPost, "/api/agents/heartbeat", c.token, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Poll waits up to wait for a run, returning nil when none arrived
+func (c *Client) Poll(ctx context.Context, wait time.Duration) (*agents.Assignment, error) {
+	var resp agents.Assignment
+	path := "/api/agents/poll?wait=" + url.QueryEscape(wait.String())
+	status, err := c.do(ctx, http.MethodGet, path, c.token, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNoContent {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+// SendLogs appends output to a run in progress
+func (c *Client) SendLogs(ctx context.Context, runID string, chunk agents.LogChunk) error {
+	_, err := c.do(ctx, http.MethodPost, "/api/agents/runs/"+url.PathEscape(runID)+"/logs", c.token, chunk, nil)
+	return err
+}
+
+// Complete reports the result of a run
+func (c *Client) Complete(ctx context.Context, runID string, result agents.RunResult) error {
+	_, err := c.do(ctx, http.MethodPost, "/api/agents/runs/"+url.PathEscape(runID)+"/result", c.token, result, nil)
+	return err
+}
+
+// OpenAttachment downloads an attachment given to a run. The caller must
+// close the returned contents.
+func (c *Client) OpenAttachment(ctx context.Context, runID, attachmentID string) (io.ReadCloser, error) {
+	path := "/api/agents/runs/" + url.PathEscape(runID) + "/attachments/" + url.PathEscape(attachmentID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do sends a JSON request and decodes a JSON response into out, returning
+// the response status
+func (c *Client) do(ctx context.Context, method, path, token string, in, out interface{}) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, body)
+	if err != nil {
+		return 0, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return resp.StatusCode, err
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// checkStatus maps error responses to errors
+func checkStatus(resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case resp.StatusCode == http.StatusConflict:
+		return ErrLeaseLost
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Package agents lets remote agents register with the server and pull runs.
+
+Agents connect out to the server, so the machines they run on need no
+inbound access. Jobs with agent labels are queued by the executor instead
+of being run on the server; an agent offering all of a job's labels
+claims the run by long-polling, streams its output back while it runs and
+reports the result. Runs given standard input or input files list the
+attachments in their assignment; the agent downloads them while it holds
+the run and verifies their checksums. A claimed run is leased to the agent and the lease is
+renewed by heartbeats; runs of agents that stop heartbeating are requeued
+for another agent.
+
+Authentication:
+
+Agents register with the server-wide registration token
+(GOPHER_TOWER_AGENT_TOKEN) and receive their own access token, which they
+send as a bearer token on every other agent request:
+
+	Authorization: Bearer <token>
+
+API Endpoints:
+
+	POST   /agents/register              - Register an agent (registration token)
+	GET    /agents                       - List agents
+	DELETE /agents/{id}                  - Remove an agent
+	POST   /agents/heartbeat             - Renew the agent's run leases
+	GET    /agents/poll?wait=30s         - Claim a run (204 when none arrives in time)
+	POST   /agents/runs/{run_id}/logs    - Append streamed output to a run
+	POST   /agents/runs/{run_id}/result  - Report the result of a run
+	GET    /agents/runs/{run_id}/attachments/{attachment_id} - Download an attachment of a run
+
+Example Registration:
+
+	POST /agents/register
+	Authorization: Bearer <registration token>
+	{"name": "build-1", "labels": ["linux", "docker"]}
+
+	Response:
+	{
+		"agent": {"id": "...", "name": "build-1", "labels": ["linux", "docker"], ...},
+		"token": "...",
+		"heartbeat_interval_ms": 10000
+	}
+
+Error Handling:
+
+  - 400: Invalid request
+  - 401: Missing or invalid token
+  - 403: Agent registration is disabled
+  - 404: Agent not found, or the attachment was not given to the run
+  - 409: The run is not leased by the agent
+  - 503: The executor is shutting down
+*/
+package agents
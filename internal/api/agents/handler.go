@@ -0,0 +1,270 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Long-poll bounds for GET /agents/poll. MaxPollWait stays below the
+// server's request timeout.
+const (
+	DefaultPollWait = 30 * time.Second
+	MaxPollWait     = 50 * time.Second
+)
+
+// maxRequestBytes bounds the size of log chunks and results sent by agents
+const maxRequestBytes = 16 << 20
+
+// Handler handles HTTP requests for remote agents
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new agent handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the agent routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/agents/register", h.Register)
+	r.Get("/agents", h.ListAgents)
+	r.Delete("/agents/{id}", h.DeleteAgent)
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.authenticate)
+		r.Post("/agents/heartbeat", h.Heartbeat)
+		r.Get("/agents/poll", h.Poll)
+		r.Post("/agents/runs/{runID}/logs", h.AppendLogs)
+		r.Post("/agents/runs/{runID}/result", h.Complete)
+		r.Get("/agents/runs/{runID}/attachments/{attachmentID}", h.GetAttachment)
+	})
+}
+
+type agentKey struct{}
+
+// authenticate resolves the agent from the bearer token of the request
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent, err := h.service.Authenticate(r.Context(), bearerToken(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), agentKey{}, agent)))
+	})
+}
+
+// agentFrom returns the agent authenticated for the request
+func agentFrom(r *http.Request) *AgentResponse {
+	agent, _ := r.Context().Value(agentKey{}).(*AgentResponse)
+	return agent
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer" header
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// runID extracts and validates the run ID path parameter
+func runID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "runID")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid run ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidAgent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrUnauthorized):
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, ErrRegistrationDisabled):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrAgentNotFound), errors.Is(err, ErrAttachmentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrLeaseLost):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// decodeJSON decodes a size-limited JSON request body
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// Register handles agent registration requests
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	resp, err := h.service.Register(r.Context(), bearerToken(r), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// ListAgents handles agent listing requests
+func (h *Handler) ListAgents(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.ListAgents(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DeleteAgent handles agent removal requests
+func (h *Handler) DeleteAgent(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid agent ID format", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteAgent(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Heartbeat handles agent heartbeats
+func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Heartbeat(r.Context(), agentFrom(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Poll hands the agent a run, waiting for one for up to ?wait= (default
+// 30s). It responds with 204 No Content when no run arrived in time.
+func (h *Handler) Poll(w http.ResponseWriter, r *http.Request) {
+	wait := DefaultPollWait
+	if v := r.URL.Query().Get("wait"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			http.Error(w, "Invalid wait parameter", http.StatusBadRequest)
+			return
+		}
+		wait = min(d, MaxPollWait)
+	}
+
+	resp, err := h.service.Poll(r.Context(), agentFrom(r), wait)
+	if err != nil {
+		if r.Context().Err() != nil {
+			// The agent hung up
+			return
+		}
+		writeError(w, err)
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// AppendLogs handles output streamed by an agent
+func (h *Handler) AppendLogs(w http.ResponseWriter, r *http.Request) {
+	id, ok := runID(w, r)
+	if !ok {
+		return
+	}
+	var chunk LogChunk
+	if !decodeJSON(w, r, &chunk) {
+		return
+	}
+
+	if err := h.service.AppendLogs(r.Context(), agentFrom(r), id, chunk); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Complete handles the result of a run reported by an agent
+func (h *Handler) Complete(w http.ResponseWriter, r *http.Request) {
+	id, ok := runID(w, r)
+	if !ok {
+		return
+	}
+	var result RunResult
+	if !decodeJSON(w, r, &result) {
+		return
+	}
+
+	if err := h.service.Complete(r.Context(), agentFrom(r), id, result); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAttachment streams an attachment given to a run held by the agent
+func (h *Handler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := runID(w, r)
+	if !ok {
+		return
+	}
+	attachmentID := chi.URLParam(r, "attachmentID")
+	if _, err := uuid.Parse(attachmentID); err != nil {
+		http.Error(w, "Invalid attachment ID format", http.StatusBadRequest)
+		return
+	}
+
+	f, src, err := h.service.OpenAttachment(r.Context(), agentFrom(r), id, attachmentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(src.Size, 10))
+	if _, err := io.Copy(w, io.LimitReader(f, src.Size)); err != nil {
+		log.Printf("Failed to send attachment %s of run %s: %v", attachmentID, id, err)
+	}
+}
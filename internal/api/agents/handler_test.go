@@ -0,0 +1,233 @@
+package agents
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, method, target, token, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// expectAgent authenticates "agent-token" as the test agent
+func expectAgent(mockService *MockService) *AgentResponse {
+	agent := &AgentResponse{ID: testAgentID, Labels: []string{"linux"}}
+	mockService.EXPECT().Authenticate(gomock.Any(), "agent-token").Return(agent, nil)
+	return agent
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name:  "created",
+			token: registrationToken,
+			body:  `{"name":"build-1","labels":["linux"]}`,
+			setupMock: func(m *MockService) {
+				m.EXPECT().Register(gomock.Any(), registrationToken, RegisterRequest{Name: "build-1", Labels: []string{"linux"}}).
+					Return(&RegisterResponse{Agent: AgentResponse{ID: testAgentID}, Token: "agent-token"}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:  "wrong token",
+			token: "guess",
+			body:  `{"name":"build-1"}`,
+			setupMock: func(m *MockService) {
+				m.EXPECT().Register(gomock.Any(), "guess", gomock.Any()).Return(nil, ErrUnauthorized)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "disabled",
+			body: `{"name":"build-1"}`,
+			setupMock: func(m *MockService) {
+				m.EXPECT().Register(gomock.Any(), "", gomock.Any()).Return(nil, ErrRegistrationDisabled)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid body",
+			body:       `{`,
+			setupMock:  func(m *MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, router := setupHandler(t)
+			tt.setupMock(mockService)
+			w := serve(router, http.MethodPost, "/agents/register", tt.token, tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	mockService, router := setupHandler(t)
+	mockService.EXPECT().Authenticate(gomock.Any(), "").Return(nil, ErrUnauthorized)
+	w := serve(router, http.MethodPost, "/agents/heartbeat", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+}
+
+func TestHeartbeat(t *testing.T) {
+	mockService, router := setupHandler(t)
+	agent := expectAgent(mockService)
+	expires := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockService.EXPECT().Heartbeat(gomock.Any(), agent).Return(&HeartbeatResponse{LeaseExpiresAt: expires}, nil)
+
+	w := serve(router, http.MethodPost, "/agents/heartbeat", "agent-token", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp HeartbeatResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.True(t, expires.Equal(resp.LeaseExpiresAt))
+}
+
+func TestPoll(t *testing.T) {
+	t.Run("assignment", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		agent := expectAgent(mockService)
+		mockService.EXPECT().Poll(gomock.Any(), agent, 5*time.Second).
+			Return(&Assignment{RunID: testRunID, Plugin: "script"}, nil)
+
+		w := serve(router, http.MethodGet, "/agents/poll?wait=5s", "agent-token", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp Assignment
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, testRunID, resp.RunID)
+	})
+
+	t.Run("nothing queued", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		agent := expectAgent(mockService)
+		mockService.EXPECT().Poll(gomock.Any(), agent, DefaultPollWait).Return(nil, nil)
+		w := serve(router, http.MethodGet, "/agents/poll", "agent-token", "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("wait is capped", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		agent := expectAgent(mockService)
+		mockService.EXPECT().Poll(gomock.Any(), agent, MaxPollWait).Return(nil, nil)
+		w := serve(router, http.MethodGet, "/agents/poll?wait=1h", "agent-token", "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid wait", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		expectAgent(mockService)
+		w := serve(router, http.MethodGet, "/agents/poll?wait=soon", "agent-token", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRunReports(t *testing.T) {
+	t.Run("logs", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		agent := expectAgent(mockService)
+		mockService.EXPECT().AppendLogs(gomock.Any(), agent, testRunID, LogChunk{Stdout: "hi\n"}).Return(nil)
+		w := serve(router, http.MethodPost, "/agents/runs/"+testRunID+"/logs", "agent-token", `{"stdout":"hi\n"}`)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("lease lost", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		agent := expectAgent(mockService)
+		mockService.EXPECT().Complete(gomock.Any(), agent, testRunID, RunResult{ExitCode: 1}).Return(ErrLeaseLost)
+		w := serve(router, http.MethodPost, "/agents/runs/"+testRunID+"/result", "agent-token", `{"exit_code":1}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid run id", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		expectAgent(mockService)
+		w := serve(router, http.MethodPost, "/agents/runs/nope/result", "agent-token", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetAttachment(t *testing.T) {
+	const attachmentID = "5c4b3a29-1807-4f6e-8d5c-4b3a29180706"
+	target := "/agents/runs/" + testRunID + "/attachments/" + attachmentID
+
+	t.Run("downloaded", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		agent := expectAgent(mockService)
+		path := filepath.Join(t.TempDir(), "contents")
+		require.NoError(t, os.WriteFile(path, []byte("a,b\n"), 0o644))
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		mockService.EXPECT().OpenAttachment(gomock.Any(), agent, testRunID, attachmentID).
+			Return(f, &attachments.Source{Attachment: attachmentID, Size: 4}, nil)
+
+		w := serve(router, http.MethodGet, target, "agent-token", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "4", w.Header().Get("Content-Length"))
+		assert.Equal(t, "a,b\n", w.Body.String())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		agent := expectAgent(mockService)
+		mockService.EXPECT().OpenAttachment(gomock.Any(), agent, testRunID, attachmentID).Return(nil, nil, ErrAttachmentNotFound)
+		w := serve(router, http.MethodGet, target, "agent-token", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid attachment id", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		expectAgent(mockService)
+		w := serve(router, http.MethodGet, "/agents/runs/"+testRunID+"/attachments/nope", "agent-token", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestListAndDeleteAgents(t *testing.T) {
+	mockService, router := setupHandler(t)
+	mockService.EXPECT().ListAgents(gomock.Any()).Return([]AgentResponse{{ID: testAgentID, Name: "build-1"}}, nil)
+	w := serve(router, http.MethodGet, "/agents", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var agents []AgentResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&agents))
+	assert.Len(t, agents, 1)
+
+	mockService.EXPECT().DeleteAgent(gomock.Any(), testAgentID).Return(ErrAgentNotFound)
+	w = serve(router, http.MethodDelete, "/agents/"+testAgentID, "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(router, http.MethodDelete, "/agents/nope", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,133 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/agents (interfaces: Dispatcher)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_dispatcher_test.go -package=agents github.com/klauern/gopher-tower/internal/api/agents Dispatcher
+//
+
+// Package agents is a generated GoMock package.
+package agents
+
+import (
+	context "context"
+	os "os"
+	reflect "reflect"
+	time "time"
+
+	attachments "github.com/klauern/gopher-tower/internal/attachments"
+	executor "github.com/klauern/gopher-tower/internal/executor"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDispatcher is a mock of Dispatcher interface.
+type MockDispatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockDispatcherMockRecorder
+	isgomock struct{}
+}
+
+// MockDispatcherMockRecorder is the mock recorder for MockDispatcher.
+type MockDispatcherMockRecorder struct {
+	mock *MockDispatcher
+}
+
+// NewMockDispatcher creates a new mock instance.
+func NewMockDispatcher(ctrl *gomock.Controller) *MockDispatcher {
+	mock := &MockDispatcher{ctrl: ctrl}
+	mock.recorder = &MockDispatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDispatcher) EXPECT() *MockDispatcherMockRecorder {
+	return m.recorder
+}
+
+// AppendLogs mocks base method.
+func (m *MockDispatcher) AppendLogs(ctx context.Context, agentID, runID, stdout, stderr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendLogs", ctx, agentID, runID, stdout, stderr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendLogs indicates an expected call of AppendLogs.
+func (mr *MockDispatcherMockRecorder) AppendLogs(ctx, agentID, runID, stdout, stderr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendLogs", reflect.TypeOf((*MockDispatcher)(nil).AppendLogs), ctx, agentID, runID, stdout, stderr)
+}
+
+// Claim mocks base method.
+func (m *MockDispatcher) Claim(ctx context.Context, agentID string, labels []string, wait time.Duration) (*executor.Assignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, agentID, labels, wait)
+	ret0, _ := ret[0].(*executor.Assignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockDispatcherMockRecorder) Claim(ctx, agentID, labels, wait any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockDispatcher)(nil).Claim), ctx, agentID, labels, wait)
+}
+
+// Complete mocks base method.
+func (m *MockDispatcher) Complete(ctx context.Context, agentID, runID string, res executor.RemoteResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, agentID, runID, res)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockDispatcherMockRecorder) Complete(ctx, agentID, runID, res any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockDispatcher)(nil).Complete), ctx, agentID, runID, res)
+}
+
+// Heartbeat mocks base method.
+func (m *MockDispatcher) Heartbeat(ctx context.Context, agentID string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Heartbeat", ctx, agentID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Heartbeat indicates an expected call of Heartbeat.
+func (mr *MockDispatcherMockRecorder) Heartbeat(ctx, agentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Heartbeat", reflect.TypeOf((*MockDispatcher)(nil).Heartbeat), ctx, agentID)
+}
+
+// LeaseTTL mocks base method.
+func (m *MockDispatcher) LeaseTTL() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaseTTL")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// LeaseTTL indicates an expected call of LeaseTTL.
+func (mr *MockDispatcherMockRecorder) LeaseTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaseTTL", reflect.TypeOf((*MockDispatcher)(nil).LeaseTTL))
+}
+
+// OpenAttachment mocks base method.
+func (m *MockDispatcher) OpenAttachment(ctx context.Context, agentID, runID, attachmentID string) (*os.File, attachments.Source, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenAttachment", ctx, agentID, runID, attachmentID)
+	ret0, _ := ret[0].(*os.File)
+	ret1, _ := ret[1].(attachments.Source)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenAttachment indicates an expected call of OpenAttachment.
+func (mr *MockDispatcherMockRecorder) OpenAttachment(ctx, agentID, runID, attachmentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenAttachment", reflect.TypeOf((*MockDispatcher)(nil).OpenAttachment), ctx, agentID, runID, attachmentID)
+}
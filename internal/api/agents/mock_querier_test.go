@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/agents (interfaces: AgentQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=agents github.com/klauern/gopher-tower/internal/api/agents AgentQuerier
+//
+
+// Package agents is a generated GoMock package.
+package agents
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAgentQuerier is a mock of AgentQuerier interface.
+type MockAgentQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgentQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockAgentQuerierMockRecorder is the mock recorder for MockAgentQuerier.
+type MockAgentQuerierMockRecorder struct {
+	mock *MockAgentQuerier
+}
+
+// NewMockAgentQuerier creates a new mock instance.
+func NewMockAgentQuerier(ctrl *gomock.Controller) *MockAgentQuerier {
+	mock := &MockAgentQuerier{ctrl: ctrl}
+	mock.recorder = &MockAgentQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgentQuerier) EXPECT() *MockAgentQuerierMockRecorder {
+	return m.recorder
+}
+
+// CreateAgent mocks base method.
+func (m *MockAgentQuerier) CreateAgent(ctx context.Context, arg db.CreateAgentParams) (db.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAgent", ctx, arg)
+	ret0, _ := ret[0].(db.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAgent indicates an expected call of CreateAgent.
+func (mr *MockAgentQuerierMockRecorder) CreateAgent(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAgent", reflect.TypeOf((*MockAgentQuerier)(nil).CreateAgent), ctx, arg)
+}
+
+// DeleteAgent mocks base method.
+func (m *MockAgentQuerier) DeleteAgent(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAgent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAgent indicates an expected call of DeleteAgent.
+func (mr *MockAgentQuerierMockRecorder) DeleteAgent(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAgent", reflect.TypeOf((*MockAgentQuerier)(nil).DeleteAgent), ctx, id)
+}
+
+// GetAgent mocks base method.
+func (m *MockAgentQuerier) GetAgent(ctx context.Context, id string) (db.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAgent", ctx, id)
+	ret0, _ := ret[0].(db.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAgent indicates an expected call of GetAgent.
+func (mr *MockAgentQuerierMockRecorder) GetAgent(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgent", reflect.TypeOf((*MockAgentQuerier)(nil).GetAgent), ctx, id)
+}
+
+// GetAgentByTokenHash mocks base method.
+func (m *MockAgentQuerier) GetAgentByTokenHash(ctx context.Context, tokenHash string) (db.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAgentByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(db.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAgentByTokenHash indicates an expected call of GetAgentByTokenHash.
+func (mr *MockAgentQuerierMockRecorder) GetAgentByTokenHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgentByTokenHash", reflect.TypeOf((*MockAgentQuerier)(nil).GetAgentByTokenHash), ctx, tokenHash)
+}
+
+// ListAgents mocks base method.
+func (m *MockAgentQuerier) ListAgents(ctx context.Context) ([]db.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAgents", ctx)
+	ret0, _ := ret[0].([]db.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAgents indicates an expected call of ListAgents.
+func (mr *MockAgentQuerierMockRecorder) ListAgents(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAgents", reflect.TypeOf((*MockAgentQuerier)(nil).ListAgents), ctx)
+}
+
+// RecordAgentHeartbeat mocks base method.
+func (m *MockAgentQuerier) RecordAgentHeartbeat(ctx context.Context, arg db.RecordAgentHeartbeatParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAgentHeartbeat", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAgentHeartbeat indicates an expected call of RecordAgentHeartbeat.
+func (mr *MockAgentQuerierMockRecorder) RecordAgentHeartbeat(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAgentHeartbeat", reflect.TypeOf((*MockAgentQuerier)(nil).RecordAgentHeartbeat), ctx, arg)
+}
@@ -0,0 +1,177 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/agents (interfaces: Service)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_service_test.go -package=agents github.com/klauern/gopher-tower/internal/api/agents Service
+//
+
+// Package agents is a generated GoMock package.
+package agents
+
+import (
+	context "context"
+	os "os"
+	reflect "reflect"
+	time "time"
+
+	attachments "github.com/klauern/gopher-tower/internal/attachments"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// AppendLogs mocks base method.
+func (m *MockService) AppendLogs(ctx context.Context, agent *AgentResponse, runID string, chunk LogChunk) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendLogs", ctx, agent, runID, chunk)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendLogs indicates an expected call of AppendLogs.
+func (mr *MockServiceMockRecorder) AppendLogs(ctx, agent, runID, chunk any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendLogs", reflect.TypeOf((*MockService)(nil).AppendLogs), ctx, agent, runID, chunk)
+}
+
+// Authenticate mocks base method.
+func (m *MockService) Authenticate(ctx context.Context, token string) (*AgentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, token)
+	ret0, _ := ret[0].(*AgentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockServiceMockRecorder) Authenticate(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockService)(nil).Authenticate), ctx, token)
+}
+
+// Complete mocks base method.
+func (m *MockService) Complete(ctx context.Context, agent *AgentResponse, runID string, result RunResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, agent, runID, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockServiceMockRecorder) Complete(ctx, agent, runID, result any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockService)(nil).Complete), ctx, agent, runID, result)
+}
+
+// DeleteAgent mocks base method.
+func (m *MockService) DeleteAgent(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAgent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAgent indicates an expected call of DeleteAgent.
+func (mr *MockServiceMockRecorder) DeleteAgent(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAgent", reflect.TypeOf((*MockService)(nil).DeleteAgent), ctx, id)
+}
+
+// Heartbeat mocks base method.
+func (m *MockService) Heartbeat(ctx context.Context, agent *AgentResponse) (*HeartbeatResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Heartbeat", ctx, agent)
+	ret0, _ := ret[0].(*HeartbeatResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Heartbeat indicates an expected call of Heartbeat.
+func (mr *MockServiceMockRecorder) Heartbeat(ctx, agent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Heartbeat", reflect.TypeOf((*MockService)(nil).Heartbeat), ctx, agent)
+}
+
+// ListAgents mocks base method.
+func (m *MockService) ListAgents(ctx context.Context) ([]AgentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAgents", ctx)
+	ret0, _ := ret[0].([]AgentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAgents indicates an expected call of ListAgents.
+func (mr *MockServiceMockRecorder) ListAgents(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAgents", reflect.TypeOf((*MockService)(nil).ListAgents), ctx)
+}
+
+// OpenAttachment mocks base method.
+func (m *MockService) OpenAttachment(ctx context.Context, agent *AgentResponse, runID, attachmentID string) (*os.File, *attachments.Source, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenAttachment", ctx, agent, runID, attachmentID)
+	ret0, _ := ret[0].(*os.File)
+	ret1, _ := ret[1].(*attachments.Source)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenAttachment indicates an expected call of OpenAttachment.
+func (mr *MockServiceMockRecorder) OpenAttachment(ctx, agent, runID, attachmentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenAttachment", reflect.TypeOf((*MockService)(nil).OpenAttachment), ctx, agent, runID, attachmentID)
+}
+
+// Poll mocks base method.
+func (m *MockService) Poll(ctx context.Context, agent *AgentResponse, wait time.Duration) (*Assignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Poll", ctx, agent, wait)
+	ret0, _ := ret[0].(*Assignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Poll indicates an expected call of Poll.
+func (mr *MockServiceMockRecorder) Poll(ctx, agent, wait any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Poll", reflect.TypeOf((*MockService)(nil).Poll), ctx, agent, wait)
+}
+
+// Register mocks base method.
+func (m *MockService) Register(ctx context.Context, registrationToken string, req RegisterRequest) (*RegisterResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, registrationToken, req)
+	ret0, _ := ret[0].(*RegisterResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockServiceMockRecorder) Register(ctx, registrationToken, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockService)(nil).Register), ctx, registrationToken, req)
+}
@@ -0,0 +1,108 @@
+package agents
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/testreport"
+)
+
+// RegisterRequest represents the request to register an agent
+type RegisterRequest struct {
+	Name     string   `json:"name"`
+	Labels   []string `json:"labels"`
+	Hostname string   `json:"hostname,omitempty"`
+	Version  string   `json:"version,omitempty"`
+}
+
+// Validate checks if the registration request is valid
+func (r *RegisterRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	for _, label := range r.Labels {
+		if strings.TrimSpace(label) == "" {
+			return errors.New("labels must not be empty")
+		}
+	}
+	return nil
+}
+
+// AgentResponse represents an agent in responses
+type AgentResponse struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Labels          []string   `json:"labels"`
+	Hostname        string     `json:"hostname,omitempty"`
+	Version         string     `json:"version,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastHeartbeatAt *time.Time `json:"last_heartbeat_at,omitempty"`
+	// Online reports whether the agent has sent a heartbeat within the
+	// lease TTL
+	Online bool `json:"online"`
+}
+
+// RegisterResponse is returned to a newly registered agent
+type RegisterResponse struct {
+	Agent AgentResponse `json:"agent"`
+	// Token authenticates the agent's further requests. It is only
+	// returned once.
+	Token string `json:"token"`
+	// HeartbeatIntervalMS is how often the agent should send heartbeats
+	HeartbeatIntervalMS int64 `json:"heartbeat_interval_ms"`
+}
+
+// HeartbeatResponse is returned for a heartbeat
+type HeartbeatResponse struct {
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+}
+
+// Assignment is a run handed to an agent
+type Assignment struct {
+	RunID          string                 `json:"run_id"`
+	JobID          string                 `json:"job_id"`
+	RunNumber      int64                  `json:"run_number"`
+	Plugin         string                 `json:"plugin"`
+	Config         map[string]interface{} `json:"config,omitempty"`
+	Sandbox        bool                   `json:"sandbox,omitempty"`
+	Limits         *limits.Limits         `json:"limits,omitempty"`
+	Inputs         json.RawMessage        `json:"inputs,omitempty"`
+	Matrix         map[string]interface{} `json:"matrix,omitempty"`
+	Source         *source.Source         `json:"source,omitempty"`
+	Caches         []cache.Entry          `json:"caches,omitempty"`
+	TestReports    []string               `json:"test_reports,omitempty"`
+	Stdin          *attachments.Source    `json:"stdin,omitempty"`
+	Files          []attachments.Source   `json:"files,omitempty"`
+	LeaseExpiresAt time.Time              `json:"lease_expires_at"`
+}
+
+// LogChunk carries output streamed by an agent while a run is in progress
+type LogChunk struct {
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// RunResult is the outcome of a run reported by an agent. Stdout and
+// Stderr hold the complete output and replace what was streamed.
+type RunResult struct {
+	ExitCode      int                    `json:"exit_code"`
+	Stdout        string                 `json:"stdout,omitempty"`
+	Stderr        string                 `json:"stderr,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	FailureReason string                 `json:"failure_reason,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Artifacts     []string               `json:"artifacts,omitempty"`
+	Sandbox       *sandbox.Profile       `json:"sandbox,omitempty"`
+	SourceSHA     string                 `json:"source_sha,omitempty"`
+	Caches        []cache.Result         `json:"caches,omitempty"`
+	TestResults   []testreport.Result    `json:"test_results,omitempty"`
+	Plan          *plugin.Plan           `json:"plan,omitempty"`
+}
@@ -0,0 +1,301 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=agents github.com/klauern/gopher-tower/internal/api/agents AgentQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=agents github.com/klauern/gopher-tower/internal/api/agents Service
+//go:generate go tool mockgen -destination=mock_dispatcher_test.go -package=agents github.com/klauern/gopher-tower/internal/api/agents Dispatcher
+
+package agents
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+var (
+	ErrInvalidAgent         = errors.New("invalid agent data")
+	ErrAgentNotFound        = errors.New("agent not found")
+	ErrUnauthorized         = errors.New("invalid agent token")
+	ErrRegistrationDisabled = errors.New("agent registration is disabled")
+	ErrLeaseLost            = errors.New("run is not leased by this agent")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrUnavailable          = errors.New("executor unavailable")
+)
+
+// AgentQuerier defines the interface for agent-related database operations
+type AgentQuerier interface {
+	CreateAgent(ctx context.Context, arg db.CreateAgentParams) (db.Agent, error)
+	GetAgent(ctx context.Context, id string) (db.Agent, error)
+	GetAgentByTokenHash(ctx context.Context, tokenHash string) (db.Agent, error)
+	ListAgents(ctx context.Context) ([]db.Agent, error)
+	RecordAgentHeartbeat(ctx context.Context, arg db.RecordAgentHeartbeatParams) error
+	DeleteAgent(ctx context.Context, id string) error
+}
+
+// Dispatcher hands queued runs to agents; it is implemented by
+// executor.Executor
+type Dispatcher interface {
+	Claim(ctx context.Context, agentID string, labels []string, wait time.Duration) (*executor.Assignment, error)
+	Heartbeat(ctx context.Context, agentID string) (time.Time, error)
+	AppendLogs(ctx context.Context, agentID, runID, stdout, stderr string) error
+	Complete(ctx context.Context, agentID, runID string, res executor.RemoteResult) error
+	OpenAttachment(ctx context.Context, agentID, runID, attachmentID string) (*os.File, attachments.Source, error)
+	LeaseTTL() time.Duration
+}
+
+// Config holds the agent service settings
+type Config struct {
+	// RegistrationToken must be presented by agents to register; agent
+	// registration is disabled when it is empty
+	RegistrationToken string
+}
+
+// Service provides agent operations
+type Service interface {
+	Register(ctx context.Context, registrationToken string, req RegisterRequest) (*RegisterResponse, error)
+	// Authenticate returns the agent owning an access token
+	Authenticate(ctx context.Context, token string) (*AgentResponse, error)
+	Heartbeat(ctx context.Context, agent *AgentResponse) (*HeartbeatResponse, error)
+	// Poll waits up to wait for a run matching the agent's labels, returning
+	// nil when none arrives in time
+	Poll(ctx context.Context, agent *AgentResponse, wait time.Duration) (*Assignment, error)
+	AppendLogs(ctx context.Context, agent *AgentResponse, runID string, chunk LogChunk) error
+	Complete(ctx context.Context, agent *AgentResponse, runID string, result RunResult) error
+	// OpenAttachment opens the contents of an attachment given to a run
+	// held by the agent
+	OpenAttachment(ctx context.Context, agent *AgentResponse, runID, attachmentID string) (*os.File, *attachments.Source, error)
+	ListAgents(ctx context.Context) ([]AgentResponse, error)
+	DeleteAgent(ctx context.Context, id string) error
+}
+
+// agentService implements the Service interface
+type agentService struct {
+	queries    AgentQuerier
+	dispatcher Dispatcher
+	cfg        Config
+	now        func() time.Time
+}
+
+// NewService creates a new agent service
+func NewService(queries AgentQuerier, dispatcher Dispatcher, cfg Config) Service {
+	return &agentService{queries: queries, dispatcher: dispatcher, cfg: cfg, now: time.Now}
+}
+
+// Register creates an agent and issues its access token
+func (s *agentService) Register(ctx context.Context, registrationToken string, req RegisterRequest) (*RegisterResponse, error) {
+	if s.cfg.RegistrationToken == "" {
+		return nil, ErrRegistrationDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(registrationToken), []byte(s.cfg.RegistrationToken)) != 1 {
+		return nil, ErrUnauthorized
+	}
+	if err := req.Validate(); err != nil {
+		return nil, ErrInvalidAgent
+	}
+	labels, err := json.Marshal(append([]string{}, req.Labels...))
+	if err != nil {
+		return nil, ErrInvalidAgent
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.queries.CreateAgent(ctx, db.CreateAgentParams{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Labels:    string(labels),
+		Hostname:  db.StringToNullString(req.Hostname),
+		Version:   db.StringToNullString(req.Version),
+		TokenHash: hashToken(token),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RegisterResponse{
+		Agent:               *s.toAgentResponse(agent),
+		Token:               token,
+		HeartbeatIntervalMS: (s.dispatcher.LeaseTTL() / 3).Milliseconds(),
+	}, nil
+}
+
+// Authenticate returns the agent owning an access token
+func (s *agentService) Authenticate(ctx context.Context, token string) (*AgentResponse, error) {
+	if token == "" {
+		return nil, ErrUnauthorized
+	}
+	agent, err := s.queries.GetAgentByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	return s.toAgentResponse(agent), nil
+}
+
+// Heartbeat records that the agent is alive and renews its run leases
+func (s *agentService) Heartbeat(ctx context.Context, agent *AgentResponse) (*HeartbeatResponse, error) {
+	if err := s.queries.RecordAgentHeartbeat(ctx, db.RecordAgentHeartbeatParams{
+		LastHeartbeatAt: sql.NullTime{Time: s.now().UTC(), Valid: true},
+		ID:              agent.ID,
+	}); err != nil {
+		return nil, err
+	}
+	expires, err := s.dispatcher.Heartbeat(ctx, agent.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &HeartbeatResponse{LeaseExpiresAt: expires}, nil
+}
+
+// Poll claims a run matching the agent's labels
+func (s *agentService) Poll(ctx context.Context, agent *AgentResponse, wait time.Duration) (*Assignment, error) {
+	a, err := s.dispatcher.Claim(ctx, agent.ID, agent.Labels, wait)
+	if err != nil {
+		if errors.Is(err, executor.ErrShuttingDown) {
+			return nil, ErrUnavailable
+		}
+		return nil, err
+	}
+	if a == nil {
+		return nil, nil
+	}
+	resp := &Assignment{
+		RunID:          a.Run.ID,
+		JobID:          a.Run.JobID,
+		RunNumber:      a.Run.RunNumber,
+		Plugin:         a.Job.Plugin.String,
+		Config:         a.Config,
+		Sandbox:        a.Job.Sandbox,
+		Matrix:         executor.DecodeMatrixValues(a.Run.MatrixValues),
+		Source:         a.Source,
+		Caches:         a.Caches,
+		TestReports:    a.TestReports,
+		Stdin:          executor.DecodeStdin(a.Run.Stdin),
+		Files:          executor.DecodeInputFiles(a.Run.InputFiles),
+		LeaseExpiresAt: a.LeaseExpiresAt,
+	}
+	if !a.Limits.IsZero() {
+		resp.Limits = &a.Limits
+	}
+	if a.Run.Inputs.Valid {
+		resp.Inputs = json.RawMessage(a.Run.Inputs.String)
+	}
+	return resp, nil
+}
+
+// AppendLogs appends streamed output to a run held by the agent
+func (s *agentService) AppendLogs(ctx context.Context, agent *AgentResponse, runID string, chunk LogChunk) error {
+	err := s.dispatcher.AppendLogs(ctx, agent.ID, runID, chunk.Stdout, chunk.Stderr)
+	if errors.Is(err, executor.ErrLeaseLost) {
+		return ErrLeaseLost
+	}
+	return err
+}
+
+// Complete records the result of a run held by the agent
+func (s *agentService) Complete(ctx context.Context, agent *AgentResponse, runID string, result RunResult) error {
+	err := s.dispatcher.Complete(ctx, agent.ID, runID, executor.RemoteResult{
+		Result: plugin.JobResult{
+			ExitCode:  result.ExitCode,
+			Output:    result.Stdout,
+			Error:     result.Stderr,
+			Metadata:  result.Metadata,
+			Artifacts: result.Artifacts,
+		},
+		Error:         result.Error,
+		FailureReason: result.FailureReason,
+		Sandbox:       result.Sandbox,
+		SourceSHA:     result.SourceSHA,
+		Caches:        result.Caches,
+		TestResults:   result.TestResults,
+		Plan:          result.Plan,
+	})
+	if errors.Is(err, executor.ErrLeaseLost) {
+		return ErrLeaseLost
+	}
+	return err
+}
+
+// OpenAttachment opens an attachment given to a run held by the agent
+func (s *agentService) OpenAttachment(ctx context.Context, agent *AgentResponse, runID, attachmentID string) (*os.File, *attachments.Source, error) {
+	f, src, err := s.dispatcher.OpenAttachment(ctx, agent.ID, runID, attachmentID)
+	switch {
+	case errors.Is(err, executor.ErrLeaseLost):
+		return nil, nil, ErrLeaseLost
+	case errors.Is(err, executor.ErrNotRunAttachment), errors.Is(err, os.ErrNotExist):
+		return nil, nil, ErrAttachmentNotFound
+	case err != nil:
+		return nil, nil, err
+	}
+	return f, &src, nil
+}
+
+// ListAgents returns all registered agents
+func (s *agentService) ListAgents(ctx context.Context) ([]AgentResponse, error) {
+	agents, err := s.queries.ListAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]AgentResponse, len(agents))
+	for i, agent := range agents {
+		responses[i] = *s.toAgentResponse(agent)
+	}
+	return responses, nil
+}
+
+// DeleteAgent removes an agent, revoking its token
+func (s *agentService) DeleteAgent(ctx context.Context, id string) error {
+	if _, err := s.queries.GetAgent(ctx, id); err != nil {
+		if db.IsNotFound(err) {
+			return ErrAgentNotFound
+		}
+		return err
+	}
+	return s.queries.DeleteAgent(ctx, id)
+}
+
+// toAgentResponse converts a db.Agent to an AgentResponse
+func (s *agentService) toAgentResponse(agent db.Agent) *AgentResponse {
+	resp := &AgentResponse{
+		ID:              agent.ID,
+		Name:            agent.Name,
+		Labels:          []string{},
+		Hostname:        agent.Hostname.String,
+		Version:         agent.Version.String,
+		CreatedAt:       agent.CreatedAt,
+		LastHeartbeatAt: db.NullTimeToTimePtr(agent.LastHeartbeatAt),
+	}
+	_ = json.Unmarshal([]byte(agent.Labels), &resp.Labels)
+	if resp.LastHeartbeatAt != nil {
+		resp.Online = s.now().Sub(*resp.LastHeartbeatAt) < s.dispatcher.LeaseTTL()
+	}
+	return resp
+}
+
+// newToken generates a random agent access token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the digest under which a token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
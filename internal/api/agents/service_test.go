@@ -0,0 +1,261 @@
+package agents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testAgentID       = "0b7f4c1e-2d3a-4b5c-8d9e-0f1a2b3c4d5e"
+	testRunID         = "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"
+	registrationToken = "s3cret"
+)
+
+type serviceTest struct {
+	querier    *MockAgentQuerier
+	dispatcher *MockDispatcher
+	svc        *agentService
+	now        time.Time
+}
+
+func setupService(t *testing.T) *serviceTest {
+	ctrl := gomock.NewController(t)
+	st := &serviceTest{
+		querier:    NewMockAgentQuerier(ctrl),
+		dispatcher: NewMockDispatcher(ctrl),
+		now:        time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	st.svc = NewService(st.querier, st.dispatcher, Config{RegistrationToken: registrationToken}).(*agentService)
+	st.svc.now = func() time.Time { return st.now }
+	st.dispatcher.EXPECT().LeaseTTL().Return(30 * time.Second).AnyTimes()
+	return st
+}
+
+func TestAgentService_Register(t *testing.T) {
+	t.Run("registered", func(t *testing.T) {
+		st := setupService(t)
+		var stored db.CreateAgentParams
+		st.querier.EXPECT().CreateAgent(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateAgentParams) (db.Agent, error) {
+				stored = arg
+				return db.Agent{ID: arg.ID, Name: arg.Name, Labels: arg.Labels, TokenHash: arg.TokenHash}, nil
+			})
+
+		resp, err := st.svc.Register(context.Background(), registrationToken, RegisterRequest{
+			Name:   "build-1",
+			Labels: []string{"linux", "docker"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"linux", "docker"}, resp.Agent.Labels)
+		assert.Equal(t, int64(10000), resp.HeartbeatIntervalMS)
+		assert.Len(t, resp.Token, 64)
+		assert.Equal(t, hashToken(resp.Token), stored.TokenHash, "only the token hash is stored")
+		assert.NotEqual(t, resp.Token, stored.TokenHash)
+	})
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		token   string
+		req     RegisterRequest
+		wantErr error
+	}{
+		{name: "disabled", token: "", req: RegisterRequest{Name: "a"}, wantErr: ErrRegistrationDisabled},
+		{name: "wrong token", cfg: Config{RegistrationToken: registrationToken}, token: "guess", req: RegisterRequest{Name: "a"}, wantErr: ErrUnauthorized},
+		{name: "missing name", cfg: Config{RegistrationToken: registrationToken}, token: registrationToken, wantErr: ErrInvalidAgent},
+		{name: "blank label", cfg: Config{RegistrationToken: registrationToken}, token: registrationToken, req: RegisterRequest{Name: "a", Labels: []string{""}}, wantErr: ErrInvalidAgent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := setupService(t)
+			st.svc.cfg = tt.cfg
+			_, err := st.svc.Register(context.Background(), tt.token, tt.req)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAgentService_Authenticate(t *testing.T) {
+	st := setupService(t)
+	heartbeat := st.now.Add(-5 * time.Second)
+	st.querier.EXPECT().GetAgentByTokenHash(gomock.Any(), hashToken("good")).Return(db.Agent{
+		ID:              testAgentID,
+		Name:            "build-1",
+		Labels:          `["linux"]`,
+		LastHeartbeatAt: sql.NullTime{Time: heartbeat, Valid: true},
+	}, nil)
+	st.querier.EXPECT().GetAgentByTokenHash(gomock.Any(), hashToken("revoked")).Return(db.Agent{}, sql.ErrNoRows)
+
+	agent, err := st.svc.Authenticate(context.Background(), "good")
+	require.NoError(t, err)
+	assert.Equal(t, testAgentID, agent.ID)
+	assert.Equal(t, []string{"linux"}, agent.Labels)
+	assert.True(t, agent.Online)
+
+	_, err = st.svc.Authenticate(context.Background(), "revoked")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = st.svc.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestAgentService_Heartbeat(t *testing.T) {
+	st := setupService(t)
+	expires := st.now.Add(30 * time.Second)
+	st.querier.EXPECT().RecordAgentHeartbeat(gomock.Any(), db.RecordAgentHeartbeatParams{
+		LastHeartbeatAt: sql.NullTime{Time: st.now, Valid: true},
+		ID:              testAgentID,
+	}).Return(nil)
+	st.dispatcher.EXPECT().Heartbeat(gomock.Any(), testAgentID).Return(expires, nil)
+
+	resp, err := st.svc.Heartbeat(context.Background(), &AgentResponse{ID: testAgentID})
+	require.NoError(t, err)
+	assert.Equal(t, expires, resp.LeaseExpiresAt)
+}
+
+func TestAgentService_Poll(t *testing.T) {
+	agent := &AgentResponse{ID: testAgentID, Labels: []string{"linux"}}
+
+	t.Run("assignment", func(t *testing.T) {
+		st := setupService(t)
+		st.dispatcher.EXPECT().Claim(gomock.Any(), testAgentID, []string{"linux"}, time.Second).Return(&executor.Assignment{
+			Run: db.JobRun{
+				ID:         testRunID,
+				JobID:      "job-1",
+				RunNumber:  4,
+				Stdin:      sql.NullString{String: `{"attachment":"a-1","filename":"stdin","inline":true,"size":3,"sha256":"abc"}`, Valid: true},
+				InputFiles: sql.NullString{String: `[{"attachment":"a-2","filename":"in.csv","path":"in.csv","size":5,"sha256":"def"}]`, Valid: true},
+			},
+			Job:    db.Job{ID: "job-1", Plugin: db.StringToNullString("script"), Sandbox: true},
+			Config: map[string]interface{}{"body": "echo hi"},
+			Limits: limits.Limits{Timeout: limits.Duration(time.Minute)},
+		}, nil)
+
+		resp, err := st.svc.Poll(context.Background(), agent, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, testRunID, resp.RunID)
+		assert.Equal(t, int64(4), resp.RunNumber)
+		assert.Equal(t, "script", resp.Plugin)
+		assert.True(t, resp.Sandbox)
+		require.NotNil(t, resp.Limits)
+		assert.Equal(t, limits.Duration(time.Minute), resp.Limits.Timeout)
+		assert.Equal(t, &attachments.Source{Attachment: "a-1", Filename: "stdin", Inline: true, Size: 3, SHA256: "abc"}, resp.Stdin)
+		assert.Equal(t, []attachments.Source{{Attachment: "a-2", Filename: "in.csv", Path: "in.csv", Size: 5, SHA256: "def"}}, resp.Files)
+	})
+
+	t.Run("nothing queued", func(t *testing.T) {
+		st := setupService(t)
+		st.dispatcher.EXPECT().Claim(gomock.Any(), testAgentID, gomock.Any(), gomock.Any()).Return(nil, nil)
+		resp, err := st.svc.Poll(context.Background(), agent, time.Second)
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		st := setupService(t)
+		st.dispatcher.EXPECT().Claim(gomock.Any(), testAgentID, gomock.Any(), gomock.Any()).Return(nil, executor.ErrShuttingDown)
+		_, err := st.svc.Poll(context.Background(), agent, time.Second)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+}
+
+func TestAgentService_Complete(t *testing.T) {
+	st := setupService(t)
+	agent := &AgentResponse{ID: testAgentID}
+	st.dispatcher.EXPECT().Complete(gomock.Any(), testAgentID, testRunID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, res executor.RemoteResult) error {
+			assert.Equal(t, 1, res.Result.ExitCode)
+			assert.Equal(t, "out", res.Result.Output)
+			assert.Equal(t, "boom", res.Error)
+			assert.Equal(t, "oom", res.FailureReason)
+			return nil
+		})
+	require.NoError(t, st.svc.Complete(context.Background(), agent, testRunID, RunResult{
+		ExitCode:      1,
+		Stdout:        "out",
+		Error:         "boom",
+		FailureReason: "oom",
+	}))
+
+	st.dispatcher.EXPECT().Complete(gomock.Any(), testAgentID, testRunID, gomock.Any()).Return(executor.ErrLeaseLost)
+	assert.ErrorIs(t, st.svc.Complete(context.Background(), agent, testRunID, RunResult{}), ErrLeaseLost)
+
+	st.dispatcher.EXPECT().AppendLogs(gomock.Any(), testAgentID, testRunID, "a", "b").Return(executor.ErrLeaseLost)
+	assert.ErrorIs(t, st.svc.AppendLogs(context.Background(), agent, testRunID, LogChunk{Stdout: "a", Stderr: "b"}), ErrLeaseLost)
+}
+
+func TestAgentService_OpenAttachment(t *testing.T) {
+	agent := &AgentResponse{ID: testAgentID}
+	const attachmentID = "5c4b3a29-1807-4f6e-8d5c-4b3a29180706"
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "opened"},
+		{name: "lease lost", err: executor.ErrLeaseLost, wantErr: ErrLeaseLost},
+		{name: "not given to the run", err: executor.ErrNotRunAttachment, wantErr: ErrAttachmentNotFound},
+		{name: "contents removed", err: os.ErrNotExist, wantErr: ErrAttachmentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := setupService(t)
+			var f *os.File
+			if tt.err == nil {
+				var err error
+				f, err = os.Open(os.DevNull)
+				require.NoError(t, err)
+				defer f.Close()
+			}
+			src := attachments.Source{Attachment: attachmentID, Filename: "in.csv", Path: "in.csv"}
+			st.dispatcher.EXPECT().OpenAttachment(gomock.Any(), testAgentID, testRunID, attachmentID).Return(f, src, tt.err)
+
+			got, gotSrc, err := st.svc.OpenAttachment(context.Background(), agent, testRunID, attachmentID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, f, got)
+			assert.Equal(t, &src, gotSrc)
+		})
+	}
+}
+
+func TestAgentService_ListAndDelete(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().ListAgents(gomock.Any()).Return([]db.Agent{
+		{ID: "a", Name: "fresh", Labels: `[]`, LastHeartbeatAt: sql.NullTime{Time: st.now.Add(-time.Second), Valid: true}},
+		{ID: "b", Name: "stale", Labels: `["gpu"]`, LastHeartbeatAt: sql.NullTime{Time: st.now.Add(-time.Hour), Valid: true}},
+		{ID: "c", Name: "never", Labels: `[]`},
+	}, nil)
+
+	agents, err := st.svc.ListAgents(context.Background())
+	require.NoError(t, err)
+	require.Len(t, agents, 3)
+	assert.True(t, agents[0].Online)
+	assert.False(t, agents[1].Online)
+	assert.False(t, agents[2].Online)
+	assert.Equal(t, []string{}, agents[2].Labels)
+
+	st.querier.EXPECT().GetAgent(gomock.Any(), testAgentID).Return(db.Agent{ID: testAgentID}, nil)
+	st.querier.EXPECT().DeleteAgent(gomock.Any(), testAgentID).Return(nil)
+	require.NoError(t, st.svc.DeleteAgent(context.Background(), testAgentID))
+
+	st.querier.EXPECT().GetAgent(gomock.Any(), "missing").Return(db.Agent{}, sql.ErrNoRows)
+	assert.ErrorIs(t, st.svc.DeleteAgent(context.Background(), "missing"), ErrAgentNotFound)
+
+	st.querier.EXPECT().GetAgent(gomock.Any(), "broken").Return(db.Agent{}, errors.New("disk I/O error"))
+	assert.Error(t, st.svc.DeleteAgent(context.Background(), "broken"))
+}
@@ -0,0 +1,49 @@
+/*
+Package approvals exposes the approval gates of job runs over HTTP.
+
+Runs of a job with requires_approval are created in the awaiting_approval
+status and are only queued once a user whose role (users.role) is one of
+the job's approver_roles approves them. A rejected run ends as rejected;
+a run nobody decides on within GOPHER_TOWER_APPROVAL_TTL (24h by default)
+ends as expired. Approving a matrix run queues all of its children.
+
+	AwaitingApproval -> Pending (approved)
+	                 -> Rejected/Expired
+
+Every decision is recorded in the activity log of the run (entity type
+job_run) with the deciding user and their comment.
+
+API Endpoints:
+
+	GET    /jobs/{id}/runs/{number}/approval - Get the approval state and decisions
+	POST   /jobs/{id}/runs/{number}/approve  - Approve a run awaiting approval
+	POST   /jobs/{id}/runs/{number}/reject   - Reject a run awaiting approval
+
+Example Request:
+
+	POST /jobs/{id}/runs/4/approve
+	{"comment": "Change window CHG-1234"}
+
+Example Response:
+
+	{
+		"job_id": "5f0c...",
+		"run_id": "a91b...",
+		"run_number": 4,
+		"status": "pending",
+		"approver_roles": ["admin", "release-manager"],
+		"decisions": [
+			{"action": "approve", "user_id": "u-17", "comment": "Change window CHG-1234", "created_at": "..."}
+		]
+	}
+
+Error Handling:
+
+  - 200: Approval state returned or run decided
+  - 400: Invalid ID, run number or body
+  - 401: No authenticated user
+  - 403: The user's role is not one of the job's approver roles
+  - 404: Job or run not found
+  - 409: The run is not awaiting approval or its approval expired
+*/
+package approvals
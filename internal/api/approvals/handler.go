@@ -0,0 +1,127 @@
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+)
+
+// Handler handles HTTP requests for run approvals
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new approval handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the approval routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/jobs/{id}/runs/{number}/approval", h.GetApproval)
+	r.Post("/jobs/{id}/runs/{number}/approve", h.Approve)
+	r.Post("/jobs/{id}/runs/{number}/reject", h.Reject)
+}
+
+// runRef extracts and validates the job ID and run number path parameters
+func runRef(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return "", 0, false
+	}
+	number, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+	if err != nil || number < 1 {
+		http.Error(w, "Invalid run number", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return id, number, true
+}
+
+// userID returns the user deciding on a run
+func userID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, ok := r.Context().Value(jobs.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+	return id, ok
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrRunNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrNotAwaitingApproval), errors.Is(err, ErrApprovalExpired):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetApproval handles requests for the approval state of a run
+func (h *Handler) GetApproval(w http.ResponseWriter, r *http.Request) {
+	id, number, ok := runRef(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.GetApproval(r.Context(), id, number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Approve handles requests to approve a run
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.Approve)
+}
+
+// Reject handles requests to reject a run
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.Reject)
+}
+
+// decide handles approve and reject requests. The body is optional and may
+// carry a comment.
+func (h *Handler) decide(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, jobID string, number int64, userID string, req DecisionRequest) (*ApprovalResponse, error)) {
+	id, number, ok := runRef(w, r)
+	if !ok {
+		return
+	}
+	user, ok := userID(w, r)
+	if !ok {
+		return
+	}
+	var req DecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := decide(r.Context(), id, number, user, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
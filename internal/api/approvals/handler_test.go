@@ -0,0 +1,122 @@
+package approvals
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, method, target, body string, user bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if user {
+		req = req.WithContext(context.WithValue(req.Context(), jobs.UserIDKey, "u-17"))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		user       bool
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name:   "approved with comment",
+			target: "/jobs/" + testJobID + "/runs/4/approve",
+			body:   `{"comment": "ship it"}`,
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Approve(gomock.Any(), testJobID, int64(4), "u-17", DecisionRequest{Comment: "ship it"}).
+					Return(&ApprovalResponse{Status: "pending"}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "rejected without body",
+			target: "/jobs/" + testJobID + "/runs/4/reject",
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Reject(gomock.Any(), testJobID, int64(4), "u-17", DecisionRequest{}).
+					Return(&ApprovalResponse{Status: "rejected"}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no user",
+			target:     "/jobs/" + testJobID + "/runs/4/approve",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid run number",
+			target:     "/jobs/" + testJobID + "/runs/0/approve",
+			user:       true,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			target:     "/jobs/" + testJobID + "/runs/4/approve",
+			body:       `{`,
+			user:       true,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "forbidden",
+			target: "/jobs/" + testJobID + "/runs/4/approve",
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Approve(gomock.Any(), testJobID, int64(4), "u-17", gomock.Any()).Return(nil, ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "expired",
+			target: "/jobs/" + testJobID + "/runs/4/approve",
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Approve(gomock.Any(), testJobID, int64(4), "u-17", gomock.Any()).Return(nil, ErrApprovalExpired)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodPost, tt.target, tt.body, tt.user)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestGetApproval(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().GetApproval(gomock.Any(), testJobID, int64(4)).Return(nil, ErrRunNotFound)
+	w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/runs/4/approval", "", false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(router, http.MethodGet, "/jobs/not-a-uuid/runs/4/approval", "", false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/approvals (interfaces: Approver)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_approver_test.go -package=approvals github.com/klauern/gopher-tower/internal/api/approvals Approver
+//
+
+// Package approvals is a generated GoMock package.
+package approvals
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockApprover is a mock of Approver interface.
+type MockApprover struct {
+	ctrl     *gomock.Controller
+	recorder *MockApproverMockRecorder
+	isgomock struct{}
+}
+
+// MockApproverMockRecorder is the mock recorder for MockApprover.
+type MockApproverMockRecorder struct {
+	mock *MockApprover
+}
+
+// NewMockApprover creates a new mock instance.
+func NewMockApprover(ctrl *gomock.Controller) *MockApprover {
+	mock := &MockApprover{ctrl: ctrl}
+	mock.recorder = &MockApproverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApprover) EXPECT() *MockApproverMockRecorder {
+	return m.recorder
+}
+
+// Approve mocks base method.
+func (m *MockApprover) Approve(ctx context.Context, runID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Approve", ctx, runID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Approve indicates an expected call of Approve.
+func (mr *MockApproverMockRecorder) Approve(ctx, runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Approve", reflect.TypeOf((*MockApprover)(nil).Approve), ctx, runID)
+}
+
+// Reject mocks base method.
+func (m *MockApprover) Reject(ctx context.Context, runID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", ctx, runID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockApproverMockRecorder) Reject(ctx, runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockApprover)(nil).Reject), ctx, runID)
+}
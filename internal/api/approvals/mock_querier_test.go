@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/approvals (interfaces: ApprovalQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=approvals github.com/klauern/gopher-tower/internal/api/approvals ApprovalQuerier
+//
+
+// Package approvals is a generated GoMock package.
+package approvals
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockApprovalQuerier is a mock of ApprovalQuerier interface.
+type MockApprovalQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockApprovalQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockApprovalQuerierMockRecorder is the mock recorder for MockApprovalQuerier.
+type MockApprovalQuerierMockRecorder struct {
+	mock *MockApprovalQuerier
+}
+
+// NewMockApprovalQuerier creates a new mock instance.
+func NewMockApprovalQuerier(ctrl *gomock.Controller) *MockApprovalQuerier {
+	mock := &MockApprovalQuerier{ctrl: ctrl}
+	mock.recorder = &MockApprovalQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApprovalQuerier) EXPECT() *MockApprovalQuerierMockRecorder {
+	return m.recorder
+}
+
+// CreateActivityLog mocks base method.
+func (m *MockApprovalQuerier) CreateActivityLog(ctx context.Context, arg db.CreateActivityLogParams) (db.ActivityLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateActivityLog", ctx, arg)
+	ret0, _ := ret[0].(db.ActivityLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateActivityLog indicates an expected call of CreateActivityLog.
+func (mr *MockApprovalQuerierMockRecorder) CreateActivityLog(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateActivityLog", reflect.TypeOf((*MockApprovalQuerier)(nil).CreateActivityLog), ctx, arg)
+}
+
+// GetJob mocks base method.
+func (m *MockApprovalQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockApprovalQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockApprovalQuerier)(nil).GetJob), ctx, id)
+}
+
+// GetJobRunByNumber mocks base method.
+func (m *MockApprovalQuerier) GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobRunByNumber", ctx, arg)
+	ret0, _ := ret[0].(db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobRunByNumber indicates an expected call of GetJobRunByNumber.
+func (mr *MockApprovalQuerierMockRecorder) GetJobRunByNumber(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobRunByNumber", reflect.TypeOf((*MockApprovalQuerier)(nil).GetJobRunByNumber), ctx, arg)
+}
+
+// GetUser mocks base method.
+func (m *MockApprovalQuerier) GetUser(ctx context.Context, id string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, id)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockApprovalQuerierMockRecorder) GetUser(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockApprovalQuerier)(nil).GetUser), ctx, id)
+}
+
+// ListActivityLogsByEntity mocks base method.
+func (m *MockApprovalQuerier) ListActivityLogsByEntity(ctx context.Context, arg db.ListActivityLogsByEntityParams) ([]db.ActivityLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActivityLogsByEntity", ctx, arg)
+	ret0, _ := ret[0].([]db.ActivityLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActivityLogsByEntity indicates an expected call of ListActivityLogsByEntity.
+func (mr *MockApprovalQuerierMockRecorder) ListActivityLogsByEntity(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActivityLogsByEntity", reflect.TypeOf((*MockApprovalQuerier)(nil).ListActivityLogsByEntity), ctx, arg)
+}
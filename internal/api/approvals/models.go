@@ -0,0 +1,34 @@
+package approvals
+
+import "time"
+
+// Activity log entries recording approval decisions on job_run entities
+const (
+	EntityType    = "job_run"
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+)
+
+// DecisionRequest is the body of approve and reject requests
+type DecisionRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// Decision is an approval decision recorded in the activity log
+type Decision struct {
+	Action    string    `json:"action"`
+	UserID    string    `json:"user_id"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ApprovalResponse describes the approval state of a run
+type ApprovalResponse struct {
+	JobID         string     `json:"job_id"`
+	RunID         string     `json:"run_id"`
+	RunNumber     int64      `json:"run_number"`
+	Status        string     `json:"status"`
+	ApproverRoles []string   `json:"approver_roles"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Decisions     []Decision `json:"decisions"`
+}
@@ -0,0 +1,214 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=approvals github.com/klauern/gopher-tower/internal/api/approvals ApprovalQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=approvals github.com/klauern/gopher-tower/internal/api/approvals Service
+//go:generate go tool mockgen -destination=mock_approver_test.go -package=approvals github.com/klauern/gopher-tower/internal/api/approvals Approver
+
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+)
+
+var (
+	ErrJobNotFound         = errors.New("job not found")
+	ErrRunNotFound         = errors.New("run not found")
+	ErrForbidden           = errors.New("user may not approve runs of this job")
+	ErrNotAwaitingApproval = errors.New("run is not awaiting approval")
+	ErrApprovalExpired     = errors.New("approval of run expired")
+)
+
+// decisionLimit bounds the decisions listed for a run
+const decisionLimit = 100
+
+// ApprovalQuerier defines the database operations used by the approval
+// service
+type ApprovalQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error)
+	GetUser(ctx context.Context, id string) (db.User, error)
+	CreateActivityLog(ctx context.Context, arg db.CreateActivityLogParams) (db.ActivityLog, error)
+	ListActivityLogsByEntity(ctx context.Context, arg db.ListActivityLogsByEntityParams) ([]db.ActivityLog, error)
+}
+
+// Approver decides runs awaiting approval; it is implemented by
+// executor.Executor
+type Approver interface {
+	Approve(ctx context.Context, runID string) error
+	Reject(ctx context.Context, runID string) error
+}
+
+// Service provides approval operations
+type Service interface {
+	GetApproval(ctx context.Context, jobID string, number int64) (*ApprovalResponse, error)
+	// Approve queues a run awaiting approval on behalf of userID, whose
+	// role must be one of the job's approver roles
+	Approve(ctx context.Context, jobID string, number int64, userID string, req DecisionRequest) (*ApprovalResponse, error)
+	// Reject finishes a run awaiting approval without executing it
+	Reject(ctx context.Context, jobID string, number int64, userID string, req DecisionRequest) (*ApprovalResponse, error)
+}
+
+// approvalService implements the Service interface
+type approvalService struct {
+	queries  ApprovalQuerier
+	approver Approver
+}
+
+// NewService creates a new approval service
+func NewService(queries ApprovalQuerier, approver Approver) Service {
+	return &approvalService{queries: queries, approver: approver}
+}
+
+// GetApproval returns the approval state and decisions of a run
+func (s *approvalService) GetApproval(ctx context.Context, jobID string, number int64) (*ApprovalResponse, error) {
+	job, run, err := s.getRun(ctx, jobID, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.toApprovalResponse(ctx, job, run)
+}
+
+// Approve approves a run awaiting approval
+func (s *approvalService) Approve(ctx context.Context, jobID string, number int64, userID string, req DecisionRequest) (*ApprovalResponse, error) {
+	return s.decide(ctx, jobID, number, userID, req, ActionApprove)
+}
+
+// Reject rejects a run awaiting approval
+func (s *approvalService) Reject(ctx context.Context, jobID string, number int64, userID string, req DecisionRequest) (*ApprovalResponse, error) {
+	return s.decide(ctx, jobID, number, userID, req, ActionReject)
+}
+
+// details is the activity log details of a decision
+type details struct {
+	JobID     string `json:"job_id"`
+	RunNumber int64  `json:"run_number"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+func (s *approvalService) decide(ctx context.Context, jobID string, number int64, userID string, req DecisionRequest, action string) (*ApprovalResponse, error) {
+	job, run, err := s.getRun(ctx, jobID, number)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, job, userID); err != nil {
+		return nil, err
+	}
+
+	decide := s.approver.Approve
+	if action == ActionReject {
+		decide = s.approver.Reject
+	}
+	if err := decide(ctx, run.ID); err != nil {
+		switch {
+		case errors.Is(err, executor.ErrNotAwaitingApproval):
+			return nil, ErrNotAwaitingApproval
+		case errors.Is(err, executor.ErrApprovalExpired):
+			return nil, ErrApprovalExpired
+		case db.IsNotFound(err):
+			return nil, ErrRunNotFound
+		}
+		return nil, err
+	}
+
+	data, err := json.Marshal(details{JobID: jobID, RunNumber: number, Comment: req.Comment})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.queries.CreateActivityLog(ctx, db.CreateActivityLogParams{
+		ID:         uuid.New().String(),
+		Action:     action,
+		EntityType: EntityType,
+		EntityID:   run.ID,
+		Details:    db.StringToNullString(string(data)),
+		UserID:     userID,
+	}); err != nil {
+		return nil, err
+	}
+
+	_, run, err = s.getRun(ctx, jobID, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.toApprovalResponse(ctx, job, run)
+}
+
+// authorize checks that the user has one of the job's approver roles
+func (s *approvalService) authorize(ctx context.Context, job db.Job, userID string) error {
+	user, err := s.queries.GetUser(ctx, userID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if !user.Role.Valid || !slices.Contains(executor.ApproverRoles(job), user.Role.String) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *approvalService) getRun(ctx context.Context, jobID string, number int64) (db.Job, db.JobRun, error) {
+	job, err := s.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return db.Job{}, db.JobRun{}, ErrJobNotFound
+		}
+		return db.Job{}, db.JobRun{}, err
+	}
+	run, err := s.queries.GetJobRunByNumber(ctx, db.GetJobRunByNumberParams{JobID: jobID, RunNumber: number})
+	if err != nil {
+		if db.IsNotFound(err) {
+			return db.Job{}, db.JobRun{}, ErrRunNotFound
+		}
+		return db.Job{}, db.JobRun{}, err
+	}
+	return job, run, nil
+}
+
+// toApprovalResponse describes a run along with the decisions recorded on
+// it, oldest first
+func (s *approvalService) toApprovalResponse(ctx context.Context, job db.Job, run db.JobRun) (*ApprovalResponse, error) {
+	logs, err := s.queries.ListActivityLogsByEntity(ctx, db.ListActivityLogsByEntityParams{
+		EntityType: EntityType,
+		EntityID:   run.ID,
+		Limit:      decisionLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	decisions := []Decision{}
+	for _, entry := range slices.Backward(logs) {
+		if entry.Action != ActionApprove && entry.Action != ActionReject {
+			continue
+		}
+		var d details
+		if entry.Details.Valid {
+			_ = json.Unmarshal([]byte(entry.Details.String), &d)
+		}
+		decisions = append(decisions, Decision{
+			Action:    entry.Action,
+			UserID:    entry.UserID,
+			Comment:   d.Comment,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+
+	roles := executor.ApproverRoles(job)
+	if roles == nil {
+		roles = []string{}
+	}
+	return &ApprovalResponse{
+		JobID:         job.ID,
+		RunID:         run.ID,
+		RunNumber:     run.RunNumber,
+		Status:        run.Status,
+		ApproverRoles: roles,
+		ExpiresAt:     db.NullTimeToTimePtr(run.ApprovalExpiresAt),
+		Decisions:     decisions,
+	}, nil
+}
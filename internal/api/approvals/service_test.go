@@ -0,0 +1,173 @@
+package approvals
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testJobID = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+
+type serviceTest struct {
+	querier  *MockApprovalQuerier
+	approver *MockApprover
+	svc      Service
+}
+
+func setupService(t *testing.T) *serviceTest {
+	ctrl := gomock.NewController(t)
+	st := &serviceTest{
+		querier:  NewMockApprovalQuerier(ctrl),
+		approver: NewMockApprover(ctrl),
+	}
+	st.svc = NewService(st.querier, st.approver)
+	return st
+}
+
+func gatedJob() db.Job {
+	return db.Job{
+		ID:               testJobID,
+		RequiresApproval: true,
+		ApproverRoles:    sql.NullString{String: `["admin","release-manager"]`, Valid: true},
+	}
+}
+
+func awaitingRun(status string) db.JobRun {
+	return db.JobRun{
+		ID:                "run-1",
+		JobID:             testJobID,
+		RunNumber:         4,
+		Status:            status,
+		ApprovalExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+}
+
+func user(role string) db.User {
+	return db.User{ID: "u-17", Role: sql.NullString{String: role, Valid: true}}
+}
+
+func TestApprovalService_Decide(t *testing.T) {
+	ctx := context.Background()
+	byNumber := db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 4}
+
+	t.Run("approve records the decision", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(gatedJob(), nil).Times(2)
+		gomock.InOrder(
+			st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), byNumber).Return(awaitingRun(executor.StatusAwaitingApproval), nil),
+			st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), byNumber).Return(awaitingRun(executor.StatusPending), nil),
+		)
+		st.querier.EXPECT().GetUser(gomock.Any(), "u-17").Return(user("release-manager"), nil)
+		st.approver.EXPECT().Approve(gomock.Any(), "run-1").Return(nil)
+
+		var logged db.CreateActivityLogParams
+		st.querier.EXPECT().CreateActivityLog(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateActivityLogParams) (db.ActivityLog, error) {
+				logged = arg
+				return db.ActivityLog{}, nil
+			})
+		st.querier.EXPECT().ListActivityLogsByEntity(gomock.Any(), db.ListActivityLogsByEntityParams{
+			EntityType: EntityType, EntityID: "run-1", Limit: decisionLimit,
+		}).DoAndReturn(func(context.Context, db.ListActivityLogsByEntityParams) ([]db.ActivityLog, error) {
+			return []db.ActivityLog{{
+				Action: logged.Action, EntityType: logged.EntityType, EntityID: logged.EntityID,
+				Details: logged.Details, UserID: logged.UserID,
+			}}, nil
+		})
+
+		resp, err := st.svc.Approve(ctx, testJobID, 4, "u-17", DecisionRequest{Comment: "ship it"})
+		require.NoError(t, err)
+		assert.Equal(t, ActionApprove, logged.Action)
+		assert.Equal(t, "run-1", logged.EntityID)
+		var d details
+		require.NoError(t, json.Unmarshal([]byte(logged.Details.String), &d))
+		assert.Equal(t, details{JobID: testJobID, RunNumber: 4, Comment: "ship it"}, d)
+
+		assert.Equal(t, executor.StatusPending, resp.Status)
+		assert.Equal(t, []string{"admin", "release-manager"}, resp.ApproverRoles)
+		require.Len(t, resp.Decisions, 1)
+		assert.Equal(t, Decision{Action: ActionApprove, UserID: "u-17", Comment: "ship it"}, resp.Decisions[0])
+	})
+
+	tests := []struct {
+		name    string
+		role    string
+		reject  bool
+		decided error
+		wantErr error
+	}{
+		{name: "role not allowed", role: "user", wantErr: ErrForbidden},
+		{name: "not awaiting approval", role: "admin", decided: executor.ErrNotAwaitingApproval, wantErr: ErrNotAwaitingApproval},
+		{name: "expired", role: "admin", decided: executor.ErrApprovalExpired, wantErr: ErrApprovalExpired},
+		{name: "reject already decided", role: "admin", reject: true, decided: executor.ErrNotAwaitingApproval, wantErr: ErrNotAwaitingApproval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := setupService(t)
+			st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(gatedJob(), nil)
+			st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), byNumber).Return(awaitingRun(executor.StatusAwaitingApproval), nil)
+			st.querier.EXPECT().GetUser(gomock.Any(), "u-17").Return(user(tt.role), nil)
+			decide := st.svc.Approve
+			if tt.reject {
+				decide = st.svc.Reject
+			}
+			if tt.decided != nil {
+				if tt.reject {
+					st.approver.EXPECT().Reject(gomock.Any(), "run-1").Return(tt.decided)
+				} else {
+					st.approver.EXPECT().Approve(gomock.Any(), "run-1").Return(tt.decided)
+				}
+			}
+
+			_, err := decide(ctx, testJobID, 4, "u-17", DecisionRequest{})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(gatedJob(), nil)
+		st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), byNumber).Return(awaitingRun(executor.StatusAwaitingApproval), nil)
+		st.querier.EXPECT().GetUser(gomock.Any(), "u-17").Return(db.User{}, sql.ErrNoRows)
+
+		_, err := st.svc.Reject(ctx, testJobID, 4, "u-17", DecisionRequest{})
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("run not found", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(gatedJob(), nil)
+		st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), byNumber).Return(db.JobRun{}, sql.ErrNoRows)
+
+		_, err := st.svc.Approve(ctx, testJobID, 4, "u-17", DecisionRequest{})
+		assert.ErrorIs(t, err, ErrRunNotFound)
+	})
+}
+
+func TestApprovalService_GetApproval(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(gatedJob(), nil)
+	st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), gomock.Any()).Return(awaitingRun(executor.StatusRejected), nil)
+	st.querier.EXPECT().ListActivityLogsByEntity(gomock.Any(), gomock.Any()).Return([]db.ActivityLog{
+		{Action: ActionReject, UserID: "u-2", Details: sql.NullString{String: `{"comment":"not now"}`, Valid: true}},
+		{Action: "comment", UserID: "u-3"},
+		{Action: ActionApprove, UserID: "u-1"},
+	}, nil)
+
+	resp, err := st.svc.GetApproval(context.Background(), testJobID, 4)
+	require.NoError(t, err)
+	assert.Equal(t, executor.StatusRejected, resp.Status)
+	assert.NotNil(t, resp.ExpiresAt)
+	assert.Equal(t, []Decision{
+		{Action: ActionApprove, UserID: "u-1"},
+		{Action: ActionReject, UserID: "u-2", Comment: "not now"},
+	}, resp.Decisions)
+}
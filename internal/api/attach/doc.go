@@ -0,0 +1,37 @@
+/*
+Package attach connects users to the terminals of running interactive jobs
+over WebSocket.
+
+Runs of a job with interactive set run their processes under a
+pseudo-terminal (see package terminal). Only the owner of the job
+(jobs.owner_id) may attach to it. Without a run number the latest running
+run is attached. Terminals live on the server executing the run, so the
+attach request must reach that server.
+
+The socket carries two kinds of messages:
+
+  - Binary messages carry terminal data: the server sends the process's
+    output, starting with the output written before the client attached;
+    the client sends input typed by the user.
+  - Text messages carry JSON control messages. The client resizes the
+    terminal with {"type": "resize", "cols": 120, "rows": 40}. Once the run
+    finished the server sends {"type": "exit", "exit_code": 0} and closes
+    the socket normally.
+
+A client that falls too far behind the output is disconnected with status
+1013 (try again later) and may attach again.
+
+API Endpoints:
+
+	GET /jobs/{id}/attach?run={number} - Attach to the terminal of a running run
+
+Error Handling (before the upgrade):
+
+  - 400: Invalid ID or run number, or not a WebSocket request
+  - 401: No authenticated user
+  - 403: The user does not own the job
+  - 404: Job or run not found
+  - 409: The job is not interactive, the run is not running or its
+    terminal is on another server
+*/
+package attach
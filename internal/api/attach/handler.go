@@ -0,0 +1,175 @@
+package attach
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/klauern/gopher-tower/internal/terminal"
+)
+
+// writeTimeout bounds how long a message may take to reach the client
+const writeTimeout = 10 * time.Second
+
+// Handler handles WebSocket requests attaching to terminals
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new attach handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the attach routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/jobs/{id}/attach", h.Attach)
+}
+
+// userID returns the user attaching to a terminal
+func userID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, ok := r.Context().Value(jobs.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+	return id, ok
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrRunNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrNotInteractive), errors.Is(err, ErrNotRunning), errors.Is(err, ErrNoTerminal):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Attach upgrades the request to a WebSocket relaying the terminal of a
+// run until the run finishes or the client disconnects
+func (h *Handler) Attach(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return
+	}
+	var number int64
+	if s := r.URL.Query().Get("run"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid run number", http.StatusBadRequest)
+			return
+		}
+		number = n
+	}
+	user, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	term, err := h.service.Open(r.Context(), id, number, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The session outlives the server's read timeout and the request
+	// timeout, neither of which is lifted by the upgrade
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept already responded
+		return
+	}
+	relay(context.WithoutCancel(r.Context()), conn, term)
+}
+
+// relay copies the terminal's output to the client and the client's input
+// and resize messages to the terminal
+func relay(ctx context.Context, conn *websocket.Conn, term *terminal.Terminal) {
+	defer conn.CloseNow()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client, backlog, err := term.Attach()
+	if errors.Is(err, terminal.ErrClosed) {
+		sendExit(ctx, conn, term)
+		return
+	}
+	defer client.Detach()
+	go func() {
+		defer cancel()
+		readInput(ctx, conn, term)
+	}()
+
+	if len(backlog) > 0 && write(ctx, conn, websocket.MessageBinary, backlog) != nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case chunk, ok := <-client.Output():
+			if !ok {
+				select {
+				case <-term.Done():
+					sendExit(ctx, conn, term)
+				default:
+					conn.Close(websocket.StatusTryAgainLater, "client fell behind the output")
+				}
+				return
+			}
+			if write(ctx, conn, websocket.MessageBinary, chunk) != nil {
+				return
+			}
+		}
+	}
+}
+
+// readInput hands the client's messages to the terminal until the client
+// disconnects
+func readInput(ctx context.Context, conn *websocket.Conn, term *terminal.Terminal) {
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if typ == websocket.MessageBinary {
+			// Input typed between processes is dropped
+			_, _ = term.Write(data)
+			continue
+		}
+		var msg Message
+		if json.Unmarshal(data, &msg) == nil && msg.Type == MessageResize {
+			_ = term.Resize(terminal.Size{Cols: msg.Cols, Rows: msg.Rows})
+		}
+	}
+}
+
+// sendExit tells the client how the run ended and closes the socket
+func sendExit(ctx context.Context, conn *websocket.Conn, term *terminal.Terminal) {
+	code := term.ExitCode()
+	data, err := json.Marshal(Message{Type: MessageExit, ExitCode: &code})
+	if err == nil && write(ctx, conn, websocket.MessageText, data) == nil {
+		conn.Close(websocket.StatusNormalClosure, "run finished")
+	}
+}
+
+func write(ctx context.Context, conn *websocket.Conn, typ websocket.MessageType, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+	return conn.Write(ctx, typ, data)
+}
@@ -0,0 +1,176 @@
+package attach
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/go-chi/chi/v5"
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/klauern/gopher-tower/internal/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+// withUser authenticates every request as u-17
+func withUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), jobs.UserIDKey, "u-17")))
+	})
+}
+
+func TestAttachErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		user       bool
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name:       "no user",
+			target:     "/jobs/" + testJobID + "/attach",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid job ID",
+			target:     "/jobs/nope/attach",
+			user:       true,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid run number",
+			target:     "/jobs/" + testJobID + "/attach?run=0",
+			user:       true,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "not the owner",
+			target: "/jobs/" + testJobID + "/attach?run=4",
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Open(gomock.Any(), testJobID, int64(4), "u-17").Return(nil, ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "job not found",
+			target: "/jobs/" + testJobID + "/attach",
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Open(gomock.Any(), testJobID, int64(0), "u-17").Return(nil, ErrJobNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "not running",
+			target: "/jobs/" + testJobID + "/attach",
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Open(gomock.Any(), testJobID, int64(0), "u-17").Return(nil, ErrNotRunning)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:   "not a WebSocket request",
+			target: "/jobs/" + testJobID + "/attach",
+			user:   true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Open(gomock.Any(), testJobID, int64(0), "u-17").Return(terminal.New(), nil)
+			},
+			wantStatus: http.StatusUpgradeRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, router := setupHandler(t)
+			tt.setupMock(mockService)
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.user {
+				req = req.WithContext(context.WithValue(req.Context(), jobs.UserIDKey, "u-17"))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAttachSession(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminals are only supported on Linux")
+	}
+	mockService, router := setupHandler(t)
+	term := terminal.New()
+	mockService.EXPECT().Open(gomock.Any(), testJobID, int64(0), "u-17").Return(term, nil)
+	server := httptest.NewServer(withUser(router))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/jobs/"+testJobID+"/attach", nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	resize, err := json.Marshal(Message{Type: MessageResize, Cols: 100, Rows: 40})
+	require.NoError(t, err)
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, resize))
+	require.Eventually(t, func() bool {
+		return term.Size() == terminal.Size{Cols: 100, Rows: 40}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		cmd := exec.Command("sh", "-c", `stty size; printf 'name? '; read name; echo "hello $name"; exit 3`)
+		done <- term.Run(cmd, &strings.Builder{})
+	}()
+
+	var got strings.Builder
+	for !strings.Contains(got.String(), "name? ") {
+		typ, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		require.Equal(t, websocket.MessageBinary, typ)
+		got.Write(data)
+	}
+	assert.Contains(t, got.String(), "40 100")
+	require.NoError(t, conn.Write(ctx, websocket.MessageBinary, []byte("gopher\n")))
+	<-done
+	term.Close(3)
+
+	var exit Message
+	for exit.Type == "" {
+		typ, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		if typ == websocket.MessageBinary {
+			got.Write(data)
+			continue
+		}
+		require.NoError(t, json.Unmarshal(data, &exit))
+	}
+	assert.Contains(t, got.String(), "hello gopher")
+	assert.Equal(t, MessageExit, exit.Type)
+	require.NotNil(t, exit.ExitCode)
+	assert.Equal(t, 3, *exit.ExitCode)
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t, websocket.StatusNormalClosure, websocket.CloseStatus(err))
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/attach (interfaces: AttachQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=attach github.com/klauern/gopher-tower/internal/api/attach AttachQuerier
+//
+
+// Package attach is a generated GoMock package.
+package attach
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAttachQuerier is a mock of AttachQuerier interface.
+type MockAttachQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockAttachQuerierMockRecorder is the mock recorder for MockAttachQuerier.
+type MockAttachQuerierMockRecorder struct {
+	mock *MockAttachQuerier
+}
+
+// NewMockAttachQuerier creates a new mock instance.
+func NewMockAttachQuerier(ctrl *gomock.Controller) *MockAttachQuerier {
+	mock := &MockAttachQuerier{ctrl: ctrl}
+	mock.recorder = &MockAttachQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachQuerier) EXPECT() *MockAttachQuerierMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockAttachQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockAttachQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockAttachQuerier)(nil).GetJob), ctx, id)
+}
+
+// GetJobRunByNumber mocks base method.
+func (m *MockAttachQuerier) GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobRunByNumber", ctx, arg)
+	ret0, _ := ret[0].(db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobRunByNumber indicates an expected call of GetJobRunByNumber.
+func (mr *MockAttachQuerierMockRecorder) GetJobRunByNumber(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobRunByNumber", reflect.TypeOf((*MockAttachQuerier)(nil).GetJobRunByNumber), ctx, arg)
+}
+
+// ListJobRunsByJob mocks base method.
+func (m *MockAttachQuerier) ListJobRunsByJob(ctx context.Context, arg db.ListJobRunsByJobParams) ([]db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunsByJob", ctx, arg)
+	ret0, _ := ret[0].([]db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunsByJob indicates an expected call of ListJobRunsByJob.
+func (mr *MockAttachQuerierMockRecorder) ListJobRunsByJob(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunsByJob", reflect.TypeOf((*MockAttachQuerier)(nil).ListJobRunsByJob), ctx, arg)
+}
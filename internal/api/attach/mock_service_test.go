@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/attach (interfaces: Service)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_service_test.go -package=attach github.com/klauern/gopher-tower/internal/api/attach Service
+//
+
+// Package attach is a generated GoMock package.
+package attach
+
+import (
+	context "context"
+	reflect "reflect"
+
+	terminal "github.com/klauern/gopher-tower/internal/terminal"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockService) Open(ctx context.Context, jobID string, number int64, userID string) (*terminal.Terminal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, jobID, number, userID)
+	ret0, _ := ret[0].(*terminal.Terminal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockServiceMockRecorder) Open(ctx, jobID, number, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockService)(nil).Open), ctx, jobID, number, userID)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/attach (interfaces: Terminals)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_terminals_test.go -package=attach github.com/klauern/gopher-tower/internal/api/attach Terminals
+//
+
+// Package attach is a generated GoMock package.
+package attach
+
+import (
+	reflect "reflect"
+
+	terminal "github.com/klauern/gopher-tower/internal/terminal"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTerminals is a mock of Terminals interface.
+type MockTerminals struct {
+	ctrl     *gomock.Controller
+	recorder *MockTerminalsMockRecorder
+	isgomock struct{}
+}
+
+// MockTerminalsMockRecorder is the mock recorder for MockTerminals.
+type MockTerminalsMockRecorder struct {
+	mock *MockTerminals
+}
+
+// NewMockTerminals creates a new mock instance.
+func NewMockTerminals(ctrl *gomock.Controller) *MockTerminals {
+	mock := &MockTerminals{ctrl: ctrl}
+	mock.recorder = &MockTerminalsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTerminals) EXPECT() *MockTerminalsMockRecorder {
+	return m.recorder
+}
+
+// Terminal mocks base method.
+func (m *MockTerminals) Terminal(runID string) (*terminal.Terminal, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Terminal", runID)
+	ret0, _ := ret[0].(*terminal.Terminal)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Terminal indicates an expected call of Terminal.
+func (mr *MockTerminalsMockRecorder) Terminal(runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Terminal", reflect.TypeOf((*MockTerminals)(nil).Terminal), runID)
+}
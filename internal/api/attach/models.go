@@ -0,0 +1,18 @@
+package attach
+
+// Types of control messages
+const (
+	MessageResize = "resize"
+	MessageExit   = "exit"
+)
+
+// Message is a control message, sent as a WebSocket text message
+type Message struct {
+	Type string `json:"type"`
+	// Cols and Rows are the size of the client's terminal in resize
+	// messages
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	// ExitCode is the exit code of the finished run in exit messages
+	ExitCode *int `json:"exit_code,omitempty"`
+}
@@ -0,0 +1,116 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=attach github.com/klauern/gopher-tower/internal/api/attach AttachQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=attach github.com/klauern/gopher-tower/internal/api/attach Service
+//go:generate go tool mockgen -destination=mock_terminals_test.go -package=attach github.com/klauern/gopher-tower/internal/api/attach Terminals
+
+package attach
+
+import (
+	"context"
+	"errors"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/terminal"
+)
+
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrRunNotFound    = errors.New("run not found")
+	ErrForbidden      = errors.New("only the owner of the job may attach to it")
+	ErrNotInteractive = errors.New("job is not interactive")
+	ErrNotRunning     = errors.New("run is not running")
+	ErrNoTerminal     = errors.New("terminal of run is not on this server")
+)
+
+// runScanLimit bounds the runs searched for the latest running one
+const runScanLimit = 50
+
+// AttachQuerier defines the database operations used by the attach service
+type AttachQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error)
+	ListJobRunsByJob(ctx context.Context, arg db.ListJobRunsByJobParams) ([]db.JobRun, error)
+}
+
+// Terminals looks up the terminals of running runs; it is implemented by
+// executor.Executor
+type Terminals interface {
+	Terminal(runID string) (*terminal.Terminal, bool)
+}
+
+// Service provides access to the terminals of interactive jobs
+type Service interface {
+	// Open returns the terminal of a running run of an interactive job
+	// owned by userID. A zero number selects the latest running run.
+	Open(ctx context.Context, jobID string, number int64, userID string) (*terminal.Terminal, error)
+}
+
+// attachService implements the Service interface
+type attachService struct {
+	queries   AttachQuerier
+	terminals Terminals
+}
+
+// NewService creates a new attach service
+func NewService(queries AttachQuerier, terminals Terminals) Service {
+	return &attachService{queries: queries, terminals: terminals}
+}
+
+// Open returns the terminal of a run
+func (s *attachService) Open(ctx context.Context, jobID string, number int64, userID string) (*terminal.Terminal, error) {
+	job, err := s.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	if !job.OwnerID.Valid || job.OwnerID.String != userID {
+		return nil, ErrForbidden
+	}
+	if !job.Interactive {
+		return nil, ErrNotInteractive
+	}
+	if number == 0 {
+		return s.latest(ctx, jobID)
+	}
+
+	run, err := s.queries.GetJobRunByNumber(ctx, db.GetJobRunByNumberParams{JobID: jobID, RunNumber: number})
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrRunNotFound
+		}
+		return nil, err
+	}
+	if run.Status != executor.StatusRunning {
+		return nil, ErrNotRunning
+	}
+	term, ok := s.terminals.Terminal(run.ID)
+	if !ok {
+		return nil, ErrNoTerminal
+	}
+	return term, nil
+}
+
+// latest returns the terminal of the latest running run of a job. Matrix
+// runs are skipped, their children have the terminals.
+func (s *attachService) latest(ctx context.Context, jobID string) (*terminal.Terminal, error) {
+	runs, err := s.queries.ListJobRunsByJob(ctx, db.ListJobRunsByJobParams{JobID: jobID, Limit: runScanLimit})
+	if err != nil {
+		return nil, err
+	}
+	running := false
+	for _, run := range runs {
+		if run.Status != executor.StatusRunning || run.ChildCount > 0 {
+			continue
+		}
+		running = true
+		if term, ok := s.terminals.Terminal(run.ID); ok {
+			return term, nil
+		}
+	}
+	if running {
+		return nil, ErrNoTerminal
+	}
+	return nil, ErrNotRunning
+}
@@ -0,0 +1,150 @@
+package attach
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testJobID = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+
+type serviceTest struct {
+	querier   *MockAttachQuerier
+	terminals *MockTerminals
+	svc       Service
+}
+
+func setupService(t *testing.T) *serviceTest {
+	ctrl := gomock.NewController(t)
+	st := &serviceTest{
+		querier:   NewMockAttachQuerier(ctrl),
+		terminals: NewMockTerminals(ctrl),
+	}
+	st.svc = NewService(st.querier, st.terminals)
+	return st
+}
+
+func interactiveJob() db.Job {
+	return db.Job{
+		ID:          testJobID,
+		OwnerID:     sql.NullString{String: "u-17", Valid: true},
+		Interactive: true,
+	}
+}
+
+func TestAttachService_Open(t *testing.T) {
+	ctx := context.Background()
+	byNumber := db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 4}
+	term := terminal.New()
+
+	t.Run("run by number", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(interactiveJob(), nil)
+		st.querier.EXPECT().GetJobRunByNumber(ctx, byNumber).
+			Return(db.JobRun{ID: "run-4", Status: executor.StatusRunning}, nil)
+		st.terminals.EXPECT().Terminal("run-4").Return(term, true)
+
+		got, err := st.svc.Open(ctx, testJobID, 4, "u-17")
+		require.NoError(t, err)
+		assert.Same(t, term, got)
+	})
+
+	t.Run("latest running run", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(interactiveJob(), nil)
+		st.querier.EXPECT().ListJobRunsByJob(ctx, db.ListJobRunsByJobParams{JobID: testJobID, Limit: runScanLimit}).
+			Return([]db.JobRun{
+				{ID: "run-6", Status: executor.StatusPending},
+				{ID: "run-5", Status: executor.StatusRunning, ChildCount: 2},
+				{ID: "run-4", Status: executor.StatusRunning},
+			}, nil)
+		st.terminals.EXPECT().Terminal("run-4").Return(term, true)
+
+		got, err := st.svc.Open(ctx, testJobID, 0, "u-17")
+		require.NoError(t, err)
+		assert.Same(t, term, got)
+	})
+
+	t.Run("no running run", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(interactiveJob(), nil)
+		st.querier.EXPECT().ListJobRunsByJob(ctx, gomock.Any()).
+			Return([]db.JobRun{{ID: "run-4", Status: executor.StatusComplete}}, nil)
+
+		_, err := st.svc.Open(ctx, testJobID, 0, "u-17")
+		assert.ErrorIs(t, err, ErrNotRunning)
+	})
+
+	t.Run("running on another server", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(interactiveJob(), nil)
+		st.querier.EXPECT().ListJobRunsByJob(ctx, gomock.Any()).
+			Return([]db.JobRun{{ID: "run-4", Status: executor.StatusRunning}}, nil)
+		st.terminals.EXPECT().Terminal("run-4").Return(nil, false)
+
+		_, err := st.svc.Open(ctx, testJobID, 0, "u-17")
+		assert.ErrorIs(t, err, ErrNoTerminal)
+	})
+
+	t.Run("run not running", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(interactiveJob(), nil)
+		st.querier.EXPECT().GetJobRunByNumber(ctx, byNumber).
+			Return(db.JobRun{ID: "run-4", Status: executor.StatusComplete}, nil)
+
+		_, err := st.svc.Open(ctx, testJobID, 4, "u-17")
+		assert.ErrorIs(t, err, ErrNotRunning)
+	})
+
+	t.Run("run not found", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(interactiveJob(), nil)
+		st.querier.EXPECT().GetJobRunByNumber(ctx, byNumber).Return(db.JobRun{}, sql.ErrNoRows)
+
+		_, err := st.svc.Open(ctx, testJobID, 4, "u-17")
+		assert.ErrorIs(t, err, ErrRunNotFound)
+	})
+
+	t.Run("not the owner", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(interactiveJob(), nil)
+
+		_, err := st.svc.Open(ctx, testJobID, 4, "u-42")
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("job without owner", func(t *testing.T) {
+		st := setupService(t)
+		job := interactiveJob()
+		job.OwnerID = sql.NullString{}
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(job, nil)
+
+		_, err := st.svc.Open(ctx, testJobID, 4, "")
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("not interactive", func(t *testing.T) {
+		st := setupService(t)
+		job := interactiveJob()
+		job.Interactive = false
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(job, nil)
+
+		_, err := st.svc.Open(ctx, testJobID, 4, "u-17")
+		assert.ErrorIs(t, err, ErrNotInteractive)
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(ctx, testJobID).Return(db.Job{}, db.ErrNotFound)
+
+		_, err := st.svc.Open(ctx, testJobID, 4, "u-17")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
@@ -0,0 +1,46 @@
+/*
+Package deadletters exposes runs that exhausted their retries over HTTP.
+
+A failed run of a job with max_retries is queued again until it has been
+attempted max_retries+1 times. When the last attempt fails, the run ends in
+the dead_letter status and a dead letter is filed for it, recording the
+failure of every attempt. Dead letters stay open until they are requeued,
+which starts a new run of the job, or discarded.
+
+API Endpoints:
+
+	GET    /dead-letters              - List open dead letters, newest first
+	GET    /dead-letters/{id}         - Get a dead letter and its attempts
+	POST   /dead-letters/{id}/requeue - Start a new run of the job
+	POST   /dead-letters/{id}/discard - Close the dead letter without a run
+
+Example Response:
+
+	{
+		"id": "7d4e...",
+		"job_id": "5f0c...",
+		"job_name": "nightly-export",
+		"run_id": "a91b...",
+		"run_number": 12,
+		"attempts": 3,
+		"error": "exit status 1",
+		"history": [
+			{"attempt": 1, "status": "failed", "exit_code": 1, "error": "exit status 1"},
+			...
+		]
+	}
+
+When GOPHER_TOWER_DEAD_LETTER_ALERT_THRESHOLD is set, reaching that many open
+dead letters publishes a dead_letters.threshold_reached event on
+/api/events.
+
+Error Handling:
+
+  - 200: Dead letter returned or discarded
+  - 202: Dead letter requeued
+  - 400: Invalid ID or pagination parameters
+  - 404: Dead letter not found
+  - 409: Dead letter already resolved
+  - 503: The executor is shutting down
+*/
+package deadletters
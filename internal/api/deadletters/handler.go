@@ -0,0 +1,143 @@
+package deadletters
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for dead letters
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new dead letter handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the dead letter routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/dead-letters", h.ListDeadLetters)
+	r.Get("/dead-letters/{id}", h.GetDeadLetter)
+	r.Post("/dead-letters/{id}/requeue", h.Requeue)
+	r.Post("/dead-letters/{id}/discard", h.Discard)
+}
+
+// deadLetterID extracts and validates the dead letter ID path parameter
+func deadLetterID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid dead letter ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrDeadLetterNotFound), errors.Is(err, ErrJobNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrAlreadyResolved):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrInvalidRun):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ListDeadLetters handles dead letter listing requests
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	params := ListParams{
+		Page:     1,
+		PageSize: 10,
+	}
+	if page := r.URL.Query().Get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil {
+			http.Error(w, "Invalid page parameter", http.StatusBadRequest)
+			return
+		}
+		params.Page = p
+	}
+	if pageSize := r.URL.Query().Get("page_size"); pageSize != "" {
+		ps, err := strconv.Atoi(pageSize)
+		if err != nil {
+			http.Error(w, "Invalid page_size parameter", http.StatusBadRequest)
+			return
+		}
+		params.PageSize = ps
+	}
+	if err := params.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.ListDeadLetters(r.Context(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetDeadLetter handles dead letter retrieval requests
+func (h *Handler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, ok := deadLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.GetDeadLetter(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Requeue handles requests to run a dead-lettered job again
+func (h *Handler) Requeue(w http.ResponseWriter, r *http.Request) {
+	id, ok := deadLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.Requeue(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// Discard handles requests to close a dead letter
+func (h *Handler) Discard(w http.ResponseWriter, r *http.Request) {
+	id, ok := deadLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.Discard(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
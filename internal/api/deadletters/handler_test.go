@@ -0,0 +1,163 @@
+package deadletters
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestListDeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name: "default pagination",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().
+					ListDeadLetters(gomock.Any(), ListParams{Page: 1, PageSize: 10}).
+					Return(&ListResponse{DeadLetters: []DeadLetterResponse{{ID: testDeadLetterID}}, TotalCount: 1}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "custom pagination",
+			query: "?page=2&page_size=5",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().
+					ListDeadLetters(gomock.Any(), ListParams{Page: 2, PageSize: 5}).
+					Return(&ListResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid page",
+			query:      "?page=abc",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "page size too large",
+			query:      "?page_size=1000",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodGet, "/dead-letters"+tt.query)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestGetDeadLetter(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name: "found",
+			id:   testDeadLetterID,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().GetDeadLetter(gomock.Any(), testDeadLetterID).Return(&DeadLetterResponse{
+					ID:      testDeadLetterID,
+					History: []AttemptResponse{{Attempt: 1}, {Attempt: 2}},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			id:   testDeadLetterID,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().GetDeadLetter(gomock.Any(), testDeadLetterID).Return(nil, ErrDeadLetterNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodGet, "/dead-letters/"+tt.id)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if w.Code == http.StatusOK {
+				var resp DeadLetterResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Len(t, resp.History, 2)
+			}
+		})
+	}
+}
+
+func TestResolveDeadLetter(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     string
+		err        error
+		wantStatus int
+	}{
+		{name: "requeued", action: "requeue", wantStatus: http.StatusAccepted},
+		{name: "discarded", action: "discard", wantStatus: http.StatusOK},
+		{name: "requeue resolved", action: "requeue", err: ErrAlreadyResolved, wantStatus: http.StatusConflict},
+		{name: "discard resolved", action: "discard", err: ErrAlreadyResolved, wantStatus: http.StatusConflict},
+		{name: "not found", action: "discard", err: ErrDeadLetterNotFound, wantStatus: http.StatusNotFound},
+		{name: "job cannot run", action: "requeue", err: ErrInvalidRun, wantStatus: http.StatusBadRequest},
+		{name: "shutting down", action: "requeue", err: ErrUnavailable, wantStatus: http.StatusServiceUnavailable},
+		{name: "internal error", action: "requeue", err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			var resp *DeadLetterResponse
+			if tt.err == nil {
+				resp = &DeadLetterResponse{ID: testDeadLetterID}
+			}
+			switch tt.action {
+			case "requeue":
+				ms.EXPECT().Requeue(gomock.Any(), testDeadLetterID).Return(resp, tt.err)
+			case "discard":
+				ms.EXPECT().Discard(gomock.Any(), testDeadLetterID).Return(resp, tt.err)
+			}
+			w := serve(router, http.MethodPost, "/dead-letters/"+testDeadLetterID+"/"+tt.action)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/deadletters (interfaces: DeadLetterQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=deadletters github.com/klauern/gopher-tower/internal/api/deadletters DeadLetterQuerier
+//
+
+// Package deadletters is a generated GoMock package.
+package deadletters
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterQuerier is a mock of DeadLetterQuerier interface.
+type MockDeadLetterQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockDeadLetterQuerierMockRecorder is the mock recorder for MockDeadLetterQuerier.
+type MockDeadLetterQuerierMockRecorder struct {
+	mock *MockDeadLetterQuerier
+}
+
+// NewMockDeadLetterQuerier creates a new mock instance.
+func NewMockDeadLetterQuerier(ctrl *gomock.Controller) *MockDeadLetterQuerier {
+	mock := &MockDeadLetterQuerier{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterQuerier) EXPECT() *MockDeadLetterQuerierMockRecorder {
+	return m.recorder
+}
+
+// CountOpenDeadLetters mocks base method.
+func (m *MockDeadLetterQuerier) CountOpenDeadLetters(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOpenDeadLetters", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOpenDeadLetters indicates an expected call of CountOpenDeadLetters.
+func (mr *MockDeadLetterQuerierMockRecorder) CountOpenDeadLetters(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOpenDeadLetters", reflect.TypeOf((*MockDeadLetterQuerier)(nil).CountOpenDeadLetters), ctx)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockDeadLetterQuerier) GetDeadLetter(ctx context.Context, id string) (db.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, id)
+	ret0, _ := ret[0].(db.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockDeadLetterQuerierMockRecorder) GetDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockDeadLetterQuerier)(nil).GetDeadLetter), ctx, id)
+}
+
+// GetJob mocks base method.
+func (m *MockDeadLetterQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockDeadLetterQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockDeadLetterQuerier)(nil).GetJob), ctx, id)
+}
+
+// GetJobRun mocks base method.
+func (m *MockDeadLetterQuerier) GetJobRun(ctx context.Context, id string) (db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobRun", ctx, id)
+	ret0, _ := ret[0].(db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobRun indicates an expected call of GetJobRun.
+func (mr *MockDeadLetterQuerierMockRecorder) GetJobRun(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobRun", reflect.TypeOf((*MockDeadLetterQuerier)(nil).GetJobRun), ctx, id)
+}
+
+// ListJobRunAttempts mocks base method.
+func (m *MockDeadLetterQuerier) ListJobRunAttempts(ctx context.Context, runID string) ([]db.JobRunAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunAttempts", ctx, runID)
+	ret0, _ := ret[0].([]db.JobRunAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunAttempts indicates an expected call of ListJobRunAttempts.
+func (mr *MockDeadLetterQuerierMockRecorder) ListJobRunAttempts(ctx, runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunAttempts", reflect.TypeOf((*MockDeadLetterQuerier)(nil).ListJobRunAttempts), ctx, runID)
+}
+
+// ListOpenDeadLetters mocks base method.
+func (m *MockDeadLetterQuerier) ListOpenDeadLetters(ctx context.Context, arg db.ListOpenDeadLettersParams) ([]db.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenDeadLetters", ctx, arg)
+	ret0, _ := ret[0].([]db.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenDeadLetters indicates an expected call of ListOpenDeadLetters.
+func (mr *MockDeadLetterQuerierMockRecorder) ListOpenDeadLetters(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenDeadLetters", reflect.TypeOf((*MockDeadLetterQuerier)(nil).ListOpenDeadLetters), ctx, arg)
+}
+
+// ResolveDeadLetter mocks base method.
+func (m *MockDeadLetterQuerier) ResolveDeadLetter(ctx context.Context, arg db.ResolveDeadLetterParams) (db.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveDeadLetter", ctx, arg)
+	ret0, _ := ret[0].(db.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveDeadLetter indicates an expected call of ResolveDeadLetter.
+func (mr *MockDeadLetterQuerierMockRecorder) ResolveDeadLetter(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveDeadLetter", reflect.TypeOf((*MockDeadLetterQuerier)(nil).ResolveDeadLetter), ctx, arg)
+}
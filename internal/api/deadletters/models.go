@@ -0,0 +1,67 @@
+package deadletters
+
+import (
+	"errors"
+	"time"
+)
+
+// Resolutions of dead letters
+const (
+	ResolutionRequeued  = "requeued"
+	ResolutionDiscarded = "discarded"
+)
+
+// AttemptResponse describes one attempt of a dead-lettered run
+type AttemptResponse struct {
+	Attempt       int64      `json:"attempt"`
+	Status        string     `json:"status"`
+	ExitCode      *int64     `json:"exit_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	LeaseOwner    string     `json:"lease_owner,omitempty"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    time.Time  `json:"finished_at"`
+}
+
+// DeadLetterResponse represents a dead letter in responses. JobName,
+// RunNumber and History are only filled in for single dead letters.
+type DeadLetterResponse struct {
+	ID            string            `json:"id"`
+	JobID         string            `json:"job_id"`
+	JobName       string            `json:"job_name,omitempty"`
+	RunID         string            `json:"run_id"`
+	RunNumber     int64             `json:"run_number,omitempty"`
+	Attempts      int64             `json:"attempts"`
+	Error         string            `json:"error,omitempty"`
+	FailureReason string            `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Resolution    string            `json:"resolution,omitempty"`
+	ResolvedAt    *time.Time        `json:"resolved_at,omitempty"`
+	RequeuedRunID string            `json:"requeued_run_id,omitempty"`
+	History       []AttemptResponse `json:"history,omitempty"`
+}
+
+// ListParams represents parameters for listing dead letters
+type ListParams struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// Validate checks if the list parameters are valid
+func (p *ListParams) Validate() error {
+	if p.Page < 1 {
+		return errors.New("page must be greater than 0")
+	}
+	if p.PageSize < 1 || p.PageSize > 100 {
+		return errors.New("page size must be between 1 and 100")
+	}
+	return nil
+}
+
+// ListResponse represents a paginated list of open dead letters
+type ListResponse struct {
+	DeadLetters []DeadLetterResponse `json:"dead_letters"`
+	TotalCount  int64                `json:"total_count"`
+	Page        int                  `json:"page"`
+	PageSize    int                  `json:"page_size"`
+}
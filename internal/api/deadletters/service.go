@@ -0,0 +1,227 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=deadletters github.com/klauern/gopher-tower/internal/api/deadletters DeadLetterQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=deadletters github.com/klauern/gopher-tower/internal/api/deadletters Service
+//go:generate go tool mockgen -destination=mock_starter_test.go -package=deadletters github.com/klauern/gopher-tower/internal/api/deadletters Starter
+
+package deadletters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+)
+
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrAlreadyResolved    = errors.New("dead letter already resolved")
+	ErrJobNotFound        = errors.New("job not found")
+	ErrInvalidRun         = errors.New("job cannot be run")
+	ErrUnavailable        = errors.New("executor unavailable")
+)
+
+// DeadLetterQuerier defines the interface for dead letter database
+// operations
+type DeadLetterQuerier interface {
+	GetDeadLetter(ctx context.Context, id string) (db.DeadLetter, error)
+	ListOpenDeadLetters(ctx context.Context, arg db.ListOpenDeadLettersParams) ([]db.DeadLetter, error)
+	CountOpenDeadLetters(ctx context.Context) (int64, error)
+	ResolveDeadLetter(ctx context.Context, arg db.ResolveDeadLetterParams) (db.DeadLetter, error)
+	ListJobRunAttempts(ctx context.Context, runID string) ([]db.JobRunAttempt, error)
+	GetJobRun(ctx context.Context, id string) (db.JobRun, error)
+	GetJob(ctx context.Context, id string) (db.Job, error)
+}
+
+// Starter starts job runs; it is implemented by executor.Executor
+type Starter interface {
+	StartRun(ctx context.Context, jobID string, opts executor.RunOptions) (db.JobRun, error)
+}
+
+// Service provides dead letter operations
+type Service interface {
+	ListDeadLetters(ctx context.Context, params ListParams) (*ListResponse, error)
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetterResponse, error)
+	// Requeue starts a new run of the dead-lettered job with the inputs,
+	// standard input and input files of the dead-lettered run and resolves
+	// the dead letter with it
+	Requeue(ctx context.Context, id string) (*DeadLetterResponse, error)
+	Discard(ctx context.Context, id string) (*DeadLetterResponse, error)
+}
+
+// deadLetterService implements the Service interface
+type deadLetterService struct {
+	queries DeadLetterQuerier
+	starter Starter
+}
+
+// NewService creates a new dead letter service
+func NewService(queries DeadLetterQuerier, starter Starter) Service {
+	return &deadLetterService{queries: queries, starter: starter}
+}
+
+// ListDeadLetters returns a paginated list of open dead letters, newest
+// first
+func (s *deadLetterService) ListDeadLetters(ctx context.Context, params ListParams) (*ListResponse, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	letters, err := s.queries.ListOpenDeadLetters(ctx, db.ListOpenDeadLettersParams{
+		Limit:  int64(params.PageSize),
+		Offset: int64((params.Page - 1) * params.PageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.queries.CountOpenDeadLetters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]DeadLetterResponse, len(letters))
+	for i, letter := range letters {
+		responses[i] = *toDeadLetterResponse(letter)
+	}
+	return &ListResponse{
+		DeadLetters: responses,
+		TotalCount:  total,
+		Page:        params.Page,
+		PageSize:    params.PageSize,
+	}, nil
+}
+
+// GetDeadLetter retrieves a dead letter with the history of its run
+func (s *deadLetterService) GetDeadLetter(ctx context.Context, id string) (*DeadLetterResponse, error) {
+	letter, err := s.getDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toDeadLetterResponse(letter)
+
+	attempts, err := s.queries.ListJobRunAttempts(ctx, letter.RunID)
+	if err != nil {
+		return nil, err
+	}
+	resp.History = make([]AttemptResponse, len(attempts))
+	for i, attempt := range attempts {
+		resp.History[i] = toAttemptResponse(attempt)
+	}
+
+	if run, err := s.queries.GetJobRun(ctx, letter.RunID); err == nil {
+		resp.RunNumber = run.RunNumber
+	} else if !db.IsNotFound(err) {
+		return nil, err
+	}
+	if job, err := s.queries.GetJob(ctx, letter.JobID); err == nil {
+		resp.JobName = job.Name
+	} else if !db.IsNotFound(err) {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Requeue starts a new run of the job and marks the dead letter requeued
+func (s *deadLetterService) Requeue(ctx context.Context, id string) (*DeadLetterResponse, error) {
+	letter, err := s.getDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if letter.Resolution.Valid {
+		return nil, ErrAlreadyResolved
+	}
+
+	var opts executor.RunOptions
+	if failed, err := s.queries.GetJobRun(ctx, letter.RunID); err == nil {
+		opts = executor.RunOptionsOf(failed)
+	} else if !db.IsNotFound(err) {
+		return nil, err
+	}
+
+	run, err := s.starter.StartRun(ctx, letter.JobID, opts)
+	if err != nil {
+		switch {
+		case db.IsNotFound(err):
+			return nil, ErrJobNotFound
+		case executor.IsInvalidRun(err):
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRun, err)
+		case errors.Is(err, executor.ErrShuttingDown):
+			return nil, ErrUnavailable
+		}
+		return nil, err
+	}
+	return s.resolve(ctx, id, ResolutionRequeued, db.StringToNullString(run.ID))
+}
+
+// Discard closes the dead letter without running the job again
+func (s *deadLetterService) Discard(ctx context.Context, id string) (*DeadLetterResponse, error) {
+	letter, err := s.getDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if letter.Resolution.Valid {
+		return nil, ErrAlreadyResolved
+	}
+	return s.resolve(ctx, id, ResolutionDiscarded, sql.NullString{})
+}
+
+func (s *deadLetterService) resolve(ctx context.Context, id, resolution string, runID sql.NullString) (*DeadLetterResponse, error) {
+	letter, err := s.queries.ResolveDeadLetter(ctx, db.ResolveDeadLetterParams{
+		Resolution:    db.StringToNullString(resolution),
+		RequeuedRunID: runID,
+		ID:            id,
+	})
+	if err != nil {
+		if db.IsNotFound(err) {
+			// Resolved concurrently
+			return nil, ErrAlreadyResolved
+		}
+		return nil, err
+	}
+	return toDeadLetterResponse(letter), nil
+}
+
+func (s *deadLetterService) getDeadLetter(ctx context.Context, id string) (db.DeadLetter, error) {
+	letter, err := s.queries.GetDeadLetter(ctx, id)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return db.DeadLetter{}, ErrDeadLetterNotFound
+		}
+		return db.DeadLetter{}, err
+	}
+	return letter, nil
+}
+
+// toDeadLetterResponse converts a db.DeadLetter to a DeadLetterResponse
+func toDeadLetterResponse(letter db.DeadLetter) *DeadLetterResponse {
+	return &DeadLetterResponse{
+		ID:            letter.ID,
+		JobID:         letter.JobID,
+		RunID:         letter.RunID,
+		Attempts:      letter.Attempts,
+		Error:         letter.Error.String,
+		FailureReason: letter.FailureReason.String,
+		CreatedAt:     letter.CreatedAt,
+		Resolution:    letter.Resolution.String,
+		ResolvedAt:    db.NullTimeToTimePtr(letter.ResolvedAt),
+		RequeuedRunID: letter.RequeuedRunID.String,
+	}
+}
+
+// toAttemptResponse converts a db.JobRunAttempt to an AttemptResponse
+func toAttemptResponse(attempt db.JobRunAttempt) AttemptResponse {
+	resp := AttemptResponse{
+		Attempt:       attempt.Attempt,
+		Status:        attempt.Status,
+		Error:         attempt.Error.String,
+		FailureReason: attempt.FailureReason.String,
+		LeaseOwner:    attempt.LeaseOwner.String,
+		StartedAt:     db.NullTimeToTimePtr(attempt.StartedAt),
+		FinishedAt:    attempt.FinishedAt,
+	}
+	if attempt.ExitCode.Valid {
+		resp.ExitCode = &attempt.ExitCode.Int64
+	}
+	return resp
+}
@@ -0,0 +1,186 @@
+package deadletters
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testDeadLetterID = "0e6c2b8a-4f1d-4a8e-9b7c-2d3e4f5a6b7c"
+	testJobID        = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+)
+
+type serviceTest struct {
+	querier *MockDeadLetterQuerier
+	starter *MockStarter
+	svc     Service
+}
+
+func setupService(t *testing.T) *serviceTest {
+	ctrl := gomock.NewController(t)
+	st := &serviceTest{
+		querier: NewMockDeadLetterQuerier(ctrl),
+		starter: NewMockStarter(ctrl),
+	}
+	st.svc = NewService(st.querier, st.starter)
+	return st
+}
+
+func openDeadLetter() db.DeadLetter {
+	return db.DeadLetter{
+		ID:            testDeadLetterID,
+		RunID:         "run-1",
+		JobID:         testJobID,
+		Attempts:      3,
+		Error:         sql.NullString{String: "exit status 1", Valid: true},
+		FailureReason: sql.NullString{String: "timeout", Valid: true},
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestDeadLetterService_ListDeadLetters(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().
+		ListOpenDeadLetters(gomock.Any(), db.ListOpenDeadLettersParams{Limit: 10, Offset: 10}).
+		Return([]db.DeadLetter{openDeadLetter()}, nil)
+	st.querier.EXPECT().CountOpenDeadLetters(gomock.Any()).Return(int64(11), nil)
+
+	resp, err := st.svc.ListDeadLetters(context.Background(), ListParams{Page: 2, PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, resp.DeadLetters, 1)
+	assert.Equal(t, int64(11), resp.TotalCount)
+	assert.Equal(t, "run-1", resp.DeadLetters[0].RunID)
+	assert.Equal(t, "exit status 1", resp.DeadLetters[0].Error)
+	assert.Equal(t, "timeout", resp.DeadLetters[0].FailureReason)
+
+	_, err = st.svc.ListDeadLetters(context.Background(), ListParams{Page: 0, PageSize: 10})
+	assert.Error(t, err)
+}
+
+func TestDeadLetterService_GetDeadLetter(t *testing.T) {
+	t.Run("with history", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetDeadLetter(gomock.Any(), testDeadLetterID).Return(openDeadLetter(), nil)
+		st.querier.EXPECT().ListJobRunAttempts(gomock.Any(), "run-1").Return([]db.JobRunAttempt{
+			{Attempt: 1, Status: executor.StatusFailed, ExitCode: sql.NullInt64{Int64: 1, Valid: true}, Error: sql.NullString{String: "exit status 1", Valid: true}},
+			{Attempt: 2, Status: executor.StatusFailed, FailureReason: sql.NullString{String: executor.ReasonLeaseExpired, Valid: true}},
+		}, nil)
+		st.querier.EXPECT().GetJobRun(gomock.Any(), "run-1").Return(db.JobRun{ID: "run-1", RunNumber: 7}, nil)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID, Name: "nightly"}, nil)
+
+		resp, err := st.svc.GetDeadLetter(context.Background(), testDeadLetterID)
+		require.NoError(t, err)
+		assert.Equal(t, "nightly", resp.JobName)
+		assert.Equal(t, int64(7), resp.RunNumber)
+		require.Len(t, resp.History, 2)
+		require.NotNil(t, resp.History[0].ExitCode)
+		assert.Equal(t, int64(1), *resp.History[0].ExitCode)
+		assert.Nil(t, resp.History[1].ExitCode)
+		assert.Equal(t, executor.ReasonLeaseExpired, resp.History[1].FailureReason)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetDeadLetter(gomock.Any(), testDeadLetterID).Return(db.DeadLetter{}, sql.ErrNoRows)
+
+		_, err := st.svc.GetDeadLetter(context.Background(), testDeadLetterID)
+		assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+	})
+}
+
+func TestDeadLetterService_Requeue(t *testing.T) {
+	resolved := openDeadLetter()
+	resolved.Resolution = sql.NullString{String: ResolutionDiscarded, Valid: true}
+
+	tests := []struct {
+		name     string
+		letter   db.DeadLetter
+		runErr   error
+		startErr error
+		resolve  error
+		wantErr  error
+	}{
+		{name: "requeued", letter: openDeadLetter()},
+		{name: "failed run deleted", letter: openDeadLetter(), runErr: sql.ErrNoRows},
+		{name: "already resolved", letter: resolved, wantErr: ErrAlreadyResolved},
+		{name: "job deleted", letter: openDeadLetter(), startErr: sql.ErrNoRows, wantErr: ErrJobNotFound},
+		{name: "shutting down", letter: openDeadLetter(), startErr: executor.ErrShuttingDown, wantErr: ErrUnavailable},
+		{name: "resolved concurrently", letter: openDeadLetter(), resolve: sql.ErrNoRows, wantErr: ErrAlreadyResolved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := setupService(t)
+			st.querier.EXPECT().GetDeadLetter(gomock.Any(), testDeadLetterID).Return(tt.letter, nil)
+			if !tt.letter.Resolution.Valid {
+				// The failed run's inputs, standard input and input files
+				// are replayed
+				var want executor.RunOptions
+				failed := db.JobRun{
+					ID:         "run-1",
+					Inputs:     sql.NullString{String: `{"region":"eu-west-1"}`, Valid: true},
+					Stdin:      sql.NullString{String: `{"attachment":"a-1","filename":"stdin","inline":true,"size":3,"sha256":"abc"}`, Valid: true},
+					InputFiles: sql.NullString{String: `[{"attachment":"a-2","filename":"input.csv","path":"data/input.csv","size":5,"sha256":"def"}]`, Valid: true},
+				}
+				if tt.runErr == nil {
+					want = executor.RunOptions{
+						Inputs: json.RawMessage(failed.Inputs.String),
+						Stdin:  &attachments.Stdin{Attachment: "a-1"},
+						Files:  []attachments.File{{Attachment: "a-2", Path: "data/input.csv"}},
+					}
+				}
+				st.querier.EXPECT().GetJobRun(gomock.Any(), "run-1").Return(failed, tt.runErr)
+				st.starter.EXPECT().StartRun(gomock.Any(), testJobID, want).Return(db.JobRun{ID: "run-2"}, tt.startErr)
+			}
+			if !tt.letter.Resolution.Valid && tt.startErr == nil {
+				st.querier.EXPECT().
+					ResolveDeadLetter(gomock.Any(), db.ResolveDeadLetterParams{
+						Resolution:    sql.NullString{String: ResolutionRequeued, Valid: true},
+						RequeuedRunID: sql.NullString{String: "run-2", Valid: true},
+						ID:            testDeadLetterID,
+					}).
+					DoAndReturn(func(_ context.Context, arg db.ResolveDeadLetterParams) (db.DeadLetter, error) {
+						letter := tt.letter
+						letter.Resolution = arg.Resolution
+						letter.RequeuedRunID = arg.RequeuedRunID
+						return letter, tt.resolve
+					})
+			}
+
+			resp, err := st.svc.Requeue(context.Background(), testDeadLetterID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ResolutionRequeued, resp.Resolution)
+			assert.Equal(t, "run-2", resp.RequeuedRunID)
+		})
+	}
+}
+
+func TestDeadLetterService_Discard(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().GetDeadLetter(gomock.Any(), testDeadLetterID).Return(openDeadLetter(), nil)
+	st.querier.EXPECT().
+		ResolveDeadLetter(gomock.Any(), db.ResolveDeadLetterParams{
+			Resolution: sql.NullString{String: ResolutionDiscarded, Valid: true},
+			ID:         testDeadLetterID,
+		}).
+		Return(db.DeadLetter{ID: testDeadLetterID, Resolution: sql.NullString{String: ResolutionDiscarded, Valid: true}}, nil)
+
+	resp, err := st.svc.Discard(context.Background(), testDeadLetterID)
+	require.NoError(t, err)
+	assert.Equal(t, ResolutionDiscarded, resp.Resolution)
+	assert.Empty(t, resp.RequeuedRunID)
+}
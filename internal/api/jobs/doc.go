@@ -15,7 +15,7 @@ Core Components:
 Example Usage:
 
 	// Create a new job service
-	jobService := jobs.NewService(dbQueries)
+	jobService := jobs.NewService(dbQueries, jobExecutor)
 
 	// Create a new job handler
 	jobHandler := jobs.NewHandler(jobService)
@@ -29,7 +29,7 @@ API Endpoints:
 	GET    /jobs/{id}  - Get job details
 	PUT    /jobs/{id}  - Update job status/details
 	DELETE /jobs/{id}  - Delete a job
-	GET    /jobs       - List jobs with pagination and filters
+	GET    /jobs       - List or search jobs with pagination and filters
 
 Request/Response Examples:
 
@@ -56,6 +56,32 @@ List Jobs:
 		"page_size": 10
 	}
 
+Search Jobs:
+
+	GET /jobs?q=connection+refused&page=1&page_size=10
+
+	Response:
+	{
+		"jobs": [
+			{
+				"id": "...",
+				"name": "nightly backup",
+				...
+				"match": {
+					"run_number": 7,
+					"snippet": "dial tcp 10.0.0.5:5432: <mark>connection</mark> <mark>refused</mark>",
+					"score": 3.2
+				}
+			}
+		],
+		...
+	}
+
+Searches match jobs whose name, description or output of a finished run
+contains all words of q; a word ending in * matches by prefix. Each job is
+listed once, with its best match, best matches first: a match in the name
+ranks above one in the description, which ranks above one in output.
+
 Error Handling:
 
 The package uses standard HTTP status codes:
@@ -189,6 +189,8 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 		params.Status = JobStatus(status)
 	}
 
+	params.Query = r.URL.Query().Get("q")
+
 	resp, err := h.service.ListJobs(r.Context(), params)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
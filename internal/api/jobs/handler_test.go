@@ -253,6 +253,24 @@ func TestListJobs(t *testing.T) {
 			wantStatus: http.StatusOK,
 			wantCount:  1,
 		},
+		{
+			name:  "search",
+			query: "?q=connection+refused",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().
+					ListJobs(gomock.Any(), JobListParams{Page: 1, PageSize: 10, Query: "connection refused"}).
+					Return(&JobListResponse{
+						Jobs: []JobResponse{
+							{ID: "1", Name: "Job 1", Match: &SearchMatch{Snippet: "<mark>connection</mark> <mark>refused</mark>", Score: 2}},
+						},
+						TotalCount: 1,
+						Page:       1,
+						PageSize:   10,
+					}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
 		{
 			name:       "invalid page",
 			query:      "?page=invalid",
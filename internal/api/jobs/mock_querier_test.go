@@ -100,6 +100,21 @@ func (mr *MockJobQuerierMockRecorder) ListJobs(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockJobQuerier)(nil).ListJobs), ctx, arg)
 }
 
+// SearchJobs mocks base method.
+func (m *MockJobQuerier) SearchJobs(ctx context.Context, arg db.SearchJobsParams) ([]db.SearchJobsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchJobs", ctx, arg)
+	ret0, _ := ret[0].([]db.SearchJobsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchJobs indicates an expected call of SearchJobs.
+func (mr *MockJobQuerierMockRecorder) SearchJobs(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchJobs", reflect.TypeOf((*MockJobQuerier)(nil).SearchJobs), ctx, arg)
+}
+
 // UpdateJob mocks base method.
 func (m *MockJobQuerier) UpdateJob(ctx context.Context, arg db.UpdateJobParams) (db.Job, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +55,21 @@ func (mr *MockServiceMockRecorder) CreateJob(ctx, req, ownerID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockService)(nil).CreateJob), ctx, req, ownerID)
 }
 
+// CreateTemplatedJob mocks base method.
+func (m *MockService) CreateTemplatedJob(ctx context.Context, req JobRequest, ownerID string, origin TemplateOrigin) (*JobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTemplatedJob", ctx, req, ownerID, origin)
+	ret0, _ := ret[0].(*JobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTemplatedJob indicates an expected call of CreateTemplatedJob.
+func (mr *MockServiceMockRecorder) CreateTemplatedJob(ctx, req, ownerID, origin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTemplatedJob", reflect.TypeOf((*MockService)(nil).CreateTemplatedJob), ctx, req, ownerID, origin)
+}
+
 // DeleteJob mocks base method.
 func (m *MockService) DeleteJob(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,53 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/jobs (interfaces: TriggerFirer)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_triggers_test.go -package=jobs github.com/klauern/gopher-tower/internal/api/jobs TriggerFirer
+//
+
+// Package jobs is a generated GoMock package.
+package jobs
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTriggerFirer is a mock of TriggerFirer interface.
+type MockTriggerFirer struct {
+	ctrl     *gomock.Controller
+	recorder *MockTriggerFirerMockRecorder
+	isgomock struct{}
+}
+
+// MockTriggerFirerMockRecorder is the mock recorder for MockTriggerFirer.
+type MockTriggerFirerMockRecorder struct {
+	mock *MockTriggerFirer
+}
+
+// NewMockTriggerFirer creates a new mock instance.
+func NewMockTriggerFirer(ctrl *gomock.Controller) *MockTriggerFirer {
+	mock := &MockTriggerFirer{ctrl: ctrl}
+	mock.recorder = &MockTriggerFirerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTriggerFirer) EXPECT() *MockTriggerFirerMockRecorder {
+	return m.recorder
+}
+
+// FireTriggers mocks base method.
+func (m *MockTriggerFirer) FireTriggers(ctx context.Context, jobID, jobStatus string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FireTriggers", ctx, jobID, jobStatus)
+}
+
+// FireTriggers indicates an expected call of FireTriggers.
+func (mr *MockTriggerFirerMockRecorder) FireTriggers(ctx, jobID, jobStatus any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FireTriggers", reflect.TypeOf((*MockTriggerFirer)(nil).FireTriggers), ctx, jobID, jobStatus)
+}
@@ -1,10 +1,20 @@
 package jobs
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/inputs"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/matrix"
+	"github.com/klauern/gopher-tower/internal/schedule"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/testreport"
+	"github.com/klauern/gopher-tower/internal/watch"
 )
 
 // JobStatus represents the current state of a job
@@ -29,11 +39,50 @@ func (s JobStatus) IsValid() bool {
 
 // JobRequest represents the request to create or update a job
 type JobRequest struct {
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	Status      JobStatus  `json:"status"`
-	StartDate   *time.Time `json:"start_date,omitempty"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Status      JobStatus              `json:"status"`
+	StartDate   *time.Time             `json:"start_date,omitempty"`
+	EndDate     *time.Time             `json:"end_date,omitempty"`
+	Plugin      string                 `json:"plugin,omitempty"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+	// Sandbox isolates the job's processes; see internal/sandbox
+	Sandbox bool `json:"sandbox,omitempty"`
+	// Limits bounds the resources of each run; see internal/limits
+	Limits *limits.Limits `json:"limits,omitempty"`
+	// AgentLabels routes runs to a remote agent offering all of the labels
+	AgentLabels []string `json:"agent_labels,omitempty"`
+	// MaxRetries is how often a failed run is retried before it is
+	// dead-lettered
+	MaxRetries int64 `json:"max_retries,omitempty"`
+	// InputSchema is the JSON Schema the inputs of each run are validated
+	// against; see internal/inputs. Jobs without one take no inputs.
+	InputSchema map[string]interface{} `json:"input_schema,omitempty"`
+	// Matrix fans each run out into one child run per combination; see
+	// internal/matrix
+	Matrix *matrix.Matrix `json:"matrix,omitempty"`
+	// Source is the repository checked out before each run; see
+	// internal/source
+	Source *source.Source `json:"source,omitempty"`
+	// Caches are restored before and saved after each run; see
+	// internal/cache
+	Caches []cache.Entry `json:"caches,omitempty"`
+	// Interactive runs the job's process under a pseudo-terminal users can
+	// attach to; see internal/terminal
+	Interactive bool `json:"interactive,omitempty"`
+	// FileTrigger starts a run whenever files settle in a directory; see
+	// internal/watch. The run receives the files as the "files" input.
+	FileTrigger *watch.Trigger `json:"file_trigger,omitempty"`
+	// Schedule starts runs at the fire times of a cron expression; see
+	// internal/schedule. The runs are started without inputs.
+	Schedule *schedule.Schedule `json:"schedule,omitempty"`
+	// TestReports are globs of the JUnit XML reports each run writes,
+	// relative to its working directory; see internal/testreport
+	TestReports []string `json:"test_reports,omitempty"`
+	// RequiresApproval holds each run until a user with one of the
+	// ApproverRoles (users.role) approves it
+	RequiresApproval bool     `json:"requires_approval,omitempty"`
+	ApproverRoles    []string `json:"approver_roles,omitempty"`
 }
 
 // Validate checks if the job request is valid
@@ -41,6 +90,83 @@ func (r *JobRequest) Validate() error {
 	if r.Name == "" {
 		return errors.New("name is required")
 	}
+	if r.Config != nil && r.Plugin == "" {
+		return errors.New("plugin is required when config is set")
+	}
+	if r.Limits != nil {
+		if err := r.Limits.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, label := range r.AgentLabels {
+		if strings.TrimSpace(label) == "" {
+			return errors.New("agent labels must not be empty")
+		}
+	}
+	if r.MaxRetries < 0 {
+		return errors.New("max_retries must not be negative")
+	}
+	if r.InputSchema != nil {
+		data, err := json.Marshal(r.InputSchema)
+		if err != nil {
+			return err
+		}
+		if _, err := inputs.ParseSchema(data); err != nil {
+			return err
+		}
+	}
+	if r.Matrix != nil {
+		if err := r.Matrix.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Source != nil {
+		if err := r.Source.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := cache.ValidateEntries(r.Caches); err != nil {
+		return err
+	}
+	if err := testreport.Validate(r.TestReports); err != nil {
+		return err
+	}
+	if r.FileTrigger != nil {
+		if err := r.FileTrigger.Validate(); err != nil {
+			return err
+		}
+		properties, _ := r.InputSchema["properties"].(map[string]interface{})
+		if _, ok := properties[watch.FilesInput]; !ok {
+			return errors.New("input_schema must declare the files input when file_trigger is set")
+		}
+	}
+	if r.Schedule != nil {
+		if err := r.Schedule.Validate(); err != nil {
+			return err
+		}
+		if r.InputSchema != nil {
+			data, err := json.Marshal(r.InputSchema)
+			if err != nil {
+				return err
+			}
+			// Validated above
+			schema, _ := inputs.ParseSchema(data)
+			if err := schema.Validate(map[string]interface{}{}); err != nil {
+				return errors.New("input_schema must not require inputs when schedule is set")
+			}
+		}
+	}
+	if r.Interactive && len(r.AgentLabels) > 0 {
+		return errors.New("interactive jobs cannot run on agents")
+	}
+	if r.RequiresApproval && len(r.ApproverRoles) == 0 {
+		return errors.New("approver_roles is required when requires_approval is set")
+	}
+	for _, role := range r.ApproverRoles {
+		if strings.TrimSpace(role) == "" {
+			return errors.New("approver roles must not be empty")
+		}
+	}
 
 	switch r.Status {
 	case JobStatusPending, JobStatusActive, JobStatusComplete, JobStatusFailed:
@@ -52,15 +178,56 @@ func (r *JobRequest) Validate() error {
 
 // JobResponse represents a job in responses
 type JobResponse struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	Status      JobStatus  `json:"status"`
-	StartDate   *time.Time `json:"start_date,omitempty"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	OwnerID     string     `json:"owner_id,omitempty"`
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Status      JobStatus              `json:"status"`
+	StartDate   *time.Time             `json:"start_date,omitempty"`
+	EndDate     *time.Time             `json:"end_date,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	OwnerID     string                 `json:"owner_id,omitempty"`
+	Plugin      string                 `json:"plugin,omitempty"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+	Sandbox     bool                   `json:"sandbox,omitempty"`
+	Limits      *limits.Limits         `json:"limits,omitempty"`
+	AgentLabels []string               `json:"agent_labels,omitempty"`
+	MaxRetries  int64                  `json:"max_retries,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema,omitempty"`
+	Matrix      *matrix.Matrix         `json:"matrix,omitempty"`
+	Source      *source.Source         `json:"source,omitempty"`
+	Caches      []cache.Entry          `json:"caches,omitempty"`
+	Interactive bool                   `json:"interactive,omitempty"`
+	FileTrigger *watch.Trigger         `json:"file_trigger,omitempty"`
+	Schedule    *schedule.Schedule     `json:"schedule,omitempty"`
+	TestReports []string               `json:"test_reports,omitempty"`
+	Template    *TemplateOrigin        `json:"template,omitempty"`
+
+	RequiresApproval bool     `json:"requires_approval,omitempty"`
+	ApproverRoles    []string `json:"approver_roles,omitempty"`
+
+	// Match is where a search found the job; only set in search results
+	Match *SearchMatch `json:"match,omitempty"`
+}
+
+// SearchMatch is the best match of a search in a job
+type SearchMatch struct {
+	// RunNumber is the run whose output matched; nil when the job's name
+	// or description did
+	RunNumber *int64 `json:"run_number,omitempty"`
+	// Snippet is the matching text with matches wrapped in <mark> and
+	// </mark>
+	Snippet string `json:"snippet"`
+	// Score ranks the match among the results; higher is better
+	Score float64 `json:"score"`
+}
+
+// TemplateOrigin records the template version and parameters a job was
+// rendered from
+type TemplateOrigin struct {
+	ID      string                 `json:"id"`
+	Version int64                  `json:"version"`
+	Params  map[string]interface{} `json:"params,omitempty"`
 }
 
 // JobListParams represents parameters for listing jobs
@@ -68,8 +235,14 @@ type JobListParams struct {
 	Page     int       `json:"page"`
 	PageSize int       `json:"page_size"`
 	Status   JobStatus `json:"status,omitempty"`
+	// Query searches the jobs' names, descriptions and the output of their
+	// finished runs for all of its words, best match first
+	Query string `json:"q,omitempty"`
 }
 
+// MaxQueryLength bounds the length of a search query in bytes
+const MaxQueryLength = 512
+
 // Validate checks if the list parameters are valid
 func (p *JobListParams) Validate() error {
 	if p.Page < 1 {
@@ -78,6 +251,9 @@ func (p *JobListParams) Validate() error {
 	if p.PageSize < 1 {
 		return errors.New("page_size must be greater than 0")
 	}
+	if len(p.Query) > MaxQueryLength {
+		return errors.New("query is too long")
+	}
 	if p.Status != "" {
 		switch p.Status {
 		case JobStatusPending, JobStatusActive, JobStatusComplete, JobStatusFailed:
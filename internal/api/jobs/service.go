@@ -1,14 +1,24 @@
 //go:generate go tool mockgen -destination=mock_querier_test.go -package=jobs github.com/klauern/gopher-tower/internal/api/jobs JobQuerier
 //go:generate go tool mockgen -destination=mock_service_test.go -package=jobs github.com/klauern/gopher-tower/internal/api/jobs Service
+//go:generate go tool mockgen -destination=mock_triggers_test.go -package=jobs github.com/klauern/gopher-tower/internal/api/jobs TriggerFirer
 
 package jobs
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/cache"
 	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/matrix"
+	"github.com/klauern/gopher-tower/internal/schedule"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/watch"
 )
 
 var (
@@ -23,25 +33,38 @@ type JobQuerier interface {
 	UpdateJob(ctx context.Context, arg db.UpdateJobParams) (db.Job, error)
 	DeleteJob(ctx context.Context, id string) error
 	ListJobs(ctx context.Context, arg db.ListJobsParams) ([]db.Job, error)
+	SearchJobs(ctx context.Context, arg db.SearchJobsParams) ([]db.SearchJobsRow, error)
 }
 
 // Service provides job management operations
 type Service interface {
 	CreateJob(ctx context.Context, req JobRequest, ownerID string) (*JobResponse, error)
+	// CreateTemplatedJob creates a job rendered from a template, recording
+	// the template version and parameters it was rendered from
+	CreateTemplatedJob(ctx context.Context, req JobRequest, ownerID string, origin TemplateOrigin) (*JobResponse, error)
 	GetJob(ctx context.Context, id string) (*JobResponse, error)
 	UpdateJob(ctx context.Context, id string, req JobRequest) (*JobResponse, error)
 	DeleteJob(ctx context.Context, id string) error
 	ListJobs(ctx context.Context, params JobListParams) (*JobListResponse, error)
 }
 
+// TriggerFirer fires the completion triggers of jobs; it is implemented by
+// executor.Executor
+type TriggerFirer interface {
+	FireTriggers(ctx context.Context, jobID, jobStatus string)
+}
+
 // jobService implements the Service interface
 type jobService struct {
-	queries JobQuerier
+	queries  JobQuerier
+	triggers TriggerFirer
 }
 
-// NewService creates a new job service
-func NewService(queries JobQuerier) Service {
-	return &jobService{queries: queries}
+// NewService creates a new job service. Updates setting a job's status to
+// complete or failed fire its completion triggers through triggers, unless
+// it is nil.
+func NewService(queries JobQuerier, triggers TriggerFirer) Service {
+	return &jobService{queries: queries, triggers: triggers}
 }
 
 // generateID generates a new UUID for job IDs
@@ -51,19 +74,69 @@ func generateID() string {
 
 // CreateJob creates a new job
 func (s *jobService) CreateJob(ctx context.Context, req JobRequest, ownerID string) (*JobResponse, error) {
+	return s.createJob(ctx, req, ownerID, nil)
+}
+
+// CreateTemplatedJob creates a new job rendered from a template
+func (s *jobService) CreateTemplatedJob(ctx context.Context, req JobRequest, ownerID string, origin TemplateOrigin) (*JobResponse, error) {
+	return s.createJob(ctx, req, ownerID, &origin)
+}
+
+func (s *jobService) createJob(ctx context.Context, req JobRequest, ownerID string, origin *TemplateOrigin) (*JobResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, ErrInvalidJob
 	}
+	pluginConfig, err := encodeConfig(req.Config)
+	if err != nil {
+		return nil, ErrInvalidJob
+	}
+	jobLimits, err := encodeLimits(req.Limits)
+	if err != nil {
+		return nil, ErrInvalidJob
+	}
+	inputSchema, err := encodeConfig(req.InputSchema)
+	if err != nil {
+		return nil, ErrInvalidJob
+	}
+	jobMatrix := encodeMatrix(req.Matrix)
 
-	job, err := s.queries.CreateJob(ctx, db.CreateJobParams{
-		ID:          generateID(),
-		Name:        req.Name,
-		Description: db.StringToNullString(req.Description),
-		Status:      string(req.Status),
-		StartDate:   db.TimeToNullTime(req.StartDate),
-		EndDate:     db.TimeToNullTime(req.EndDate),
-		OwnerID:     db.StringToNullString(ownerID),
-	})
+	params := db.CreateJobParams{
+		ID:           generateID(),
+		Name:         req.Name,
+		Description:  db.StringToNullString(req.Description),
+		Status:       string(req.Status),
+		StartDate:    db.TimeToNullTime(req.StartDate),
+		EndDate:      db.TimeToNullTime(req.EndDate),
+		OwnerID:      db.StringToNullString(ownerID),
+		Plugin:       db.StringToNullString(req.Plugin),
+		PluginConfig: pluginConfig,
+		Sandbox:      req.Sandbox,
+		Limits:       jobLimits,
+		AgentLabels:  encodeLabels(req.AgentLabels),
+		MaxRetries:   req.MaxRetries,
+		InputSchema:  inputSchema,
+		Matrix:       jobMatrix,
+
+		RequiresApproval: req.RequiresApproval,
+		ApproverRoles:    encodeLabels(req.ApproverRoles),
+		Source:           encodeSource(req.Source),
+		Caches:           encodeCaches(req.Caches),
+		Interactive:      req.Interactive,
+		FileTrigger:      encodeFileTrigger(req.FileTrigger),
+		Schedule:         encodeSchedule(req.Schedule),
+		TestReports:      encodeLabels(req.TestReports),
+	}
+	if origin != nil {
+		templateParams, err := encodeConfig(origin.Params)
+		if err != nil {
+			return nil, ErrInvalidJob
+		}
+		params.TemplateID = db.StringToNullString(origin.ID)
+		params.TemplateVersion = db.Int64ToNullInt64(origin.Version)
+		params.TemplateParams = templateParams
+	}
+
+	job, err := s.queries.CreateJob(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -89,20 +162,85 @@ func (s *jobService) UpdateJob(ctx context.Context, id string, req JobRequest) (
 	if err := req.Validate(); err != nil {
 		return nil, ErrInvalidJob
 	}
-
-	job, err := s.queries.UpdateJob(ctx, db.UpdateJobParams{
-		ID:          id,
-		Name:        req.Name,
-		Description: db.StringToNullString(req.Description),
-		Status:      string(req.Status),
-		StartDate:   db.TimeToNullTime(req.StartDate),
-		EndDate:     db.TimeToNullTime(req.EndDate),
-	})
+	pluginConfig, err := encodeConfig(req.Config)
 	if err != nil {
-		if errors.Is(err, db.ErrNotFound) {
+		return nil, ErrInvalidJob
+	}
+	jobLimits, err := encodeLimits(req.Limits)
+	if err != nil {
+		return nil, ErrInvalidJob
+	}
+	inputSchema, err := encodeConfig(req.InputSchema)
+	if err != nil {
+		return nil, ErrInvalidJob
+	}
+	jobMatrix := encodeMatrix(req.Matrix)
+
+	params := db.UpdateJobParams{
+		ID:           id,
+		Name:         req.Name,
+		Description:  db.StringToNullString(req.Description),
+		Status:       string(req.Status),
+		StartDate:    db.TimeToNullTime(req.StartDate),
+		EndDate:      db.TimeToNullTime(req.EndDate),
+		Plugin:       db.StringToNullString(req.Plugin),
+		PluginConfig: pluginConfig,
+		Sandbox:      req.Sandbox,
+		Limits:       jobLimits,
+		AgentLabels:  encodeLabels(req.AgentLabels),
+		MaxRetries:   req.MaxRetries,
+		InputSchema:  inputSchema,
+		Matrix:       jobMatrix,
+
+		RequiresApproval: req.RequiresApproval,
+		ApproverRoles:    encodeLabels(req.ApproverRoles),
+		Source:           encodeSource(req.Source),
+		Caches:           encodeCaches(req.Caches),
+		Interactive:      req.Interactive,
+		FileTrigger:      encodeFileTrigger(req.FileTrigger),
+		Schedule:         encodeSchedule(req.Schedule),
+		TestReports:      encodeLabels(req.TestReports),
+	}
+
+	// With triggers the update only applies to the status read right
+	// before it, so of concurrent updates completing a job exactly one
+	// sees the change; the others read the job again and retry
+	var (
+		job      db.Job
+		previous string
+	)
+	for {
+		if s.triggers != nil {
+			current, err := s.queries.GetJob(ctx, id)
+			if err != nil {
+				if db.IsNotFound(err) {
+					return nil, ErrJobNotFound
+				}
+				return nil, err
+			}
+			previous = current.Status
+			params.PreviousStatus = db.StringToNullString(previous)
+		}
+		job, err = s.queries.UpdateJob(ctx, params)
+		if err == nil {
+			break
+		}
+		if !db.IsNotFound(err) {
+			return nil, err
+		}
+		// Without a status to compare, no row means no job
+		if s.triggers == nil {
 			return nil, ErrJobNotFound
 		}
-		return nil, err
+	}
+
+	// Like the executor finishing a run, a status change to complete or
+	// failed fires the job's completion triggers
+	if s.triggers != nil && job.Status != previous {
+		switch JobStatus(job.Status) {
+		case JobStatusComplete, JobStatusFailed:
+			s.triggers.FireTriggers(ctx, job.ID, job.Status)
+		}
 	}
 
 	return toJobResponse(job), nil
@@ -120,11 +258,15 @@ func (s *jobService) DeleteJob(ctx context.Context, id string) error {
 	return nil
 }
 
-// ListJobs returns a paginated list of jobs
+// ListJobs returns a paginated list of jobs, or of the jobs matching
+// params.Query best match first
 func (s *jobService) ListJobs(ctx context.Context, params JobListParams) (*JobListResponse, error) {
 	if err := params.Validate(); err != nil {
 		return nil, err
 	}
+	if query := matchQuery(params.Query); query != "" {
+		return s.searchJobs(ctx, query, params)
+	}
 
 	jobs, err := s.queries.ListJobs(ctx, db.ListJobsParams{
 		Limit:  int64(params.PageSize),
@@ -159,6 +301,56 @@ func (s *jobService) ListJobs(ctx context.Context, params JobListParams) (*JobLi
 	}, nil
 }
 
+// searchJobs returns a page of the jobs matching the FTS5 query, best
+// match first
+func (s *jobService) searchJobs(ctx context.Context, query string, params JobListParams) (*JobListResponse, error) {
+	rows, err := s.queries.SearchJobs(ctx, db.SearchJobsParams{
+		Query:  query,
+		Limit:  int64(params.PageSize),
+		Offset: int64((params.Page - 1) * params.PageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]JobResponse, 0, len(rows))
+	for _, row := range rows {
+		if params.Status != "" && row.Job.Status != string(params.Status) {
+			continue
+		}
+		resp := toJobResponse(row.Job)
+		// bm25 scores better matches lower
+		resp.Match = &SearchMatch{Snippet: row.Snippet, Score: -row.Score}
+		if row.RunNumber.Valid {
+			resp.Match.RunNumber = &row.RunNumber.Int64
+		}
+		responses = append(responses, *resp)
+	}
+
+	return &JobListResponse{
+		Jobs:       responses,
+		TotalCount: int64(len(responses)),
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+	}, nil
+}
+
+// matchQuery turns a search query into an FTS5 query matching all of its
+// words. Words are quoted so operators and punctuation in them are matched
+// as text; a trailing * keeps its meaning of matching words by prefix.
+func matchQuery(q string) string {
+	words := strings.Fields(q)
+	for i, word := range words {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.TrimRight(word, "*")
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+		if prefix {
+			words[i] += "*"
+		}
+	}
+	return strings.Join(words, " ")
+}
+
 // toJobResponse converts a db.Job to a JobResponse
 func toJobResponse(job db.Job) *JobResponse {
 	return &JobResponse{
@@ -171,5 +363,243 @@ func toJobResponse(job db.Job) *JobResponse {
 		CreatedAt:   job.CreatedAt,
 		UpdatedAt:   job.UpdatedAt,
 		OwnerID:     job.OwnerID.String,
+		Plugin:      job.Plugin.String,
+		Config:      decodeConfig(job.PluginConfig),
+		Sandbox:     job.Sandbox,
+		Limits:      decodeLimits(job.Limits),
+		AgentLabels: decodeLabels(job.AgentLabels),
+		MaxRetries:  job.MaxRetries,
+		InputSchema: decodeConfig(job.InputSchema),
+		Matrix:      decodeMatrix(job.Matrix),
+		Source:      decodeSource(job.Source),
+		Caches:      decodeCaches(job.Caches),
+		Interactive: job.Interactive,
+		FileTrigger: decodeFileTrigger(job.FileTrigger),
+		Schedule:    decodeSchedule(job.Schedule),
+		TestReports: decodeLabels(job.TestReports),
+		Template:    decodeTemplateOrigin(job),
+
+		RequiresApproval: job.RequiresApproval,
+		ApproverRoles:    decodeLabels(job.ApproverRoles),
+	}
+}
+
+// decodeTemplateOrigin returns the template a job was rendered from, if any
+func decodeTemplateOrigin(job db.Job) *TemplateOrigin {
+	if !job.TemplateID.Valid {
+		return nil
+	}
+	return &TemplateOrigin{
+		ID:      job.TemplateID.String,
+		Version: job.TemplateVersion.Int64,
+		Params:  decodeConfig(job.TemplateParams),
+	}
+}
+
+// encodeConfig serializes a plugin configuration for storage
+func encodeConfig(config map[string]interface{}) (sql.NullString, error) {
+	if config == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return db.StringToNullString(string(data)), nil
+}
+
+// decodeConfig parses a stored plugin configuration. Invalid or missing
+// configurations decode to nil.
+func decodeConfig(s sql.NullString) map[string]interface{} {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(s.String), &config); err != nil {
+		return nil
+	}
+	return config
+}
+
+// encodeLimits serializes resource limits for storage. Unset or empty
+// limits are stored as NULL.
+func encodeLimits(l *limits.Limits) (sql.NullString, error) {
+	if l == nil || l.IsZero() {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return db.StringToNullString(string(data)), nil
+}
+
+// decodeLimits parses stored resource limits. Invalid or missing limits
+// decode to nil.
+func decodeLimits(s sql.NullString) *limits.Limits {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var l limits.Limits
+	if err := json.Unmarshal([]byte(s.String), &l); err != nil {
+		return nil
+	}
+	return &l
+}
+
+// encodeMatrix serializes a matrix for storage; no matrix is stored as
+// NULL
+func encodeMatrix(m *matrix.Matrix) sql.NullString {
+	if m == nil {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return db.StringToNullString(string(data))
+}
+
+// decodeMatrix parses a stored matrix. Invalid or missing matrices decode
+// to nil.
+func decodeMatrix(s sql.NullString) *matrix.Matrix {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var m matrix.Matrix
+	if err := json.Unmarshal([]byte(s.String), &m); err != nil {
+		return nil
+	}
+	return &m
+}
+
+// encodeSource serializes a source for storage; no source is stored as
+// NULL
+func encodeSource(src *source.Source) sql.NullString {
+	if src == nil {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(src)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return db.StringToNullString(string(data))
+}
+
+// decodeSource parses a stored source. Invalid or missing sources decode
+// to nil.
+func decodeSource(s sql.NullString) *source.Source {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var src source.Source
+	if err := json.Unmarshal([]byte(s.String), &src); err != nil {
+		return nil
+	}
+	return &src
+}
+
+// encodeCaches serializes cache entries for storage; no entries are
+// stored as NULL
+func encodeCaches(entries []cache.Entry) sql.NullString {
+	if len(entries) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return db.StringToNullString(string(data))
+}
+
+// decodeCaches parses stored cache entries. Invalid or missing entries
+// decode to nil.
+func decodeCaches(s sql.NullString) []cache.Entry {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var entries []cache.Entry
+	if err := json.Unmarshal([]byte(s.String), &entries); err != nil {
+		return nil
+	}
+	return entries
+}
+
+// encodeFileTrigger serializes a file trigger for storage; no trigger is
+// stored as NULL
+func encodeFileTrigger(t *watch.Trigger) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return db.StringToNullString(string(data))
+}
+
+// decodeFileTrigger parses a stored file trigger. Invalid or missing
+// triggers decode to nil.
+func decodeFileTrigger(s sql.NullString) *watch.Trigger {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var t watch.Trigger
+	if err := json.Unmarshal([]byte(s.String), &t); err != nil {
+		return nil
+	}
+	return &t
+}
+
+// encodeSchedule serializes a schedule for storage; no schedule is stored
+// as NULL
+func encodeSchedule(sched *schedule.Schedule) sql.NullString {
+	if sched == nil {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(sched)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return db.StringToNullString(string(data))
+}
+
+// decodeSchedule parses a stored schedule. Invalid or missing schedules
+// decode to nil.
+func decodeSchedule(s sql.NullString) *schedule.Schedule {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var sched schedule.Schedule
+	if err := json.Unmarshal([]byte(s.String), &sched); err != nil {
+		return nil
+	}
+	return &sched
+}
+
+// encodeLabels serializes agent labels, or approver roles and test report
+// patterns, for storage. No labels is stored as NULL, which runs the job on
+// the server.
+func encodeLabels(labels []string) sql.NullString {
+	if len(labels) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return db.StringToNullString(string(data))
+}
+
+// decodeLabels parses stored agent labels, approver roles or test report
+// patterns
+func decodeLabels(s sql.NullString) []string {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var labels []string
+	if err := json.Unmarshal([]byte(s.String), &labels); err != nil {
+		return nil
 	}
+	return labels
 }
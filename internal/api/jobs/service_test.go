@@ -4,10 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/klauern/gopher-tower/internal/cache"
 	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/matrix"
+	"github.com/klauern/gopher-tower/internal/schedule"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/watch"
 	"go.uber.org/mock/gomock"
 )
 
@@ -233,7 +241,7 @@ func TestJobService_CreateJob(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockQuerier := NewMockJobQuerier(ctrl)
-	svc := NewService(mockQuerier)
+	svc := NewService(mockQuerier, nil)
 	ctx := context.Background()
 
 	tests := []struct {
@@ -272,6 +280,353 @@ func TestJobService_CreateJob(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "job with plugin config",
+			req: JobRequest{
+				Name:        "Script Job",
+				Status:      JobStatusPending,
+				Plugin:      "script",
+				Config:      map[string]interface{}{"interpreter": "sh", "body": "echo hi"},
+				Sandbox:     true,
+				Limits:      &limits.Limits{MemoryBytes: 1 << 28, Timeout: limits.Duration(time.Minute)},
+				AgentLabels: []string{"linux", "gpu"},
+				MaxRetries:  2,
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"region": map[string]interface{}{"type": "string"}},
+					"required":   []interface{}{"region"},
+				},
+				Matrix: &matrix.Matrix{
+					Axes:        map[string][]interface{}{"os": {"linux", "darwin"}},
+					MaxParallel: 1,
+					FailFast:    true,
+				},
+				Source: &source.Source{
+					Repository: "https://github.com/acme/etl.git",
+					Ref:        "v1.4.0",
+					Path:       "src",
+				},
+				Caches: []cache.Entry{{
+					Path:        "~/go/pkg/mod",
+					Key:         "go-mod-${{ hashFiles('src/go.sum') }}",
+					RestoreKeys: []string{"go-mod-"},
+				}},
+				RequiresApproval: true,
+				ApproverRoles:    []string{"admin", "release-manager"},
+			},
+			ownerID: "owner123",
+			setup: func() {
+				mockQuerier.EXPECT().
+					CreateJob(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, arg db.CreateJobParams) (db.Job, error) {
+						return db.Job{
+							ID:           arg.ID,
+							Name:         arg.Name,
+							Status:       arg.Status,
+							Plugin:       arg.Plugin,
+							PluginConfig: arg.PluginConfig,
+							Sandbox:      arg.Sandbox,
+							Limits:       arg.Limits,
+							AgentLabels:  arg.AgentLabels,
+							MaxRetries:   arg.MaxRetries,
+							InputSchema:  arg.InputSchema,
+							Matrix:       arg.Matrix,
+							Source:       arg.Source,
+							Caches:       arg.Caches,
+							CreatedAt:    time.Now(),
+							UpdatedAt:    time.Now(),
+
+							RequiresApproval: arg.RequiresApproval,
+							ApproverRoles:    arg.ApproverRoles,
+						}, nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "interactive job",
+			req: JobRequest{
+				Name:        "Maintenance Job",
+				Status:      JobStatusPending,
+				Interactive: true,
+			},
+			ownerID: "owner123",
+			setup: func() {
+				mockQuerier.EXPECT().
+					CreateJob(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, arg db.CreateJobParams) (db.Job, error) {
+						return db.Job{
+							ID:          arg.ID,
+							Name:        arg.Name,
+							Status:      arg.Status,
+							Interactive: arg.Interactive,
+							CreatedAt:   time.Now(),
+							UpdatedAt:   time.Now(),
+						}, nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "file triggered job",
+			req: JobRequest{
+				Name:   "Ingest Job",
+				Status: JobStatusPending,
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"files": map[string]interface{}{"type": "array"}},
+				},
+				FileTrigger: &watch.Trigger{
+					Path:     "/srv/drop",
+					Glob:     "*.csv",
+					Debounce: limits.Duration(10 * time.Second),
+				},
+			},
+			ownerID: "owner123",
+			setup: func() {
+				mockQuerier.EXPECT().
+					CreateJob(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, arg db.CreateJobParams) (db.Job, error) {
+						return db.Job{
+							ID:          arg.ID,
+							Name:        arg.Name,
+							Status:      arg.Status,
+							InputSchema: arg.InputSchema,
+							FileTrigger: arg.FileTrigger,
+							CreatedAt:   time.Now(),
+							UpdatedAt:   time.Now(),
+						}, nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "scheduled job",
+			req: JobRequest{
+				Name:   "Nightly Job",
+				Status: JobStatusPending,
+				Schedule: &schedule.Schedule{
+					Cron:    "0 2 * * *",
+					Overlap: schedule.OverlapQueue,
+					CatchUp: schedule.CatchUpLatest,
+				},
+			},
+			ownerID: "owner123",
+			setup: func() {
+				mockQuerier.EXPECT().
+					CreateJob(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, arg db.CreateJobParams) (db.Job, error) {
+						return db.Job{
+							ID:        arg.ID,
+							Name:      arg.Name,
+							Status:    arg.Status,
+							Schedule:  arg.Schedule,
+							CreatedAt: time.Now(),
+							UpdatedAt: time.Now(),
+						}, nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "job with test reports",
+			req: JobRequest{
+				Name:        "Test Job",
+				Status:      JobStatusPending,
+				TestReports: []string{"reports/*.xml"},
+			},
+			ownerID: "owner123",
+			setup: func() {
+				mockQuerier.EXPECT().
+					CreateJob(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, arg db.CreateJobParams) (db.Job, error) {
+						return db.Job{
+							ID:          arg.ID,
+							Name:        arg.Name,
+							Status:      arg.Status,
+							TestReports: arg.TestReports,
+							CreatedAt:   time.Now(),
+							UpdatedAt:   time.Now(),
+						}, nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative limits",
+			req: JobRequest{
+				Name:   "Test Job",
+				Status: JobStatusPending,
+				Limits: &limits.Limits{OpenFiles: -1},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "blank agent label",
+			req: JobRequest{
+				Name:        "Test Job",
+				Status:      JobStatusPending,
+				AgentLabels: []string{"linux", " "},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "negative max retries",
+			req: JobRequest{
+				Name:       "Test Job",
+				Status:     JobStatusPending,
+				MaxRetries: -1,
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "invalid input schema",
+			req: JobRequest{
+				Name:        "Test Job",
+				Status:      JobStatusPending,
+				InputSchema: map[string]interface{}{"type": "object", "$ref": "#/definitions/inputs"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "invalid matrix",
+			req: JobRequest{
+				Name:   "Test Job",
+				Status: JobStatusPending,
+				Matrix: &matrix.Matrix{Axes: map[string][]interface{}{"os": {}}},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "invalid source",
+			req: JobRequest{
+				Name:   "Test Job",
+				Status: JobStatusPending,
+				Source: &source.Source{Repository: "etl.git"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "invalid caches",
+			req: JobRequest{
+				Name:   "Test Job",
+				Status: JobStatusPending,
+				Caches: []cache.Entry{{Path: "/var/cache", Key: "deps"}},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "interactive job on agents",
+			req: JobRequest{
+				Name:        "Test Job",
+				Status:      JobStatusPending,
+				Interactive: true,
+				AgentLabels: []string{"linux"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "invalid file trigger",
+			req: JobRequest{
+				Name:   "Test Job",
+				Status: JobStatusPending,
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"files": map[string]interface{}{"type": "array"}},
+				},
+				FileTrigger: &watch.Trigger{Path: "drop"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "file trigger without files input",
+			req: JobRequest{
+				Name:        "Test Job",
+				Status:      JobStatusPending,
+				FileTrigger: &watch.Trigger{Path: "/srv/drop"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "invalid schedule",
+			req: JobRequest{
+				Name:     "Test Job",
+				Status:   JobStatusPending,
+				Schedule: &schedule.Schedule{Cron: "0 2 * *"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "schedule with required inputs",
+			req: JobRequest{
+				Name:   "Test Job",
+				Status: JobStatusPending,
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"region": map[string]interface{}{"type": "string"}},
+					"required":   []interface{}{"region"},
+				},
+				Schedule: &schedule.Schedule{Cron: "@daily"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "test reports outside the working directory",
+			req: JobRequest{
+				Name:        "Test Job",
+				Status:      JobStatusPending,
+				TestReports: []string{"../reports/*.xml"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "approval without approver roles",
+			req: JobRequest{
+				Name:             "Test Job",
+				Status:           JobStatusPending,
+				RequiresApproval: true,
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
+		{
+			name: "config without plugin",
+			req: JobRequest{
+				Name:   "Test Job",
+				Status: JobStatusPending,
+				Config: map[string]interface{}{"body": "echo hi"},
+			},
+			ownerID: "owner123",
+			setup:   func() {}, // No mock expectations as validation should fail
+			wantErr: true,
+		},
 		{
 			name: "empty name",
 			req: JobRequest{
@@ -313,17 +668,110 @@ func TestJobService_CreateJob(t *testing.T) {
 				if resp.Status != tt.req.Status {
 					t.Errorf("CreateJob() status = %v, want %v", resp.Status, tt.req.Status)
 				}
+				if resp.Plugin != tt.req.Plugin {
+					t.Errorf("CreateJob() plugin = %v, want %v", resp.Plugin, tt.req.Plugin)
+				}
+				if !reflect.DeepEqual(resp.Config, tt.req.Config) {
+					t.Errorf("CreateJob() config = %v, want %v", resp.Config, tt.req.Config)
+				}
+				if resp.Sandbox != tt.req.Sandbox {
+					t.Errorf("CreateJob() sandbox = %v, want %v", resp.Sandbox, tt.req.Sandbox)
+				}
+				if !reflect.DeepEqual(resp.Limits, tt.req.Limits) {
+					t.Errorf("CreateJob() limits = %v, want %v", resp.Limits, tt.req.Limits)
+				}
+				if !reflect.DeepEqual(resp.AgentLabels, tt.req.AgentLabels) {
+					t.Errorf("CreateJob() agent labels = %v, want %v", resp.AgentLabels, tt.req.AgentLabels)
+				}
+				if resp.MaxRetries != tt.req.MaxRetries {
+					t.Errorf("CreateJob() max retries = %v, want %v", resp.MaxRetries, tt.req.MaxRetries)
+				}
+				if !reflect.DeepEqual(resp.Matrix, tt.req.Matrix) {
+					t.Errorf("CreateJob() matrix = %v, want %v", resp.Matrix, tt.req.Matrix)
+				}
+				if !reflect.DeepEqual(resp.Source, tt.req.Source) {
+					t.Errorf("CreateJob() source = %v, want %v", resp.Source, tt.req.Source)
+				}
+				if !reflect.DeepEqual(resp.Caches, tt.req.Caches) {
+					t.Errorf("CreateJob() caches = %v, want %v", resp.Caches, tt.req.Caches)
+				}
+				if resp.Interactive != tt.req.Interactive {
+					t.Errorf("CreateJob() interactive = %v, want %v", resp.Interactive, tt.req.Interactive)
+				}
+				if !reflect.DeepEqual(resp.FileTrigger, tt.req.FileTrigger) {
+					t.Errorf("CreateJob() file trigger = %v, want %v", resp.FileTrigger, tt.req.FileTrigger)
+				}
+				if !reflect.DeepEqual(resp.Schedule, tt.req.Schedule) {
+					t.Errorf("CreateJob() schedule = %v, want %v", resp.Schedule, tt.req.Schedule)
+				}
+				if !reflect.DeepEqual(resp.TestReports, tt.req.TestReports) {
+					t.Errorf("CreateJob() test reports = %v, want %v", resp.TestReports, tt.req.TestReports)
+				}
+				if !reflect.DeepEqual(resp.InputSchema, tt.req.InputSchema) {
+					t.Errorf("CreateJob() input schema = %v, want %v", resp.InputSchema, tt.req.InputSchema)
+				}
+				if resp.RequiresApproval != tt.req.RequiresApproval {
+					t.Errorf("CreateJob() requires approval = %v, want %v", resp.RequiresApproval, tt.req.RequiresApproval)
+				}
+				if !reflect.DeepEqual(resp.ApproverRoles, tt.req.ApproverRoles) {
+					t.Errorf("CreateJob() approver roles = %v, want %v", resp.ApproverRoles, tt.req.ApproverRoles)
+				}
 			}
 		})
 	}
 }
 
+func TestJobService_CreateTemplatedJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuerier := NewMockJobQuerier(ctrl)
+	svc := NewService(mockQuerier, nil)
+	ctx := context.Background()
+
+	origin := TemplateOrigin{
+		ID:      "tmpl-1",
+		Version: 3,
+		Params:  map[string]interface{}{"env": "prod"},
+	}
+	mockQuerier.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.CreateJobParams) (db.Job, error) {
+			if arg.TemplateID.String != "tmpl-1" || arg.TemplateVersion != (sql.NullInt64{Int64: 3, Valid: true}) {
+				t.Errorf("CreateJob() template = %v version %v", arg.TemplateID, arg.TemplateVersion)
+			}
+			if arg.TemplateParams.String != `{"env":"prod"}` {
+				t.Errorf("CreateJob() template params = %v", arg.TemplateParams.String)
+			}
+			return db.Job{
+				ID:              arg.ID,
+				Name:            arg.Name,
+				Status:          arg.Status,
+				TemplateID:      arg.TemplateID,
+				TemplateVersion: arg.TemplateVersion,
+				TemplateParams:  arg.TemplateParams,
+			}, nil
+		})
+
+	resp, err := svc.CreateTemplatedJob(ctx, JobRequest{Name: "export-prod", Status: JobStatusPending}, "owner123", origin)
+	if err != nil {
+		t.Fatalf("CreateTemplatedJob() error = %v", err)
+	}
+	if !reflect.DeepEqual(resp.Template, &origin) {
+		t.Errorf("CreateTemplatedJob() template = %v, want %v", resp.Template, origin)
+	}
+
+	if _, err := svc.CreateTemplatedJob(ctx, JobRequest{Status: JobStatusPending}, "owner123", origin); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("CreateTemplatedJob() error = %v, want %v", err, ErrInvalidJob)
+	}
+}
+
 func TestJobService_GetJob(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockQuerier := NewMockJobQuerier(ctrl)
-	svc := NewService(mockQuerier)
+	svc := NewService(mockQuerier, nil)
 	ctx := context.Background()
 
 	testJob := db.Job{
@@ -390,7 +838,7 @@ func TestJobService_UpdateJob(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockQuerier := NewMockJobQuerier(ctrl)
-	svc := NewService(mockQuerier)
+	svc := NewService(mockQuerier, nil)
 	ctx := context.Background()
 
 	testJob := db.Job{
@@ -480,12 +928,86 @@ func TestJobService_UpdateJob(t *testing.T) {
 	}
 }
 
+func TestJobService_UpdateJobFiresTriggers(t *testing.T) {
+	ctx := context.Background()
+	update := func(t *testing.T, from, to JobStatus, fires bool) {
+		ctrl := gomock.NewController(t)
+		mockQuerier := NewMockJobQuerier(ctrl)
+		mockTriggers := NewMockTriggerFirer(ctrl)
+		svc := NewService(mockQuerier, mockTriggers)
+
+		mockQuerier.EXPECT().GetJob(gomock.Any(), "test-job-id").
+			Return(db.Job{ID: "test-job-id", Status: string(from)}, nil)
+		mockQuerier.EXPECT().
+			UpdateJob(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.UpdateJobParams) (db.Job, error) {
+				if arg.PreviousStatus.String != string(from) {
+					t.Errorf("UpdateJob() previous status = %q, want %q", arg.PreviousStatus.String, from)
+				}
+				return db.Job{ID: arg.ID, Name: arg.Name, Status: arg.Status}, nil
+			})
+		if fires {
+			mockTriggers.EXPECT().FireTriggers(gomock.Any(), "test-job-id", string(to))
+		}
+
+		if _, err := svc.UpdateJob(ctx, "test-job-id", JobRequest{Name: "Test Job", Status: to}); err != nil {
+			t.Fatalf("UpdateJob() error = %v", err)
+		}
+	}
+
+	t.Run("completed", func(t *testing.T) { update(t, JobStatusActive, JobStatusComplete, true) })
+	t.Run("failed", func(t *testing.T) { update(t, JobStatusPending, JobStatusFailed, true) })
+	t.Run("status unchanged", func(t *testing.T) { update(t, JobStatusComplete, JobStatusComplete, false) })
+	t.Run("activated", func(t *testing.T) { update(t, JobStatusPending, JobStatusActive, false) })
+
+	t.Run("concurrently completed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockQuerier := NewMockJobQuerier(ctrl)
+		// No FireTriggers expected: the concurrent update fired them
+		svc := NewService(mockQuerier, NewMockTriggerFirer(ctrl))
+
+		// The status read first was changed before the update applied, so
+		// the update finds no row and is retried with the new status
+		gomock.InOrder(
+			mockQuerier.EXPECT().GetJob(gomock.Any(), "test-job-id").
+				Return(db.Job{ID: "test-job-id", Status: string(JobStatusActive)}, nil),
+			mockQuerier.EXPECT().UpdateJob(gomock.Any(), gomock.Any()).Return(db.Job{}, sql.ErrNoRows),
+			mockQuerier.EXPECT().GetJob(gomock.Any(), "test-job-id").
+				Return(db.Job{ID: "test-job-id", Status: string(JobStatusComplete)}, nil),
+			mockQuerier.EXPECT().
+				UpdateJob(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, arg db.UpdateJobParams) (db.Job, error) {
+					if arg.PreviousStatus.String != string(JobStatusComplete) {
+						t.Errorf("UpdateJob() previous status = %q, want %q", arg.PreviousStatus.String, JobStatusComplete)
+					}
+					return db.Job{ID: arg.ID, Name: arg.Name, Status: arg.Status}, nil
+				}),
+		)
+
+		if _, err := svc.UpdateJob(ctx, "test-job-id", JobRequest{Name: "Test Job", Status: JobStatusComplete}); err != nil {
+			t.Fatalf("UpdateJob() error = %v", err)
+		}
+	})
+
+	t.Run("non-existent job", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockQuerier := NewMockJobQuerier(ctrl)
+		svc := NewService(mockQuerier, NewMockTriggerFirer(ctrl))
+		mockQuerier.EXPECT().GetJob(gomock.Any(), "non-existent-id").Return(db.Job{}, db.ErrNotFound)
+
+		_, err := svc.UpdateJob(ctx, "non-existent-id", JobRequest{Name: "Test Job", Status: JobStatusComplete})
+		if !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("UpdateJob() error = %v, want %v", err, ErrJobNotFound)
+		}
+	})
+}
+
 func TestJobService_DeleteJob(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockQuerier := NewMockJobQuerier(ctrl)
-	svc := NewService(mockQuerier)
+	svc := NewService(mockQuerier, nil)
 	ctx := context.Background()
 
 	tests := []struct {
@@ -532,7 +1054,7 @@ func TestJobService_ListJobs(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockQuerier := NewMockJobQuerier(ctrl)
-	svc := NewService(mockQuerier)
+	svc := NewService(mockQuerier, nil)
 	ctx := context.Background()
 
 	testJobs := []db.Job{
@@ -600,6 +1122,73 @@ func TestJobService_ListJobs(t *testing.T) {
 			want:    0,
 			wantErr: true,
 		},
+		{
+			name: "search",
+			params: JobListParams{
+				Page:     2,
+				PageSize: 10,
+				Query:    "connection refused",
+			},
+			setup: func() {
+				mockQuerier.EXPECT().
+					SearchJobs(gomock.Any(), db.SearchJobsParams{
+						Query:  `"connection" "refused"`,
+						Limit:  10,
+						Offset: 10,
+					}).
+					Return([]db.SearchJobsRow{
+						{Job: testJobs[0], RunNumber: sql.NullInt64{Int64: 3, Valid: true}, Score: -2, Snippet: "<mark>connection</mark> <mark>refused</mark>"},
+						{Job: testJobs[1], Score: -1, Snippet: "<mark>connection</mark> pool"},
+					}, nil)
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "search filtered by status",
+			params: JobListParams{
+				Page:     1,
+				PageSize: 10,
+				Status:   JobStatusActive,
+				Query:    "connection",
+			},
+			setup: func() {
+				mockQuerier.EXPECT().
+					SearchJobs(gomock.Any(), gomock.Any()).
+					Return([]db.SearchJobsRow{
+						{Job: testJobs[0], Score: -2},
+						{Job: testJobs[1], Score: -1},
+					}, nil)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "blank query lists jobs",
+			params: JobListParams{
+				Page:     1,
+				PageSize: 10,
+				Query:    "  ",
+			},
+			setup: func() {
+				mockQuerier.EXPECT().
+					ListJobs(gomock.Any(), gomock.Any()).
+					Return(testJobs, nil)
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "query too long",
+			params: JobListParams{
+				Page:     1,
+				PageSize: 10,
+				Query:    strings.Repeat("a", MaxQueryLength+1),
+			},
+			setup:   func() {},
+			want:    0,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -622,3 +1211,69 @@ func TestJobService_ListJobs(t *testing.T) {
 		})
 	}
 }
+
+func TestJobService_ListJobs_SearchMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuerier := NewMockJobQuerier(ctrl)
+	svc := NewService(mockQuerier, nil)
+
+	mockQuerier.EXPECT().
+		SearchJobs(gomock.Any(), gomock.Any()).
+		Return([]db.SearchJobsRow{
+			{
+				Job:       db.Job{ID: "job-1", Name: "deploy", Status: string(JobStatusActive)},
+				RunNumber: sql.NullInt64{Int64: 7, Valid: true},
+				Score:     -1.5,
+				Snippet:   "dial tcp: <mark>connection</mark> refused",
+			},
+			{
+				Job:     db.Job{ID: "job-2", Name: "connection check", Status: string(JobStatusActive)},
+				Score:   -0.5,
+				Snippet: "<mark>connection</mark> check",
+			},
+		}, nil)
+
+	resp, err := svc.ListJobs(context.Background(), JobListParams{Page: 1, PageSize: 10, Query: "connection"})
+	if err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+	if len(resp.Jobs) != 2 {
+		t.Fatalf("ListJobs() returned %d jobs, want 2", len(resp.Jobs))
+	}
+
+	run := int64(7)
+	want := []*SearchMatch{
+		{RunNumber: &run, Snippet: "dial tcp: <mark>connection</mark> refused", Score: 1.5},
+		{Snippet: "<mark>connection</mark> check", Score: 0.5},
+	}
+	for i, job := range resp.Jobs {
+		if !reflect.DeepEqual(job.Match, want[i]) {
+			t.Errorf("ListJobs() match %d = %+v, want %+v", i, job.Match, want[i])
+		}
+	}
+}
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{q: "", want: ""},
+		{q: "   ", want: ""},
+		{q: "refused", want: `"refused"`},
+		{q: " connection  refused ", want: `"connection" "refused"`},
+		{q: "10.0.0.1:5432", want: `"10.0.0.1:5432"`},
+		{q: `say "hi" OR NOT`, want: `"say" """hi""" "OR" "NOT"`},
+		{q: "post*", want: `"post"*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			if got := matchQuery(tt.q); got != tt.want {
+				t.Errorf("matchQuery(%q) = %q, want %q", tt.q, got, tt.want)
+			}
+		})
+	}
+}
@@ -85,6 +85,7 @@ func (h *Handler) swaggerDeleteJob() {}
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Items per page (default: 10)"
 // @Param status query string false "Filter by status (pending, active, complete, failed)"
+// @Param q query string false "Search names, descriptions and run output; results are ranked and carry a highlighted snippet"
 // @Success 200 {object} JobListResponse
 // @Failure 400 {string} string "Invalid parameters"
 // @Failure 500 {string} string "Internal server error"
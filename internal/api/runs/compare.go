@@ -0,0 +1,130 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// maxDiffLines bounds the lines of output diffed per stream; longer
+// output is diffed by its last lines, where failures usually show
+const maxDiffLines = 5000
+
+// CompareRuns compares two runs of a job
+func (s *runService) CompareRuns(ctx context.Context, jobID string, a, b int64) (*RunComparison, error) {
+	runA, err := s.getRun(ctx, jobID, a)
+	if err != nil {
+		return nil, err
+	}
+	runB, err := s.getRun(ctx, jobID, b)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &RunComparison{A: toComparedRun(runA), B: toComparedRun(runB)}
+	c.Inputs = changes(decodeInputs(runA), decodeInputs(runB))
+	c.Env = changes(c.A.Env, c.B.Env)
+	c.CommandChanged = c.A.Command != nil && c.B.Command != nil && !slices.Equal(c.A.Command, c.B.Command)
+	c.ExitCodeChanged = !reflect.DeepEqual(c.A.ExitCode, c.B.ExitCode)
+	if c.A.Duration != nil && c.B.Duration != nil {
+		delta := *c.B.Duration - *c.A.Duration
+		c.DurationDelta = &delta
+	}
+	c.StdoutDiff = unifiedDiff(runA.Stdout.String, runB.Stdout.String, a, b, "stdout")
+	c.StderrDiff = unifiedDiff(runA.Stderr.String, runB.Stderr.String, a, b, "stderr")
+	return c, nil
+}
+
+// toComparedRun converts a db.JobRun to a ComparedRun
+func toComparedRun(run db.JobRun) ComparedRun {
+	c := ComparedRun{
+		ID:         run.ID,
+		RunNumber:  run.RunNumber,
+		Status:     run.Status,
+		SourceSHA:  run.SourceSha.String,
+		StartedAt:  db.NullTimeToTimePtr(run.StartedAt),
+		FinishedAt: db.NullTimeToTimePtr(run.FinishedAt),
+	}
+	if run.Inputs.Valid {
+		c.Inputs = json.RawMessage(run.Inputs.String)
+	}
+	if plan := executor.DecodePlan(run.Plan); plan != nil {
+		c.Command, c.Dir, c.Env = plan.Command, plan.Dir, plan.Env
+	}
+	if run.ExitCode.Valid {
+		c.ExitCode = &run.ExitCode.Int64
+	}
+	if run.StartedAt.Valid && run.FinishedAt.Valid {
+		d := run.FinishedAt.Time.Sub(run.StartedAt.Time).Seconds()
+		c.Duration = &d
+	}
+	return c
+}
+
+// decodeInputs returns the inputs of a run by name
+func decodeInputs(run db.JobRun) map[string]interface{} {
+	var values map[string]interface{}
+	if run.Inputs.Valid {
+		_ = json.Unmarshal([]byte(run.Inputs.String), &values)
+	}
+	return values
+}
+
+// changes lists the names whose values differ between a and b, sorted
+func changes[V any](a, b map[string]V) []Change {
+	var diff []Change
+	for name, va := range a {
+		if vb, ok := b[name]; !ok {
+			diff = append(diff, Change{Name: name, A: va})
+		} else if !reflect.DeepEqual(va, vb) {
+			diff = append(diff, Change{Name: name, A: va, B: vb})
+		}
+	}
+	for name, vb := range b {
+		if _, ok := a[name]; !ok {
+			diff = append(diff, Change{Name: name, B: vb})
+		}
+	}
+	slices.SortFunc(diff, func(x, y Change) int { return strings.Compare(x.Name, y.Name) })
+	return diff
+}
+
+// unifiedDiff diffs the output of two runs, labelling the sides with
+// their run numbers
+func unifiedDiff(a, b string, numA, numB int64, stream string) string {
+	if a == b {
+		return ""
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lastLines(a),
+		B:        lastLines(b),
+		FromFile: fmt.Sprintf("run %d %s", numA, stream),
+		ToFile:   fmt.Sprintf("run %d %s", numB, stream),
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+// lastLines splits output into at most maxDiffLines lines, each ending in
+// a newline
+func lastLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+	lines[len(lines)-1] += "\n"
+	if len(lines) > maxDiffLines {
+		return lines[len(lines)-maxDiffLines:]
+	}
+	return lines
+}
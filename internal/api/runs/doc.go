@@ -0,0 +1,142 @@
+/*
+Package runs exposes job executions and their workspaces over HTTP.
+
+Runs are started through the executor and recorded with a sequential run
+number per job:
+
+	Pending -> Running -> Complete/Failed/DeadLetter
+
+Failed runs of jobs with max_retries go back to Pending; a run that fails
+after being retried ends as a dead letter (see package deadletters). Runs
+of jobs with requires_approval start as AwaitingApproval and are only
+queued once approved (see package approvals).
+
+Jobs with a matrix start a parent run whose children, one per matrix
+combination, are numbered after it. The parent is never executed itself:
+its status aggregates the children's and GET /jobs/{id}/runs/{number} lists
+them. Children cancelled by a fail-fast matrix end as Cancelled.
+
+Jobs with a schedule are started at its fire times (see package schedule);
+their runs carry the fire time as scheduled_for, and caught_up when they
+were started for a fire time missed while the server was down. Fire times
+that did not start a run are recorded as Skipped runs whose failure_reason
+says why (overlap, missed or blackout); missed fire times too old to be
+handled at once share one Skipped run counting them as dropped_fire_times.
+Runs replaced under the replace overlap policy end as Cancelled.
+
+API Endpoints:
+
+	POST   /jobs/{id}/runs                - Start a new run, optionally with inputs, stdin and files (202 Accepted)
+	POST   /jobs/{id}/dry-run             - Show how a run would be executed, without starting it
+	GET    /jobs/{id}/runs                - List runs, newest first
+	GET    /jobs/{id}/runs/{number}       - Get run details
+	GET    /jobs/{id}/runs/compare        - Compare run ?b= with run ?a=
+	POST   /jobs/{id}/runs/{number}/rerun - Start a new run replaying a run (202 Accepted)
+	GET    /jobs/{id}/workspace/...       - Browse a retained run workspace
+
+Run Inputs:
+
+Jobs that declare an input schema are run with inputs, which are validated
+against the schema and stored with the run (see package inputs):
+
+	POST /jobs/{id}/runs
+	{"inputs": {"region": "eu-west-1", "shards": 8}}
+
+Standard Input and Input Files:
+
+A run may be given standard input, either inline or as an attachment
+uploaded to the job (see package attachments), and attachments to place
+into its working directory. Paths default to the attachments' filenames:
+
+	POST /jobs/{id}/runs
+	{
+		"stdin": {"content": "yes\n"},
+		"files": [{"attachment": "...", "path": "data/input.csv"}]
+	}
+
+The run records what it received, including the size and SHA-256 checksum
+of every attachment, under "stdin" and "files".
+
+Dry Runs:
+
+A dry run takes the body of POST /jobs/{id}/runs and validates everything
+starting the run would, without creating or executing it. It returns the
+plugin's plan, i.e. the command line, environment and working directory of
+its process or the request it would send, with secrets masked, and the
+workspace the run would get. Problems are listed by field instead of
+failing the request:
+
+	POST /jobs/{id}/dry-run
+	{"inputs": {"region": "eu-west-1"}}
+
+	Response:
+	{
+		"valid": true,
+		"plugin": "script",
+		"plan": {
+			"command": ["/usr/bin/bash", "-e", "-u", "-o", "pipefail", "<script file>"],
+			"dir": ".../work",
+			"env": {"HOME": ".../home", "INPUT_REGION": "eu-west-1", ...},
+			"details": {"interpreter": "bash", "body": "..."}
+		},
+		"workspace": {"path": ".../{job}/dry-run", "work_dir": ".../work"}
+	}
+
+	{"valid": false, "errors": [{"field": "inputs.region", "message": "must be of type string"}], ...}
+
+Comparing Runs:
+
+Every run records the plan of its plugin as it starts, like a dry run
+shows it, with secrets masked. Comparing two runs lists the inputs and
+environment variables that differ, whether the command line and exit code
+changed, how much longer run b took, and unified diffs of the two runs'
+stdout and stderr. Output longer than 5000 lines is diffed by its last
+5000 lines. Runs that never started a plugin have no command or
+environment to compare.
+
+	GET /jobs/{id}/runs/compare?a=41&b=42
+
+	Response:
+	{
+		"a": {"run_number": 41, "status": "complete", "exit_code": 0, "duration": 12.5, ...},
+		"b": {"run_number": 42, "status": "failed", "exit_code": 1, "duration": 3.1, ...},
+		"inputs": [{"name": "region", "a": "eu-west-1", "b": "us-east-1"}],
+		"env": [{"name": "INPUT_REGION", "a": "eu-west-1", "b": "us-east-1"}],
+		"command_changed": false,
+		"exit_code_changed": true,
+		"duration_delta": -9.4,
+		"stdout_diff": "--- run 41 stdout\n+++ run 42 stdout\n@@ -1,2 +1,2 @@\n..."
+	}
+
+Rerunning a run starts a new run with the same inputs, standard input and
+input files, under the job's current configuration. Rerunning a child of a
+matrix run starts the whole matrix again.
+
+Workspace Browsing:
+
+The latest run that still has a retained workspace is served by default;
+?run=N selects a specific run. Directories are returned as a JSON listing,
+files as their raw contents:
+
+	GET /jobs/{id}/workspace/work?run=3
+
+	Response:
+	{
+		"run_number": 3,
+		"path": "/work",
+		"entries": [
+			{"name": "sql-results", "path": "/work/sql-results", "is_dir": true, ...}
+		]
+	}
+
+Error Handling:
+
+  - 200: Dry run resolved, whether or not the run is valid
+  - 202: Run started
+  - 400: Invalid ID, run number or body, the job has no valid plugin configuration,
+    the inputs do not match the job's input schema, or the standard input
+    or input files are invalid
+  - 404: Job, run, workspace or file not found
+  - 503: The executor is shutting down
+*/
+package runs
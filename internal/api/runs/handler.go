@@ -0,0 +1,301 @@
+package runs
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for job runs and their workspaces
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new run handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the run routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/jobs/{id}/runs", h.StartRun)
+	r.Post("/jobs/{id}/dry-run", h.DryRun)
+	r.Get("/jobs/{id}/runs", h.ListRuns)
+	r.Get("/jobs/{id}/runs/compare", h.CompareRuns)
+	r.Get("/jobs/{id}/runs/{number}", h.GetRun)
+	r.Post("/jobs/{id}/runs/{number}/rerun", h.Rerun)
+	r.Get("/jobs/{id}/workspace", h.BrowseWorkspace)
+	r.Get("/jobs/{id}/workspace/*", h.BrowseWorkspace)
+}
+
+// jobID extracts and validates the job ID path parameter
+func jobID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		http.Error(w, "Missing job ID", http.StatusBadRequest)
+		return "", false
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// parseRunNumber parses a positive run number
+func parseRunNumber(s string) (int64, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil && n > 0
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrRunNotFound), errors.Is(err, ErrWorkspaceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidRun):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// StartRun handles requests to run a job. The body is optional and may
+// carry the run's inputs.
+func (h *Handler) StartRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	var req RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.StartRun(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// DryRun handles requests to resolve a run without starting it. The body
+// is that of StartRun.
+func (h *Handler) DryRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	var req RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.DryRun(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetRun handles run retrieval requests
+func (h *Handler) GetRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	number, ok := parseRunNumber(chi.URLParam(r, "number"))
+	if !ok {
+		http.Error(w, "Invalid run number", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.GetRun(r.Context(), id, number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// CompareRuns handles requests to compare run ?b= of a job with run ?a=
+func (h *Handler) CompareRuns(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	a, okA := parseRunNumber(r.URL.Query().Get("a"))
+	b, okB := parseRunNumber(r.URL.Query().Get("b"))
+	if !okA || !okB {
+		http.Error(w, "Run numbers a and b are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.CompareRuns(r.Context(), id, a, b)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Rerun handles requests to replay a run
+func (h *Handler) Rerun(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	number, ok := parseRunNumber(chi.URLParam(r, "number"))
+	if !ok {
+		http.Error(w, "Invalid run number", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.Rerun(r.Context(), id, number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// ListRuns handles run listing requests
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+
+	params := RunListParams{
+		Page:     1,
+		PageSize: 10,
+	}
+	if page := r.URL.Query().Get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil {
+			http.Error(w, "Invalid page parameter", http.StatusBadRequest)
+			return
+		}
+		params.Page = p
+	}
+	if pageSize := r.URL.Query().Get("page_size"); pageSize != "" {
+		ps, err := strconv.Atoi(pageSize)
+		if err != nil {
+			http.Error(w, "Invalid page_size parameter", http.StatusBadRequest)
+			return
+		}
+		params.PageSize = ps
+	}
+	if err := params.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.ListRuns(r.Context(), id, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// BrowseWorkspace serves files from a retained run workspace. Directories
+// are returned as a JSON listing. The latest retained workspace is used
+// unless a run number is given with ?run=.
+func (h *Handler) BrowseWorkspace(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	var number int64
+	if run := r.URL.Query().Get("run"); run != "" {
+		if number, ok = parseRunNumber(run); !ok {
+			http.Error(w, "Invalid run parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ws, number, err := h.service.GetWorkspace(r.Context(), id, number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	name := chi.URLParam(r, "*")
+	f, err := ws.Open(name)
+	if err != nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !info.IsDir() {
+		http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+		return
+	}
+
+	entries, err := f.ReadDir(-1)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	dir := path.Clean("/" + name)
+	writeJSON(w, http.StatusOK, WorkspaceListing{
+		RunNumber: number,
+		Path:      dir,
+		Entries:   toWorkspaceEntries(dir, entries),
+	})
+}
+
+// toWorkspaceEntries converts directory entries, listing directories first
+func toWorkspaceEntries(dir string, entries []fs.DirEntry) []WorkspaceEntry {
+	result := make([]WorkspaceEntry, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			// Removed while listing
+			continue
+		}
+		result = append(result, WorkspaceEntry{
+			Name:    e.Name(),
+			Path:    path.Join(dir, e.Name()),
+			IsDir:   e.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].IsDir != result[j].IsDir {
+			return result[i].IsDir
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,266 @@
+package runs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestStartRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		jobID      string
+		body       string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name:  "accepted",
+			jobID: testJobID,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().StartRun(gomock.Any(), testJobID, RunRequest{}).Return(&RunResponse{RunNumber: 1}, nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:  "with inputs",
+			jobID: testJobID,
+			body:  `{"inputs": {"region": "eu-west-1"}}`,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().
+					StartRun(gomock.Any(), testJobID, RunRequest{Inputs: json.RawMessage(`{"region": "eu-west-1"}`)}).
+					Return(&RunResponse{RunNumber: 1}, nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "invalid body",
+			jobID:      testJobID,
+			body:       `{"inputs":`,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid job id",
+			jobID:      "not-a-uuid",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "job cannot run",
+			jobID: testJobID,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().StartRun(gomock.Any(), testJobID, gomock.Any()).Return(nil, ErrInvalidRun)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "job not found",
+			jobID: testJobID,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().StartRun(gomock.Any(), testJobID, gomock.Any()).Return(nil, ErrJobNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/jobs/"+tt.jobID+"/runs", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().
+		DryRun(gomock.Any(), testJobID, RunRequest{Inputs: json.RawMessage(`{"region": "eu-west-1"}`)}).
+		Return(&executor.DryRun{Valid: true, Plugin: "script"}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs/"+testJobID+"/dry-run", strings.NewReader(`{"inputs": {"region": "eu-west-1"}}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp executor.DryRun
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.True(t, resp.Valid)
+	assert.Equal(t, "script", resp.Plugin)
+
+	ms.EXPECT().DryRun(gomock.Any(), testJobID, RunRequest{}).Return(nil, ErrJobNotFound)
+	w = serve(router, http.MethodPost, "/jobs/"+testJobID+"/dry-run")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetRun(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().GetRun(gomock.Any(), testJobID, int64(2)).Return(&RunResponse{RunNumber: 2}, nil)
+
+	w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/runs/2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp RunResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, int64(2), resp.RunNumber)
+
+	w = serve(router, http.MethodGet, "/jobs/"+testJobID+"/runs/zero")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCompareRuns(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().CompareRuns(gomock.Any(), testJobID, int64(1), int64(2)).
+		Return(&RunComparison{A: ComparedRun{RunNumber: 1}, B: ComparedRun{RunNumber: 2}, ExitCodeChanged: true}, nil)
+
+	w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/runs/compare?a=1&b=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp RunComparison
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.True(t, resp.ExitCodeChanged)
+
+	w = serve(router, http.MethodGet, "/jobs/"+testJobID+"/runs/compare?a=1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRerun(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().Rerun(gomock.Any(), testJobID, int64(3)).Return(&RunResponse{RunNumber: 4}, nil)
+	ms.EXPECT().Rerun(gomock.Any(), testJobID, int64(9)).Return(nil, ErrRunNotFound)
+
+	w := serve(router, http.MethodPost, "/jobs/"+testJobID+"/runs/3/rerun")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var resp RunResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, int64(4), resp.RunNumber)
+
+	w = serve(router, http.MethodPost, "/jobs/"+testJobID+"/runs/9/rerun")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListRuns(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().
+		ListRuns(gomock.Any(), testJobID, RunListParams{Page: 2, PageSize: 5}).
+		Return(&RunListResponse{Runs: []RunResponse{}, Page: 2, PageSize: 5}, nil)
+
+	w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/runs?page=2&page_size=5")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(router, http.MethodGet, "/jobs/"+testJobID+"/runs?page_size=1000")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBrowseWorkspace(t *testing.T) {
+	manager, err := workspace.NewManager(workspace.Config{Root: t.TempDir()})
+	require.NoError(t, err)
+	ws, err := manager.Create(testJobID, "run-1")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(ws.Dir(), "reports"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir(), "out.txt"), []byte("hello"), 0o644))
+
+	tests := []struct {
+		name       string
+		target     string
+		number     int64
+		wantStatus int
+		check      func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "root listing",
+			target:     "/jobs/" + testJobID + "/workspace",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var listing WorkspaceListing
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&listing))
+				assert.Equal(t, "/", listing.Path)
+				assert.Len(t, listing.Entries, 3)
+			},
+		},
+		{
+			name:       "directory listing",
+			target:     "/jobs/" + testJobID + "/workspace/work?run=1",
+			number:     1,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var listing WorkspaceListing
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&listing))
+				require.Len(t, listing.Entries, 2)
+				assert.Equal(t, "reports", listing.Entries[0].Name)
+				assert.True(t, listing.Entries[0].IsDir)
+				assert.Equal(t, "/work/out.txt", listing.Entries[1].Path)
+				assert.Equal(t, int64(5), listing.Entries[1].Size)
+			},
+		},
+		{
+			name:       "file contents",
+			target:     "/jobs/" + testJobID + "/workspace/work/out.txt",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, "hello", w.Body.String())
+			},
+		},
+		{
+			name:       "missing file",
+			target:     "/jobs/" + testJobID + "/workspace/work/nope.txt",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "traversal stays inside the workspace",
+			target:     "/jobs/" + testJobID + "/workspace/work/%2e%2e/%2e%2e/%2e%2e/etc/passwd",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			ms.EXPECT().GetWorkspace(gomock.Any(), testJobID, tt.number).Return(ws, int64(1), nil)
+
+			w := serve(router, http.MethodGet, tt.target)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.check != nil {
+				tt.check(t, w)
+			}
+		})
+	}
+
+	t.Run("no retained workspace", func(t *testing.T) {
+		ms, router := setupHandler(t)
+		ms.EXPECT().GetWorkspace(gomock.Any(), testJobID, int64(0)).Return(nil, int64(0), ErrWorkspaceNotFound)
+		w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/workspace/")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid run", func(t *testing.T) {
+		_, router := setupHandler(t)
+		w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/workspace?run=-1")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
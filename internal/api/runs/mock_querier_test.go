@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/runs (interfaces: RunQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=runs github.com/klauern/gopher-tower/internal/api/runs RunQuerier
+//
+
+// Package runs is a generated GoMock package.
+package runs
+
+import (
+	context "context"
+	sql "database/sql"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRunQuerier is a mock of RunQuerier interface.
+type MockRunQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockRunQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockRunQuerierMockRecorder is the mock recorder for MockRunQuerier.
+type MockRunQuerierMockRecorder struct {
+	mock *MockRunQuerier
+}
+
+// NewMockRunQuerier creates a new mock instance.
+func NewMockRunQuerier(ctrl *gomock.Controller) *MockRunQuerier {
+	mock := &MockRunQuerier{ctrl: ctrl}
+	mock.recorder = &MockRunQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRunQuerier) EXPECT() *MockRunQuerierMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockRunQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockRunQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockRunQuerier)(nil).GetJob), ctx, id)
+}
+
+// GetJobRunByNumber mocks base method.
+func (m *MockRunQuerier) GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobRunByNumber", ctx, arg)
+	ret0, _ := ret[0].(db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobRunByNumber indicates an expected call of GetJobRunByNumber.
+func (mr *MockRunQuerierMockRecorder) GetJobRunByNumber(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobRunByNumber", reflect.TypeOf((*MockRunQuerier)(nil).GetJobRunByNumber), ctx, arg)
+}
+
+// ListChildRuns mocks base method.
+func (m *MockRunQuerier) ListChildRuns(ctx context.Context, parentRunID sql.NullString) ([]db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChildRuns", ctx, parentRunID)
+	ret0, _ := ret[0].([]db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChildRuns indicates an expected call of ListChildRuns.
+func (mr *MockRunQuerierMockRecorder) ListChildRuns(ctx, parentRunID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChildRuns", reflect.TypeOf((*MockRunQuerier)(nil).ListChildRuns), ctx, parentRunID)
+}
+
+// ListJobRunsByJob mocks base method.
+func (m *MockRunQuerier) ListJobRunsByJob(ctx context.Context, arg db.ListJobRunsByJobParams) ([]db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunsByJob", ctx, arg)
+	ret0, _ := ret[0].([]db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunsByJob indicates an expected call of ListJobRunsByJob.
+func (mr *MockRunQuerierMockRecorder) ListJobRunsByJob(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunsByJob", reflect.TypeOf((*MockRunQuerier)(nil).ListJobRunsByJob), ctx, arg)
+}
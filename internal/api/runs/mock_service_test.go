@@ -0,0 +1,149 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/runs (interfaces: Service)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_service_test.go -package=runs github.com/klauern/gopher-tower/internal/api/runs Service
+//
+
+// Package runs is a generated GoMock package.
+package runs
+
+import (
+	context "context"
+	reflect "reflect"
+
+	executor "github.com/klauern/gopher-tower/internal/executor"
+	workspace "github.com/klauern/gopher-tower/internal/workspace"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CompareRuns mocks base method.
+func (m *MockService) CompareRuns(ctx context.Context, jobID string, a, b int64) (*RunComparison, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareRuns", ctx, jobID, a, b)
+	ret0, _ := ret[0].(*RunComparison)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompareRuns indicates an expected call of CompareRuns.
+func (mr *MockServiceMockRecorder) CompareRuns(ctx, jobID, a, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareRuns", reflect.TypeOf((*MockService)(nil).CompareRuns), ctx, jobID, a, b)
+}
+
+// DryRun mocks base method.
+func (m *MockService) DryRun(ctx context.Context, jobID string, req RunRequest) (*executor.DryRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRun", ctx, jobID, req)
+	ret0, _ := ret[0].(*executor.DryRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRun indicates an expected call of DryRun.
+func (mr *MockServiceMockRecorder) DryRun(ctx, jobID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRun", reflect.TypeOf((*MockService)(nil).DryRun), ctx, jobID, req)
+}
+
+// GetRun mocks base method.
+func (m *MockService) GetRun(ctx context.Context, jobID string, number int64) (*RunResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRun", ctx, jobID, number)
+	ret0, _ := ret[0].(*RunResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRun indicates an expected call of GetRun.
+func (mr *MockServiceMockRecorder) GetRun(ctx, jobID, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRun", reflect.TypeOf((*MockService)(nil).GetRun), ctx, jobID, number)
+}
+
+// GetWorkspace mocks base method.
+func (m *MockService) GetWorkspace(ctx context.Context, jobID string, number int64) (*workspace.Workspace, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspace", ctx, jobID, number)
+	ret0, _ := ret[0].(*workspace.Workspace)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetWorkspace indicates an expected call of GetWorkspace.
+func (mr *MockServiceMockRecorder) GetWorkspace(ctx, jobID, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockService)(nil).GetWorkspace), ctx, jobID, number)
+}
+
+// ListRuns mocks base method.
+func (m *MockService) ListRuns(ctx context.Context, jobID string, params RunListParams) (*RunListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuns", ctx, jobID, params)
+	ret0, _ := ret[0].(*RunListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuns indicates an expected call of ListRuns.
+func (mr *MockServiceMockRecorder) ListRuns(ctx, jobID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuns", reflect.TypeOf((*MockService)(nil).ListRuns), ctx, jobID, params)
+}
+
+// Rerun mocks base method.
+func (m *MockService) Rerun(ctx context.Context, jobID string, number int64) (*RunResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rerun", ctx, jobID, number)
+	ret0, _ := ret[0].(*RunResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rerun indicates an expected call of Rerun.
+func (mr *MockServiceMockRecorder) Rerun(ctx, jobID, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rerun", reflect.TypeOf((*MockService)(nil).Rerun), ctx, jobID, number)
+}
+
+// StartRun mocks base method.
+func (m *MockService) StartRun(ctx context.Context, jobID string, req RunRequest) (*RunResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartRun", ctx, jobID, req)
+	ret0, _ := ret[0].(*RunResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartRun indicates an expected call of StartRun.
+func (mr *MockServiceMockRecorder) StartRun(ctx, jobID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartRun", reflect.TypeOf((*MockService)(nil).StartRun), ctx, jobID, req)
+}
@@ -0,0 +1,73 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/runs (interfaces: Starter)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_starter_test.go -package=runs github.com/klauern/gopher-tower/internal/api/runs Starter
+//
+
+// Package runs is a generated GoMock package.
+package runs
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	executor "github.com/klauern/gopher-tower/internal/executor"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStarter is a mock of Starter interface.
+type MockStarter struct {
+	ctrl     *gomock.Controller
+	recorder *MockStarterMockRecorder
+	isgomock struct{}
+}
+
+// MockStarterMockRecorder is the mock recorder for MockStarter.
+type MockStarterMockRecorder struct {
+	mock *MockStarter
+}
+
+// NewMockStarter creates a new mock instance.
+func NewMockStarter(ctrl *gomock.Controller) *MockStarter {
+	mock := &MockStarter{ctrl: ctrl}
+	mock.recorder = &MockStarterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStarter) EXPECT() *MockStarterMockRecorder {
+	return m.recorder
+}
+
+// DryRun mocks base method.
+func (m *MockStarter) DryRun(ctx context.Context, jobID string, opts executor.RunOptions) (executor.DryRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRun", ctx, jobID, opts)
+	ret0, _ := ret[0].(executor.DryRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRun indicates an expected call of DryRun.
+func (mr *MockStarterMockRecorder) DryRun(ctx, jobID, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRun", reflect.TypeOf((*MockStarter)(nil).DryRun), ctx, jobID, opts)
+}
+
+// StartRun mocks base method.
+func (m *MockStarter) StartRun(ctx context.Context, jobID string, opts executor.RunOptions) (db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartRun", ctx, jobID, opts)
+	ret0, _ := ret[0].(db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartRun indicates an expected call of StartRun.
+func (mr *MockStarterMockRecorder) StartRun(ctx, jobID, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartRun", reflect.TypeOf((*MockStarter)(nil).StartRun), ctx, jobID, opts)
+}
@@ -0,0 +1,157 @@
+package runs
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+)
+
+// RunRequest represents a request to run a job
+type RunRequest struct {
+	// Inputs is a JSON object validated against the job's input schema
+	Inputs json.RawMessage `json:"inputs,omitempty"`
+	// Stdin is streamed into the standard input of the run's processes
+	Stdin *attachments.Stdin `json:"stdin,omitempty"`
+	// Files are attachments placed into the run's working directory
+	Files []attachments.File `json:"files,omitempty"`
+}
+
+// RunResponse represents a job run in responses
+type RunResponse struct {
+	ID               string                 `json:"id"`
+	JobID            string                 `json:"job_id"`
+	RunNumber        int64                  `json:"run_number"`
+	Status           string                 `json:"status"`
+	Inputs           json.RawMessage        `json:"inputs,omitempty"`
+	Stdin            *attachments.Source    `json:"stdin,omitempty"`
+	Files            []attachments.Source   `json:"files,omitempty"`
+	ParentRunID      string                 `json:"parent_run_id,omitempty"`
+	ScheduledFor     *time.Time             `json:"scheduled_for,omitempty"`
+	CaughtUp         bool                   `json:"caught_up,omitempty"`
+	DroppedFireTimes int64                  `json:"dropped_fire_times,omitempty"`
+	TriggeredBy      string                 `json:"triggered_by_run_id,omitempty"`
+	Matrix           map[string]interface{} `json:"matrix,omitempty"`
+	Children         []ChildRun             `json:"children,omitempty"`
+	SourceSHA        string                 `json:"source_sha,omitempty"`
+	Caches           []cache.Result         `json:"caches,omitempty"`
+	ExitCode         *int64                 `json:"exit_code,omitempty"`
+	Stdout           string                 `json:"stdout,omitempty"`
+	Stderr           string                 `json:"stderr,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	Artifacts        []string               `json:"artifacts,omitempty"`
+	Sandbox          *sandbox.Profile       `json:"sandbox,omitempty"`
+	Limits           *limits.Limits         `json:"limits,omitempty"`
+	FailureReason    string                 `json:"failure_reason,omitempty"`
+	Attempt          int64                  `json:"attempt"`
+	LeaseOwner       string                 `json:"lease_owner,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+	StartedAt        *time.Time             `json:"started_at,omitempty"`
+	FinishedAt       *time.Time             `json:"finished_at,omitempty"`
+	// ApprovalExpiresAt is set while the run awaits approval
+	ApprovalExpiresAt *time.Time `json:"approval_expires_at,omitempty"`
+}
+
+// ChildRun summarizes a child run of a matrix run
+type ChildRun struct {
+	ID            string                 `json:"id"`
+	RunNumber     int64                  `json:"run_number"`
+	Status        string                 `json:"status"`
+	Matrix        map[string]interface{} `json:"matrix"`
+	ExitCode      *int64                 `json:"exit_code,omitempty"`
+	FailureReason string                 `json:"failure_reason,omitempty"`
+	StartedAt     *time.Time             `json:"started_at,omitempty"`
+	FinishedAt    *time.Time             `json:"finished_at,omitempty"`
+}
+
+// RunListParams represents parameters for listing runs
+type RunListParams struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// Validate checks if the list parameters are valid
+func (p *RunListParams) Validate() error {
+	if p.Page < 1 {
+		return errors.New("page must be greater than 0")
+	}
+	if p.PageSize < 1 || p.PageSize > 100 {
+		return errors.New("page size must be between 1 and 100")
+	}
+	return nil
+}
+
+// RunListResponse represents a paginated list of runs, newest first
+type RunListResponse struct {
+	Runs     []RunResponse `json:"runs"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// WorkspaceEntry describes a file or directory in a run workspace
+type WorkspaceEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	IsDir   bool      `json:"is_dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// WorkspaceListing is returned when a workspace path names a directory
+type WorkspaceListing struct {
+	RunNumber int64            `json:"run_number"`
+	Path      string           `json:"path"`
+	Entries   []WorkspaceEntry `json:"entries"`
+}
+
+// RunComparison shows how run B of a job differs from run A
+type RunComparison struct {
+	A ComparedRun `json:"a"`
+	B ComparedRun `json:"b"`
+	// Inputs and Env list the inputs and environment variables whose
+	// values differ, by name
+	Inputs []Change `json:"inputs,omitempty"`
+	Env    []Change `json:"env,omitempty"`
+	// CommandChanged is set when both runs recorded a command line and
+	// the command lines differ
+	CommandChanged  bool `json:"command_changed"`
+	ExitCodeChanged bool `json:"exit_code_changed"`
+	// DurationDelta is how many seconds longer B ran than A, when both
+	// finished
+	DurationDelta *float64 `json:"duration_delta,omitempty"`
+	// StdoutDiff and StderrDiff are unified diffs from A's output to B's;
+	// empty when the output is the same
+	StdoutDiff string `json:"stdout_diff,omitempty"`
+	StderrDiff string `json:"stderr_diff,omitempty"`
+}
+
+// ComparedRun describes one side of a RunComparison
+type ComparedRun struct {
+	ID        string          `json:"id"`
+	RunNumber int64           `json:"run_number"`
+	Status    string          `json:"status"`
+	Inputs    json.RawMessage `json:"inputs,omitempty"`
+	// Command, Dir and Env are what the run's plugin did, with secrets
+	// masked; they are missing for runs that did not get to run a plugin
+	Command   []string          `json:"command,omitempty"`
+	Dir       string            `json:"dir,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	SourceSHA string            `json:"source_sha,omitempty"`
+	ExitCode  *int64            `json:"exit_code,omitempty"`
+	// Duration is how many seconds the run took, once it finished
+	Duration   *float64   `json:"duration,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Change is a value that differs between two runs. A or B is missing when
+// only the other run has the value.
+type Change struct {
+	Name string      `json:"name"`
+	A    interface{} `json:"a,omitempty"`
+	B    interface{} `json:"b,omitempty"`
+}
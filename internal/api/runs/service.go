@@ -0,0 +1,291 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=runs github.com/klauern/gopher-tower/internal/api/runs RunQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=runs github.com/klauern/gopher-tower/internal/api/runs Service
+//go:generate go tool mockgen -destination=mock_starter_test.go -package=runs github.com/klauern/gopher-tower/internal/api/runs Starter
+
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/workspace"
+)
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrRunNotFound       = errors.New("run not found")
+	ErrWorkspaceNotFound = errors.New("no retained workspace")
+	ErrInvalidRun        = errors.New("job cannot be run")
+	ErrUnavailable       = errors.New("executor unavailable")
+)
+
+// latestWorkspaceScan bounds how many recent runs are checked when looking
+// for the latest retained workspace
+const latestWorkspaceScan = 50
+
+// RunQuerier defines the interface for run-related database operations
+type RunQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error)
+	ListJobRunsByJob(ctx context.Context, arg db.ListJobRunsByJobParams) ([]db.JobRun, error)
+	ListChildRuns(ctx context.Context, parentRunID sql.NullString) ([]db.JobRun, error)
+}
+
+// Starter starts job runs; it is implemented by executor.Executor
+type Starter interface {
+	StartRun(ctx context.Context, jobID string, opts executor.RunOptions) (db.JobRun, error)
+	DryRun(ctx context.Context, jobID string, opts executor.RunOptions) (executor.DryRun, error)
+}
+
+// WorkspaceOpener looks up retained workspaces; it is implemented by
+// workspace.Manager
+type WorkspaceOpener interface {
+	Open(jobID, runID string) (*workspace.Workspace, error)
+}
+
+// Service provides job run operations
+type Service interface {
+	StartRun(ctx context.Context, jobID string, req RunRequest) (*RunResponse, error)
+	// DryRun resolves a run of a job without starting it
+	DryRun(ctx context.Context, jobID string, req RunRequest) (*executor.DryRun, error)
+	GetRun(ctx context.Context, jobID string, number int64) (*RunResponse, error)
+	ListRuns(ctx context.Context, jobID string, params RunListParams) (*RunListResponse, error)
+	// GetWorkspace returns the retained workspace of a run and its run
+	// number. A number of 0 selects the latest run with a retained workspace.
+	GetWorkspace(ctx context.Context, jobID string, number int64) (*workspace.Workspace, int64, error)
+	// CompareRuns shows how run b of a job differs from run a
+	CompareRuns(ctx context.Context, jobID string, a, b int64) (*RunComparison, error)
+	// Rerun starts a new run of a job with the inputs, standard input and
+	// input files of an earlier run
+	Rerun(ctx context.Context, jobID string, number int64) (*RunResponse, error)
+}
+
+// runService implements the Service interface
+type runService struct {
+	queries    RunQuerier
+	starter    Starter
+	workspaces WorkspaceOpener
+}
+
+// NewService creates a new run service
+func NewService(queries RunQuerier, starter Starter, workspaces WorkspaceOpener) Service {
+	return &runService{queries: queries, starter: starter, workspaces: workspaces}
+}
+
+// StartRun starts a new run of a job with the requested inputs, standard
+// input and input files
+func (s *runService) StartRun(ctx context.Context, jobID string, req RunRequest) (*RunResponse, error) {
+	return s.start(ctx, jobID, executor.RunOptions{
+		Inputs: req.Inputs,
+		Stdin:  req.Stdin,
+		Files:  req.Files,
+	})
+}
+
+// Rerun replays a run of a job. The new run uses the job's current
+// configuration; only what the run was started with is replayed.
+func (s *runService) Rerun(ctx context.Context, jobID string, number int64) (*RunResponse, error) {
+	run, err := s.getRun(ctx, jobID, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.start(ctx, jobID, executor.RunOptionsOf(run))
+}
+
+// start starts a run of a job with opts
+func (s *runService) start(ctx context.Context, jobID string, opts executor.RunOptions) (*RunResponse, error) {
+	run, err := s.starter.StartRun(ctx, jobID, opts)
+	if err != nil {
+		switch {
+		case db.IsNotFound(err):
+			return nil, ErrJobNotFound
+		case executor.IsInvalidRun(err):
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRun, err)
+		case errors.Is(err, executor.ErrShuttingDown):
+			return nil, ErrUnavailable
+		}
+		return nil, err
+	}
+	return s.withChildren(ctx, run)
+}
+
+// DryRun shows how a run of a job started with req would be executed.
+// Problems with the job or request are reported in the result.
+func (s *runService) DryRun(ctx context.Context, jobID string, req RunRequest) (*executor.DryRun, error) {
+	d, err := s.starter.DryRun(ctx, jobID, executor.RunOptions{
+		Inputs: req.Inputs,
+		Stdin:  req.Stdin,
+		Files:  req.Files,
+	})
+	if db.IsNotFound(err) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// GetRun retrieves a run by its job and run number. Matrix runs include
+// their child runs.
+func (s *runService) GetRun(ctx context.Context, jobID string, number int64) (*RunResponse, error) {
+	run, err := s.getRun(ctx, jobID, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.withChildren(ctx, run)
+}
+
+// withChildren converts a run to a RunResponse listing the children of
+// matrix runs
+func (s *runService) withChildren(ctx context.Context, run db.JobRun) (*RunResponse, error) {
+	resp := toRunResponse(run)
+	if run.ChildCount == 0 {
+		return resp, nil
+	}
+	children, err := s.queries.ListChildRuns(ctx, db.StringToNullString(run.ID))
+	if err != nil {
+		return nil, err
+	}
+	resp.Children = make([]ChildRun, len(children))
+	for i, child := range children {
+		resp.Children[i] = ChildRun{
+			ID:            child.ID,
+			RunNumber:     child.RunNumber,
+			Status:        child.Status,
+			Matrix:        executor.DecodeMatrixValues(child.MatrixValues),
+			FailureReason: child.FailureReason.String,
+			StartedAt:     db.NullTimeToTimePtr(child.StartedAt),
+			FinishedAt:    db.NullTimeToTimePtr(child.FinishedAt),
+		}
+		if child.ExitCode.Valid {
+			resp.Children[i].ExitCode = &child.ExitCode.Int64
+		}
+	}
+	return resp, nil
+}
+
+// ListRuns returns a paginated list of a job's runs, newest first
+func (s *runService) ListRuns(ctx context.Context, jobID string, params RunListParams) (*RunListResponse, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.queries.ListJobRunsByJob(ctx, db.ListJobRunsByJobParams{
+		JobID:  jobID,
+		Limit:  int64(params.PageSize),
+		Offset: int64((params.Page - 1) * params.PageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]RunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = *toRunResponse(run)
+	}
+	return &RunListResponse{Runs: responses, Page: params.Page, PageSize: params.PageSize}, nil
+}
+
+// GetWorkspace returns a retained run workspace
+func (s *runService) GetWorkspace(ctx context.Context, jobID string, number int64) (*workspace.Workspace, int64, error) {
+	if number > 0 {
+		run, err := s.getRun(ctx, jobID, number)
+		if err != nil {
+			return nil, 0, err
+		}
+		ws, err := s.workspaces.Open(jobID, run.ID)
+		if err != nil {
+			return nil, 0, ErrWorkspaceNotFound
+		}
+		return ws, run.RunNumber, nil
+	}
+
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, 0, err
+	}
+	runs, err := s.queries.ListJobRunsByJob(ctx, db.ListJobRunsByJobParams{
+		JobID: jobID,
+		Limit: latestWorkspaceScan,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, run := range runs {
+		if ws, err := s.workspaces.Open(jobID, run.ID); err == nil {
+			return ws, run.RunNumber, nil
+		}
+	}
+	return nil, 0, ErrWorkspaceNotFound
+}
+
+func (s *runService) checkJob(ctx context.Context, jobID string) error {
+	if _, err := s.queries.GetJob(ctx, jobID); err != nil {
+		if db.IsNotFound(err) {
+			return ErrJobNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *runService) getRun(ctx context.Context, jobID string, number int64) (db.JobRun, error) {
+	run, err := s.queries.GetJobRunByNumber(ctx, db.GetJobRunByNumberParams{JobID: jobID, RunNumber: number})
+	if err != nil {
+		if db.IsNotFound(err) {
+			return db.JobRun{}, ErrRunNotFound
+		}
+		return db.JobRun{}, err
+	}
+	return run, nil
+}
+
+// toRunResponse converts a db.JobRun to a RunResponse
+func toRunResponse(run db.JobRun) *RunResponse {
+	metadata := executor.DecodeMetadata(run.Metadata)
+	resp := &RunResponse{
+		ID:               run.ID,
+		JobID:            run.JobID,
+		RunNumber:        run.RunNumber,
+		Status:           run.Status,
+		Stdout:           run.Stdout.String,
+		Stderr:           run.Stderr.String,
+		Metadata:         metadata.Plugin,
+		Artifacts:        metadata.Artifacts,
+		Sandbox:          executor.DecodeSandboxProfile(run.SandboxProfile),
+		Limits:           executor.DecodeLimits(run.Limits),
+		ParentRunID:      run.ParentRunID.String,
+		ScheduledFor:     db.NullTimeToTimePtr(run.ScheduledFor),
+		CaughtUp:         run.CaughtUp,
+		DroppedFireTimes: run.DroppedFireTimes,
+		TriggeredBy:      run.TriggeredByRunID.String,
+		Matrix:           executor.DecodeMatrixValues(run.MatrixValues),
+		Stdin:            executor.DecodeStdin(run.Stdin),
+		Files:            executor.DecodeInputFiles(run.InputFiles),
+		SourceSHA:        run.SourceSha.String,
+		Caches:           executor.DecodeCacheResults(run.CacheResults),
+		FailureReason:    run.FailureReason.String,
+		Attempt:          run.Attempt,
+		LeaseOwner:       run.LeaseOwner.String,
+		CreatedAt:        run.CreatedAt,
+		StartedAt:        db.NullTimeToTimePtr(run.StartedAt),
+		FinishedAt:       db.NullTimeToTimePtr(run.FinishedAt),
+
+		ApprovalExpiresAt: db.NullTimeToTimePtr(run.ApprovalExpiresAt),
+	}
+	if run.ExitCode.Valid {
+		resp.ExitCode = &run.ExitCode.Int64
+	}
+	if run.Inputs.Valid {
+		resp.Inputs = json.RawMessage(run.Inputs.String)
+	}
+	return resp
+}
@@ -0,0 +1,319 @@
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/inputs"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/matrix"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testJobID = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+
+type serviceTest struct {
+	querier    *MockRunQuerier
+	starter    *MockStarter
+	workspaces *workspace.Manager
+	svc        Service
+}
+
+func setupService(t *testing.T) *serviceTest {
+	ctrl := gomock.NewController(t)
+	workspaces, err := workspace.NewManager(workspace.Config{Root: t.TempDir()})
+	require.NoError(t, err)
+	st := &serviceTest{
+		querier:    NewMockRunQuerier(ctrl),
+		starter:    NewMockStarter(ctrl),
+		workspaces: workspaces,
+	}
+	st.svc = NewService(st.querier, st.starter, workspaces)
+	return st
+}
+
+func TestRunService_StartRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "started"},
+		{name: "job not found", err: sql.ErrNoRows, wantErr: ErrJobNotFound},
+		{name: "no plugin", err: executor.ErrNoPlugin, wantErr: ErrInvalidRun},
+		{name: "invalid config", err: plugin.NewConfigError("body", "is required"), wantErr: ErrInvalidRun},
+		{name: "invalid limits", err: fmt.Errorf("%w: processes must not be negative", limits.ErrInvalidLimits), wantErr: ErrInvalidRun},
+		{name: "invalid inputs", err: fmt.Errorf("%w: inputs.region: must be of type string", inputs.ErrInvalidInputs), wantErr: ErrInvalidRun},
+		{name: "invalid matrix", err: fmt.Errorf("%w: axis \"os\" has no values", matrix.ErrInvalidMatrix), wantErr: ErrInvalidRun},
+		{name: "invalid input schema", err: fmt.Errorf("%w: unsupported keyword", inputs.ErrInvalidSchema), wantErr: ErrInvalidRun},
+		{name: "invalid input files", err: fmt.Errorf("%w: job has no attachment \"a-1\"", attachments.ErrInvalidInput), wantErr: ErrInvalidRun},
+		{name: "no attachment store", err: executor.ErrNoAttachmentStore, wantErr: ErrInvalidRun},
+		{name: "shutting down", err: executor.ErrShuttingDown, wantErr: ErrUnavailable},
+	}
+
+	given := json.RawMessage(`{"region": "eu-west-1"}`)
+	stdin := &attachments.Stdin{Attachment: "a-1"}
+	files := []attachments.File{{Attachment: "a-2", Path: "data/input.csv"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := setupService(t)
+			opts := executor.RunOptions{Inputs: given, Stdin: stdin, Files: files}
+			st.starter.EXPECT().StartRun(gomock.Any(), testJobID, opts).Return(db.JobRun{
+				ID:         "run-1",
+				JobID:      testJobID,
+				RunNumber:  1,
+				Status:     executor.StatusPending,
+				Inputs:     sql.NullString{String: `{"region":"eu-west-1"}`, Valid: true},
+				Stdin:      sql.NullString{String: `{"attachment":"a-1","filename":"stdin.txt","size":3,"sha256":"abc"}`, Valid: true},
+				InputFiles: sql.NullString{String: `[{"attachment":"a-2","filename":"input.csv","path":"data/input.csv","size":5,"sha256":"def"}]`, Valid: true},
+				CreatedAt:  time.Now(),
+			}, tt.err)
+
+			resp, err := st.svc.StartRun(context.Background(), testJobID, RunRequest{Inputs: given, Stdin: stdin, Files: files})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), resp.RunNumber)
+			assert.Equal(t, executor.StatusPending, resp.Status)
+			assert.JSONEq(t, `{"region": "eu-west-1"}`, string(resp.Inputs))
+			assert.Equal(t, &attachments.Source{Attachment: "a-1", Filename: "stdin.txt", Size: 3, SHA256: "abc"}, resp.Stdin)
+			assert.Equal(t, []attachments.Source{{Attachment: "a-2", Filename: "input.csv", Path: "data/input.csv", Size: 5, SHA256: "def"}}, resp.Files)
+		})
+	}
+}
+
+func TestRunService_DryRun(t *testing.T) {
+	given := json.RawMessage(`{"region": 1}`)
+	dryRun := executor.DryRun{Errors: []plugin.ConfigError{{Field: "inputs.region", Message: "must be of type string"}}}
+
+	st := setupService(t)
+	st.starter.EXPECT().DryRun(gomock.Any(), testJobID, executor.RunOptions{Inputs: given}).Return(dryRun, nil)
+	resp, err := st.svc.DryRun(context.Background(), testJobID, RunRequest{Inputs: given})
+	require.NoError(t, err)
+	assert.Equal(t, &dryRun, resp)
+
+	st = setupService(t)
+	st.starter.EXPECT().DryRun(gomock.Any(), testJobID, gomock.Any()).Return(executor.DryRun{}, sql.ErrNoRows)
+	_, err = st.svc.DryRun(context.Background(), testJobID, RunRequest{})
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestRunService_GetRun(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().
+		GetJobRunByNumber(gomock.Any(), db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 3}).
+		Return(db.JobRun{
+			ID:            "run-3",
+			JobID:         testJobID,
+			RunNumber:     3,
+			Status:        executor.StatusFailed,
+			ExitCode:      sql.NullInt64{Int64: 2, Valid: true},
+			Metadata:      sql.NullString{String: `{"plugin":{"rows":1},"artifacts":["a.csv"]}`, Valid: true},
+			Limits:        sql.NullString{String: `{"timeout":"1m0s"}`, Valid: true},
+			FailureReason: sql.NullString{String: "timeout", Valid: true},
+			Attempt:       2,
+			LeaseOwner:    sql.NullString{String: "tower-1", Valid: true},
+		}, nil)
+	st.querier.EXPECT().
+		GetJobRunByNumber(gomock.Any(), db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 4}).
+		Return(db.JobRun{}, sql.ErrNoRows)
+
+	resp, err := st.svc.GetRun(context.Background(), testJobID, 3)
+	require.NoError(t, err)
+	require.NotNil(t, resp.ExitCode)
+	assert.Equal(t, int64(2), *resp.ExitCode)
+	assert.Equal(t, []string{"a.csv"}, resp.Artifacts)
+	assert.Equal(t, float64(1), resp.Metadata["rows"])
+	assert.Equal(t, limits.ReasonTimeout, resp.FailureReason)
+	assert.Equal(t, int64(2), resp.Attempt)
+	assert.Equal(t, "tower-1", resp.LeaseOwner)
+	require.NotNil(t, resp.Limits)
+	assert.Equal(t, limits.Duration(time.Minute), resp.Limits.Timeout)
+
+	_, err = st.svc.GetRun(context.Background(), testJobID, 4)
+	assert.ErrorIs(t, err, ErrRunNotFound)
+}
+
+func TestRunService_CompareRuns(t *testing.T) {
+	started := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	st := setupService(t)
+	st.querier.EXPECT().
+		GetJobRunByNumber(gomock.Any(), db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 1}).
+		Return(db.JobRun{
+			ID:         "run-1",
+			RunNumber:  1,
+			Status:     executor.StatusComplete,
+			Inputs:     sql.NullString{String: `{"region":"eu-west-1","day":"mon"}`, Valid: true},
+			Plan:       sql.NullString{String: `{"command":["sh","-c","run"],"env":{"TOKEN":"********","MODE":"full"}}`, Valid: true},
+			ExitCode:   sql.NullInt64{Int64: 0, Valid: true},
+			Stdout:     sql.NullString{String: "start\nloaded 10 rows\ndone\n", Valid: true},
+			StartedAt:  sql.NullTime{Time: started, Valid: true},
+			FinishedAt: sql.NullTime{Time: started.Add(10 * time.Second), Valid: true},
+		}, nil)
+	st.querier.EXPECT().
+		GetJobRunByNumber(gomock.Any(), db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 2}).
+		Return(db.JobRun{
+			ID:         "run-2",
+			RunNumber:  2,
+			Status:     executor.StatusFailed,
+			Inputs:     sql.NullString{String: `{"region":"us-east-1","day":"mon"}`, Valid: true},
+			Plan:       sql.NullString{String: `{"command":["sh","-c","run"],"env":{"TOKEN":"********","DEBUG":"1"}}`, Valid: true},
+			ExitCode:   sql.NullInt64{Int64: 1, Valid: true},
+			Stdout:     sql.NullString{String: "start\nno rows\ndone\n", Valid: true},
+			StartedAt:  sql.NullTime{Time: started, Valid: true},
+			FinishedAt: sql.NullTime{Time: started.Add(4 * time.Second), Valid: true},
+		}, nil)
+
+	c, err := st.svc.CompareRuns(context.Background(), testJobID, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Name: "region", A: "eu-west-1", B: "us-east-1"}}, c.Inputs)
+	assert.Equal(t, []Change{{Name: "DEBUG", B: "1"}, {Name: "MODE", A: "full"}}, c.Env)
+	assert.False(t, c.CommandChanged)
+	assert.True(t, c.ExitCodeChanged)
+	require.NotNil(t, c.DurationDelta)
+	assert.Equal(t, -6.0, *c.DurationDelta)
+	assert.Equal(t, "--- run 1 stdout\n+++ run 2 stdout\n@@ -1,3 +1,3 @@\n start\n-loaded 10 rows\n+no rows\n done\n", c.StdoutDiff)
+	assert.Empty(t, c.StderrDiff)
+
+	st = setupService(t)
+	st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), gomock.Any()).Return(db.JobRun{}, sql.ErrNoRows)
+	_, err = st.svc.CompareRuns(context.Background(), testJobID, 1, 2)
+	assert.ErrorIs(t, err, ErrRunNotFound)
+}
+
+func TestRunService_Rerun(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().
+		GetJobRunByNumber(gomock.Any(), db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 3}).
+		Return(db.JobRun{
+			ID:               "run-3",
+			RunNumber:        3,
+			Inputs:           sql.NullString{String: `{"region":"eu-west-1"}`, Valid: true},
+			Stdin:            sql.NullString{String: `{"attachment":"a-1","filename":"stdin.txt","size":3,"sha256":"abc"}`, Valid: true},
+			InputFiles:       sql.NullString{String: `[{"attachment":"a-2","filename":"input.csv","path":"data/input.csv","size":5,"sha256":"def"}]`, Valid: true},
+			TriggeredByRunID: sql.NullString{String: "upstream-run", Valid: true},
+		}, nil)
+	st.starter.EXPECT().StartRun(gomock.Any(), testJobID, executor.RunOptions{
+		Inputs:      json.RawMessage(`{"region":"eu-west-1"}`),
+		Stdin:       &attachments.Stdin{Attachment: "a-1"},
+		Files:       []attachments.File{{Attachment: "a-2", Path: "data/input.csv"}},
+		TriggeredBy: "upstream-run",
+	}).Return(db.JobRun{ID: "run-4", RunNumber: 4, Status: executor.StatusPending}, nil)
+
+	resp, err := st.svc.Rerun(context.Background(), testJobID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), resp.RunNumber)
+
+	st = setupService(t)
+	st.querier.EXPECT().GetJobRunByNumber(gomock.Any(), gomock.Any()).Return(db.JobRun{RunNumber: 3}, nil)
+	st.starter.EXPECT().StartRun(gomock.Any(), testJobID, gomock.Any()).Return(db.JobRun{}, executor.ErrNoPlugin)
+	_, err = st.svc.Rerun(context.Background(), testJobID, 3)
+	assert.ErrorIs(t, err, ErrInvalidRun)
+}
+
+func TestRunService_GetMatrixRun(t *testing.T) {
+	st := setupService(t)
+	parent := db.JobRun{ID: "run-1", JobID: testJobID, RunNumber: 1, Status: executor.StatusRunning, ChildCount: 2}
+	st.querier.EXPECT().
+		GetJobRunByNumber(gomock.Any(), db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 1}).
+		Return(parent, nil)
+	st.querier.EXPECT().
+		ListChildRuns(gomock.Any(), sql.NullString{String: "run-1", Valid: true}).
+		Return([]db.JobRun{
+			{
+				ID:           "run-2",
+				RunNumber:    2,
+				Status:       executor.StatusComplete,
+				ExitCode:     sql.NullInt64{Int64: 0, Valid: true},
+				ParentRunID:  sql.NullString{String: "run-1", Valid: true},
+				MatrixValues: sql.NullString{String: `{"os":"linux"}`, Valid: true},
+			},
+			{
+				ID:           "run-3",
+				RunNumber:    3,
+				Status:       executor.StatusRunning,
+				ParentRunID:  sql.NullString{String: "run-1", Valid: true},
+				MatrixValues: sql.NullString{String: `{"os":"darwin"}`, Valid: true},
+			},
+		}, nil)
+
+	resp, err := st.svc.GetRun(context.Background(), testJobID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, executor.StatusRunning, resp.Status)
+	require.Len(t, resp.Children, 2)
+	assert.Equal(t, int64(2), resp.Children[0].RunNumber)
+	assert.Equal(t, map[string]interface{}{"os": "linux"}, resp.Children[0].Matrix)
+	require.NotNil(t, resp.Children[0].ExitCode)
+	assert.Equal(t, executor.StatusRunning, resp.Children[1].Status)
+	assert.Nil(t, resp.Children[1].ExitCode)
+}
+
+func TestRunService_ListRuns(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+	st.querier.EXPECT().
+		ListJobRunsByJob(gomock.Any(), db.ListJobRunsByJobParams{JobID: testJobID, Limit: 10, Offset: 10}).
+		Return([]db.JobRun{{ID: "run-2", RunNumber: 2}, {ID: "run-1", RunNumber: 1}}, nil)
+
+	resp, err := st.svc.ListRuns(context.Background(), testJobID, RunListParams{Page: 2, PageSize: 10})
+	require.NoError(t, err)
+	assert.Len(t, resp.Runs, 2)
+	assert.Equal(t, int64(2), resp.Runs[0].RunNumber)
+
+	_, err = st.svc.ListRuns(context.Background(), testJobID, RunListParams{Page: 0, PageSize: 10})
+	assert.Error(t, err)
+
+	st.querier.EXPECT().GetJob(gomock.Any(), "missing").Return(db.Job{}, sql.ErrNoRows)
+	_, err = st.svc.ListRuns(context.Background(), "missing", RunListParams{Page: 1, PageSize: 10})
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestRunService_GetWorkspace(t *testing.T) {
+	t.Run("latest retained", func(t *testing.T) {
+		st := setupService(t)
+		ws, err := st.workspaces.Create(testJobID, "run-1")
+		require.NoError(t, err)
+
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		st.querier.EXPECT().
+			ListJobRunsByJob(gomock.Any(), gomock.Any()).
+			Return([]db.JobRun{{ID: "run-2", RunNumber: 2}, {ID: "run-1", RunNumber: 1}}, nil)
+
+		got, number, err := st.svc.GetWorkspace(context.Background(), testJobID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), number)
+		assert.Equal(t, ws.Path, got.Path)
+	})
+
+	t.Run("specific run without workspace", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().
+			GetJobRunByNumber(gomock.Any(), gomock.Any()).
+			Return(db.JobRun{ID: "run-2", RunNumber: 2}, nil)
+
+		_, _, err := st.svc.GetWorkspace(context.Background(), testJobID, 2)
+		assert.ErrorIs(t, err, ErrWorkspaceNotFound)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{}, errors.New("boom"))
+
+		_, _, err := st.svc.GetWorkspace(context.Background(), testJobID, 0)
+		assert.EqualError(t, err, "boom")
+	})
+}
@@ -0,0 +1,58 @@
+/*
+Package schedules exposes blackout calendars and previews of job schedules
+over HTTP.
+
+A blackout calendar is a named set of windows in which the fire times of
+the schedules naming it in their "calendars" are skipped, recorded as
+skipped runs with the reason "blackout". A window is either a one-off range
+of days, "from" and "to" both included, or a recurring window starting at
+the fire times of a cron expression and lasting "duration". Windows are
+evaluated in the calendar's IANA "timezone", UTC when empty. A calendar
+cannot be deleted while a schedule names it.
+
+The preview of a job's schedule lists its next fire times, 10 by default
+and at most 100, in the schedule's time zone and across DST changes the way
+the scheduler computes them. Fire times falling into a blackout window are
+listed with the name of the calendar skipping them.
+
+API Endpoints:
+
+	GET    /calendars                          - List the blackout calendars
+	POST   /calendars                          - Create a blackout calendar
+	GET    /calendars/{name}                   - Get a blackout calendar
+	PUT    /calendars/{name}                   - Replace a blackout calendar
+	DELETE /calendars/{name}                   - Delete an unused calendar
+	GET    /jobs/{id}/schedule/preview?count=N - Preview the next fire times
+
+Example Request:
+
+	{
+		"name": "change-freeze",
+		"timezone": "Europe/Berlin",
+		"windows": [
+			{"from": "2025-12-20", "to": "2026-01-02"},
+			{"cron": "0 18 * * fri", "duration": "62h"}
+		]
+	}
+
+Example Preview Response:
+
+	{
+		"job_id": "5f0c...",
+		"timezone": "Europe/Berlin",
+		"fire_times": [
+			{"at": "2025-12-19T09:00:00+01:00"},
+			{"at": "2025-12-20T09:00:00+01:00", "blackout": "change-freeze"}
+		]
+	}
+
+Error Handling:
+
+  - 200: Calendars listed, calendar returned or replaced, or preview returned
+  - 201: Calendar created
+  - 204: Calendar deleted
+  - 400: Invalid calendar, job ID or count
+  - 404: Calendar or job not found, or the job has no schedule
+  - 409: The calendar already exists or is named by a schedule
+*/
+package schedules
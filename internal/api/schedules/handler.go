@@ -0,0 +1,140 @@
+package schedules
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for blackout calendars and schedule previews
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new schedule handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the calendar and schedule preview routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/calendars", h.ListCalendars)
+	r.Post("/calendars", h.CreateCalendar)
+	r.Get("/calendars/{name}", h.GetCalendar)
+	r.Put("/calendars/{name}", h.UpdateCalendar)
+	r.Delete("/calendars/{name}", h.DeleteCalendar)
+	r.Get("/jobs/{id}/schedule/preview", h.PreviewSchedule)
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrNoSchedule), errors.Is(err, ErrCalendarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidCalendar), errors.Is(err, ErrInvalidCount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrDuplicateCalendar), errors.Is(err, ErrCalendarInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ListCalendars handles requests to list the blackout calendars
+func (h *Handler) ListCalendars(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.ListCalendars(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetCalendar handles requests to get a blackout calendar
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.GetCalendar(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// CreateCalendar handles requests to create a blackout calendar
+func (h *Handler) CreateCalendar(w http.ResponseWriter, r *http.Request) {
+	var req CalendarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.CreateCalendar(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// UpdateCalendar handles requests to replace a blackout calendar
+func (h *Handler) UpdateCalendar(w http.ResponseWriter, r *http.Request) {
+	var req CalendarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.UpdateCalendar(r.Context(), chi.URLParam(r, "name"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DeleteCalendar handles requests to delete a blackout calendar
+func (h *Handler) DeleteCalendar(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteCalendar(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewSchedule handles requests to preview the next fire times of a
+// job's schedule
+func (h *Handler) PreviewSchedule(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(jobID); err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return
+	}
+	count := DefaultPreview
+	if s := r.URL.Query().Get("count"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, ErrInvalidCount.Error(), http.StatusBadRequest)
+			return
+		}
+		count = n
+	}
+
+	resp, err := h.service.PreviewSchedule(r.Context(), jobID, count)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
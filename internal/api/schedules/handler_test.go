@@ -0,0 +1,143 @@
+package schedules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauern/gopher-tower/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestListCalendars(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().ListCalendars(gomock.Any()).
+		Return(&ListResponse{Calendars: []CalendarResponse{{Name: testCalendar}}}, nil)
+
+	w := serve(router, http.MethodGet, "/calendars", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp ListResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, testCalendar, resp.Calendars[0].Name)
+}
+
+func TestCreateCalendar(t *testing.T) {
+	body := `{"name": "` + testCalendar + `", "windows": [{"from": "2025-12-20", "to": "2026-01-02"}]}`
+	req := CalendarRequest{Name: testCalendar, Windows: []schedule.Window{{From: "2025-12-20", To: "2026-01-02"}}}
+	tests := []struct {
+		name       string
+		body       string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name: "created",
+			body: body,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateCalendar(gomock.Any(), req).Return(&CalendarResponse{Name: testCalendar}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid body",
+			body:       `{`,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid calendar",
+			body: body,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateCalendar(gomock.Any(), req).Return(nil, ErrInvalidCalendar)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "duplicate",
+			body: body,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateCalendar(gomock.Any(), req).Return(nil, ErrDuplicateCalendar)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodPost, "/calendars", tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestUpdateCalendar(t *testing.T) {
+	ms, router := setupHandler(t)
+	req := CalendarRequest{Windows: []schedule.Window{{From: "2025-12-24", To: "2025-12-26"}}}
+	gomock.InOrder(
+		ms.EXPECT().UpdateCalendar(gomock.Any(), testCalendar, req).Return(&CalendarResponse{Name: testCalendar}, nil),
+		ms.EXPECT().UpdateCalendar(gomock.Any(), testCalendar, req).Return(nil, ErrCalendarNotFound),
+	)
+
+	body := `{"windows": [{"from": "2025-12-24", "to": "2025-12-26"}]}`
+	w := serve(router, http.MethodPut, "/calendars/"+testCalendar, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(router, http.MethodPut, "/calendars/"+testCalendar, body)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteCalendar(t *testing.T) {
+	ms, router := setupHandler(t)
+	gomock.InOrder(
+		ms.EXPECT().DeleteCalendar(gomock.Any(), testCalendar).Return(nil),
+		ms.EXPECT().DeleteCalendar(gomock.Any(), testCalendar).Return(ErrCalendarInUse),
+	)
+
+	w := serve(router, http.MethodDelete, "/calendars/"+testCalendar, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodDelete, "/calendars/"+testCalendar, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	ms, router := setupHandler(t)
+	gomock.InOrder(
+		ms.EXPECT().PreviewSchedule(gomock.Any(), testJobID, DefaultPreview).Return(&PreviewResponse{JobID: testJobID}, nil),
+		ms.EXPECT().PreviewSchedule(gomock.Any(), testJobID, 3).Return(&PreviewResponse{JobID: testJobID}, nil),
+		ms.EXPECT().PreviewSchedule(gomock.Any(), testJobID, 1000).Return(nil, ErrInvalidCount),
+		ms.EXPECT().PreviewSchedule(gomock.Any(), testJobID, DefaultPreview).Return(nil, ErrNoSchedule),
+	)
+
+	w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/schedule/preview", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(router, http.MethodGet, "/jobs/"+testJobID+"/schedule/preview?count=3", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(router, http.MethodGet, "/jobs/"+testJobID+"/schedule/preview?count=1000", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodGet, "/jobs/"+testJobID+"/schedule/preview", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(router, http.MethodGet, "/jobs/"+testJobID+"/schedule/preview?count=many", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodGet, "/jobs/not-a-uuid/schedule/preview", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,147 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/schedules (interfaces: ScheduleQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=schedules github.com/klauern/gopher-tower/internal/api/schedules ScheduleQuerier
+//
+
+// Package schedules is a generated GoMock package.
+package schedules
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockScheduleQuerier is a mock of ScheduleQuerier interface.
+type MockScheduleQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduleQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockScheduleQuerierMockRecorder is the mock recorder for MockScheduleQuerier.
+type MockScheduleQuerierMockRecorder struct {
+	mock *MockScheduleQuerier
+}
+
+// NewMockScheduleQuerier creates a new mock instance.
+func NewMockScheduleQuerier(ctrl *gomock.Controller) *MockScheduleQuerier {
+	mock := &MockScheduleQuerier{ctrl: ctrl}
+	mock.recorder = &MockScheduleQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduleQuerier) EXPECT() *MockScheduleQuerierMockRecorder {
+	return m.recorder
+}
+
+// CountJobsUsingCalendar mocks base method.
+func (m *MockScheduleQuerier) CountJobsUsingCalendar(ctx context.Context, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountJobsUsingCalendar", ctx, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountJobsUsingCalendar indicates an expected call of CountJobsUsingCalendar.
+func (mr *MockScheduleQuerierMockRecorder) CountJobsUsingCalendar(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountJobsUsingCalendar", reflect.TypeOf((*MockScheduleQuerier)(nil).CountJobsUsingCalendar), ctx, name)
+}
+
+// CreateCalendar mocks base method.
+func (m *MockScheduleQuerier) CreateCalendar(ctx context.Context, arg db.CreateCalendarParams) (db.Calendar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCalendar", ctx, arg)
+	ret0, _ := ret[0].(db.Calendar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCalendar indicates an expected call of CreateCalendar.
+func (mr *MockScheduleQuerierMockRecorder) CreateCalendar(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCalendar", reflect.TypeOf((*MockScheduleQuerier)(nil).CreateCalendar), ctx, arg)
+}
+
+// DeleteCalendar mocks base method.
+func (m *MockScheduleQuerier) DeleteCalendar(ctx context.Context, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCalendar", ctx, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCalendar indicates an expected call of DeleteCalendar.
+func (mr *MockScheduleQuerierMockRecorder) DeleteCalendar(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCalendar", reflect.TypeOf((*MockScheduleQuerier)(nil).DeleteCalendar), ctx, name)
+}
+
+// GetCalendar mocks base method.
+func (m *MockScheduleQuerier) GetCalendar(ctx context.Context, name string) (db.Calendar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalendar", ctx, name)
+	ret0, _ := ret[0].(db.Calendar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalendar indicates an expected call of GetCalendar.
+func (mr *MockScheduleQuerierMockRecorder) GetCalendar(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalendar", reflect.TypeOf((*MockScheduleQuerier)(nil).GetCalendar), ctx, name)
+}
+
+// GetJob mocks base method.
+func (m *MockScheduleQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockScheduleQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockScheduleQuerier)(nil).GetJob), ctx, id)
+}
+
+// ListCalendars mocks base method.
+func (m *MockScheduleQuerier) ListCalendars(ctx context.Context) ([]db.Calendar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCalendars", ctx)
+	ret0, _ := ret[0].([]db.Calendar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCalendars indicates an expected call of ListCalendars.
+func (mr *MockScheduleQuerierMockRecorder) ListCalendars(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCalendars", reflect.TypeOf((*MockScheduleQuerier)(nil).ListCalendars), ctx)
+}
+
+// UpdateCalendar mocks base method.
+func (m *MockScheduleQuerier) UpdateCalendar(ctx context.Context, arg db.UpdateCalendarParams) (db.Calendar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCalendar", ctx, arg)
+	ret0, _ := ret[0].(db.Calendar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCalendar indicates an expected call of UpdateCalendar.
+func (mr *MockScheduleQuerierMockRecorder) UpdateCalendar(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCalendar", reflect.TypeOf((*MockScheduleQuerier)(nil).UpdateCalendar), ctx, arg)
+}
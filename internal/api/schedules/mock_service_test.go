@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/schedules (interfaces: Service)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_service_test.go -package=schedules github.com/klauern/gopher-tower/internal/api/schedules Service
+//
+
+// Package schedules is a generated GoMock package.
+package schedules
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateCalendar mocks base method.
+func (m *MockService) CreateCalendar(ctx context.Context, req CalendarRequest) (*CalendarResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCalendar", ctx, req)
+	ret0, _ := ret[0].(*CalendarResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCalendar indicates an expected call of CreateCalendar.
+func (mr *MockServiceMockRecorder) CreateCalendar(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCalendar", reflect.TypeOf((*MockService)(nil).CreateCalendar), ctx, req)
+}
+
+// DeleteCalendar mocks base method.
+func (m *MockService) DeleteCalendar(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCalendar", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCalendar indicates an expected call of DeleteCalendar.
+func (mr *MockServiceMockRecorder) DeleteCalendar(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCalendar", reflect.TypeOf((*MockService)(nil).DeleteCalendar), ctx, name)
+}
+
+// GetCalendar mocks base method.
+func (m *MockService) GetCalendar(ctx context.Context, name string) (*CalendarResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalendar", ctx, name)
+	ret0, _ := ret[0].(*CalendarResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalendar indicates an expected call of GetCalendar.
+func (mr *MockServiceMockRecorder) GetCalendar(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalendar", reflect.TypeOf((*MockService)(nil).GetCalendar), ctx, name)
+}
+
+// ListCalendars mocks base method.
+func (m *MockService) ListCalendars(ctx context.Context) (*ListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCalendars", ctx)
+	ret0, _ := ret[0].(*ListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCalendars indicates an expected call of ListCalendars.
+func (mr *MockServiceMockRecorder) ListCalendars(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCalendars", reflect.TypeOf((*MockService)(nil).ListCalendars), ctx)
+}
+
+// PreviewSchedule mocks base method.
+func (m *MockService) PreviewSchedule(ctx context.Context, jobID string, count int) (*PreviewResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewSchedule", ctx, jobID, count)
+	ret0, _ := ret[0].(*PreviewResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewSchedule indicates an expected call of PreviewSchedule.
+func (mr *MockServiceMockRecorder) PreviewSchedule(ctx, jobID, count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewSchedule", reflect.TypeOf((*MockService)(nil).PreviewSchedule), ctx, jobID, count)
+}
+
+// UpdateCalendar mocks base method.
+func (m *MockService) UpdateCalendar(ctx context.Context, name string, req CalendarRequest) (*CalendarResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCalendar", ctx, name, req)
+	ret0, _ := ret[0].(*CalendarResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCalendar indicates an expected call of UpdateCalendar.
+func (mr *MockServiceMockRecorder) UpdateCalendar(ctx, name, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCalendar", reflect.TypeOf((*MockService)(nil).UpdateCalendar), ctx, name, req)
+}
@@ -0,0 +1,57 @@
+package schedules
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/schedule"
+)
+
+// calendarName matches valid calendar names, which appear in URLs
+var calendarName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// CalendarRequest represents the request to create or replace a blackout
+// calendar
+type CalendarRequest struct {
+	// Name identifies the calendar in schedules; only used on creation
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Timezone is the IANA time zone the windows are evaluated in; UTC
+	// when empty
+	Timezone string            `json:"timezone,omitempty"`
+	Windows  []schedule.Window `json:"windows"`
+}
+
+// Validate checks if the calendar request is valid
+func (r *CalendarRequest) Validate() error {
+	if !calendarName.MatchString(r.Name) {
+		return errors.New("name must be up to 64 letters, digits, dots, dashes and underscores")
+	}
+	c := schedule.Calendar{Timezone: r.Timezone, Windows: r.Windows}
+	return c.Validate()
+}
+
+// CalendarResponse represents a blackout calendar in responses
+type CalendarResponse struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Timezone    string            `json:"timezone,omitempty"`
+	Windows     []schedule.Window `json:"windows"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// ListResponse represents the blackout calendars
+type ListResponse struct {
+	Calendars []CalendarResponse `json:"calendars"`
+}
+
+// PreviewResponse represents the upcoming fire times of a job's schedule
+type PreviewResponse struct {
+	JobID    string `json:"job_id"`
+	Timezone string `json:"timezone"`
+	// FireTimes are in the schedule's time zone; those skipped by one of
+	// its calendars name it
+	FireTimes []schedule.FireTime `json:"fire_times"`
+}
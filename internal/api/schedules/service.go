@@ -0,0 +1,232 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=schedules github.com/klauern/gopher-tower/internal/api/schedules ScheduleQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=schedules github.com/klauern/gopher-tower/internal/api/schedules Service
+
+package schedules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/schedule"
+)
+
+const (
+	// DefaultPreview is the number of fire times previewed by default
+	DefaultPreview = 10
+	// MaxPreview is the largest number of fire times previewed at once
+	MaxPreview = 100
+)
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrNoSchedule        = errors.New("job has no schedule")
+	ErrCalendarNotFound  = errors.New("calendar not found")
+	ErrInvalidCalendar   = schedule.ErrInvalidCalendar
+	ErrDuplicateCalendar = errors.New("calendar already exists")
+	ErrCalendarInUse     = errors.New("calendar is used by a schedule")
+	ErrInvalidCount      = errors.New("count must be between 1 and 100")
+)
+
+// ScheduleQuerier defines the interface for calendar and schedule database
+// operations
+type ScheduleQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	CreateCalendar(ctx context.Context, arg db.CreateCalendarParams) (db.Calendar, error)
+	GetCalendar(ctx context.Context, name string) (db.Calendar, error)
+	ListCalendars(ctx context.Context) ([]db.Calendar, error)
+	UpdateCalendar(ctx context.Context, arg db.UpdateCalendarParams) (db.Calendar, error)
+	DeleteCalendar(ctx context.Context, name string) (int64, error)
+	CountJobsUsingCalendar(ctx context.Context, name string) (int64, error)
+}
+
+// Service provides blackout calendar and schedule preview operations
+type Service interface {
+	ListCalendars(ctx context.Context) (*ListResponse, error)
+	GetCalendar(ctx context.Context, name string) (*CalendarResponse, error)
+	CreateCalendar(ctx context.Context, req CalendarRequest) (*CalendarResponse, error)
+	UpdateCalendar(ctx context.Context, name string, req CalendarRequest) (*CalendarResponse, error)
+	DeleteCalendar(ctx context.Context, name string) error
+	PreviewSchedule(ctx context.Context, jobID string, count int) (*PreviewResponse, error)
+}
+
+// scheduleService implements the Service interface
+type scheduleService struct {
+	queries ScheduleQuerier
+	now     func() time.Time
+}
+
+// NewService creates a new schedule service
+func NewService(queries ScheduleQuerier) Service {
+	return &scheduleService{queries: queries, now: time.Now}
+}
+
+// ListCalendars returns all blackout calendars by name
+func (s *scheduleService) ListCalendars(ctx context.Context) (*ListResponse, error) {
+	calendars, err := s.queries.ListCalendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListResponse{Calendars: make([]CalendarResponse, len(calendars))}
+	for i, c := range calendars {
+		resp.Calendars[i] = toCalendarResponse(c)
+	}
+	return resp, nil
+}
+
+// GetCalendar returns a blackout calendar
+func (s *scheduleService) GetCalendar(ctx context.Context, name string) (*CalendarResponse, error) {
+	c, err := s.queries.GetCalendar(ctx, name)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrCalendarNotFound
+		}
+		return nil, err
+	}
+	resp := toCalendarResponse(c)
+	return &resp, nil
+}
+
+// CreateCalendar stores a new blackout calendar
+func (s *scheduleService) CreateCalendar(ctx context.Context, req CalendarRequest) (*CalendarResponse, error) {
+	windows, err := encodeWindows(req)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.queries.CreateCalendar(ctx, db.CreateCalendarParams{
+		Name:        req.Name,
+		Description: db.StringToNullString(req.Description),
+		Timezone:    db.StringToNullString(req.Timezone),
+		Windows:     windows,
+	})
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return nil, ErrDuplicateCalendar
+		}
+		return nil, err
+	}
+	resp := toCalendarResponse(c)
+	return &resp, nil
+}
+
+// UpdateCalendar replaces the description, time zone and windows of a
+// blackout calendar. Calendars cannot be renamed.
+func (s *scheduleService) UpdateCalendar(ctx context.Context, name string, req CalendarRequest) (*CalendarResponse, error) {
+	if req.Name == "" {
+		req.Name = name
+	}
+	if req.Name != name {
+		return nil, fmt.Errorf("%w: calendars cannot be renamed", ErrInvalidCalendar)
+	}
+	windows, err := encodeWindows(req)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.queries.UpdateCalendar(ctx, db.UpdateCalendarParams{
+		Description: db.StringToNullString(req.Description),
+		Timezone:    db.StringToNullString(req.Timezone),
+		Windows:     windows,
+		Name:        name,
+	})
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrCalendarNotFound
+		}
+		return nil, err
+	}
+	resp := toCalendarResponse(c)
+	return &resp, nil
+}
+
+// DeleteCalendar removes a blackout calendar no schedule names
+func (s *scheduleService) DeleteCalendar(ctx context.Context, name string) error {
+	users, err := s.queries.CountJobsUsingCalendar(ctx, name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrCalendarInUse
+	}
+	n, err := s.queries.DeleteCalendar(ctx, name)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrCalendarNotFound
+	}
+	return nil
+}
+
+// PreviewSchedule returns the next count fire times of a job's schedule,
+// marking those its calendars skip
+func (s *scheduleService) PreviewSchedule(ctx context.Context, jobID string, count int) (*PreviewResponse, error) {
+	if count < 1 || count > MaxPreview {
+		return nil, ErrInvalidCount
+	}
+	job, err := s.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	if !job.Schedule.Valid {
+		return nil, ErrNoSchedule
+	}
+	sched, err := schedule.Decode([]byte(job.Schedule.String))
+	if err != nil {
+		return nil, err
+	}
+	calendars, err := schedule.LoadCalendars(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+	times, err := sched.Preview(s.now(), count, calendars)
+	if err != nil {
+		return nil, err
+	}
+	if times == nil {
+		// A schedule whose cron never matches has no fire times
+		times = []schedule.FireTime{}
+	}
+
+	timezone := sched.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return &PreviewResponse{JobID: jobID, Timezone: timezone, FireTimes: times}, nil
+}
+
+// encodeWindows validates a calendar request and serializes its windows for
+// storage
+func encodeWindows(req CalendarRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		if !errors.Is(err, ErrInvalidCalendar) {
+			err = fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+		}
+		return "", err
+	}
+	data, err := json.Marshal(req.Windows)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// toCalendarResponse converts a db.Calendar to a CalendarResponse
+func toCalendarResponse(c db.Calendar) CalendarResponse {
+	resp := CalendarResponse{
+		Name:        c.Name,
+		Description: c.Description.String,
+		Timezone:    c.Timezone.String,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if err := json.Unmarshal([]byte(c.Windows), &resp.Windows); err != nil {
+		resp.Windows = nil
+	}
+	return resp
+}
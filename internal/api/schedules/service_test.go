@@ -0,0 +1,208 @@
+package schedules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testJobID    = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+	testCalendar = "change-freeze"
+)
+
+func setupService(t *testing.T) (*MockScheduleQuerier, Service) {
+	ctrl := gomock.NewController(t)
+	querier := NewMockScheduleQuerier(ctrl)
+	now := func() time.Time { return time.Date(2025, 12, 18, 12, 0, 0, 0, time.UTC) }
+	return querier, &scheduleService{queries: querier, now: now}
+}
+
+func testCalendarRow() db.Calendar {
+	return db.Calendar{
+		Name:      testCalendar,
+		Timezone:  sql.NullString{String: "Europe/Berlin", Valid: true},
+		Windows:   `[{"from":"2025-12-20","to":"2026-01-02"}]`,
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestScheduleService_CreateCalendar(t *testing.T) {
+	ctx := context.Background()
+	req := CalendarRequest{
+		Name:     testCalendar,
+		Timezone: "Europe/Berlin",
+		Windows:  []schedule.Window{{From: "2025-12-20", To: "2026-01-02"}},
+	}
+
+	t.Run("creates the calendar", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().
+			CreateCalendar(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateCalendarParams) (db.Calendar, error) {
+				assert.Equal(t, testCalendar, arg.Name)
+				assert.Equal(t, "Europe/Berlin", arg.Timezone.String)
+				assert.JSONEq(t, `[{"from":"2025-12-20","to":"2026-01-02"}]`, arg.Windows)
+				return testCalendarRow(), nil
+			})
+
+		resp, err := svc.CreateCalendar(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, testCalendar, resp.Name)
+		assert.Equal(t, req.Windows, resp.Windows)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, svc := setupService(t)
+		for name, req := range map[string]CalendarRequest{
+			"missing name":          {Windows: req.Windows},
+			"name with slash":       {Name: "a/b", Windows: req.Windows},
+			"unknown time zone":     {Name: testCalendar, Timezone: "Mars/Olympus", Windows: req.Windows},
+			"no windows":            {Name: testCalendar},
+			"cron without duration": {Name: testCalendar, Windows: []schedule.Window{{Cron: "0 18 * * fri"}}},
+		} {
+			_, err := svc.CreateCalendar(ctx, req)
+			assert.ErrorIs(t, err, ErrInvalidCalendar, name)
+		}
+	})
+
+	t.Run("duplicate calendar", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().
+			CreateCalendar(gomock.Any(), gomock.Any()).
+			Return(db.Calendar{}, errors.New("constraint failed: UNIQUE constraint failed: calendars.name"))
+
+		_, err := svc.CreateCalendar(ctx, req)
+		assert.ErrorIs(t, err, ErrDuplicateCalendar)
+	})
+}
+
+func TestScheduleService_UpdateCalendar(t *testing.T) {
+	ctx := context.Background()
+	req := CalendarRequest{Windows: []schedule.Window{{Cron: "0 18 * * fri", Duration: limits.Duration(62 * time.Hour)}}}
+
+	t.Run("replaces the calendar", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().
+			UpdateCalendar(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.UpdateCalendarParams) (db.Calendar, error) {
+				assert.Equal(t, testCalendar, arg.Name)
+				assert.False(t, arg.Timezone.Valid)
+				assert.JSONEq(t, `[{"cron":"0 18 * * fri","duration":"62h0m0s"}]`, arg.Windows)
+				return testCalendarRow(), nil
+			})
+
+		_, err := svc.UpdateCalendar(ctx, testCalendar, req)
+		require.NoError(t, err)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		_, svc := setupService(t)
+		renamed := req
+		renamed.Name = "other"
+		_, err := svc.UpdateCalendar(ctx, testCalendar, renamed)
+		assert.ErrorIs(t, err, ErrInvalidCalendar)
+	})
+
+	t.Run("unknown calendar", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().UpdateCalendar(gomock.Any(), gomock.Any()).Return(db.Calendar{}, sql.ErrNoRows)
+		_, err := svc.UpdateCalendar(ctx, testCalendar, req)
+		assert.ErrorIs(t, err, ErrCalendarNotFound)
+	})
+}
+
+func TestScheduleService_DeleteCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes the calendar", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().CountJobsUsingCalendar(gomock.Any(), testCalendar).Return(int64(0), nil)
+		querier.EXPECT().DeleteCalendar(gomock.Any(), testCalendar).Return(int64(1), nil)
+		assert.NoError(t, svc.DeleteCalendar(ctx, testCalendar))
+	})
+
+	t.Run("calendar in use", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().CountJobsUsingCalendar(gomock.Any(), testCalendar).Return(int64(2), nil)
+		assert.ErrorIs(t, svc.DeleteCalendar(ctx, testCalendar), ErrCalendarInUse)
+	})
+
+	t.Run("unknown calendar", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().CountJobsUsingCalendar(gomock.Any(), testCalendar).Return(int64(0), nil)
+		querier.EXPECT().DeleteCalendar(gomock.Any(), testCalendar).Return(int64(0), nil)
+		assert.ErrorIs(t, svc.DeleteCalendar(ctx, testCalendar), ErrCalendarNotFound)
+	})
+}
+
+func TestScheduleService_GetCalendar(t *testing.T) {
+	querier, svc := setupService(t)
+	querier.EXPECT().GetCalendar(gomock.Any(), testCalendar).Return(testCalendarRow(), nil)
+	querier.EXPECT().GetCalendar(gomock.Any(), "nope").Return(db.Calendar{}, sql.ErrNoRows)
+
+	resp, err := svc.GetCalendar(context.Background(), testCalendar)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", resp.Timezone)
+	_, err = svc.GetCalendar(context.Background(), "nope")
+	assert.ErrorIs(t, err, ErrCalendarNotFound)
+}
+
+func TestScheduleService_PreviewSchedule(t *testing.T) {
+	ctx := context.Background()
+	scheduled := db.Job{
+		ID: testJobID,
+		Schedule: sql.NullString{
+			String: `{"cron": "0 9 * * *", "timezone": "Europe/Berlin", "calendars": ["` + testCalendar + `"]}`,
+			Valid:  true,
+		},
+	}
+
+	t.Run("previews the fire times", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(scheduled, nil)
+		querier.EXPECT().ListCalendars(gomock.Any()).Return([]db.Calendar{testCalendarRow()}, nil)
+
+		resp, err := svc.PreviewSchedule(ctx, testJobID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "Europe/Berlin", resp.Timezone)
+		require.Len(t, resp.FireTimes, 3)
+		// 09:00 in Berlin is 08:00 UTC in winter
+		assert.True(t, time.Date(2025, 12, 19, 8, 0, 0, 0, time.UTC).Equal(resp.FireTimes[0].At))
+		assert.Empty(t, resp.FireTimes[0].Blackout)
+		assert.Equal(t, testCalendar, resp.FireTimes[1].Blackout)
+		assert.Equal(t, testCalendar, resp.FireTimes[2].Blackout)
+	})
+
+	t.Run("invalid count", func(t *testing.T) {
+		_, svc := setupService(t)
+		for _, count := range []int{0, MaxPreview + 1} {
+			_, err := svc.PreviewSchedule(ctx, testJobID, count)
+			assert.ErrorIs(t, err, ErrInvalidCount)
+		}
+	})
+
+	t.Run("job without schedule", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		_, err := svc.PreviewSchedule(ctx, testJobID, 3)
+		assert.ErrorIs(t, err, ErrNoSchedule)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{}, db.ErrNotFound)
+		_, err := svc.PreviewSchedule(ctx, testJobID, 3)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
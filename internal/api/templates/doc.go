@@ -0,0 +1,48 @@
+/*
+Package templates exposes job templates over HTTP.
+
+A template declares typed parameters and a job body with ${{ params.x }}
+placeholders (see internal/templates). Instantiating a template renders the
+body with the given parameters and creates a job from it, optionally
+starting a run right away.
+
+Versioning:
+
+Updating a template creates a new version; earlier versions are kept.
+Instantiation uses the latest version unless another one is given, and the
+created job records the template version and the parameters it was
+rendered from, so its runs can be traced back and reproduced.
+
+API Endpoints:
+
+	POST   /templates                  - Create a template (version 1)
+	GET    /templates                  - List templates
+	GET    /templates/{id}             - Get the latest version, or ?version=N
+	PUT    /templates/{id}             - Create a new version
+	GET    /templates/{id}/versions    - List versions, newest first
+	POST   /templates/{id}/instantiate - Create a job, and optionally run it
+
+Example Instantiation:
+
+	POST /templates/{id}/instantiate
+	{
+		"params": {"env": "prod", "shards": 8},
+		"run": true
+	}
+
+	Response:
+	{
+		"job": {"id": "...", "name": "export-prod", "template": {"id": "...", "version": 3, "params": {...}}, ...},
+		"run": {"id": "...", "run_number": 1, "status": "pending"}
+	}
+
+Error Handling:
+
+  - 201: Template created, or job instantiated
+  - 400: Invalid template, parameters, or rendered job
+  - 401: Missing user for created templates and jobs
+  - 404: Template or version not found
+  - 409: Template name taken, or a concurrent update created the version
+  - 503: The executor is shutting down
+*/
+package templates
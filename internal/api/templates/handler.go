@@ -0,0 +1,220 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+)
+
+// Handler handles HTTP requests for job templates
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new template handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the template routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/templates", h.CreateTemplate)
+	r.Get("/templates", h.ListTemplates)
+	r.Get("/templates/{id}", h.GetTemplate)
+	r.Put("/templates/{id}", h.UpdateTemplate)
+	r.Get("/templates/{id}/versions", h.ListVersions)
+	r.Post("/templates/{id}/instantiate", h.Instantiate)
+}
+
+// templateID extracts and validates the template ID path parameter
+func templateID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid template ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// userID returns the user creating templates and jobs
+func userID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, ok := r.Context().Value(jobs.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+	return id, ok
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrTemplateNotFound), errors.Is(err, ErrVersionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidTemplate), errors.Is(err, ErrInvalidParams),
+		errors.Is(err, ErrInvalidJob), errors.Is(err, ErrInvalidRun):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNameTaken), errors.Is(err, ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// CreateTemplate handles template creation requests
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	owner, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.CreateTemplate(r.Context(), req, owner)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// GetTemplate handles template retrieval requests. The latest version is
+// returned unless one is given with ?version=.
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := templateID(w, r)
+	if !ok {
+		return
+	}
+	var version int64
+	if v := r.URL.Query().Get("version"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid version parameter", http.StatusBadRequest)
+			return
+		}
+		version = n
+	}
+
+	resp, err := h.service.GetTemplate(r.Context(), id, version)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ListTemplates handles template listing requests
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	params := ListParams{
+		Page:     1,
+		PageSize: 10,
+	}
+	if page := r.URL.Query().Get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil {
+			http.Error(w, "Invalid page parameter", http.StatusBadRequest)
+			return
+		}
+		params.Page = p
+	}
+	if pageSize := r.URL.Query().Get("page_size"); pageSize != "" {
+		ps, err := strconv.Atoi(pageSize)
+		if err != nil {
+			http.Error(w, "Invalid page_size parameter", http.StatusBadRequest)
+			return
+		}
+		params.PageSize = ps
+	}
+	if err := params.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.ListTemplates(r.Context(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// UpdateTemplate handles requests creating a new template version
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := templateID(w, r)
+	if !ok {
+		return
+	}
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.UpdateTemplate(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ListVersions handles template version listing requests
+func (h *Handler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	id, ok := templateID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.ListVersions(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Instantiate handles requests to create, and optionally run, a job from a
+// template
+func (h *Handler) Instantiate(w http.ResponseWriter, r *http.Request) {
+	id, ok := templateID(w, r)
+	if !ok {
+		return
+	}
+	var req InstantiateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Version < 0 {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+	owner, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.Instantiate(r.Context(), id, req, owner)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
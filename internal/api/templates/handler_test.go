@@ -0,0 +1,249 @@
+package templates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, method, target, body string, user bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if user {
+		req = req.WithContext(context.WithValue(req.Context(), jobs.UserIDKey, "owner-1"))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		user       bool
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name: "created",
+			body: `{"name": "export", "body": {"plugin": "script"}}`,
+			user: true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateTemplate(gomock.Any(), gomock.Any(), "owner-1").Return(&TemplateResponse{ID: testTemplateID, Version: 1}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid body",
+			body:       `{`,
+			user:       true,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no user",
+			body:       `{"name": "export"}`,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "invalid template",
+			body: `{"name": "export"}`,
+			user: true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateTemplate(gomock.Any(), gomock.Any(), "owner-1").Return(nil, ErrInvalidTemplate)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "name taken",
+			body: `{"name": "export", "body": {}}`,
+			user: true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateTemplate(gomock.Any(), gomock.Any(), "owner-1").Return(nil, ErrNameTaken)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodPost, "/templates", tt.body, tt.user)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestGetTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name:   "latest",
+			target: "/templates/" + testTemplateID,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().GetTemplate(gomock.Any(), testTemplateID, int64(0)).Return(&TemplateResponse{Version: 2}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "specific version",
+			target: "/templates/" + testTemplateID + "?version=1",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().GetTemplate(gomock.Any(), testTemplateID, int64(1)).Return(&TemplateResponse{Version: 1}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid version",
+			target:     "/templates/" + testTemplateID + "?version=0",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid id",
+			target:     "/templates/export",
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "version not found",
+			target: "/templates/" + testTemplateID + "?version=9",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().GetTemplate(gomock.Any(), testTemplateID, int64(9)).Return(nil, ErrVersionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodGet, tt.target, "", false)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestListTemplates(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().ListTemplates(gomock.Any(), ListParams{Page: 2, PageSize: 5}).Return(&ListResponse{}, nil)
+
+	w := serve(router, http.MethodGet, "/templates?page=2&page_size=5", "", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(router, http.MethodGet, "/templates?page_size=500", "", false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().UpdateTemplate(gomock.Any(), testTemplateID, gomock.Any()).Return(&TemplateResponse{Version: 3}, nil)
+	ms.EXPECT().UpdateTemplate(gomock.Any(), testTemplateID, gomock.Any()).Return(nil, ErrConflict)
+
+	w := serve(router, http.MethodPut, "/templates/"+testTemplateID, `{"name": "export", "body": {}}`, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(router, http.MethodPut, "/templates/"+testTemplateID, `{"name": "export", "body": {}}`, false)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestListVersions(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().ListVersions(gomock.Any(), testTemplateID).Return([]VersionResponse{{Version: 2}, {Version: 1}}, nil)
+
+	w := serve(router, http.MethodGet, "/templates/"+testTemplateID+"/versions", "", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"version": 2, "created_at": "0001-01-01T00:00:00Z"}, {"version": 1, "created_at": "0001-01-01T00:00:00Z"}]`, w.Body.String())
+}
+
+func TestInstantiate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		user       bool
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name: "created",
+			body: `{"params": {"env": "prod"}, "run": true}`,
+			user: true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().
+					Instantiate(gomock.Any(), testTemplateID, InstantiateRequest{Params: map[string]interface{}{"env": "prod"}, Run: true}, "owner-1").
+					Return(&InstantiateResponse{Job: &jobs.JobResponse{ID: testJobID}, Run: &RunSummary{RunNumber: 1}}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "negative version",
+			body:       `{"version": -1}`,
+			user:       true,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no user",
+			body:       `{}`,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "invalid parameters",
+			body: `{"params": {"env": "dev"}}`,
+			user: true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Instantiate(gomock.Any(), testTemplateID, gomock.Any(), "owner-1").Return(nil, ErrInvalidParams)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "template not found",
+			body: `{}`,
+			user: true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Instantiate(gomock.Any(), testTemplateID, gomock.Any(), "owner-1").Return(nil, ErrTemplateNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "executor shutting down",
+			body: `{"run": true}`,
+			user: true,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Instantiate(gomock.Any(), testTemplateID, gomock.Any(), "owner-1").Return(nil, ErrUnavailable)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodPost, "/templates/"+testTemplateID+"/instantiate", tt.body, tt.user)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
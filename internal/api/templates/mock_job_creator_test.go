@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/templates (interfaces: JobCreator)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_job_creator_test.go -package=templates github.com/klauern/gopher-tower/internal/api/templates JobCreator
+//
+
+// Package templates is a generated GoMock package.
+package templates
+
+import (
+	context "context"
+	reflect "reflect"
+
+	jobs "github.com/klauern/gopher-tower/internal/api/jobs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockJobCreator is a mock of JobCreator interface.
+type MockJobCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobCreatorMockRecorder
+	isgomock struct{}
+}
+
+// MockJobCreatorMockRecorder is the mock recorder for MockJobCreator.
+type MockJobCreatorMockRecorder struct {
+	mock *MockJobCreator
+}
+
+// NewMockJobCreator creates a new mock instance.
+func NewMockJobCreator(ctrl *gomock.Controller) *MockJobCreator {
+	mock := &MockJobCreator{ctrl: ctrl}
+	mock.recorder = &MockJobCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobCreator) EXPECT() *MockJobCreatorMockRecorder {
+	return m.recorder
+}
+
+// CreateTemplatedJob mocks base method.
+func (m *MockJobCreator) CreateTemplatedJob(ctx context.Context, req jobs.JobRequest, ownerID string, origin jobs.TemplateOrigin) (*jobs.JobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTemplatedJob", ctx, req, ownerID, origin)
+	ret0, _ := ret[0].(*jobs.JobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTemplatedJob indicates an expected call of CreateTemplatedJob.
+func (mr *MockJobCreatorMockRecorder) CreateTemplatedJob(ctx, req, ownerID, origin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTemplatedJob", reflect.TypeOf((*MockJobCreator)(nil).CreateTemplatedJob), ctx, req, ownerID, origin)
+}
@@ -0,0 +1,162 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/templates (interfaces: TemplateQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=templates github.com/klauern/gopher-tower/internal/api/templates TemplateQuerier
+//
+
+// Package templates is a generated GoMock package.
+package templates
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTemplateQuerier is a mock of TemplateQuerier interface.
+type MockTemplateQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockTemplateQuerierMockRecorder is the mock recorder for MockTemplateQuerier.
+type MockTemplateQuerierMockRecorder struct {
+	mock *MockTemplateQuerier
+}
+
+// NewMockTemplateQuerier creates a new mock instance.
+func NewMockTemplateQuerier(ctrl *gomock.Controller) *MockTemplateQuerier {
+	mock := &MockTemplateQuerier{ctrl: ctrl}
+	mock.recorder = &MockTemplateQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplateQuerier) EXPECT() *MockTemplateQuerierMockRecorder {
+	return m.recorder
+}
+
+// CountJobTemplates mocks base method.
+func (m *MockTemplateQuerier) CountJobTemplates(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountJobTemplates", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountJobTemplates indicates an expected call of CountJobTemplates.
+func (mr *MockTemplateQuerierMockRecorder) CountJobTemplates(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountJobTemplates", reflect.TypeOf((*MockTemplateQuerier)(nil).CountJobTemplates), ctx)
+}
+
+// CreateJobTemplate mocks base method.
+func (m *MockTemplateQuerier) CreateJobTemplate(ctx context.Context, arg db.CreateJobTemplateParams) (db.JobTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobTemplate", ctx, arg)
+	ret0, _ := ret[0].(db.JobTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJobTemplate indicates an expected call of CreateJobTemplate.
+func (mr *MockTemplateQuerierMockRecorder) CreateJobTemplate(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobTemplate", reflect.TypeOf((*MockTemplateQuerier)(nil).CreateJobTemplate), ctx, arg)
+}
+
+// CreateJobTemplateVersion mocks base method.
+func (m *MockTemplateQuerier) CreateJobTemplateVersion(ctx context.Context, arg db.CreateJobTemplateVersionParams) (db.JobTemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobTemplateVersion", ctx, arg)
+	ret0, _ := ret[0].(db.JobTemplateVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJobTemplateVersion indicates an expected call of CreateJobTemplateVersion.
+func (mr *MockTemplateQuerierMockRecorder) CreateJobTemplateVersion(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobTemplateVersion", reflect.TypeOf((*MockTemplateQuerier)(nil).CreateJobTemplateVersion), ctx, arg)
+}
+
+// GetJobTemplate mocks base method.
+func (m *MockTemplateQuerier) GetJobTemplate(ctx context.Context, id string) (db.JobTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobTemplate", ctx, id)
+	ret0, _ := ret[0].(db.JobTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobTemplate indicates an expected call of GetJobTemplate.
+func (mr *MockTemplateQuerierMockRecorder) GetJobTemplate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobTemplate", reflect.TypeOf((*MockTemplateQuerier)(nil).GetJobTemplate), ctx, id)
+}
+
+// GetJobTemplateVersion mocks base method.
+func (m *MockTemplateQuerier) GetJobTemplateVersion(ctx context.Context, arg db.GetJobTemplateVersionParams) (db.JobTemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobTemplateVersion", ctx, arg)
+	ret0, _ := ret[0].(db.JobTemplateVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobTemplateVersion indicates an expected call of GetJobTemplateVersion.
+func (mr *MockTemplateQuerierMockRecorder) GetJobTemplateVersion(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobTemplateVersion", reflect.TypeOf((*MockTemplateQuerier)(nil).GetJobTemplateVersion), ctx, arg)
+}
+
+// ListJobTemplateVersions mocks base method.
+func (m *MockTemplateQuerier) ListJobTemplateVersions(ctx context.Context, templateID string) ([]db.JobTemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobTemplateVersions", ctx, templateID)
+	ret0, _ := ret[0].([]db.JobTemplateVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobTemplateVersions indicates an expected call of ListJobTemplateVersions.
+func (mr *MockTemplateQuerierMockRecorder) ListJobTemplateVersions(ctx, templateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobTemplateVersions", reflect.TypeOf((*MockTemplateQuerier)(nil).ListJobTemplateVersions), ctx, templateID)
+}
+
+// ListJobTemplates mocks base method.
+func (m *MockTemplateQuerier) ListJobTemplates(ctx context.Context, arg db.ListJobTemplatesParams) ([]db.JobTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobTemplates", ctx, arg)
+	ret0, _ := ret[0].([]db.JobTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobTemplates indicates an expected call of ListJobTemplates.
+func (mr *MockTemplateQuerierMockRecorder) ListJobTemplates(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobTemplates", reflect.TypeOf((*MockTemplateQuerier)(nil).ListJobTemplates), ctx, arg)
+}
+
+// UpdateJobTemplate mocks base method.
+func (m *MockTemplateQuerier) UpdateJobTemplate(ctx context.Context, arg db.UpdateJobTemplateParams) (db.JobTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJobTemplate", ctx, arg)
+	ret0, _ := ret[0].(db.JobTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateJobTemplate indicates an expected call of UpdateJobTemplate.
+func (mr *MockTemplateQuerierMockRecorder) UpdateJobTemplate(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJobTemplate", reflect.TypeOf((*MockTemplateQuerier)(nil).UpdateJobTemplate), ctx, arg)
+}
@@ -0,0 +1,104 @@
+package templates
+
+import (
+	"errors"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/klauern/gopher-tower/internal/templates"
+)
+
+// TemplateRequest represents the request to create a template or a new
+// version of one
+type TemplateRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Parameters  []templates.Param `json:"parameters"`
+	// Body is a job request whose strings may contain ${{ params.x }}
+	// placeholders
+	Body map[string]interface{} `json:"body"`
+}
+
+// Validate checks if the template request is valid
+func (r *TemplateRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if r.Body == nil {
+		return errors.New("body is required")
+	}
+	if err := templates.ValidateParams(r.Parameters); err != nil {
+		return err
+	}
+	return templates.Check(r.Parameters, r.Body)
+}
+
+// TemplateResponse represents a version of a template in responses
+type TemplateResponse struct {
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	OwnerID       string                 `json:"owner_id,omitempty"`
+	Version       int64                  `json:"version"`
+	LatestVersion int64                  `json:"latest_version"`
+	Parameters    []templates.Param      `json:"parameters"`
+	Body          map[string]interface{} `json:"body"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// VersionResponse summarizes a template version
+type VersionResponse struct {
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListParams represents parameters for listing templates
+type ListParams struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// Validate checks if the list parameters are valid
+func (p *ListParams) Validate() error {
+	if p.Page < 1 {
+		return errors.New("page must be greater than 0")
+	}
+	if p.PageSize < 1 || p.PageSize > 100 {
+		return errors.New("page size must be between 1 and 100")
+	}
+	return nil
+}
+
+// ListResponse represents a paginated list of templates, by name. Only
+// the latest version of each template is included.
+type ListResponse struct {
+	Templates  []TemplateResponse `json:"templates"`
+	TotalCount int64              `json:"total_count"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+}
+
+// InstantiateRequest represents the request to create a job from a
+// template
+type InstantiateRequest struct {
+	// Version selects the template version; the latest when zero
+	Version int64                  `json:"version,omitempty"`
+	Params  map[string]interface{} `json:"params"`
+	// Run starts a run of the created job
+	Run bool `json:"run,omitempty"`
+}
+
+// RunSummary identifies a run started by an instantiation
+type RunSummary struct {
+	ID        string `json:"id"`
+	RunNumber int64  `json:"run_number"`
+	Status    string `json:"status"`
+}
+
+// InstantiateResponse represents the job created from a template and the
+// run started for it
+type InstantiateResponse struct {
+	Job *jobs.JobResponse `json:"job"`
+	Run *RunSummary       `json:"run,omitempty"`
+}
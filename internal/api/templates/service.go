@@ -0,0 +1,426 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=templates github.com/klauern/gopher-tower/internal/api/templates TemplateQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=templates github.com/klauern/gopher-tower/internal/api/templates Service
+//go:generate go tool mockgen -destination=mock_job_creator_test.go -package=templates github.com/klauern/gopher-tower/internal/api/templates JobCreator
+//go:generate go tool mockgen -destination=mock_starter_test.go -package=templates github.com/klauern/gopher-tower/internal/api/templates Starter
+
+package templates
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/templates"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrVersionNotFound  = errors.New("template version not found")
+	ErrInvalidTemplate  = templates.ErrInvalidTemplate
+	ErrInvalidParams    = templates.ErrInvalidParams
+	ErrInvalidJob       = errors.New("rendered job is invalid")
+	ErrNameTaken        = errors.New("template name already taken")
+	ErrConflict         = errors.New("template was updated concurrently")
+	ErrInvalidRun       = errors.New("job cannot be run")
+	ErrUnavailable      = errors.New("executor unavailable")
+)
+
+// TemplateQuerier defines the interface for template database operations
+type TemplateQuerier interface {
+	CreateJobTemplate(ctx context.Context, arg db.CreateJobTemplateParams) (db.JobTemplate, error)
+	GetJobTemplate(ctx context.Context, id string) (db.JobTemplate, error)
+	ListJobTemplates(ctx context.Context, arg db.ListJobTemplatesParams) ([]db.JobTemplate, error)
+	CountJobTemplates(ctx context.Context) (int64, error)
+	UpdateJobTemplate(ctx context.Context, arg db.UpdateJobTemplateParams) (db.JobTemplate, error)
+	CreateJobTemplateVersion(ctx context.Context, arg db.CreateJobTemplateVersionParams) (db.JobTemplateVersion, error)
+	GetJobTemplateVersion(ctx context.Context, arg db.GetJobTemplateVersionParams) (db.JobTemplateVersion, error)
+	ListJobTemplateVersions(ctx context.Context, templateID string) ([]db.JobTemplateVersion, error)
+}
+
+// JobCreator creates jobs rendered from templates; it is implemented by
+// jobs.Service
+type JobCreator interface {
+	CreateTemplatedJob(ctx context.Context, req jobs.JobRequest, ownerID string, origin jobs.TemplateOrigin) (*jobs.JobResponse, error)
+}
+
+// Starter starts job runs; it is implemented by executor.Executor
+type Starter interface {
+	Start(ctx context.Context, jobID string) (db.JobRun, error)
+}
+
+// Transactor runs fn with queries bound to one database transaction,
+// committing when fn returns nil and rolling back otherwise
+type Transactor interface {
+	InTx(ctx context.Context, fn func(TemplateQuerier) error) error
+}
+
+// dbTransactor runs transactions on a database connection
+type dbTransactor struct {
+	conn *sql.DB
+}
+
+// NewTransactor returns a Transactor starting transactions on conn
+func NewTransactor(conn *sql.DB) Transactor {
+	return dbTransactor{conn: conn}
+}
+
+// InTx runs fn in a transaction on the connection
+func (t dbTransactor) InTx(ctx context.Context, fn func(TemplateQuerier) error) error {
+	return db.InTx(ctx, t.conn, func(q *db.Queries) error { return fn(q) })
+}
+
+// Service provides job template operations
+type Service interface {
+	CreateTemplate(ctx context.Context, req TemplateRequest, ownerID string) (*TemplateResponse, error)
+	// GetTemplate returns a version of a template; the latest when version
+	// is zero
+	GetTemplate(ctx context.Context, id string, version int64) (*TemplateResponse, error)
+	ListTemplates(ctx context.Context, params ListParams) (*ListResponse, error)
+	// UpdateTemplate creates a new version of a template
+	UpdateTemplate(ctx context.Context, id string, req TemplateRequest) (*TemplateResponse, error)
+	ListVersions(ctx context.Context, id string) ([]VersionResponse, error)
+	Instantiate(ctx context.Context, id string, req InstantiateRequest, ownerID string) (*InstantiateResponse, error)
+}
+
+// templateService implements the Service interface
+type templateService struct {
+	queries TemplateQuerier
+	tx      Transactor
+	jobs    JobCreator
+	starter Starter
+}
+
+// NewService creates a new template service. Writes spanning a template and
+// its versions run in transactions started by tx.
+func NewService(queries TemplateQuerier, tx Transactor, jobs JobCreator, starter Starter) Service {
+	return &templateService{queries: queries, tx: tx, jobs: jobs, starter: starter}
+}
+
+// CreateTemplate creates the first version of a template
+func (s *templateService) CreateTemplate(ctx context.Context, req TemplateRequest, ownerID string) (*TemplateResponse, error) {
+	params, body, err := encodeVersion(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The template and its first version are stored together so a failed
+	// version insert leaves no template without versions behind
+	var (
+		tmpl    db.JobTemplate
+		version db.JobTemplateVersion
+	)
+	err = s.tx.InTx(ctx, func(q TemplateQuerier) error {
+		var err error
+		tmpl, err = q.CreateJobTemplate(ctx, db.CreateJobTemplateParams{
+			ID:          uuid.New().String(),
+			Name:        req.Name,
+			Description: db.StringToNullString(req.Description),
+			OwnerID:     db.StringToNullString(ownerID),
+		})
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				return ErrNameTaken
+			}
+			return err
+		}
+		version, err = q.CreateJobTemplateVersion(ctx, db.CreateJobTemplateVersionParams{
+			TemplateID: tmpl.ID,
+			Version:    tmpl.LatestVersion,
+			Parameters: params,
+			Body:       body,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toTemplateResponse(tmpl, version)
+}
+
+// GetTemplate retrieves a version of a template
+func (s *templateService) GetTemplate(ctx context.Context, id string, version int64) (*TemplateResponse, error) {
+	tmpl, v, err := s.getVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return toTemplateResponse(tmpl, v)
+}
+
+// ListTemplates returns a paginated list of templates in their latest
+// version
+func (s *templateService) ListTemplates(ctx context.Context, params ListParams) (*ListResponse, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	tmpls, err := s.queries.ListJobTemplates(ctx, db.ListJobTemplatesParams{
+		Limit:  int64(params.PageSize),
+		Offset: int64((params.Page - 1) * params.PageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.queries.CountJobTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]TemplateResponse, 0, len(tmpls))
+	for _, tmpl := range tmpls {
+		version, err := s.queries.GetJobTemplateVersion(ctx, db.GetJobTemplateVersionParams{
+			TemplateID: tmpl.ID,
+			Version:    tmpl.LatestVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp, err := toTemplateResponse(tmpl, version)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+	return &ListResponse{Templates: responses, TotalCount: total, Page: params.Page, PageSize: params.PageSize}, nil
+}
+
+// UpdateTemplate stores the request as the template's next version
+func (s *templateService) UpdateTemplate(ctx context.Context, id string, req TemplateRequest) (*TemplateResponse, error) {
+	params, body, err := encodeVersion(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getTemplate(ctx, id); err != nil {
+		return nil, err
+	}
+	versions, err := s.queries.ListJobTemplateVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	next := int64(1)
+	if len(versions) > 0 {
+		next = versions[0].Version + 1
+	}
+
+	// The version is stored first; a concurrent update claiming the same
+	// version number fails on the primary key
+	var (
+		tmpl    db.JobTemplate
+		version db.JobTemplateVersion
+	)
+	err = s.tx.InTx(ctx, func(q TemplateQuerier) error {
+		var err error
+		version, err = q.CreateJobTemplateVersion(ctx, db.CreateJobTemplateVersionParams{
+			TemplateID: id,
+			Version:    next,
+			Parameters: params,
+			Body:       body,
+		})
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				return ErrConflict
+			}
+			return err
+		}
+		tmpl, err = q.UpdateJobTemplate(ctx, db.UpdateJobTemplateParams{
+			Name:          req.Name,
+			Description:   db.StringToNullString(req.Description),
+			LatestVersion: version.Version,
+			ID:            id,
+		})
+		if db.IsUniqueViolation(err) {
+			return ErrNameTaken
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toTemplateResponse(tmpl, version)
+}
+
+// ListVersions lists the versions of a template, newest first
+func (s *templateService) ListVersions(ctx context.Context, id string) ([]VersionResponse, error) {
+	if _, err := s.getTemplate(ctx, id); err != nil {
+		return nil, err
+	}
+	versions, err := s.queries.ListJobTemplateVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]VersionResponse, len(versions))
+	for i, v := range versions {
+		responses[i] = VersionResponse{Version: v.Version, CreatedAt: v.CreatedAt}
+	}
+	return responses, nil
+}
+
+// Instantiate renders a template version with the given parameters and
+// creates a job from it, starting a run when asked to
+func (s *templateService) Instantiate(ctx context.Context, id string, req InstantiateRequest, ownerID string) (*InstantiateResponse, error) {
+	tmpl, version, err := s.getVersion(ctx, id, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	params, body, err := decodeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := templates.Resolve(params, req.Params)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := templates.Render(body, values)
+	if err != nil {
+		return nil, err
+	}
+	jobReq, err := toJobRequest(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJob, err)
+	}
+	if jobReq.Name == "" {
+		jobReq.Name = tmpl.Name
+	}
+	if jobReq.Status == "" {
+		jobReq.Status = jobs.JobStatusPending
+	}
+
+	job, err := s.jobs.CreateTemplatedJob(ctx, jobReq, ownerID, jobs.TemplateOrigin{
+		ID:      tmpl.ID,
+		Version: version.Version,
+		Params:  values,
+	})
+	if err != nil {
+		if errors.Is(err, jobs.ErrInvalidJob) {
+			return nil, ErrInvalidJob
+		}
+		return nil, err
+	}
+	resp := &InstantiateResponse{Job: job}
+	if !req.Run {
+		return resp, nil
+	}
+
+	run, err := s.starter.Start(ctx, job.ID)
+	if err != nil {
+		switch {
+		case executor.IsInvalidRun(err):
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRun, err)
+		case errors.Is(err, executor.ErrShuttingDown):
+			return nil, ErrUnavailable
+		}
+		return nil, err
+	}
+	resp.Run = &RunSummary{ID: run.ID, RunNumber: run.RunNumber, Status: run.Status}
+	return resp, nil
+}
+
+func (s *templateService) getTemplate(ctx context.Context, id string) (db.JobTemplate, error) {
+	tmpl, err := s.queries.GetJobTemplate(ctx, id)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return db.JobTemplate{}, ErrTemplateNotFound
+		}
+		return db.JobTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// getVersion looks up a template and one of its versions; the latest when
+// version is zero
+func (s *templateService) getVersion(ctx context.Context, id string, version int64) (db.JobTemplate, db.JobTemplateVersion, error) {
+	tmpl, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return db.JobTemplate{}, db.JobTemplateVersion{}, err
+	}
+	if version == 0 {
+		version = tmpl.LatestVersion
+	}
+	v, err := s.queries.GetJobTemplateVersion(ctx, db.GetJobTemplateVersionParams{TemplateID: id, Version: version})
+	if err != nil {
+		if db.IsNotFound(err) {
+			return db.JobTemplate{}, db.JobTemplateVersion{}, ErrVersionNotFound
+		}
+		return db.JobTemplate{}, db.JobTemplateVersion{}, err
+	}
+	return tmpl, v, nil
+}
+
+// encodeVersion validates a template request and serializes its parameters
+// and body for storage
+func encodeVersion(req TemplateRequest) (params, body string, err error) {
+	if err := req.Validate(); err != nil {
+		if !errors.Is(err, ErrInvalidTemplate) {
+			err = fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		return "", "", err
+	}
+	p := req.Parameters
+	if p == nil {
+		p = []templates.Param{}
+	}
+	paramData, err := json.Marshal(p)
+	if err != nil {
+		return "", "", err
+	}
+	bodyData, err := json.Marshal(req.Body)
+	if err != nil {
+		return "", "", err
+	}
+	return string(paramData), string(bodyData), nil
+}
+
+// decodeVersion parses the stored parameters and body of a template version
+func decodeVersion(v db.JobTemplateVersion) ([]templates.Param, map[string]interface{}, error) {
+	var params []templates.Param
+	if err := json.Unmarshal([]byte(v.Parameters), &params); err != nil {
+		return nil, nil, fmt.Errorf("decoding parameters of version %d: %w", v.Version, err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(v.Body), &body); err != nil {
+		return nil, nil, fmt.Errorf("decoding body of version %d: %w", v.Version, err)
+	}
+	return params, body, nil
+}
+
+// toJobRequest converts a rendered body to a job request, rejecting
+// unknown fields
+func toJobRequest(rendered interface{}) (jobs.JobRequest, error) {
+	var req jobs.JobRequest
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return req, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&req)
+	return req, err
+}
+
+// toTemplateResponse converts a template and one of its versions to a
+// TemplateResponse
+func toTemplateResponse(tmpl db.JobTemplate, version db.JobTemplateVersion) (*TemplateResponse, error) {
+	params, body, err := decodeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateResponse{
+		ID:            tmpl.ID,
+		Name:          tmpl.Name,
+		Description:   tmpl.Description.String,
+		OwnerID:       tmpl.OwnerID.String,
+		Version:       version.Version,
+		LatestVersion: tmpl.LatestVersion,
+		Parameters:    params,
+		Body:          body,
+		CreatedAt:     tmpl.CreatedAt,
+		UpdatedAt:     tmpl.UpdatedAt,
+	}, nil
+}
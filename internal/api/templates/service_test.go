@@ -0,0 +1,339 @@
+package templates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/api/jobs"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testTemplateID = "3b1f6a2e-8c4d-4e5f-9a0b-1c2d3e4f5a6b"
+	testJobID      = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+)
+
+type serviceTest struct {
+	querier *MockTemplateQuerier
+	tx      *inlineTx
+	jobs    *MockJobCreator
+	starter *MockStarter
+	svc     Service
+}
+
+// inlineTx runs transactions directly against the mocked querier and
+// records whether the last one was rolled back
+type inlineTx struct {
+	querier    TemplateQuerier
+	rolledBack bool
+}
+
+func (tx *inlineTx) InTx(_ context.Context, fn func(TemplateQuerier) error) error {
+	err := fn(tx.querier)
+	tx.rolledBack = err != nil
+	return err
+}
+
+func setupService(t *testing.T) *serviceTest {
+	ctrl := gomock.NewController(t)
+	st := &serviceTest{
+		querier: NewMockTemplateQuerier(ctrl),
+		jobs:    NewMockJobCreator(ctrl),
+		starter: NewMockStarter(ctrl),
+	}
+	st.tx = &inlineTx{querier: st.querier}
+	st.svc = NewService(st.querier, st.tx, st.jobs, st.starter)
+	return st
+}
+
+// exportRequest is a template with an enum, an int and a required string
+func exportRequest() TemplateRequest {
+	return TemplateRequest{
+		Name: "export",
+		Parameters: []templates.Param{
+			{Name: "env", Type: templates.TypeEnum, Values: []string{"staging", "prod"}, Default: "staging"},
+			{Name: "shards", Type: templates.TypeInt, Default: float64(4)},
+			{Name: "table", Type: templates.TypeString},
+		},
+		Body: map[string]interface{}{
+			"name":   "export-${{ params.env }}",
+			"plugin": "script",
+			"config": map[string]interface{}{
+				"interpreter": "sh",
+				"body":        "./export.sh ${{ params.table }}",
+				"args":        []interface{}{"--shards", "${{ params.shards }}"},
+				"env":         map[string]interface{}{"ENV": "${{ params.env }}"},
+			},
+		},
+	}
+}
+
+const (
+	exportParams = `[{"name":"env","type":"enum","default":"staging","values":["staging","prod"]},{"name":"shards","type":"int","default":4},{"name":"table","type":"string"}]`
+	exportBody   = `{"config":{"args":["--shards","${{ params.shards }}"],"body":"./export.sh ${{ params.table }}","env":{"ENV":"${{ params.env }}"},"interpreter":"sh"},"name":"export-${{ params.env }}","plugin":"script"}`
+)
+
+func exportTemplate(latest int64) db.JobTemplate {
+	return db.JobTemplate{ID: testTemplateID, Name: "export", LatestVersion: latest, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+}
+
+func exportVersion(version int64) db.JobTemplateVersion {
+	return db.JobTemplateVersion{TemplateID: testTemplateID, Version: version, Parameters: exportParams, Body: exportBody}
+}
+
+func TestTemplateService_CreateTemplate(t *testing.T) {
+	t.Run("created", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().
+			CreateJobTemplate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateJobTemplateParams) (db.JobTemplate, error) {
+				assert.Equal(t, "export", arg.Name)
+				assert.Equal(t, "owner-1", arg.OwnerID.String)
+				return db.JobTemplate{ID: arg.ID, Name: arg.Name, OwnerID: arg.OwnerID, LatestVersion: 1}, nil
+			})
+		st.querier.EXPECT().
+			CreateJobTemplateVersion(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateJobTemplateVersionParams) (db.JobTemplateVersion, error) {
+				assert.Equal(t, int64(1), arg.Version)
+				assert.JSONEq(t, exportParams, arg.Parameters)
+				assert.JSONEq(t, exportBody, arg.Body)
+				return db.JobTemplateVersion{TemplateID: arg.TemplateID, Version: arg.Version, Parameters: arg.Parameters, Body: arg.Body}, nil
+			})
+
+		resp, err := st.svc.CreateTemplate(context.Background(), exportRequest(), "owner-1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), resp.Version)
+		assert.Len(t, resp.Parameters, 3)
+		assert.Equal(t, "script", resp.Body["plugin"])
+	})
+
+	t.Run("name taken", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().
+			CreateJobTemplate(gomock.Any(), gomock.Any()).
+			Return(db.JobTemplate{}, errors.New("constraint failed: UNIQUE constraint failed: job_templates.name (2067)"))
+
+		_, err := st.svc.CreateTemplate(context.Background(), exportRequest(), "owner-1")
+		assert.ErrorIs(t, err, ErrNameTaken)
+	})
+
+	t.Run("version insert fails", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().
+			CreateJobTemplate(gomock.Any(), gomock.Any()).
+			Return(exportTemplate(1), nil)
+		st.querier.EXPECT().
+			CreateJobTemplateVersion(gomock.Any(), gomock.Any()).
+			Return(db.JobTemplateVersion{}, errors.New("disk I/O error"))
+
+		_, err := st.svc.CreateTemplate(context.Background(), exportRequest(), "owner-1")
+		assert.Error(t, err)
+		assert.True(t, st.tx.rolledBack, "the template insert must be rolled back")
+	})
+
+	invalid := []struct {
+		name   string
+		modify func(*TemplateRequest)
+	}{
+		{name: "missing name", modify: func(r *TemplateRequest) { r.Name = "" }},
+		{name: "missing body", modify: func(r *TemplateRequest) { r.Body = nil }},
+		{name: "invalid parameter", modify: func(r *TemplateRequest) { r.Parameters[1].Type = "float" }},
+		{name: "undeclared placeholder", modify: func(r *TemplateRequest) { r.Body["description"] = "${{ params.owner }}" }},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			st := setupService(t)
+			req := exportRequest()
+			tt.modify(&req)
+			_, err := st.svc.CreateTemplate(context.Background(), req, "owner-1")
+			assert.ErrorIs(t, err, ErrInvalidTemplate)
+		})
+	}
+}
+
+func TestTemplateService_UpdateTemplate(t *testing.T) {
+	t.Run("new version", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJobTemplate(gomock.Any(), testTemplateID).Return(exportTemplate(2), nil)
+		st.querier.EXPECT().ListJobTemplateVersions(gomock.Any(), testTemplateID).
+			Return([]db.JobTemplateVersion{exportVersion(2), exportVersion(1)}, nil)
+		st.querier.EXPECT().
+			CreateJobTemplateVersion(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateJobTemplateVersionParams) (db.JobTemplateVersion, error) {
+				assert.Equal(t, int64(3), arg.Version)
+				return db.JobTemplateVersion{TemplateID: arg.TemplateID, Version: arg.Version, Parameters: arg.Parameters, Body: arg.Body}, nil
+			})
+		st.querier.EXPECT().
+			UpdateJobTemplate(gomock.Any(), db.UpdateJobTemplateParams{Name: "export", LatestVersion: 3, ID: testTemplateID}).
+			Return(exportTemplate(3), nil)
+
+		resp, err := st.svc.UpdateTemplate(context.Background(), testTemplateID, exportRequest())
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), resp.Version)
+		assert.Equal(t, int64(3), resp.LatestVersion)
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJobTemplate(gomock.Any(), testTemplateID).Return(exportTemplate(1), nil)
+		st.querier.EXPECT().ListJobTemplateVersions(gomock.Any(), testTemplateID).Return([]db.JobTemplateVersion{exportVersion(1)}, nil)
+		st.querier.EXPECT().
+			CreateJobTemplateVersion(gomock.Any(), gomock.Any()).
+			Return(db.JobTemplateVersion{}, errors.New("UNIQUE constraint failed: job_template_versions.template_id, job_template_versions.version"))
+
+		_, err := st.svc.UpdateTemplate(context.Background(), testTemplateID, exportRequest())
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJobTemplate(gomock.Any(), testTemplateID).Return(db.JobTemplate{}, sql.ErrNoRows)
+
+		_, err := st.svc.UpdateTemplate(context.Background(), testTemplateID, exportRequest())
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	})
+}
+
+func TestTemplateService_GetTemplate(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().GetJobTemplate(gomock.Any(), testTemplateID).Return(exportTemplate(2), nil).Times(2)
+	st.querier.EXPECT().
+		GetJobTemplateVersion(gomock.Any(), db.GetJobTemplateVersionParams{TemplateID: testTemplateID, Version: 2}).
+		Return(exportVersion(2), nil)
+	st.querier.EXPECT().
+		GetJobTemplateVersion(gomock.Any(), db.GetJobTemplateVersionParams{TemplateID: testTemplateID, Version: 5}).
+		Return(db.JobTemplateVersion{}, sql.ErrNoRows)
+
+	resp, err := st.svc.GetTemplate(context.Background(), testTemplateID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Version)
+
+	_, err = st.svc.GetTemplate(context.Background(), testTemplateID, 5)
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestTemplateService_ListTemplates(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().
+		ListJobTemplates(gomock.Any(), db.ListJobTemplatesParams{Limit: 10, Offset: 0}).
+		Return([]db.JobTemplate{exportTemplate(2)}, nil)
+	st.querier.EXPECT().CountJobTemplates(gomock.Any()).Return(int64(1), nil)
+	st.querier.EXPECT().
+		GetJobTemplateVersion(gomock.Any(), db.GetJobTemplateVersionParams{TemplateID: testTemplateID, Version: 2}).
+		Return(exportVersion(2), nil)
+
+	resp, err := st.svc.ListTemplates(context.Background(), ListParams{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, resp.Templates, 1)
+	assert.Equal(t, int64(1), resp.TotalCount)
+	assert.Equal(t, int64(2), resp.Templates[0].Version)
+}
+
+func TestTemplateService_ListVersions(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().GetJobTemplate(gomock.Any(), testTemplateID).Return(exportTemplate(2), nil)
+	st.querier.EXPECT().ListJobTemplateVersions(gomock.Any(), testTemplateID).
+		Return([]db.JobTemplateVersion{exportVersion(2), exportVersion(1)}, nil)
+
+	versions, err := st.svc.ListVersions(context.Background(), testTemplateID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, int64(2), versions[0].Version)
+}
+
+func TestTemplateService_Instantiate(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       InstantiateRequest
+		createErr error
+		startErr  error
+		wantErr   error
+	}{
+		{
+			name: "job created",
+			req:  InstantiateRequest{Params: map[string]interface{}{"env": "prod", "table": "orders"}},
+		},
+		{
+			name: "job created and run",
+			req:  InstantiateRequest{Params: map[string]interface{}{"env": "prod", "table": "orders"}, Run: true},
+		},
+		{
+			name:    "missing parameter",
+			req:     InstantiateRequest{Params: map[string]interface{}{"env": "prod"}},
+			wantErr: ErrInvalidParams,
+		},
+		{
+			name:    "invalid enum value",
+			req:     InstantiateRequest{Params: map[string]interface{}{"env": "dev", "table": "orders"}},
+			wantErr: ErrInvalidParams,
+		},
+		{
+			name:      "invalid rendered job",
+			req:       InstantiateRequest{Params: map[string]interface{}{"env": "prod", "table": "orders"}},
+			createErr: jobs.ErrInvalidJob,
+			wantErr:   ErrInvalidJob,
+		},
+		{
+			name:     "executor shutting down",
+			req:      InstantiateRequest{Params: map[string]interface{}{"env": "prod", "table": "orders"}, Run: true},
+			startErr: executor.ErrShuttingDown,
+			wantErr:  ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := setupService(t)
+			st.querier.EXPECT().GetJobTemplate(gomock.Any(), testTemplateID).Return(exportTemplate(2), nil)
+			st.querier.EXPECT().
+				GetJobTemplateVersion(gomock.Any(), db.GetJobTemplateVersionParams{TemplateID: testTemplateID, Version: 2}).
+				Return(exportVersion(2), nil)
+			if tt.wantErr != ErrInvalidParams {
+				st.jobs.EXPECT().
+					CreateTemplatedJob(gomock.Any(), gomock.Any(), "owner-1", gomock.Any()).
+					DoAndReturn(func(_ context.Context, req jobs.JobRequest, _ string, origin jobs.TemplateOrigin) (*jobs.JobResponse, error) {
+						assert.Equal(t, "export-prod", req.Name)
+						assert.Equal(t, jobs.JobStatusPending, req.Status)
+						assert.Equal(t, "./export.sh orders", req.Config["body"])
+						assert.Equal(t, []interface{}{"--shards", float64(4)}, req.Config["args"])
+						assert.Equal(t, map[string]interface{}{"ENV": "prod"}, req.Config["env"])
+						assert.Equal(t, jobs.TemplateOrigin{
+							ID:      testTemplateID,
+							Version: 2,
+							Params:  map[string]interface{}{"env": "prod", "shards": int64(4), "table": "orders"},
+						}, origin)
+						if tt.createErr != nil {
+							return nil, tt.createErr
+						}
+						return &jobs.JobResponse{ID: testJobID, Name: req.Name}, nil
+					})
+			}
+			if tt.req.Run {
+				st.starter.EXPECT().Start(gomock.Any(), testJobID).
+					Return(db.JobRun{ID: "run-1", RunNumber: 1, Status: executor.StatusPending}, tt.startErr)
+			}
+
+			resp, err := st.svc.Instantiate(context.Background(), testTemplateID, tt.req, "owner-1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testJobID, resp.Job.ID)
+			if tt.req.Run {
+				require.NotNil(t, resp.Run)
+				assert.Equal(t, int64(1), resp.Run.RunNumber)
+			} else {
+				assert.Nil(t, resp.Run)
+			}
+		})
+	}
+}
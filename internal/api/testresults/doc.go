@@ -0,0 +1,58 @@
+/*
+Package testresults exposes the test cases reported by job runs over HTTP.
+
+Jobs declaring test_reports have the JUnit XML reports of every run parsed
+into the test_results table (see internal/testreport). The results of a
+single run are returned along with a summary counting them by status.
+
+A test is flaky when, within the most recent runs of its job, it passed in
+some runs and failed or errored in others. The flaky endpoint looks at the
+last 20 runs by default, or at as many as ?runs= asks for, up to 200. Each
+flaky test lists its outcome in every run it was reported by and how often
+its status flipped between consecutive runs; skipped runs are not counted.
+Tests that flipped most often come first.
+
+API Endpoints:
+
+	GET /jobs/{id}/runs/{number}/tests - List the test cases of a run
+	GET /jobs/{id}/tests/flaky         - List the flaky tests of a job
+
+Example Response (run):
+
+	{
+		"run_id": "7c1e...",
+		"run_number": 12,
+		"summary": {"total": 2, "passed": 1, "failed": 1, "errors": 0, "skipped": 0, "duration": 1.5},
+		"results": [
+			{"suite": "unit", "name": "TestDiv", "duration": 1.2, "status": "failed", "message": "division by zero"},
+			{"suite": "unit", "name": "TestAdd", "duration": 0.3, "status": "passed"}
+		]
+	}
+
+Example Response (flaky):
+
+	{
+		"runs": 20,
+		"tests": [
+			{
+				"suite": "unit",
+				"name": "TestDiv",
+				"passed": 1,
+				"failed": 1,
+				"flips": 1,
+				"last_status": "failed",
+				"history": [
+					{"run_number": 11, "status": "passed", "duration": 1.1},
+					{"run_number": 12, "status": "failed", "duration": 1.2}
+				]
+			}
+		]
+	}
+
+Error Handling:
+
+  - 200: Results listed
+  - 400: Invalid ID, run number or window
+  - 404: Job or run not found
+*/
+package testresults
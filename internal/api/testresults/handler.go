@@ -0,0 +1,99 @@
+package testresults
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for test results
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new test result handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the test result routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/jobs/{id}/runs/{number}/tests", h.GetRunTests)
+	r.Get("/jobs/{id}/tests/flaky", h.ListFlakyTests)
+}
+
+// jobID extracts and validates the job ID path parameter
+func jobID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrRunNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetRunTests handles requests for the test cases of a run
+func (h *Handler) GetRunTests(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	number, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+	if err != nil || number <= 0 {
+		http.Error(w, "Invalid run number", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.GetRunTests(r.Context(), id, number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ListFlakyTests handles requests for the flaky tests of a job
+func (h *Handler) ListFlakyTests(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	var runs int
+	if s := r.URL.Query().Get("runs"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > MaxFlakyWindow {
+			http.Error(w, "runs must be between 1 and "+strconv.Itoa(MaxFlakyWindow), http.StatusBadRequest)
+			return
+		}
+		runs = n
+	}
+
+	resp, err := h.service.ListFlakyTests(r.Context(), id, runs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
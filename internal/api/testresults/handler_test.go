@@ -0,0 +1,62 @@
+package testresults
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestGetRunTests(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().GetRunTests(gomock.Any(), testJobID, int64(3)).
+		Return(&RunTestsResponse{RunID: testRunID, RunNumber: 3}, nil)
+	ms.EXPECT().GetRunTests(gomock.Any(), testJobID, int64(4)).Return(nil, ErrRunNotFound)
+
+	w := serve(router, "/jobs/"+testJobID+"/runs/3/tests")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp RunTestsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, testRunID, resp.RunID)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "/jobs/"+testJobID+"/runs/4/tests").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(router, "/jobs/"+testJobID+"/runs/0/tests").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(router, "/jobs/not-a-uuid/runs/3/tests").Code)
+}
+
+func TestListFlakyTests(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().ListFlakyTests(gomock.Any(), testJobID, 0).Return(&FlakyResponse{Runs: DefaultFlakyWindow}, nil)
+	ms.EXPECT().ListFlakyTests(gomock.Any(), testJobID, 50).Return(&FlakyResponse{Runs: 50}, nil)
+	ms.EXPECT().ListFlakyTests(gomock.Any(), testJobID, 5).Return(nil, ErrJobNotFound)
+
+	w := serve(router, "/jobs/"+testJobID+"/tests/flaky")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp FlakyResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, DefaultFlakyWindow, resp.Runs)
+
+	assert.Equal(t, http.StatusOK, serve(router, "/jobs/"+testJobID+"/tests/flaky?runs=50").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "/jobs/"+testJobID+"/tests/flaky?runs=5").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(router, "/jobs/"+testJobID+"/tests/flaky?runs=201").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(router, "/jobs/"+testJobID+"/tests/flaky?runs=x").Code)
+}
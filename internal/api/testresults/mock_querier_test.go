@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/testresults (interfaces: TestResultQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=testresults github.com/klauern/gopher-tower/internal/api/testresults TestResultQuerier
+//
+
+// Package testresults is a generated GoMock package.
+package testresults
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTestResultQuerier is a mock of TestResultQuerier interface.
+type MockTestResultQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockTestResultQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockTestResultQuerierMockRecorder is the mock recorder for MockTestResultQuerier.
+type MockTestResultQuerierMockRecorder struct {
+	mock *MockTestResultQuerier
+}
+
+// NewMockTestResultQuerier creates a new mock instance.
+func NewMockTestResultQuerier(ctrl *gomock.Controller) *MockTestResultQuerier {
+	mock := &MockTestResultQuerier{ctrl: ctrl}
+	mock.recorder = &MockTestResultQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTestResultQuerier) EXPECT() *MockTestResultQuerierMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockTestResultQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockTestResultQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockTestResultQuerier)(nil).GetJob), ctx, id)
+}
+
+// GetJobRunByNumber mocks base method.
+func (m *MockTestResultQuerier) GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobRunByNumber", ctx, arg)
+	ret0, _ := ret[0].(db.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobRunByNumber indicates an expected call of GetJobRunByNumber.
+func (mr *MockTestResultQuerierMockRecorder) GetJobRunByNumber(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobRunByNumber", reflect.TypeOf((*MockTestResultQuerier)(nil).GetJobRunByNumber), ctx, arg)
+}
+
+// GetNextRunNumber mocks base method.
+func (m *MockTestResultQuerier) GetNextRunNumber(ctx context.Context, jobID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextRunNumber", ctx, jobID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextRunNumber indicates an expected call of GetNextRunNumber.
+func (mr *MockTestResultQuerierMockRecorder) GetNextRunNumber(ctx, jobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextRunNumber", reflect.TypeOf((*MockTestResultQuerier)(nil).GetNextRunNumber), ctx, jobID)
+}
+
+// ListTestResultsByJob mocks base method.
+func (m *MockTestResultQuerier) ListTestResultsByJob(ctx context.Context, arg db.ListTestResultsByJobParams) ([]db.ListTestResultsByJobRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTestResultsByJob", ctx, arg)
+	ret0, _ := ret[0].([]db.ListTestResultsByJobRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTestResultsByJob indicates an expected call of ListTestResultsByJob.
+func (mr *MockTestResultQuerierMockRecorder) ListTestResultsByJob(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTestResultsByJob", reflect.TypeOf((*MockTestResultQuerier)(nil).ListTestResultsByJob), ctx, arg)
+}
+
+// ListTestResultsByRun mocks base method.
+func (m *MockTestResultQuerier) ListTestResultsByRun(ctx context.Context, runID string) ([]db.TestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTestResultsByRun", ctx, runID)
+	ret0, _ := ret[0].([]db.TestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTestResultsByRun indicates an expected call of ListTestResultsByRun.
+func (mr *MockTestResultQuerierMockRecorder) ListTestResultsByRun(ctx, runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTestResultsByRun", reflect.TypeOf((*MockTestResultQuerier)(nil).ListTestResultsByRun), ctx, runID)
+}
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/testresults (interfaces: Service)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_service_test.go -package=testresults github.com/klauern/gopher-tower/internal/api/testresults Service
+//
+
+// Package testresults is a generated GoMock package.
+package testresults
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetRunTests mocks base method.
+func (m *MockService) GetRunTests(ctx context.Context, jobID string, runNumber int64) (*RunTestsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunTests", ctx, jobID, runNumber)
+	ret0, _ := ret[0].(*RunTestsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunTests indicates an expected call of GetRunTests.
+func (mr *MockServiceMockRecorder) GetRunTests(ctx, jobID, runNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunTests", reflect.TypeOf((*MockService)(nil).GetRunTests), ctx, jobID, runNumber)
+}
+
+// ListFlakyTests mocks base method.
+func (m *MockService) ListFlakyTests(ctx context.Context, jobID string, runs int) (*FlakyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFlakyTests", ctx, jobID, runs)
+	ret0, _ := ret[0].(*FlakyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFlakyTests indicates an expected call of ListFlakyTests.
+func (mr *MockServiceMockRecorder) ListFlakyTests(ctx, jobID, runs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFlakyTests", reflect.TypeOf((*MockService)(nil).ListFlakyTests), ctx, jobID, runs)
+}
@@ -0,0 +1,49 @@
+package testresults
+
+import "github.com/klauern/gopher-tower/internal/testreport"
+
+// Bounds of the number of runs flaky tests are looked for in
+const (
+	DefaultFlakyWindow = 20
+	MaxFlakyWindow     = 200
+)
+
+// RunTestsResponse represents the test cases of a run
+type RunTestsResponse struct {
+	RunID     string              `json:"run_id"`
+	RunNumber int64               `json:"run_number"`
+	Summary   testreport.Summary  `json:"summary"`
+	Results   []testreport.Result `json:"results"`
+}
+
+// Outcome is the status of a test in one run
+type Outcome struct {
+	RunNumber int64   `json:"run_number"`
+	Status    string  `json:"status"`
+	Duration  float64 `json:"duration"`
+}
+
+// FlakyTest is a test that both passed and failed in recent runs
+type FlakyTest struct {
+	Suite     string `json:"suite"`
+	Name      string `json:"name"`
+	Classname string `json:"classname,omitempty"`
+	// Passed and Failed count the runs the test passed and failed or
+	// errored in
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// Flips counts the changes between passing and failing from one run
+	// of the test to the next
+	Flips      int    `json:"flips"`
+	LastStatus string `json:"last_status"`
+	// LastFailure is the message of the most recent failure
+	LastFailure string    `json:"last_failure,omitempty"`
+	History     []Outcome `json:"history"`
+}
+
+// FlakyResponse represents the flaky tests of a job
+type FlakyResponse struct {
+	// Runs is the number of most recent runs looked at
+	Runs  int         `json:"runs"`
+	Tests []FlakyTest `json:"tests"`
+}
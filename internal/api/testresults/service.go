@@ -0,0 +1,163 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=testresults github.com/klauern/gopher-tower/internal/api/testresults TestResultQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=testresults github.com/klauern/gopher-tower/internal/api/testresults Service
+
+package testresults
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/testreport"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrRunNotFound = errors.New("run not found")
+)
+
+// TestResultQuerier defines the interface for test result database
+// operations
+type TestResultQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	GetJobRunByNumber(ctx context.Context, arg db.GetJobRunByNumberParams) (db.JobRun, error)
+	GetNextRunNumber(ctx context.Context, jobID string) (int64, error)
+	ListTestResultsByRun(ctx context.Context, runID string) ([]db.TestResult, error)
+	ListTestResultsByJob(ctx context.Context, arg db.ListTestResultsByJobParams) ([]db.ListTestResultsByJobRow, error)
+}
+
+// Service provides test result operations
+type Service interface {
+	GetRunTests(ctx context.Context, jobID string, runNumber int64) (*RunTestsResponse, error)
+	ListFlakyTests(ctx context.Context, jobID string, runs int) (*FlakyResponse, error)
+}
+
+// testResultService implements the Service interface
+type testResultService struct {
+	queries TestResultQuerier
+}
+
+// NewService creates a new test result service
+func NewService(queries TestResultQuerier) Service {
+	return &testResultService{queries: queries}
+}
+
+// GetRunTests returns the test cases reported by a run of a job
+func (s *testResultService) GetRunTests(ctx context.Context, jobID string, runNumber int64) (*RunTestsResponse, error) {
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+	run, err := s.queries.GetJobRunByNumber(ctx, db.GetJobRunByNumberParams{JobID: jobID, RunNumber: runNumber})
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrRunNotFound
+		}
+		return nil, err
+	}
+	rows, err := s.queries.ListTestResultsByRun(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]testreport.Result, len(rows))
+	for i, row := range rows {
+		results[i] = testreport.Result{
+			Suite:     row.Suite,
+			Name:      row.Name,
+			Classname: row.Classname.String,
+			Duration:  row.Duration,
+			Status:    row.Status,
+			Message:   row.Message.String,
+		}
+	}
+	return &RunTestsResponse{
+		RunID:     run.ID,
+		RunNumber: run.RunNumber,
+		Summary:   testreport.Summarize(results),
+		Results:   results,
+	}, nil
+}
+
+// ListFlakyTests returns the tests of a job that both passed and failed in
+// its last runs runs, DefaultFlakyWindow when zero
+func (s *testResultService) ListFlakyTests(ctx context.Context, jobID string, runs int) (*FlakyResponse, error) {
+	if runs <= 0 {
+		runs = DefaultFlakyWindow
+	}
+	runs = min(runs, MaxFlakyWindow)
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+	next, err := s.queries.GetNextRunNumber(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListTestResultsByJob(ctx, db.ListTestResultsByJobParams{
+		JobID:     jobID,
+		RunNumber: next - int64(runs),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &FlakyResponse{Runs: runs, Tests: flakyTests(rows)}, nil
+}
+
+// flakyTests groups the results of a job's runs, ordered by run number,
+// by test and keeps the tests that both passed and failed
+func flakyTests(rows []db.ListTestResultsByJobRow) []FlakyTest {
+	type key struct{ suite, name string }
+	byTest := make(map[key]*FlakyTest)
+	var order []key
+	for _, row := range rows {
+		if row.Status == testreport.StatusSkipped {
+			continue
+		}
+		k := key{row.Suite, row.Name}
+		t, ok := byTest[k]
+		if !ok {
+			t = &FlakyTest{Suite: row.Suite, Name: row.Name, Classname: row.Classname.String}
+			byTest[k] = t
+			order = append(order, k)
+		}
+		failed := testreport.IsFailure(row.Status)
+		if n := len(t.History); n > 0 && failed != testreport.IsFailure(t.History[n-1].Status) {
+			t.Flips++
+		}
+		if failed {
+			t.Failed++
+			t.LastFailure = row.Message.String
+		} else {
+			t.Passed++
+		}
+		t.LastStatus = row.Status
+		t.History = append(t.History, Outcome{RunNumber: row.RunNumber, Status: row.Status, Duration: row.Duration})
+	}
+
+	tests := []FlakyTest{}
+	for _, k := range order {
+		if t := byTest[k]; t.Passed > 0 && t.Failed > 0 {
+			tests = append(tests, *t)
+		}
+	}
+	slices.SortFunc(tests, func(a, b FlakyTest) int {
+		return cmp.Or(
+			cmp.Compare(b.Flips, a.Flips),
+			cmp.Compare(b.Failed, a.Failed),
+			cmp.Compare(a.Suite, b.Suite),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return tests
+}
+
+// checkJob reports ErrJobNotFound for unknown jobs
+func (s *testResultService) checkJob(ctx context.Context, id string) error {
+	if _, err := s.queries.GetJob(ctx, id); err != nil {
+		if db.IsNotFound(err) {
+			return ErrJobNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package testresults
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/testreport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testJobID = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+	testRunID = "7c1e2d3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+)
+
+func setupService(t *testing.T) (*MockTestResultQuerier, Service) {
+	ctrl := gomock.NewController(t)
+	querier := NewMockTestResultQuerier(ctrl)
+	return querier, NewService(querier)
+}
+
+func TestTestResultService_GetRunTests(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("summarizes the results", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		querier.EXPECT().
+			GetJobRunByNumber(gomock.Any(), db.GetJobRunByNumberParams{JobID: testJobID, RunNumber: 3}).
+			Return(db.JobRun{ID: testRunID, JobID: testJobID, RunNumber: 3}, nil)
+		querier.EXPECT().ListTestResultsByRun(gomock.Any(), testRunID).Return([]db.TestResult{
+			{Suite: "unit", Name: "TestDiv", Duration: 1, Status: testreport.StatusFailed, Message: sql.NullString{String: "boom", Valid: true}},
+			{Suite: "unit", Name: "TestAdd", Duration: 0.5, Status: testreport.StatusPassed},
+		}, nil)
+
+		resp, err := svc.GetRunTests(ctx, testJobID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), resp.RunNumber)
+		assert.Equal(t, testreport.Summary{Total: 2, Passed: 1, Failed: 1, Duration: 1.5}, resp.Summary)
+		assert.Equal(t, "boom", resp.Results[0].Message)
+	})
+
+	t.Run("unknown run", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		querier.EXPECT().GetJobRunByNumber(gomock.Any(), gomock.Any()).Return(db.JobRun{}, sql.ErrNoRows)
+
+		_, err := svc.GetRunTests(ctx, testJobID, 3)
+		assert.ErrorIs(t, err, ErrRunNotFound)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{}, sql.ErrNoRows)
+
+		_, err := svc.GetRunTests(ctx, testJobID, 3)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
+
+func TestTestResultService_ListFlakyTests(t *testing.T) {
+	ctx := context.Background()
+	row := func(run int64, name, status string) db.ListTestResultsByJobRow {
+		return db.ListTestResultsByJobRow{RunNumber: run, Suite: "unit", Name: name, Status: status}
+	}
+
+	t.Run("keeps tests that passed and failed", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		querier.EXPECT().GetNextRunNumber(gomock.Any(), testJobID).Return(int64(31), nil)
+		querier.EXPECT().
+			ListTestResultsByJob(gomock.Any(), db.ListTestResultsByJobParams{JobID: testJobID, RunNumber: 11}).
+			Return([]db.ListTestResultsByJobRow{
+				row(28, "TestStable", testreport.StatusPassed),
+				row(28, "TestOnce", testreport.StatusPassed),
+				row(28, "TestFlaky", testreport.StatusFailed),
+				row(29, "TestStable", testreport.StatusPassed),
+				row(29, "TestOnce", testreport.StatusError),
+				row(29, "TestFlaky", testreport.StatusPassed),
+				row(30, "TestStable", testreport.StatusPassed),
+				row(30, "TestOnce", testreport.StatusError),
+				row(30, "TestFlaky", testreport.StatusSkipped),
+				row(30, "TestFlaky", testreport.StatusFailed),
+			}, nil)
+
+		resp, err := svc.ListFlakyTests(ctx, testJobID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultFlakyWindow, resp.Runs)
+		require.Len(t, resp.Tests, 2)
+
+		flaky := resp.Tests[0]
+		assert.Equal(t, "TestFlaky", flaky.Name)
+		assert.Equal(t, 1, flaky.Passed)
+		assert.Equal(t, 2, flaky.Failed)
+		assert.Equal(t, 2, flaky.Flips)
+		assert.Equal(t, testreport.StatusFailed, flaky.LastStatus)
+		assert.Equal(t, []Outcome{
+			{RunNumber: 28, Status: testreport.StatusFailed},
+			{RunNumber: 29, Status: testreport.StatusPassed},
+			{RunNumber: 30, Status: testreport.StatusFailed},
+		}, flaky.History)
+
+		once := resp.Tests[1]
+		assert.Equal(t, "TestOnce", once.Name)
+		assert.Equal(t, 1, once.Flips)
+	})
+
+	t.Run("caps the window", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		querier.EXPECT().GetNextRunNumber(gomock.Any(), testJobID).Return(int64(1), nil)
+		querier.EXPECT().
+			ListTestResultsByJob(gomock.Any(), db.ListTestResultsByJobParams{JobID: testJobID, RunNumber: 1 - MaxFlakyWindow}).
+			Return(nil, nil)
+
+		resp, err := svc.ListFlakyTests(ctx, testJobID, MaxFlakyWindow+1)
+		require.NoError(t, err)
+		assert.Equal(t, MaxFlakyWindow, resp.Runs)
+		assert.Empty(t, resp.Tests)
+	})
+}
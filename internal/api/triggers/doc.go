@@ -0,0 +1,52 @@
+/*
+Package triggers exposes the completion triggers of jobs over HTTP.
+
+A completion trigger starts a run of a target job whenever a run of the
+source job finishes and leaves the source job in the given status:
+"complete", "failed" or "any". The triggered run receives the outputs of the
+finished run, the metadata its plugin reported, as inputs; only the outputs
+the target job's input schema declares are passed on, and jobs without an
+input schema receive all of them. The run records the run that triggered it
+as triggered_by_run_id.
+
+Triggers are evaluated by the executor as it records the outcome of a run,
+which is when a job's status changes to complete or failed. A matrix run
+fires the triggers of its job once all of its children finished. Changing a
+job's status to complete or failed through PUT /jobs/{id} fires its
+triggers as well; as no run finished, the triggered runs receive no inputs
+and record no triggered_by_run_id.
+
+Chains of triggers cannot loop: a trigger is skipped when its target job
+already ran earlier in the chain of runs that led to the finished run, or
+when the chain reached executor.MaxTriggerChain runs.
+
+API Endpoints:
+
+	GET    /jobs/{id}/triggers             - List the triggers of a source job
+	POST   /jobs/{id}/triggers             - Add a trigger to a source job
+	DELETE /jobs/{id}/triggers/{triggerID} - Remove a trigger
+
+Example Request:
+
+	{"target_job_id": "9a3e...", "on_status": "complete"}
+
+Example Response:
+
+	{
+		"id": "c41d...",
+		"source_job_id": "5f0c...",
+		"target_job_id": "9a3e...",
+		"on_status": "complete",
+		"created_at": "2025-06-01T12:00:00Z"
+	}
+
+Error Handling:
+
+  - 200: Triggers listed
+  - 201: Trigger added
+  - 204: Trigger removed
+  - 400: Invalid ID, status or target job
+  - 404: Job or trigger not found
+  - 409: The job already has the same trigger
+*/
+package triggers
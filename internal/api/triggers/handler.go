@@ -0,0 +1,112 @@
+package triggers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for completion triggers
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new trigger handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the trigger routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/jobs/{id}/triggers", h.ListTriggers)
+	r.Post("/jobs/{id}/triggers", h.CreateTrigger)
+	r.Delete("/jobs/{id}/triggers/{triggerID}", h.DeleteTrigger)
+}
+
+// pathID extracts and validates a UUID path parameter
+func pathID(w http.ResponseWriter, r *http.Request, param, what string) (string, bool) {
+	id := chi.URLParam(r, param)
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid "+what+" ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrTriggerNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidTrigger):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrDuplicateTrigger):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ListTriggers handles requests to list the triggers of a job
+func (h *Handler) ListTriggers(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := pathID(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.ListTriggers(r.Context(), jobID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// CreateTrigger handles requests to add a trigger to a job
+func (h *Handler) CreateTrigger(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := pathID(w, r, "id", "job")
+	if !ok {
+		return
+	}
+	var req TriggerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.CreateTrigger(r.Context(), jobID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// DeleteTrigger handles requests to remove a trigger of a job
+func (h *Handler) DeleteTrigger(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := pathID(w, r, "id", "job")
+	if !ok {
+		return
+	}
+	triggerID, ok := pathID(w, r, "triggerID", "trigger")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTrigger(r.Context(), jobID, triggerID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package triggers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestListTriggers(t *testing.T) {
+	ms, router := setupHandler(t)
+	ms.EXPECT().ListTriggers(gomock.Any(), testJobID).
+		Return(&ListResponse{Triggers: []TriggerResponse{{ID: testTriggerID}}}, nil)
+
+	w := serve(router, http.MethodGet, "/jobs/"+testJobID+"/triggers", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp ListResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, testTriggerID, resp.Triggers[0].ID)
+
+	w = serve(router, http.MethodGet, "/jobs/not-a-uuid/triggers", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateTrigger(t *testing.T) {
+	req := TriggerRequest{TargetJobID: testTargetID, OnStatus: "failed"}
+	tests := []struct {
+		name       string
+		body       string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name: "created",
+			body: `{"target_job_id": "` + testTargetID + `", "on_status": "failed"}`,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateTrigger(gomock.Any(), testJobID, req).Return(&TriggerResponse{ID: testTriggerID}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid body",
+			body:       `{`,
+			setupMock:  func(*MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid trigger",
+			body: `{"target_job_id": "` + testTargetID + `", "on_status": "failed"}`,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateTrigger(gomock.Any(), testJobID, req).Return(nil, ErrInvalidTrigger)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "duplicate",
+			body: `{"target_job_id": "` + testTargetID + `", "on_status": "failed"}`,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateTrigger(gomock.Any(), testJobID, req).Return(nil, ErrDuplicateTrigger)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "unknown job",
+			body: `{"target_job_id": "` + testTargetID + `", "on_status": "failed"}`,
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().CreateTrigger(gomock.Any(), testJobID, req).Return(nil, ErrJobNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, router := setupHandler(t)
+			tt.setupMock(ms)
+			w := serve(router, http.MethodPost, "/jobs/"+testJobID+"/triggers", tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestDeleteTrigger(t *testing.T) {
+	ms, router := setupHandler(t)
+	gomock.InOrder(
+		ms.EXPECT().DeleteTrigger(gomock.Any(), testJobID, testTriggerID).Return(nil),
+		ms.EXPECT().DeleteTrigger(gomock.Any(), testJobID, testTriggerID).Return(ErrTriggerNotFound),
+	)
+
+	w := serve(router, http.MethodDelete, "/jobs/"+testJobID+"/triggers/"+testTriggerID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodDelete, "/jobs/"+testJobID+"/triggers/"+testTriggerID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serve(router, http.MethodDelete, "/jobs/"+testJobID+"/triggers/nope", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
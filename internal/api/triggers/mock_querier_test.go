@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/triggers (interfaces: TriggerQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=triggers github.com/klauern/gopher-tower/internal/api/triggers TriggerQuerier
+//
+
+// Package triggers is a generated GoMock package.
+package triggers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTriggerQuerier is a mock of TriggerQuerier interface.
+type MockTriggerQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockTriggerQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockTriggerQuerierMockRecorder is the mock recorder for MockTriggerQuerier.
+type MockTriggerQuerierMockRecorder struct {
+	mock *MockTriggerQuerier
+}
+
+// NewMockTriggerQuerier creates a new mock instance.
+func NewMockTriggerQuerier(ctrl *gomock.Controller) *MockTriggerQuerier {
+	mock := &MockTriggerQuerier{ctrl: ctrl}
+	mock.recorder = &MockTriggerQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTriggerQuerier) EXPECT() *MockTriggerQuerierMockRecorder {
+	return m.recorder
+}
+
+// CreateJobTrigger mocks base method.
+func (m *MockTriggerQuerier) CreateJobTrigger(ctx context.Context, arg db.CreateJobTriggerParams) (db.JobTrigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobTrigger", ctx, arg)
+	ret0, _ := ret[0].(db.JobTrigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJobTrigger indicates an expected call of CreateJobTrigger.
+func (mr *MockTriggerQuerierMockRecorder) CreateJobTrigger(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobTrigger", reflect.TypeOf((*MockTriggerQuerier)(nil).CreateJobTrigger), ctx, arg)
+}
+
+// DeleteJobTrigger mocks base method.
+func (m *MockTriggerQuerier) DeleteJobTrigger(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteJobTrigger", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteJobTrigger indicates an expected call of DeleteJobTrigger.
+func (mr *MockTriggerQuerierMockRecorder) DeleteJobTrigger(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJobTrigger", reflect.TypeOf((*MockTriggerQuerier)(nil).DeleteJobTrigger), ctx, id)
+}
+
+// GetJob mocks base method.
+func (m *MockTriggerQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockTriggerQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockTriggerQuerier)(nil).GetJob), ctx, id)
+}
+
+// GetJobTrigger mocks base method.
+func (m *MockTriggerQuerier) GetJobTrigger(ctx context.Context, id string) (db.JobTrigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobTrigger", ctx, id)
+	ret0, _ := ret[0].(db.JobTrigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobTrigger indicates an expected call of GetJobTrigger.
+func (mr *MockTriggerQuerierMockRecorder) GetJobTrigger(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobTrigger", reflect.TypeOf((*MockTriggerQuerier)(nil).GetJobTrigger), ctx, id)
+}
+
+// ListJobTriggersBySource mocks base method.
+func (m *MockTriggerQuerier) ListJobTriggersBySource(ctx context.Context, sourceJobID string) ([]db.JobTrigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobTriggersBySource", ctx, sourceJobID)
+	ret0, _ := ret[0].([]db.JobTrigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobTriggersBySource indicates an expected call of ListJobTriggersBySource.
+func (mr *MockTriggerQuerierMockRecorder) ListJobTriggersBySource(ctx, sourceJobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobTriggersBySource", reflect.TypeOf((*MockTriggerQuerier)(nil).ListJobTriggersBySource), ctx, sourceJobID)
+}
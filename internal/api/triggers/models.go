@@ -0,0 +1,42 @@
+package triggers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/executor"
+)
+
+// TriggerRequest represents the request to add a trigger to a job
+type TriggerRequest struct {
+	TargetJobID string `json:"target_job_id"`
+	// OnStatus is the job status the trigger fires on: complete, failed
+	// or any
+	OnStatus string `json:"on_status"`
+}
+
+// Validate checks if the trigger request is valid
+func (r *TriggerRequest) Validate() error {
+	if _, err := uuid.Parse(r.TargetJobID); err != nil {
+		return errors.New("target_job_id must be a job ID")
+	}
+	if !executor.ValidTriggerStatus(r.OnStatus) {
+		return errors.New("on_status must be complete, failed or any")
+	}
+	return nil
+}
+
+// TriggerResponse represents a trigger in responses
+type TriggerResponse struct {
+	ID          string    `json:"id"`
+	SourceJobID string    `json:"source_job_id"`
+	TargetJobID string    `json:"target_job_id"`
+	OnStatus    string    `json:"on_status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListResponse represents the triggers of a job
+type ListResponse struct {
+	Triggers []TriggerResponse `json:"triggers"`
+}
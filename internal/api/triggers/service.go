@@ -0,0 +1,134 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=triggers github.com/klauern/gopher-tower/internal/api/triggers TriggerQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=triggers github.com/klauern/gopher-tower/internal/api/triggers Service
+
+package triggers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+)
+
+var (
+	ErrJobNotFound      = errors.New("job not found")
+	ErrTriggerNotFound  = errors.New("trigger not found")
+	ErrInvalidTrigger   = errors.New("invalid trigger")
+	ErrDuplicateTrigger = errors.New("job already has this trigger")
+)
+
+// TriggerQuerier defines the interface for trigger database operations
+type TriggerQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	CreateJobTrigger(ctx context.Context, arg db.CreateJobTriggerParams) (db.JobTrigger, error)
+	GetJobTrigger(ctx context.Context, id string) (db.JobTrigger, error)
+	ListJobTriggersBySource(ctx context.Context, sourceJobID string) ([]db.JobTrigger, error)
+	DeleteJobTrigger(ctx context.Context, id string) error
+}
+
+// Service provides completion trigger operations
+type Service interface {
+	ListTriggers(ctx context.Context, jobID string) (*ListResponse, error)
+	CreateTrigger(ctx context.Context, jobID string, req TriggerRequest) (*TriggerResponse, error)
+	DeleteTrigger(ctx context.Context, jobID, triggerID string) error
+}
+
+// triggerService implements the Service interface
+type triggerService struct {
+	queries TriggerQuerier
+}
+
+// NewService creates a new trigger service
+func NewService(queries TriggerQuerier) Service {
+	return &triggerService{queries: queries}
+}
+
+// ListTriggers returns the triggers fired by runs of a job
+func (s *triggerService) ListTriggers(ctx context.Context, jobID string) (*ListResponse, error) {
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+	triggers, err := s.queries.ListJobTriggersBySource(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListResponse{Triggers: make([]TriggerResponse, len(triggers))}
+	for i, t := range triggers {
+		resp.Triggers[i] = toTriggerResponse(t)
+	}
+	return resp, nil
+}
+
+// CreateTrigger adds a trigger starting the target job when a run of the
+// job finishes. A job cannot trigger itself.
+func (s *triggerService) CreateTrigger(ctx context.Context, jobID string, req TriggerRequest) (*TriggerResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrigger, err)
+	}
+	if req.TargetJobID == jobID {
+		return nil, fmt.Errorf("%w: a job cannot trigger itself", ErrInvalidTrigger)
+	}
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+	if err := s.checkJob(ctx, req.TargetJobID); err != nil {
+		if errors.Is(err, ErrJobNotFound) {
+			return nil, fmt.Errorf("%w: target job not found", ErrInvalidTrigger)
+		}
+		return nil, err
+	}
+
+	t, err := s.queries.CreateJobTrigger(ctx, db.CreateJobTriggerParams{
+		ID:          uuid.New().String(),
+		SourceJobID: jobID,
+		TargetJobID: req.TargetJobID,
+		OnStatus:    req.OnStatus,
+	})
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return nil, ErrDuplicateTrigger
+		}
+		return nil, err
+	}
+	resp := toTriggerResponse(t)
+	return &resp, nil
+}
+
+// DeleteTrigger removes a trigger of a job
+func (s *triggerService) DeleteTrigger(ctx context.Context, jobID, triggerID string) error {
+	t, err := s.queries.GetJobTrigger(ctx, triggerID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return ErrTriggerNotFound
+		}
+		return err
+	}
+	if t.SourceJobID != jobID {
+		return ErrTriggerNotFound
+	}
+	return s.queries.DeleteJobTrigger(ctx, triggerID)
+}
+
+// checkJob reports ErrJobNotFound for unknown jobs
+func (s *triggerService) checkJob(ctx context.Context, id string) error {
+	if _, err := s.queries.GetJob(ctx, id); err != nil {
+		if db.IsNotFound(err) {
+			return ErrJobNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// toTriggerResponse converts a db.JobTrigger to a TriggerResponse
+func toTriggerResponse(t db.JobTrigger) TriggerResponse {
+	return TriggerResponse{
+		ID:          t.ID,
+		SourceJobID: t.SourceJobID,
+		TargetJobID: t.TargetJobID,
+		OnStatus:    t.OnStatus,
+		CreatedAt:   t.CreatedAt,
+	}
+}
@@ -0,0 +1,134 @@
+package triggers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testJobID     = "5f0c4f3e-6a4b-4c1e-9d2a-1b2c3d4e5f60"
+	testTargetID  = "9a3e7d1c-2b4f-4e6a-8c0d-1e2f3a4b5c6d"
+	testTriggerID = "c41d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+)
+
+func setupService(t *testing.T) (*MockTriggerQuerier, Service) {
+	ctrl := gomock.NewController(t)
+	querier := NewMockTriggerQuerier(ctrl)
+	return querier, NewService(querier)
+}
+
+func testTrigger() db.JobTrigger {
+	return db.JobTrigger{
+		ID:          testTriggerID,
+		SourceJobID: testJobID,
+		TargetJobID: testTargetID,
+		OnStatus:    "complete",
+		CreatedAt:   time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTriggerService_CreateTrigger(t *testing.T) {
+	ctx := context.Background()
+	req := TriggerRequest{TargetJobID: testTargetID, OnStatus: "complete"}
+
+	t.Run("creates the trigger", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		querier.EXPECT().GetJob(gomock.Any(), testTargetID).Return(db.Job{ID: testTargetID}, nil)
+		querier.EXPECT().
+			CreateJobTrigger(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateJobTriggerParams) (db.JobTrigger, error) {
+				assert.Equal(t, testJobID, arg.SourceJobID)
+				assert.Equal(t, testTargetID, arg.TargetJobID)
+				assert.Equal(t, "complete", arg.OnStatus)
+				assert.NotEmpty(t, arg.ID)
+				return testTrigger(), nil
+			})
+
+		resp, err := svc.CreateTrigger(ctx, testJobID, req)
+		require.NoError(t, err)
+		assert.Equal(t, testTriggerID, resp.ID)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, svc := setupService(t)
+		for name, req := range map[string]TriggerRequest{
+			"unknown status": {TargetJobID: testTargetID, OnStatus: "dead_letter"},
+			"missing target": {OnStatus: "any"},
+			"self trigger":   {TargetJobID: testJobID, OnStatus: "any"},
+		} {
+			_, err := svc.CreateTrigger(ctx, testJobID, req)
+			assert.ErrorIs(t, err, ErrInvalidTrigger, name)
+		}
+	})
+
+	t.Run("unknown source job", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{}, db.ErrNotFound)
+
+		_, err := svc.CreateTrigger(ctx, testJobID, req)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("unknown target job", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		querier.EXPECT().GetJob(gomock.Any(), testTargetID).Return(db.Job{}, sql.ErrNoRows)
+
+		_, err := svc.CreateTrigger(ctx, testJobID, req)
+		assert.ErrorIs(t, err, ErrInvalidTrigger)
+	})
+
+	t.Run("duplicate trigger", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJob(gomock.Any(), gomock.Any()).Return(db.Job{}, nil).Times(2)
+		querier.EXPECT().
+			CreateJobTrigger(gomock.Any(), gomock.Any()).
+			Return(db.JobTrigger{}, errors.New("constraint failed: UNIQUE constraint failed: job_triggers.source_job_id"))
+
+		_, err := svc.CreateTrigger(ctx, testJobID, req)
+		assert.ErrorIs(t, err, ErrDuplicateTrigger)
+	})
+}
+
+func TestTriggerService_ListTriggers(t *testing.T) {
+	querier, svc := setupService(t)
+	querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+	querier.EXPECT().ListJobTriggersBySource(gomock.Any(), testJobID).Return([]db.JobTrigger{testTrigger()}, nil)
+
+	resp, err := svc.ListTriggers(context.Background(), testJobID)
+	require.NoError(t, err)
+	require.Len(t, resp.Triggers, 1)
+	assert.Equal(t, testTargetID, resp.Triggers[0].TargetJobID)
+}
+
+func TestTriggerService_DeleteTrigger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes the trigger", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJobTrigger(gomock.Any(), testTriggerID).Return(testTrigger(), nil)
+		querier.EXPECT().DeleteJobTrigger(gomock.Any(), testTriggerID).Return(nil)
+		assert.NoError(t, svc.DeleteTrigger(ctx, testJobID, testTriggerID))
+	})
+
+	t.Run("trigger of another job", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJobTrigger(gomock.Any(), testTriggerID).Return(testTrigger(), nil)
+		assert.ErrorIs(t, svc.DeleteTrigger(ctx, testTargetID, testTriggerID), ErrTriggerNotFound)
+	})
+
+	t.Run("unknown trigger", func(t *testing.T) {
+		querier, svc := setupService(t)
+		querier.EXPECT().GetJobTrigger(gomock.Any(), testTriggerID).Return(db.JobTrigger{}, sql.ErrNoRows)
+		assert.ErrorIs(t, svc.DeleteTrigger(ctx, testJobID, testTriggerID), ErrTriggerNotFound)
+	})
+}
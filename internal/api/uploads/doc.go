@@ -0,0 +1,52 @@
+/*
+Package uploads lets users upload attachments to jobs over HTTP.
+
+Attachments are files kept for a job in the attachment store (see package
+attachments). Runs of the job reference them by ID to have them streamed
+into the standard input of their processes or placed into their working
+directory as input files (see package runs). The store records the size
+and SHA-256 checksum of every upload; runs verify the contents against
+them, so an attachment is never modified, only deleted.
+
+Uploads are sent as the raw request body; the Content-Type header becomes
+the attachment's MIME type. Uploads larger than
+GOPHER_TOWER_ATTACHMENT_MAX_BYTES (64MiB by default) are rejected.
+
+API Endpoints:
+
+	POST   /jobs/{id}/attachments?filename=x    - Upload an attachment (201 Created)
+	GET    /jobs/{id}/attachments               - List the job's attachments, newest first
+	GET    /jobs/{id}/attachments/{attachment}  - Download an attachment
+	DELETE /jobs/{id}/attachments/{attachment}  - Delete an attachment
+
+Example Request:
+
+	POST /jobs/{id}/attachments?filename=input.csv
+	Content-Type: text/csv
+
+	region,shards
+	eu-west-1,8
+
+Example Response:
+
+	{
+		"id": "3f6c...",
+		"job_id": "5f0c...",
+		"filename": "input.csv",
+		"mime_type": "text/csv",
+		"size": 26,
+		"sha256": "9b1e...",
+		"created_at": "..."
+	}
+
+Deleting an attachment does not change the runs that received it, but
+queued runs and re-runs referencing it fail.
+
+Error Handling:
+
+  - 201: Attachment uploaded
+  - 400: Invalid ID or filename
+  - 404: Job or attachment not found
+  - 413: The upload exceeds the size limit
+*/
+package uploads
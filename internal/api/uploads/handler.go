@@ -0,0 +1,147 @@
+package uploads
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// uploadTimeout bounds how long reading an upload may take; the server's
+// read timeout is meant for small request bodies
+const uploadTimeout = 10 * time.Minute
+
+// Handler handles HTTP requests for job attachments
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new upload handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the attachment routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/jobs/{id}/attachments", h.Upload)
+	r.Get("/jobs/{id}/attachments", h.ListAttachments)
+	r.Get("/jobs/{id}/attachments/{attachmentID}", h.GetAttachment)
+	r.Delete("/jobs/{id}/attachments/{attachmentID}", h.DeleteAttachment)
+}
+
+// jobID extracts and validates the job ID path parameter
+func jobID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid job ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// attachmentRef extracts and validates the job and attachment ID path
+// parameters
+func attachmentRef(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	job, ok := jobID(w, r)
+	if !ok {
+		return "", "", false
+	}
+	id := chi.URLParam(r, "attachmentID")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid attachment ID format", http.StatusBadRequest)
+		return "", "", false
+	}
+	return job, id, true
+}
+
+// writeError maps service errors to HTTP responses
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidUpload):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrAttachmentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// Upload handles attachment uploads
+func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	// Not every ResponseWriter supports deadlines; uploads are then bound
+	// by the server's read timeout
+	_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(uploadTimeout))
+
+	req := UploadRequest{Filename: r.URL.Query().Get("filename"), MimeType: r.Header.Get("Content-Type")}
+	resp, err := h.service.Upload(r.Context(), id, req, r.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// ListAttachments handles requests to list the attachments of a job
+func (h *Handler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.ListAttachments(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetAttachment handles attachment downloads
+func (h *Handler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	job, id, ok := attachmentRef(w, r)
+	if !ok {
+		return
+	}
+
+	f, a, err := h.service.OpenAttachment(r.Context(), job, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", a.MimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	http.ServeContent(w, r, a.Filename, a.CreatedAt, f)
+}
+
+// DeleteAttachment handles requests to delete an attachment
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	job, id, ok := attachmentRef(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(r.Context(), job, id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
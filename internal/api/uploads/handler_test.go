@@ -0,0 +1,140 @@
+package uploads
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupHandler(t *testing.T) (*MockService, chi.Router) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	router := chi.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+	return mockService, router
+}
+
+func serve(router chi.Router, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpload(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		setupMock  func(*MockService)
+		wantStatus int
+	}{
+		{
+			name:   "uploaded",
+			target: "/jobs/" + testJobID + "/attachments?filename=input.csv",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Upload(gomock.Any(), testJobID, UploadRequest{Filename: "input.csv", MimeType: "text/csv"}, gomock.Any()).
+					DoAndReturn(func(_, _ any, _ UploadRequest, body io.Reader) (*AttachmentResponse, error) {
+						data, err := io.ReadAll(body)
+						require.NoError(t, err)
+						assert.Equal(t, "a,b\n", string(data))
+						return &AttachmentResponse{ID: testAttachmentID, Size: 4}, nil
+					})
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:   "too large",
+			target: "/jobs/" + testJobID + "/attachments?filename=input.csv",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Upload(gomock.Any(), testJobID, gomock.Any(), gomock.Any()).Return(nil, ErrTooLarge)
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "invalid filename",
+			target: "/jobs/" + testJobID + "/attachments",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Upload(gomock.Any(), testJobID, gomock.Any(), gomock.Any()).Return(nil, ErrInvalidUpload)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "job not found",
+			target: "/jobs/" + testJobID + "/attachments?filename=input.csv",
+			setupMock: func(ms *MockService) {
+				ms.EXPECT().Upload(gomock.Any(), testJobID, gomock.Any(), gomock.Any()).Return(nil, ErrJobNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid job id",
+			target:     "/jobs/nope/attachments?filename=input.csv",
+			setupMock:  func(ms *MockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, router := setupHandler(t)
+			tt.setupMock(mockService)
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader("a,b\n"))
+			req.Header.Set("Content-Type", "text/csv")
+			w := serve(router, req)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestGetAttachment(t *testing.T) {
+	target := "/jobs/" + testJobID + "/attachments/" + testAttachmentID
+
+	t.Run("downloaded", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		path := filepath.Join(t.TempDir(), testAttachmentID)
+		require.NoError(t, os.WriteFile(path, []byte("a,b\n"), 0o644))
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		mockService.EXPECT().OpenAttachment(gomock.Any(), testJobID, testAttachmentID).
+			Return(f, &AttachmentResponse{Filename: "input.csv", MimeType: "text/csv"}, nil)
+
+		w := serve(router, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename=input.csv`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "a,b\n", w.Body.String())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService, router := setupHandler(t)
+		mockService.EXPECT().OpenAttachment(gomock.Any(), testJobID, testAttachmentID).Return(nil, nil, ErrAttachmentNotFound)
+		w := serve(router, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid attachment id", func(t *testing.T) {
+		_, router := setupHandler(t)
+		w := serve(router, httptest.NewRequest(http.MethodGet, "/jobs/"+testJobID+"/attachments/nope", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestListAndDeleteAttachments(t *testing.T) {
+	mockService, router := setupHandler(t)
+	mockService.EXPECT().ListAttachments(gomock.Any(), testJobID).
+		Return(&AttachmentListResponse{Attachments: []AttachmentResponse{{ID: testAttachmentID}}}, nil)
+	w := serve(router, httptest.NewRequest(http.MethodGet, "/jobs/"+testJobID+"/attachments", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), testAttachmentID)
+
+	mockService.EXPECT().DeleteAttachment(gomock.Any(), testJobID, testAttachmentID).Return(nil)
+	w = serve(router, httptest.NewRequest(http.MethodDelete, "/jobs/"+testJobID+"/attachments/"+testAttachmentID, nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/klauern/gopher-tower/internal/api/uploads (interfaces: UploadQuerier)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_querier_test.go -package=uploads github.com/klauern/gopher-tower/internal/api/uploads UploadQuerier
+//
+
+// Package uploads is a generated GoMock package.
+package uploads
+
+import (
+	context "context"
+	sql "database/sql"
+	reflect "reflect"
+
+	db "github.com/klauern/gopher-tower/internal/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUploadQuerier is a mock of UploadQuerier interface.
+type MockUploadQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockUploadQuerierMockRecorder is the mock recorder for MockUploadQuerier.
+type MockUploadQuerierMockRecorder struct {
+	mock *MockUploadQuerier
+}
+
+// NewMockUploadQuerier creates a new mock instance.
+func NewMockUploadQuerier(ctrl *gomock.Controller) *MockUploadQuerier {
+	mock := &MockUploadQuerier{ctrl: ctrl}
+	mock.recorder = &MockUploadQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadQuerier) EXPECT() *MockUploadQuerierMockRecorder {
+	return m.recorder
+}
+
+// CreateAttachment mocks base method.
+func (m *MockUploadQuerier) CreateAttachment(ctx context.Context, arg db.CreateAttachmentParams) (db.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAttachment", ctx, arg)
+	ret0, _ := ret[0].(db.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAttachment indicates an expected call of CreateAttachment.
+func (mr *MockUploadQuerierMockRecorder) CreateAttachment(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAttachment", reflect.TypeOf((*MockUploadQuerier)(nil).CreateAttachment), ctx, arg)
+}
+
+// DeleteAttachment mocks base method.
+func (m *MockUploadQuerier) DeleteAttachment(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockUploadQuerierMockRecorder) DeleteAttachment(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockUploadQuerier)(nil).DeleteAttachment), ctx, id)
+}
+
+// GetAttachment mocks base method.
+func (m *MockUploadQuerier) GetAttachment(ctx context.Context, id string) (db.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachment", ctx, id)
+	ret0, _ := ret[0].(db.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachment indicates an expected call of GetAttachment.
+func (mr *MockUploadQuerierMockRecorder) GetAttachment(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachment", reflect.TypeOf((*MockUploadQuerier)(nil).GetAttachment), ctx, id)
+}
+
+// GetJob mocks base method.
+func (m *MockUploadQuerier) GetJob(ctx context.Context, id string) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockUploadQuerierMockRecorder) GetJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockUploadQuerier)(nil).GetJob), ctx, id)
+}
+
+// ListAttachmentsByJob mocks base method.
+func (m *MockUploadQuerier) ListAttachmentsByJob(ctx context.Context, jobID sql.NullString) ([]db.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttachmentsByJob", ctx, jobID)
+	ret0, _ := ret[0].([]db.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAttachmentsByJob indicates an expected call of ListAttachmentsByJob.
+func (mr *MockUploadQuerierMockRecorder) ListAttachmentsByJob(ctx, jobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttachmentsByJob", reflect.TypeOf((*MockUploadQuerier)(nil).ListAttachmentsByJob), ctx, jobID)
+}
@@ -0,0 +1,49 @@
+package uploads
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// maxFilenameLength bounds the length of attachment filenames
+const maxFilenameLength = 255
+
+// defaultMimeType is recorded for uploads without a Content-Type
+const defaultMimeType = "application/octet-stream"
+
+// UploadRequest describes an uploaded attachment; its contents are the
+// request body
+type UploadRequest struct {
+	Filename string
+	MimeType string
+}
+
+// Validate checks if the upload request is valid
+func (r *UploadRequest) Validate() error {
+	switch {
+	case strings.TrimSpace(r.Filename) == "":
+		return errors.New("filename is required")
+	case len(r.Filename) > maxFilenameLength:
+		return errors.New("filename must be at most 255 characters")
+	case r.Filename == "." || r.Filename == ".." || strings.ContainsAny(r.Filename, "/\\\x00"):
+		return errors.New("filename must not be a path")
+	}
+	return nil
+}
+
+// AttachmentResponse represents an attachment in responses
+type AttachmentResponse struct {
+	ID        string    `json:"id"`
+	JobID     string    `json:"job_id"`
+	Filename  string    `json:"filename"`
+	MimeType  string    `json:"mime_type"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AttachmentListResponse lists the attachments of a job
+type AttachmentListResponse struct {
+	Attachments []AttachmentResponse `json:"attachments"`
+}
@@ -0,0 +1,194 @@
+//go:generate go tool mockgen -destination=mock_querier_test.go -package=uploads github.com/klauern/gopher-tower/internal/api/uploads UploadQuerier
+//go:generate go tool mockgen -destination=mock_service_test.go -package=uploads github.com/klauern/gopher-tower/internal/api/uploads Service
+//go:generate go tool mockgen -destination=mock_storage_test.go -package=uploads github.com/klauern/gopher-tower/internal/api/uploads Storage
+
+package uploads
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+)
+
+var (
+	ErrJobNotFound        = errors.New("job not found")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrInvalidUpload      = errors.New("invalid upload")
+	ErrTooLarge           = errors.New("upload exceeds the size limit")
+)
+
+// UploadQuerier defines the database operations used by the upload service
+type UploadQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	GetAttachment(ctx context.Context, id string) (db.Attachment, error)
+	ListAttachmentsByJob(ctx context.Context, jobID sql.NullString) ([]db.Attachment, error)
+	CreateAttachment(ctx context.Context, arg db.CreateAttachmentParams) (db.Attachment, error)
+	DeleteAttachment(ctx context.Context, id string) error
+}
+
+// Storage keeps the contents of attachments; it is implemented by
+// attachments.Store
+type Storage interface {
+	Save(id string, r io.Reader) (int64, string, error)
+	Open(id string) (*os.File, error)
+	Remove(id string) error
+	Path(id string) (string, error)
+}
+
+// Service provides attachment operations
+type Service interface {
+	Upload(ctx context.Context, jobID string, req UploadRequest, body io.Reader) (*AttachmentResponse, error)
+	ListAttachments(ctx context.Context, jobID string) (*AttachmentListResponse, error)
+	// OpenAttachment opens the contents of an attachment of a job
+	OpenAttachment(ctx context.Context, jobID, id string) (*os.File, *AttachmentResponse, error)
+	DeleteAttachment(ctx context.Context, jobID, id string) error
+}
+
+// uploadService implements the Service interface
+type uploadService struct {
+	queries UploadQuerier
+	storage Storage
+}
+
+// NewService creates a new upload service
+func NewService(queries UploadQuerier, storage Storage) Service {
+	return &uploadService{queries: queries, storage: storage}
+}
+
+// Upload stores the contents read from body as a new attachment of a job
+func (s *uploadService) Upload(ctx context.Context, jobID string, req UploadRequest, body io.Reader) (*AttachmentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+	}
+	if req.MimeType == "" {
+		req.MimeType = defaultMimeType
+	}
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	size, sum, err := s.storage.Save(id, body)
+	if err != nil {
+		if errors.Is(err, attachments.ErrTooLarge) {
+			return nil, fmt.Errorf("%w: %v", ErrTooLarge, err)
+		}
+		return nil, err
+	}
+	path, err := s.storage.Path(id)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.queries.CreateAttachment(ctx, db.CreateAttachmentParams{
+		ID:       id,
+		Filename: req.Filename,
+		FilePath: path,
+		FileSize: size,
+		MimeType: req.MimeType,
+		JobID:    db.StringToNullString(jobID),
+		Sha256:   db.StringToNullString(sum),
+	})
+	if err != nil {
+		s.remove(id)
+		return nil, err
+	}
+	return toAttachmentResponse(a), nil
+}
+
+// ListAttachments returns the attachments of a job, newest first
+func (s *uploadService) ListAttachments(ctx context.Context, jobID string) (*AttachmentListResponse, error) {
+	if err := s.checkJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+	list, err := s.queries.ListAttachmentsByJob(ctx, db.StringToNullString(jobID))
+	if err != nil {
+		return nil, err
+	}
+	resp := &AttachmentListResponse{Attachments: make([]AttachmentResponse, len(list))}
+	for i, a := range list {
+		resp.Attachments[i] = *toAttachmentResponse(a)
+	}
+	return resp, nil
+}
+
+// OpenAttachment opens the contents of an attachment
+func (s *uploadService) OpenAttachment(ctx context.Context, jobID, id string) (*os.File, *AttachmentResponse, error) {
+	a, err := s.getAttachment(ctx, jobID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := s.storage.Open(id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	return f, toAttachmentResponse(a), nil
+}
+
+// DeleteAttachment removes an attachment and its contents
+func (s *uploadService) DeleteAttachment(ctx context.Context, jobID, id string) error {
+	if _, err := s.getAttachment(ctx, jobID, id); err != nil {
+		return err
+	}
+	if err := s.queries.DeleteAttachment(ctx, id); err != nil {
+		return err
+	}
+	s.remove(id)
+	return nil
+}
+
+// remove deletes the contents of an attachment, which are unreachable
+// without its row
+func (s *uploadService) remove(id string) {
+	if err := s.storage.Remove(id); err != nil {
+		log.Printf("Failed to remove contents of attachment %s: %v", id, err)
+	}
+}
+
+func (s *uploadService) checkJob(ctx context.Context, jobID string) error {
+	if _, err := s.queries.GetJob(ctx, jobID); err != nil {
+		if db.IsNotFound(err) {
+			return ErrJobNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// getAttachment looks up an attachment of a job
+func (s *uploadService) getAttachment(ctx context.Context, jobID, id string) (db.Attachment, error) {
+	a, err := s.queries.GetAttachment(ctx, id)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return db.Attachment{}, ErrAttachmentNotFound
+		}
+		return db.Attachment{}, err
+	}
+	if a.JobID.String != jobID {
+		return db.Attachment{}, ErrAttachmentNotFound
+	}
+	return a, nil
+}
+
+// toAttachmentResponse converts a db.Attachment to an AttachmentResponse
+func toAttachmentResponse(a db.Attachment) *AttachmentResponse {
+	return &AttachmentResponse{
+		ID:        a.ID,
+		JobID:     a.JobID.String,
+		Filename:  a.Filename,
+		MimeType:  a.MimeType,
+		Size:      a.FileSize,
+		SHA256:    a.Sha256.String,
+		CreatedAt: a.CreatedAt,
+	}
+}
@@ -0,0 +1,184 @@
+package uploads
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testJobID        = "5f0c2b1a-3d4e-4f5a-8b6c-7d8e9f0a1b2c"
+	testAttachmentID = "3f6c1d2e-4a5b-4c6d-9e7f-8a9b0c1d2e3f"
+)
+
+type serviceTest struct {
+	querier *MockUploadQuerier
+	storage *MockStorage
+	svc     Service
+}
+
+func setupService(t *testing.T) *serviceTest {
+	ctrl := gomock.NewController(t)
+	st := &serviceTest{querier: NewMockUploadQuerier(ctrl), storage: NewMockStorage(ctrl)}
+	st.svc = NewService(st.querier, st.storage)
+	return st
+}
+
+func testAttachment() db.Attachment {
+	return db.Attachment{
+		ID:        testAttachmentID,
+		Filename:  "input.csv",
+		FilePath:  "/attachments/" + testAttachmentID,
+		FileSize:  4,
+		MimeType:  "text/csv",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		JobID:     sql.NullString{String: testJobID, Valid: true},
+		Sha256:    sql.NullString{String: "abc", Valid: true},
+	}
+}
+
+func TestUploadService_Upload(t *testing.T) {
+	t.Run("uploaded", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		var saved string
+		st.storage.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(id string, r io.Reader) (int64, string, error) {
+			saved = id
+			return 4, "abc", nil
+		})
+		st.storage.EXPECT().Path(gomock.Any()).DoAndReturn(func(id string) (string, error) {
+			return "/attachments/" + id, nil
+		})
+		st.querier.EXPECT().CreateAttachment(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, arg db.CreateAttachmentParams) (db.Attachment, error) {
+				assert.Equal(t, saved, arg.ID)
+				assert.Equal(t, "/attachments/"+saved, arg.FilePath)
+				assert.Equal(t, defaultMimeType, arg.MimeType)
+				assert.Equal(t, sql.NullString{String: "abc", Valid: true}, arg.Sha256)
+				return db.Attachment{ID: arg.ID, Filename: arg.Filename, FileSize: arg.FileSize, MimeType: arg.MimeType, JobID: arg.JobID, Sha256: arg.Sha256}, nil
+			})
+
+		resp, err := st.svc.Upload(context.Background(), testJobID, UploadRequest{Filename: "input.csv"}, strings.NewReader("a,b\n"))
+		require.NoError(t, err)
+		assert.Equal(t, saved, resp.ID)
+		assert.Equal(t, testJobID, resp.JobID)
+		assert.Equal(t, int64(4), resp.Size)
+		assert.Equal(t, "abc", resp.SHA256)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		st.storage.EXPECT().Save(gomock.Any(), gomock.Any()).Return(int64(0), "", fmt.Errorf("%w of 8 bytes", attachments.ErrTooLarge))
+		_, err := st.svc.Upload(context.Background(), testJobID, UploadRequest{Filename: "input.csv"}, strings.NewReader("123456789"))
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("contents removed when not recorded", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+		st.storage.EXPECT().Save(gomock.Any(), gomock.Any()).Return(int64(4), "abc", nil)
+		st.storage.EXPECT().Path(gomock.Any()).Return("/attachments/x", nil)
+		st.querier.EXPECT().CreateAttachment(gomock.Any(), gomock.Any()).Return(db.Attachment{}, errors.New("disk full"))
+		st.storage.EXPECT().Remove(gomock.Any()).Return(nil)
+		_, err := st.svc.Upload(context.Background(), testJobID, UploadRequest{Filename: "input.csv"}, strings.NewReader("a,b\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{}, sql.ErrNoRows)
+		_, err := st.svc.Upload(context.Background(), testJobID, UploadRequest{Filename: "input.csv"}, strings.NewReader(""))
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	for _, filename := range []string{"", "..", "dir/input.csv", `dir\input.csv`, strings.Repeat("x", 256)} {
+		t.Run("invalid filename "+filename, func(t *testing.T) {
+			st := setupService(t)
+			_, err := st.svc.Upload(context.Background(), testJobID, UploadRequest{Filename: filename}, strings.NewReader(""))
+			assert.ErrorIs(t, err, ErrInvalidUpload)
+		})
+	}
+}
+
+func TestUploadService_ListAttachments(t *testing.T) {
+	st := setupService(t)
+	st.querier.EXPECT().GetJob(gomock.Any(), testJobID).Return(db.Job{ID: testJobID}, nil)
+	st.querier.EXPECT().ListAttachmentsByJob(gomock.Any(), sql.NullString{String: testJobID, Valid: true}).
+		Return([]db.Attachment{testAttachment()}, nil)
+
+	resp, err := st.svc.ListAttachments(context.Background(), testJobID)
+	require.NoError(t, err)
+	require.Len(t, resp.Attachments, 1)
+	assert.Equal(t, AttachmentResponse{
+		ID:        testAttachmentID,
+		JobID:     testJobID,
+		Filename:  "input.csv",
+		MimeType:  "text/csv",
+		Size:      4,
+		SHA256:    "abc",
+		CreatedAt: testAttachment().CreatedAt,
+	}, resp.Attachments[0])
+}
+
+func TestUploadService_OpenAttachment(t *testing.T) {
+	t.Run("opened", func(t *testing.T) {
+		st := setupService(t)
+		path := filepath.Join(t.TempDir(), testAttachmentID)
+		require.NoError(t, os.WriteFile(path, []byte("a,b\n"), 0o644))
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		st.querier.EXPECT().GetAttachment(gomock.Any(), testAttachmentID).Return(testAttachment(), nil)
+		st.storage.EXPECT().Open(testAttachmentID).Return(f, nil)
+
+		got, resp, err := st.svc.OpenAttachment(context.Background(), testJobID, testAttachmentID)
+		require.NoError(t, err)
+		assert.Equal(t, f, got)
+		assert.Equal(t, "input.csv", resp.Filename)
+	})
+
+	t.Run("attachment of another job", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetAttachment(gomock.Any(), testAttachmentID).Return(testAttachment(), nil)
+		_, _, err := st.svc.OpenAttachment(context.Background(), "other-job", testAttachmentID)
+		assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	})
+
+	t.Run("contents missing", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetAttachment(gomock.Any(), testAttachmentID).Return(testAttachment(), nil)
+		st.storage.EXPECT().Open(testAttachmentID).Return(nil, os.ErrNotExist)
+		_, _, err := st.svc.OpenAttachment(context.Background(), testJobID, testAttachmentID)
+		assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	})
+}
+
+func TestUploadService_DeleteAttachment(t *testing.T) {
+	t.Run("deleted", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetAttachment(gomock.Any(), testAttachmentID).Return(testAttachment(), nil)
+		st.querier.EXPECT().DeleteAttachment(gomock.Any(), testAttachmentID).Return(nil)
+		st.storage.EXPECT().Remove(testAttachmentID).Return(nil)
+		require.NoError(t, st.svc.DeleteAttachment(context.Background(), testJobID, testAttachmentID))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		st := setupService(t)
+		st.querier.EXPECT().GetAttachment(gomock.Any(), testAttachmentID).Return(db.Attachment{}, sql.ErrNoRows)
+		assert.ErrorIs(t, st.svc.DeleteAttachment(context.Background(), testJobID, testAttachmentID), ErrAttachmentNotFound)
+	})
+}
@@ -0,0 +1,122 @@
+/*
+Package attachments stores files uploaded for jobs and hands them to runs.
+
+An attachment belongs to a job. A run may have one attachment streamed into
+the standard input of its processes and any number placed into its working
+directory as input files. Standard input may also be given inline when the
+run is started; it is then stored as an attachment of its own. What a run
+received is recorded with the run as a Source per attachment, including the
+size and SHA-256 checksum of the contents, which are verified when the
+contents are handed to the run.
+
+Uploads are capped by the store's size limit, inline standard input by
+MaxInlineBytes and the number of input files of a run by MaxFiles.
+*/
+package attachments
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid run stdin or input files")
+	ErrTooLarge     = errors.New("attachment exceeds the size limit")
+	ErrMismatch     = errors.New("attachment does not match its checksum")
+)
+
+const (
+	// MaxInlineBytes caps standard input given inline with a run request
+	MaxInlineBytes = 1 << 20
+	// MaxFiles caps the number of input files of a run
+	MaxFiles = 32
+)
+
+// Stdin selects the standard input of a run: either Content given inline
+// or an uploaded Attachment
+type Stdin struct {
+	Content    *string `json:"content,omitempty"`
+	Attachment string  `json:"attachment,omitempty"`
+}
+
+// Validate checks that exactly one source is given and that inline content
+// fits MaxInlineBytes
+func (s *Stdin) Validate() error {
+	switch {
+	case s.Content != nil && s.Attachment != "":
+		return fmt.Errorf("%w: stdin takes either content or an attachment", ErrInvalidInput)
+	case s.Content == nil && s.Attachment == "":
+		return fmt.Errorf("%w: stdin requires content or an attachment", ErrInvalidInput)
+	case s.Content != nil && len(*s.Content) > MaxInlineBytes:
+		return fmt.Errorf("%w: inline stdin exceeds %d bytes", ErrInvalidInput, MaxInlineBytes)
+	}
+	return nil
+}
+
+// File places an uploaded attachment into the working directory of a run
+type File struct {
+	Attachment string `json:"attachment"`
+	// Path is relative to the working directory; the attachment's filename
+	// when empty
+	Path string `json:"path,omitempty"`
+}
+
+// ValidateFiles checks the input files of a run. Paths defaulting to the
+// filenames of the attachments are checked once resolved, see
+// ValidateSources.
+func ValidateFiles(files []File) error {
+	if len(files) > MaxFiles {
+		return fmt.Errorf("%w: at most %d input files are allowed", ErrInvalidInput, MaxFiles)
+	}
+	for _, f := range files {
+		if f.Attachment == "" {
+			return fmt.Errorf("%w: input files require an attachment", ErrInvalidInput)
+		}
+		if f.Path != "" {
+			if err := validatePath(f.Path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Source records an attachment handed to a run
+type Source struct {
+	Attachment string `json:"attachment"`
+	Filename   string `json:"filename"`
+	// Path is where an input file is placed, relative to the working
+	// directory; empty for standard input
+	Path string `json:"path,omitempty"`
+	// Inline marks standard input given with the run request
+	Inline bool   `json:"inline,omitempty"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ValidateSources checks that the input files of a run have valid, distinct
+// paths
+func ValidateSources(files []Source) error {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if err := validatePath(f.Path); err != nil {
+			return err
+		}
+		path := filepath.Clean(filepath.FromSlash(f.Path))
+		if slices.Contains(paths, path) {
+			return fmt.Errorf("%w: duplicate input file %q", ErrInvalidInput, f.Path)
+		}
+		paths = append(paths, path)
+	}
+	return nil
+}
+
+// validatePath checks that an input file stays inside the working directory
+func validatePath(path string) error {
+	if !filepath.IsLocal(filepath.FromSlash(path)) {
+		return fmt.Errorf("%w: input file path %q must be relative and stay inside the working directory", ErrInvalidInput, path)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package attachments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdinValidate(t *testing.T) {
+	content, empty, large := "input", "", strings.Repeat("x", MaxInlineBytes+1)
+	tests := []struct {
+		name    string
+		stdin   Stdin
+		wantErr bool
+	}{
+		{"content", Stdin{Content: &content}, false},
+		{"empty content", Stdin{Content: &empty}, false},
+		{"attachment", Stdin{Attachment: "id"}, false},
+		{"both", Stdin{Content: &content, Attachment: "id"}, true},
+		{"neither", Stdin{}, true},
+		{"too large", Stdin{Content: &large}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.stdin.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	assert.NoError(t, ValidateFiles([]File{{Attachment: "a"}, {Attachment: "b", Path: "dir/b.txt"}}))
+	assert.ErrorIs(t, ValidateFiles([]File{{Path: "a.txt"}}), ErrInvalidInput)
+	for _, path := range []string{"/etc/passwd", "../a.txt", "dir/../../a.txt"} {
+		assert.ErrorIs(t, ValidateFiles([]File{{Attachment: "a", Path: path}}), ErrInvalidInput, path)
+	}
+	assert.ErrorIs(t, ValidateFiles(make([]File, MaxFiles+1)), ErrInvalidInput)
+}
+
+func TestValidateSources(t *testing.T) {
+	assert.NoError(t, ValidateSources([]Source{{Path: "a.txt"}, {Path: "dir/a.txt"}}))
+	assert.ErrorIs(t, ValidateSources([]Source{{Path: "a.txt"}, {Path: "dir/../a.txt"}}), ErrInvalidInput)
+	assert.ErrorIs(t, ValidateSources([]Source{{Path: ""}}), ErrInvalidInput)
+}
@@ -0,0 +1,170 @@
+package attachments
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMaxBytes caps the size of an attachment when no limit is
+// configured
+const DefaultMaxBytes = 64 << 20
+
+// tmpPrefix marks files of uploads in progress
+const tmpPrefix = "tmp-"
+
+// Store keeps the contents of attachments on local disk, one file per
+// attachment named after its ID
+type Store struct {
+	root     string
+	maxBytes int64
+}
+
+// NewStore opens the store kept under root, creating root if needed.
+// maxBytes caps the size of each attachment; DefaultMaxBytes when zero.
+func NewStore(root string, maxBytes int64) (*Store, error) {
+	if root == "" {
+		return nil, errors.New("attachment store root is required")
+	}
+	if maxBytes < 0 {
+		return nil, errors.New("attachment size limit must not be negative")
+	}
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{root: root, maxBytes: maxBytes}, nil
+}
+
+// MaxBytes returns the size limit of an attachment
+func (s *Store) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Save stores the contents read from r under id and returns their size and
+// SHA-256 checksum. Contents exceeding the size limit are rejected with
+// ErrTooLarge.
+func (s *Store) Save(id string, r io.Reader) (int64, string, error) {
+	path, err := s.Path(id)
+	if err != nil {
+		return 0, "", err
+	}
+	tmp, err := os.CreateTemp(s.root, tmpPrefix)
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	sum := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, sum), io.LimitReader(r, s.maxBytes+1))
+	if err == nil && n > s.maxBytes {
+		err = fmt.Errorf("%w of %d bytes", ErrTooLarge, s.maxBytes)
+	}
+	if err != nil {
+		tmp.Close()
+		return 0, "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// Open opens the contents of an attachment
+func (s *Store) Open(id string) (*os.File, error) {
+	path, err := s.Path(id)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Remove deletes the contents of an attachment; missing contents are not
+// an error
+func (s *Store) Remove(id string) error {
+	path, err := s.Path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Path returns the file holding an attachment. IDs are UUIDs, so they
+// cannot point outside the store.
+func (s *Store) Path(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("invalid attachment ID %q", id)
+	}
+	return filepath.Join(s.root, id), nil
+}
+
+// Verify wraps r, the contents of src, so that reading it fails with
+// ErrMismatch once it turns out they differ in size or checksum from what
+// was recorded
+func Verify(r io.Reader, src Source) io.Reader {
+	return &verifier{r: r, src: src, sum: sha256.New()}
+}
+
+type verifier struct {
+	r   io.Reader
+	src Source
+	sum hash.Hash
+	n   int64
+}
+
+func (v *verifier) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.n += int64(n)
+	v.sum.Write(p[:n])
+	if v.n > v.src.Size {
+		return n, fmt.Errorf("%w: %s is larger than %d bytes", ErrMismatch, v.src.Filename, v.src.Size)
+	}
+	if errors.Is(err, io.EOF) {
+		if v.n != v.src.Size || hex.EncodeToString(v.sum.Sum(nil)) != v.src.SHA256 {
+			return n, fmt.Errorf("%w: %s", ErrMismatch, v.src.Filename)
+		}
+	}
+	return n, err
+}
+
+// WriteFile writes an input file, read from r, into dir at its path,
+// creating parent directories. The contents are verified and the file
+// cannot escape dir, not even through symbolic links.
+func WriteFile(dir string, src Source, r io.Reader) error {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	name := filepath.Clean(filepath.FromSlash(src.Path))
+	if parent := filepath.Dir(name); parent != "." {
+		if err := root.MkdirAll(parent, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, Verify(r, src)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
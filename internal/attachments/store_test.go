@@ -0,0 +1,88 @@
+package attachments
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// abcSHA256 is the SHA-256 checksum of "abc"
+const abcSHA256 = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+
+func TestStore(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewStore(root, 8)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), store.MaxBytes())
+
+	id := uuid.New().String()
+	size, sum, err := store.Save(id, strings.NewReader("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), size)
+	assert.Equal(t, abcSHA256, sum)
+
+	f, err := store.Open(id)
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+
+	t.Run("too large", func(t *testing.T) {
+		_, _, err := store.Save(uuid.New().String(), strings.NewReader("123456789"))
+		assert.ErrorIs(t, err, ErrTooLarge)
+		// Nothing is left behind
+		entries, err := os.ReadDir(root)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		_, _, err := store.Save("../escape", strings.NewReader("abc"))
+		assert.Error(t, err)
+		_, err = store.Open("../escape")
+		assert.Error(t, err)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, store.Remove(id))
+		_, err := store.Open(id)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.NoError(t, store.Remove(id))
+	})
+}
+
+func TestVerify(t *testing.T) {
+	src := Source{Filename: "abc.txt", Size: 3, SHA256: abcSHA256}
+	data, err := io.ReadAll(Verify(strings.NewReader("abc"), src))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+
+	for _, content := range []string{"abd", "ab", "abcd"} {
+		_, err := io.ReadAll(Verify(strings.NewReader(content), src))
+		assert.ErrorIs(t, err, ErrMismatch, content)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	src := Source{Filename: "abc.txt", Path: "nested/abc.txt", Size: 3, SHA256: abcSHA256}
+	require.NoError(t, WriteFile(dir, src, strings.NewReader("abc")))
+	data, err := os.ReadFile(filepath.Join(dir, "nested", "abc.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+
+	// Symbolic links cannot lead the file out of dir
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+	src.Path = "link/abc.txt"
+	assert.Error(t, WriteFile(dir, src, strings.NewReader("abc")))
+	_, err = os.Stat(filepath.Join(outside, "abc.txt"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
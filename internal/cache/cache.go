@@ -0,0 +1,132 @@
+/*
+Package cache keeps directories of job runs, such as dependency caches,
+between runs.
+
+A job declares its caches as a list of entries, each naming a path and a
+key template:
+
+	{"path": "~/go/pkg/mod", "key": "go-mod-${{ hashFiles('go.sum') }}", "restore_keys": ["go-mod-"]}
+
+Paths are relative to the run's working directory; a leading ~/ refers to
+the run's HOME. Keys are resolved before the run (see Resolve): a key that
+changes whenever go.sum changes makes every dependency update start from a
+fresh cache.
+
+Before the run, the entry saved under the exact key is restored into the
+path. When there is none, the most recently saved entry whose key starts
+with the first matching restore key is restored instead. After a
+successful run, every path whose exact key missed is saved under its key.
+Saved entries are never overwritten.
+
+Entries live in a Store on local disk, scoped per job. The store evicts
+the least recently used entries to stay under its size cap.
+*/
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidCache = errors.New("invalid cache")
+	ErrTooLarge     = errors.New("cache entry exceeds the store size")
+)
+
+// Limits of a job's cache declarations
+const (
+	MaxEntries   = 16
+	MaxKeyLength = 512
+)
+
+// homePrefix marks paths relative to the run's HOME
+const homePrefix = "~/"
+
+// Entry declares a directory cached between the runs of a job
+type Entry struct {
+	// Path is the cached directory, relative to the working directory or,
+	// starting with ~/, to HOME
+	Path string `json:"path"`
+	// Key is the template of the key the directory is saved under
+	Key string `json:"key"`
+	// RestoreKeys are templates of key prefixes tried in order when no
+	// entry was saved under Key
+	RestoreKeys []string `json:"restore_keys,omitempty"`
+}
+
+// Validate checks the entry without resolving its keys
+func (e *Entry) Validate() error {
+	path := strings.TrimPrefix(e.Path, homePrefix)
+	if path == "" || !filepath.IsLocal(path) {
+		return fmt.Errorf("%w: path %q must be relative to the working directory or ~/", ErrInvalidCache, e.Path)
+	}
+	if e.Key == "" {
+		return fmt.Errorf("%w: key is required for %s", ErrInvalidCache, e.Path)
+	}
+	for _, tmpl := range append([]string{e.Key}, e.RestoreKeys...) {
+		if _, err := parse(tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dir returns the directory of the entry given a run's working directory
+// and HOME
+func (e *Entry) Dir(workDir, home string) string {
+	if rest, ok := strings.CutPrefix(e.Path, homePrefix); ok {
+		return filepath.Join(home, rest)
+	}
+	return filepath.Join(workDir, e.Path)
+}
+
+// ValidateEntries checks a job's cache declarations. Two entries may not
+// share a path.
+func ValidateEntries(entries []Entry) error {
+	if len(entries) > MaxEntries {
+		return fmt.Errorf("%w: at most %d entries are allowed", ErrInvalidCache, MaxEntries)
+	}
+	seen := make(map[string]bool, len(entries))
+	for i := range entries {
+		if err := entries[i].Validate(); err != nil {
+			return err
+		}
+		path := filepath.Clean(entries[i].Path)
+		if seen[path] {
+			return fmt.Errorf("%w: duplicate path %q", ErrInvalidCache, entries[i].Path)
+		}
+		seen[path] = true
+	}
+	return nil
+}
+
+// Decode parses and validates stored cache declarations
+func Decode(data []byte) ([]Entry, error) {
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCache, err)
+	}
+	if err := ValidateEntries(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Result reports what happened to one entry during a run
+type Result struct {
+	Path string `json:"path"`
+	// Key is the resolved key of the entry
+	Key string `json:"key"`
+	// Hit is set when an entry saved under exactly Key was restored
+	Hit bool `json:"hit"`
+	// RestoredKey is the key of the restored entry, if any; a restore key
+	// matched when it differs from Key
+	RestoredKey string `json:"restored_key,omitempty"`
+	// Saved is set when the directory was saved under Key after the run
+	Saved bool `json:"saved,omitempty"`
+	// Error describes why the entry could not be restored or saved
+	Error string `json:"error,omitempty"`
+}
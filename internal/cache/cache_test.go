@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   Entry
+		wantErr bool
+	}{
+		{name: "hashed key", entry: Entry{Path: "node_modules", Key: "npm-${{ hashFiles('package-lock.json') }}", RestoreKeys: []string{"npm-"}}},
+		{name: "home path", entry: Entry{Path: "~/go/pkg/mod", Key: "go-mod-${{ hashFiles('**/go.sum', '!vendor/**') }}"}},
+		{name: "env key", entry: Entry{Path: ".cache", Key: "build-${{ env.MATRIX_OS }}"}},
+		{name: "missing path", entry: Entry{Key: "k"}, wantErr: true},
+		{name: "path escapes", entry: Entry{Path: "../etc", Key: "k"}, wantErr: true},
+		{name: "absolute path", entry: Entry{Path: "/etc", Key: "k"}, wantErr: true},
+		{name: "home escapes", entry: Entry{Path: "~/../etc", Key: "k"}, wantErr: true},
+		{name: "missing key", entry: Entry{Path: ".cache"}, wantErr: true},
+		{name: "unterminated expression", entry: Entry{Path: ".cache", Key: "k-${{ env.HOME"}, wantErr: true},
+		{name: "unknown function", entry: Entry{Path: ".cache", Key: "k-${{ format('x') }}"}, wantErr: true},
+		{name: "unquoted pattern", entry: Entry{Path: ".cache", Key: "k-${{ hashFiles(go.sum) }}"}, wantErr: true},
+		{name: "pattern escapes", entry: Entry{Path: ".cache", Key: "k-${{ hashFiles('../go.sum') }}"}, wantErr: true},
+		{name: "invalid restore key", entry: Entry{Path: ".cache", Key: "k", RestoreKeys: []string{"${{ env. }}"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCache)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	entries, err := Decode([]byte(`[{"path": "~/.npm", "key": "npm", "restore_keys": ["n"]}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Path: "~/.npm", Key: "npm", RestoreKeys: []string{"n"}}}, entries)
+	assert.Equal(t, "/ws/home/.npm", entries[0].Dir("/ws/work", "/ws/home"))
+
+	_, err = Decode([]byte(`[{"path": "a", "key": "x"}, {"path": "a/", "key": "y"}]`))
+	assert.ErrorIs(t, err, ErrInvalidCache)
+	_, err = Decode([]byte(`{}`))
+	assert.ErrorIs(t, err, ErrInvalidCache)
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("go.sum", "a")
+	write("tools/go.sum", "b")
+	write("vendor/x/go.sum", "c")
+	env := map[string]string{"MATRIX_OS": "linux"}
+
+	resolve := func(tmpl string) string {
+		t.Helper()
+		key, err := Resolve(tmpl, dir, env)
+		require.NoError(t, err)
+		return key
+	}
+
+	key := resolve("go-${{ env.MATRIX_OS }}-${{ hashFiles('**/go.sum') }}")
+	assert.Regexp(t, `^go-linux-[0-9a-f]{64}$`, key)
+	assert.Equal(t, key, resolve("go-${{env.MATRIX_OS}}-${{ hashFiles('**/go.sum') }}"), "whitespace is insignificant")
+	assert.NotEqual(t, key, resolve("go-linux-${{ hashFiles('go.sum') }}"))
+	assert.Equal(t, resolve("${{ hashFiles('go.sum', 'tools/go.sum') }}"), resolve("${{ hashFiles('**/go.sum', '!vendor/**') }}"))
+	assert.Equal(t, "deps-", resolve("deps-${{ hashFiles('missing/*.lock') }}"), "no matches hash to nothing")
+
+	before := resolve("${{ hashFiles('go.sum') }}")
+	write("go.sum", "changed")
+	assert.NotEqual(t, before, resolve("${{ hashFiles('go.sum') }}"))
+
+	_, err := Resolve("${{ hashFiles('missing') }}", dir, env)
+	assert.ErrorIs(t, err, ErrInvalidCache, "keys may not resolve to nothing")
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"go.sum", "go.sum", true},
+		{"*.sum", "go.sum", true},
+		{"*.sum", "a/go.sum", false},
+		{"**/go.sum", "go.sum", true},
+		{"**/go.sum", "a/b/go.sum", true},
+		{"a/**", "a/b/c", true},
+		{"a/**/c", "a/c", true},
+		{"a/**/c", "b/c", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, match(tt.pattern, tt.name), "%s ~ %s", tt.pattern, tt.name)
+	}
+}
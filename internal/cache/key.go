@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Expressions are written as ${{ expr }} inside key templates
+const (
+	exprOpen  = "${{"
+	exprClose = "}}"
+)
+
+// segment is a literal part of a key template or an expression
+type segment struct {
+	literal string
+	// fn is "hashFiles" or "env"; empty for literals
+	fn   string
+	args []string
+}
+
+// parse splits a key template into literals and expressions. Supported
+// expressions are hashFiles('pattern', ...) and env.NAME.
+func parse(tmpl string) ([]segment, error) {
+	var segs []segment
+	rest := tmpl
+	for rest != "" {
+		before, after, found := strings.Cut(rest, exprOpen)
+		if before != "" {
+			segs = append(segs, segment{literal: before})
+		}
+		if !found {
+			break
+		}
+		expr, tail, ok := strings.Cut(after, exprClose)
+		if !ok {
+			return nil, fmt.Errorf("%w: unterminated expression in key %q", ErrInvalidCache, tmpl)
+		}
+		seg, err := parseExpr(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("%w in key %q", err, tmpl)
+		}
+		segs = append(segs, seg)
+		rest = tail
+	}
+	return segs, nil
+}
+
+func parseExpr(expr string) (segment, error) {
+	if name, ok := strings.CutPrefix(expr, "env."); ok {
+		if name == "" || strings.ContainsFunc(name, func(r rune) bool {
+			return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		}) {
+			return segment{}, fmt.Errorf("%w: invalid variable %q", ErrInvalidCache, expr)
+		}
+		return segment{fn: "env", args: []string{name}}, nil
+	}
+	list, ok := strings.CutPrefix(expr, "hashFiles(")
+	if !ok {
+		return segment{}, fmt.Errorf("%w: unsupported expression %q", ErrInvalidCache, expr)
+	}
+	list, ok = strings.CutSuffix(list, ")")
+	if !ok {
+		return segment{}, fmt.Errorf("%w: unterminated call %q", ErrInvalidCache, expr)
+	}
+	var patterns []string
+	for arg := range strings.SplitSeq(list, ",") {
+		arg = strings.TrimSpace(arg)
+		if len(arg) < 2 || arg[0] != '\'' || arg[len(arg)-1] != '\'' {
+			return segment{}, fmt.Errorf("%w: hashFiles takes quoted patterns, got %q", ErrInvalidCache, arg)
+		}
+		pattern := arg[1 : len(arg)-1]
+		if !validPattern(strings.TrimPrefix(pattern, "!")) {
+			return segment{}, fmt.Errorf("%w: invalid pattern %q", ErrInvalidCache, pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return segment{fn: "hashFiles", args: patterns}, nil
+}
+
+// validPattern reports whether a hashFiles pattern is well-formed and
+// stays inside the working directory
+func validPattern(pattern string) bool {
+	if pattern == "" || !filepath.IsLocal(pattern) {
+		return false
+	}
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+// Resolve expands a key template for a run with the given working
+// directory and environment. hashFiles('pattern', ...) expands to the
+// SHA-256 of the files below workDir matching any of the patterns, or to
+// nothing when none match; patterns starting with ! exclude files and **
+// matches any number of directories. env.NAME expands to a variable of the
+// run, such as MATRIX_OS.
+func Resolve(tmpl, workDir string, env map[string]string) (string, error) {
+	segs, err := parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var key strings.Builder
+	for _, seg := range segs {
+		switch seg.fn {
+		case "":
+			key.WriteString(seg.literal)
+		case "env":
+			key.WriteString(env[seg.args[0]])
+		case "hashFiles":
+			sum, err := hashFiles(workDir, seg.args)
+			if err != nil {
+				return "", err
+			}
+			key.WriteString(sum)
+		}
+	}
+	if key.Len() == 0 || key.Len() > MaxKeyLength {
+		return "", fmt.Errorf("%w: key %q must resolve to 1 to %d bytes", ErrInvalidCache, tmpl, MaxKeyLength)
+	}
+	return key.String(), nil
+}
+
+// hashFiles hashes the contents of the files matching patterns, in path
+// order
+func hashFiles(dir string, patterns []string) (string, error) {
+	var include, exclude []string
+	for _, p := range patterns {
+		if rest, ok := strings.CutPrefix(p, "!"); ok {
+			exclude = append(exclude, rest)
+		} else {
+			include = append(include, p)
+		}
+	}
+
+	matched := make(map[string]bool)
+	for _, pattern := range include {
+		base := literalPrefix(pattern)
+		err := filepath.WalkDir(filepath.Join(dir, base), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if match(pattern, rel) && !slices.ContainsFunc(exclude, func(ex string) bool { return match(ex, rel) }) {
+				matched[rel] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to hash files: %w", err)
+		}
+	}
+	if len(matched) == 0 {
+		return "", nil
+	}
+
+	files := make([]string, 0, len(matched))
+	for rel := range matched {
+		files = append(files, rel)
+	}
+	slices.Sort(files)
+	h := sha256.New()
+	for _, rel := range files {
+		sum, err := hashFile(filepath.Join(dir, rel))
+		if err != nil {
+			return "", fmt.Errorf("failed to hash files: %w", err)
+		}
+		h.Write(sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// literalPrefix returns the leading directories of a pattern that contain
+// no wildcards, where walking for matches starts
+func literalPrefix(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	n := 0
+	for n < len(parts)-1 && !strings.ContainsAny(parts[n], `*?[\`) {
+		n++
+	}
+	return filepath.FromSlash(strings.Join(parts[:n], "/"))
+}
+
+// match reports whether a slash-separated path matches a pattern in which
+// a ** element matches any number of directories
+func match(pattern, name string) bool {
+	return matchParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
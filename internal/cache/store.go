@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBytes caps the size of a store when no cap is configured
+const DefaultMaxBytes = 10 << 30
+
+// File names of the entries of a store: <id>.tar.gz holds the archive,
+// <id>.json its metadata
+const (
+	archiveExt = ".tar.gz"
+	metaExt    = ".json"
+	tmpPrefix  = "tmp-"
+)
+
+// Store keeps cache entries on local disk
+type Store struct {
+	root     string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*meta
+	size    int64
+}
+
+// meta describes a stored entry
+type meta struct {
+	Scope   string    `json:"scope"`
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+	Used    time.Time `json:"used"`
+}
+
+// NewStore opens the store kept under root, creating root if needed.
+// maxBytes caps the total size of the entries; DefaultMaxBytes when zero.
+func NewStore(root string, maxBytes int64) (*Store, error) {
+	if root == "" {
+		return nil, fmt.Errorf("%w: store root is required", ErrInvalidCache)
+	}
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("%w: store size must not be negative", ErrInvalidCache)
+	}
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store{root: root, maxBytes: maxBytes, entries: make(map[string]*meta)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads the metadata of all entries, removing what interrupted saves
+// left behind
+func (s *Store) load() error {
+	files, err := os.ReadDir(s.root)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		name := f.Name()
+		if strings.HasPrefix(name, tmpPrefix) {
+			_ = os.Remove(filepath.Join(s.root, name))
+			continue
+		}
+		id, ok := strings.CutSuffix(name, metaExt)
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.root, name))
+		if err != nil {
+			return err
+		}
+		var m meta
+		if err := json.Unmarshal(data, &m); err != nil {
+			log.Printf("Cache store: dropping entry %s: %v", id, err)
+			s.remove(id)
+			continue
+		}
+		s.entries[id] = &m
+		s.size += m.Size
+	}
+	for _, f := range files {
+		id, ok := strings.CutSuffix(f.Name(), archiveExt)
+		if _, known := s.entries[id]; ok && !known {
+			s.remove(id)
+		}
+	}
+	// The size cap may have been lowered since the entries were saved
+	s.evict("")
+	return nil
+}
+
+// Root returns the directory holding the entries
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Size returns the total size of the stored entries
+func (s *Store) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Restore extracts the entry saved under key in scope into dir. When there
+// is none, the most recently saved entry whose key starts with the first
+// matching restore key is extracted. It returns the key of the extracted
+// entry, or "" on a miss.
+func (s *Store) Restore(scope, key string, restoreKeys []string, dir string) (string, error) {
+	s.mu.Lock()
+	id, m := s.lookup(scope, key, restoreKeys)
+	if m == nil {
+		s.mu.Unlock()
+		return "", nil
+	}
+	restored := m.Key
+	// Open under the lock: an entry evicted meanwhile stays readable
+	// through the open file
+	f, err := os.Open(s.path(id, archiveExt))
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := unpack(f, dir); err != nil {
+		return "", fmt.Errorf("failed to restore cache %q: %w", restored, err)
+	}
+	return restored, nil
+}
+
+// lookup finds the entry to restore and marks it used. The caller must
+// hold s.mu.
+func (s *Store) lookup(scope, key string, restoreKeys []string) (string, *meta) {
+	id := entryID(scope, key)
+	m := s.entries[id]
+	for _, prefix := range restoreKeys {
+		if m != nil {
+			break
+		}
+		for candidate, c := range s.entries {
+			if c.Scope == scope && strings.HasPrefix(c.Key, prefix) && (m == nil || c.Created.After(m.Created)) {
+				id, m = candidate, c
+			}
+		}
+	}
+	if m == nil {
+		return "", nil
+	}
+	m.Used = time.Now()
+	if err := s.writeMeta(id, m); err != nil {
+		log.Printf("Cache store: failed to update entry %s: %v", id, err)
+	}
+	return id, m
+}
+
+// Save archives dir under key in scope. It reports false without saving
+// when an entry already exists under key, since entries are immutable.
+// The least recently used entries are evicted to make room.
+func (s *Store) Save(scope, key, dir string) (bool, error) {
+	id := entryID(scope, key)
+	if s.exists(id) {
+		return false, nil
+	}
+
+	tmp, err := os.CreateTemp(s.root, tmpPrefix)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+	if err := pack(dir, tmp); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("failed to save cache %q: %w", key, err)
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if info.Size() > s.maxBytes {
+		return false, fmt.Errorf("%w: %q is %d bytes", ErrTooLarge, key, info.Size())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[id]; ok {
+		// Saved concurrently by another run
+		return false, nil
+	}
+	now := time.Now()
+	m := &meta{Scope: scope, Key: key, Size: info.Size(), Created: now, Used: now}
+	if err := os.Rename(tmp.Name(), s.path(id, archiveExt)); err != nil {
+		return false, err
+	}
+	if err := s.writeMeta(id, m); err != nil {
+		s.remove(id)
+		return false, err
+	}
+	s.entries[id] = m
+	s.size += m.Size
+	s.evict(id)
+	return true, nil
+}
+
+func (s *Store) exists(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[id]
+	return ok
+}
+
+// evict removes the least recently used entries other than keep until the
+// store fits its size cap. The caller must hold s.mu.
+func (s *Store) evict(keep string) {
+	if s.size <= s.maxBytes {
+		return
+	}
+	ids := make([]string, 0, len(s.entries))
+	for id := range s.entries {
+		if id != keep {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return s.entries[ids[i]].Used.Before(s.entries[ids[j]].Used)
+	})
+	for _, id := range ids {
+		if s.size <= s.maxBytes {
+			return
+		}
+		s.size -= s.entries[id].Size
+		delete(s.entries, id)
+		s.remove(id)
+	}
+}
+
+// writeMeta atomically writes the metadata of an entry
+func (s *Store) writeMeta(id string, m *meta) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.root, tmpPrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(id, metaExt))
+}
+
+// remove deletes the files of an entry
+func (s *Store) remove(id string) {
+	for _, ext := range []string{metaExt, archiveExt} {
+		if err := os.Remove(s.path(id, ext)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Cache store: failed to remove entry %s: %v", id, err)
+		}
+	}
+}
+
+func (s *Store) path(id, ext string) string {
+	return filepath.Join(s.root, id+ext)
+}
+
+// entryID derives the file name of an entry from its scope and key
+func entryID(scope, key string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return hex.EncodeToString(sum[:16])
+}
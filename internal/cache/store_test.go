@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fillDir creates a directory holding the given files
+func fillDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestStore(t *testing.T) {
+	t.Run("saves and restores", func(t *testing.T) {
+		store, err := NewStore(t.TempDir(), 0)
+		require.NoError(t, err)
+		src := fillDir(t, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+		require.NoError(t, os.Symlink("a.txt", filepath.Join(src, "link")))
+
+		saved, err := store.Save("job", "deps-1", src)
+		require.NoError(t, err)
+		assert.True(t, saved)
+		saved, err = store.Save("job", "deps-1", src)
+		require.NoError(t, err)
+		assert.False(t, saved, "entries are immutable")
+
+		dest := filepath.Join(t.TempDir(), "restored")
+		key, err := store.Restore("job", "deps-1", nil, dest)
+		require.NoError(t, err)
+		assert.Equal(t, "deps-1", key)
+		assert.Equal(t, "a", readFile(t, filepath.Join(dest, "a.txt")))
+		assert.Equal(t, "b", readFile(t, filepath.Join(dest, "sub", "b.txt")))
+		assert.Equal(t, "a", readFile(t, filepath.Join(dest, "link")))
+	})
+
+	t.Run("falls back to restore keys", func(t *testing.T) {
+		store, err := NewStore(t.TempDir(), 0)
+		require.NoError(t, err)
+		for i, key := range []string{"deps-old", "deps-new", "other"} {
+			_, err := store.Save("job", key, fillDir(t, map[string]string{"v": key}))
+			require.NoError(t, err)
+			if i == 0 {
+				// Creation times order the candidates
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+
+		dest := t.TempDir()
+		key, err := store.Restore("job", "deps-3", []string{"nothing-", "deps-"}, dest)
+		require.NoError(t, err)
+		assert.Equal(t, "deps-new", key)
+		assert.Equal(t, "deps-new", readFile(t, filepath.Join(dest, "v")))
+
+		key, err = store.Restore("job", "deps-3", []string{"nothing-"}, t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, key)
+		key, err = store.Restore("other-job", "deps-new", []string{"deps-"}, t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, key, "entries are scoped")
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		root := t.TempDir()
+		content := strings.Repeat("x", 1<<10)
+
+		// Size the cap to fit two entries
+		probe, err := NewStore(t.TempDir(), 0)
+		require.NoError(t, err)
+		_, err = probe.Save("job", "probe", fillDir(t, map[string]string{"f": content}))
+		require.NoError(t, err)
+		store, err := NewStore(root, 2*probe.Size()+probe.Size()/2)
+		require.NoError(t, err)
+
+		for _, key := range []string{"a", "b"} {
+			_, err := store.Save("job", key, fillDir(t, map[string]string{"f": content}))
+			require.NoError(t, err)
+			time.Sleep(10 * time.Millisecond)
+		}
+		// Using a makes b the least recently used entry
+		_, err = store.Restore("job", "a", nil, t.TempDir())
+		require.NoError(t, err)
+		_, err = store.Save("job", "c", fillDir(t, map[string]string{"f": content}))
+		require.NoError(t, err)
+
+		for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+			got, err := store.Restore("job", key, nil, t.TempDir())
+			require.NoError(t, err)
+			assert.Equal(t, want, got != "", key)
+		}
+		assert.LessOrEqual(t, store.Size(), 2*probe.Size()+probe.Size()/2)
+
+		// Entries survive reopening the store
+		reopened, err := NewStore(root, 0)
+		require.NoError(t, err)
+		assert.Equal(t, store.Size(), reopened.Size())
+	})
+
+	t.Run("rejects entries larger than the store", func(t *testing.T) {
+		store, err := NewStore(t.TempDir(), 16)
+		require.NoError(t, err)
+		_, err = store.Save("job", "big", fillDir(t, map[string]string{"f": strings.Repeat("x", 1<<10)}))
+		assert.ErrorIs(t, err, ErrTooLarge)
+		assert.Zero(t, store.Size())
+	})
+
+	t.Run("restores nothing outside the directory", func(t *testing.T) {
+		store, err := NewStore(t.TempDir(), 0)
+		require.NoError(t, err)
+		outside := t.TempDir()
+		src := t.TempDir()
+		require.NoError(t, os.Symlink(outside, filepath.Join(src, "escape")))
+		_, err = store.Save("job", "evil", src)
+		require.NoError(t, err)
+
+		// A directory of the same name as the link was saved later
+		evil := fillDir(t, map[string]string{"escape/pwned": "x"})
+		_, err = store.Save("job", "evil-2", evil)
+		require.NoError(t, err)
+
+		dest := t.TempDir()
+		_, err = store.Restore("job", "evil", nil, dest)
+		require.NoError(t, err)
+		_, err = store.Restore("job", "evil-2", nil, dest)
+		assert.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(outside, "pwned"))
+	})
+}
@@ -7,7 +7,8 @@ import (
 	"log"
 
 	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	// The modernc.org/sqlite driver the server uses; it includes FTS5
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
@@ -24,7 +25,7 @@ func MigrateDB(dbPath string, migrations FS) error {
 		return fmt.Errorf("error creating migration source: %w", err)
 	}
 
-	dbDSN := fmt.Sprintf("sqlite3://%s", dbPath)
+	dbDSN := fmt.Sprintf("sqlite://%s", dbPath)
 	m, err := migrate.NewWithSourceInstance("iofs", d, dbDSN)
 	if err != nil {
 		return fmt.Errorf("error creating migrate instance: %w", err)
@@ -51,7 +52,7 @@ func RollbackDB(dbPath string, migrations FS) error {
 		return fmt.Errorf("error creating migration source: %w", err)
 	}
 
-	dbDSN := fmt.Sprintf("sqlite3://%s", dbPath)
+	dbDSN := fmt.Sprintf("sqlite://%s", dbPath)
 	m, err := migrate.NewWithSourceInstance("iofs", d, dbDSN)
 	if err != nil {
 		return fmt.Errorf("error creating migrate instance: %w", err)
@@ -78,7 +79,7 @@ func GetMigrationVersion(dbPath string, migrations FS) (uint, bool, error) {
 		return 0, false, fmt.Errorf("error creating migration source: %w", err)
 	}
 
-	dbDSN := fmt.Sprintf("sqlite3://%s", dbPath)
+	dbDSN := fmt.Sprintf("sqlite://%s", dbPath)
 	m, err := migrate.NewWithSourceInstance("iofs", d, dbDSN)
 	if err != nil {
 		return 0, false, fmt.Errorf("error creating migrate instance: %w", err)
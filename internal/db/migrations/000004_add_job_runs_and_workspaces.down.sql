@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_job_runs_status;
+DROP INDEX IF EXISTS idx_job_runs_job_id;
+DROP TABLE IF EXISTS job_runs;
+
+ALTER TABLE jobs DROP COLUMN plugin_config;
+ALTER TABLE jobs DROP COLUMN plugin;
@@ -0,0 +1,29 @@
+-- Plugin used to execute the job (e.g. "script", "http", "sql")
+ALTER TABLE jobs ADD COLUMN plugin TEXT;
+
+-- JSON-encoded plugin configuration
+ALTER TABLE jobs ADD COLUMN plugin_config TEXT;
+
+-- Each execution of a job is recorded as a run
+CREATE TABLE IF NOT EXISTS job_runs (
+  id TEXT PRIMARY KEY,
+  job_id TEXT NOT NULL,
+  -- Sequential run number per job, starting at 1
+  run_number INTEGER NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  exit_code INTEGER,
+  stdout TEXT,
+  stderr TEXT,
+  -- JSON-encoded plugin metadata and artifacts
+  metadata TEXT,
+  -- Workspace directory used by the run
+  workspace_path TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP,
+  FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE,
+  UNIQUE(job_id, run_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_id ON job_runs(job_id);
+CREATE INDEX IF NOT EXISTS idx_job_runs_status ON job_runs(status);
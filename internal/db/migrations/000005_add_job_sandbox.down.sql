@@ -0,0 +1,3 @@
+ALTER TABLE job_runs DROP COLUMN sandbox_profile;
+
+ALTER TABLE jobs DROP COLUMN sandbox;
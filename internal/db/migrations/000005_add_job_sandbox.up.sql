@@ -0,0 +1,5 @@
+-- Run the job's processes in an isolated sandbox
+ALTER TABLE jobs ADD COLUMN sandbox BOOLEAN NOT NULL DEFAULT 0;
+
+-- JSON-encoded sandbox profile applied to the run
+ALTER TABLE job_runs ADD COLUMN sandbox_profile TEXT;
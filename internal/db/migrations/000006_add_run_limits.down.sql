@@ -0,0 +1,5 @@
+ALTER TABLE job_runs DROP COLUMN failure_reason;
+
+ALTER TABLE job_runs DROP COLUMN limits;
+
+ALTER TABLE jobs DROP COLUMN limits;
@@ -0,0 +1,8 @@
+-- JSON-encoded resource limits requested by the job
+ALTER TABLE jobs ADD COLUMN limits TEXT;
+
+-- JSON-encoded effective limits of the run after server defaults and maximums
+ALTER TABLE job_runs ADD COLUMN limits TEXT;
+
+-- Why a failed run failed when it exceeded a limit (e.g. "timeout", "oom")
+ALTER TABLE job_runs ADD COLUMN failure_reason TEXT;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_job_runs_agent_id;
+
+ALTER TABLE job_runs DROP COLUMN lease_expires_at;
+
+ALTER TABLE job_runs DROP COLUMN agent_id;
+
+ALTER TABLE job_runs DROP COLUMN agent_labels;
+
+ALTER TABLE jobs DROP COLUMN agent_labels;
+
+DROP TABLE IF EXISTS agents;
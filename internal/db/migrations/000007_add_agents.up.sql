@@ -0,0 +1,25 @@
+-- Remote agents that pull runs from the server
+CREATE TABLE IF NOT EXISTS agents (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  -- JSON array of labels offered by the agent
+  labels TEXT NOT NULL DEFAULT '[]',
+  hostname TEXT,
+  version TEXT,
+  -- SHA-256 hex digest of the agent's access token
+  token_hash TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_heartbeat_at TIMESTAMP
+);
+
+-- JSON array of labels an agent must offer to run the job; NULL runs locally
+ALTER TABLE jobs ADD COLUMN agent_labels TEXT;
+
+-- Labels copied from the job when the run was queued for an agent
+ALTER TABLE job_runs ADD COLUMN agent_labels TEXT;
+
+-- Agent holding the run and until when its lease is valid
+ALTER TABLE job_runs ADD COLUMN agent_id TEXT REFERENCES agents(id) ON DELETE SET NULL;
+ALTER TABLE job_runs ADD COLUMN lease_expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_agent_id ON job_runs(agent_id);
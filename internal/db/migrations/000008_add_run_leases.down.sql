@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_job_runs_status_lease;
+
+ALTER TABLE job_runs DROP COLUMN attempt;
+
+ALTER TABLE job_runs DROP COLUMN lease_owner;
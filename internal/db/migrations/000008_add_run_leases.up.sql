@@ -0,0 +1,12 @@
+-- Executor or agent holding a running run. Local runs are claimed through
+-- the same lease as runs of remote agents, so that several servers can
+-- share one database.
+ALTER TABLE job_runs ADD COLUMN lease_owner TEXT;
+
+-- Number of times the run was claimed; runs whose lease expired are requeued
+ALTER TABLE job_runs ADD COLUMN attempt INTEGER NOT NULL DEFAULT 0;
+
+UPDATE job_runs SET lease_owner = agent_id, attempt = 1
+WHERE agent_id IS NOT NULL AND status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_status_lease ON job_runs(status, lease_owner);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_dead_letters_resolution;
+
+DROP TABLE IF EXISTS dead_letters;
+
+DROP INDEX IF EXISTS idx_job_run_attempts_run_id;
+
+DROP TABLE IF EXISTS job_run_attempts;
+
+ALTER TABLE jobs DROP COLUMN max_retries;
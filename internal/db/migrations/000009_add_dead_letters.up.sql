@@ -0,0 +1,38 @@
+-- How often a failed run is retried before it is dead-lettered
+ALTER TABLE jobs ADD COLUMN max_retries INTEGER NOT NULL DEFAULT 0;
+
+-- Outcome of every attempt of a run, including attempts that were retried
+-- or whose lease expired
+CREATE TABLE IF NOT EXISTS job_run_attempts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id TEXT NOT NULL REFERENCES job_runs(id) ON DELETE CASCADE,
+  attempt INTEGER NOT NULL,
+  status TEXT NOT NULL,
+  exit_code INTEGER,
+  error TEXT,
+  failure_reason TEXT,
+  lease_owner TEXT,
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_run_attempts_run_id ON job_run_attempts(run_id);
+
+-- Runs that kept failing after being retried, until someone requeues or
+-- discards them
+CREATE TABLE IF NOT EXISTS dead_letters (
+  id TEXT PRIMARY KEY,
+  run_id TEXT NOT NULL UNIQUE REFERENCES job_runs(id) ON DELETE CASCADE,
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  attempts INTEGER NOT NULL,
+  -- Error and failure reason of the last attempt
+  error TEXT,
+  failure_reason TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  -- requeued or discarded; NULL while the dead letter is open
+  resolution TEXT,
+  resolved_at TIMESTAMP,
+  requeued_run_id TEXT REFERENCES job_runs(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_resolution ON dead_letters(resolution);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_jobs_template_id;
+
+ALTER TABLE jobs DROP COLUMN template_params;
+
+ALTER TABLE jobs DROP COLUMN template_version;
+
+ALTER TABLE jobs DROP COLUMN template_id;
+
+DROP TABLE IF EXISTS job_template_versions;
+
+DROP TABLE IF EXISTS job_templates;
@@ -0,0 +1,31 @@
+-- Reusable job definitions with typed parameters
+CREATE TABLE IF NOT EXISTS job_templates (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  description TEXT,
+  owner_id TEXT REFERENCES users(id),
+  -- Version instantiated unless another one is asked for
+  latest_version INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every version of a template is kept, so that jobs created from an older
+-- version can be traced back to the definition they were rendered from
+CREATE TABLE IF NOT EXISTS job_template_versions (
+  template_id TEXT NOT NULL REFERENCES job_templates(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  -- JSON array of parameter declarations
+  parameters TEXT NOT NULL DEFAULT '[]',
+  -- JSON job definition with ${{ params.x }} placeholders
+  body TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (template_id, version)
+);
+
+-- Template version and parameters a job was rendered from
+ALTER TABLE jobs ADD COLUMN template_id TEXT REFERENCES job_templates(id) ON DELETE SET NULL;
+ALTER TABLE jobs ADD COLUMN template_version INTEGER;
+ALTER TABLE jobs ADD COLUMN template_params TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_template_id ON jobs(template_id);
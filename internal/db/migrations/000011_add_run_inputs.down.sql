@@ -0,0 +1,3 @@
+ALTER TABLE job_runs DROP COLUMN inputs;
+
+ALTER TABLE jobs DROP COLUMN input_schema;
@@ -0,0 +1,6 @@
+-- JSON Schema the inputs of a job's runs are validated against
+ALTER TABLE jobs ADD COLUMN input_schema TEXT;
+
+-- JSON object of inputs a run was started with, kept verbatim so the run
+-- can be replayed exactly
+ALTER TABLE job_runs ADD COLUMN inputs TEXT;
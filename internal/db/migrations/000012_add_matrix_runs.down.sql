@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_job_runs_parent_run_id;
+
+ALTER TABLE job_runs DROP COLUMN max_parallel;
+
+ALTER TABLE job_runs DROP COLUMN child_count;
+
+ALTER TABLE job_runs DROP COLUMN matrix_values;
+
+ALTER TABLE job_runs DROP COLUMN parent_run_id;
+
+ALTER TABLE jobs DROP COLUMN matrix;
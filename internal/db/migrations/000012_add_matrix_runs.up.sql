@@ -0,0 +1,15 @@
+-- Matrix a job's runs fan out over; see internal/matrix
+ALTER TABLE jobs ADD COLUMN matrix TEXT;
+
+-- A matrix run is a parent run with one child run per combination. The
+-- parent is never executed; its status aggregates the children.
+ALTER TABLE job_runs ADD COLUMN parent_run_id TEXT REFERENCES job_runs(id) ON DELETE CASCADE;
+-- JSON object of the matrix values of a child run
+ALTER TABLE job_runs ADD COLUMN matrix_values TEXT;
+-- Number of children of a parent run; 0 for every other run
+ALTER TABLE job_runs ADD COLUMN child_count INTEGER NOT NULL DEFAULT 0;
+-- Maximum number of running children of the parent of a child run; 0 for
+-- no limit
+ALTER TABLE job_runs ADD COLUMN max_parallel INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_parent_run_id ON job_runs(parent_run_id);
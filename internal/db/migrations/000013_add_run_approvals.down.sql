@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_job_runs_approval_expires_at;
+
+ALTER TABLE job_runs DROP COLUMN approval_expires_at;
+
+ALTER TABLE jobs DROP COLUMN approver_roles;
+
+ALTER TABLE jobs DROP COLUMN requires_approval;
@@ -0,0 +1,10 @@
+-- Runs of jobs requiring approval wait in the awaiting_approval status
+-- until a user with one of the approver roles approves or rejects them
+ALTER TABLE jobs ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT 0;
+-- JSON array of the user roles (users.role) allowed to decide
+ALTER TABLE jobs ADD COLUMN approver_roles TEXT;
+
+-- When a run awaiting approval expires; NULL once it was decided
+ALTER TABLE job_runs ADD COLUMN approval_expires_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_approval_expires_at ON job_runs(approval_expires_at);
@@ -0,0 +1,3 @@
+ALTER TABLE job_runs DROP COLUMN source_sha;
+
+ALTER TABLE jobs DROP COLUMN source;
@@ -0,0 +1,6 @@
+-- Repository checked out into the workspace before each run; JSON object
+-- with repository, ref and path, see internal/source
+ALTER TABLE jobs ADD COLUMN source TEXT;
+
+-- Commit the source ref resolved to for the run
+ALTER TABLE job_runs ADD COLUMN source_sha TEXT;
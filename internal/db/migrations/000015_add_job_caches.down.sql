@@ -0,0 +1,3 @@
+ALTER TABLE job_runs DROP COLUMN cache_results;
+
+ALTER TABLE jobs DROP COLUMN caches;
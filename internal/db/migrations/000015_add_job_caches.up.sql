@@ -0,0 +1,6 @@
+-- Directories restored before and saved after each run; JSON array of
+-- cache entries with path, key and restore_keys, see internal/cache
+ALTER TABLE jobs ADD COLUMN caches TEXT;
+
+-- JSON array reporting the hit or miss of every cache entry of the run
+ALTER TABLE job_runs ADD COLUMN cache_results TEXT;
@@ -0,0 +1,3 @@
+-- Interactive jobs run their process under a pseudo-terminal users can
+-- attach to
+ALTER TABLE jobs ADD COLUMN interactive BOOLEAN NOT NULL DEFAULT 0;
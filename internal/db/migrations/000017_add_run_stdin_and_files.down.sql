@@ -0,0 +1,5 @@
+ALTER TABLE job_runs DROP COLUMN input_files;
+
+ALTER TABLE job_runs DROP COLUMN stdin;
+
+ALTER TABLE attachments DROP COLUMN sha256;
@@ -0,0 +1,9 @@
+-- Checksum of the uploaded contents, verified when a run receives them
+ALTER TABLE attachments ADD COLUMN sha256 TEXT;
+
+-- Attachment streamed into the standard input of the run's processes; JSON
+-- object, see internal/attachments
+ALTER TABLE job_runs ADD COLUMN stdin TEXT;
+
+-- Attachments placed into the run's working directory; JSON array
+ALTER TABLE job_runs ADD COLUMN input_files TEXT;
@@ -0,0 +1,6 @@
+ALTER TABLE job_runs DROP COLUMN caught_up;
+ALTER TABLE job_runs DROP COLUMN scheduled_for;
+
+ALTER TABLE jobs DROP COLUMN schedule_queued_for;
+ALTER TABLE jobs DROP COLUMN schedule_checked_at;
+ALTER TABLE jobs DROP COLUMN schedule;
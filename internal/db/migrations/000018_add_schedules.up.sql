@@ -0,0 +1,15 @@
+-- Cron schedule starting runs of the job; JSON object, see internal/schedule
+ALTER TABLE jobs ADD COLUMN schedule TEXT;
+-- Time up to which the scheduler handled the schedule's fire times, so that
+-- fire times missed while no scheduler was running are found after a restart.
+-- NULL until the schedule is first checked and again whenever it changes.
+ALTER TABLE jobs ADD COLUMN schedule_checked_at TIMESTAMP;
+-- Fire time held back by the queue overlap policy until the job's run in
+-- flight finishes
+ALTER TABLE jobs ADD COLUMN schedule_queued_for TIMESTAMP;
+
+-- Fire time of the schedule the run was started or skipped for
+ALTER TABLE job_runs ADD COLUMN scheduled_for TIMESTAMP;
+-- Whether the run catches up on a fire time missed while no scheduler was
+-- running
+ALTER TABLE job_runs ADD COLUMN caught_up BOOLEAN NOT NULL DEFAULT 0;
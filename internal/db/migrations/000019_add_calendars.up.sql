@@ -0,0 +1,12 @@
+-- Blackout calendars: fire times of schedules naming a calendar are skipped
+-- while they fall into one of its windows. windows is a JSON array of
+-- one-off date ranges and recurring rules evaluated in timezone (UTC when
+-- NULL); see internal/schedule
+CREATE TABLE calendars (
+  name TEXT PRIMARY KEY,
+  description TEXT,
+  timezone TEXT,
+  windows TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
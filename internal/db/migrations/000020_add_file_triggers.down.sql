@@ -0,0 +1,3 @@
+DROP TABLE triggered_files;
+
+ALTER TABLE jobs DROP COLUMN file_trigger;
@@ -0,0 +1,17 @@
+-- Directory whose settled files start runs of the job; JSON object, see
+-- internal/watch
+ALTER TABLE jobs ADD COLUMN file_trigger TEXT;
+
+-- Files that started a run of a job, so that they are not picked up again
+-- after a restart. A file triggers again once its size or modification
+-- time changes.
+CREATE TABLE triggered_files (
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  path TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  -- Modification time in nanoseconds since the Unix epoch
+  mod_time INTEGER NOT NULL,
+  run_id TEXT REFERENCES job_runs(id) ON DELETE SET NULL,
+  triggered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (job_id, path)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE job_runs DROP COLUMN triggered_by_run_id;
+
+DROP TABLE job_triggers;
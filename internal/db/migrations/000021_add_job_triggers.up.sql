@@ -0,0 +1,16 @@
+-- Completion triggers: when a run of source_job_id finishes and the job's
+-- status becomes on_status ('complete', 'failed' or 'any'), a run of
+-- target_job_id is started with the outputs of the finished run
+CREATE TABLE job_triggers (
+  id TEXT PRIMARY KEY,
+  source_job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  target_job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  on_status TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (source_job_id, target_job_id, on_status)
+);
+CREATE INDEX idx_job_triggers_target_job_id ON job_triggers(target_job_id);
+
+-- Run whose completion started this run through a trigger; the chain of
+-- these links is how trigger loops are detected
+ALTER TABLE job_runs ADD COLUMN triggered_by_run_id TEXT REFERENCES job_runs(id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+DROP TABLE test_results;
+
+ALTER TABLE jobs DROP COLUMN test_reports;
@@ -0,0 +1,20 @@
+-- Paths of the JUnit XML reports a job's runs write; JSON array of globs
+-- relative to the run's working directory, see internal/testreport
+ALTER TABLE jobs ADD COLUMN test_reports TEXT;
+
+-- One row per test case found in the reports of a run. duration is in
+-- seconds; status is 'passed', 'failed', 'error' or 'skipped'
+CREATE TABLE test_results (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id TEXT NOT NULL REFERENCES job_runs(id) ON DELETE CASCADE,
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  suite TEXT NOT NULL,
+  name TEXT NOT NULL,
+  classname TEXT,
+  duration REAL NOT NULL DEFAULT 0,
+  status TEXT NOT NULL,
+  message TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_test_results_run_id ON test_results(run_id);
+CREATE INDEX idx_test_results_job_id ON test_results(job_id);
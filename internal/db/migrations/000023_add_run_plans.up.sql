@@ -0,0 +1,3 @@
+-- What the run's plugin did, as shown by dry runs: command line, working
+-- directory and environment with secrets masked; JSON, see plugin.Plan
+ALTER TABLE job_runs ADD COLUMN plan TEXT;
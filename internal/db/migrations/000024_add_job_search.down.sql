@@ -0,0 +1,8 @@
+DROP TRIGGER job_runs_search_delete;
+DROP TRIGGER job_runs_search_update;
+DROP TRIGGER job_runs_search_insert;
+DROP TRIGGER jobs_search_delete;
+DROP TRIGGER jobs_search_update;
+DROP TRIGGER jobs_search_insert;
+
+DROP TABLE job_search;
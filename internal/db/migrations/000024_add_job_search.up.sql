@@ -0,0 +1,59 @@
+-- Full-text index over jobs and the output of their finished runs. Each job
+-- has one row with its name and description (run_id NULL) and each finished
+-- run one row with its standard output and error; the triggers below keep
+-- it in sync. Output is indexed once a run finishes, not as it streams in.
+CREATE VIRTUAL TABLE job_search USING fts5(
+  job_id UNINDEXED,
+  run_id UNINDEXED,
+  run_number UNINDEXED,
+  name,
+  description,
+  output
+);
+
+CREATE TRIGGER jobs_search_insert AFTER INSERT ON jobs
+BEGIN
+  INSERT INTO job_search (job_id, name, description)
+  VALUES (new.id, new.name, new.description);
+END;
+
+CREATE TRIGGER jobs_search_update AFTER UPDATE OF name, description ON jobs
+BEGIN
+  UPDATE job_search SET name = new.name, description = new.description
+  WHERE job_id = old.id AND run_id IS NULL;
+END;
+
+CREATE TRIGGER jobs_search_delete AFTER DELETE ON jobs
+BEGIN
+  DELETE FROM job_search WHERE job_id = old.id;
+END;
+
+CREATE TRIGGER job_runs_search_insert AFTER INSERT ON job_runs
+WHEN new.finished_at IS NOT NULL
+BEGIN
+  INSERT INTO job_search (job_id, run_id, run_number, output)
+  VALUES (new.job_id, new.id, new.run_number, COALESCE(new.stdout, '') || char(10) || COALESCE(new.stderr, ''));
+END;
+
+CREATE TRIGGER job_runs_search_update AFTER UPDATE OF stdout, stderr, finished_at ON job_runs
+WHEN old.finished_at IS NOT NULL OR new.finished_at IS NOT NULL
+BEGIN
+  DELETE FROM job_search WHERE old.finished_at IS NOT NULL AND run_id = old.id;
+  INSERT INTO job_search (job_id, run_id, run_number, output)
+  SELECT new.job_id, new.id, new.run_number, COALESCE(new.stdout, '') || char(10) || COALESCE(new.stderr, '')
+  WHERE new.finished_at IS NOT NULL;
+END;
+
+CREATE TRIGGER job_runs_search_delete AFTER DELETE ON job_runs
+WHEN old.finished_at IS NOT NULL
+BEGIN
+  DELETE FROM job_search WHERE run_id = old.id;
+END;
+
+INSERT INTO job_search (job_id, name, description)
+SELECT id, name, description FROM jobs;
+
+INSERT INTO job_search (job_id, run_id, run_number, output)
+SELECT job_id, id, run_number, COALESCE(stdout, '') || char(10) || COALESCE(stderr, '')
+FROM job_runs
+WHERE finished_at IS NOT NULL;
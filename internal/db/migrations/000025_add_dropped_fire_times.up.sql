@@ -0,0 +1,4 @@
+-- Number of missed fire times a skipped run stands for when the scheduler
+-- dropped them for being older than the ones it handles at once; the run's
+-- scheduled_for is the most recent of them
+ALTER TABLE job_runs ADD COLUMN dropped_fire_times INTEGER NOT NULL DEFAULT 0;
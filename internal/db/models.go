@@ -19,6 +19,17 @@ type ActivityLog struct {
 	UserID     string
 }
 
+type Agent struct {
+	ID              string
+	Name            string
+	Labels          string
+	Hostname        sql.NullString
+	Version         sql.NullString
+	TokenHash       string
+	CreatedAt       time.Time
+	LastHeartbeatAt sql.NullTime
+}
+
 type Attachment struct {
 	ID        string
 	Filename  string
@@ -28,6 +39,16 @@ type Attachment struct {
 	CreatedAt time.Time
 	TaskID    sql.NullString
 	JobID     sql.NullString
+	Sha256    sql.NullString
+}
+
+type Calendar struct {
+	Name        string
+	Description sql.NullString
+	Timezone    sql.NullString
+	Windows     string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 type Comment struct {
@@ -39,16 +60,157 @@ type Comment struct {
 	TaskID    string
 }
 
+type DeadLetter struct {
+	ID            string
+	RunID         string
+	JobID         string
+	Attempts      int64
+	Error         sql.NullString
+	FailureReason sql.NullString
+	CreatedAt     time.Time
+	Resolution    sql.NullString
+	ResolvedAt    sql.NullTime
+	RequeuedRunID sql.NullString
+}
+
+type EnvSecret struct {
+	ID             interface{}
+	EnvVarID       int64
+	EncryptedValue interface{}
+	Iv             interface{}
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type EnvVar struct {
+	ID            interface{}
+	EnvironmentID int64
+	Key           string
+	Value         sql.NullString
+	IsSensitive   bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Environment struct {
+	ID        interface{}
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type Job struct {
+	ID                string
+	Name              string
+	Description       sql.NullString
+	Status            string
+	StartDate         sql.NullTime
+	EndDate           sql.NullTime
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	OwnerID           sql.NullString
+	Command           sql.NullString
+	Arguments         sql.NullString
+	Stdout            sql.NullString
+	Stderr            sql.NullString
+	Plugin            sql.NullString
+	PluginConfig      sql.NullString
+	Sandbox           bool
+	Limits            sql.NullString
+	AgentLabels       sql.NullString
+	MaxRetries        int64
+	TemplateID        sql.NullString
+	TemplateVersion   sql.NullInt64
+	TemplateParams    sql.NullString
+	InputSchema       sql.NullString
+	Matrix            sql.NullString
+	RequiresApproval  bool
+	ApproverRoles     sql.NullString
+	Source            sql.NullString
+	Caches            sql.NullString
+	Interactive       bool
+	Schedule          sql.NullString
+	ScheduleCheckedAt sql.NullTime
+	ScheduleQueuedFor sql.NullTime
+	FileTrigger       sql.NullString
+	TestReports       sql.NullString
+}
+
+type JobRun struct {
+	ID                string
+	JobID             string
+	RunNumber         int64
+	Status            string
+	ExitCode          sql.NullInt64
+	Stdout            sql.NullString
+	Stderr            sql.NullString
+	Metadata          sql.NullString
+	WorkspacePath     sql.NullString
+	CreatedAt         time.Time
+	StartedAt         sql.NullTime
+	FinishedAt        sql.NullTime
+	SandboxProfile    sql.NullString
+	Limits            sql.NullString
+	FailureReason     sql.NullString
+	AgentLabels       sql.NullString
+	AgentID           sql.NullString
+	LeaseExpiresAt    sql.NullTime
+	LeaseOwner        sql.NullString
+	Attempt           int64
+	Inputs            sql.NullString
+	ParentRunID       sql.NullString
+	MatrixValues      sql.NullString
+	ChildCount        int64
+	MaxParallel       int64
+	ApprovalExpiresAt sql.NullTime
+	SourceSha         sql.NullString
+	CacheResults      sql.NullString
+	Stdin             sql.NullString
+	InputFiles        sql.NullString
+	ScheduledFor      sql.NullTime
+	CaughtUp          bool
+	TriggeredByRunID  sql.NullString
+	Plan              sql.NullString
+	DroppedFireTimes  int64
+}
+
+type JobRunAttempt struct {
+	ID            int64
+	RunID         string
+	Attempt       int64
+	Status        string
+	ExitCode      sql.NullInt64
+	Error         sql.NullString
+	FailureReason sql.NullString
+	LeaseOwner    sql.NullString
+	StartedAt     sql.NullTime
+	FinishedAt    time.Time
+}
+
+type JobTemplate struct {
+	ID            string
+	Name          string
+	Description   sql.NullString
+	OwnerID       sql.NullString
+	LatestVersion int64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type JobTemplateVersion struct {
+	TemplateID string
+	Version    int64
+	Parameters string
+	Body       string
+	CreatedAt  time.Time
+}
+
+type JobTrigger struct {
 	ID          string
-	Name        string
-	Description sql.NullString
-	Status      string
-	StartDate   sql.NullTime
-	EndDate     sql.NullTime
+	SourceJobID string
+	TargetJobID string
+	OnStatus    string
 	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	OwnerID     sql.NullString
 }
 
 type Notification struct {
@@ -75,6 +237,28 @@ type Task struct {
 	JobID       sql.NullString
 }
 
+type TestResult struct {
+	ID        int64
+	RunID     string
+	JobID     string
+	Suite     string
+	Name      string
+	Classname sql.NullString
+	Duration  float64
+	Status    string
+	Message   sql.NullString
+	CreatedAt time.Time
+}
+
+type TriggeredFile struct {
+	JobID       string
+	Path        string
+	Size        int64
+	ModTime     int64
+	RunID       sql.NullString
+	TriggeredAt time.Time
+}
+
 type User struct {
 	ID           string
 	Username     string
@@ -10,6 +10,364 @@ import (
 	"database/sql"
 )
 
+const advanceJobSchedule = `-- name: AdvanceJobSchedule :execrows
+UPDATE jobs
+SET schedule_checked_at = ?
+WHERE id = ? AND schedule = ?
+  AND schedule_checked_at IS ?
+`
+
+type AdvanceJobScheduleParams struct {
+	CheckedAt sql.NullTime
+	ID        string
+	Schedule  sql.NullString
+	Previous  sql.NullTime
+}
+
+func (q *Queries) AdvanceJobSchedule(ctx context.Context, arg AdvanceJobScheduleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, advanceJobSchedule,
+		arg.CheckedAt,
+		arg.ID,
+		arg.Schedule,
+		arg.Previous,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const appendJobRunLogs = `-- name: AppendJobRunLogs :execrows
+UPDATE job_runs
+SET
+  stdout = COALESCE(stdout, '') || ?,
+  stderr = COALESCE(stderr, '') || ?
+WHERE id = ? AND agent_id = ? AND status = 'running'
+`
+
+type AppendJobRunLogsParams struct {
+	Stdout  interface{}
+	Stderr  interface{}
+	ID      string
+	AgentID string
+}
+
+func (q *Queries) AppendJobRunLogs(ctx context.Context, arg AppendJobRunLogsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, appendJobRunLogs,
+		arg.Stdout,
+		arg.Stderr,
+		arg.ID,
+		arg.AgentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const cancelChildRuns = `-- name: CancelChildRuns :many
+UPDATE job_runs
+SET
+  status = 'cancelled',
+  finished_at = CURRENT_TIMESTAMP
+WHERE parent_run_id = ? AND finished_at IS NULL
+RETURNING id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times
+`
+
+func (q *Queries) CancelChildRuns(ctx context.Context, parentRunID sql.NullString) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, cancelChildRuns, parentRunID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const cancelJobRuns = `-- name: CancelJobRuns :many
+UPDATE job_runs
+SET
+  status = 'cancelled',
+  finished_at = CURRENT_TIMESTAMP
+WHERE job_id = ? AND finished_at IS NULL
+RETURNING id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times
+`
+
+func (q *Queries) CancelJobRuns(ctx context.Context, jobID string) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, cancelJobRuns, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimJobRun = `-- name: ClaimJobRun :one
+UPDATE job_runs
+SET
+  status = ?,
+  lease_owner = ?,
+  agent_id = ?,
+  lease_expires_at = ?,
+  limits = ?,
+  attempt = attempt + 1,
+  started_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'pending' AND lease_owner IS NULL
+  AND child_count = 0
+  AND (max_parallel = 0 OR (
+    SELECT COUNT(*) FROM job_runs AS sibling
+    WHERE sibling.parent_run_id = job_runs.parent_run_id AND sibling.status = 'running'
+  ) < max_parallel)
+RETURNING id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times
+`
+
+type ClaimJobRunParams struct {
+	Status         string
+	LeaseOwner     sql.NullString
+	AgentID        sql.NullString
+	LeaseExpiresAt sql.NullTime
+	Limits         sql.NullString
+	ID             string
+}
+
+func (q *Queries) ClaimJobRun(ctx context.Context, arg ClaimJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, claimJobRun,
+		arg.Status,
+		arg.LeaseOwner,
+		arg.AgentID,
+		arg.LeaseExpiresAt,
+		arg.Limits,
+		arg.ID,
+	)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.RunNumber,
+		&i.Status,
+		&i.ExitCode,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Metadata,
+		&i.WorkspacePath,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.SandboxProfile,
+		&i.Limits,
+		&i.FailureReason,
+		&i.AgentLabels,
+		&i.AgentID,
+		&i.LeaseExpiresAt,
+		&i.LeaseOwner,
+		&i.Attempt,
+		&i.Inputs,
+		&i.ParentRunID,
+		&i.MatrixValues,
+		&i.ChildCount,
+		&i.MaxParallel,
+		&i.ApprovalExpiresAt,
+		&i.SourceSha,
+		&i.CacheResults,
+		&i.Stdin,
+		&i.InputFiles,
+		&i.ScheduledFor,
+		&i.CaughtUp,
+		&i.TriggeredByRunID,
+		&i.Plan,
+		&i.DroppedFireTimes,
+	)
+	return i, err
+}
+
+const claimTriggeredFile = `-- name: ClaimTriggeredFile :one
+INSERT INTO triggered_files (
+  job_id, path, size, mod_time
+) VALUES (
+  ?, ?, ?, ?
+)
+ON CONFLICT (job_id, path) DO UPDATE SET
+  size = excluded.size,
+  mod_time = excluded.mod_time,
+  run_id = NULL,
+  triggered_at = CURRENT_TIMESTAMP
+WHERE triggered_files.size != excluded.size OR triggered_files.mod_time != excluded.mod_time
+RETURNING job_id, path, size, mod_time, run_id, triggered_at
+`
+
+type ClaimTriggeredFileParams struct {
+	JobID   string
+	Path    string
+	Size    int64
+	ModTime int64
+}
+
+func (q *Queries) ClaimTriggeredFile(ctx context.Context, arg ClaimTriggeredFileParams) (TriggeredFile, error) {
+	row := q.db.QueryRowContext(ctx, claimTriggeredFile,
+		arg.JobID,
+		arg.Path,
+		arg.Size,
+		arg.ModTime,
+	)
+	var i TriggeredFile
+	err := row.Scan(
+		&i.JobID,
+		&i.Path,
+		&i.Size,
+		&i.ModTime,
+		&i.RunID,
+		&i.TriggeredAt,
+	)
+	return i, err
+}
+
+const countActiveJobRuns = `-- name: CountActiveJobRuns :one
+SELECT COUNT(*) FROM job_runs
+WHERE job_id = ? AND parent_run_id IS NULL AND finished_at IS NULL
+`
+
+func (q *Queries) CountActiveJobRuns(ctx context.Context, jobID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveJobRuns, jobID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countJobsUsingCalendar = `-- name: CountJobsUsingCalendar :one
+SELECT COUNT(*) FROM jobs
+WHERE schedule IS NOT NULL
+  AND EXISTS (SELECT 1 FROM json_each(jobs.schedule, '$.calendars') WHERE value = ?)
+`
+
+func (q *Queries) CountJobsUsingCalendar(ctx context.Context, name string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countJobsUsingCalendar, name)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countJobTemplates = `-- name: CountJobTemplates :one
+SELECT COUNT(*) FROM job_templates
+`
+
+func (q *Queries) CountJobTemplates(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countJobTemplates)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOpenDeadLetters = `-- name: CountOpenDeadLetters :one
+SELECT COUNT(*) FROM dead_letters
+WHERE resolution IS NULL
+`
+
+func (q *Queries) CountOpenDeadLetters(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOpenDeadLetters)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createActivityLog = `-- name: CreateActivityLog :one
 INSERT INTO activity_logs (
   id, action, entity_type, entity_id, details, user_id
@@ -50,13 +408,54 @@ func (q *Queries) CreateActivityLog(ctx context.Context, arg CreateActivityLogPa
 	return i, err
 }
 
+const createAgent = `-- name: CreateAgent :one
+INSERT INTO agents (
+  id, name, labels, hostname, version, token_hash
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING id, name, labels, hostname, version, token_hash, created_at, last_heartbeat_at
+`
+
+type CreateAgentParams struct {
+	ID        string
+	Name      string
+	Labels    string
+	Hostname  sql.NullString
+	Version   sql.NullString
+	TokenHash string
+}
+
+func (q *Queries) CreateAgent(ctx context.Context, arg CreateAgentParams) (Agent, error) {
+	row := q.db.QueryRowContext(ctx, createAgent,
+		arg.ID,
+		arg.Name,
+		arg.Labels,
+		arg.Hostname,
+		arg.Version,
+		arg.TokenHash,
+	)
+	var i Agent
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Labels,
+		&i.Hostname,
+		&i.Version,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.LastHeartbeatAt,
+	)
+	return i, err
+}
+
 const createAttachment = `-- name: CreateAttachment :one
 INSERT INTO attachments (
-  id, filename, file_path, file_size, mime_type, task_id, job_id
+  id, filename, file_path, file_size, mime_type, task_id, job_id, sha256
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, filename, file_path, file_size, mime_type, created_at, task_id, job_id
+RETURNING id, filename, file_path, file_size, mime_type, created_at, task_id, job_id, sha256
 `
 
 type CreateAttachmentParams struct {
@@ -67,6 +466,7 @@ type CreateAttachmentParams struct {
 	MimeType string
 	TaskID   sql.NullString
 	JobID    sql.NullString
+	Sha256   sql.NullString
 }
 
 func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error) {
@@ -78,6 +478,7 @@ func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentPara
 		arg.MimeType,
 		arg.TaskID,
 		arg.JobID,
+		arg.Sha256,
 	)
 	var i Attachment
 	err := row.Scan(
@@ -89,6 +490,42 @@ func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentPara
 		&i.CreatedAt,
 		&i.TaskID,
 		&i.JobID,
+		&i.Sha256,
+	)
+	return i, err
+}
+
+const createCalendar = `-- name: CreateCalendar :one
+INSERT INTO calendars (
+  name, description, timezone, windows
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING name, description, timezone, windows, created_at, updated_at
+`
+
+type CreateCalendarParams struct {
+	Name        string
+	Description sql.NullString
+	Timezone    sql.NullString
+	Windows     string
+}
+
+func (q *Queries) CreateCalendar(ctx context.Context, arg CreateCalendarParams) (Calendar, error) {
+	row := q.db.QueryRowContext(ctx, createCalendar,
+		arg.Name,
+		arg.Description,
+		arg.Timezone,
+		arg.Windows,
+	)
+	var i Calendar
+	err := row.Scan(
+		&i.Name,
+		&i.Description,
+		&i.Timezone,
+		&i.Windows,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -128,23 +565,87 @@ func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (C
 	return i, err
 }
 
+const createDeadLetter = `-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (
+  id, run_id, job_id, attempts, error, failure_reason
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING id, run_id, job_id, attempts, error, failure_reason, created_at, resolution, resolved_at, requeued_run_id
+`
+
+type CreateDeadLetterParams struct {
+	ID            string
+	RunID         string
+	JobID         string
+	Attempts      int64
+	Error         sql.NullString
+	FailureReason sql.NullString
+}
+
+func (q *Queries) CreateDeadLetter(ctx context.Context, arg CreateDeadLetterParams) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, createDeadLetter,
+		arg.ID,
+		arg.RunID,
+		arg.JobID,
+		arg.Attempts,
+		arg.Error,
+		arg.FailureReason,
+	)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.RunID,
+		&i.JobID,
+		&i.Attempts,
+		&i.Error,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.Resolution,
+		&i.ResolvedAt,
+		&i.RequeuedRunID,
+	)
+	return i, err
+}
+
 const createJob = `-- name: CreateJob :one
 INSERT INTO jobs (
-  id, name, description, status, start_date, end_date, owner_id
+  id, name, description, status, start_date, end_date, owner_id, plugin, plugin_config, sandbox, limits, agent_labels, max_retries,
+  template_id, template_version, template_params, input_schema, matrix, requires_approval, approver_roles,
+  source, caches, interactive, schedule, file_trigger, test_reports
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, name, description, status, start_date, end_date, created_at, updated_at, owner_id
+RETURNING id, name, description, status, start_date, end_date, created_at, updated_at, owner_id, command, arguments, stdout, stderr, plugin, plugin_config, sandbox, limits, agent_labels, max_retries, template_id, template_version, template_params, input_schema, matrix, requires_approval, approver_roles, source, caches, interactive, schedule, schedule_checked_at, schedule_queued_for, file_trigger, test_reports
 `
 
 type CreateJobParams struct {
-	ID          string
-	Name        string
-	Description sql.NullString
-	Status      string
-	StartDate   sql.NullTime
-	EndDate     sql.NullTime
-	OwnerID     sql.NullString
+	ID               string
+	Name             string
+	Description      sql.NullString
+	Status           string
+	StartDate        sql.NullTime
+	EndDate          sql.NullTime
+	OwnerID          sql.NullString
+	Plugin           sql.NullString
+	PluginConfig     sql.NullString
+	Sandbox          bool
+	Limits           sql.NullString
+	AgentLabels      sql.NullString
+	MaxRetries       int64
+	TemplateID       sql.NullString
+	TemplateVersion  sql.NullInt64
+	TemplateParams   sql.NullString
+	InputSchema      sql.NullString
+	Matrix           sql.NullString
+	RequiresApproval bool
+	ApproverRoles    sql.NullString
+	Source           sql.NullString
+	Caches           sql.NullString
+	Interactive      bool
+	Schedule         sql.NullString
+	FileTrigger      sql.NullString
+	TestReports      sql.NullString
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
@@ -156,6 +657,25 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		arg.StartDate,
 		arg.EndDate,
 		arg.OwnerID,
+		arg.Plugin,
+		arg.PluginConfig,
+		arg.Sandbox,
+		arg.Limits,
+		arg.AgentLabels,
+		arg.MaxRetries,
+		arg.TemplateID,
+		arg.TemplateVersion,
+		arg.TemplateParams,
+		arg.InputSchema,
+		arg.Matrix,
+		arg.RequiresApproval,
+		arg.ApproverRoles,
+		arg.Source,
+		arg.Caches,
+		arg.Interactive,
+		arg.Schedule,
+		arg.FileTrigger,
+		arg.TestReports,
 	)
 	var i Job
 	err := row.Scan(
@@ -168,83 +688,405 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.OwnerID,
+		&i.Command,
+		&i.Arguments,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Plugin,
+		&i.PluginConfig,
+		&i.Sandbox,
+		&i.Limits,
+		&i.AgentLabels,
+		&i.MaxRetries,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.TemplateParams,
+		&i.InputSchema,
+		&i.Matrix,
+		&i.RequiresApproval,
+		&i.ApproverRoles,
+		&i.Source,
+		&i.Caches,
+		&i.Interactive,
+		&i.Schedule,
+		&i.ScheduleCheckedAt,
+		&i.ScheduleQueuedFor,
+		&i.FileTrigger,
+		&i.TestReports,
 	)
 	return i, err
 }
 
-const createNotification = `-- name: CreateNotification :one
-INSERT INTO notifications (
-  id, type, content, user_id, reference_id, reference_type
+const createJobRun = `-- name: CreateJobRun :one
+INSERT INTO job_runs (
+  id, job_id, run_number, status, agent_labels, inputs,
+  parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at,
+  stdin, input_files, scheduled_for, caught_up, triggered_by_run_id
 ) VALUES (
-  ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, type, content, is_read, created_at, user_id, reference_id, reference_type
+RETURNING id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times
 `
 
-type CreateNotificationParams struct {
-	ID            string
-	Type          string
-	Content       string
-	UserID        string
-	ReferenceID   sql.NullString
-	ReferenceType sql.NullString
+type CreateJobRunParams struct {
+	ID                string
+	JobID             string
+	RunNumber         int64
+	Status            string
+	AgentLabels       sql.NullString
+	Inputs            sql.NullString
+	ParentRunID       sql.NullString
+	MatrixValues      sql.NullString
+	ChildCount        int64
+	MaxParallel       int64
+	ApprovalExpiresAt sql.NullTime
+	Stdin             sql.NullString
+	InputFiles        sql.NullString
+	ScheduledFor      sql.NullTime
+	CaughtUp          bool
+	TriggeredByRunID  sql.NullString
 }
 
-func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
-	row := q.db.QueryRowContext(ctx, createNotification,
+func (q *Queries) CreateJobRun(ctx context.Context, arg CreateJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, createJobRun,
 		arg.ID,
-		arg.Type,
-		arg.Content,
-		arg.UserID,
-		arg.ReferenceID,
-		arg.ReferenceType,
+		arg.JobID,
+		arg.RunNumber,
+		arg.Status,
+		arg.AgentLabels,
+		arg.Inputs,
+		arg.ParentRunID,
+		arg.MatrixValues,
+		arg.ChildCount,
+		arg.MaxParallel,
+		arg.ApprovalExpiresAt,
+		arg.Stdin,
+		arg.InputFiles,
+		arg.ScheduledFor,
+		arg.CaughtUp,
+		arg.TriggeredByRunID,
 	)
-	var i Notification
+	var i JobRun
 	err := row.Scan(
 		&i.ID,
-		&i.Type,
-		&i.Content,
-		&i.IsRead,
+		&i.JobID,
+		&i.RunNumber,
+		&i.Status,
+		&i.ExitCode,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Metadata,
+		&i.WorkspacePath,
 		&i.CreatedAt,
-		&i.UserID,
-		&i.ReferenceID,
-		&i.ReferenceType,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.SandboxProfile,
+		&i.Limits,
+		&i.FailureReason,
+		&i.AgentLabels,
+		&i.AgentID,
+		&i.LeaseExpiresAt,
+		&i.LeaseOwner,
+		&i.Attempt,
+		&i.Inputs,
+		&i.ParentRunID,
+		&i.MatrixValues,
+		&i.ChildCount,
+		&i.MaxParallel,
+		&i.ApprovalExpiresAt,
+		&i.SourceSha,
+		&i.CacheResults,
+		&i.Stdin,
+		&i.InputFiles,
+		&i.ScheduledFor,
+		&i.CaughtUp,
+		&i.TriggeredByRunID,
+		&i.Plan,
+		&i.DroppedFireTimes,
 	)
 	return i, err
 }
 
-const createTask = `-- name: CreateTask :one
-INSERT INTO tasks (
-  id, title, description, status, priority, due_date, user_id, job_id
+const createJobRunAttempt = `-- name: CreateJobRunAttempt :exec
+INSERT INTO job_run_attempts (
+  run_id, attempt, status, exit_code, error, failure_reason, lease_owner, started_at
 ) VALUES (
   ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, title, description, status, priority, due_date, created_at, updated_at, user_id, job_id
 `
 
-type CreateTaskParams struct {
+type CreateJobRunAttemptParams struct {
+	RunID         string
+	Attempt       int64
+	Status        string
+	ExitCode      sql.NullInt64
+	Error         sql.NullString
+	FailureReason sql.NullString
+	LeaseOwner    sql.NullString
+	StartedAt     sql.NullTime
+}
+
+func (q *Queries) CreateJobRunAttempt(ctx context.Context, arg CreateJobRunAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, createJobRunAttempt,
+		arg.RunID,
+		arg.Attempt,
+		arg.Status,
+		arg.ExitCode,
+		arg.Error,
+		arg.FailureReason,
+		arg.LeaseOwner,
+		arg.StartedAt,
+	)
+	return err
+}
+
+const createJobTemplate = `-- name: CreateJobTemplate :one
+INSERT INTO job_templates (
+  id, name, description, owner_id
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING id, name, description, owner_id, latest_version, created_at, updated_at
+`
+
+type CreateJobTemplateParams struct {
 	ID          string
-	Title       string
+	Name        string
 	Description sql.NullString
-	Status      string
-	Priority    sql.NullString
-	DueDate     sql.NullTime
-	UserID      sql.NullString
-	JobID       sql.NullString
+	OwnerID     sql.NullString
 }
 
-func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
-	row := q.db.QueryRowContext(ctx, createTask,
+func (q *Queries) CreateJobTemplate(ctx context.Context, arg CreateJobTemplateParams) (JobTemplate, error) {
+	row := q.db.QueryRowContext(ctx, createJobTemplate,
 		arg.ID,
-		arg.Title,
+		arg.Name,
 		arg.Description,
-		arg.Status,
-		arg.Priority,
-		arg.DueDate,
-		arg.UserID,
-		arg.JobID,
+		arg.OwnerID,
 	)
-	var i Task
+	var i JobTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.LatestVersion,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createJobTemplateVersion = `-- name: CreateJobTemplateVersion :one
+INSERT INTO job_template_versions (
+  template_id, version, parameters, body
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING template_id, version, parameters, body, created_at
+`
+
+type CreateJobTemplateVersionParams struct {
+	TemplateID string
+	Version    int64
+	Parameters string
+	Body       string
+}
+
+func (q *Queries) CreateJobTemplateVersion(ctx context.Context, arg CreateJobTemplateVersionParams) (JobTemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, createJobTemplateVersion,
+		arg.TemplateID,
+		arg.Version,
+		arg.Parameters,
+		arg.Body,
+	)
+	var i JobTemplateVersion
+	err := row.Scan(
+		&i.TemplateID,
+		&i.Version,
+		&i.Parameters,
+		&i.Body,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createJobTrigger = `-- name: CreateJobTrigger :one
+INSERT INTO job_triggers (
+  id, source_job_id, target_job_id, on_status
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING id, source_job_id, target_job_id, on_status, created_at
+`
+
+type CreateJobTriggerParams struct {
+	ID          string
+	SourceJobID string
+	TargetJobID string
+	OnStatus    string
+}
+
+func (q *Queries) CreateJobTrigger(ctx context.Context, arg CreateJobTriggerParams) (JobTrigger, error) {
+	row := q.db.QueryRowContext(ctx, createJobTrigger,
+		arg.ID,
+		arg.SourceJobID,
+		arg.TargetJobID,
+		arg.OnStatus,
+	)
+	var i JobTrigger
+	err := row.Scan(
+		&i.ID,
+		&i.SourceJobID,
+		&i.TargetJobID,
+		&i.OnStatus,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createNotification = `-- name: CreateNotification :one
+INSERT INTO notifications (
+  id, type, content, user_id, reference_id, reference_type
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING id, type, content, is_read, created_at, user_id, reference_id, reference_type
+`
+
+type CreateNotificationParams struct {
+	ID            string
+	Type          string
+	Content       string
+	UserID        string
+	ReferenceID   sql.NullString
+	ReferenceType sql.NullString
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
+	row := q.db.QueryRowContext(ctx, createNotification,
+		arg.ID,
+		arg.Type,
+		arg.Content,
+		arg.UserID,
+		arg.ReferenceID,
+		arg.ReferenceType,
+	)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Content,
+		&i.IsRead,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ReferenceID,
+		&i.ReferenceType,
+	)
+	return i, err
+}
+
+const createSkippedJobRun = `-- name: CreateSkippedJobRun :one
+INSERT INTO job_runs (
+  id, job_id, run_number, status, failure_reason, scheduled_for, caught_up, dropped_fire_times, finished_at
+) VALUES (
+  ?, ?, ?, 'skipped', ?, ?, ?, ?, CURRENT_TIMESTAMP
+)
+RETURNING id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times
+`
+
+type CreateSkippedJobRunParams struct {
+	ID               string
+	JobID            string
+	RunNumber        int64
+	FailureReason    sql.NullString
+	ScheduledFor     sql.NullTime
+	CaughtUp         bool
+	DroppedFireTimes int64
+}
+
+func (q *Queries) CreateSkippedJobRun(ctx context.Context, arg CreateSkippedJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, createSkippedJobRun,
+		arg.ID,
+		arg.JobID,
+		arg.RunNumber,
+		arg.FailureReason,
+		arg.ScheduledFor,
+		arg.CaughtUp,
+		arg.DroppedFireTimes,
+	)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.RunNumber,
+		&i.Status,
+		&i.ExitCode,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Metadata,
+		&i.WorkspacePath,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.SandboxProfile,
+		&i.Limits,
+		&i.FailureReason,
+		&i.AgentLabels,
+		&i.AgentID,
+		&i.LeaseExpiresAt,
+		&i.LeaseOwner,
+		&i.Attempt,
+		&i.Inputs,
+		&i.ParentRunID,
+		&i.MatrixValues,
+		&i.ChildCount,
+		&i.MaxParallel,
+		&i.ApprovalExpiresAt,
+		&i.SourceSha,
+		&i.CacheResults,
+		&i.Stdin,
+		&i.InputFiles,
+		&i.ScheduledFor,
+		&i.CaughtUp,
+		&i.TriggeredByRunID,
+		&i.Plan,
+		&i.DroppedFireTimes,
+	)
+	return i, err
+}
+
+const createTask = `-- name: CreateTask :one
+INSERT INTO tasks (
+  id, title, description, status, priority, due_date, user_id, job_id
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, title, description, status, priority, due_date, created_at, updated_at, user_id, job_id
+`
+
+type CreateTaskParams struct {
+	ID          string
+	Title       string
+	Description sql.NullString
+	Status      string
+	Priority    sql.NullString
+	DueDate     sql.NullTime
+	UserID      sql.NullString
+	JobID       sql.NullString
+}
+
+func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, createTask,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.Status,
+		arg.Priority,
+		arg.DueDate,
+		arg.UserID,
+		arg.JobID,
+	)
+	var i Task
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
@@ -260,6 +1102,39 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 	return i, err
 }
 
+const createTestResult = `-- name: CreateTestResult :exec
+INSERT INTO test_results (
+  run_id, job_id, suite, name, classname, duration, status, message
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateTestResultParams struct {
+	RunID     string
+	JobID     string
+	Suite     string
+	Name      string
+	Classname sql.NullString
+	Duration  float64
+	Status    string
+	Message   sql.NullString
+}
+
+func (q *Queries) CreateTestResult(ctx context.Context, arg CreateTestResultParams) error {
+	_, err := q.db.ExecContext(ctx, createTestResult,
+		arg.RunID,
+		arg.JobID,
+		arg.Suite,
+		arg.Name,
+		arg.Classname,
+		arg.Duration,
+		arg.Status,
+		arg.Message,
+	)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   id, username, email, password_hash, full_name, role
@@ -302,6 +1177,39 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const decideJobRun = `-- name: DecideJobRun :execrows
+UPDATE job_runs
+SET
+  status = ?,
+  approval_expires_at = NULL,
+  finished_at = ?
+WHERE CAST(? AS TEXT) IN (id, parent_run_id) AND status = 'awaiting_approval'
+`
+
+type DecideJobRunParams struct {
+	Status     string
+	FinishedAt sql.NullTime
+	ID         string
+}
+
+func (q *Queries) DecideJobRun(ctx context.Context, arg DecideJobRunParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, decideJobRun, arg.Status, arg.FinishedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAgent = `-- name: DeleteAgent :exec
+DELETE FROM agents
+WHERE id = ?
+`
+
+func (q *Queries) DeleteAgent(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteAgent, id)
+	return err
+}
+
 const deleteAttachment = `-- name: DeleteAttachment :exec
 DELETE FROM attachments
 WHERE id = ?
@@ -312,6 +1220,19 @@ func (q *Queries) DeleteAttachment(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteCalendar = `-- name: DeleteCalendar :execrows
+DELETE FROM calendars
+WHERE name = ?
+`
+
+func (q *Queries) DeleteCalendar(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCalendar, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteComment = `-- name: DeleteComment :exec
 DELETE FROM comments
 WHERE id = ?
@@ -332,6 +1253,16 @@ func (q *Queries) DeleteJob(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteJobTrigger = `-- name: DeleteJobTrigger :exec
+DELETE FROM job_triggers
+WHERE id = ?
+`
+
+func (q *Queries) DeleteJobTrigger(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteJobTrigger, id)
+	return err
+}
+
 const deleteNotification = `-- name: DeleteNotification :exec
 DELETE FROM notifications
 WHERE id = ?
@@ -352,6 +1283,31 @@ func (q *Queries) DeleteTask(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteTestResultsByRun = `-- name: DeleteTestResultsByRun :exec
+DELETE FROM test_results
+WHERE run_id = ?
+`
+
+func (q *Queries) DeleteTestResultsByRun(ctx context.Context, runID string) error {
+	_, err := q.db.ExecContext(ctx, deleteTestResultsByRun, runID)
+	return err
+}
+
+const deleteTriggeredFile = `-- name: DeleteTriggeredFile :exec
+DELETE FROM triggered_files
+WHERE job_id = ? AND path = ?
+`
+
+type DeleteTriggeredFileParams struct {
+	JobID string
+	Path  string
+}
+
+func (q *Queries) DeleteTriggeredFile(ctx context.Context, arg DeleteTriggeredFileParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTriggeredFile, arg.JobID, arg.Path)
+	return err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = ?
@@ -362,8 +1318,127 @@ func (q *Queries) DeleteUser(ctx context.Context, id string) error {
 	return err
 }
 
+const finishJobRun = `-- name: FinishJobRun :one
+UPDATE job_runs
+SET
+  status = ?,
+  exit_code = ?,
+  stdout = ?,
+  stderr = ?,
+  metadata = ?,
+  failure_reason = ?,
+  finished_at = CURRENT_TIMESTAMP
+WHERE id = ? AND lease_owner IS ? AND finished_at IS NULL
+RETURNING id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times
+`
+
+type FinishJobRunParams struct {
+	Status        string
+	ExitCode      sql.NullInt64
+	Stdout        sql.NullString
+	Stderr        sql.NullString
+	Metadata      sql.NullString
+	FailureReason sql.NullString
+	ID            string
+	LeaseOwner    sql.NullString
+}
+
+func (q *Queries) FinishJobRun(ctx context.Context, arg FinishJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, finishJobRun,
+		arg.Status,
+		arg.ExitCode,
+		arg.Stdout,
+		arg.Stderr,
+		arg.Metadata,
+		arg.FailureReason,
+		arg.ID,
+		arg.LeaseOwner,
+	)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.RunNumber,
+		&i.Status,
+		&i.ExitCode,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Metadata,
+		&i.WorkspacePath,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.SandboxProfile,
+		&i.Limits,
+		&i.FailureReason,
+		&i.AgentLabels,
+		&i.AgentID,
+		&i.LeaseExpiresAt,
+		&i.LeaseOwner,
+		&i.Attempt,
+		&i.Inputs,
+		&i.ParentRunID,
+		&i.MatrixValues,
+		&i.ChildCount,
+		&i.MaxParallel,
+		&i.ApprovalExpiresAt,
+		&i.SourceSha,
+		&i.CacheResults,
+		&i.Stdin,
+		&i.InputFiles,
+		&i.ScheduledFor,
+		&i.CaughtUp,
+		&i.TriggeredByRunID,
+		&i.Plan,
+		&i.DroppedFireTimes,
+	)
+	return i, err
+}
+
+const getAgent = `-- name: GetAgent :one
+SELECT id, name, labels, hostname, version, token_hash, created_at, last_heartbeat_at FROM agents
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetAgent(ctx context.Context, id string) (Agent, error) {
+	row := q.db.QueryRowContext(ctx, getAgent, id)
+	var i Agent
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Labels,
+		&i.Hostname,
+		&i.Version,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.LastHeartbeatAt,
+	)
+	return i, err
+}
+
+const getAgentByTokenHash = `-- name: GetAgentByTokenHash :one
+SELECT id, name, labels, hostname, version, token_hash, created_at, last_heartbeat_at FROM agents
+WHERE token_hash = ? LIMIT 1
+`
+
+func (q *Queries) GetAgentByTokenHash(ctx context.Context, tokenHash string) (Agent, error) {
+	row := q.db.QueryRowContext(ctx, getAgentByTokenHash, tokenHash)
+	var i Agent
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Labels,
+		&i.Hostname,
+		&i.Version,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.LastHeartbeatAt,
+	)
+	return i, err
+}
+
 const getAttachment = `-- name: GetAttachment :one
-SELECT id, filename, file_path, file_size, mime_type, created_at, task_id, job_id FROM attachments
+SELECT id, filename, file_path, file_size, mime_type, created_at, task_id, job_id, sha256 FROM attachments
 WHERE id = ? LIMIT 1
 `
 
@@ -379,6 +1454,26 @@ func (q *Queries) GetAttachment(ctx context.Context, id string) (Attachment, err
 		&i.CreatedAt,
 		&i.TaskID,
 		&i.JobID,
+		&i.Sha256,
+	)
+	return i, err
+}
+
+const getCalendar = `-- name: GetCalendar :one
+SELECT name, description, timezone, windows, created_at, updated_at FROM calendars
+WHERE name = ? LIMIT 1
+`
+
+func (q *Queries) GetCalendar(ctx context.Context, name string) (Calendar, error) {
+	row := q.db.QueryRowContext(ctx, getCalendar, name)
+	var i Calendar
+	err := row.Scan(
+		&i.Name,
+		&i.Description,
+		&i.Timezone,
+		&i.Windows,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -402,8 +1497,31 @@ func (q *Queries) GetComment(ctx context.Context, id string) (Comment, error) {
 	return i, err
 }
 
+const getDeadLetter = `-- name: GetDeadLetter :one
+SELECT id, run_id, job_id, attempts, error, failure_reason, created_at, resolution, resolved_at, requeued_run_id FROM dead_letters
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetDeadLetter(ctx context.Context, id string) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, getDeadLetter, id)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.RunID,
+		&i.JobID,
+		&i.Attempts,
+		&i.Error,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.Resolution,
+		&i.ResolvedAt,
+		&i.RequeuedRunID,
+	)
+	return i, err
+}
+
 const getJob = `-- name: GetJob :one
-SELECT id, name, description, status, start_date, end_date, created_at, updated_at, owner_id FROM jobs
+SELECT id, name, description, status, start_date, end_date, created_at, updated_at, owner_id, command, arguments, stdout, stderr, plugin, plugin_config, sandbox, limits, agent_labels, max_retries, template_id, template_version, template_params, input_schema, matrix, requires_approval, approver_roles, source, caches, interactive, schedule, schedule_checked_at, schedule_queued_for, file_trigger, test_reports FROM jobs
 WHERE id = ? LIMIT 1
 `
 
@@ -420,21 +1538,220 @@ func (q *Queries) GetJob(ctx context.Context, id string) (Job, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.OwnerID,
+		&i.Command,
+		&i.Arguments,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Plugin,
+		&i.PluginConfig,
+		&i.Sandbox,
+		&i.Limits,
+		&i.AgentLabels,
+		&i.MaxRetries,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.TemplateParams,
+		&i.InputSchema,
+		&i.Matrix,
+		&i.RequiresApproval,
+		&i.ApproverRoles,
+		&i.Source,
+		&i.Caches,
+		&i.Interactive,
+		&i.Schedule,
+		&i.ScheduleCheckedAt,
+		&i.ScheduleQueuedFor,
+		&i.FileTrigger,
+		&i.TestReports,
 	)
 	return i, err
 }
 
-const getNotification = `-- name: GetNotification :one
-SELECT id, type, content, is_read, created_at, user_id, reference_id, reference_type FROM notifications
+const getJobRun = `-- name: GetJobRun :one
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
 WHERE id = ? LIMIT 1
 `
 
-func (q *Queries) GetNotification(ctx context.Context, id string) (Notification, error) {
-	row := q.db.QueryRowContext(ctx, getNotification, id)
-	var i Notification
+func (q *Queries) GetJobRun(ctx context.Context, id string) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, getJobRun, id)
+	var i JobRun
 	err := row.Scan(
 		&i.ID,
-		&i.Type,
+		&i.JobID,
+		&i.RunNumber,
+		&i.Status,
+		&i.ExitCode,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Metadata,
+		&i.WorkspacePath,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.SandboxProfile,
+		&i.Limits,
+		&i.FailureReason,
+		&i.AgentLabels,
+		&i.AgentID,
+		&i.LeaseExpiresAt,
+		&i.LeaseOwner,
+		&i.Attempt,
+		&i.Inputs,
+		&i.ParentRunID,
+		&i.MatrixValues,
+		&i.ChildCount,
+		&i.MaxParallel,
+		&i.ApprovalExpiresAt,
+		&i.SourceSha,
+		&i.CacheResults,
+		&i.Stdin,
+		&i.InputFiles,
+		&i.ScheduledFor,
+		&i.CaughtUp,
+		&i.TriggeredByRunID,
+		&i.Plan,
+		&i.DroppedFireTimes,
+	)
+	return i, err
+}
+
+const getJobRunByNumber = `-- name: GetJobRunByNumber :one
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
+WHERE job_id = ? AND run_number = ? LIMIT 1
+`
+
+type GetJobRunByNumberParams struct {
+	JobID     string
+	RunNumber int64
+}
+
+func (q *Queries) GetJobRunByNumber(ctx context.Context, arg GetJobRunByNumberParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, getJobRunByNumber, arg.JobID, arg.RunNumber)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.RunNumber,
+		&i.Status,
+		&i.ExitCode,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Metadata,
+		&i.WorkspacePath,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.SandboxProfile,
+		&i.Limits,
+		&i.FailureReason,
+		&i.AgentLabels,
+		&i.AgentID,
+		&i.LeaseExpiresAt,
+		&i.LeaseOwner,
+		&i.Attempt,
+		&i.Inputs,
+		&i.ParentRunID,
+		&i.MatrixValues,
+		&i.ChildCount,
+		&i.MaxParallel,
+		&i.ApprovalExpiresAt,
+		&i.SourceSha,
+		&i.CacheResults,
+		&i.Stdin,
+		&i.InputFiles,
+		&i.ScheduledFor,
+		&i.CaughtUp,
+		&i.TriggeredByRunID,
+		&i.Plan,
+		&i.DroppedFireTimes,
+	)
+	return i, err
+}
+
+const getJobTemplate = `-- name: GetJobTemplate :one
+SELECT id, name, description, owner_id, latest_version, created_at, updated_at FROM job_templates
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetJobTemplate(ctx context.Context, id string) (JobTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getJobTemplate, id)
+	var i JobTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.LatestVersion,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getJobTemplateVersion = `-- name: GetJobTemplateVersion :one
+SELECT template_id, version, parameters, body, created_at FROM job_template_versions
+WHERE template_id = ? AND version = ? LIMIT 1
+`
+
+type GetJobTemplateVersionParams struct {
+	TemplateID string
+	Version    int64
+}
+
+func (q *Queries) GetJobTemplateVersion(ctx context.Context, arg GetJobTemplateVersionParams) (JobTemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getJobTemplateVersion, arg.TemplateID, arg.Version)
+	var i JobTemplateVersion
+	err := row.Scan(
+		&i.TemplateID,
+		&i.Version,
+		&i.Parameters,
+		&i.Body,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getJobTrigger = `-- name: GetJobTrigger :one
+SELECT id, source_job_id, target_job_id, on_status, created_at FROM job_triggers
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetJobTrigger(ctx context.Context, id string) (JobTrigger, error) {
+	row := q.db.QueryRowContext(ctx, getJobTrigger, id)
+	var i JobTrigger
+	err := row.Scan(
+		&i.ID,
+		&i.SourceJobID,
+		&i.TargetJobID,
+		&i.OnStatus,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getNextRunNumber = `-- name: GetNextRunNumber :one
+SELECT CAST(COALESCE(MAX(run_number), 0) + 1 AS INTEGER) AS next_run_number FROM job_runs
+WHERE job_id = ?
+`
+
+func (q *Queries) GetNextRunNumber(ctx context.Context, jobID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getNextRunNumber, jobID)
+	var nextRunNumber int64
+	err := row.Scan(&nextRunNumber)
+	return nextRunNumber, err
+}
+
+const getNotification = `-- name: GetNotification :one
+SELECT id, type, content, is_read, created_at, user_id, reference_id, reference_type FROM notifications
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetNotification(ctx context.Context, id string) (Notification, error) {
+	row := q.db.QueryRowContext(ctx, getNotification, id)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
 		&i.Content,
 		&i.IsRead,
 		&i.CreatedAt,
@@ -628,8 +1945,45 @@ func (q *Queries) ListActivityLogsByUser(ctx context.Context, arg ListActivityLo
 	return items, nil
 }
 
+const listAgents = `-- name: ListAgents :many
+SELECT id, name, labels, hostname, version, token_hash, created_at, last_heartbeat_at FROM agents
+ORDER BY name, id
+`
+
+func (q *Queries) ListAgents(ctx context.Context) ([]Agent, error) {
+	rows, err := q.db.QueryContext(ctx, listAgents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Agent
+	for rows.Next() {
+		var i Agent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Labels,
+			&i.Hostname,
+			&i.Version,
+			&i.TokenHash,
+			&i.CreatedAt,
+			&i.LastHeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAttachmentsByJob = `-- name: ListAttachmentsByJob :many
-SELECT id, filename, file_path, file_size, mime_type, created_at, task_id, job_id FROM attachments
+SELECT id, filename, file_path, file_size, mime_type, created_at, task_id, job_id, sha256 FROM attachments
 WHERE job_id = ?
 ORDER BY created_at DESC
 `
@@ -642,16 +1996,507 @@ func (q *Queries) ListAttachmentsByJob(ctx context.Context, jobID sql.NullString
 	defer rows.Close()
 	var items []Attachment
 	for rows.Next() {
-		var i Attachment
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.CreatedAt,
+			&i.TaskID,
+			&i.JobID,
+			&i.Sha256,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAttachmentsByTask = `-- name: ListAttachmentsByTask :many
+SELECT id, filename, file_path, file_size, mime_type, created_at, task_id, job_id, sha256 FROM attachments
+WHERE task_id = ?
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListAttachmentsByTask(ctx context.Context, taskID sql.NullString) ([]Attachment, error) {
+	rows, err := q.db.QueryContext(ctx, listAttachmentsByTask, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.CreatedAt,
+			&i.TaskID,
+			&i.JobID,
+			&i.Sha256,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCalendars = `-- name: ListCalendars :many
+SELECT name, description, timezone, windows, created_at, updated_at FROM calendars
+ORDER BY name
+`
+
+func (q *Queries) ListCalendars(ctx context.Context) ([]Calendar, error) {
+	rows, err := q.db.QueryContext(ctx, listCalendars)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Calendar
+	for rows.Next() {
+		var i Calendar
+		if err := rows.Scan(
+			&i.Name,
+			&i.Description,
+			&i.Timezone,
+			&i.Windows,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChildRuns = `-- name: ListChildRuns :many
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
+WHERE parent_run_id = ?
+ORDER BY run_number
+`
+
+func (q *Queries) ListChildRuns(ctx context.Context, parentRunID sql.NullString) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listChildRuns, parentRunID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsByTask = `-- name: ListCommentsByTask :many
+SELECT id, content, created_at, updated_at, user_id, task_id FROM comments
+WHERE task_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?
+`
+
+type ListCommentsByTaskParams struct {
+	TaskID string
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) ListCommentsByTask(ctx context.Context, arg ListCommentsByTaskParams) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, listCommentsByTask, arg.TaskID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.TaskID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredApprovals = `-- name: ListExpiredApprovals :many
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
+WHERE status = 'awaiting_approval' AND parent_run_id IS NULL AND approval_expires_at < ?
+`
+
+func (q *Queries) ListExpiredApprovals(ctx context.Context, approvalExpiresAt sql.NullTime) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredApprovals, approvalExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredJobRuns = `-- name: ListExpiredJobRuns :many
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
+WHERE status = 'running' AND lease_owner IS NOT NULL AND lease_expires_at < ?
+`
+
+func (q *Queries) ListExpiredJobRuns(ctx context.Context, leaseExpiresAt sql.NullTime) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredJobRuns, leaseExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFileTriggers = `-- name: ListFileTriggers :many
+SELECT id, file_trigger FROM jobs
+WHERE file_trigger IS NOT NULL
+`
+
+type ListFileTriggersRow struct {
+	ID          string
+	FileTrigger sql.NullString
+}
+
+func (q *Queries) ListFileTriggers(ctx context.Context) ([]ListFileTriggersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFileTriggers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFileTriggersRow
+	for rows.Next() {
+		var i ListFileTriggersRow
+		if err := rows.Scan(&i.ID, &i.FileTrigger); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobRunAttempts = `-- name: ListJobRunAttempts :many
+SELECT id, run_id, attempt, status, exit_code, error, failure_reason, lease_owner, started_at, finished_at FROM job_run_attempts
+WHERE run_id = ?
+ORDER BY attempt, id
+`
+
+func (q *Queries) ListJobRunAttempts(ctx context.Context, runID string) ([]JobRunAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, listJobRunAttempts, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRunAttempt
+	for rows.Next() {
+		var i JobRunAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.Attempt,
+			&i.Status,
+			&i.ExitCode,
+			&i.Error,
+			&i.FailureReason,
+			&i.LeaseOwner,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobRunsByJob = `-- name: ListJobRunsByJob :many
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
+WHERE job_id = ?
+ORDER BY run_number DESC
+LIMIT ? OFFSET ?
+`
+
+type ListJobRunsByJobParams struct {
+	JobID  string
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) ListJobRunsByJob(ctx context.Context, arg ListJobRunsByJobParams) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listJobRunsByJob, arg.JobID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobTemplateVersions = `-- name: ListJobTemplateVersions :many
+SELECT template_id, version, parameters, body, created_at FROM job_template_versions
+WHERE template_id = ?
+ORDER BY version DESC
+`
+
+func (q *Queries) ListJobTemplateVersions(ctx context.Context, templateID string) ([]JobTemplateVersion, error) {
+	rows, err := q.db.QueryContext(ctx, listJobTemplateVersions, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobTemplateVersion
+	for rows.Next() {
+		var i JobTemplateVersion
 		if err := rows.Scan(
-			&i.ID,
-			&i.Filename,
-			&i.FilePath,
-			&i.FileSize,
-			&i.MimeType,
+			&i.TemplateID,
+			&i.Version,
+			&i.Parameters,
+			&i.Body,
 			&i.CreatedAt,
-			&i.TaskID,
-			&i.JobID,
 		); err != nil {
 			return nil, err
 		}
@@ -666,30 +2511,34 @@ func (q *Queries) ListAttachmentsByJob(ctx context.Context, jobID sql.NullString
 	return items, nil
 }
 
-const listAttachmentsByTask = `-- name: ListAttachmentsByTask :many
-SELECT id, filename, file_path, file_size, mime_type, created_at, task_id, job_id FROM attachments
-WHERE task_id = ?
-ORDER BY created_at DESC
+const listJobTemplates = `-- name: ListJobTemplates :many
+SELECT id, name, description, owner_id, latest_version, created_at, updated_at FROM job_templates
+ORDER BY name
+LIMIT ? OFFSET ?
 `
 
-func (q *Queries) ListAttachmentsByTask(ctx context.Context, taskID sql.NullString) ([]Attachment, error) {
-	rows, err := q.db.QueryContext(ctx, listAttachmentsByTask, taskID)
+type ListJobTemplatesParams struct {
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) ListJobTemplates(ctx context.Context, arg ListJobTemplatesParams) ([]JobTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, listJobTemplates, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Attachment
+	var items []JobTemplate
 	for rows.Next() {
-		var i Attachment
+		var i JobTemplate
 		if err := rows.Scan(
 			&i.ID,
-			&i.Filename,
-			&i.FilePath,
-			&i.FileSize,
-			&i.MimeType,
+			&i.Name,
+			&i.Description,
+			&i.OwnerID,
+			&i.LatestVersion,
 			&i.CreatedAt,
-			&i.TaskID,
-			&i.JobID,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -704,35 +2553,27 @@ func (q *Queries) ListAttachmentsByTask(ctx context.Context, taskID sql.NullStri
 	return items, nil
 }
 
-const listCommentsByTask = `-- name: ListCommentsByTask :many
-SELECT id, content, created_at, updated_at, user_id, task_id FROM comments
-WHERE task_id = ?
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
+const listJobTriggersBySource = `-- name: ListJobTriggersBySource :many
+SELECT id, source_job_id, target_job_id, on_status, created_at FROM job_triggers
+WHERE source_job_id = ?
+ORDER BY created_at, id
 `
 
-type ListCommentsByTaskParams struct {
-	TaskID string
-	Limit  int64
-	Offset int64
-}
-
-func (q *Queries) ListCommentsByTask(ctx context.Context, arg ListCommentsByTaskParams) ([]Comment, error) {
-	rows, err := q.db.QueryContext(ctx, listCommentsByTask, arg.TaskID, arg.Limit, arg.Offset)
+func (q *Queries) ListJobTriggersBySource(ctx context.Context, sourceJobID string) ([]JobTrigger, error) {
+	rows, err := q.db.QueryContext(ctx, listJobTriggersBySource, sourceJobID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Comment
+	var items []JobTrigger
 	for rows.Next() {
-		var i Comment
+		var i JobTrigger
 		if err := rows.Scan(
 			&i.ID,
-			&i.Content,
+			&i.SourceJobID,
+			&i.TargetJobID,
+			&i.OnStatus,
 			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.UserID,
-			&i.TaskID,
 		); err != nil {
 			return nil, err
 		}
@@ -748,7 +2589,7 @@ func (q *Queries) ListCommentsByTask(ctx context.Context, arg ListCommentsByTask
 }
 
 const listJobs = `-- name: ListJobs :many
-SELECT id, name, description, status, start_date, end_date, created_at, updated_at, owner_id FROM jobs
+SELECT id, name, description, status, start_date, end_date, created_at, updated_at, owner_id, command, arguments, stdout, stderr, plugin, plugin_config, sandbox, limits, agent_labels, max_retries, template_id, template_version, template_params, input_schema, matrix, requires_approval, approver_roles, source, caches, interactive, schedule, schedule_checked_at, schedule_queued_for, file_trigger, test_reports FROM jobs
 ORDER BY created_at DESC
 LIMIT ? OFFSET ?
 `
@@ -777,6 +2618,31 @@ func (q *Queries) ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, erro
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.OwnerID,
+			&i.Command,
+			&i.Arguments,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Plugin,
+			&i.PluginConfig,
+			&i.Sandbox,
+			&i.Limits,
+			&i.AgentLabels,
+			&i.MaxRetries,
+			&i.TemplateID,
+			&i.TemplateVersion,
+			&i.TemplateParams,
+			&i.InputSchema,
+			&i.Matrix,
+			&i.RequiresApproval,
+			&i.ApproverRoles,
+			&i.Source,
+			&i.Caches,
+			&i.Interactive,
+			&i.Schedule,
+			&i.ScheduleCheckedAt,
+			&i.ScheduleQueuedFor,
+			&i.FileTrigger,
+			&i.TestReports,
 		); err != nil {
 			return nil, err
 		}
@@ -792,7 +2658,7 @@ func (q *Queries) ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, erro
 }
 
 const listJobsByOwner = `-- name: ListJobsByOwner :many
-SELECT id, name, description, status, start_date, end_date, created_at, updated_at, owner_id FROM jobs
+SELECT id, name, description, status, start_date, end_date, created_at, updated_at, owner_id, command, arguments, stdout, stderr, plugin, plugin_config, sandbox, limits, agent_labels, max_retries, template_id, template_version, template_params, input_schema, matrix, requires_approval, approver_roles, source, caches, interactive, schedule, schedule_checked_at, schedule_queued_for, file_trigger, test_reports FROM jobs
 WHERE owner_id = ?
 ORDER BY created_at DESC
 LIMIT ? OFFSET ?
@@ -823,6 +2689,31 @@ func (q *Queries) ListJobsByOwner(ctx context.Context, arg ListJobsByOwnerParams
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.OwnerID,
+			&i.Command,
+			&i.Arguments,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Plugin,
+			&i.PluginConfig,
+			&i.Sandbox,
+			&i.Limits,
+			&i.AgentLabels,
+			&i.MaxRetries,
+			&i.TemplateID,
+			&i.TemplateVersion,
+			&i.TemplateParams,
+			&i.InputSchema,
+			&i.Matrix,
+			&i.RequiresApproval,
+			&i.ApproverRoles,
+			&i.Source,
+			&i.Caches,
+			&i.Interactive,
+			&i.Schedule,
+			&i.ScheduleCheckedAt,
+			&i.ScheduleQueuedFor,
+			&i.FileTrigger,
+			&i.TestReports,
 		); err != nil {
 			return nil, err
 		}
@@ -882,6 +2773,235 @@ func (q *Queries) ListNotificationsByUser(ctx context.Context, arg ListNotificat
 	return items, nil
 }
 
+const listOpenDeadLetters = `-- name: ListOpenDeadLetters :many
+SELECT id, run_id, job_id, attempts, error, failure_reason, created_at, resolution, resolved_at, requeued_run_id FROM dead_letters
+WHERE resolution IS NULL
+ORDER BY created_at DESC, rowid DESC
+LIMIT ? OFFSET ?
+`
+
+type ListOpenDeadLettersParams struct {
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) ListOpenDeadLetters(ctx context.Context, arg ListOpenDeadLettersParams) ([]DeadLetter, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenDeadLetters, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeadLetter
+	for rows.Next() {
+		var i DeadLetter
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.JobID,
+			&i.Attempts,
+			&i.Error,
+			&i.FailureReason,
+			&i.CreatedAt,
+			&i.Resolution,
+			&i.ResolvedAt,
+			&i.RequeuedRunID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueuedAgentRuns = `-- name: ListQueuedAgentRuns :many
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
+WHERE status = 'pending' AND agent_labels IS NOT NULL AND lease_owner IS NULL
+  AND child_count = 0
+  AND (max_parallel = 0 OR (
+    SELECT COUNT(*) FROM job_runs AS sibling
+    WHERE sibling.parent_run_id = job_runs.parent_run_id AND sibling.status = 'running'
+  ) < max_parallel)
+ORDER BY created_at, rowid
+LIMIT ?
+`
+
+func (q *Queries) ListQueuedAgentRuns(ctx context.Context, limit int64) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listQueuedAgentRuns, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueuedLocalRuns = `-- name: ListQueuedLocalRuns :many
+SELECT id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times FROM job_runs
+WHERE status = 'pending' AND agent_labels IS NULL AND lease_owner IS NULL
+  AND child_count = 0
+  AND (max_parallel = 0 OR (
+    SELECT COUNT(*) FROM job_runs AS sibling
+    WHERE sibling.parent_run_id = job_runs.parent_run_id AND sibling.status = 'running'
+  ) < max_parallel)
+ORDER BY created_at, rowid
+LIMIT ?
+`
+
+func (q *Queries) ListQueuedLocalRuns(ctx context.Context, limit int64) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listQueuedLocalRuns, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.RunNumber,
+			&i.Status,
+			&i.ExitCode,
+			&i.Stdout,
+			&i.Stderr,
+			&i.Metadata,
+			&i.WorkspacePath,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.SandboxProfile,
+			&i.Limits,
+			&i.FailureReason,
+			&i.AgentLabels,
+			&i.AgentID,
+			&i.LeaseExpiresAt,
+			&i.LeaseOwner,
+			&i.Attempt,
+			&i.Inputs,
+			&i.ParentRunID,
+			&i.MatrixValues,
+			&i.ChildCount,
+			&i.MaxParallel,
+			&i.ApprovalExpiresAt,
+			&i.SourceSha,
+			&i.CacheResults,
+			&i.Stdin,
+			&i.InputFiles,
+			&i.ScheduledFor,
+			&i.CaughtUp,
+			&i.TriggeredByRunID,
+			&i.Plan,
+			&i.DroppedFireTimes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledJobs = `-- name: ListScheduledJobs :many
+SELECT id, schedule, schedule_checked_at, schedule_queued_for FROM jobs
+WHERE schedule IS NOT NULL
+ORDER BY id
+`
+
+type ListScheduledJobsRow struct {
+	ID                string
+	Schedule          sql.NullString
+	ScheduleCheckedAt sql.NullTime
+	ScheduleQueuedFor sql.NullTime
+}
+
+func (q *Queries) ListScheduledJobs(ctx context.Context) ([]ListScheduledJobsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListScheduledJobsRow
+	for rows.Next() {
+		var i ListScheduledJobsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Schedule,
+			&i.ScheduleCheckedAt,
+			&i.ScheduleQueuedFor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTasks = `-- name: ListTasks :many
 SELECT id, title, description, status, priority, due_date, created_at, updated_at, user_id, job_id FROM tasks
 ORDER BY created_at DESC
@@ -1021,39 +3141,170 @@ func (q *Queries) ListTasksByStatus(ctx context.Context, arg ListTasksByStatusPa
 	return items, nil
 }
 
-const listTasksByUser = `-- name: ListTasksByUser :many
-SELECT id, title, description, status, priority, due_date, created_at, updated_at, user_id, job_id FROM tasks
-WHERE user_id = ?
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
+const listTasksByUser = `-- name: ListTasksByUser :many
+SELECT id, title, description, status, priority, due_date, created_at, updated_at, user_id, job_id FROM tasks
+WHERE user_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?
+`
+
+type ListTasksByUserParams struct {
+	UserID sql.NullString
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) ListTasksByUser(ctx context.Context, arg ListTasksByUserParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listTasksByUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.JobID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTestResultsByJob = `-- name: ListTestResultsByJob :many
+SELECT job_runs.run_number, test_results.suite, test_results.name, test_results.classname,
+  test_results.duration, test_results.status, test_results.message
+FROM test_results
+JOIN job_runs ON job_runs.id = test_results.run_id
+WHERE test_results.job_id = ? AND job_runs.run_number >= ?
+ORDER BY job_runs.run_number, test_results.suite, test_results.name
+`
+
+type ListTestResultsByJobParams struct {
+	JobID     string
+	RunNumber int64
+}
+
+type ListTestResultsByJobRow struct {
+	RunNumber int64
+	Suite     string
+	Name      string
+	Classname sql.NullString
+	Duration  float64
+	Status    string
+	Message   sql.NullString
+}
+
+func (q *Queries) ListTestResultsByJob(ctx context.Context, arg ListTestResultsByJobParams) ([]ListTestResultsByJobRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTestResultsByJob, arg.JobID, arg.RunNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTestResultsByJobRow
+	for rows.Next() {
+		var i ListTestResultsByJobRow
+		if err := rows.Scan(
+			&i.RunNumber,
+			&i.Suite,
+			&i.Name,
+			&i.Classname,
+			&i.Duration,
+			&i.Status,
+			&i.Message,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTestResultsByRun = `-- name: ListTestResultsByRun :many
+SELECT id, run_id, job_id, suite, name, classname, duration, status, message, created_at FROM test_results
+WHERE run_id = ?
+ORDER BY suite, name, id
+`
+
+func (q *Queries) ListTestResultsByRun(ctx context.Context, runID string) ([]TestResult, error) {
+	rows, err := q.db.QueryContext(ctx, listTestResultsByRun, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TestResult
+	for rows.Next() {
+		var i TestResult
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.JobID,
+			&i.Suite,
+			&i.Name,
+			&i.Classname,
+			&i.Duration,
+			&i.Status,
+			&i.Message,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTriggeredFiles = `-- name: ListTriggeredFiles :many
+SELECT job_id, path, size, mod_time, run_id, triggered_at FROM triggered_files
+WHERE job_id = ?
+ORDER BY triggered_at DESC, path
 `
 
-type ListTasksByUserParams struct {
-	UserID sql.NullString
-	Limit  int64
-	Offset int64
-}
-
-func (q *Queries) ListTasksByUser(ctx context.Context, arg ListTasksByUserParams) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listTasksByUser, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) ListTriggeredFiles(ctx context.Context, jobID string) ([]TriggeredFile, error) {
+	rows, err := q.db.QueryContext(ctx, listTriggeredFiles, jobID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Task
+	var items []TriggeredFile
 	for rows.Next() {
-		var i Task
+		var i TriggeredFile
 		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.Description,
-			&i.Status,
-			&i.Priority,
-			&i.DueDate,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.UserID,
 			&i.JobID,
+			&i.Path,
+			&i.Size,
+			&i.ModTime,
+			&i.RunID,
+			&i.TriggeredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1191,6 +3442,453 @@ func (q *Queries) MarkNotificationAsRead(ctx context.Context, id string) (Notifi
 	return i, err
 }
 
+const recordAgentHeartbeat = `-- name: RecordAgentHeartbeat :exec
+UPDATE agents
+SET last_heartbeat_at = ?
+WHERE id = ?
+`
+
+type RecordAgentHeartbeatParams struct {
+	LastHeartbeatAt sql.NullTime
+	ID              string
+}
+
+func (q *Queries) RecordAgentHeartbeat(ctx context.Context, arg RecordAgentHeartbeatParams) error {
+	_, err := q.db.ExecContext(ctx, recordAgentHeartbeat, arg.LastHeartbeatAt, arg.ID)
+	return err
+}
+
+const renewJobRunLease = `-- name: RenewJobRunLease :execrows
+UPDATE job_runs
+SET lease_expires_at = ?
+WHERE id = ? AND lease_owner = ? AND status = 'running'
+`
+
+type RenewJobRunLeaseParams struct {
+	LeaseExpiresAt sql.NullTime
+	ID             string
+	LeaseOwner     sql.NullString
+}
+
+func (q *Queries) RenewJobRunLease(ctx context.Context, arg RenewJobRunLeaseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, renewJobRunLease, arg.LeaseExpiresAt, arg.ID, arg.LeaseOwner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renewLeases = `-- name: RenewLeases :execrows
+UPDATE job_runs
+SET lease_expires_at = ?
+WHERE lease_owner = ? AND status = 'running'
+`
+
+type RenewLeasesParams struct {
+	LeaseExpiresAt sql.NullTime
+	LeaseOwner     sql.NullString
+}
+
+func (q *Queries) RenewLeases(ctx context.Context, arg RenewLeasesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, renewLeases, arg.LeaseExpiresAt, arg.LeaseOwner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const requeueJobRun = `-- name: RequeueJobRun :execrows
+UPDATE job_runs
+SET
+  status = 'pending',
+  lease_owner = NULL,
+  agent_id = NULL,
+  lease_expires_at = NULL,
+  workspace_path = NULL,
+  sandbox_profile = NULL,
+  stdout = NULL,
+  stderr = NULL,
+  started_at = NULL
+WHERE id = ? AND lease_owner = ? AND status = 'running' AND lease_expires_at < ?
+`
+
+type RequeueJobRunParams struct {
+	ID             string
+	LeaseOwner     sql.NullString
+	LeaseExpiresAt sql.NullTime
+}
+
+func (q *Queries) RequeueJobRun(ctx context.Context, arg RequeueJobRunParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requeueJobRun, arg.ID, arg.LeaseOwner, arg.LeaseExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resolveDeadLetter = `-- name: ResolveDeadLetter :one
+UPDATE dead_letters
+SET
+  resolution = ?,
+  requeued_run_id = ?,
+  resolved_at = CURRENT_TIMESTAMP
+WHERE id = ? AND resolution IS NULL
+RETURNING id, run_id, job_id, attempts, error, failure_reason, created_at, resolution, resolved_at, requeued_run_id
+`
+
+type ResolveDeadLetterParams struct {
+	Resolution    sql.NullString
+	RequeuedRunID sql.NullString
+	ID            string
+}
+
+func (q *Queries) ResolveDeadLetter(ctx context.Context, arg ResolveDeadLetterParams) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, resolveDeadLetter, arg.Resolution, arg.RequeuedRunID, arg.ID)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.RunID,
+		&i.JobID,
+		&i.Attempts,
+		&i.Error,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.Resolution,
+		&i.ResolvedAt,
+		&i.RequeuedRunID,
+	)
+	return i, err
+}
+
+const retryJobRun = `-- name: RetryJobRun :execrows
+UPDATE job_runs
+SET
+  status = 'pending',
+  lease_owner = NULL,
+  agent_id = NULL,
+  lease_expires_at = NULL,
+  workspace_path = NULL,
+  sandbox_profile = NULL,
+  stdout = NULL,
+  stderr = NULL,
+  started_at = NULL
+WHERE id = ? AND lease_owner = ? AND finished_at IS NULL
+`
+
+type RetryJobRunParams struct {
+	ID         string
+	LeaseOwner sql.NullString
+}
+
+func (q *Queries) RetryJobRun(ctx context.Context, arg RetryJobRunParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, retryJobRun, arg.ID, arg.LeaseOwner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchJobs = `-- name: SearchJobs :many
+-- Best match per job in job_search, best first; run_number is NULL when
+-- the job matched on its name or description rather than a run's output
+WITH hits AS (
+  SELECT job_id, run_number,
+    CAST(bm25(job_search, 0.0, 0.0, 0.0, 10.0, 5.0, 1.0) AS REAL) AS score,
+    CAST(snippet(job_search, -1, '<mark>', '</mark>', '...', 16) AS TEXT) AS snippet
+  FROM job_search
+  WHERE job_search MATCH ?
+), best AS (
+  SELECT job_id, run_number, score, snippet,
+    ROW_NUMBER() OVER (PARTITION BY job_id ORDER BY score) AS position
+  FROM hits
+)
+SELECT jobs.id, jobs.name, jobs.description, jobs.status, jobs.start_date, jobs.end_date, jobs.created_at, jobs.updated_at, jobs.owner_id, jobs.command, jobs.arguments, jobs.stdout, jobs.stderr, jobs.plugin, jobs.plugin_config, jobs.sandbox, jobs.limits, jobs.agent_labels, jobs.max_retries, jobs.template_id, jobs.template_version, jobs.template_params, jobs.input_schema, jobs.matrix, jobs.requires_approval, jobs.approver_roles, jobs.source, jobs.caches, jobs.interactive, jobs.file_trigger, jobs.test_reports, best.run_number, best.score, best.snippet
+FROM best
+JOIN jobs ON jobs.id = best.job_id
+WHERE best.position = 1
+ORDER BY best.score, jobs.created_at DESC
+LIMIT ? OFFSET ?
+`
+
+type SearchJobsParams struct {
+	Query  string
+	Limit  int64
+	Offset int64
+}
+
+type SearchJobsRow struct {
+	Job       Job
+	RunNumber sql.NullInt64
+	Score     float64
+	Snippet   string
+}
+
+// Best match per job in job_search, best first; run_number is NULL when
+// the job matched on its name or description rather than a run's output
+func (q *Queries) SearchJobs(ctx context.Context, arg SearchJobsParams) ([]SearchJobsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchJobs, arg.Query, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchJobsRow
+	for rows.Next() {
+		var i SearchJobsRow
+		if err := rows.Scan(
+			&i.Job.ID,
+			&i.Job.Name,
+			&i.Job.Description,
+			&i.Job.Status,
+			&i.Job.StartDate,
+			&i.Job.EndDate,
+			&i.Job.CreatedAt,
+			&i.Job.UpdatedAt,
+			&i.Job.OwnerID,
+			&i.Job.Command,
+			&i.Job.Arguments,
+			&i.Job.Stdout,
+			&i.Job.Stderr,
+			&i.Job.Plugin,
+			&i.Job.PluginConfig,
+			&i.Job.Sandbox,
+			&i.Job.Limits,
+			&i.Job.AgentLabels,
+			&i.Job.MaxRetries,
+			&i.Job.TemplateID,
+			&i.Job.TemplateVersion,
+			&i.Job.TemplateParams,
+			&i.Job.InputSchema,
+			&i.Job.Matrix,
+			&i.Job.RequiresApproval,
+			&i.Job.ApproverRoles,
+			&i.Job.Source,
+			&i.Job.Caches,
+			&i.Job.Interactive,
+			&i.Job.FileTrigger,
+			&i.Job.TestReports,
+			&i.RunNumber,
+			&i.Score,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setJobRunCacheResults = `-- name: SetJobRunCacheResults :exec
+UPDATE job_runs
+SET cache_results = ?
+WHERE id = ?
+`
+
+type SetJobRunCacheResultsParams struct {
+	CacheResults sql.NullString
+	ID           string
+}
+
+func (q *Queries) SetJobRunCacheResults(ctx context.Context, arg SetJobRunCacheResultsParams) error {
+	_, err := q.db.ExecContext(ctx, setJobRunCacheResults, arg.CacheResults, arg.ID)
+	return err
+}
+
+const setJobRunPlan = `-- name: SetJobRunPlan :exec
+UPDATE job_runs
+SET plan = ?
+WHERE id = ?
+`
+
+type SetJobRunPlanParams struct {
+	Plan sql.NullString
+	ID   string
+}
+
+func (q *Queries) SetJobRunPlan(ctx context.Context, arg SetJobRunPlanParams) error {
+	_, err := q.db.ExecContext(ctx, setJobRunPlan, arg.Plan, arg.ID)
+	return err
+}
+
+const setJobRunSandboxProfile = `-- name: SetJobRunSandboxProfile :exec
+UPDATE job_runs
+SET sandbox_profile = ?
+WHERE id = ?
+`
+
+type SetJobRunSandboxProfileParams struct {
+	SandboxProfile sql.NullString
+	ID             string
+}
+
+func (q *Queries) SetJobRunSandboxProfile(ctx context.Context, arg SetJobRunSandboxProfileParams) error {
+	_, err := q.db.ExecContext(ctx, setJobRunSandboxProfile, arg.SandboxProfile, arg.ID)
+	return err
+}
+
+const setJobRunSourceSHA = `-- name: SetJobRunSourceSHA :exec
+UPDATE job_runs
+SET source_sha = ?
+WHERE id = ?
+`
+
+type SetJobRunSourceSHAParams struct {
+	SourceSha sql.NullString
+	ID        string
+}
+
+func (q *Queries) SetJobRunSourceSHA(ctx context.Context, arg SetJobRunSourceSHAParams) error {
+	_, err := q.db.ExecContext(ctx, setJobRunSourceSHA, arg.SourceSha, arg.ID)
+	return err
+}
+
+const setJobScheduleQueued = `-- name: SetJobScheduleQueued :execrows
+UPDATE jobs
+SET schedule_queued_for = ?
+WHERE id = ? AND schedule_queued_for IS ?
+`
+
+type SetJobScheduleQueuedParams struct {
+	QueuedFor sql.NullTime
+	ID        string
+	Previous  sql.NullTime
+}
+
+func (q *Queries) SetJobScheduleQueued(ctx context.Context, arg SetJobScheduleQueuedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setJobScheduleQueued, arg.QueuedFor, arg.ID, arg.Previous)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setTriggeredFileRun = `-- name: SetTriggeredFileRun :exec
+UPDATE triggered_files
+SET run_id = ?
+WHERE job_id = ? AND path = ?
+`
+
+type SetTriggeredFileRunParams struct {
+	RunID sql.NullString
+	JobID string
+	Path  string
+}
+
+func (q *Queries) SetTriggeredFileRun(ctx context.Context, arg SetTriggeredFileRunParams) error {
+	_, err := q.db.ExecContext(ctx, setTriggeredFileRun, arg.RunID, arg.JobID, arg.Path)
+	return err
+}
+
+const startJobRun = `-- name: StartJobRun :one
+UPDATE job_runs
+SET
+  workspace_path = ?,
+  sandbox_profile = ?,
+  limits = ?,
+  started_at = CURRENT_TIMESTAMP
+WHERE id = ? AND lease_owner = ? AND status = 'running'
+RETURNING id, job_id, run_number, status, exit_code, stdout, stderr, metadata, workspace_path, created_at, started_at, finished_at, sandbox_profile, limits, failure_reason, agent_labels, agent_id, lease_expires_at, lease_owner, attempt, inputs, parent_run_id, matrix_values, child_count, max_parallel, approval_expires_at, source_sha, cache_results, stdin, input_files, scheduled_for, caught_up, triggered_by_run_id, plan, dropped_fire_times
+`
+
+type StartJobRunParams struct {
+	WorkspacePath  sql.NullString
+	SandboxProfile sql.NullString
+	Limits         sql.NullString
+	ID             string
+	LeaseOwner     sql.NullString
+}
+
+func (q *Queries) StartJobRun(ctx context.Context, arg StartJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, startJobRun,
+		arg.WorkspacePath,
+		arg.SandboxProfile,
+		arg.Limits,
+		arg.ID,
+		arg.LeaseOwner,
+	)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.RunNumber,
+		&i.Status,
+		&i.ExitCode,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Metadata,
+		&i.WorkspacePath,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.SandboxProfile,
+		&i.Limits,
+		&i.FailureReason,
+		&i.AgentLabels,
+		&i.AgentID,
+		&i.LeaseExpiresAt,
+		&i.LeaseOwner,
+		&i.Attempt,
+		&i.Inputs,
+		&i.ParentRunID,
+		&i.MatrixValues,
+		&i.ChildCount,
+		&i.MaxParallel,
+		&i.ApprovalExpiresAt,
+		&i.SourceSha,
+		&i.CacheResults,
+		&i.Stdin,
+		&i.InputFiles,
+		&i.ScheduledFor,
+		&i.CaughtUp,
+		&i.TriggeredByRunID,
+		&i.Plan,
+		&i.DroppedFireTimes,
+	)
+	return i, err
+}
+
+const updateCalendar = `-- name: UpdateCalendar :one
+UPDATE calendars
+SET
+  description = ?,
+  timezone = ?,
+  windows = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE name = ?
+RETURNING name, description, timezone, windows, created_at, updated_at
+`
+
+type UpdateCalendarParams struct {
+	Description sql.NullString
+	Timezone    sql.NullString
+	Windows     string
+	Name        string
+}
+
+func (q *Queries) UpdateCalendar(ctx context.Context, arg UpdateCalendarParams) (Calendar, error) {
+	row := q.db.QueryRowContext(ctx, updateCalendar,
+		arg.Description,
+		arg.Timezone,
+		arg.Windows,
+		arg.Name,
+	)
+	var i Calendar
+	err := row.Scan(
+		&i.Name,
+		&i.Description,
+		&i.Timezone,
+		&i.Windows,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateComment = `-- name: UpdateComment :one
 UPDATE comments
 SET
@@ -1227,18 +3925,58 @@ SET
   status = ?,
   start_date = ?,
   end_date = ?,
+  plugin = ?,
+  plugin_config = ?,
+  sandbox = ?,
+  limits = ?,
+  agent_labels = ?,
+  max_retries = ?,
+  input_schema = ?,
+  matrix = ?,
+  requires_approval = ?,
+  approver_roles = ?,
+  source = ?,
+  caches = ?,
+  interactive = ?,
+  -- A changed schedule starts over: fire times of the old one are neither
+  -- caught up nor queued
+  schedule_checked_at = CASE WHEN schedule IS ? THEN schedule_checked_at END,
+  schedule_queued_for = CASE WHEN schedule IS ? THEN schedule_queued_for END,
+  schedule = ?,
+  file_trigger = ?,
+  test_reports = ?,
   updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, description, status, start_date, end_date, created_at, updated_at, owner_id
+  -- Callers acting on status changes pass the status they read, so the
+  -- update finds no row when another one changed it in between
+  AND (status = ? OR ? IS NULL)
+RETURNING id, name, description, status, start_date, end_date, created_at, updated_at, owner_id, command, arguments, stdout, stderr, plugin, plugin_config, sandbox, limits, agent_labels, max_retries, template_id, template_version, template_params, input_schema, matrix, requires_approval, approver_roles, source, caches, interactive, schedule, schedule_checked_at, schedule_queued_for, file_trigger, test_reports
 `
 
 type UpdateJobParams struct {
-	Name        string
-	Description sql.NullString
-	Status      string
-	StartDate   sql.NullTime
-	EndDate     sql.NullTime
-	ID          string
+	Name             string
+	Description      sql.NullString
+	Status           string
+	StartDate        sql.NullTime
+	EndDate          sql.NullTime
+	Plugin           sql.NullString
+	PluginConfig     sql.NullString
+	Sandbox          bool
+	Limits           sql.NullString
+	AgentLabels      sql.NullString
+	MaxRetries       int64
+	InputSchema      sql.NullString
+	Matrix           sql.NullString
+	RequiresApproval bool
+	ApproverRoles    sql.NullString
+	Source           sql.NullString
+	Caches           sql.NullString
+	Interactive      bool
+	Schedule         sql.NullString
+	FileTrigger      sql.NullString
+	TestReports      sql.NullString
+	ID               string
+	PreviousStatus   sql.NullString
 }
 
 func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, error) {
@@ -1248,7 +3986,27 @@ func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, erro
 		arg.Status,
 		arg.StartDate,
 		arg.EndDate,
+		arg.Plugin,
+		arg.PluginConfig,
+		arg.Sandbox,
+		arg.Limits,
+		arg.AgentLabels,
+		arg.MaxRetries,
+		arg.InputSchema,
+		arg.Matrix,
+		arg.RequiresApproval,
+		arg.ApproverRoles,
+		arg.Source,
+		arg.Caches,
+		arg.Interactive,
+		arg.Schedule,
+		arg.Schedule,
+		arg.Schedule,
+		arg.FileTrigger,
+		arg.TestReports,
 		arg.ID,
+		arg.PreviousStatus,
+		arg.PreviousStatus,
 	)
 	var i Job
 	err := row.Scan(
@@ -1261,10 +4019,120 @@ func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.OwnerID,
+		&i.Command,
+		&i.Arguments,
+		&i.Stdout,
+		&i.Stderr,
+		&i.Plugin,
+		&i.PluginConfig,
+		&i.Sandbox,
+		&i.Limits,
+		&i.AgentLabels,
+		&i.MaxRetries,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.TemplateParams,
+		&i.InputSchema,
+		&i.Matrix,
+		&i.RequiresApproval,
+		&i.ApproverRoles,
+		&i.Source,
+		&i.Caches,
+		&i.Interactive,
+		&i.Schedule,
+		&i.ScheduleCheckedAt,
+		&i.ScheduleQueuedFor,
+		&i.FileTrigger,
+		&i.TestReports,
+	)
+	return i, err
+}
+
+const updateJobStatus = `-- name: UpdateJobStatus :exec
+UPDATE jobs
+SET
+  status = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateJobStatusParams struct {
+	Status string
+	ID     string
+}
+
+func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateJobStatus, arg.Status, arg.ID)
+	return err
+}
+
+const updateJobTemplate = `-- name: UpdateJobTemplate :one
+UPDATE job_templates
+SET
+  name = ?,
+  description = ?,
+  latest_version = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, name, description, owner_id, latest_version, created_at, updated_at
+`
+
+type UpdateJobTemplateParams struct {
+	Name          string
+	Description   sql.NullString
+	LatestVersion int64
+	ID            string
+}
+
+func (q *Queries) UpdateJobTemplate(ctx context.Context, arg UpdateJobTemplateParams) (JobTemplate, error) {
+	row := q.db.QueryRowContext(ctx, updateJobTemplate,
+		arg.Name,
+		arg.Description,
+		arg.LatestVersion,
+		arg.ID,
+	)
+	var i JobTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.LatestVersion,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const updateParentRun = `-- name: UpdateParentRun :execrows
+UPDATE job_runs
+SET
+  status = ?,
+  started_at = COALESCE(started_at, ?),
+  finished_at = ?
+WHERE id = ? AND child_count > 0 AND finished_at IS NULL
+`
+
+type UpdateParentRunParams struct {
+	Status     string
+	StartedAt  interface{}
+	FinishedAt sql.NullTime
+	ID         string
+}
+
+func (q *Queries) UpdateParentRun(ctx context.Context, arg UpdateParentRunParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateParentRun,
+		arg.Status,
+		arg.StartedAt,
+		arg.FinishedAt,
+		arg.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks
 SET
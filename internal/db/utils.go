@@ -1,8 +1,11 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -58,3 +61,30 @@ func NullInt64ToInt64(i sql.NullInt64, defaultValue int64) int64 {
 	}
 	return i.Int64
 }
+
+// IsNotFound reports whether err is a query finding no row, as returned by
+// the generated queries (sql.ErrNoRows) or by code translating it
+// (ErrNotFound)
+func IsNotFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNotFound)
+}
+
+// IsUniqueViolation reports whether err is SQLite rejecting a duplicate
+// value of a UNIQUE column or primary key
+func IsUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// InTx runs fn with queries bound to a transaction on conn. The transaction
+// is committed when fn returns nil and rolled back otherwise.
+func InTx(ctx context.Context, conn *sql.DB, fn func(*Queries) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(New(tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
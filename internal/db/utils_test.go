@@ -1,11 +1,17 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
 )
 
 func TestStringToNullString(t *testing.T) {
@@ -223,3 +229,73 @@ func TestNullInt64ToInt64(t *testing.T) {
 func TestErrNotFound(t *testing.T) {
 	assert.Equal(t, "record not found", ErrNotFound.Error())
 }
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "no rows", err: sql.ErrNoRows, expected: true},
+		{name: "not found", err: ErrNotFound, expected: true},
+		{name: "wrapped", err: fmt.Errorf("failed to get job: %w", sql.ErrNoRows), expected: true},
+		{name: "other", err: errors.New("database is locked"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsNotFound(tt.err))
+		})
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "unique", err: errors.New("constraint failed: UNIQUE constraint failed: job_templates.name (2067)"), expected: true},
+		{name: "other", err: sql.ErrNoRows, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsUniqueViolation(tt.err))
+		})
+	}
+}
+
+func TestInTx(t *testing.T) {
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Exec("CREATE TABLE items (name TEXT)")
+	require.NoError(t, err)
+
+	insert := func(q *Queries) error {
+		_, err := q.db.ExecContext(ctx, "INSERT INTO items (name) VALUES ('a'), ('b')")
+		return err
+	}
+	count := func() int {
+		var n int
+		require.NoError(t, conn.QueryRow("SELECT count(*) FROM items").Scan(&n))
+		return n
+	}
+
+	failed := errors.New("second write failed")
+	err = InTx(ctx, conn, func(q *Queries) error {
+		if err := insert(q); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Zero(t, count(), "failed transactions are rolled back")
+
+	require.NoError(t, InTx(ctx, conn, insert))
+	assert.Equal(t, 2, count())
+}
@@ -0,0 +1,105 @@
+/*
+Package events distributes server events, such as alerts, to subscribers
+within the process.
+
+Publishers hand events to a Bus, which fans them out to every current
+subscriber, e.g. the /api/events stream. Delivery is best effort: a
+subscriber that falls behind misses events rather than blocking the
+publisher.
+*/
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	// TypeRunDeadLettered is published when a run is moved to the dead
+	// letters after exhausting its retries
+	TypeRunDeadLettered = "run.dead_lettered"
+	// TypeDeadLetterThreshold is published when the number of open dead
+	// letters reaches the alert threshold
+	TypeDeadLetterThreshold = "dead_letters.threshold_reached"
+)
+
+// Event is something that happened on the server
+type Event struct {
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload"`
+	Time    time.Time   `json:"time"`
+}
+
+// DeadLetterPayload is the payload of TypeRunDeadLettered events
+type DeadLetterPayload struct {
+	DeadLetterID  string `json:"dead_letter_id"`
+	JobID         string `json:"job_id"`
+	RunID         string `json:"run_id"`
+	Attempts      int64  `json:"attempts"`
+	Error         string `json:"error,omitempty"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// ThresholdPayload is the payload of TypeDeadLetterThreshold events
+type ThresholdPayload struct {
+	Open      int64 `json:"open"`
+	Threshold int   `json:"threshold"`
+}
+
+// Publisher publishes events
+type Publisher interface {
+	Publish(event Event)
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind
+const subscriberBuffer = 64
+
+// Bus fans events out to subscribers. The zero value is ready to use.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Publish sends an event to all subscribers, stamping it with the current
+// time when it has none
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// The subscriber is not keeping up
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events published from now on
+// and a function that ends the subscription
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = map[chan Event]struct{}{}
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("fans out to subscribers", func(t *testing.T) {
+		bus := NewBus()
+		first, unsubscribeFirst := bus.Subscribe()
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := bus.Subscribe()
+		defer unsubscribeSecond()
+
+		bus.Publish(Event{Type: TypeRunDeadLettered, Payload: "run-1"})
+		for _, ch := range []<-chan Event{first, second} {
+			event := <-ch
+			assert.Equal(t, TypeRunDeadLettered, event.Type)
+			assert.Equal(t, "run-1", event.Payload)
+			assert.False(t, event.Time.IsZero())
+		}
+	})
+
+	t.Run("unsubscribed channels receive nothing", func(t *testing.T) {
+		var bus Bus
+		ch, unsubscribe := bus.Subscribe()
+		unsubscribe()
+		unsubscribe()
+		bus.Publish(Event{Type: TypeDeadLetterThreshold})
+		assert.Empty(t, ch)
+	})
+
+	t.Run("slow subscribers miss events", func(t *testing.T) {
+		var bus Bus
+		ch, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+		for range subscriberBuffer + 10 {
+			bus.Publish(Event{Type: TypeRunDeadLettered})
+		}
+		require.Len(t, ch, subscriberBuffer)
+	})
+}
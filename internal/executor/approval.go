@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+)
+
+// DefaultApprovalTTL is how long a run awaits approval when
+// Config.ApprovalTTL is zero
+const DefaultApprovalTTL = 24 * time.Hour
+
+var (
+	ErrNotAwaitingApproval = errors.New("run is not awaiting approval")
+	ErrApprovalExpired     = errors.New("approval of run expired")
+)
+
+// ApprovalTTL returns how long runs await approval before they expire
+func (e *Executor) ApprovalTTL() time.Duration {
+	if e.cfg.ApprovalTTL > 0 {
+		return e.cfg.ApprovalTTL
+	}
+	return DefaultApprovalTTL
+}
+
+// ApproverRoles returns the user roles allowed to approve runs of a job
+func ApproverRoles(job db.Job) []string {
+	if !job.ApproverRoles.Valid {
+		return nil
+	}
+	var roles []string
+	if err := json.Unmarshal([]byte(job.ApproverRoles.String), &roles); err != nil {
+		return nil
+	}
+	return roles
+}
+
+// Approve queues a run awaiting approval, along with the children of a
+// matrix run. Runs whose approval expired are expired instead and reported
+// as ErrApprovalExpired.
+func (e *Executor) Approve(ctx context.Context, runID string) error {
+	run, err := e.awaiting(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run.ApprovalExpiresAt.Valid && !run.ApprovalExpiresAt.Time.After(time.Now().UTC()) {
+		if err := e.decide(ctx, run.ID, StatusExpired); err != nil && !errors.Is(err, ErrNotAwaitingApproval) {
+			return err
+		}
+		return ErrApprovalExpired
+	}
+	if err := e.decide(ctx, run.ID, StatusPending); err != nil {
+		return err
+	}
+	e.queue.notify()
+	return nil
+}
+
+// Reject finishes a run awaiting approval, along with the children of a
+// matrix run, without executing it
+func (e *Executor) Reject(ctx context.Context, runID string) error {
+	run, err := e.awaiting(ctx, runID)
+	if err != nil {
+		return err
+	}
+	return e.decide(ctx, run.ID, StatusRejected)
+}
+
+// ExpireApprovals finishes the runs whose approval expired and returns how
+// many were expired
+func (e *Executor) ExpireApprovals(ctx context.Context) (int, error) {
+	runs, err := e.queries.ListExpiredApprovals(ctx, sql.NullTime{Time: time.Now().UTC(), Valid: true})
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, run := range runs {
+		if err := e.decide(ctx, run.ID, StatusExpired); err != nil {
+			if !errors.Is(err, ErrNotAwaitingApproval) {
+				log.Printf("Failed to expire run %s: %v", run.ID, err)
+			}
+			continue
+		}
+		log.Printf("Run %s expired awaiting approval", run.ID)
+		expired++
+	}
+	return expired, nil
+}
+
+// awaiting looks up a run awaiting approval. Children of a matrix run are
+// decided through their parent.
+func (e *Executor) awaiting(ctx context.Context, runID string) (db.JobRun, error) {
+	run, err := e.queries.GetJobRun(ctx, runID)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	if run.Status != StatusAwaitingApproval || run.ParentRunID.Valid {
+		return db.JobRun{}, ErrNotAwaitingApproval
+	}
+	return run, nil
+}
+
+// decide moves a run awaiting approval and its children to status,
+// finishing them unless they are queued. It returns ErrNotAwaitingApproval
+// when the run was decided concurrently.
+func (e *Executor) decide(ctx context.Context, runID, status string) error {
+	var finished sql.NullTime
+	if status != StatusPending {
+		finished = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	n, err := e.queries.DecideJobRun(ctx, db.DecideJobRunParams{
+		Status:     status,
+		FinishedAt: finished,
+		ID:         runID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotAwaitingApproval
+	}
+	return nil
+}
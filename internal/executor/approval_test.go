@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproval(t *testing.T) {
+	ctx := context.Background()
+
+	createGatedJob := func(t *testing.T, env *testEnv, m string) db.Job {
+		t.Helper()
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:               uuid.New().String(),
+			Name:             "gated",
+			Status:           "pending",
+			Plugin:           db.StringToNullString("fake"),
+			PluginConfig:     db.StringToNullString(`{"fail": false}`),
+			Matrix:           db.StringToNullString(m),
+			RequiresApproval: true,
+			ApproverRoles:    db.StringToNullString(`["admin"]`),
+		})
+		require.NoError(t, err)
+		return job
+	}
+	status := func(t *testing.T, env *testEnv, id string) string {
+		t.Helper()
+		run, err := env.queries.GetJobRun(ctx, id)
+		require.NoError(t, err)
+		return run.Status
+	}
+
+	t.Run("approved runs are queued", func(t *testing.T) {
+		env := setup(t)
+		job := createGatedJob(t, env, "")
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusAwaitingApproval, run.Status)
+		assert.WithinDuration(t, time.Now().Add(DefaultApprovalTTL), run.ApprovalExpiresAt.Time, time.Minute)
+		assert.Equal(t, []string{"admin"}, ApproverRoles(job))
+
+		// Not claimed while awaiting approval
+		require.NoError(t, env.executor.claimLocal(ctx))
+		assert.Equal(t, StatusAwaitingApproval, status(t, env, run.ID))
+
+		require.NoError(t, env.executor.Approve(ctx, run.ID))
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusComplete, run.Status)
+		assert.False(t, run.ApprovalExpiresAt.Valid)
+
+		assert.ErrorIs(t, env.executor.Approve(ctx, run.ID), ErrNotAwaitingApproval)
+	})
+
+	t.Run("rejected runs finish without executing", func(t *testing.T) {
+		env := setup(t)
+		job := createGatedJob(t, env, "")
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		require.NoError(t, env.executor.Reject(ctx, run.ID))
+
+		run, err = env.queries.GetJobRun(ctx, run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusRejected, run.Status)
+		assert.True(t, run.FinishedAt.Valid)
+		assert.False(t, run.WorkspacePath.Valid)
+		assert.ErrorIs(t, env.executor.Reject(ctx, run.ID), ErrNotAwaitingApproval)
+	})
+
+	t.Run("approvals expire", func(t *testing.T) {
+		env := setupWithConfig(t, Config{ApprovalTTL: time.Millisecond})
+		job := createGatedJob(t, env, "")
+
+		late, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		swept, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		assert.ErrorIs(t, env.executor.Approve(ctx, late.ID), ErrApprovalExpired)
+		assert.Equal(t, StatusExpired, status(t, env, late.ID))
+
+		n, err := env.executor.ExpireApprovals(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, StatusExpired, status(t, env, swept.ID))
+	})
+
+	t.Run("matrix children wait for the parent's approval", func(t *testing.T) {
+		env := setup(t)
+		job := createGatedJob(t, env, `{"axes": {"n": ["1", "2"]}}`)
+
+		parent, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		children, err := env.queries.ListChildRuns(ctx, db.StringToNullString(parent.ID))
+		require.NoError(t, err)
+		require.Len(t, children, 2)
+		for _, child := range children {
+			assert.Equal(t, StatusAwaitingApproval, child.Status)
+			assert.ErrorIs(t, env.executor.Approve(ctx, child.ID), ErrNotAwaitingApproval, "children are decided through their parent")
+		}
+
+		require.NoError(t, env.executor.Approve(ctx, parent.ID))
+		parent = env.waitForRun(t, parent.ID)
+		assert.Equal(t, StatusComplete, parent.Status)
+	})
+}
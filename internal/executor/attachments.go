@@ -0,0 +1,287 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/workspace"
+)
+
+var (
+	ErrNoAttachmentStore = errors.New("no attachment store is configured")
+	ErrNotRunAttachment  = errors.New("attachment was not given to the run")
+)
+
+// Attributes of the attachment holding standard input given inline
+const (
+	inlineFilename = "stdin"
+	inlineMimeType = "application/octet-stream"
+)
+
+// resolveAttachments validates the standard input and input files of a new
+// run of a job and returns them as stored with the run. Inline standard
+// input is saved as an attachment of the job.
+func (e *Executor) resolveAttachments(ctx context.Context, job db.Job, stdin *attachments.Stdin, files []attachments.File) (sql.NullString, sql.NullString, error) {
+	stdinSource, sources, err := e.attachmentSources(ctx, job, stdin, files)
+	if err != nil {
+		return sql.NullString{}, sql.NullString{}, err
+	}
+	var storedFiles sql.NullString
+	if len(sources) > 0 {
+		storedFiles = encodeJSON(sources)
+	}
+	var storedStdin sql.NullString
+	if stdinSource != nil {
+		if stdin.Content != nil {
+			saved, err := e.saveInline(ctx, job.ID, *stdin.Content)
+			if err != nil {
+				return sql.NullString{}, sql.NullString{}, err
+			}
+			stdinSource = &saved
+		}
+		storedStdin = encodeJSON(stdinSource)
+	}
+	return storedStdin, storedFiles, nil
+}
+
+// attachmentSources validates the standard input and input files of a new
+// run of a job and describes the attachments they refer to. Inline
+// standard input is described without being saved.
+func (e *Executor) attachmentSources(ctx context.Context, job db.Job, stdin *attachments.Stdin, files []attachments.File) (*attachments.Source, []attachments.Source, error) {
+	if stdin == nil && len(files) == 0 {
+		return nil, nil, nil
+	}
+	if e.cfg.Attachments == nil {
+		return nil, nil, ErrNoAttachmentStore
+	}
+	if stdin != nil {
+		if err := stdin.Validate(); err != nil {
+			return nil, nil, err
+		}
+		if job.Interactive {
+			return nil, nil, fmt.Errorf("%w: interactive jobs take their input from the terminal", attachments.ErrInvalidInput)
+		}
+	}
+	if err := attachments.ValidateFiles(files); err != nil {
+		return nil, nil, err
+	}
+
+	var sources []attachments.Source
+	if len(files) > 0 {
+		sources = make([]attachments.Source, len(files))
+		for i, f := range files {
+			a, err := e.jobAttachment(ctx, job.ID, f.Attachment)
+			if err != nil {
+				return nil, nil, err
+			}
+			sources[i] = sourceOf(a)
+			sources[i].Path = f.Path
+			if sources[i].Path == "" {
+				sources[i].Path = a.Filename
+			}
+		}
+		if err := attachments.ValidateSources(sources); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if stdin == nil {
+		return nil, sources, nil
+	}
+	if stdin.Content != nil {
+		sum := sha256.Sum256([]byte(*stdin.Content))
+		return &attachments.Source{
+			Filename: inlineFilename,
+			Inline:   true,
+			Size:     int64(len(*stdin.Content)),
+			SHA256:   hex.EncodeToString(sum[:]),
+		}, sources, nil
+	}
+	a, err := e.jobAttachment(ctx, job.ID, stdin.Attachment)
+	if err != nil {
+		return nil, nil, err
+	}
+	src := sourceOf(a)
+	return &src, sources, nil
+}
+
+// jobAttachment looks up an attachment of a job
+func (e *Executor) jobAttachment(ctx context.Context, jobID, id string) (db.Attachment, error) {
+	a, err := e.queries.GetAttachment(ctx, id)
+	if db.IsNotFound(err) || (err == nil && a.JobID.String != jobID) {
+		return db.Attachment{}, fmt.Errorf("%w: job has no attachment %q", attachments.ErrInvalidInput, id)
+	}
+	if err != nil {
+		return db.Attachment{}, err
+	}
+	if !a.Sha256.Valid {
+		return db.Attachment{}, fmt.Errorf("%w: attachment %q has no checksum", attachments.ErrInvalidInput, id)
+	}
+	return a, nil
+}
+
+// saveInline stores standard input given inline as an attachment of a job
+func (e *Executor) saveInline(ctx context.Context, jobID, content string) (attachments.Source, error) {
+	id := uuid.New().String()
+	size, sum, err := e.cfg.Attachments.Save(id, strings.NewReader(content))
+	if err != nil {
+		return attachments.Source{}, err
+	}
+	path, _ := e.cfg.Attachments.Path(id)
+	a, err := e.queries.CreateAttachment(ctx, db.CreateAttachmentParams{
+		ID:       id,
+		Filename: inlineFilename,
+		FilePath: path,
+		FileSize: size,
+		MimeType: inlineMimeType,
+		JobID:    db.StringToNullString(jobID),
+		Sha256:   db.StringToNullString(sum),
+	})
+	if err != nil {
+		_ = e.cfg.Attachments.Remove(id)
+		return attachments.Source{}, err
+	}
+	src := sourceOf(a)
+	src.Inline = true
+	return src, nil
+}
+
+// sourceOf describes an attachment handed to a run
+func sourceOf(a db.Attachment) attachments.Source {
+	return attachments.Source{
+		Attachment: a.ID,
+		Filename:   a.Filename,
+		Size:       a.FileSize,
+		SHA256:     a.Sha256.String,
+	}
+}
+
+// DecodeStdin parses the stdin column of a run, returning nil for runs
+// without standard input
+func DecodeStdin(s sql.NullString) *attachments.Source {
+	if !s.Valid {
+		return nil
+	}
+	var src attachments.Source
+	if err := json.Unmarshal([]byte(s.String), &src); err != nil {
+		return nil
+	}
+	return &src
+}
+
+// DecodeInputFiles parses the input_files column of a run, returning nil
+// for runs without input files
+func DecodeInputFiles(s sql.NullString) []attachments.Source {
+	if !s.Valid {
+		return nil
+	}
+	var files []attachments.Source
+	if err := json.Unmarshal([]byte(s.String), &files); err != nil {
+		return nil
+	}
+	return files
+}
+
+// RunOptionsOf returns the options a run was started with, for starting it
+// again. Inline standard input is referenced by the attachment it was
+// saved as. Runs started by a completion trigger keep the run that
+// triggered them, so the outputs forwarded to a job without an input
+// schema are accepted again.
+func RunOptionsOf(run db.JobRun) RunOptions {
+	opts := RunOptions{TriggeredBy: run.TriggeredByRunID.String}
+	if run.Inputs.Valid {
+		opts.Inputs = json.RawMessage(run.Inputs.String)
+	}
+	if src := DecodeStdin(run.Stdin); src != nil {
+		opts.Stdin = &attachments.Stdin{Attachment: src.Attachment}
+	}
+	for _, f := range DecodeInputFiles(run.InputFiles) {
+		opts.Files = append(opts.Files, attachments.File{Attachment: f.Attachment, Path: f.Path})
+	}
+	return opts
+}
+
+// ApplyAttachments hands the standard input and input files of a run,
+// read through open, to its processes: standard input is written to the
+// workspace and set as the execution's Stdin, input files are placed into
+// the working directory. The contents are verified against their
+// checksums. Runs without attachments are left unchanged.
+func ApplyAttachments(execution *plugin.Execution, ws *workspace.Workspace, stdin *attachments.Source, files []attachments.Source, open func(attachments.Source) (io.ReadCloser, error)) error {
+	if stdin != nil {
+		r, err := open(*stdin)
+		if err != nil {
+			return fmt.Errorf("failed to open stdin: %w", err)
+		}
+		path, err := ws.WriteStdin(attachments.Verify(r, *stdin))
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write stdin: %w", err)
+		}
+		execution.Stdin = path
+	}
+	for _, f := range files {
+		r, err := open(f)
+		if err != nil {
+			return fmt.Errorf("failed to open input file %s: %w", f.Path, err)
+		}
+		err = attachments.WriteFile(execution.WorkDir, f, r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to place input file %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// openAttachment opens an attachment of a run executed by this executor
+func (e *Executor) openAttachment(src attachments.Source) (io.ReadCloser, error) {
+	if e.cfg.Attachments == nil {
+		return nil, ErrNoAttachmentStore
+	}
+	return e.cfg.Attachments.Open(src.Attachment)
+}
+
+// OpenAttachment opens an attachment of a run held by an agent, which must
+// have been given to the run as standard input or an input file
+func (e *Executor) OpenAttachment(ctx context.Context, agentID, runID, attachmentID string) (*os.File, attachments.Source, error) {
+	run, err := e.queries.GetJobRun(ctx, runID)
+	if db.IsNotFound(err) {
+		return nil, attachments.Source{}, ErrLeaseLost
+	}
+	if err != nil {
+		return nil, attachments.Source{}, err
+	}
+	if run.LeaseOwner.String != agentID || run.Status != StatusRunning {
+		return nil, attachments.Source{}, ErrLeaseLost
+	}
+
+	sources := DecodeInputFiles(run.InputFiles)
+	if stdin := DecodeStdin(run.Stdin); stdin != nil {
+		sources = append(sources, *stdin)
+	}
+	i := slices.IndexFunc(sources, func(src attachments.Source) bool { return src.Attachment == attachmentID })
+	if i < 0 {
+		return nil, attachments.Source{}, ErrNotRunAttachment
+	}
+	if e.cfg.Attachments == nil {
+		return nil, attachments.Source{}, ErrNoAttachmentStore
+	}
+	f, err := e.cfg.Attachments.Open(attachmentID)
+	if err != nil {
+		return nil, attachments.Source{}, err
+	}
+	return f, sources[i], nil
+}
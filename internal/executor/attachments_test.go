@@ -0,0 +1,178 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// catPlugin echoes its standard input followed by an input file
+type catPlugin struct{}
+
+func (catPlugin) Name() string        { return "cat" }
+func (catPlugin) Description() string { return "test plugin" }
+func (catPlugin) Version() string     { return "0.0.1" }
+func (catPlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+func (catPlugin) Validate(map[string]interface{}) error { return nil }
+
+func (catPlugin) Execute(ctx context.Context, _ map[string]interface{}) (plugin.JobResult, error) {
+	return plugin.RunProcess(ctx, plugin.Process{Path: "sh", Args: []string{"-c", "cat; cat data/input.txt"}})
+}
+
+// upload stores an attachment of a job
+func (env *testEnv) upload(t *testing.T, store *attachments.Store, jobID, filename, content string) db.Attachment {
+	t.Helper()
+	id := uuid.New().String()
+	size, sum, err := store.Save(id, strings.NewReader(content))
+	require.NoError(t, err)
+	path, err := store.Path(id)
+	require.NoError(t, err)
+	a, err := env.queries.CreateAttachment(context.Background(), db.CreateAttachmentParams{
+		ID:       id,
+		Filename: filename,
+		FilePath: path,
+		FileSize: size,
+		MimeType: "text/plain",
+		JobID:    db.StringToNullString(jobID),
+		Sha256:   db.StringToNullString(sum),
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func TestAttachments(t *testing.T) {
+	ctx := context.Background()
+	store, err := attachments.NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+	env := setupWithConfig(t, Config{Attachments: store})
+	require.NoError(t, env.registry.Register(catPlugin{}))
+	job := env.createJob(t, "cat", "")
+	input := env.upload(t, store, job.ID, "input.txt", "from a file\n")
+
+	t.Run("inline stdin and input file", func(t *testing.T) {
+		content := "from stdin\n"
+		run, err := env.executor.StartRun(ctx, job.ID, RunOptions{
+			Stdin: &attachments.Stdin{Content: &content},
+			Files: []attachments.File{{Attachment: input.ID, Path: "data/input.txt"}},
+		})
+		require.NoError(t, err)
+
+		stdin := DecodeStdin(run.Stdin)
+		require.NotNil(t, stdin)
+		assert.True(t, stdin.Inline)
+		assert.Equal(t, int64(len(content)), stdin.Size)
+		assert.Equal(t, []attachments.Source{{
+			Attachment: input.ID,
+			Filename:   "input.txt",
+			Path:       "data/input.txt",
+			Size:       input.FileSize,
+			SHA256:     input.Sha256.String,
+		}}, DecodeInputFiles(run.InputFiles))
+
+		finished := env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusComplete, finished.Status)
+		assert.Equal(t, "from stdin\nfrom a file\n", finished.Stdout.String)
+
+		// Starting the run again reuses the saved standard input
+		opts := RunOptionsOf(finished)
+		require.NotNil(t, opts.Stdin)
+		assert.Equal(t, stdin.Attachment, opts.Stdin.Attachment)
+		rerun, err := env.executor.StartRun(ctx, job.ID, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "from stdin\nfrom a file\n", env.waitForRun(t, rerun.ID).Stdout.String)
+	})
+
+	t.Run("modified attachment", func(t *testing.T) {
+		stdin := env.upload(t, store, job.ID, "stdin.txt", "original\n")
+		run, err := env.executor.StartRun(ctx, job.ID, RunOptions{
+			Stdin: &attachments.Stdin{Attachment: stdin.ID},
+			Files: []attachments.File{{Attachment: input.ID, Path: "data/input.txt"}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(stdin.FilePath, []byte("tampered\n"), 0o644))
+
+		finished := env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusFailed, finished.Status)
+		assert.Contains(t, finished.Stderr.String, attachments.ErrMismatch.Error())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		other := env.createJob(t, "cat", "")
+		foreign := env.upload(t, store, other.ID, "input.txt", "other job\n")
+		empty := ""
+		for name, opts := range map[string]RunOptions{
+			"attachment of another job": {Files: []attachments.File{{Attachment: foreign.ID}}},
+			"unknown attachment":        {Stdin: &attachments.Stdin{Attachment: uuid.New().String()}},
+			"content and attachment":    {Stdin: &attachments.Stdin{Content: &empty, Attachment: input.ID}},
+			"escaping path":             {Files: []attachments.File{{Attachment: input.ID, Path: "../input.txt"}}},
+			"duplicate path": {Files: []attachments.File{
+				{Attachment: input.ID},
+				{Attachment: input.ID, Path: "./input.txt"},
+			}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := env.executor.StartRun(ctx, job.ID, opts)
+				assert.ErrorIs(t, err, attachments.ErrInvalidInput)
+			})
+		}
+	})
+
+	t.Run("interactive job", func(t *testing.T) {
+		interactive, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:          uuid.New().String(),
+			Name:        "interactive",
+			Status:      "pending",
+			Plugin:      db.StringToNullString("cat"),
+			Interactive: true,
+		})
+		require.NoError(t, err)
+		content := "input"
+		_, err = env.executor.StartRun(ctx, interactive.ID, RunOptions{Stdin: &attachments.Stdin{Content: &content}})
+		assert.ErrorIs(t, err, attachments.ErrInvalidInput)
+	})
+
+	t.Run("no store", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": false}`)
+		content := "input"
+		_, err := env.executor.StartRun(ctx, job.ID, RunOptions{Stdin: &attachments.Stdin{Content: &content}})
+		assert.ErrorIs(t, err, ErrNoAttachmentStore)
+	})
+}
+
+func TestApplyAttachments(t *testing.T) {
+	env := setup(t)
+	ws, err := env.workspaces.Create(uuid.New().String(), uuid.New().String())
+	require.NoError(t, err)
+	execution := &plugin.Execution{WorkDir: ws.Dir()}
+	open := func(src attachments.Source) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(src.Filename)), nil
+	}
+	// The checksums are those of the filenames
+	stdin := &attachments.Source{Attachment: "a", Filename: "abc", Size: 3, SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"}
+	files := []attachments.Source{{Attachment: "b", Filename: "abc", Path: "nested/dir/abc.txt", Size: 3, SHA256: stdin.SHA256}}
+
+	require.NoError(t, ApplyAttachments(execution, ws, stdin, files, open))
+	data, err := os.ReadFile(execution.Stdin)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+	data, err = os.ReadFile(filepath.Join(ws.Dir(), "nested", "dir", "abc.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+
+	files[0].Size = 4
+	err = ApplyAttachments(&plugin.Execution{WorkDir: ws.Dir()}, ws, nil, files, open)
+	assert.ErrorIs(t, err, attachments.ErrMismatch)
+}
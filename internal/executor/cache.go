@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+// jobCaches returns the cache entries of a job, or nil for jobs without
+// any
+func jobCaches(job db.Job) ([]cache.Entry, error) {
+	if !job.Caches.Valid {
+		return nil, nil
+	}
+	return cache.Decode([]byte(job.Caches.String))
+}
+
+// RestoreCaches resolves the keys of a run's cache entries and restores
+// them from store, scoped to the run's job. Caching never fails a run:
+// entries that cannot be resolved or restored are reported as misses with
+// an error. It returns nil when there is no store.
+func RestoreCaches(store *cache.Store, jobID string, entries []cache.Entry, execution *plugin.Execution) []cache.Result {
+	if store == nil || len(entries) == 0 {
+		return nil
+	}
+	results := make([]cache.Result, len(entries))
+	for i, entry := range entries {
+		results[i] = cache.Result{Path: entry.Path}
+		key, err := cache.Resolve(entry.Key, execution.WorkDir, execution.Env)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Key = key
+		restoreKeys := make([]string, 0, len(entry.RestoreKeys))
+		for _, tmpl := range entry.RestoreKeys {
+			// Restore keys are prefixes, so they may resolve to nothing
+			if prefix, err := cache.Resolve(tmpl, execution.WorkDir, execution.Env); err == nil {
+				restoreKeys = append(restoreKeys, prefix)
+			}
+		}
+		restored, err := store.Restore(jobID, key, restoreKeys, entry.Dir(execution.WorkDir, execution.Env["HOME"]))
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].RestoredKey = restored
+		results[i].Hit = restored == key
+	}
+	return results
+}
+
+// SaveCaches saves the entries of a successful run whose key missed
+func SaveCaches(store *cache.Store, jobID string, entries []cache.Entry, results []cache.Result, execution *plugin.Execution) {
+	for i := range results {
+		if results[i].Hit || results[i].Key == "" {
+			continue
+		}
+		saved, err := store.Save(jobID, results[i].Key, entries[i].Dir(execution.WorkDir, execution.Env["HOME"]))
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Saved = saved
+	}
+}
+
+// recordCaches stores the cache results of a run
+func (e *Executor) recordCaches(ctx context.Context, runID string, results []cache.Result) {
+	if results == nil {
+		return
+	}
+	if err := e.queries.SetJobRunCacheResults(ctx, db.SetJobRunCacheResultsParams{
+		CacheResults: encodeJSON(results),
+		ID:           runID,
+	}); err != nil {
+		log.Printf("Failed to record cache results of run %s: %v", runID, err)
+	}
+}
+
+// DecodeCacheResults parses the cache_results column of a run, returning
+// nil for runs without caches
+func DecodeCacheResults(s sql.NullString) []cache.Result {
+	if !s.Valid {
+		return nil
+	}
+	var results []cache.Result
+	if err := json.Unmarshal([]byte(s.String), &results); err != nil {
+		return nil
+	}
+	return results
+}
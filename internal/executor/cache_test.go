@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cachePlugin reports whether deps/marker was restored, then writes it.
+// It fails when configured to.
+type cachePlugin struct{}
+
+func (cachePlugin) Name() string        { return "cache" }
+func (cachePlugin) Description() string { return "test plugin" }
+func (cachePlugin) Version() string     { return "0.0.1" }
+func (cachePlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+func (cachePlugin) Validate(map[string]interface{}) error { return nil }
+
+func (cachePlugin) Execute(ctx context.Context, config map[string]interface{}) (plugin.JobResult, error) {
+	marker := filepath.Join(plugin.ExecutionFrom(ctx).WorkDir, "deps", "marker")
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		data = []byte("missing")
+	}
+	if err := os.MkdirAll(filepath.Dir(marker), 0o755); err != nil {
+		return plugin.JobResult{}, err
+	}
+	if err := os.WriteFile(marker, []byte("restored"), 0o644); err != nil {
+		return plugin.JobResult{}, err
+	}
+	if config["fail"] == true {
+		return plugin.JobResult{ExitCode: 1}, errors.New("failed on purpose")
+	}
+	return plugin.JobResult{Output: string(data)}, nil
+}
+
+func TestCaches(t *testing.T) {
+	ctx := context.Background()
+
+	setupCaches := func(t *testing.T) *testEnv {
+		t.Helper()
+		store, err := cache.NewStore(t.TempDir(), 0)
+		require.NoError(t, err)
+		env := setupWithConfig(t, Config{Caches: store})
+		require.NoError(t, env.registry.Register(cachePlugin{}))
+		return env
+	}
+	createJob := func(t *testing.T, env *testEnv, config, caches string) db.Job {
+		t.Helper()
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "cached",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("cache"),
+			PluginConfig: db.StringToNullString(config),
+			Caches:       db.StringToNullString(caches),
+		})
+		require.NoError(t, err)
+		return job
+	}
+	runJob := func(t *testing.T, env *testEnv, job db.Job) db.JobRun {
+		t.Helper()
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		return env.waitForRun(t, run.ID)
+	}
+
+	t.Run("restores what the previous run saved", func(t *testing.T) {
+		env := setupCaches(t)
+		job := createJob(t, env, `{}`, `[{"path": "deps", "key": "deps-v1"}]`)
+
+		first := runJob(t, env, job)
+		assert.Equal(t, StatusComplete, first.Status)
+		assert.Equal(t, "missing", first.Stdout.String)
+		assert.Equal(t, []cache.Result{{Path: "deps", Key: "deps-v1", Saved: true}}, DecodeCacheResults(first.CacheResults))
+
+		second := runJob(t, env, job)
+		assert.Equal(t, "restored", second.Stdout.String)
+		assert.Equal(t, []cache.Result{{Path: "deps", Key: "deps-v1", Hit: true, RestoredKey: "deps-v1"}}, DecodeCacheResults(second.CacheResults))
+	})
+
+	t.Run("falls back to restore keys", func(t *testing.T) {
+		env := setupCaches(t)
+		job := createJob(t, env, `{}`, `[{"path": "deps", "key": "deps-v1"}]`)
+		runJob(t, env, job)
+
+		// A new key, e.g. after a dependency update, starts from the old
+		// entry
+		job, err := env.queries.UpdateJob(ctx, db.UpdateJobParams{
+			ID:     job.ID,
+			Name:   job.Name,
+			Status: job.Status,
+			Plugin: job.Plugin,
+			Caches: db.StringToNullString(`[{"path": "deps", "key": "deps-v2", "restore_keys": ["deps-"]}]`),
+		})
+		require.NoError(t, err)
+
+		run := runJob(t, env, job)
+		assert.Equal(t, "restored", run.Stdout.String)
+		assert.Equal(t, []cache.Result{{Path: "deps", Key: "deps-v2", RestoredKey: "deps-v1", Saved: true}}, DecodeCacheResults(run.CacheResults))
+	})
+
+	t.Run("failed runs are not saved", func(t *testing.T) {
+		env := setupCaches(t)
+		job := createJob(t, env, `{"fail": true}`, `[{"path": "deps", "key": "deps-v1"}]`)
+
+		run := runJob(t, env, job)
+		assert.Equal(t, StatusFailed, run.Status)
+		assert.Equal(t, []cache.Result{{Path: "deps", Key: "deps-v1"}}, DecodeCacheResults(run.CacheResults))
+	})
+
+	t.Run("invalid caches", func(t *testing.T) {
+		env := setupCaches(t)
+		job := createJob(t, env, `{}`, `[{"path": "../deps", "key": "deps"}]`)
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, cache.ErrInvalidCache)
+	})
+
+	t.Run("no cache store", func(t *testing.T) {
+		env := setup(t)
+		require.NoError(t, env.registry.Register(cachePlugin{}))
+		run := runJob(t, env, createJob(t, env, `{}`, `[{"path": "deps", "key": "deps-v1"}]`))
+		assert.Equal(t, StatusComplete, run.Status)
+		assert.False(t, run.CacheResults.Valid)
+	})
+}
@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/events"
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+// retry requeues a run after a failed attempt when its job has retries
+// left. Attempts that used up their lease budget are not retried. It
+// returns sql.ErrNoRows when the run is no longer leased by owner.
+func (e *Executor) retry(ctx context.Context, run db.JobRun, owner string, result plugin.JobResult, runErr error, reason string) (bool, error) {
+	if run.Attempt == 0 || reason == ReasonLeaseExpired {
+		return false, nil
+	}
+	job, err := e.queries.GetJob(ctx, run.JobID)
+	if err != nil || run.Attempt > job.MaxRetries {
+		return false, nil
+	}
+
+	n, err := e.queries.RetryJobRun(ctx, db.RetryJobRunParams{
+		ID:         run.ID,
+		LeaseOwner: db.StringToNullString(owner),
+	})
+	if err != nil {
+		log.Printf("Failed to retry run %s: %v", run.ID, err)
+		return false, nil
+	}
+	if n == 0 {
+		return false, sql.ErrNoRows
+	}
+	e.recordAttempt(ctx, run, result, runErr, reason)
+	log.Printf("Retrying run %s after attempt %d of %d failed: %v", run.ID, run.Attempt, job.MaxRetries+1, runErr)
+	e.queue.notify()
+	return true, nil
+}
+
+// release hands a run interrupted by Shutdown back to the queue, so that
+// another executor, or this one after a restart, runs it again
+func (e *Executor) release(ctx context.Context, run db.JobRun, result plugin.JobResult) {
+	n, err := e.queries.RetryJobRun(ctx, db.RetryJobRunParams{
+		ID:         run.ID,
+		LeaseOwner: db.StringToNullString(e.cfg.ID),
+	})
+	if err != nil {
+		log.Printf("Failed to release run %s: %v", run.ID, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Released run %s on shutdown", run.ID)
+		e.recordAttempt(ctx, run, result, ErrShuttingDown, "")
+	}
+}
+
+// recordAttempt stores the outcome of an attempt of a run
+func (e *Executor) recordAttempt(ctx context.Context, run db.JobRun, result plugin.JobResult, runErr error, reason string) {
+	if run.Attempt == 0 {
+		// The run failed before it was ever claimed
+		return
+	}
+	status, msg := StatusComplete, ""
+	if runErr != nil {
+		status, msg = StatusFailed, runErr.Error()
+	}
+	if err := e.queries.CreateJobRunAttempt(ctx, db.CreateJobRunAttemptParams{
+		RunID:         run.ID,
+		Attempt:       run.Attempt,
+		Status:        status,
+		ExitCode:      db.Int64ToNullInt64(int64(result.ExitCode)),
+		Error:         db.StringToNullString(msg),
+		FailureReason: db.StringToNullString(reason),
+		LeaseOwner:    run.LeaseOwner,
+		StartedAt:     run.StartedAt,
+	}); err != nil {
+		log.Printf("Failed to record attempt %d of run %s: %v", run.Attempt, run.ID, err)
+	}
+}
+
+// deadLetter files a dead letter for a run that failed after being retried
+// and alerts when the number of open dead letters reaches the threshold
+func (e *Executor) deadLetter(ctx context.Context, run db.JobRun, runErr error, reason string) {
+	letter, err := e.queries.CreateDeadLetter(ctx, db.CreateDeadLetterParams{
+		ID:            uuid.New().String(),
+		RunID:         run.ID,
+		JobID:         run.JobID,
+		Attempts:      run.Attempt,
+		Error:         db.StringToNullString(runErr.Error()),
+		FailureReason: db.StringToNullString(reason),
+	})
+	if err != nil {
+		log.Printf("Failed to dead-letter run %s: %v", run.ID, err)
+		return
+	}
+	log.Printf("Dead-lettered run %s after %d attempts: %v", run.ID, run.Attempt, runErr)
+	e.publish(events.TypeRunDeadLettered, events.DeadLetterPayload{
+		DeadLetterID:  letter.ID,
+		JobID:         letter.JobID,
+		RunID:         letter.RunID,
+		Attempts:      letter.Attempts,
+		Error:         letter.Error.String,
+		FailureReason: letter.FailureReason.String,
+	})
+
+	threshold := e.cfg.DeadLetterThreshold
+	if threshold <= 0 {
+		return
+	}
+	open, err := e.queries.CountOpenDeadLetters(ctx)
+	if err != nil {
+		log.Printf("Failed to count open dead letters: %v", err)
+		return
+	}
+	// Alert once when the threshold is reached rather than on every
+	// dead letter beyond it
+	if open == int64(threshold) {
+		log.Printf("ALERT: %d open dead letters reached the threshold of %d", open, threshold)
+		e.publish(events.TypeDeadLetterThreshold, events.ThresholdPayload{Open: open, Threshold: threshold})
+	}
+}
+
+func (e *Executor) publish(eventType string, payload interface{}) {
+	if e.cfg.Events != nil {
+		e.cfg.Events.Publish(events.Event{Type: eventType, Payload: payload})
+	}
+}
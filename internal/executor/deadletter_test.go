@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/events"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyPlugin fails its first failures executions
+type flakyPlugin struct {
+	failures int32
+	calls    *atomic.Int32
+}
+
+func (flakyPlugin) Name() string        { return "flaky" }
+func (flakyPlugin) Description() string { return "fails before it succeeds" }
+func (flakyPlugin) Version() string     { return "0.0.1" }
+func (flakyPlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+func (flakyPlugin) Validate(map[string]interface{}) error { return nil }
+
+func (p flakyPlugin) Execute(context.Context, map[string]interface{}) (plugin.JobResult, error) {
+	if n := p.calls.Add(1); n <= p.failures {
+		return plugin.JobResult{ExitCode: 1, Output: "attempt output"}, errors.New("flaked")
+	}
+	return plugin.JobResult{Output: "ok"}, nil
+}
+
+func (env *testEnv) createRetryingJob(t *testing.T, pluginName string, maxRetries int64) db.Job {
+	t.Helper()
+	job, err := env.queries.CreateJob(context.Background(), db.CreateJobParams{
+		ID:           uuid.New().String(),
+		Name:         "retrying",
+		Status:       "pending",
+		Plugin:       db.StringToNullString(pluginName),
+		PluginConfig: db.StringToNullString(`{"fail": true}`),
+		MaxRetries:   maxRetries,
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failed attempts are retried", func(t *testing.T) {
+		env := setup(t)
+		calls := &atomic.Int32{}
+		require.NoError(t, env.registry.Register(flakyPlugin{failures: 2, calls: calls}))
+		job := env.createRetryingJob(t, "flaky", 3)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusComplete, run.Status)
+		assert.Equal(t, int64(3), run.Attempt)
+		assert.Equal(t, "ok", run.Stdout.String)
+
+		attempts, err := env.queries.ListJobRunAttempts(ctx, run.ID)
+		require.NoError(t, err)
+		require.Len(t, attempts, 3)
+		for i, status := range []string{StatusFailed, StatusFailed, StatusComplete} {
+			assert.Equal(t, int64(i+1), attempts[i].Attempt)
+			assert.Equal(t, status, attempts[i].Status)
+		}
+		assert.Equal(t, "flaked", attempts[0].Error.String)
+	})
+
+	t.Run("runs without retries fail", func(t *testing.T) {
+		env := setup(t)
+		job := env.createRetryingJob(t, "fake", 0)
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusFailed, run.Status)
+
+		open, err := env.queries.CountOpenDeadLetters(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, open)
+	})
+
+	t.Run("exhausted retries are dead-lettered", func(t *testing.T) {
+		bus := events.NewBus()
+		published, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+		env := setupWithConfig(t, Config{Events: bus, DeadLetterThreshold: 1})
+		job := env.createRetryingJob(t, "fake", 1)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+
+		// Published once the dead letter was filed
+		event := <-published
+		assert.Equal(t, events.TypeRunDeadLettered, event.Type)
+		assert.Equal(t, run.ID, event.Payload.(events.DeadLetterPayload).RunID)
+		event = <-published
+		assert.Equal(t, events.TypeDeadLetterThreshold, event.Type)
+		assert.Equal(t, events.ThresholdPayload{Open: 1, Threshold: 1}, event.Payload)
+
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusDeadLetter, run.Status)
+		assert.Equal(t, int64(2), run.Attempt)
+
+		stored, err := env.queries.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobStatusFailed, stored.Status)
+
+		attempts, err := env.queries.ListJobRunAttempts(ctx, run.ID)
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+
+		letters, err := env.queries.ListOpenDeadLetters(ctx, db.ListOpenDeadLettersParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, letters, 1)
+		assert.Equal(t, run.ID, letters[0].RunID)
+		assert.Equal(t, int64(2), letters[0].Attempts)
+		assert.Contains(t, letters[0].Error.String, "non-zero")
+	})
+}
@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/inputs"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/source"
+)
+
+// dryRunID stands in for the ID of the run in the workspace of a dry run
+const dryRunID = "dry-run"
+
+// DryRun describes how a run of a job would be executed
+type DryRun struct {
+	// Valid reports whether the run could be started; Errors lists the
+	// problems preventing it by the field of the job or run request at
+	// fault, e.g. "config.url" or "inputs.region"
+	Valid  bool                 `json:"valid"`
+	Errors []plugin.ConfigError `json:"errors,omitempty"`
+	Plugin string               `json:"plugin,omitempty"`
+	// Plan is what the plugin would do. Its Env includes the variables set
+	// by the executor. Plugins that are no plugin.Planner only report the
+	// environment.
+	Plan      *plugin.Plan     `json:"plan,omitempty"`
+	Workspace WorkspacePlan    `json:"workspace"`
+	Limits    *limits.Limits   `json:"limits,omitempty"`
+	Sandbox   *sandbox.Profile `json:"sandbox,omitempty"`
+	// Matrix lists the combinations of a matrix job, one child run each;
+	// the plan is that of the first child
+	Matrix           []map[string]interface{} `json:"matrix,omitempty"`
+	AgentLabels      []string                 `json:"agent_labels,omitempty"`
+	RequiresApproval bool                     `json:"requires_approval,omitempty"`
+}
+
+// WorkspacePlan describes the workspace a run would get and what would be
+// placed into it before the plugin runs
+type WorkspacePlan struct {
+	Path    string               `json:"path"`
+	WorkDir string               `json:"work_dir"`
+	Source  *source.Source       `json:"source,omitempty"`
+	Caches  []cache.Entry        `json:"caches,omitempty"`
+	Stdin   *attachments.Source  `json:"stdin,omitempty"`
+	Files   []attachments.Source `json:"files,omitempty"`
+}
+
+// DryRun resolves a run of a job started with opts without creating or
+// executing it. Everything StartRun validates is checked, and the plugin
+// describes its work when it is a plugin.Planner, with secrets resolved
+// but masked. Problems are reported in the result; errors are only
+// returned when the job cannot be looked up.
+//
+// Workspace paths are those of this server. Agents lay out workspaces the
+// same way below their own root, and the commit of a source is only known
+// once it is checked out.
+func (e *Executor) DryRun(ctx context.Context, jobID string, opts RunOptions) (DryRun, error) {
+	job, err := e.queries.GetJob(ctx, jobID)
+	if err != nil {
+		return DryRun{}, err
+	}
+	ws, err := e.workspaces.Plan(job.ID, dryRunID)
+	if err != nil {
+		return DryRun{}, err
+	}
+	d := DryRun{
+		Plugin:           job.Plugin.String,
+		Workspace:        WorkspacePlan{Path: ws.Path, WorkDir: ws.Dir()},
+		AgentLabels:      agentLabels(job),
+		RequiresApproval: job.RequiresApproval,
+	}
+	fail := func(field string, err error) {
+		d.Errors = append(d.Errors, fieldErrors(field, err)...)
+	}
+	execution := &plugin.Execution{WorkDir: ws.Dir(), Env: ws.Env()}
+
+	p, config, err := e.resolve(job)
+	switch {
+	case errors.Is(err, ErrNoPlugin), errors.Is(err, plugin.ErrPluginNotFound):
+		fail("plugin", err)
+	case err != nil:
+		fail("config", err)
+	}
+	if runLimits, err := e.limits(job); err != nil {
+		fail("limits", err)
+	} else if !runLimits.IsZero() {
+		execution.Limits = runLimits
+		d.Limits = &runLimits
+	}
+	if stored, err := checkInputs(job, opts); err != nil {
+		fail("inputs", err)
+	} else if stored.Valid {
+		values, _ := inputs.Decode([]byte(stored.String))
+		addInputsEnv(execution, values, ws.InputsPath())
+	}
+	if m, err := jobMatrix(job); err != nil {
+		fail("matrix", err)
+	} else if m != nil {
+		if d.Matrix, err = m.Expand(); err != nil {
+			fail("matrix", err)
+		} else if len(d.Matrix) > 0 {
+			ApplyMatrix(execution, d.Matrix[0])
+		}
+	}
+	if src, err := e.checkSource(job, len(d.AgentLabels) == 0); err != nil {
+		fail("source", err)
+	} else if src != nil {
+		d.Workspace.Source = src
+		execution.Env[source.DirEnv] = filepath.Join(execution.WorkDir, src.Path)
+	}
+	if d.Workspace.Caches, err = jobCaches(job); err != nil {
+		fail("caches", err)
+	}
+	// Checked apart to tell which of them is at fault
+	if d.Workspace.Stdin, _, err = e.attachmentSources(ctx, job, opts.Stdin, nil); err != nil {
+		fail("stdin", err)
+	}
+	if _, d.Workspace.Files, err = e.attachmentSources(ctx, job, nil, opts.Files); err != nil {
+		fail("files", err)
+	}
+	if job.Sandbox {
+		execution.Sandbox = &sandbox.Policy{Writable: []string{ws.Path}}
+		profile := sandbox.Plan(*execution.Sandbox)
+		d.Sandbox = &profile
+	}
+
+	if p != nil {
+		if plan, err := PlanRun(ctx, p, config, execution); err != nil {
+			fail("config", err)
+		} else {
+			d.Plan = &plan
+		}
+	}
+	d.Valid = len(d.Errors) == 0
+	return d, nil
+}
+
+// fieldErrors reports err as problems with field, or with the fields
+// below it named by the error
+func fieldErrors(field string, err error) []plugin.ConfigError {
+	var (
+		configErr *plugin.ConfigError
+		inputsErr *inputs.ValidationError
+	)
+	switch {
+	case errors.As(err, &inputsErr):
+		problems := make([]plugin.ConfigError, len(inputsErr.Problems))
+		for i, p := range inputsErr.Problems {
+			problems[i] = plugin.ConfigError{Field: p.Path, Message: p.Message}
+		}
+		return problems
+	case errors.As(err, &configErr):
+		return []plugin.ConfigError{{Field: field + "." + configErr.Field, Message: configErr.Message}}
+	default:
+		return []plugin.ConfigError{{Field: field, Message: err.Error()}}
+	}
+}
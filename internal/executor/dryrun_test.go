@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/plugin/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	env := setup(t)
+	require.NoError(t, env.registry.Register(script.New()))
+	const schema = `{"type": "object", "properties": {"region": {"type": "string"}, "shards": {"type": "integer"}}, "required": ["region"]}`
+
+	createJob := func(t *testing.T, pluginName, config string) db.Job {
+		t.Helper()
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "export",
+			Status:       "pending",
+			Plugin:       db.StringToNullString(pluginName),
+			PluginConfig: db.StringToNullString(config),
+			InputSchema:  db.StringToNullString(schema),
+			Limits:       db.StringToNullString(`{"timeout": "5m"}`),
+		})
+		require.NoError(t, err)
+		return job
+	}
+
+	t.Run("resolves the run", func(t *testing.T) {
+		job := createJob(t, script.Name, `{"interpreter": "sh", "body": "echo $INPUT_REGION", "env": {"MODE": "full"}}`)
+		d, err := env.executor.DryRun(ctx, job.ID, RunOptions{Inputs: json.RawMessage(`{"region": "eu-west-1"}`)})
+		require.NoError(t, err)
+
+		assert.True(t, d.Valid)
+		assert.Empty(t, d.Errors)
+		assert.Equal(t, script.Name, d.Plugin)
+		wsPath := filepath.Join(env.workspaces.Root(), job.ID, dryRunID)
+		assert.Equal(t, wsPath, d.Workspace.Path)
+		require.NotNil(t, d.Plan)
+		assert.Equal(t, d.Workspace.WorkDir, d.Plan.Dir)
+		assert.Contains(t, d.Plan.Command, filepath.Join(d.Workspace.WorkDir, ".script-*", "script.sh"))
+		assert.Equal(t, "eu-west-1", d.Plan.Env["INPUT_REGION"])
+		assert.Equal(t, "full", d.Plan.Env["MODE"])
+		assert.Equal(t, filepath.Join(wsPath, "home"), d.Plan.Env["HOME"])
+		require.NotNil(t, d.Limits)
+
+		// Nothing was created
+		assert.NoDirExists(t, wsPath)
+		number, err := env.queries.GetNextRunNumber(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), number)
+	})
+
+	t.Run("reports every problem by field", func(t *testing.T) {
+		job := createJob(t, "fake", `{}`)
+		d, err := env.executor.DryRun(ctx, job.ID, RunOptions{
+			Inputs: json.RawMessage(`{"shards": "many"}`),
+			Files:  []attachments.File{{Attachment: uuid.New().String()}},
+		})
+		require.NoError(t, err)
+
+		assert.False(t, d.Valid)
+		assert.Nil(t, d.Plan)
+		fields := make([]string, len(d.Errors))
+		for i, e := range d.Errors {
+			fields[i] = e.Field
+		}
+		assert.ElementsMatch(t, []string{"config.fail", "inputs", "inputs.shards", "files"}, fields)
+	})
+
+	t.Run("unknown plugin", func(t *testing.T) {
+		job := createJob(t, "nope", `{}`)
+		d, err := env.executor.DryRun(ctx, job.ID, RunOptions{Inputs: json.RawMessage(`{"region": "eu-west-1"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, []plugin.ConfigError{{Field: "plugin", Message: d.Errors[0].Message}}, d.Errors)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		_, err := env.executor.DryRun(ctx, uuid.New().String(), RunOptions{})
+		assert.Error(t, err)
+	})
+}
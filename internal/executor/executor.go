@@ -0,0 +1,854 @@
+/*
+Package executor runs jobs through their configured plugin.
+
+Every execution is recorded as a run (db.JobRun) with a sequential run
+number per job. A run gets its own workspace from the workspace.Manager;
+the workspace is handed to the plugin through plugin.WithExecution and is
+released according to the retention policy once the run finishes. Jobs
+with sandboxing enabled have their processes confined to the workspace by
+the sandbox package; the applied profile is stored on the run.
+
+Every run gets the job's resource limits, completed with the server-wide
+defaults and clamped to the server-wide maximums (see package limits). A
+run stopped by a limit fails with a failure reason such as "timeout".
+
+Runs are queued as pending and claimed atomically by exactly one executor
+or agent, which then holds a lease on the run: the lease names its owner
+and expires unless it is renewed. Executors claim queued runs of jobs
+without agent labels and renew the leases of their runs in the background;
+runs of jobs with agent labels wait for a remote agent offering all of the
+labels to claim them with Claim and renew its leases with Heartbeat. Reap
+requeues runs whose lease expired, so that several servers and agents can
+share one database without running a job twice.
+
+Jobs may allow failed runs to be retried (jobs.max_retries). Every attempt
+of a run is recorded with its outcome; a run that fails after being
+retried is moved to the dead letters (see deadletter.go), where it waits
+to be requeued or discarded.
+
+Jobs with a matrix (see package matrix) are started as a parent run with one
+child run per combination. Only the children are executed, at most
+max_parallel at a time; the parent's status aggregates theirs (see
+matrix.go). A failing child of a fail-fast matrix cancels its unfinished
+siblings.
+
+Jobs with a source (see package source) have their repository checked out
+into the workspace before the plugin runs; the commit is recorded with the
+run. Runs whose checkout fails fail with the reason "checkout_failed".
+
+Jobs with caches (see package cache) have them restored from the cache
+store after the checkout and saved after a successful run (see cache.go).
+The hit or miss of every cache is recorded with the run.
+
+Runs may be given standard input and input files (see package
+attachments). The attachments are recorded with the run and handed to it
+after the checkout; a run whose attachments cannot be handed over fails.
+
+Interactive jobs (jobs.interactive) run their processes under a
+pseudo-terminal (see package terminal). Users attach to the terminal of a
+running run through Terminal, which only knows the runs of this executor;
+the terminal is closed with the run's exit code once the run finishes.
+
+Runs of jobs requiring approval (jobs.requires_approval) are created
+awaiting approval and only queued once approved (see approval.go). Runs
+that are neither approved nor rejected within the approval TTL expire.
+
+Run Lifecycle:
+
+	AwaitingApproval --> Rejected/Expired
+	   |
+	   v
+	Pending -> Running -> Complete/Failed/DeadLetter
+	   |          |
+	   |          +--> Pending (lease expired or retried)
+	   +----------+--> Cancelled (matrix failed fast)
+*/
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/attachments"
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/events"
+	"github.com/klauern/gopher-tower/internal/inputs"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/matrix"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/terminal"
+	"github.com/klauern/gopher-tower/internal/testreport"
+	"github.com/klauern/gopher-tower/internal/workspace"
+)
+
+// Run statuses
+const (
+	StatusPending  = "pending"
+	StatusRunning  = "running"
+	StatusComplete = "complete"
+	StatusFailed   = "failed"
+	// StatusDeadLetter marks runs that failed after being retried
+	StatusDeadLetter = "dead_letter"
+	// StatusCancelled marks matrix runs cancelled by fail-fast and runs
+	// replaced by a newer run of their schedule
+	StatusCancelled = "cancelled"
+	// StatusAwaitingApproval marks runs that are queued once approved
+	StatusAwaitingApproval = "awaiting_approval"
+	// StatusRejected marks runs whose approval was rejected
+	StatusRejected = "rejected"
+	// StatusExpired marks runs that were not approved in time
+	StatusExpired = "expired"
+	// StatusSkipped marks scheduled fire times that did not start a run
+	StatusSkipped = "skipped"
+)
+
+// Job statuses set while a job has a run in flight and once it finishes
+const (
+	jobStatusActive   = "active"
+	jobStatusComplete = "complete"
+	jobStatusFailed   = "failed"
+)
+
+var (
+	ErrNoPlugin     = errors.New("job has no plugin configured")
+	ErrShuttingDown = errors.New("executor is shutting down")
+)
+
+// IsInvalidRun reports whether err, returned when starting a run, rejects
+// the job's configuration or the run's options rather than being a
+// server-side failure
+func IsInvalidRun(err error) bool {
+	switch {
+	case errors.Is(err, ErrNoPlugin),
+		errors.Is(err, plugin.ErrPluginNotFound),
+		errors.Is(err, plugin.ErrInvalidConfig),
+		errors.Is(err, limits.ErrInvalidLimits),
+		errors.Is(err, inputs.ErrInvalidSchema),
+		errors.Is(err, inputs.ErrInvalidInputs),
+		errors.Is(err, matrix.ErrInvalidMatrix),
+		errors.Is(err, source.ErrInvalidSource),
+		errors.Is(err, ErrNoSourceCache),
+		errors.Is(err, cache.ErrInvalidCache),
+		errors.Is(err, testreport.ErrInvalidReports),
+		errors.Is(err, attachments.ErrInvalidInput),
+		errors.Is(err, ErrNoAttachmentStore):
+		return true
+	}
+	return false
+}
+
+// RunQuerier defines the database operations used by the executor
+type RunQuerier interface {
+	GetJob(ctx context.Context, id string) (db.Job, error)
+	UpdateJobStatus(ctx context.Context, arg db.UpdateJobStatusParams) error
+	GetNextRunNumber(ctx context.Context, jobID string) (int64, error)
+	GetAttachment(ctx context.Context, id string) (db.Attachment, error)
+	CreateAttachment(ctx context.Context, arg db.CreateAttachmentParams) (db.Attachment, error)
+	CreateJobRun(ctx context.Context, arg db.CreateJobRunParams) (db.JobRun, error)
+	CreateJobRunAttempt(ctx context.Context, arg db.CreateJobRunAttemptParams) error
+	RetryJobRun(ctx context.Context, arg db.RetryJobRunParams) (int64, error)
+	CreateDeadLetter(ctx context.Context, arg db.CreateDeadLetterParams) (db.DeadLetter, error)
+	CountOpenDeadLetters(ctx context.Context) (int64, error)
+	StartJobRun(ctx context.Context, arg db.StartJobRunParams) (db.JobRun, error)
+	FinishJobRun(ctx context.Context, arg db.FinishJobRunParams) (db.JobRun, error)
+	GetJobRun(ctx context.Context, id string) (db.JobRun, error)
+	ListQueuedLocalRuns(ctx context.Context, limit int64) ([]db.JobRun, error)
+	ListQueuedAgentRuns(ctx context.Context, limit int64) ([]db.JobRun, error)
+	ClaimJobRun(ctx context.Context, arg db.ClaimJobRunParams) (db.JobRun, error)
+	RenewJobRunLease(ctx context.Context, arg db.RenewJobRunLeaseParams) (int64, error)
+	RenewLeases(ctx context.Context, arg db.RenewLeasesParams) (int64, error)
+	ListExpiredJobRuns(ctx context.Context, leaseExpiresAt sql.NullTime) ([]db.JobRun, error)
+	RequeueJobRun(ctx context.Context, arg db.RequeueJobRunParams) (int64, error)
+	AppendJobRunLogs(ctx context.Context, arg db.AppendJobRunLogsParams) (int64, error)
+	SetJobRunSandboxProfile(ctx context.Context, arg db.SetJobRunSandboxProfileParams) error
+	SetJobRunSourceSHA(ctx context.Context, arg db.SetJobRunSourceSHAParams) error
+	SetJobRunCacheResults(ctx context.Context, arg db.SetJobRunCacheResultsParams) error
+	SetJobRunPlan(ctx context.Context, arg db.SetJobRunPlanParams) error
+	ListChildRuns(ctx context.Context, parentRunID sql.NullString) ([]db.JobRun, error)
+	CancelChildRuns(ctx context.Context, parentRunID sql.NullString) ([]db.JobRun, error)
+	CancelJobRuns(ctx context.Context, jobID string) ([]db.JobRun, error)
+	CreateSkippedJobRun(ctx context.Context, arg db.CreateSkippedJobRunParams) (db.JobRun, error)
+	UpdateParentRun(ctx context.Context, arg db.UpdateParentRunParams) (int64, error)
+	DecideJobRun(ctx context.Context, arg db.DecideJobRunParams) (int64, error)
+	ListExpiredApprovals(ctx context.Context, approvalExpiresAt sql.NullTime) ([]db.JobRun, error)
+	ListJobTriggersBySource(ctx context.Context, sourceJobID string) ([]db.JobTrigger, error)
+	DeleteTestResultsByRun(ctx context.Context, runID string) error
+	CreateTestResult(ctx context.Context, arg db.CreateTestResultParams) error
+}
+
+// Config holds server-wide execution settings
+type Config struct {
+	// DefaultLimits apply to limits a job leaves unset
+	DefaultLimits limits.Limits
+	// MaxLimits cap the limits of every run
+	MaxLimits limits.Limits
+	// ID names the executor as the owner of its leases; a random ID based
+	// on the host name when empty. It must be unique among the executors
+	// sharing a database.
+	ID string
+	// LeaseTTL is how long an executor or agent holds a run without
+	// renewing its lease; DefaultLeaseTTL when zero
+	LeaseTTL time.Duration
+	// MaxAttempts is how often a run is claimed before an expired lease
+	// fails it instead of requeueing it; DefaultMaxAttempts when zero
+	MaxAttempts int
+	// Events receives dead-letter events; none are published when nil
+	Events events.Publisher
+	// DeadLetterThreshold is the number of open dead letters at which an
+	// alert is published; no alerts when zero
+	DeadLetterThreshold int
+	// ApprovalTTL is how long runs of jobs requiring approval wait for a
+	// decision; DefaultApprovalTTL when zero
+	ApprovalTTL time.Duration
+	// Sources mirrors the repositories of jobs with a source; such jobs
+	// cannot be run when nil
+	Sources *source.Cache
+	// Caches stores the caches of jobs between runs; jobs run without
+	// their caches when nil
+	Caches *cache.Store
+	// Attachments stores the files uploaded for jobs; runs cannot be given
+	// standard input or input files when nil
+	Attachments *attachments.Store
+}
+
+// Executor starts job runs in the background
+type Executor struct {
+	queries    RunQuerier
+	plugins    plugin.PluginRegistry
+	workspaces *workspace.Manager
+	cfg        Config
+
+	// queue wakes the dispatcher and agents waiting in Claim when runs are
+	// queued
+	queue queue
+
+	// active holds the attempts executed by this executor, by run ID
+	activeMu sync.Mutex
+	active   map[string]*attempt
+
+	// ctx is cancelled with ErrShuttingDown on Shutdown, stopping all
+	// in-flight runs
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	// numberMu serializes run number allocation
+	numberMu sync.Mutex
+}
+
+// New creates an executor and starts claiming queued runs in the
+// background
+func New(queries RunQuerier, plugins plugin.PluginRegistry, workspaces *workspace.Manager, cfg Config) *Executor {
+	if cfg.ID == "" {
+		cfg.ID = defaultID()
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	e := &Executor{
+		queries:    queries,
+		plugins:    plugins,
+		workspaces: workspaces,
+		cfg:        cfg,
+		active:     map[string]*attempt{},
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	e.wg.Add(2)
+	go e.dispatch()
+	go e.renew()
+	return e
+}
+
+// defaultID returns an executor ID unique to this process
+func defaultID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "executor"
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.New().String()[:8])
+}
+
+// ID returns the name of the executor as a lease owner
+func (e *Executor) ID() string {
+	return e.cfg.ID
+}
+
+// Start records a new pending run of a job and queues it. The job's plugin
+// configuration is validated up front so that configuration errors are
+// reported to the caller instead of as a failed run. Runs of jobs without
+// agent labels are claimed by an executor, usually this one; runs of jobs
+// with agent labels wait for a remote agent. Runs of jobs requiring
+// approval are queued once approved.
+func (e *Executor) Start(ctx context.Context, jobID string) (db.JobRun, error) {
+	return e.StartRun(ctx, jobID, RunOptions{})
+}
+
+// StartWithInputs is like Start but starts the run with inputs, a JSON
+// object validated against the job's input schema. The inputs are stored
+// verbatim with the run. Jobs without an input schema take no inputs.
+func (e *Executor) StartWithInputs(ctx context.Context, jobID string, data json.RawMessage) (db.JobRun, error) {
+	return e.StartRun(ctx, jobID, RunOptions{Inputs: data})
+}
+
+// RunOptions are the parameters of a new run
+type RunOptions struct {
+	// Inputs is a JSON object validated against the job's input schema;
+	// jobs without one only take the inputs of triggered runs
+	Inputs json.RawMessage
+	// Stdin is streamed into the standard input of the run's processes
+	Stdin *attachments.Stdin
+	// Files are placed into the run's working directory
+	Files []attachments.File
+	// ScheduledFor is the fire time of the job's schedule that started
+	// this run (see schedule.go)
+	ScheduledFor time.Time
+	// CaughtUp marks runs started for a fire time missed while no
+	// scheduler was running
+	CaughtUp bool
+	// DroppedFireTimes is the number of missed fire times a skipped run
+	// stands for when the scheduler dropped them; see SkipRun
+	DroppedFireTimes int
+	// TriggeredBy is the run whose completion started this run through a
+	// completion trigger (see triggers.go)
+	TriggeredBy string
+}
+
+// StartRun is like Start but starts the run with opts. Inputs are handled
+// like by StartWithInputs; the attachments making up the standard input
+// and input files are recorded with the run (see attachments.go).
+func (e *Executor) StartRun(ctx context.Context, jobID string, opts RunOptions) (db.JobRun, error) {
+	if e.ctx.Err() != nil {
+		return db.JobRun{}, ErrShuttingDown
+	}
+
+	job, err := e.queries.GetJob(ctx, jobID)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	if _, _, err := e.resolve(job); err != nil {
+		return db.JobRun{}, err
+	}
+	if _, err := e.limits(job); err != nil {
+		return db.JobRun{}, err
+	}
+	stored, err := checkInputs(job, opts)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	m, err := jobMatrix(job)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	if _, err := e.checkSource(job, len(agentLabels(job)) == 0); err != nil {
+		return db.JobRun{}, err
+	}
+	if _, err := jobCaches(job); err != nil {
+		return db.JobRun{}, err
+	}
+	if _, err := jobTestReports(job); err != nil {
+		return db.JobRun{}, err
+	}
+	// Last, since inline standard input is saved as an attachment
+	stdin, files, err := e.resolveAttachments(ctx, job, opts.Stdin, opts.Files)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+
+	run, err := e.createRun(ctx, job, db.CreateJobRunParams{
+		Inputs:           stored,
+		Stdin:            stdin,
+		InputFiles:       files,
+		ScheduledFor:     nullTime(opts.ScheduledFor),
+		CaughtUp:         opts.CaughtUp,
+		TriggeredByRunID: db.StringToNullString(opts.TriggeredBy),
+	}, m)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	e.queue.notify()
+	return run, nil
+}
+
+// Shutdown stops claiming runs, cancels all in-flight runs and waits for
+// them to be handed back to the queue
+func (e *Executor) Shutdown() {
+	e.cancel(ErrShuttingDown)
+	e.wg.Wait()
+}
+
+// resolve looks up the job's plugin and validates its configuration
+func (e *Executor) resolve(job db.Job) (plugin.Plugin, map[string]interface{}, error) {
+	if !job.Plugin.Valid || job.Plugin.String == "" {
+		return nil, nil, ErrNoPlugin
+	}
+	p, err := e.plugins.Get(job.Plugin.String)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := map[string]interface{}{}
+	if job.PluginConfig.Valid {
+		if err := json.Unmarshal([]byte(job.PluginConfig.String), &config); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", plugin.ErrInvalidConfig, err)
+		}
+	}
+	if err := p.Validate(config); err != nil {
+		return nil, nil, err
+	}
+	return p, config, nil
+}
+
+// prepared is a queued run ready to be claimed
+type prepared struct {
+	job     db.Job
+	plugin  plugin.Plugin
+	config  map[string]interface{}
+	limits  limits.Limits
+	source  *source.Source
+	caches  []cache.Entry
+	reports []string
+}
+
+// prepare resolves the job of a queued run. Runs whose job can no longer be
+// executed, e.g. because its configuration was changed after the run was
+// queued, are failed and reported as nil.
+func (e *Executor) prepare(ctx context.Context, run db.JobRun) (*prepared, error) {
+	job, err := e.queries.GetJob(ctx, run.JobID)
+	if err != nil {
+		return nil, err
+	}
+	p, config, err := e.resolve(job)
+	var (
+		runLimits limits.Limits
+		src       *source.Source
+		caches    []cache.Entry
+		reports   []string
+	)
+	if err == nil {
+		runLimits, err = e.limits(job)
+	}
+	if err == nil {
+		src, err = e.checkSource(job, !run.AgentLabels.Valid)
+	}
+	if err == nil {
+		caches, err = jobCaches(job)
+	}
+	if err == nil {
+		reports, err = jobTestReports(job)
+	}
+	if err != nil {
+		_ = e.finish(ctx, job.ID, run.ID, "", plugin.JobResult{ExitCode: -1}, err, "")
+		return nil, nil
+	}
+	return &prepared{job: job, plugin: p, config: config, limits: runLimits, source: src, caches: caches, reports: reports}, nil
+}
+
+// claim leases a queued run to owner. It returns sql.ErrNoRows when the run
+// was claimed by someone else in the meantime.
+func (e *Executor) claim(ctx context.Context, run db.JobRun, owner string, agentID sql.NullString, runLimits limits.Limits) (db.JobRun, time.Time, error) {
+	expires := time.Now().UTC().Add(e.LeaseTTL())
+	claimed, err := e.queries.ClaimJobRun(ctx, db.ClaimJobRunParams{
+		Status:         StatusRunning,
+		LeaseOwner:     db.StringToNullString(owner),
+		AgentID:        agentID,
+		LeaseExpiresAt: sql.NullTime{Time: expires, Valid: true},
+		Limits:         encodeLimits(runLimits),
+		ID:             run.ID,
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		err = sql.ErrNoRows
+	}
+	if err == nil && claimed.ParentRunID.Valid {
+		e.aggregate(ctx, claimed.ParentRunID.String)
+	}
+	return claimed, expires, err
+}
+
+// dispatch claims and executes queued local runs until Shutdown. Besides
+// being woken by Start, it looks for runs queued or requeued by other
+// executors sharing the database every half lease.
+func (e *Executor) dispatch() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.LeaseTTL() / 2)
+	defer ticker.Stop()
+	for {
+		// Subscribe before scanning so that runs queued in between wake us
+		ready := e.queue.wait()
+		if err := e.claimLocal(e.ctx); err != nil && e.ctx.Err() == nil {
+			log.Printf("Failed to claim queued runs: %v", err)
+		}
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ready:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimLocal claims the queued local runs and executes them in the
+// background
+func (e *Executor) claimLocal(ctx context.Context) error {
+	runs, err := e.queries.ListQueuedLocalRuns(ctx, queueScanLimit)
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		prep, err := e.prepare(ctx, run)
+		if err != nil {
+			return err
+		}
+		if prep == nil {
+			continue
+		}
+		claimed, _, err := e.claim(ctx, run, e.cfg.ID, sql.NullString{}, prep.limits)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		runCtx, cancel := context.WithCancelCause(e.ctx)
+		a := &attempt{cancel: cancel}
+		if prep.job.Interactive {
+			a.terminal = terminal.New()
+		}
+		e.activeMu.Lock()
+		if previous, ok := e.active[claimed.ID]; ok {
+			// An earlier attempt lost its lease before noticing
+			previous.cancel(ErrLeaseLost)
+		}
+		e.active[claimed.ID] = a
+		e.activeMu.Unlock()
+
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			defer cancel(nil)
+			e.execute(runCtx, a, prep, claimed)
+		}()
+	}
+	return nil
+}
+
+// attempt is a run being executed by this executor
+type attempt struct {
+	// cancel stops the attempt, with ErrLeaseLost when its lease is gone
+	cancel context.CancelCauseFunc
+	// terminal is the pseudo-terminal of runs of interactive jobs
+	terminal *terminal.Terminal
+}
+
+// untrack forgets a finished attempt and releases its workspace. A run that
+// was requeued and claimed again by this executor keeps its workspace for
+// the newer attempt.
+func (e *Executor) untrack(runID string, a *attempt, ws *workspace.Workspace, succeeded bool) {
+	e.activeMu.Lock()
+	defer e.activeMu.Unlock()
+	if e.active[runID] != a {
+		return
+	}
+	delete(e.active, runID)
+	if ws == nil {
+		return
+	}
+	if err := e.workspaces.Release(ws, succeeded); err != nil {
+		log.Printf("Failed to release workspace of run %s: %v", runID, err)
+	}
+}
+
+// limits returns the effective limits of a job's next run
+func (e *Executor) limits(job db.Job) (limits.Limits, error) {
+	var l limits.Limits
+	if job.Limits.Valid {
+		if err := json.Unmarshal([]byte(job.Limits.String), &l); err != nil {
+			return l, fmt.Errorf("%w: %v", limits.ErrInvalidLimits, err)
+		}
+	}
+	if err := l.Validate(); err != nil {
+		return l, err
+	}
+	return l.Effective(e.cfg.DefaultLimits, e.cfg.MaxLimits), nil
+}
+
+// createRun records a pending run of a job, or a matrix run when the job
+// has a matrix, with the inputs, standard input and input files of params.
+// Runs of jobs requiring approval await approval instead.
+func (e *Executor) createRun(ctx context.Context, job db.Job, params db.CreateJobRunParams, m *matrix.Matrix) (db.JobRun, error) {
+	e.numberMu.Lock()
+	defer e.numberMu.Unlock()
+
+	number, err := e.queries.GetNextRunNumber(ctx, job.ID)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	params.ID = uuid.New().String()
+	params.JobID = job.ID
+	params.RunNumber = number
+	params.Status = StatusPending
+	params.AgentLabels = encodeLabels(agentLabels(job))
+	if job.RequiresApproval {
+		params.Status = StatusAwaitingApproval
+		params.ApprovalExpiresAt = sql.NullTime{Time: time.Now().UTC().Add(e.ApprovalTTL()), Valid: true}
+	}
+	if m != nil {
+		return e.createMatrixRun(ctx, params, m)
+	}
+	return e.queries.CreateJobRun(ctx, params)
+}
+
+// execute runs a claimed run to completion and records the outcome. runCtx
+// is cancelled with ErrLeaseLost when the executor loses the run's lease.
+func (e *Executor) execute(runCtx context.Context, a *attempt, prep *prepared, run db.JobRun) {
+	// Bookkeeping must succeed even when the executor is shutting down
+	ctx := context.WithoutCancel(runCtx)
+	job := prep.job
+	exitCode := -1
+	if a.terminal != nil {
+		// Attached users are told how the run ended
+		defer func() { a.terminal.Close(exitCode) }()
+	}
+
+	ws, err := e.workspaces.Create(job.ID, run.ID)
+	if err != nil {
+		_ = e.finish(ctx, job.ID, run.ID, e.cfg.ID, plugin.JobResult{ExitCode: -1, Error: err.Error()}, err, "")
+		e.untrack(run.ID, a, nil, false)
+		return
+	}
+
+	execution := &plugin.Execution{
+		WorkDir:  ws.Dir(),
+		Env:      ws.Env(),
+		Limits:   prep.limits,
+		Terminal: a.terminal,
+	}
+	if err := ApplyInputs(execution, ws, []byte(run.Inputs.String)); err != nil {
+		_ = e.finish(ctx, job.ID, run.ID, e.cfg.ID, plugin.JobResult{ExitCode: -1, Error: err.Error()}, err, "")
+		e.untrack(run.ID, a, ws, false)
+		return
+	}
+	ApplyMatrix(execution, DecodeMatrixValues(run.MatrixValues))
+	var profile sql.NullString
+	if job.Sandbox {
+		execution.Sandbox = &sandbox.Policy{Writable: []string{ws.Path}}
+		profile = encodeJSON(sandbox.Plan(*execution.Sandbox))
+	}
+
+	_, err = e.queries.StartJobRun(ctx, db.StartJobRunParams{
+		WorkspacePath:  db.StringToNullString(ws.Path),
+		SandboxProfile: profile,
+		Limits:         encodeLimits(prep.limits),
+		ID:             run.ID,
+		LeaseOwner:     db.StringToNullString(e.cfg.ID),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Requeued before it even started
+		log.Printf("Abandoned run %s after losing its lease", run.ID)
+		e.untrack(run.ID, a, ws, false)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to mark run %s as running: %v", run.ID, err)
+	}
+	e.setJobStatus(ctx, job.ID, jobStatusActive)
+
+	var (
+		result plugin.JobResult
+		reason string
+	)
+	runErr := e.checkout(runCtx, prep.source, run.ID, execution)
+	if runErr != nil {
+		result, reason = plugin.JobResult{ExitCode: -1, Error: runErr.Error()}, ReasonCheckoutFailed
+	} else if runErr = ApplyAttachments(execution, ws, DecodeStdin(run.Stdin), DecodeInputFiles(run.InputFiles), e.openAttachment); runErr != nil {
+		result = plugin.JobResult{ExitCode: -1, Error: runErr.Error()}
+	} else {
+		caches := RestoreCaches(e.cfg.Caches, job.ID, prep.caches, execution)
+		e.recordCaches(ctx, run.ID, caches)
+		if plan, err := PlanRun(runCtx, prep.plugin, prep.config, execution); err == nil {
+			e.recordPlan(ctx, run.ID, &plan)
+		} else {
+			log.Printf("Failed to plan run %s: %v", run.ID, err)
+		}
+		result, runErr = RunPlugin(runCtx, prep.plugin, prep.config, execution)
+		reason = limits.ReasonOf(runErr)
+		exitCode = result.ExitCode
+		e.recordTestResults(ctx, run, CollectTestReports(run.ID, prep.reports, execution))
+		if runErr == nil && caches != nil {
+			SaveCaches(e.cfg.Caches, job.ID, prep.caches, caches, execution)
+			e.recordCaches(ctx, run.ID, caches)
+		}
+	}
+	switch cause := context.Cause(runCtx); {
+	case errors.Is(cause, ErrLeaseLost):
+		// The run was requeued; whoever claims it next records the outcome
+		log.Printf("Abandoned run %s after losing its lease", run.ID)
+	case errors.Is(cause, ErrRunCancelled):
+		log.Printf("Cancelled run %s", run.ID)
+	case errors.Is(cause, ErrShuttingDown):
+		e.release(ctx, run, result)
+	default:
+		_ = e.finish(ctx, job.ID, run.ID, e.cfg.ID, result, runErr, reason)
+	}
+	e.untrack(run.ID, a, ws, runErr == nil)
+}
+
+// RunPlugin executes a plugin with the given execution settings, enforcing
+// the execution's timeout. A run stopped by a limit fails with a
+// *limits.Error.
+func RunPlugin(ctx context.Context, p plugin.Plugin, config map[string]interface{}, execution *plugin.Execution) (plugin.JobResult, error) {
+	ctx = plugin.WithExecution(ctx, execution)
+	if execution.Limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(execution.Limits.Timeout))
+		defer cancel()
+	}
+	result, err := p.Execute(ctx, config)
+	if err != nil && limits.ReasonOf(err) == "" && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = &limits.Error{Reason: limits.ReasonTimeout, Err: err}
+	}
+	return result, err
+}
+
+// finish records the outcome of an attempt of a run leased by owner (none
+// for queued runs) and the resulting job status. A failed attempt is
+// retried while the job has retries left; a run that still fails after
+// being retried is dead-lettered. Runs that have already finished or are
+// leased by someone else are left alone and reported as sql.ErrNoRows.
+func (e *Executor) finish(ctx context.Context, jobID, runID, owner string, result plugin.JobResult, runErr error, reason string) error {
+	run, err := e.queries.GetJobRun(ctx, runID)
+	if err != nil {
+		log.Printf("Failed to record result of run %s: %v", runID, err)
+		return err
+	}
+
+	status, jobStatus := StatusComplete, jobStatusComplete
+	stderr := result.Error
+	if runErr != nil {
+		status, jobStatus = StatusFailed, jobStatusFailed
+		if stderr == "" {
+			stderr = runErr.Error()
+		}
+		retried, err := e.retry(ctx, run, owner, result, runErr, reason)
+		if err != nil {
+			log.Printf("Failed to record result of run %s: %v", runID, err)
+			return err
+		}
+		if retried {
+			return nil
+		}
+		if run.Attempt > 1 {
+			status = StatusDeadLetter
+		}
+	}
+
+	finished, err := e.queries.FinishJobRun(ctx, db.FinishJobRunParams{
+		Status:        status,
+		ExitCode:      db.Int64ToNullInt64(int64(result.ExitCode)),
+		Stdout:        db.StringToNullString(result.Output),
+		Stderr:        db.StringToNullString(stderr),
+		Metadata:      encodeMetadata(result),
+		FailureReason: db.StringToNullString(reason),
+		ID:            runID,
+		LeaseOwner:    db.StringToNullString(owner),
+	})
+	if err != nil {
+		log.Printf("Failed to record result of run %s: %v", runID, err)
+		return err
+	}
+	if run.ParentRunID.Valid {
+		// The job's status follows the matrix run
+		e.finishChild(ctx, run, status)
+	} else {
+		e.setJobStatus(ctx, jobID, jobStatus)
+		e.fireTriggers(ctx, finished, jobStatus)
+	}
+	e.recordAttempt(ctx, run, result, runErr, reason)
+	if status == StatusDeadLetter {
+		e.deadLetter(ctx, run, runErr, reason)
+	}
+	return nil
+}
+
+func (e *Executor) setJobStatus(ctx context.Context, jobID, status string) {
+	if err := e.queries.UpdateJobStatus(ctx, db.UpdateJobStatusParams{Status: status, ID: jobID}); err != nil {
+		log.Printf("Failed to set status of job %s to %s: %v", jobID, status, err)
+	}
+}
+
+// Metadata is the JSON document stored in job_runs.metadata
+type Metadata struct {
+	Plugin    map[string]interface{} `json:"plugin,omitempty"`
+	Artifacts []string               `json:"artifacts,omitempty"`
+}
+
+func encodeMetadata(result plugin.JobResult) sql.NullString {
+	if len(result.Metadata) == 0 && len(result.Artifacts) == 0 {
+		return sql.NullString{}
+	}
+	return encodeJSON(Metadata{Plugin: result.Metadata, Artifacts: result.Artifacts})
+}
+
+func encodeLimits(l limits.Limits) sql.NullString {
+	if l.IsZero() {
+		return sql.NullString{}
+	}
+	return encodeJSON(l)
+}
+
+// DecodeLimits parses the limits column of a job or run
+func DecodeLimits(s sql.NullString) *limits.Limits {
+	if !s.Valid {
+		return nil
+	}
+	var l limits.Limits
+	if err := json.Unmarshal([]byte(s.String), &l); err != nil {
+		return nil
+	}
+	return &l
+}
+
+// encodeJSON serializes a value for a nullable TEXT column
+func encodeJSON(v interface{}) sql.NullString {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to encode run data: %v", err)
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// DecodeSandboxProfile parses the sandbox_profile column of a run, returning
+// nil for runs that were not sandboxed
+func DecodeSandboxProfile(s sql.NullString) *sandbox.Profile {
+	if !s.Valid {
+		return nil
+	}
+	var p sandbox.Profile
+	if err := json.Unmarshal([]byte(s.String), &p); err != nil {
+		return nil
+	}
+	return &p
+}
+
+// DecodeMetadata parses the metadata column of a run
+func DecodeMetadata(s sql.NullString) Metadata {
+	var m Metadata
+	if s.Valid {
+		_ = json.Unmarshal([]byte(s.String), &m)
+	}
+	return m
+}
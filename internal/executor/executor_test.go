@@ -0,0 +1,318 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/db/migrate"
+	"github.com/klauern/gopher-tower/internal/db/migrations"
+	"github.com/klauern/gopher-tower/internal/inputs"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// fakePlugin writes a file into its workspace, reports the inputs it was
+// given in its metadata and fails or blocks until cancelled when asked to
+type fakePlugin struct{}
+
+func (fakePlugin) Name() string        { return "fake" }
+func (fakePlugin) Description() string { return "test plugin" }
+func (fakePlugin) Version() string     { return "0.0.1" }
+func (fakePlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+
+func (fakePlugin) Validate(config map[string]interface{}) error {
+	if _, ok := config["fail"].(bool); !ok {
+		return plugin.NewConfigError("fail", "is required")
+	}
+	return nil
+}
+
+func (fakePlugin) Execute(ctx context.Context, config map[string]interface{}) (plugin.JobResult, error) {
+	e := plugin.ExecutionFrom(ctx)
+	if e == nil {
+		return plugin.JobResult{}, errors.New("no execution")
+	}
+	if err := os.WriteFile(filepath.Join(e.WorkDir, "out.txt"), []byte(e.Env["HOME"]), 0o644); err != nil {
+		return plugin.JobResult{}, err
+	}
+	if block, _ := config["block"].(bool); block {
+		<-ctx.Done()
+		return plugin.JobResult{ExitCode: -1}, ctx.Err()
+	}
+	result := plugin.JobResult{Output: "done", Artifacts: []string{"out.txt"}}
+	if path := e.Env[inputs.FileEnv]; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return plugin.JobResult{}, err
+		}
+		result.Metadata = map[string]interface{}{"region": e.Env["INPUT_REGION"], "inputs": string(data)}
+	}
+	if config["fail"].(bool) {
+		result.ExitCode = 2
+		return result, plugin.ErrNonZeroExit
+	}
+	return result, nil
+}
+
+type testEnv struct {
+	conn       *sql.DB
+	queries    *db.Queries
+	registry   plugin.PluginRegistry
+	workspaces *workspace.Manager
+	executor   *Executor
+}
+
+func setup(t *testing.T) *testEnv {
+	t.Helper()
+	return setupWithConfig(t, Config{})
+}
+
+func setupWithConfig(t *testing.T, cfg Config) *testEnv {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	require.NoError(t, migrate.MigrateDB(path, migrations.Files))
+	conn, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	registry := plugin.NewRegistry()
+	require.NoError(t, registry.Register(fakePlugin{}))
+	workspaces, err := workspace.NewManager(workspace.Config{
+		Root:   filepath.Join(t.TempDir(), "workspaces"),
+		Policy: workspace.PolicyKeepOnFailure,
+	})
+	require.NoError(t, err)
+
+	queries := db.New(conn)
+	e := New(queries, registry, workspaces, cfg)
+	t.Cleanup(e.Shutdown)
+	return &testEnv{conn: conn, queries: queries, registry: registry, workspaces: workspaces, executor: e}
+}
+
+// newExecutor starts another executor sharing the environment's database
+func (env *testEnv) newExecutor(t *testing.T, cfg Config) *Executor {
+	t.Helper()
+	e := New(env.queries, env.registry, env.workspaces, cfg)
+	t.Cleanup(e.Shutdown)
+	return e
+}
+
+func (env *testEnv) createJob(t *testing.T, pluginName, config string) db.Job {
+	t.Helper()
+	job, err := env.queries.CreateJob(context.Background(), db.CreateJobParams{
+		ID:           uuid.New().String(),
+		Name:         "test",
+		Status:       "pending",
+		Plugin:       db.StringToNullString(pluginName),
+		PluginConfig: db.StringToNullString(config),
+	})
+	require.NoError(t, err)
+	return job
+}
+
+// waitForRun polls until the run has finished and the executor is done
+// with it
+func (env *testEnv) waitForRun(t *testing.T, id string) db.JobRun {
+	t.Helper()
+	var run db.JobRun
+	require.Eventually(t, func() bool {
+		var err error
+		run, err = env.queries.GetJobRun(context.Background(), id)
+		require.NoError(t, err)
+		return run.FinishedAt.Valid && !env.executor.tracks(id)
+	}, 5*time.Second, 10*time.Millisecond)
+	return run
+}
+
+// tracks reports whether the executor is still busy with a run, e.g.
+// releasing its workspace after recording the outcome
+func (e *Executor) tracks(runID string) bool {
+	e.activeMu.Lock()
+	defer e.activeMu.Unlock()
+	_, ok := e.active[runID]
+	return ok
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful run", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": false}`)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), run.RunNumber)
+		assert.Equal(t, StatusPending, run.Status)
+
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusComplete, run.Status)
+		assert.Equal(t, "done", run.Stdout.String)
+		assert.Equal(t, []string{"out.txt"}, DecodeMetadata(run.Metadata).Artifacts)
+		assert.True(t, run.WorkspacePath.Valid)
+		assert.Nil(t, DecodeSandboxProfile(run.SandboxProfile), "runs are not sandboxed by default")
+
+		// keep_on_failure removes workspaces of successful runs
+		_, err = env.workspaces.Open(job.ID, run.ID)
+		assert.ErrorIs(t, err, workspace.ErrNotFound)
+
+		updated, err := env.queries.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobStatusComplete, updated.Status)
+
+		second, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), second.RunNumber)
+		env.waitForRun(t, second.ID)
+	})
+
+	t.Run("failed run keeps workspace", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": true}`)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusFailed, run.Status)
+		assert.Equal(t, int64(2), run.ExitCode.Int64)
+		assert.Contains(t, run.Stderr.String, "non-zero")
+
+		ws, err := env.workspaces.Open(job.ID, run.ID)
+		require.NoError(t, err)
+		home, err := os.ReadFile(filepath.Join(ws.Dir(), "out.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, ws.Env()["HOME"], string(home))
+	})
+
+	t.Run("sandboxed run records profile", func(t *testing.T) {
+		env := setup(t)
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "sandboxed",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("fake"),
+			PluginConfig: db.StringToNullString(`{"fail": false}`),
+			Sandbox:      true,
+		})
+		require.NoError(t, err)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		profile := DecodeSandboxProfile(run.SandboxProfile)
+		require.NotNil(t, profile)
+		assert.Equal(t, []string{run.WorkspacePath.String}, profile.Writable)
+		assert.NotEmpty(t, profile.Mode)
+	})
+
+	t.Run("limits are completed and clamped", func(t *testing.T) {
+		env := setupWithConfig(t, Config{
+			DefaultLimits: limits.Limits{OpenFiles: 256, Timeout: limits.Duration(time.Hour)},
+			MaxLimits:     limits.Limits{MemoryBytes: 1 << 30},
+		})
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "limited",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("fake"),
+			PluginConfig: db.StringToNullString(`{"fail": false}`),
+			Limits:       db.StringToNullString(`{"memory_bytes": 4294967296}`),
+		})
+		require.NoError(t, err)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, &limits.Limits{
+			MemoryBytes: 1 << 30,
+			OpenFiles:   256,
+			Timeout:     limits.Duration(time.Hour),
+		}, DecodeLimits(run.Limits))
+		assert.False(t, run.FailureReason.Valid)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		env := setupWithConfig(t, Config{
+			DefaultLimits: limits.Limits{Timeout: limits.Duration(50 * time.Millisecond)},
+		})
+		job := env.createJob(t, "fake", `{"fail": false, "block": true}`)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusFailed, run.Status)
+		assert.Equal(t, limits.ReasonTimeout, run.FailureReason.String)
+	})
+
+	t.Run("invalid limits", func(t *testing.T) {
+		env := setup(t)
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "limited",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("fake"),
+			PluginConfig: db.StringToNullString(`{"fail": false}`),
+			Limits:       db.StringToNullString(`{"processes": -1}`),
+		})
+		require.NoError(t, err)
+		_, err = env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, limits.ErrInvalidLimits)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{}`)
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, plugin.ErrInvalidConfig)
+	})
+
+	t.Run("no plugin", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "", "")
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, ErrNoPlugin)
+	})
+
+	t.Run("unknown plugin", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "missing", `{}`)
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, plugin.ErrPluginNotFound)
+	})
+
+	t.Run("after shutdown", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": false}`)
+		env.executor.Shutdown()
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, ErrShuttingDown)
+	})
+}
+
+func TestIsInvalidRun(t *testing.T) {
+	for _, err := range []error{
+		ErrNoPlugin,
+		plugin.NewConfigError("fail", "is required"),
+		fmt.Errorf("%w: job declares no inputs", inputs.ErrInvalidInputs),
+		ErrNoAttachmentStore,
+	} {
+		assert.True(t, IsInvalidRun(err), err.Error())
+	}
+	for _, err := range []error{nil, ErrShuttingDown, sql.ErrNoRows, errors.New("database is locked")} {
+		assert.False(t, IsInvalidRun(err), "%v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/inputs"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/workspace"
+)
+
+// checkInputs validates the inputs of a new run of a job against the job's
+// input schema and returns them as stored with the run. Jobs without a
+// schema only accept the outputs a completion trigger forwards to them.
+func checkInputs(job db.Job, opts RunOptions) (sql.NullString, error) {
+	values, err := inputs.Decode(opts.Inputs)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	if !job.InputSchema.Valid {
+		if len(values) == 0 {
+			return sql.NullString{}, nil
+		}
+		if opts.TriggeredBy == "" {
+			return sql.NullString{}, fmt.Errorf("%w: job declares no inputs", inputs.ErrInvalidInputs)
+		}
+	} else {
+		schema, err := inputs.ParseSchema([]byte(job.InputSchema.String))
+		if err != nil {
+			return sql.NullString{}, err
+		}
+		if err := schema.Validate(values); err != nil {
+			return sql.NullString{}, err
+		}
+	}
+	if len(values) == 0 {
+		return db.StringToNullString("{}"), nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, opts.Inputs); err != nil {
+		return sql.NullString{}, fmt.Errorf("%w: %v", inputs.ErrInvalidInputs, err)
+	}
+	return db.StringToNullString(compact.String()), nil
+}
+
+// ApplyInputs exposes the stored inputs of a run to its processes: they are
+// written to the workspace and added to the execution's environment. Runs
+// without inputs are left unchanged.
+func ApplyInputs(execution *plugin.Execution, ws *workspace.Workspace, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	values, err := inputs.Decode(data)
+	if err != nil {
+		return err
+	}
+	path, err := ws.WriteInputs(data)
+	if err != nil {
+		return fmt.Errorf("failed to write inputs: %w", err)
+	}
+	addInputsEnv(execution, values, path)
+	return nil
+}
+
+// addInputsEnv adds the variables exposing inputs, written to the file at
+// path, to the execution's environment
+func addInputsEnv(execution *plugin.Execution, values map[string]interface{}, path string) {
+	env := inputs.Env(values)
+	env[inputs.FileEnv] = path
+	if execution.Env == nil {
+		execution.Env = env
+		return
+	}
+	maps.Copy(execution.Env, env)
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/inputs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartWithInputs(t *testing.T) {
+	ctx := context.Background()
+
+	createJob := func(t *testing.T, env *testEnv, schema string) db.Job {
+		t.Helper()
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "export",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("fake"),
+			PluginConfig: db.StringToNullString(`{"fail": false}`),
+			InputSchema:  db.StringToNullString(schema),
+		})
+		require.NoError(t, err)
+		return job
+	}
+	const schema = `{"type": "object", "properties": {"region": {"type": "string"}, "shards": {"type": "integer"}}, "required": ["region"]}`
+
+	t.Run("inputs are stored and exposed", func(t *testing.T) {
+		env := setup(t)
+		job := createJob(t, env, schema)
+
+		run, err := env.executor.StartWithInputs(ctx, job.ID, json.RawMessage(`{ "region": "eu-west-1", "shards": 10000000000000000001 }`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"region":"eu-west-1","shards":10000000000000000001}`, run.Inputs.String)
+
+		run = env.waitForRun(t, run.ID)
+		require.Equal(t, StatusComplete, run.Status)
+		metadata := DecodeMetadata(run.Metadata)
+		assert.Equal(t, "eu-west-1", metadata.Plugin["region"])
+		assert.Equal(t, run.Inputs.String, metadata.Plugin["inputs"])
+	})
+
+	t.Run("no inputs are validated as an empty object", func(t *testing.T) {
+		env := setup(t)
+		job := createJob(t, env, schema)
+
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, inputs.ErrInvalidInputs)
+	})
+
+	t.Run("invalid inputs", func(t *testing.T) {
+		env := setup(t)
+		job := createJob(t, env, schema)
+
+		for _, given := range []string{`{"region": 1}`, `{"region": "eu-west-1", "shards": 1.5}`, `["eu-west-1"]`} {
+			_, err := env.executor.StartWithInputs(ctx, job.ID, json.RawMessage(given))
+			assert.ErrorIs(t, err, inputs.ErrInvalidInputs, given)
+		}
+	})
+
+	t.Run("job without schema", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": false}`)
+
+		_, err := env.executor.StartWithInputs(ctx, job.ID, json.RawMessage(`{"region": "eu-west-1"}`))
+		assert.ErrorIs(t, err, inputs.ErrInvalidInputs)
+
+		run, err := env.executor.StartWithInputs(ctx, job.ID, json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.False(t, run.Inputs.Valid)
+		run = env.waitForRun(t, run.ID)
+		assert.Empty(t, DecodeMetadata(run.Metadata).Plugin)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		env := setup(t)
+		job := createJob(t, env, `{"type": "object", "properties": {"region": {"$ref": "#/$defs/region"}}}`)
+
+		_, err := env.executor.StartWithInputs(ctx, job.ID, json.RawMessage(`{"region": "eu-west-1"}`))
+		assert.ErrorIs(t, err, inputs.ErrInvalidSchema)
+	})
+}
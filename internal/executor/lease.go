@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+// Lease defaults for Config fields left zero
+const (
+	DefaultLeaseTTL    = 30 * time.Second
+	DefaultMaxAttempts = 3
+)
+
+// ReasonLeaseExpired is the failure reason of runs whose lease expired on
+// their last attempt
+const ReasonLeaseExpired = "lease_expired"
+
+// ErrLeaseLost is returned when an executor or agent reports on a run it no
+// longer holds, e.g. because its lease expired
+var ErrLeaseLost = errors.New("run is no longer leased by this executor")
+
+// LeaseTTL returns how long executors and agents hold a run without
+// renewing its lease
+func (e *Executor) LeaseTTL() time.Duration {
+	if e.cfg.LeaseTTL > 0 {
+		return e.cfg.LeaseTTL
+	}
+	return DefaultLeaseTTL
+}
+
+func (e *Executor) maxAttempts() int64 {
+	if e.cfg.MaxAttempts > 0 {
+		return int64(e.cfg.MaxAttempts)
+	}
+	return DefaultMaxAttempts
+}
+
+// renew renews the leases of the executor's runs every third of a lease
+// until Shutdown
+func (e *Executor) renew() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.LeaseTTL() / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.renewLeases(e.ctx)
+		}
+	}
+}
+
+// renewLeases renews the lease of every run the executor is executing and
+// cancels the runs whose lease was lost
+func (e *Executor) renewLeases(ctx context.Context) {
+	e.activeMu.Lock()
+	active := make(map[string]*attempt, len(e.active))
+	for id, a := range e.active {
+		active[id] = a
+	}
+	e.activeMu.Unlock()
+
+	expires := sql.NullTime{Time: time.Now().UTC().Add(e.LeaseTTL()), Valid: true}
+	for id, a := range active {
+		n, err := e.queries.RenewJobRunLease(ctx, db.RenewJobRunLeaseParams{
+			LeaseExpiresAt: expires,
+			ID:             id,
+			LeaseOwner:     db.StringToNullString(e.cfg.ID),
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to renew the lease on run %s: %v", id, err)
+			}
+			continue
+		}
+		if n == 0 {
+			log.Printf("Lost the lease on run %s, cancelling it", id)
+			a.cancel(ErrLeaseLost)
+		}
+	}
+}
+
+// Reap requeues running runs whose lease expired, so that another executor
+// or agent claims them, and returns how many runs were reaped. Runs on
+// their last attempt (see Config.MaxAttempts) fail with ReasonLeaseExpired
+// instead, keeping the output streamed so far; having been retried, they
+// are dead-lettered.
+func (e *Executor) Reap(ctx context.Context) (int, error) {
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	runs, err := e.queries.ListExpiredJobRuns(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	reaped := 0
+	for _, run := range runs {
+		owner := run.LeaseOwner.String
+		if run.Attempt >= e.maxAttempts() {
+			result := plugin.JobResult{ExitCode: -1, Output: run.Stdout.String, Error: run.Stderr.String}
+			runErr := fmt.Errorf("lease held by %s expired", owner)
+			if err := e.finish(ctx, run.JobID, run.ID, owner, result, runErr, ReasonLeaseExpired); err == nil {
+				log.Printf("Run %s failed: %s stopped renewing its lease on attempt %d", run.ID, owner, run.Attempt)
+				reaped++
+			}
+			continue
+		}
+
+		n, err := e.queries.RequeueJobRun(ctx, db.RequeueJobRunParams{
+			ID:             run.ID,
+			LeaseOwner:     run.LeaseOwner,
+			LeaseExpiresAt: now,
+		})
+		if err != nil {
+			log.Printf("Failed to requeue run %s: %v", run.ID, err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("Requeued run %s: %s stopped renewing its lease", run.ID, owner)
+			e.recordAttempt(ctx, run, plugin.JobResult{ExitCode: -1}, fmt.Errorf("lease held by %s expired", owner), ReasonLeaseExpired)
+			reaped++
+		}
+	}
+	if reaped > 0 {
+		e.queue.notify()
+	}
+	return reaped, nil
+}
+
+// RunReaper calls Reap and ExpireApprovals every half lease until ctx is
+// cancelled
+func (e *Executor) RunReaper(ctx context.Context) {
+	ticker := time.NewTicker(e.LeaseTTL() / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := e.Reap(ctx); err != nil {
+				log.Printf("Failed to reap expired run leases: %v", err)
+			}
+			if _, err := e.ExpireApprovals(ctx); err != nil {
+				log.Printf("Failed to expire run approvals: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeases(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("executors sharing a database run each job once", func(t *testing.T) {
+		env := setupWithConfig(t, Config{ID: "first"})
+		second := env.newExecutor(t, Config{ID: "second"})
+
+		var ids []string
+		for range 20 {
+			job := env.createJob(t, "fake", `{"fail": false}`)
+			starter := env.executor
+			if len(ids)%2 == 1 {
+				starter = second
+			}
+			run, err := starter.Start(ctx, job.ID)
+			require.NoError(t, err)
+			ids = append(ids, run.ID)
+		}
+
+		for _, id := range ids {
+			run := env.waitForRun(t, id)
+			assert.Equal(t, StatusComplete, run.Status)
+			assert.Equal(t, int64(1), run.Attempt, "run %s was claimed twice", id)
+			assert.Contains(t, []string{"first", "second"}, run.LeaseOwner.String)
+		}
+	})
+
+	t.Run("leases are renewed while running", func(t *testing.T) {
+		env := setupWithConfig(t, Config{ID: "first", LeaseTTL: 60 * time.Millisecond})
+		job := env.createJob(t, "fake", `{"fail": false, "block": true}`)
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			r, err := env.queries.GetJobRun(ctx, run.ID)
+			require.NoError(t, err)
+			return r.Status == StatusRunning
+		}, 5*time.Second, 10*time.Millisecond)
+
+		time.Sleep(200 * time.Millisecond)
+		n, err := env.executor.Reap(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "lease should have been renewed")
+	})
+
+	t.Run("runs of a dead executor are requeued", func(t *testing.T) {
+		env := setupWithConfig(t, Config{ID: "survivor"})
+		job := env.createJob(t, "fake", `{"fail": false}`)
+
+		// Queued and claimed at once by an executor that died before
+		// renewing its lease
+		tx, err := env.conn.Begin()
+		require.NoError(t, err)
+		run, err := env.queries.WithTx(tx).CreateJobRun(ctx, db.CreateJobRunParams{
+			ID:        uuid.New().String(),
+			JobID:     job.ID,
+			RunNumber: 1,
+			Status:    StatusPending,
+		})
+		require.NoError(t, err)
+		_, err = env.queries.WithTx(tx).ClaimJobRun(ctx, db.ClaimJobRunParams{
+			Status:         StatusRunning,
+			LeaseOwner:     db.StringToNullString("dead"),
+			LeaseExpiresAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			ID:             run.ID,
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		done := env.waitForRunWithReaper(t, run.ID)
+		assert.Equal(t, StatusComplete, done.Status)
+		assert.Equal(t, int64(2), done.Attempt)
+		assert.Equal(t, "survivor", done.LeaseOwner.String)
+	})
+
+	t.Run("executor losing its lease abandons the run", func(t *testing.T) {
+		env := setupWithConfig(t, Config{ID: "first", LeaseTTL: 60 * time.Millisecond})
+		job := env.createJob(t, "fake", `{"fail": false, "block": true}`)
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			r, err := env.queries.GetJobRun(ctx, run.ID)
+			require.NoError(t, err)
+			return r.Status == StatusRunning
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// Requeue the run behind the executor's back, as a reaper would
+		// after a network partition
+		n, err := env.queries.RequeueJobRun(ctx, db.RequeueJobRunParams{
+			ID:             run.ID,
+			LeaseOwner:     db.StringToNullString("first"),
+			LeaseExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+
+		// The first attempt is cancelled without being recorded as failed
+		// and the requeued run is claimed again
+		require.Eventually(t, func() bool {
+			r, err := env.queries.GetJobRun(ctx, run.ID)
+			require.NoError(t, err)
+			return r.Attempt == 2 && r.Status == StatusRunning
+		}, 5*time.Second, 10*time.Millisecond)
+		stored, err := env.queries.GetJobRun(ctx, run.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.FinishedAt.Valid)
+		assert.False(t, stored.FailureReason.Valid)
+	})
+}
+
+// waitForRunWithReaper reaps expired leases until the run has finished
+func (env *testEnv) waitForRunWithReaper(t *testing.T, id string) db.JobRun {
+	t.Helper()
+	var run db.JobRun
+	require.Eventually(t, func() bool {
+		_, err := env.executor.Reap(context.Background())
+		require.NoError(t, err)
+		run, err = env.queries.GetJobRun(context.Background(), id)
+		require.NoError(t, err)
+		return run.FinishedAt.Valid
+	}, 5*time.Second, 10*time.Millisecond)
+	return run
+}
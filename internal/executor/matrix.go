@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/matrix"
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+// ErrRunCancelled stops the runs of a matrix cancelled by fail-fast and
+// runs replaced by a newer run of their schedule
+var ErrRunCancelled = errors.New("run was cancelled")
+
+// jobMatrix returns the matrix of a job, or nil for jobs without one
+func jobMatrix(job db.Job) (*matrix.Matrix, error) {
+	if !job.Matrix.Valid {
+		return nil, nil
+	}
+	return matrix.Parse([]byte(job.Matrix.String))
+}
+
+// createMatrixRun records the parent run of a matrix and one child run per
+// combination, with the status of the parent. The children are numbered
+// after the parent. Called with numberMu held.
+func (e *Executor) createMatrixRun(ctx context.Context, params db.CreateJobRunParams, m *matrix.Matrix) (db.JobRun, error) {
+	combinations, err := m.Expand()
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	labels := params.AgentLabels
+	params.AgentLabels = sql.NullString{}
+	params.ChildCount = int64(len(combinations))
+	parent, err := e.queries.CreateJobRun(ctx, params)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+
+	for i, combination := range combinations {
+		_, err := e.queries.CreateJobRun(ctx, db.CreateJobRunParams{
+			ID:                uuid.New().String(),
+			JobID:             params.JobID,
+			RunNumber:         params.RunNumber + int64(i) + 1,
+			Status:            params.Status,
+			AgentLabels:       labels,
+			Inputs:            params.Inputs,
+			Stdin:             params.Stdin,
+			InputFiles:        params.InputFiles,
+			ParentRunID:       db.StringToNullString(parent.ID),
+			MatrixValues:      encodeJSON(combination),
+			MaxParallel:       int64(m.MaxParallel),
+			ApprovalExpiresAt: params.ApprovalExpiresAt,
+		})
+		if err != nil {
+			// Do not leave a partial matrix behind
+			e.cancelChildren(ctx, parent.ID)
+			return db.JobRun{}, err
+		}
+	}
+	return parent, nil
+}
+
+// finishChild records that a child run of a matrix finished with status:
+// the remaining children are cancelled when the child failed and the
+// matrix fails fast, and the parent's status is updated
+func (e *Executor) finishChild(ctx context.Context, run db.JobRun, status string) {
+	parentID := run.ParentRunID.String
+	if status == StatusFailed || status == StatusDeadLetter {
+		job, err := e.queries.GetJob(ctx, run.JobID)
+		if err != nil {
+			log.Printf("Failed to look up job of run %s: %v", run.ID, err)
+		} else if m, err := jobMatrix(job); err == nil && m != nil && m.FailFast {
+			if n := e.cancelChildren(ctx, parentID); n > 0 {
+				log.Printf("Cancelled %d runs of matrix run %s after run %s failed", n, parentID, run.ID)
+			}
+		}
+	}
+	e.aggregate(ctx, parentID)
+	// A slot for another child may have opened up
+	e.queue.notify()
+}
+
+// cancelChildren cancels the unfinished children of a matrix run, stopping
+// those executed by this executor, and returns how many were cancelled.
+// Children executed elsewhere are stopped when their lease can no longer
+// be renewed.
+func (e *Executor) cancelChildren(ctx context.Context, parentID string) int {
+	cancelled, err := e.queries.CancelChildRuns(ctx, db.StringToNullString(parentID))
+	if err != nil {
+		log.Printf("Failed to cancel runs of matrix run %s: %v", parentID, err)
+		return 0
+	}
+	e.activeMu.Lock()
+	for _, run := range cancelled {
+		if a, ok := e.active[run.ID]; ok {
+			a.cancel(ErrRunCancelled)
+		}
+	}
+	e.activeMu.Unlock()
+	e.aggregate(ctx, parentID)
+	return len(cancelled)
+}
+
+// aggregate updates the status of a matrix run from its children and, once
+// they have all finished, the status of the job
+func (e *Executor) aggregate(ctx context.Context, parentID string) {
+	children, err := e.queries.ListChildRuns(ctx, db.StringToNullString(parentID))
+	if err != nil {
+		log.Printf("Failed to update status of matrix run %s: %v", parentID, err)
+		return
+	}
+	status := aggregateStatus(children)
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	params := db.UpdateParentRunParams{Status: status, StartedAt: sql.NullTime{}, ID: parentID}
+	if status != StatusPending {
+		params.StartedAt = now
+	}
+	finished := status == StatusComplete || status == StatusFailed || status == StatusCancelled
+	if finished {
+		params.FinishedAt = now
+	}
+	n, err := e.queries.UpdateParentRun(ctx, params)
+	if err != nil {
+		log.Printf("Failed to update status of matrix run %s: %v", parentID, err)
+		return
+	}
+	if n > 0 && finished && len(children) > 0 {
+		jobStatus := jobStatusFailed
+		if status == StatusComplete {
+			jobStatus = jobStatusComplete
+		}
+		e.setJobStatus(ctx, children[0].JobID, jobStatus)
+		if parent, err := e.queries.GetJobRun(ctx, parentID); err == nil {
+			e.fireTriggers(ctx, parent, jobStatus)
+		}
+	}
+}
+
+// aggregateStatus returns the status of a matrix run with the given
+// children: pending until a child is started, running until every child
+// has finished, then failed if any child failed, cancelled if any child was
+// cancelled and complete otherwise
+func aggregateStatus(children []db.JobRun) string {
+	var started, unfinished, failed, cancelled int
+	for _, child := range children {
+		switch child.Status {
+		case StatusPending:
+			unfinished++
+			if child.Attempt > 0 {
+				started++
+			}
+		case StatusRunning:
+			unfinished++
+			started++
+		case StatusFailed, StatusDeadLetter:
+			failed++
+			started++
+		case StatusCancelled:
+			cancelled++
+		default:
+			started++
+		}
+	}
+	switch {
+	case unfinished > 0 && started == 0:
+		return StatusPending
+	case unfinished > 0:
+		return StatusRunning
+	case failed > 0:
+		return StatusFailed
+	case cancelled > 0:
+		return StatusCancelled
+	}
+	return StatusComplete
+}
+
+// DecodeMatrixValues parses the matrix values of a child run; nil for
+// other runs
+func DecodeMatrixValues(s sql.NullString) map[string]interface{} {
+	if !s.Valid {
+		return nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(s.String), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// ApplyMatrix exposes the matrix values of a child run to its processes as
+// MATRIX_<NAME> variables
+func ApplyMatrix(execution *plugin.Execution, values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	if execution.Env == nil {
+		execution.Env = map[string]string{}
+	}
+	maps.Copy(execution.Env, matrix.Env(values))
+}
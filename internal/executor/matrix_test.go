@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/matrix"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// matrixPlugin runs as a child of a matrix over the axis "n". It fails for
+// the value in "fail", blocks until cancelled for the value in "block" and
+// records how many runs executed at once.
+type matrixPlugin struct {
+	mu       sync.Mutex
+	running  int
+	peak     int
+	executed []string
+}
+
+func (*matrixPlugin) Name() string        { return "matrix" }
+func (*matrixPlugin) Description() string { return "test plugin" }
+func (*matrixPlugin) Version() string     { return "0.0.1" }
+func (*matrixPlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+func (*matrixPlugin) Validate(map[string]interface{}) error { return nil }
+
+func (p *matrixPlugin) Execute(ctx context.Context, config map[string]interface{}) (plugin.JobResult, error) {
+	n := plugin.ExecutionFrom(ctx).Env["MATRIX_N"]
+	p.mu.Lock()
+	p.running++
+	p.peak = max(p.peak, p.running)
+	p.executed = append(p.executed, n)
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	}()
+
+	if config["block"] == n {
+		<-ctx.Done()
+		return plugin.JobResult{ExitCode: -1}, ctx.Err()
+	}
+	time.Sleep(20 * time.Millisecond)
+	if config["fail"] == n {
+		return plugin.JobResult{ExitCode: 1}, errors.New("failed")
+	}
+	return plugin.JobResult{Metadata: map[string]interface{}{"n": n}}, nil
+}
+
+func (p *matrixPlugin) stats() (peak int, executed []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.peak, append([]string(nil), p.executed...)
+}
+
+func TestMatrixRuns(t *testing.T) {
+	ctx := context.Background()
+
+	setupMatrix := func(t *testing.T, m, config string) (*testEnv, *matrixPlugin, db.Job) {
+		t.Helper()
+		env := setup(t)
+		p := &matrixPlugin{}
+		require.NoError(t, env.registry.Register(p))
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "matrix",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("matrix"),
+			PluginConfig: db.StringToNullString(config),
+			Matrix:       db.StringToNullString(m),
+		})
+		require.NoError(t, err)
+		return env, p, job
+	}
+	children := func(t *testing.T, env *testEnv, parent db.JobRun) []db.JobRun {
+		t.Helper()
+		runs, err := env.queries.ListChildRuns(ctx, db.StringToNullString(parent.ID))
+		require.NoError(t, err)
+		for _, run := range runs {
+			require.Eventually(t, func() bool { return !env.executor.tracks(run.ID) }, 5*time.Second, 10*time.Millisecond)
+		}
+		return runs
+	}
+	statuses := func(runs []db.JobRun) map[string]string {
+		m := map[string]string{}
+		for _, run := range runs {
+			m[DecodeMatrixValues(run.MatrixValues)["n"].(string)] = run.Status
+		}
+		return m
+	}
+
+	t.Run("fans out and aggregates", func(t *testing.T) {
+		env, p, job := setupMatrix(t, `{"axes": {"n": ["1", "2", "3"]}, "exclude": [{"n": "3"}], "include": [{"n": "4"}]}`, `{}`)
+
+		parent, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), parent.ChildCount)
+		assert.Equal(t, int64(1), parent.RunNumber)
+
+		parent = env.waitForRun(t, parent.ID)
+		assert.Equal(t, StatusComplete, parent.Status)
+		assert.True(t, parent.StartedAt.Valid)
+
+		runs := children(t, env, parent)
+		require.Len(t, runs, 3)
+		for i, run := range runs {
+			assert.Equal(t, int64(i+2), run.RunNumber)
+			assert.Equal(t, DecodeMatrixValues(run.MatrixValues)["n"], DecodeMetadata(run.Metadata).Plugin["n"])
+		}
+		assert.Equal(t, map[string]string{"1": StatusComplete, "2": StatusComplete, "4": StatusComplete}, statuses(runs))
+		_, executed := p.stats()
+		assert.ElementsMatch(t, []string{"1", "2", "4"}, executed)
+
+		job, err = env.queries.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobStatusComplete, job.Status)
+	})
+
+	t.Run("max parallel", func(t *testing.T) {
+		env, p, job := setupMatrix(t, `{"axes": {"n": ["1", "2", "3", "4", "5"]}, "max_parallel": 2}`, `{}`)
+
+		parent, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		parent = env.waitForRun(t, parent.ID)
+		assert.Equal(t, StatusComplete, parent.Status)
+		peak, executed := p.stats()
+		assert.Len(t, executed, 5)
+		assert.LessOrEqual(t, peak, 2)
+	})
+
+	t.Run("failed child fails the matrix", func(t *testing.T) {
+		env, _, job := setupMatrix(t, `{"axes": {"n": ["1", "2", "3"]}, "max_parallel": 1}`, `{"fail": "1"}`)
+
+		parent, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		parent = env.waitForRun(t, parent.ID)
+		assert.Equal(t, StatusFailed, parent.Status)
+		assert.Equal(t, map[string]string{"1": StatusFailed, "2": StatusComplete, "3": StatusComplete}, statuses(children(t, env, parent)))
+
+		job, err = env.queries.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobStatusFailed, job.Status)
+	})
+
+	t.Run("fail fast cancels pending children", func(t *testing.T) {
+		env, p, job := setupMatrix(t, `{"axes": {"n": ["1", "2", "3"]}, "max_parallel": 1, "fail_fast": true}`, `{"fail": "1"}`)
+
+		parent, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		parent = env.waitForRun(t, parent.ID)
+		assert.Equal(t, StatusFailed, parent.Status)
+		assert.Equal(t, map[string]string{"1": StatusFailed, "2": StatusCancelled, "3": StatusCancelled}, statuses(children(t, env, parent)))
+		_, executed := p.stats()
+		assert.Equal(t, []string{"1"}, executed)
+	})
+
+	t.Run("fail fast stops running children", func(t *testing.T) {
+		env, _, job := setupMatrix(t, `{"axes": {"n": ["1", "2"]}, "fail_fast": true}`, `{"fail": "1", "block": "2"}`)
+
+		parent, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		parent = env.waitForRun(t, parent.ID)
+		assert.Equal(t, StatusFailed, parent.Status)
+		assert.Equal(t, map[string]string{"1": StatusFailed, "2": StatusCancelled}, statuses(children(t, env, parent)))
+	})
+
+	t.Run("invalid matrix", func(t *testing.T) {
+		env, _, job := setupMatrix(t, `{"axes": {"n": []}}`, `{}`)
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, matrix.ErrInvalidMatrix)
+	})
+}
+
+func TestAggregateStatus(t *testing.T) {
+	run := func(status string, attempt int64) db.JobRun {
+		return db.JobRun{Status: status, Attempt: attempt}
+	}
+	tests := []struct {
+		name     string
+		children []db.JobRun
+		want     string
+	}{
+		{name: "none started", children: []db.JobRun{run(StatusPending, 0), run(StatusPending, 0)}, want: StatusPending},
+		{name: "one running", children: []db.JobRun{run(StatusRunning, 1), run(StatusPending, 0)}, want: StatusRunning},
+		{name: "retry queued", children: []db.JobRun{run(StatusPending, 1), run(StatusPending, 0)}, want: StatusRunning},
+		{name: "some finished", children: []db.JobRun{run(StatusComplete, 1), run(StatusPending, 0)}, want: StatusRunning},
+		{name: "all complete", children: []db.JobRun{run(StatusComplete, 1), run(StatusComplete, 1)}, want: StatusComplete},
+		{name: "one failed", children: []db.JobRun{run(StatusComplete, 1), run(StatusDeadLetter, 2)}, want: StatusFailed},
+		{name: "failed fast", children: []db.JobRun{run(StatusFailed, 1), run(StatusCancelled, 0)}, want: StatusFailed},
+		{name: "cancelled", children: []db.JobRun{run(StatusComplete, 1), run(StatusCancelled, 0)}, want: StatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, aggregateStatus(tt.children))
+		})
+	}
+}
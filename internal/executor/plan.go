@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"maps"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+// PlanRun describes what a plugin does when run with config and
+// execution, with secrets masked. The plan's Env includes the variables
+// set by the executor; plugins that are no plugin.Planner only report the
+// environment.
+func PlanRun(ctx context.Context, p plugin.Plugin, config map[string]interface{}, execution *plugin.Execution) (plugin.Plan, error) {
+	var plan plugin.Plan
+	if planner, ok := p.(plugin.Planner); ok {
+		var err error
+		if plan, err = planner.Plan(plugin.WithExecution(ctx, execution), config); err != nil {
+			return plugin.Plan{}, err
+		}
+	}
+	env := maps.Clone(execution.Env)
+	maps.Copy(env, plan.Env)
+	plan.Env = env
+	return plan, nil
+}
+
+// recordPlan stores what the plugin of a run did, so that runs can be
+// compared later
+func (e *Executor) recordPlan(ctx context.Context, runID string, plan *plugin.Plan) {
+	if plan == nil {
+		return
+	}
+	if err := e.queries.SetJobRunPlan(ctx, db.SetJobRunPlanParams{
+		Plan: encodeJSON(plan),
+		ID:   runID,
+	}); err != nil {
+		log.Printf("Failed to record plan of run %s: %v", runID, err)
+	}
+}
+
+// DecodePlan parses the plan column of a run, returning nil for runs
+// without one
+func DecodePlan(s sql.NullString) *plugin.Plan {
+	if !s.Valid {
+		return nil
+	}
+	var plan plugin.Plan
+	if err := json.Unmarshal([]byte(s.String), &plan); err != nil {
+		return nil
+	}
+	return &plan
+}
@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordsPlan(t *testing.T) {
+	ctx := context.Background()
+	env := setup(t)
+	require.NoError(t, env.registry.Register(script.New()))
+
+	job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+		ID:           uuid.New().String(),
+		Name:         "planned",
+		Status:       "pending",
+		Plugin:       db.StringToNullString(script.Name),
+		PluginConfig: db.StringToNullString(`{"interpreter": "sh", "body": "echo $MODE", "env": {"MODE": "full"}}`),
+	})
+	require.NoError(t, err)
+
+	run, err := env.executor.Start(ctx, job.ID)
+	require.NoError(t, err)
+	run = env.waitForRun(t, run.ID)
+	require.Equal(t, StatusComplete, run.Status)
+
+	plan := DecodePlan(run.Plan)
+	require.NotNil(t, plan)
+	assert.NotEmpty(t, plan.Command)
+	assert.Equal(t, "full", plan.Env["MODE"])
+	assert.NotEmpty(t, plan.Env["HOME"])
+}
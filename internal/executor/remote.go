@@ -0,0 +1,254 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/cache"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/klauern/gopher-tower/internal/testreport"
+)
+
+// queueScanLimit bounds how many queued runs are inspected per attempt
+const queueScanLimit = 100
+
+// Assignment is a run claimed by a remote agent
+type Assignment struct {
+	Run            db.JobRun
+	Job            db.Job
+	Config         map[string]interface{}
+	Limits         limits.Limits
+	Source         *source.Source
+	Caches         []cache.Entry
+	TestReports    []string
+	LeaseExpiresAt time.Time
+}
+
+// RemoteResult is the outcome of a run reported by a remote agent
+type RemoteResult struct {
+	Result plugin.JobResult
+	// Error is the execution error; empty when the run succeeded
+	Error string
+	// FailureReason names the limit that stopped the run, if any
+	FailureReason string
+	// Sandbox is the sandbox profile applied by the agent, if any
+	Sandbox *sandbox.Profile
+	// SourceSHA is the commit the agent checked out, if any
+	SourceSHA string
+	// Caches reports the caches restored and saved by the agent, if any
+	Caches []cache.Result
+	// TestResults are the test cases found in the run's reports, if any
+	TestResults []testreport.Result
+	// Plan is what the agent's plugin did, if known
+	Plan *plugin.Plan
+}
+
+// Claim hands the oldest queued run whose labels are all offered by the
+// agent to the agent, waiting up to wait for one to be queued. It returns
+// nil when no run became available in time.
+func (e *Executor) Claim(ctx context.Context, agentID string, labels []string, wait time.Duration) (*Assignment, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		// Subscribe before scanning so that runs queued in between wake us
+		ready := e.queue.wait()
+		a, err := e.claimRemote(ctx, agentID, labels)
+		if err != nil || a != nil {
+			return a, err
+		}
+		select {
+		case <-ready:
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-e.ctx.Done():
+			return nil, ErrShuttingDown
+		}
+	}
+}
+
+func (e *Executor) claimRemote(ctx context.Context, agentID string, labels []string) (*Assignment, error) {
+	runs, err := e.queries.ListQueuedAgentRuns(ctx, queueScanLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if !MatchLabels(decodeLabels(run.AgentLabels), labels) {
+			continue
+		}
+		prep, err := e.prepare(ctx, run)
+		if err != nil {
+			return nil, err
+		}
+		if prep == nil {
+			continue
+		}
+		claimed, expires, err := e.claim(ctx, run, agentID, db.StringToNullString(agentID), prep.limits)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Claimed by another agent in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.setJobStatus(ctx, prep.job.ID, jobStatusActive)
+		return &Assignment{
+			Run:            claimed,
+			Job:            prep.job,
+			Config:         prep.config,
+			Limits:         prep.limits,
+			Source:         prep.source,
+			Caches:         prep.caches,
+			TestReports:    prep.reports,
+			LeaseExpiresAt: expires,
+		}, nil
+	}
+	return nil, nil
+}
+
+// Heartbeat renews the leases of all runs held by an agent and returns the
+// new expiry
+func (e *Executor) Heartbeat(ctx context.Context, agentID string) (time.Time, error) {
+	expires := time.Now().UTC().Add(e.LeaseTTL())
+	_, err := e.queries.RenewLeases(ctx, db.RenewLeasesParams{
+		LeaseExpiresAt: sql.NullTime{Time: expires, Valid: true},
+		LeaseOwner:     db.StringToNullString(agentID),
+	})
+	return expires, err
+}
+
+// AppendLogs appends streamed output to a run held by an agent
+func (e *Executor) AppendLogs(ctx context.Context, agentID, runID, stdout, stderr string) error {
+	n, err := e.queries.AppendJobRunLogs(ctx, db.AppendJobRunLogsParams{
+		Stdout:  stdout,
+		Stderr:  stderr,
+		ID:      runID,
+		AgentID: agentID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Complete records the result of a run held by an agent
+func (e *Executor) Complete(ctx context.Context, agentID, runID string, res RemoteResult) error {
+	run, err := e.queries.GetJobRun(ctx, runID)
+	if db.IsNotFound(err) {
+		return ErrLeaseLost
+	}
+	if err != nil {
+		return err
+	}
+	if run.LeaseOwner.String != agentID || run.Status != StatusRunning {
+		return ErrLeaseLost
+	}
+
+	// Bookkeeping must not be cut short by the agent hanging up
+	ctx = context.WithoutCancel(ctx)
+	if res.Sandbox != nil {
+		if err := e.queries.SetJobRunSandboxProfile(ctx, db.SetJobRunSandboxProfileParams{
+			SandboxProfile: encodeJSON(res.Sandbox),
+			ID:             runID,
+		}); err != nil {
+			log.Printf("Failed to record sandbox profile of run %s: %v", runID, err)
+		}
+	}
+	if res.SourceSHA != "" {
+		if err := e.queries.SetJobRunSourceSHA(ctx, db.SetJobRunSourceSHAParams{
+			SourceSha: db.StringToNullString(res.SourceSHA),
+			ID:        runID,
+		}); err != nil {
+			log.Printf("Failed to record source commit of run %s: %v", runID, err)
+		}
+	}
+	e.recordCaches(ctx, runID, res.Caches)
+	e.recordTestResults(ctx, run, res.TestResults)
+	e.recordPlan(ctx, runID, res.Plan)
+	var runErr error
+	if res.Error != "" {
+		runErr = errors.New(res.Error)
+	}
+	err = e.finish(ctx, run.JobID, runID, agentID, res.Result, runErr, res.FailureReason)
+	if db.IsNotFound(err) {
+		// Reaped while the result was on its way
+		return ErrLeaseLost
+	}
+	return err
+}
+
+// MatchLabels reports whether an agent offering offered can run a job
+// requiring required
+func MatchLabels(required, offered []string) bool {
+	for _, label := range required {
+		if !slices.Contains(offered, label) {
+			return false
+		}
+	}
+	return true
+}
+
+// agentLabels returns the labels a job's runs require of an agent, or nil
+// for jobs that run on the server
+func agentLabels(job db.Job) []string {
+	return decodeLabels(job.AgentLabels)
+}
+
+// encodeLabels stores agent labels as a JSON array; no labels is NULL
+func encodeLabels(labels []string) sql.NullString {
+	if len(labels) == 0 {
+		return sql.NullString{}
+	}
+	return encodeJSON(labels)
+}
+
+// decodeLabels parses a JSON array of agent labels
+func decodeLabels(s sql.NullString) []string {
+	if !s.Valid {
+		return nil
+	}
+	var labels []string
+	if err := json.Unmarshal([]byte(s.String), &labels); err != nil {
+		return nil
+	}
+	return labels
+}
+
+// queue is a broadcast signal for runs being queued for agents
+type queue struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// wait returns a channel that is closed on the next notify
+func (q *queue) wait() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.ch == nil {
+		q.ch = make(chan struct{})
+	}
+	return q.ch
+}
+
+func (q *queue) notify() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.ch != nil {
+		close(q.ch)
+		q.ch = nil
+	}
+}
@@ -0,0 +1,202 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (env *testEnv) createAgentJob(t *testing.T, labels string) db.Job {
+	t.Helper()
+	job, err := env.queries.CreateJob(context.Background(), db.CreateJobParams{
+		ID:           uuid.New().String(),
+		Name:         "remote",
+		Status:       "pending",
+		Plugin:       db.StringToNullString("fake"),
+		PluginConfig: db.StringToNullString(`{"fail": false}`),
+		AgentLabels:  db.StringToNullString(labels),
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func (env *testEnv) createAgent(t *testing.T) string {
+	t.Helper()
+	agent, err := env.queries.CreateAgent(context.Background(), db.CreateAgentParams{
+		ID:        uuid.New().String(),
+		Name:      "agent",
+		Labels:    `["linux"]`,
+		TokenHash: uuid.New().String(),
+	})
+	require.NoError(t, err)
+	return agent.ID
+}
+
+func TestRemoteRuns(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queued until a matching agent claims it", func(t *testing.T) {
+		env := setup(t)
+		agentID := env.createAgent(t)
+		job := env.createAgentJob(t, `["linux","gpu"]`)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, run.Status)
+		assert.True(t, run.AgentLabels.Valid)
+
+		a, err := env.executor.Claim(ctx, agentID, []string{"linux"}, 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.Nil(t, a, "agent lacks the gpu label")
+
+		a, err = env.executor.Claim(ctx, agentID, []string{"gpu", "linux", "x86_64"}, time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, a)
+		assert.Equal(t, run.ID, a.Run.ID)
+		assert.Equal(t, StatusRunning, a.Run.Status)
+		assert.Equal(t, map[string]interface{}{"fail": false}, a.Config)
+
+		stored, err := env.queries.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobStatusActive, stored.Status)
+
+		// Claimed runs are not handed out twice
+		again, err := env.executor.Claim(ctx, agentID, []string{"gpu", "linux"}, 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.Nil(t, again)
+
+		require.NoError(t, env.executor.AppendLogs(ctx, agentID, run.ID, "hello ", ""))
+		require.NoError(t, env.executor.AppendLogs(ctx, agentID, run.ID, "world\n", "warn\n"))
+		streamed, err := env.queries.GetJobRun(ctx, run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world\n", streamed.Stdout.String)
+		assert.Equal(t, "warn\n", streamed.Stderr.String)
+
+		expires, err := env.executor.Heartbeat(ctx, agentID)
+		require.NoError(t, err)
+		assert.True(t, expires.After(a.LeaseExpiresAt) || expires.Equal(a.LeaseExpiresAt))
+
+		err = env.executor.Complete(ctx, agentID, run.ID, RemoteResult{
+			Result:  plugin.JobResult{Output: "hello world\n", Artifacts: []string{"out.txt"}},
+			Sandbox: &sandbox.Profile{Mode: sandbox.ModeLandlock},
+		})
+		require.NoError(t, err)
+		done, err := env.queries.GetJobRun(ctx, run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusComplete, done.Status)
+		assert.Equal(t, []string{"out.txt"}, DecodeMetadata(done.Metadata).Artifacts)
+		assert.Equal(t, sandbox.ModeLandlock, DecodeSandboxProfile(done.SandboxProfile).Mode)
+
+		assert.ErrorIs(t, env.executor.Complete(ctx, agentID, run.ID, RemoteResult{}), ErrLeaseLost)
+		assert.ErrorIs(t, env.executor.AppendLogs(ctx, agentID, run.ID, "late", ""), ErrLeaseLost)
+	})
+
+	t.Run("waiting agent is woken", func(t *testing.T) {
+		env := setup(t)
+		agentID := env.createAgent(t)
+		job := env.createAgentJob(t, `["linux"]`)
+
+		claimed := make(chan *Assignment, 1)
+		go func() {
+			a, err := env.executor.Claim(ctx, agentID, []string{"linux"}, 5*time.Second)
+			assert.NoError(t, err)
+			claimed <- a
+		}()
+		time.Sleep(20 * time.Millisecond)
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+
+		select {
+		case a := <-claimed:
+			require.NotNil(t, a)
+			assert.Equal(t, run.ID, a.Run.ID)
+		case <-time.After(2 * time.Second):
+			t.Fatal("agent was not woken")
+		}
+	})
+
+	t.Run("failed remote run", func(t *testing.T) {
+		env := setup(t)
+		agentID := env.createAgent(t)
+		job := env.createAgentJob(t, `["linux"]`)
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		_, err = env.executor.Claim(ctx, agentID, []string{"linux"}, time.Second)
+		require.NoError(t, err)
+
+		other := env.createAgent(t)
+		assert.ErrorIs(t, env.executor.Complete(ctx, other, run.ID, RemoteResult{}), ErrLeaseLost)
+
+		require.NoError(t, env.executor.Complete(ctx, agentID, run.ID, RemoteResult{
+			Result:        plugin.JobResult{ExitCode: -1},
+			Error:         "context deadline exceeded",
+			FailureReason: "timeout",
+		}))
+		done, err := env.queries.GetJobRun(ctx, run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusFailed, done.Status)
+		assert.Equal(t, "timeout", done.FailureReason.String)
+		assert.Equal(t, "context deadline exceeded", done.Stderr.String)
+	})
+
+	t.Run("expired leases are requeued", func(t *testing.T) {
+		env := setupWithConfig(t, Config{LeaseTTL: 20 * time.Millisecond, MaxAttempts: 2})
+		agentID := env.createAgent(t)
+		job := env.createAgentJob(t, `["linux"]`)
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		_, err = env.executor.Claim(ctx, agentID, []string{"linux"}, time.Second)
+		require.NoError(t, err)
+		require.NoError(t, env.executor.AppendLogs(ctx, agentID, run.ID, "partial\n", ""))
+
+		n, err := env.executor.Reap(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "lease still valid")
+
+		time.Sleep(50 * time.Millisecond)
+		n, err = env.executor.Reap(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		requeued, err := env.queries.GetJobRun(ctx, run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, requeued.Status)
+		assert.Equal(t, int64(1), requeued.Attempt)
+		assert.False(t, requeued.LeaseOwner.Valid)
+		assert.False(t, requeued.Stdout.Valid, "output of the lost attempt is discarded")
+		assert.ErrorIs(t, env.executor.Complete(ctx, agentID, run.ID, RemoteResult{}), ErrLeaseLost)
+
+		// The last attempt fails instead of being requeued
+		other := env.createAgent(t)
+		a, err := env.executor.Claim(ctx, other, []string{"linux"}, time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, a)
+		assert.Equal(t, int64(2), a.Run.Attempt)
+		require.NoError(t, env.executor.AppendLogs(ctx, other, run.ID, "partial\n", ""))
+
+		time.Sleep(50 * time.Millisecond)
+		n, err = env.executor.Reap(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		reaped, err := env.queries.GetJobRun(ctx, run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusDeadLetter, reaped.Status)
+		assert.Equal(t, ReasonLeaseExpired, reaped.FailureReason.String)
+		assert.Equal(t, "partial\n", reaped.Stdout.String)
+		assert.ErrorIs(t, env.executor.Complete(ctx, other, run.ID, RemoteResult{}), ErrLeaseLost)
+	})
+}
+
+func TestMatchLabels(t *testing.T) {
+	assert.True(t, MatchLabels(nil, nil))
+	assert.True(t, MatchLabels([]string{"linux"}, []string{"gpu", "linux"}))
+	assert.False(t, MatchLabels([]string{"linux", "gpu"}, []string{"linux"}))
+}
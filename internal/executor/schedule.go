@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+)
+
+// SkipRun records a fire time of a job's schedule that did not start a run
+// as a run with StatusSkipped, so it shows up in the job's run history.
+// reason is recorded as the run's failure reason. With opts.DroppedFireTimes
+// set the run stands for that many fire times, the most recent of which is
+// opts.ScheduledFor.
+func (e *Executor) SkipRun(ctx context.Context, jobID string, opts RunOptions, reason string) (db.JobRun, error) {
+	e.numberMu.Lock()
+	defer e.numberMu.Unlock()
+
+	number, err := e.queries.GetNextRunNumber(ctx, jobID)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	return e.queries.CreateSkippedJobRun(ctx, db.CreateSkippedJobRunParams{
+		ID:               uuid.New().String(),
+		JobID:            jobID,
+		RunNumber:        number,
+		FailureReason:    db.StringToNullString(reason),
+		ScheduledFor:     nullTime(opts.ScheduledFor),
+		CaughtUp:         opts.CaughtUp,
+		DroppedFireTimes: int64(opts.DroppedFireTimes),
+	})
+}
+
+// CancelRuns cancels the unfinished runs of a job, stopping those executed
+// by this executor, and returns how many were cancelled. Runs executed
+// elsewhere are stopped when their lease can no longer be renewed.
+func (e *Executor) CancelRuns(ctx context.Context, jobID string) (int, error) {
+	cancelled, err := e.queries.CancelJobRuns(ctx, jobID)
+	if err != nil {
+		return 0, err
+	}
+	e.activeMu.Lock()
+	for _, run := range cancelled {
+		if a, ok := e.active[run.ID]; ok {
+			a.cancel(ErrRunCancelled)
+		}
+	}
+	e.activeMu.Unlock()
+	if len(cancelled) > 0 {
+		log.Printf("Cancelled %d run(s) of job %s", len(cancelled), jobID)
+	}
+	return len(cancelled), nil
+}
+
+// nullTime converts t to a sql.NullTime that is NULL for the zero time
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledRuns(t *testing.T) {
+	ctx := context.Background()
+	fireTime := time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+
+	t.Run("records the fire time", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": false}`)
+
+		run, err := env.executor.StartRun(ctx, job.ID, RunOptions{ScheduledFor: fireTime, CaughtUp: true})
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusComplete, run.Status)
+		assert.True(t, fireTime.Equal(run.ScheduledFor.Time))
+		assert.True(t, run.CaughtUp)
+	})
+
+	t.Run("skipped fire times", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": false}`)
+		first, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+
+		skipped, err := env.executor.SkipRun(ctx, job.ID, RunOptions{ScheduledFor: fireTime}, "overlap")
+		require.NoError(t, err)
+		assert.Equal(t, StatusSkipped, skipped.Status)
+		assert.Equal(t, first.RunNumber+1, skipped.RunNumber)
+		assert.Equal(t, "overlap", skipped.FailureReason.String)
+		assert.True(t, fireTime.Equal(skipped.ScheduledFor.Time))
+		assert.True(t, skipped.FinishedAt.Valid)
+		assert.Zero(t, skipped.DroppedFireTimes)
+		env.waitForRun(t, first.ID)
+
+		dropped, err := env.executor.SkipRun(ctx, job.ID, RunOptions{ScheduledFor: fireTime, DroppedFireTimes: 42}, "missed")
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), dropped.DroppedFireTimes)
+	})
+
+	t.Run("cancel runs", func(t *testing.T) {
+		env := setup(t)
+		job := env.createJob(t, "fake", `{"fail": false, "block": true}`)
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return env.executor.tracks(run.ID) }, 5*time.Second, 10*time.Millisecond)
+
+		n, err := env.executor.CancelRuns(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusCancelled, run.Status)
+
+		// Nothing is left to cancel
+		n, err = env.executor.CancelRuns(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchIndexesRunOutput(t *testing.T) {
+	ctx := context.Background()
+	env := setup(t)
+	require.NoError(t, env.registry.Register(script.New()))
+
+	search := func(query string) []db.SearchJobsRow {
+		t.Helper()
+		rows, err := env.queries.SearchJobs(ctx, db.SearchJobsParams{Query: query, Limit: 10})
+		require.NoError(t, err)
+		return rows
+	}
+
+	job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+		ID:           uuid.New().String(),
+		Name:         "nightly backup",
+		Description:  db.StringToNullString("Dumps the orders database"),
+		Status:       "pending",
+		Plugin:       db.StringToNullString(script.Name),
+		PluginConfig: db.StringToNullString(`{"interpreter": "sh", "body": "echo backing up; echo connection refused >&2; exit 1"}`),
+	})
+	require.NoError(t, err)
+
+	rows := search(`"orders"`)
+	require.Len(t, rows, 1)
+	assert.Equal(t, job.ID, rows[0].Job.ID)
+	assert.False(t, rows[0].RunNumber.Valid)
+	assert.Equal(t, "Dumps the <mark>orders</mark> database", rows[0].Snippet)
+	assert.Empty(t, search(`"refused"`))
+
+	run, err := env.executor.Start(ctx, job.ID)
+	require.NoError(t, err)
+	run = env.waitForRun(t, run.ID)
+	require.Equal(t, StatusFailed, run.Status)
+
+	rows = search(`"connection" "refused"`)
+	require.Len(t, rows, 1)
+	assert.Equal(t, job.ID, rows[0].Job.ID)
+	assert.Equal(t, run.RunNumber, rows[0].RunNumber.Int64)
+	assert.Contains(t, rows[0].Snippet, "<mark>connection</mark> <mark>refused</mark>")
+
+	// A job matching on its name ranks above one matching on output
+	other, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+		ID:     uuid.New().String(),
+		Name:   "connection check",
+		Status: "pending",
+	})
+	require.NoError(t, err)
+	rows = search(`"connection"`)
+	require.Len(t, rows, 2)
+	assert.Equal(t, other.ID, rows[0].Job.ID)
+	assert.Equal(t, job.ID, rows[1].Job.ID)
+
+	_, err = env.queries.UpdateJob(ctx, db.UpdateJobParams{ID: other.ID, Name: "ping", Status: "pending"})
+	require.NoError(t, err)
+	assert.Empty(t, search(`"check"`))
+	assert.Len(t, search(`"ping"`), 1)
+
+	require.NoError(t, env.queries.DeleteJob(ctx, job.ID))
+	assert.Empty(t, search(`"refused"`))
+	assert.Empty(t, search(`"orders"`))
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/source"
+)
+
+// ReasonCheckoutFailed is the failure reason of runs whose source could not
+// be checked out
+const ReasonCheckoutFailed = "checkout_failed"
+
+// ErrNoSourceCache is returned for local runs of jobs with a source when
+// the executor has no source cache
+var ErrNoSourceCache = errors.New("no source cache configured")
+
+// jobSource returns the source of a job, or nil for jobs without one
+func jobSource(job db.Job) (*source.Source, error) {
+	if !job.Source.Valid {
+		return nil, nil
+	}
+	return source.Decode([]byte(job.Source.String))
+}
+
+// checkSource validates the source of a job about to be run. Runs executed
+// by agents are checked out with the agent's cache.
+func (e *Executor) checkSource(job db.Job, local bool) (*source.Source, error) {
+	src, err := jobSource(job)
+	if err != nil || src == nil {
+		return src, err
+	}
+	if local && e.cfg.Sources == nil {
+		return nil, ErrNoSourceCache
+	}
+	return src, nil
+}
+
+// checkout checks the source of a run out into its working directory and
+// records the resolved commit with the run
+func (e *Executor) checkout(ctx context.Context, src *source.Source, runID string, execution *plugin.Execution) error {
+	sha, err := ApplySource(ctx, e.cfg.Sources, src, execution)
+	if err != nil || sha == "" {
+		return err
+	}
+	if err := e.queries.SetJobRunSourceSHA(context.WithoutCancel(ctx), db.SetJobRunSourceSHAParams{
+		SourceSha: db.StringToNullString(sha),
+		ID:        runID,
+	}); err != nil {
+		return fmt.Errorf("failed to record source commit: %w", err)
+	}
+	return nil
+}
+
+// ApplySource checks a run's source out into the execution's working
+// directory through cache and exposes the checkout to the run's processes
+// as GOPHER_TOWER_SOURCE_SHA and GOPHER_TOWER_SOURCE_DIR. It returns the
+// commit SHA, or "" for runs without a source.
+func ApplySource(ctx context.Context, cache *source.Cache, src *source.Source, execution *plugin.Execution) (string, error) {
+	if src == nil {
+		return "", nil
+	}
+	if cache == nil {
+		return "", ErrNoSourceCache
+	}
+	sha, err := cache.Checkout(ctx, *src, execution.WorkDir)
+	if err != nil {
+		return "", err
+	}
+	if execution.Env == nil {
+		execution.Env = map[string]string{}
+	}
+	execution.Env[source.SHAEnv] = sha
+	execution.Env[source.DirEnv] = filepath.Join(execution.WorkDir, src.Path)
+	return sha, nil
+}
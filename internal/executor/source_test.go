@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sourcePlugin reports the README of the checkout and the commit it was
+// told about
+type sourcePlugin struct{}
+
+func (sourcePlugin) Name() string        { return "source" }
+func (sourcePlugin) Description() string { return "test plugin" }
+func (sourcePlugin) Version() string     { return "0.0.1" }
+func (sourcePlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+func (sourcePlugin) Validate(map[string]interface{}) error { return nil }
+
+func (sourcePlugin) Execute(ctx context.Context, _ map[string]interface{}) (plugin.JobResult, error) {
+	env := plugin.ExecutionFrom(ctx).Env
+	data, err := os.ReadFile(filepath.Join(env[source.DirEnv], "README"))
+	if err != nil {
+		return plugin.JobResult{}, err
+	}
+	return plugin.JobResult{Output: string(data), Metadata: map[string]interface{}{"sha": env[source.SHAEnv]}}, nil
+}
+
+// flakySourcePlugin fails its first execution after reading the checkout
+type flakySourcePlugin struct {
+	sourcePlugin
+	calls *atomic.Int32
+}
+
+func (flakySourcePlugin) Name() string { return "flaky-source" }
+
+func (p flakySourcePlugin) Execute(ctx context.Context, config map[string]interface{}) (plugin.JobResult, error) {
+	result, err := p.sourcePlugin.Execute(ctx, config)
+	if err == nil && p.calls.Add(1) == 1 {
+		return plugin.JobResult{ExitCode: 1}, errors.New("flaked")
+	}
+	return result, err
+}
+
+// gitRepo creates a local repository with one commit and returns its path
+// and the commit's SHA
+func gitRepo(t *testing.T) (string, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "repo")
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "--quiet", path)
+	require.NoError(t, os.WriteFile(filepath.Join(path, "README"), []byte("hello"), 0o644))
+	run("-C", path, "add", "README")
+	run("-C", path, "commit", "--quiet", "-m", "initial")
+	return path, run("-C", path, "rev-parse", "HEAD")
+}
+
+func TestSourceCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	repo, sha := gitRepo(t)
+
+	createJob := func(t *testing.T, env *testEnv, src string) db.Job {
+		t.Helper()
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:     uuid.New().String(),
+			Name:   "sourced",
+			Status: "pending",
+			Plugin: db.StringToNullString("source"),
+			Source: db.StringToNullString(src),
+		})
+		require.NoError(t, err)
+		return job
+	}
+	setupSource := func(t *testing.T) *testEnv {
+		t.Helper()
+		cache, err := source.NewCache(t.TempDir())
+		require.NoError(t, err)
+		env := setupWithConfig(t, Config{Sources: cache})
+		require.NoError(t, env.registry.Register(sourcePlugin{}))
+		return env
+	}
+
+	t.Run("checks out and records the commit", func(t *testing.T) {
+		env := setupSource(t)
+		job := createJob(t, env, `{"repository": "file://`+repo+`", "path": "src"}`)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusComplete, run.Status)
+		assert.Equal(t, "hello", run.Stdout.String)
+		assert.Equal(t, sha, run.SourceSha.String)
+		assert.Equal(t, sha, DecodeMetadata(run.Metadata).Plugin["sha"])
+	})
+
+	t.Run("retries check out into a fresh workspace", func(t *testing.T) {
+		env := setupSource(t)
+		require.NoError(t, env.registry.Register(flakySourcePlugin{calls: &atomic.Int32{}}))
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:         uuid.New().String(),
+			Name:       "sourced",
+			Status:     "pending",
+			Plugin:     db.StringToNullString("flaky-source"),
+			Source:     db.StringToNullString(`{"repository": "file://` + repo + `"}`),
+			MaxRetries: 1,
+		})
+		require.NoError(t, err)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusComplete, run.Status, run.Stderr.String)
+		assert.Equal(t, int64(2), run.Attempt)
+		assert.Equal(t, "hello", run.Stdout.String)
+		assert.Equal(t, sha, run.SourceSha.String)
+	})
+
+	t.Run("failed checkout fails the run", func(t *testing.T) {
+		env := setupSource(t)
+		job := createJob(t, env, `{"repository": "`+repo+`", "ref": "missing"}`)
+
+		run, err := env.executor.Start(ctx, job.ID)
+		require.NoError(t, err)
+		run = env.waitForRun(t, run.ID)
+		assert.Equal(t, StatusFailed, run.Status)
+		assert.Equal(t, ReasonCheckoutFailed, run.FailureReason.String)
+		assert.Contains(t, run.Stderr.String, "missing")
+		assert.False(t, run.SourceSha.Valid)
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		env := setupSource(t)
+		job := createJob(t, env, `{"repository": "relative/repo"}`)
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, source.ErrInvalidSource)
+	})
+
+	t.Run("no source cache", func(t *testing.T) {
+		env := setup(t)
+		require.NoError(t, env.registry.Register(sourcePlugin{}))
+		job := createJob(t, env, `{"repository": "`+repo+`"}`)
+		_, err := env.executor.Start(ctx, job.ID)
+		assert.ErrorIs(t, err, ErrNoSourceCache)
+	})
+}
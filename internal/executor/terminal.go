@@ -0,0 +1,18 @@
+package executor
+
+import (
+	"github.com/klauern/gopher-tower/internal/terminal"
+)
+
+// Terminal returns the terminal of an interactive run executed by this
+// executor. It reports false for runs of other jobs, runs executed
+// elsewhere and runs that are not running.
+func (e *Executor) Terminal(runID string) (*terminal.Terminal, bool) {
+	e.activeMu.Lock()
+	defer e.activeMu.Unlock()
+	a, ok := e.active[runID]
+	if !ok || a.terminal == nil {
+		return nil, false
+	}
+	return a.terminal, true
+}
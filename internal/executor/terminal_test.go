@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// promptPlugin runs a shell prompting for a name
+type promptPlugin struct{}
+
+func (promptPlugin) Name() string        { return "prompt" }
+func (promptPlugin) Description() string { return "test plugin" }
+func (promptPlugin) Version() string     { return "0.0.1" }
+func (promptPlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+func (promptPlugin) Validate(map[string]interface{}) error { return nil }
+
+func (promptPlugin) Execute(ctx context.Context, _ map[string]interface{}) (plugin.JobResult, error) {
+	return plugin.RunProcess(ctx, plugin.Process{
+		Path: "sh",
+		Args: []string{"-c", `printf 'name? '; read name; echo "hello $name"`},
+	})
+}
+
+func TestTerminal(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminals are only supported on Linux")
+	}
+	ctx := context.Background()
+	env := setup(t)
+	require.NoError(t, env.registry.Register(promptPlugin{}))
+
+	job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+		ID:          uuid.New().String(),
+		Name:        "interactive",
+		Status:      "pending",
+		Plugin:      db.StringToNullString("prompt"),
+		Interactive: true,
+	})
+	require.NoError(t, err)
+	run, err := env.executor.Start(ctx, job.ID)
+	require.NoError(t, err)
+
+	var term *terminal.Terminal
+	require.Eventually(t, func() bool {
+		var ok bool
+		term, ok = env.executor.Terminal(run.ID)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	client, backlog, err := term.Attach()
+	require.NoError(t, err)
+
+	got := string(backlog)
+	for !strings.Contains(got, "name? ") {
+		select {
+		case chunk := <-client.Output():
+			got += string(chunk)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for the prompt, got %q", got)
+		}
+	}
+	_, err = term.Write([]byte("gopher\n"))
+	require.NoError(t, err)
+
+	finished := env.waitForRun(t, run.ID)
+	assert.Equal(t, StatusComplete, finished.Status)
+	assert.Contains(t, finished.Stdout.String, "hello gopher")
+	<-term.Done()
+	assert.Equal(t, 0, term.ExitCode())
+	_, ok := env.executor.Terminal(run.ID)
+	assert.False(t, ok, "finished runs have no terminal")
+}
+
+func TestTerminalNonInteractive(t *testing.T) {
+	env := setup(t)
+	job := env.createJob(t, "fake", `{"fail": false, "block": true}`)
+	run, err := env.executor.Start(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return env.executor.tracks(run.ID) }, 5*time.Second, 10*time.Millisecond)
+
+	_, ok := env.executor.Terminal(run.ID)
+	assert.False(t, ok)
+}
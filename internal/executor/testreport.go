@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"log"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/testreport"
+)
+
+// jobTestReports returns the report patterns of a job, or nil for jobs
+// without any
+func jobTestReports(job db.Job) ([]string, error) {
+	if !job.TestReports.Valid {
+		return nil, nil
+	}
+	return testreport.Decode([]byte(job.TestReports.String))
+}
+
+// CollectTestReports parses the JUnit XML reports a run wrote to its
+// working directory. Reports never fail a run: those that cannot be read
+// or parsed are logged and skipped.
+func CollectTestReports(runID string, patterns []string, execution *plugin.Execution) []testreport.Result {
+	if len(patterns) == 0 {
+		return nil
+	}
+	results, err := testreport.Collect(execution.WorkDir, patterns)
+	if err != nil {
+		log.Printf("Test reports of run %s: %v", runID, err)
+	}
+	return results
+}
+
+// recordTestResults stores the test cases reported by a run, replacing
+// those of earlier attempts
+func (e *Executor) recordTestResults(ctx context.Context, run db.JobRun, results []testreport.Result) {
+	if results == nil {
+		return
+	}
+	if err := e.queries.DeleteTestResultsByRun(ctx, run.ID); err != nil {
+		log.Printf("Failed to record test results of run %s: %v", run.ID, err)
+		return
+	}
+	for _, r := range results {
+		if err := e.queries.CreateTestResult(ctx, db.CreateTestResultParams{
+			RunID:     run.ID,
+			JobID:     run.JobID,
+			Suite:     r.Suite,
+			Name:      r.Name,
+			Classname: db.StringToNullString(r.Classname),
+			Duration:  r.Duration,
+			Status:    r.Status,
+			Message:   db.StringToNullString(r.Message),
+		}); err != nil {
+			log.Printf("Failed to record test results of run %s: %v", run.ID, err)
+			return
+		}
+	}
+}
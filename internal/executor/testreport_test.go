@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/klauern/gopher-tower/internal/testreport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reportPlugin writes the configured report to out/junit.xml and fails when
+// configured to
+type reportPlugin struct{}
+
+func (reportPlugin) Name() string        { return "report" }
+func (reportPlugin) Description() string { return "test plugin" }
+func (reportPlugin) Version() string     { return "0.0.1" }
+func (reportPlugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{}
+}
+func (reportPlugin) Validate(map[string]interface{}) error { return nil }
+
+func (reportPlugin) Execute(ctx context.Context, config map[string]interface{}) (plugin.JobResult, error) {
+	path := filepath.Join(plugin.ExecutionFrom(ctx).WorkDir, "out", "junit.xml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return plugin.JobResult{}, err
+	}
+	report, _ := config["report"].(string)
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil {
+		return plugin.JobResult{}, err
+	}
+	if config["fail"] == true {
+		return plugin.JobResult{ExitCode: 1}, errors.New("tests failed")
+	}
+	return plugin.JobResult{}, nil
+}
+
+func TestTestReports(t *testing.T) {
+	ctx := context.Background()
+	env := setup(t)
+	require.NoError(t, env.registry.Register(reportPlugin{}))
+
+	job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+		ID:     uuid.New().String(),
+		Name:   "tested",
+		Status: "pending",
+		Plugin: db.StringToNullString("report"),
+		PluginConfig: db.StringToNullString(`{"fail": true, "report": "<testsuite name=\"unit\">` +
+			`<testcase name=\"ok\" time=\"0.5\"/><testcase name=\"broken\"><failure message=\"boom\"/></testcase>` +
+			`</testsuite>"}`),
+		TestReports: db.StringToNullString(`["out/*.xml"]`),
+	})
+	require.NoError(t, err)
+
+	run, err := env.executor.Start(ctx, job.ID)
+	require.NoError(t, err)
+	run = env.waitForRun(t, run.ID)
+	// Reports of failed runs are recorded too
+	assert.Equal(t, StatusFailed, run.Status)
+
+	results, err := env.queries.ListTestResultsByRun(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "broken", results[0].Name)
+	assert.Equal(t, testreport.StatusFailed, results[0].Status)
+	assert.Equal(t, "boom", results[0].Message.String)
+	assert.Equal(t, "ok", results[1].Name)
+	assert.Equal(t, testreport.StatusPassed, results[1].Status)
+	assert.Equal(t, 0.5, results[1].Duration)
+	assert.Equal(t, job.ID, results[1].JobID)
+}
+
+func TestStartRunRejectsInvalidTestReports(t *testing.T) {
+	ctx := context.Background()
+	env := setup(t)
+	require.NoError(t, env.registry.Register(reportPlugin{}))
+
+	job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+		ID:          uuid.New().String(),
+		Name:        "tested",
+		Status:      "pending",
+		Plugin:      db.StringToNullString("report"),
+		TestReports: db.StringToNullString(`["/etc/*.xml"]`),
+	})
+	require.NoError(t, err)
+
+	_, err = env.executor.Start(ctx, job.ID)
+	assert.ErrorIs(t, err, testreport.ErrInvalidReports)
+}
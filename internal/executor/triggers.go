@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+
+	"github.com/klauern/gopher-tower/internal/db"
+)
+
+// Job statuses completion triggers fire on
+const (
+	TriggerOnComplete = "complete"
+	TriggerOnFailed   = "failed"
+	TriggerOnAny      = "any"
+)
+
+// MaxTriggerChain bounds how many runs a chain of completion triggers
+// starts, counting the run that started the chain
+const MaxTriggerChain = 16
+
+// ValidTriggerStatus reports whether a completion trigger may fire on
+// status
+func ValidTriggerStatus(status string) bool {
+	switch status {
+	case TriggerOnComplete, TriggerOnFailed, TriggerOnAny:
+		return true
+	}
+	return false
+}
+
+// FireTriggers starts the runs of the completion triggers of a job whose
+// status was set to jobStatus outside of a run, such as through the jobs
+// API. Without a finished run, the triggered runs receive no outputs and
+// start a new chain of triggers.
+func (e *Executor) FireTriggers(ctx context.Context, jobID, jobStatus string) {
+	e.fireTriggers(ctx, db.JobRun{JobID: jobID}, jobStatus)
+}
+
+// fireTriggers starts the runs of the completion triggers of a finished
+// run's job whose status became jobStatus. Each run receives the outputs of
+// the finished run as inputs. A trigger whose job already ran earlier in
+// the chain of triggered runs, or that would make the chain longer than
+// MaxTriggerChain, is skipped so that triggers cannot loop.
+func (e *Executor) fireTriggers(ctx context.Context, run db.JobRun, jobStatus string) {
+	triggers, err := e.queries.ListJobTriggersBySource(ctx, run.JobID)
+	if err != nil {
+		log.Printf("Failed to list the triggers of job %s: %v", run.JobID, err)
+		return
+	}
+	if len(triggers) == 0 {
+		return
+	}
+	chain := e.triggerChain(ctx, run)
+	for _, t := range triggers {
+		if t.OnStatus != TriggerOnAny && t.OnStatus != jobStatus {
+			continue
+		}
+		if slices.Contains(chain, t.TargetJobID) {
+			log.Printf("Trigger %s: not starting job %s, which already ran in this chain of triggers", t.ID, t.TargetJobID)
+			continue
+		}
+		if len(chain) >= MaxTriggerChain {
+			log.Printf("Trigger %s: not starting job %s, the chain of triggers reached %d runs", t.ID, t.TargetJobID, MaxTriggerChain)
+			continue
+		}
+		target, err := e.queries.GetJob(ctx, t.TargetJobID)
+		if err != nil {
+			log.Printf("Trigger %s: failed to look up job %s: %v", t.ID, t.TargetJobID, err)
+			continue
+		}
+		inputs, err := TriggerInputs(target, run)
+		if err != nil {
+			log.Printf("Trigger %s: %v", t.ID, err)
+			continue
+		}
+		if _, err := e.StartRun(ctx, t.TargetJobID, RunOptions{Inputs: inputs, TriggeredBy: run.ID}); err != nil {
+			log.Printf("Trigger %s: failed to start job %s: %v", t.ID, t.TargetJobID, err)
+		}
+	}
+}
+
+// triggerChain returns the jobs of a run and of the runs whose completion
+// triggered it, most recent first
+func (e *Executor) triggerChain(ctx context.Context, run db.JobRun) []string {
+	chain := []string{run.JobID}
+	for run.TriggeredByRunID.Valid && len(chain) < MaxTriggerChain {
+		var err error
+		run, err = e.queries.GetJobRun(ctx, run.TriggeredByRunID.String)
+		if err != nil {
+			// The run was deleted, which ends the chain
+			break
+		}
+		chain = append(chain, run.JobID)
+	}
+	return chain
+}
+
+// RunOutputs returns the outputs of a run: the metadata its plugin
+// reported, such as the query results of the SQL plugin
+func RunOutputs(run db.JobRun) map[string]interface{} {
+	return DecodeMetadata(run.Metadata).Plugin
+}
+
+// TriggerInputs returns the inputs of a run of job triggered by the
+// completion of run: the outputs of run that job's input schema declares,
+// or all of them when job has no input schema.
+func TriggerInputs(job db.Job, run db.JobRun) (json.RawMessage, error) {
+	outputs := RunOutputs(run)
+	if !job.InputSchema.Valid {
+		if len(outputs) == 0 {
+			return nil, nil
+		}
+		return json.Marshal(outputs)
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(job.InputSchema.String), &schema); err != nil {
+		return nil, err
+	}
+	inputs := make(map[string]interface{})
+	for name, v := range outputs {
+		if _, ok := schema.Properties[name]; ok {
+			inputs[name] = v
+		}
+	}
+	return json.Marshal(inputs)
+}
@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionTriggers(t *testing.T) {
+	ctx := context.Background()
+	env := setup(t)
+	const schema = `{"type": "object", "properties": {"region": {"type": "string"}}, "required": ["region"]}`
+	createJob := func(fail, schema string) db.Job {
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "chained",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("fake"),
+			PluginConfig: db.StringToNullString(`{"fail": ` + fail + `}`),
+			InputSchema:  db.StringToNullString(schema),
+		})
+		require.NoError(t, err)
+		return job
+	}
+	addTrigger := func(source, target db.Job, onStatus string) {
+		_, err := env.queries.CreateJobTrigger(ctx, db.CreateJobTriggerParams{
+			ID:          uuid.New().String(),
+			SourceJobID: source.ID,
+			TargetJobID: target.ID,
+			OnStatus:    onStatus,
+		})
+		require.NoError(t, err)
+	}
+	runs := func(job db.Job) []db.JobRun {
+		runs, err := env.queries.ListJobRunsByJob(ctx, db.ListJobRunsByJobParams{JobID: job.ID, Limit: 10})
+		require.NoError(t, err)
+		return runs
+	}
+
+	extract, load, cleanup := createJob("false", schema), createJob("false", schema), createJob("false", schema)
+	// Declares no inputs, so it receives every output
+	notify := createJob("false", "")
+	addTrigger(extract, load, TriggerOnComplete)
+	addTrigger(extract, notify, TriggerOnComplete)
+	addTrigger(extract, cleanup, TriggerOnFailed)
+	// Closes the loop extract -> load -> extract
+	addTrigger(load, extract, TriggerOnAny)
+
+	run, err := env.executor.StartWithInputs(ctx, extract.ID, []byte(`{"region": "eu-west-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, StatusComplete, env.waitForRun(t, run.ID).Status)
+
+	loads := runs(load)
+	require.Len(t, loads, 1)
+	assert.Equal(t, run.ID, loads[0].TriggeredByRunID.String)
+	// The outputs of the extract run that the load job declares as inputs
+	assert.JSONEq(t, `{"region": "eu-west-1"}`, loads[0].Inputs.String)
+	assert.Equal(t, StatusComplete, env.waitForRun(t, loads[0].ID).Status)
+
+	notifies := runs(notify)
+	require.Len(t, notifies, 1)
+	assert.JSONEq(t, `{"region": "eu-west-1", "inputs": "{\"region\":\"eu-west-1\"}"}`, notifies[0].Inputs.String)
+	assert.Equal(t, StatusComplete, env.waitForRun(t, notifies[0].ID).Status)
+
+	// Rerunning the triggered run accepts the forwarded outputs again
+	rerun, err := env.executor.StartRun(ctx, notify.ID, RunOptionsOf(notifies[0]))
+	require.NoError(t, err)
+	assert.Equal(t, run.ID, rerun.TriggeredByRunID.String)
+	assert.JSONEq(t, notifies[0].Inputs.String, rerun.Inputs.String)
+	assert.Equal(t, StatusComplete, env.waitForRun(t, rerun.ID).Status)
+
+	// The failure trigger did not fire and the loop was cut
+	assert.Empty(t, runs(cleanup))
+	assert.Len(t, runs(extract), 1)
+}
+
+func TestFireTriggers(t *testing.T) {
+	ctx := context.Background()
+	env := setup(t)
+	createJob := func() db.Job {
+		job, err := env.queries.CreateJob(ctx, db.CreateJobParams{
+			ID:           uuid.New().String(),
+			Name:         "chained",
+			Status:       "pending",
+			Plugin:       db.StringToNullString("fake"),
+			PluginConfig: db.StringToNullString(`{"fail": false}`),
+		})
+		require.NoError(t, err)
+		return job
+	}
+	source, target := createJob(), createJob()
+	_, err := env.queries.CreateJobTrigger(ctx, db.CreateJobTriggerParams{
+		ID:          uuid.New().String(),
+		SourceJobID: source.ID,
+		TargetJobID: target.ID,
+		OnStatus:    TriggerOnFailed,
+	})
+	require.NoError(t, err)
+	runs := func() []db.JobRun {
+		runs, err := env.queries.ListJobRunsByJob(ctx, db.ListJobRunsByJobParams{JobID: target.ID, Limit: 10})
+		require.NoError(t, err)
+		return runs
+	}
+
+	env.executor.FireTriggers(ctx, source.ID, "complete")
+	assert.Empty(t, runs())
+
+	// A status set without a run starts the target without inputs
+	env.executor.FireTriggers(ctx, source.ID, "failed")
+	started := runs()
+	require.Len(t, started, 1)
+	assert.False(t, started[0].TriggeredByRunID.Valid)
+	assert.False(t, started[0].Inputs.Valid)
+	assert.Equal(t, StatusComplete, env.waitForRun(t, started[0].ID).Status)
+}
+
+func TestTriggerInputs(t *testing.T) {
+	run := db.JobRun{Metadata: db.StringToNullString(`{"plugin": {"region": "eu-west-1", "rows": 42}}`)}
+
+	// Jobs without an input schema receive every output
+	inputs, err := TriggerInputs(db.Job{}, run)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"region": "eu-west-1", "rows": 42}`, string(inputs))
+
+	inputs, err = TriggerInputs(db.Job{}, db.JobRun{})
+	require.NoError(t, err)
+	assert.Nil(t, inputs)
+
+	job := db.Job{InputSchema: db.StringToNullString(`{"type": "object", "properties": {"rows": {"type": "integer"}, "table": {"type": "string"}}}`)}
+	inputs, err = TriggerInputs(job, run)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"rows": 42}`, string(inputs))
+
+	inputs, err = TriggerInputs(job, db.JobRun{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(inputs))
+}
@@ -0,0 +1,94 @@
+/*
+Package inputs validates the inputs a job run is started with and exposes
+them to the run's processes.
+
+A job may declare a JSON Schema its inputs are validated against:
+
+	{
+		"type": "object",
+		"properties": {
+			"region": {"type": "string", "enum": ["us-east-1", "eu-west-1"]},
+			"shards": {"type": "integer", "minimum": 1, "maximum": 16},
+			"tables": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["region"],
+		"additionalProperties": false
+	}
+
+The supported keywords are type, enum, const, properties, required,
+additionalProperties, items, minItems, maxItems, minimum, maximum,
+exclusiveMinimum, exclusiveMaximum, minLength, maxLength and pattern.
+$schema, $id, $comment, title, description, default, examples and format
+are accepted as annotations. Defaults are not applied to the inputs.
+
+Every top-level input is passed to the run as an INPUT_<NAME> environment
+variable, the name upper-cased with characters other than letters, digits
+and underscores replaced by underscores. Strings are passed as is, other
+values as JSON. The complete inputs are also written to a JSON file whose
+path is passed in GOPHER_TOWER_INPUTS_FILE.
+*/
+package inputs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidSchema = errors.New("invalid input schema")
+	ErrInvalidInputs = errors.New("invalid inputs")
+)
+
+const (
+	// EnvPrefix prefixes the environment variable of every input
+	EnvPrefix = "INPUT_"
+	// FileEnv names the environment variable holding the inputs file path
+	FileEnv = "GOPHER_TOWER_INPUTS_FILE"
+)
+
+// Decode parses inputs, which must be a JSON object. Empty data and null
+// decode to no inputs.
+func Decode(data []byte) (map[string]interface{}, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	v, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInputs, err)
+	}
+	switch m := v.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return m, nil
+	}
+	return nil, fmt.Errorf("%w: must be a JSON object", ErrInvalidInputs)
+}
+
+// Env returns the environment variables exposing inputs to a run
+func Env(inputs map[string]interface{}) map[string]string {
+	env := make(map[string]string, len(inputs))
+	for name, v := range inputs {
+		value, ok := v.(string)
+		if !ok {
+			value = render(v)
+		}
+		env[EnvName(name)] = value
+	}
+	return env
+}
+
+// EnvName returns the environment variable an input is passed in
+func EnvName(name string) string {
+	return EnvPrefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,39 @@
+package inputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	for _, empty := range []string{"", " ", "null"} {
+		values, err := Decode([]byte(empty))
+		require.NoError(t, err, empty)
+		assert.Empty(t, values, empty)
+	}
+
+	values, err := Decode([]byte(`{"region": "eu-west-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", values["region"])
+
+	for _, invalid := range []string{`[1]`, `"x"`, `{"a":`, `{} {}`} {
+		_, err := Decode([]byte(invalid))
+		assert.ErrorIs(t, err, ErrInvalidInputs, invalid)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	values, err := Decode([]byte(`{"region": "eu-west-1", "shards": 4, "ratio": 0.50, "dry-run": true, "tables": ["a", "b"], "label": null}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"INPUT_REGION":  "eu-west-1",
+		"INPUT_SHARDS":  "4",
+		"INPUT_RATIO":   "0.50",
+		"INPUT_DRY_RUN": "true",
+		"INPUT_TABLES":  `["a","b"]`,
+		"INPUT_LABEL":   "null",
+	}, Env(values))
+}
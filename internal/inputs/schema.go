@@ -0,0 +1,429 @@
+package inputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Types accepted by the "type" keyword
+var schemaTypes = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+// annotations are keywords that are accepted but do not affect validation
+var annotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"format":      true,
+}
+
+// Schema is a compiled JSON Schema. Only the subset of keywords documented
+// in the package comment is supported; schemas using any other keyword are
+// rejected rather than silently validating less than they declare.
+type Schema struct {
+	types    []string
+	enum     []interface{}
+	hasConst bool
+	constant interface{}
+
+	properties map[string]*Schema
+	required   []string
+	// additional validates properties not declared in properties; nil
+	// allows any value
+	additional   *Schema
+	noAdditional bool
+
+	items              *Schema
+	minItems, maxItems *int
+
+	minimum, maximum                   *big.Rat
+	exclusiveMinimum, exclusiveMaximum *big.Rat
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+}
+
+// ParseSchema compiles an input schema. The schema must describe an object,
+// since inputs are always given as a JSON object.
+func ParseSchema(data []byte) (*Schema, error) {
+	v, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: must be an object", ErrInvalidSchema)
+	}
+	s, err := compile("schema", m)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(s.types, []string{"object"}) {
+		return nil, fmt.Errorf(`%w: schema must have "type": "object"`, ErrInvalidSchema)
+	}
+	return s, nil
+}
+
+// Problem is a violation of the schema by the input at Path, e.g.
+// "inputs.tables[1]"
+type Problem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every violation of a schema by a set of inputs
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.Path + ": " + p.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidInputs, strings.Join(problems, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidInputs)
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInputs
+}
+
+// Validate checks inputs against the schema, reporting every violation as
+// a *ValidationError
+func (s *Schema) Validate(inputs map[string]interface{}) error {
+	var problems []Problem
+	s.validate("inputs", inputs, &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// decode parses JSON keeping numbers as json.Number, so that they compare
+// and render exactly as given
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+func compile(path string, m map[string]interface{}) (*Schema, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidSchema, path, fmt.Sprintf(format, args...))
+	}
+
+	s := &Schema{}
+	for _, key := range sortedKeys(m) {
+		v := m[key]
+		var err error
+		switch key {
+		case "type":
+			s.types, err = compileTypes(v)
+		case "enum":
+			values, ok := v.([]interface{})
+			if !ok || len(values) == 0 {
+				return nil, invalid("enum must be a non-empty array")
+			}
+			s.enum = values
+		case "const":
+			s.hasConst, s.constant = true, v
+		case "properties":
+			props, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, invalid("properties must be an object")
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, prop := range props {
+				sub, ok := prop.(map[string]interface{})
+				if !ok {
+					return nil, invalid("property %q must be a schema", name)
+				}
+				if s.properties[name], err = compile(path+"."+name, sub); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = compileStrings(v)
+		case "additionalProperties":
+			switch a := v.(type) {
+			case bool:
+				s.noAdditional = !a
+			case map[string]interface{}:
+				s.additional, err = compile(path+".*", a)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, invalid("additionalProperties must be a boolean or a schema")
+			}
+		case "items":
+			sub, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, invalid("items must be a schema")
+			}
+			if s.items, err = compile(path+"[]", sub); err != nil {
+				return nil, err
+			}
+		case "minItems":
+			s.minItems, err = compileCount(v)
+		case "maxItems":
+			s.maxItems, err = compileCount(v)
+		case "minLength":
+			s.minLength, err = compileCount(v)
+		case "maxLength":
+			s.maxLength, err = compileCount(v)
+		case "minimum":
+			s.minimum, err = compileNumber(v)
+		case "maximum":
+			s.maximum, err = compileNumber(v)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum, err = compileNumber(v)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum, err = compileNumber(v)
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				return nil, invalid("pattern must be a string")
+			}
+			if s.pattern, err = regexp.Compile(p); err != nil {
+				return nil, invalid("pattern: %v", err)
+			}
+		default:
+			if !annotations[key] {
+				return nil, invalid("unsupported keyword %q", key)
+			}
+		}
+		if err != nil {
+			return nil, invalid("%s: %v", key, err)
+		}
+	}
+	return s, nil
+}
+
+func compileTypes(v interface{}) ([]string, error) {
+	var types []string
+	switch t := v.(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		var err error
+		if types, err = compileStrings(t); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("must be a string or an array of strings")
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("must name at least one type")
+	}
+	for _, t := range types {
+		if !slices.Contains(schemaTypes, t) {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+	}
+	return types, nil
+}
+
+func compileStrings(v interface{}) ([]string, error) {
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an array of strings")
+	}
+	strs := make([]string, len(values))
+	for i, value := range values {
+		if strs[i], ok = value.(string); !ok {
+			return nil, fmt.Errorf("must be an array of strings")
+		}
+	}
+	return strs, nil
+}
+
+func compileCount(v interface{}) (*int, error) {
+	r, err := compileNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() || r.Sign() < 0 || !r.Num().IsInt64() {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+	n := int(r.Num().Int64())
+	return &n, nil
+}
+
+func compileNumber(v interface{}) (*big.Rat, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("must be a number")
+	}
+	r, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return nil, fmt.Errorf("must be a number")
+	}
+	return r, nil
+}
+
+func (s *Schema) validate(path string, v interface{}, problems *[]Problem) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool { return hasType(v, t) }) {
+		fail("must be of type %s", strings.Join(s.types, " or "))
+		return
+	}
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(e interface{}) bool { return equal(e, v) }) {
+		fail("must be one of %s", render(s.enum))
+	}
+	if s.hasConst && !equal(s.constant, v) {
+		fail("must be %s", render(s.constant))
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := value[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		for _, name := range sortedKeys(value) {
+			if prop, ok := s.properties[name]; ok {
+				prop.validate(path+"."+name, value[name], problems)
+			} else if s.noAdditional {
+				fail("unknown property %q", name)
+			} else if s.additional != nil {
+				s.additional.validate(path+"."+name, value[name], problems)
+			}
+		}
+	case []interface{}:
+		if s.minItems != nil && len(value) < *s.minItems {
+			fail("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(value) > *s.maxItems {
+			fail("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range value {
+				s.items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if s.minLength != nil && length < *s.minLength {
+			fail("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			fail("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(value) {
+			fail("must match %s", s.pattern)
+		}
+	case json.Number:
+		r, ok := new(big.Rat).SetString(value.String())
+		if !ok {
+			fail("must be a valid number")
+			return
+		}
+		if s.minimum != nil && r.Cmp(s.minimum) < 0 {
+			fail("must be at least %s", s.minimum.RatString())
+		}
+		if s.maximum != nil && r.Cmp(s.maximum) > 0 {
+			fail("must be at most %s", s.maximum.RatString())
+		}
+		if s.exclusiveMinimum != nil && r.Cmp(s.exclusiveMinimum) <= 0 {
+			fail("must be greater than %s", s.exclusiveMinimum.RatString())
+		}
+		if s.exclusiveMaximum != nil && r.Cmp(s.exclusiveMaximum) >= 0 {
+			fail("must be less than %s", s.exclusiveMaximum.RatString())
+		}
+	}
+}
+
+// hasType reports whether a decoded JSON value is of a schema type
+func hasType(v interface{}, t string) bool {
+	switch value := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case map[string]interface{}:
+		return t == "object"
+	case []interface{}:
+		return t == "array"
+	case string:
+		return t == "string"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		r, ok := new(big.Rat).SetString(value.String())
+		return t == "integer" && ok && r.IsInt()
+	}
+	return false
+}
+
+// equal compares decoded JSON values; numbers are equal when their values
+// are, so 1 equals 1.0
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		rx, okx := new(big.Rat).SetString(x.String())
+		ry, oky := new(big.Rat).SetString(y.String())
+		return okx && oky && rx.Cmp(ry) == 0
+	case []interface{}:
+		y, ok := b.([]interface{})
+		return ok && slices.EqualFunc(x, y, equal)
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, vx := range x {
+			vy, ok := y[k]
+			if !ok || !equal(vx, vy) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func render(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+package inputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exportSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "Export inputs",
+	"type": "object",
+	"properties": {
+		"region": {"type": "string", "enum": ["us-east-1", "eu-west-1"]},
+		"shards": {"type": "integer", "minimum": 1, "maximum": 16, "default": 4},
+		"ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+		"tables": {"type": "array", "items": {"type": "string", "pattern": "^[a-z_]+$"}, "minItems": 1, "maxItems": 3},
+		"label": {"type": ["string", "null"], "minLength": 2, "maxLength": 4},
+		"mode": {"const": "full"},
+		"tags": {"type": "object", "additionalProperties": {"type": "string"}}
+	},
+	"required": ["region"],
+	"additionalProperties": false
+}`
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{name: "supported keywords", schema: exportSchema},
+		{name: "empty object schema", schema: `{"type": "object"}`},
+		{name: "not JSON", schema: `{"type":`, wantErr: true},
+		{name: "not an object", schema: `["object"]`, wantErr: true},
+		{name: "root is not an object schema", schema: `{"type": "string"}`, wantErr: true},
+		{name: "root without type", schema: `{"properties": {}}`, wantErr: true},
+		{name: "unsupported keyword", schema: `{"type": "object", "properties": {"a": {"$ref": "#/$defs/a"}}}`, wantErr: true},
+		{name: "unknown type", schema: `{"type": "object", "properties": {"a": {"type": "float"}}}`, wantErr: true},
+		{name: "invalid pattern", schema: `{"type": "object", "properties": {"a": {"pattern": "("}}}`, wantErr: true},
+		{name: "negative length", schema: `{"type": "object", "properties": {"a": {"minLength": -1}}}`, wantErr: true},
+		{name: "empty enum", schema: `{"type": "object", "properties": {"a": {"enum": []}}}`, wantErr: true},
+		{name: "required not strings", schema: `{"type": "object", "required": [1]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchema([]byte(tt.schema))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSchema)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := ParseSchema([]byte(exportSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		inputs string
+		// want lists the expected problems; none when the inputs are valid
+		want []string
+	}{
+		{name: "minimal", inputs: `{"region": "eu-west-1"}`},
+		{
+			name:   "all inputs",
+			inputs: `{"region": "us-east-1", "shards": 16, "ratio": 0.25, "tables": ["users", "orders"], "label": null, "mode": "full", "tags": {"team": "data"}}`,
+		},
+		{name: "integral number is an integer", inputs: `{"region": "eu-west-1", "shards": 2.0}`},
+		{name: "missing required", inputs: `{}`, want: []string{`inputs: missing required property "region"`}},
+		{name: "not in enum", inputs: `{"region": "ap-south-1"}`, want: []string{`inputs.region: must be one of ["us-east-1","eu-west-1"]`}},
+		{name: "wrong type", inputs: `{"region": 1}`, want: []string{"inputs.region: must be of type string"}},
+		{name: "fraction is not an integer", inputs: `{"region": "eu-west-1", "shards": 1.5}`, want: []string{"inputs.shards: must be of type integer"}},
+		{name: "above maximum", inputs: `{"region": "eu-west-1", "shards": 17}`, want: []string{"inputs.shards: must be at most 16"}},
+		{name: "exclusive bound", inputs: `{"region": "eu-west-1", "ratio": 1}`, want: []string{"inputs.ratio: must be less than 1"}},
+		{name: "unknown property", inputs: `{"region": "eu-west-1", "debug": true}`, want: []string{`inputs: unknown property "debug"`}},
+		{name: "const", inputs: `{"region": "eu-west-1", "mode": "delta"}`, want: []string{`inputs.mode: must be "full"`}},
+		{name: "additional properties schema", inputs: `{"region": "eu-west-1", "tags": {"team": 1}}`, want: []string{"inputs.tags.team: must be of type string"}},
+		{
+			name:   "every problem is reported",
+			inputs: `{"tables": ["Users", "orders", "a", "b"], "label": "x"}`,
+			want: []string{
+				`inputs: missing required property "region"`,
+				"inputs.label: must be at least 2 characters long",
+				"inputs.tables: must have at most 3 items",
+				"inputs.tables[0]: must match ^[a-z_]+$",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := Decode([]byte(tt.inputs))
+			require.NoError(t, err)
+			err = schema.Validate(values)
+			if len(tt.want) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidInputs)
+			for _, problem := range tt.want {
+				assert.Contains(t, err.Error(), problem)
+			}
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Len(t, verr.Problems, len(tt.want))
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package keymanager
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,8 +13,10 @@ import (
 	"time"
 
 	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/aead/subtle"
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/tink"
+	"golang.org/x/crypto/scrypt"
 )
 
 type secretData struct {
@@ -28,6 +32,8 @@ type keysetMetadata struct {
 	CreatedAt      time.Time `json:"created_at"`
 	LastRotated    time.Time `json:"last_rotated"`
 	NextRotation   time.Time `json:"next_rotation"`
+	// Salt of the key derived from the master password
+	Salt []byte `json:"salt,omitempty"`
 }
 
 type TinkManager struct {
@@ -35,12 +41,17 @@ type TinkManager struct {
 	storageDir string
 	primitive  tink.AEAD
 	keyHandle  *keyset.Handle
-	metadata   keysetMetadata
-	mu         sync.RWMutex
+	// masterKey encrypts the keyset stored in storageDir; nil without a
+	// master password
+	masterKey tink.AEAD
+	metadata  keysetMetadata
+	mu        sync.RWMutex
 }
 
 const (
 	metadataFile = "keyset_metadata.json"
+	// keysetFile holds the keyset encrypted with the master key
+	keysetFile = "keyset.json"
 )
 
 func init() {
@@ -125,9 +136,9 @@ func (t *TinkManager) Initialize(ctx context.Context, config Config) error {
 	}
 
 	// Initialize Tink AEAD primitive
-	kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	kh, err := t.loadOrInitKeyset()
 	if err != nil {
-		return fmt.Errorf("failed to create keyset handle: %w", err)
+		return err
 	}
 
 	primitive, err := aead.New(kh)
@@ -168,6 +179,71 @@ func (t *TinkManager) loadOrInitMetadata() error {
 	return nil
 }
 
+// loadOrInitKeyset reads the keyset stored in the storage directory, or
+// creates and stores one. Without a master password the keyset is kept in
+// memory only, so secrets cannot be read by later managers.
+func (t *TinkManager) loadOrInitKeyset() (*keyset.Handle, error) {
+	if t.config.MasterPassword == "" {
+		kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create keyset handle: %w", err)
+		}
+		return kh, nil
+	}
+
+	if len(t.metadata.Salt) == 0 {
+		t.metadata.Salt = make([]byte, 16)
+		if _, err := rand.Read(t.metadata.Salt); err != nil {
+			return nil, fmt.Errorf("failed to create salt: %w", err)
+		}
+	}
+	key, err := scrypt.Key([]byte(t.config.MasterPassword), t.metadata.Salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive master key: %w", err)
+	}
+	if t.masterKey, err = subtle.NewAESGCM(key); err != nil {
+		return nil, fmt.Errorf("failed to create master key: %w", err)
+	}
+
+	path := filepath.Join(t.storageDir, keysetFile)
+	if err := t.checkFilePermissions(path); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("keyset file is insecure: %w", err)
+		}
+		kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create keyset handle: %w", err)
+		}
+		return kh, t.saveKeyset(kh)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyset: %w", err)
+	}
+	kh, err := keyset.Read(keyset.NewJSONReader(bytes.NewReader(data)), t.masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keyset, wrong master password?: %w", err)
+	}
+	return kh, nil
+}
+
+// saveKeyset stores kh encrypted with the master key; a no-op without a
+// master password
+func (t *TinkManager) saveKeyset(kh *keyset.Handle) error {
+	if t.masterKey == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := kh.Write(keyset.NewJSONWriter(&buf), t.masterKey); err != nil {
+		return fmt.Errorf("failed to encrypt keyset: %w", err)
+	}
+	path := filepath.Join(t.storageDir, keysetFile)
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to save keyset: %w", err)
+	}
+	return nil
+}
+
 func (t *TinkManager) saveMetadata() error {
 	data, err := json.Marshal(t.metadata)
 	if err != nil {
@@ -211,10 +287,13 @@ func (t *TinkManager) RotateKeys(ctx context.Context) error {
 		t.metadata.NextRotation = now.Add(t.config.RotationPolicy.Interval)
 	}
 
-	// Save metadata before switching keys
+	// Save metadata and the new keyset before switching keys
 	if err := t.saveMetadata(); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
+	if err := t.saveKeyset(newHandle); err != nil {
+		return err
+	}
 
 	// Switch to new key
 	t.primitive = newPrimitive
@@ -291,6 +370,10 @@ func (t *TinkManager) GetSecret(ctx context.Context, key string) (string, error)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
 	if t.primitive == nil {
 		return "", fmt.Errorf("secret manager not initialized")
 	}
@@ -326,6 +409,10 @@ func (t *TinkManager) SetSecret(ctx context.Context, key, value string) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
 	if t.primitive == nil {
 		return fmt.Errorf("secret manager not initialized")
 	}
@@ -355,6 +442,10 @@ func (t *TinkManager) DeleteSecret(ctx context.Context, key string) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
 	path := filepath.Join(t.storageDir, key+".secret")
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete secret: %w", err)
@@ -387,6 +478,14 @@ func (t *TinkManager) Close() error {
 	return nil
 }
 
+// validateKey rejects keys that do not name a file in the storage directory
+func validateKey(key string) error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return fmt.Errorf("invalid secret key: %q", key)
+	}
+	return nil
+}
+
 func (t *TinkManager) loadSecretData(key string) (secretData, error) {
 	var data secretData
 
@@ -625,3 +625,76 @@ func TestKeyMetadata(t *testing.T) {
 		assert.Equal(t, meta1.NextRotation.Unix(), meta2.NextRotation.Unix())
 	})
 }
+
+func TestKeysetPersistence(t *testing.T) {
+	ctx := context.Background()
+
+	open := func(t *testing.T, config Config) (SecretManager, error) {
+		t.Helper()
+		mgr, err := NewTinkManager(config)
+		require.NoError(t, err)
+		return mgr, mgr.Initialize(ctx, config)
+	}
+
+	t.Run("Secrets Survive Restarts", func(t *testing.T) {
+		config := Config{StoragePath: filepath.Join(t.TempDir(), "secrets"), MasterPassword: "hunter2"}
+
+		mgr1, err := open(t, config)
+		require.NoError(t, err)
+		require.NoError(t, mgr1.SetSecret(ctx, "api-token", "s3cret"))
+		require.NoError(t, mgr1.Close())
+
+		info, err := os.Stat(filepath.Join(config.StoragePath, keysetFile))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		mgr2, err := open(t, config)
+		require.NoError(t, err)
+		value, err := mgr2.GetSecret(ctx, "api-token")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", value)
+
+		// Rotated keysets are stored too
+		require.NoError(t, mgr2.(KeyRotator).RotateKeys(ctx))
+		require.NoError(t, mgr2.Close())
+
+		mgr3, err := open(t, config)
+		require.NoError(t, err)
+		value, err = mgr3.GetSecret(ctx, "api-token")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", value)
+	})
+
+	t.Run("Wrong Master Password", func(t *testing.T) {
+		config := Config{StoragePath: filepath.Join(t.TempDir(), "secrets"), MasterPassword: "hunter2"}
+		mgr, err := open(t, config)
+		require.NoError(t, err)
+		require.NoError(t, mgr.Close())
+
+		config.MasterPassword = "hunter3"
+		_, err = open(t, config)
+		assert.Error(t, err)
+	})
+
+	t.Run("Without Master Password", func(t *testing.T) {
+		config := Config{StoragePath: filepath.Join(t.TempDir(), "secrets")}
+		mgr, err := open(t, config)
+		require.NoError(t, err)
+		require.NoError(t, mgr.SetSecret(ctx, "api-token", "s3cret"))
+		require.NoError(t, mgr.Close())
+
+		_, err = os.Stat(filepath.Join(config.StoragePath, keysetFile))
+		assert.True(t, os.IsNotExist(err), "keyset should only be kept in memory")
+	})
+
+	t.Run("Invalid Keys", func(t *testing.T) {
+		mgr, err := open(t, Config{StoragePath: filepath.Join(t.TempDir(), "secrets")})
+		require.NoError(t, err)
+		for _, key := range []string{"", ".", "..", "../outside", "nested/key"} {
+			assert.Error(t, mgr.SetSecret(ctx, key, "value"), key)
+			_, err := mgr.GetSecret(ctx, key)
+			assert.Error(t, err, key)
+			assert.Error(t, mgr.DeleteSecret(ctx, key), key)
+		}
+	})
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package limits
+
+import "os"
+
+// Classify reports which limit, if any, stopped a finished process. Rlimits
+// are only enforced on Unix, so no process is ever classified elsewhere.
+func Classify(l Limits, state *os.ProcessState, stderr string) string {
+	return ""
+}
@@ -0,0 +1,78 @@
+//go:build unix
+
+package limits
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Messages printed by common runtimes and shells when a limit is hit
+var (
+	memoryMarkers   = []string{"cannot allocate memory", "out of memory", "memoryerror", "bad_alloc"}
+	openFileMarkers = []string{"too many open files"}
+	processMarkers  = []string{"resource temporarily unavailable", "cannot fork", "fork: retry"}
+	fileSizeMarkers = []string{"file too large", "file size limit exceeded"}
+)
+
+// Classify reports which limit, if any, stopped a finished process. Rlimit
+// violations are recognized by the signal the kernel sends (directly or,
+// for shells, as exit status 128+signal) and by the error messages common
+// runtimes print when an allocation, open or fork is refused.
+func Classify(l Limits, state *os.ProcessState, stderr string) string {
+	if state == nil || state.Success() {
+		return ""
+	}
+
+	if sig, ok := signal(state); ok {
+		switch {
+		case sig == syscall.SIGXCPU && l.CPUSeconds > 0:
+			return ReasonCPU
+		case sig == syscall.SIGXFSZ && l.FileSizeBytes > 0:
+			return ReasonFileSize
+		case sig == syscall.SIGKILL && l.CPUSeconds > 0 &&
+			state.UserTime()+state.SystemTime() >= time.Duration(l.CPUSeconds)*time.Second:
+			// The hard CPU limit is enforced with SIGKILL
+			return ReasonCPU
+		case l.MemoryBytes > 0 && (sig == syscall.SIGKILL || sig == syscall.SIGSEGV ||
+			sig == syscall.SIGABRT || sig == syscall.SIGBUS):
+			return ReasonOOM
+		}
+	}
+
+	stderr = strings.ToLower(stderr)
+	switch {
+	case l.MemoryBytes > 0 && containsAny(stderr, memoryMarkers):
+		return ReasonOOM
+	case l.OpenFiles > 0 && containsAny(stderr, openFileMarkers):
+		return ReasonOpenFiles
+	case l.Processes > 0 && containsAny(stderr, processMarkers):
+		return ReasonProcesses
+	case l.FileSizeBytes > 0 && containsAny(stderr, fileSizeMarkers):
+		return ReasonFileSize
+	}
+	return ""
+}
+
+// signal returns the signal that terminated the process or, following the
+// shell convention, the signal encoded in an exit status above 128
+func signal(state *os.ProcessState) (syscall.Signal, bool) {
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return ws.Signal(), true
+	}
+	if code := state.ExitCode(); code > 128 && code < 128+65 {
+		return syscall.Signal(code - 128), true
+	}
+	return 0, false
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+/*
+Package limits defines the resource limits of job runs.
+
+A job may request limits; the server fills unset values from its defaults
+and clamps every value to its configured maximum. CPU time, address space,
+open files, process count and file size are enforced with setrlimit on the
+job's processes, the wall-clock timeout and output size by the executor.
+
+A run that violates a limit fails with one of the Reason values instead of
+a generic failure.
+*/
+package limits
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Failure reasons recorded on runs that exceeded a limit
+const (
+	ReasonTimeout   = "timeout"
+	ReasonOOM       = "oom"
+	ReasonCPU       = "cpu_limit"
+	ReasonOutput    = "output_limit"
+	ReasonFileSize  = "file_size_limit"
+	ReasonOpenFiles = "open_files_limit"
+	ReasonProcesses = "process_limit"
+)
+
+var ErrInvalidLimits = errors.New("invalid limits")
+
+// Limits are the resource limits of a run. Zero values mean unlimited.
+type Limits struct {
+	// CPUSeconds caps CPU time per process (RLIMIT_CPU)
+	CPUSeconds int64 `json:"cpu_seconds,omitempty"`
+	// MemoryBytes caps the address space per process (RLIMIT_AS)
+	MemoryBytes int64 `json:"memory_bytes,omitempty"`
+	// OpenFiles caps the number of file descriptors (RLIMIT_NOFILE)
+	OpenFiles int64 `json:"open_files,omitempty"`
+	// Processes caps the number of processes of the user (RLIMIT_NPROC)
+	Processes int64 `json:"processes,omitempty"`
+	// FileSizeBytes caps the size of files written (RLIMIT_FSIZE)
+	FileSizeBytes int64 `json:"file_size_bytes,omitempty"`
+	// OutputBytes caps the captured stdout and stderr of each process
+	OutputBytes int64 `json:"output_bytes,omitempty"`
+	// Timeout caps the wall-clock duration of the run
+	Timeout Duration `json:"timeout,omitempty"`
+}
+
+// Duration is a time.Duration encoded as a Go duration string ("90s")
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON accepts a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\"")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// IsZero reports whether no limit is set
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// HasRlimits reports whether any limit enforced with setrlimit is set
+func (l Limits) HasRlimits() bool {
+	return l.CPUSeconds > 0 || l.MemoryBytes > 0 || l.OpenFiles > 0 || l.Processes > 0 || l.FileSizeBytes > 0
+}
+
+// fields lists the limits by JSON name, for validation and merging
+func (l *Limits) fields() []struct {
+	name  string
+	value *int64
+} {
+	return []struct {
+		name  string
+		value *int64
+	}{
+		{"cpu_seconds", &l.CPUSeconds},
+		{"memory_bytes", &l.MemoryBytes},
+		{"open_files", &l.OpenFiles},
+		{"processes", &l.Processes},
+		{"file_size_bytes", &l.FileSizeBytes},
+		{"output_bytes", &l.OutputBytes},
+		{"timeout", (*int64)(&l.Timeout)},
+	}
+}
+
+// Validate checks that no limit is negative
+func (l Limits) Validate() error {
+	for _, f := range l.fields() {
+		if *f.value < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidLimits, f.name)
+		}
+	}
+	return nil
+}
+
+// Effective fills unset limits from defaults and clamps every limit to max
+func (l Limits) Effective(defaults, max Limits) Limits {
+	result := l
+	resultFields, defaultFields, maxFields := result.fields(), defaults.fields(), max.fields()
+	for i := range resultFields {
+		v := resultFields[i].value
+		if *v == 0 {
+			*v = *defaultFields[i].value
+		}
+		if m := *maxFields[i].value; m > 0 && (*v == 0 || *v > m) {
+			*v = m
+		}
+	}
+	return result
+}
+
+// Error reports a run that failed because it exceeded a limit
+type Error struct {
+	Reason string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s exceeded: %v", e.Reason, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ReasonOf returns the failure reason of err, or "" if err is not caused by
+// a limit
+func ReasonOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package limits
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffective(t *testing.T) {
+	defaults := Limits{CPUSeconds: 60, Timeout: Duration(time.Hour)}
+	max := Limits{CPUSeconds: 120, MemoryBytes: 1 << 30, Timeout: Duration(2 * time.Hour)}
+
+	tests := []struct {
+		name string
+		job  Limits
+		want Limits
+	}{
+		{
+			name: "defaults fill unset values",
+			job:  Limits{OpenFiles: 256},
+			want: Limits{CPUSeconds: 60, MemoryBytes: 1 << 30, OpenFiles: 256, Timeout: Duration(time.Hour)},
+		},
+		{
+			name: "values above max are clamped",
+			job:  Limits{CPUSeconds: 600, MemoryBytes: 4 << 30, Timeout: Duration(24 * time.Hour)},
+			want: Limits{CPUSeconds: 120, MemoryBytes: 1 << 30, Timeout: Duration(2 * time.Hour)},
+		},
+		{
+			name: "values below max are kept",
+			job:  Limits{CPUSeconds: 10, MemoryBytes: 1 << 20},
+			want: Limits{CPUSeconds: 10, MemoryBytes: 1 << 20, Timeout: Duration(time.Hour)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.job.Effective(defaults, max))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Limits{CPUSeconds: 1}.Validate())
+	assert.ErrorIs(t, Limits{OpenFiles: -1}.Validate(), ErrInvalidLimits)
+	assert.ErrorContains(t, Limits{Timeout: Duration(-time.Second)}.Validate(), "timeout")
+}
+
+func TestJSON(t *testing.T) {
+	var l Limits
+	require.NoError(t, json.Unmarshal([]byte(`{"cpu_seconds": 5, "timeout": "90s"}`), &l))
+	assert.Equal(t, Limits{CPUSeconds: 5, Timeout: Duration(90 * time.Second)}, l)
+
+	data, err := json.Marshal(l)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cpu_seconds": 5, "timeout": "1m30s"}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"timeout": 90}`), &l))
+	assert.Error(t, json.Unmarshal([]byte(`{"timeout": "soon"}`), &l))
+}
+
+func TestReasonOf(t *testing.T) {
+	base := errors.New("exit status 1")
+	err := fmt.Errorf("run failed: %w", &Error{Reason: ReasonOOM, Err: base})
+	assert.Equal(t, ReasonOOM, ReasonOf(err))
+	assert.ErrorIs(t, err, base)
+	assert.Empty(t, ReasonOf(base))
+	assert.Empty(t, ReasonOf(nil))
+}
@@ -0,0 +1,221 @@
+/*
+Package matrix expands a job into one run per combination of parameter
+values.
+
+A matrix declares axes, each with a list of values. The runs of the job are
+the cartesian product of the axes, minus the combinations matched by an
+exclude rule, plus the extra combinations listed in include:
+
+	{
+		"axes": {"os": ["linux", "darwin"], "go": ["1.24", "1.25"]},
+		"exclude": [{"os": "darwin", "go": "1.24"}],
+		"include": [{"os": "windows", "go": "1.25"}],
+		"max_parallel": 2,
+		"fail_fast": true
+	}
+
+expands to linux/1.24, linux/1.25, darwin/1.25 and windows/1.25. An exclude
+rule matches every combination having all of its values, so {"os": "darwin"}
+excludes both darwin combinations. Combinations are ordered by axis name and
+then by the order of the values.
+
+MaxParallel bounds how many runs of the matrix execute at once; FailFast
+cancels the remaining runs as soon as one of them fails.
+*/
+package matrix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidMatrix = errors.New("invalid matrix")
+
+// MaxCombinations caps the number of runs a matrix expands to
+const MaxCombinations = 256
+
+// EnvPrefix prefixes the environment variable of every matrix value
+const EnvPrefix = "MATRIX_"
+
+// axisName matches valid axis names
+var axisName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Matrix declares the runs a job fans out to
+type Matrix struct {
+	Axes    map[string][]interface{} `json:"axes,omitempty"`
+	Include []map[string]interface{} `json:"include,omitempty"`
+	Exclude []map[string]interface{} `json:"exclude,omitempty"`
+	// MaxParallel bounds the runs executing at once; 0 runs them all
+	MaxParallel int `json:"max_parallel,omitempty"`
+	// FailFast cancels the remaining runs when one fails
+	FailFast bool `json:"fail_fast,omitempty"`
+}
+
+// Parse decodes and validates a stored matrix
+func Parse(data []byte) (*Matrix, error) {
+	var m Matrix
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMatrix, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks the matrix, including that it expands to at least one
+// and at most MaxCombinations runs
+func (m *Matrix) Validate() error {
+	if m.MaxParallel < 0 {
+		return fmt.Errorf("%w: max_parallel must not be negative", ErrInvalidMatrix)
+	}
+	for name, values := range m.Axes {
+		if !axisName.MatchString(name) {
+			return fmt.Errorf("%w: invalid axis name %q", ErrInvalidMatrix, name)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("%w: axis %q has no values", ErrInvalidMatrix, name)
+		}
+		for _, v := range values {
+			if !isScalar(v) {
+				return fmt.Errorf("%w: values of axis %q must be strings, numbers or booleans", ErrInvalidMatrix, name)
+			}
+		}
+	}
+	for _, rule := range m.Exclude {
+		if len(rule) == 0 {
+			return fmt.Errorf("%w: exclude rules must not be empty", ErrInvalidMatrix)
+		}
+		for name := range rule {
+			if _, ok := m.Axes[name]; !ok {
+				return fmt.Errorf("%w: exclude rule names unknown axis %q", ErrInvalidMatrix, name)
+			}
+		}
+	}
+	for _, combination := range m.Include {
+		if len(combination) == 0 {
+			return fmt.Errorf("%w: include entries must not be empty", ErrInvalidMatrix)
+		}
+		for name, v := range combination {
+			if !axisName.MatchString(name) {
+				return fmt.Errorf("%w: invalid axis name %q", ErrInvalidMatrix, name)
+			}
+			if !isScalar(v) {
+				return fmt.Errorf("%w: include value %q must be a string, number or boolean", ErrInvalidMatrix, name)
+			}
+		}
+	}
+
+	n, err := m.size()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: matrix expands to no runs", ErrInvalidMatrix)
+	}
+	return nil
+}
+
+// size counts the combinations, failing before expanding matrices whose
+// axes alone exceed MaxCombinations
+func (m *Matrix) size() (int, error) {
+	if len(m.Axes) == 0 {
+		return len(m.Include), nil
+	}
+	n := 1
+	for _, values := range m.Axes {
+		n *= len(values)
+		if n > MaxCombinations {
+			return 0, fmt.Errorf("%w: matrix expands to more than %d runs", ErrInvalidMatrix, MaxCombinations)
+		}
+	}
+	combinations, err := m.Expand()
+	if err != nil {
+		return 0, err
+	}
+	return len(combinations), nil
+}
+
+// Expand returns the combinations of the matrix, one per run
+func (m *Matrix) Expand() ([]map[string]interface{}, error) {
+	names := slices.Sorted(maps.Keys(m.Axes))
+	var combinations []map[string]interface{}
+	if len(names) > 0 {
+		combinations = []map[string]interface{}{{}}
+		for _, name := range names {
+			next := make([]map[string]interface{}, 0, len(combinations)*len(m.Axes[name]))
+			for _, c := range combinations {
+				for _, v := range m.Axes[name] {
+					combination := maps.Clone(c)
+					combination[name] = v
+					next = append(next, combination)
+				}
+			}
+			combinations = next
+		}
+	}
+
+	combinations = slices.DeleteFunc(combinations, func(c map[string]interface{}) bool {
+		return slices.ContainsFunc(m.Exclude, func(rule map[string]interface{}) bool {
+			return matches(c, rule)
+		})
+	})
+	for _, extra := range m.Include {
+		if !slices.ContainsFunc(combinations, func(c map[string]interface{}) bool { return equal(c, extra) }) {
+			combinations = append(combinations, extra)
+		}
+	}
+	if len(combinations) > MaxCombinations {
+		return nil, fmt.Errorf("%w: matrix expands to more than %d runs", ErrInvalidMatrix, MaxCombinations)
+	}
+	return combinations, nil
+}
+
+// Env returns the environment variables exposing a combination to a run:
+// MATRIX_<NAME> for every value, the name upper-cased
+func Env(combination map[string]interface{}) map[string]string {
+	env := make(map[string]string, len(combination))
+	for name, v := range combination {
+		env[EnvPrefix+strings.ToUpper(name)] = Format(v)
+	}
+	return env
+}
+
+// Format renders a matrix value as text
+func Format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// matches reports whether a combination has all values of a rule
+func matches(combination, rule map[string]interface{}) bool {
+	for name, v := range rule {
+		if Format(combination[name]) != Format(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(a, b map[string]interface{}) bool {
+	return len(a) == len(b) && matches(a, b)
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, float64, json.Number, int, int64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package matrix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []map[string]interface{}
+	}{
+		{
+			name: "cartesian product ordered by axis name",
+			data: `{"axes": {"os": ["linux", "darwin"], "go": ["1.24", "1.25"]}}`,
+			want: []map[string]interface{}{
+				{"go": "1.24", "os": "linux"},
+				{"go": "1.24", "os": "darwin"},
+				{"go": "1.25", "os": "linux"},
+				{"go": "1.25", "os": "darwin"},
+			},
+		},
+		{
+			name: "exclude and include",
+			data: `{
+				"axes": {"os": ["linux", "darwin"], "go": ["1.24", "1.25"]},
+				"exclude": [{"os": "darwin", "go": "1.24"}],
+				"include": [{"os": "windows", "go": "1.25"}, {"os": "linux", "go": "1.25"}]
+			}`,
+			want: []map[string]interface{}{
+				{"go": "1.24", "os": "linux"},
+				{"go": "1.25", "os": "linux"},
+				{"go": "1.25", "os": "darwin"},
+				{"go": "1.25", "os": "windows"},
+			},
+		},
+		{
+			name: "partial exclude rule",
+			data: `{"axes": {"os": ["linux", "darwin"], "shard": [1, 2]}, "exclude": [{"os": "darwin"}]}`,
+			want: []map[string]interface{}{
+				{"os": "linux", "shard": float64(1)},
+				{"os": "linux", "shard": float64(2)},
+			},
+		},
+		{
+			name: "include only",
+			data: `{"include": [{"target": "arm64"}]}`,
+			want: []map[string]interface{}{{"target": "arm64"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(tt.data))
+			require.NoError(t, err)
+			got, err := m.Expand()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: `{}`},
+		{name: "everything excluded", data: `{"axes": {"os": ["linux"]}, "exclude": [{"os": "linux"}]}`},
+		{name: "empty axis", data: `{"axes": {"os": []}}`},
+		{name: "invalid axis name", data: `{"axes": {"target-os": ["linux"]}}`},
+		{name: "non-scalar value", data: `{"axes": {"os": [["linux"]]}}`},
+		{name: "exclude names unknown axis", data: `{"axes": {"os": ["linux"]}, "exclude": [{"arch": "arm64"}]}`},
+		{name: "empty exclude rule", data: `{"axes": {"os": ["linux"]}, "exclude": [{}]}`},
+		{name: "negative max parallel", data: `{"axes": {"os": ["linux"]}, "max_parallel": -1}`},
+		{name: "too many runs", data: `{"axes": {
+			"a": [1, 2, 3, 4, 5, 6, 7, 8], "b": [1, 2, 3, 4, 5, 6, 7, 8], "c": [1, 2, 3, 4, 5]
+		}}`},
+		{name: "not JSON", data: `{"axes":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			assert.ErrorIs(t, err, ErrInvalidMatrix)
+		})
+	}
+}
+
+func TestEnv(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"MATRIX_OS":    "linux",
+		"MATRIX_SHARD": "2",
+		"MATRIX_RACE":  "true",
+	}, Env(map[string]interface{}{"os": "linux", "shard": float64(2), "race": true}))
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConfigError reports an invalid value in a plugin configuration
+type ConfigError struct {
+	// Field is the path of the offending key, e.g. "headers.Authorization"
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidConfig)
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// NewConfigError creates a ConfigError for the given field
+func NewConfigError(field, format string, args ...interface{}) *ConfigError {
+	return &ConfigError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// String reads an optional string value from a config map
+func String(config map[string]interface{}, key string) (string, error) {
+	v, ok := config[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", NewConfigError(key, "must be a string")
+	}
+	return s, nil
+}
+
+// RequiredString reads a string value that must be present and non-empty
+func RequiredString(config map[string]interface{}, key string) (string, error) {
+	s, err := String(config, key)
+	if err != nil {
+		return "", err
+	}
+	if s == "" {
+		return "", NewConfigError(key, "is required")
+	}
+	return s, nil
+}
+
+// StringSlice reads an optional list of strings from a config map
+func StringSlice(config map[string]interface{}, key string) ([]string, error) {
+	v, ok := config[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	switch list := v.(type) {
+	case []string:
+		return list, nil
+	case []interface{}:
+		out := make([]string, len(list))
+		for i, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, NewConfigError(fmt.Sprintf("%s[%d]", key, i), "must be a string")
+			}
+			out[i] = s
+		}
+		return out, nil
+	default:
+		return nil, NewConfigError(key, "must be a list of strings")
+	}
+}
+
+// StringMap reads an optional string-to-string map from a config map
+func StringMap(config map[string]interface{}, key string) (map[string]string, error) {
+	v, ok := config[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	switch m := v.(type) {
+	case map[string]string:
+		return m, nil
+	case map[string]interface{}:
+		out := make(map[string]string, len(m))
+		for k, item := range m {
+			s, ok := item.(string)
+			if !ok {
+				return nil, NewConfigError(key+"."+k, "must be a string")
+			}
+			out[k] = s
+		}
+		return out, nil
+	default:
+		return nil, NewConfigError(key, "must be an object of strings")
+	}
+}
+
+// Int reads an optional integer value from a config map. JSON numbers
+// decode as float64, so whole floats are accepted as well.
+func Int(config map[string]interface{}, key string) (int64, bool, error) {
+	v, ok := config[key]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return int64(n), true, nil
+	case int64:
+		return n, true, nil
+	case float64:
+		if n != float64(int64(n)) {
+			return 0, false, NewConfigError(key, "must be a whole number")
+		}
+		return int64(n), true, nil
+	default:
+		return 0, false, NewConfigError(key, "must be a number")
+	}
+}
+
+// Bool reads an optional boolean value from a config map
+func Bool(config map[string]interface{}, key string) (bool, error) {
+	v, ok := config[key]
+	if !ok || v == nil {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, NewConfigError(key, "must be a boolean")
+	}
+	return b, nil
+}
+
+// Duration reads an optional duration written as a Go duration string
+// ("30s", "5m") from a config map
+func Duration(config map[string]interface{}, key string) (time.Duration, error) {
+	s, err := String(config, key)
+	if err != nil || s == "" {
+		return 0, err
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, NewConfigError(key, "must be a duration such as \"30s\"")
+	}
+	if d < 0 {
+		return 0, NewConfigError(key, "must not be negative")
+	}
+	return d, nil
+}
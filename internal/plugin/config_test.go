@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHelpers(t *testing.T) {
+	config := map[string]interface{}{
+		"name":     "job",
+		"empty":    "",
+		"number":   float64(3),
+		"fraction": 1.5,
+		"flag":     true,
+		"list":     []interface{}{"a", "b"},
+		"badlist":  []interface{}{"a", 1},
+		"map":      map[string]interface{}{"K": "v"},
+		"badmap":   map[string]interface{}{"K": 1},
+		"timeout":  "30s",
+		"badtime":  "soon",
+	}
+
+	t.Run("strings", func(t *testing.T) {
+		s, err := String(config, "name")
+		require.NoError(t, err)
+		assert.Equal(t, "job", s)
+
+		s, err = String(config, "missing")
+		require.NoError(t, err)
+		assert.Empty(t, s)
+
+		_, err = String(config, "number")
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+
+		_, err = RequiredString(config, "empty")
+		var cfgErr *ConfigError
+		require.ErrorAs(t, err, &cfgErr)
+		assert.Equal(t, "empty", cfgErr.Field)
+	})
+
+	t.Run("lists and maps", func(t *testing.T) {
+		list, err := StringSlice(config, "list")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, list)
+
+		_, err = StringSlice(config, "badlist")
+		var cfgErr *ConfigError
+		require.ErrorAs(t, err, &cfgErr)
+		assert.Equal(t, "badlist[1]", cfgErr.Field)
+
+		m, err := StringMap(config, "map")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"K": "v"}, m)
+
+		_, err = StringMap(config, "badmap")
+		require.ErrorAs(t, err, &cfgErr)
+		assert.Equal(t, "badmap.K", cfgErr.Field)
+	})
+
+	t.Run("numbers, bools and durations", func(t *testing.T) {
+		n, ok, err := Int(config, "number")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(3), n)
+
+		_, _, err = Int(config, "fraction")
+		assert.Error(t, err)
+
+		b, err := Bool(config, "flag")
+		require.NoError(t, err)
+		assert.True(t, b)
+
+		d, err := Duration(config, "timeout")
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, d)
+
+		_, err = Duration(config, "badtime")
+		assert.Error(t, err)
+	})
+}
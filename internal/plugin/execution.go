@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"context"
+	"io"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/terminal"
+)
+
+// Execution carries per-run settings from the executor to plugins
+type Execution struct {
+	// WorkDir is the run's workspace directory. Plugins use it as the
+	// default working directory and as the place to write artifacts.
+	WorkDir string
+	// Env holds variables added to every process started for the run
+	Env map[string]string
+	// Sandbox, when set, isolates every process started for the run
+	Sandbox *sandbox.Policy
+	// Limits are applied to every process started for the run
+	Limits limits.Limits
+	// Stdout and Stderr, when set, receive process output as it is
+	// produced, e.g. to stream logs while the run is in progress. They
+	// may be written to concurrently.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Stdin, when set, is the path of a file streamed into the standard
+	// input of every process started for the run
+	Stdin string
+	// Terminal, when set, runs every process started for the run under a
+	// pseudo-terminal users can attach to. Output and errors are then
+	// merged into the result's Output.
+	Terminal *terminal.Terminal
+}
+
+type executionKey struct{}
+
+// WithExecution returns a context carrying the given execution settings
+func WithExecution(ctx context.Context, e *Execution) context.Context {
+	return context.WithValue(ctx, executionKey{}, e)
+}
+
+// ExecutionFrom returns the execution settings stored in ctx, or nil when
+// the plugin runs outside of the executor
+func ExecutionFrom(ctx context.Context) *Execution {
+	e, _ := ctx.Value(executionKey{}).(*Execution)
+	return e
+}
+
+// WorkDir returns dir when set and the execution's workspace otherwise
+func WorkDir(ctx context.Context, dir string) string {
+	if dir != "" {
+		return dir
+	}
+	if e := ExecutionFrom(ctx); e != nil {
+		return e.WorkDir
+	}
+	return ""
+}
@@ -0,0 +1,405 @@
+// Package httpreq provides a plugin that performs an HTTP request and
+// asserts on the response, for health checks and scheduled API calls
+package httpreq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+// Name is the registry name of the HTTP plugin
+const Name = "http"
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultMaxBodyBytes = 64 * 1024
+)
+
+// ErrAssertionFailed is returned when the response does not satisfy the
+// configured assertions
+var ErrAssertionFailed = errors.New("http assertion failed")
+
+// Plugin performs a single HTTP request.
+//
+// Example config:
+//
+//	{
+//		"method": "POST",
+//		"url": "http://billing.internal/api/reconcile",
+//		"headers": {"Authorization": "Bearer ${secret:billing-token}"},
+//		"body": "{\"dry_run\": false}",
+//		"timeout": "10s",
+//		"assert": {
+//			"status": [200, 202],
+//			"max_latency": "2s",
+//			"json": [{"path": "$.result.state", "equals": "ok"}]
+//		}
+//	}
+//
+// Header values may reference secrets with ${secret:NAME}; they are
+// resolved through the SecretResolver given to New.
+type Plugin struct {
+	secrets plugin.SecretResolver
+	client  *http.Client
+}
+
+// New creates an HTTP plugin. secrets may be nil if no configuration
+// references secrets.
+func New(secrets plugin.SecretResolver) *Plugin {
+	return &Plugin{secrets: secrets, client: &http.Client{}}
+}
+
+// Name returns the unique identifier for this plugin
+func (p *Plugin) Name() string { return Name }
+
+// Description provides details about what the plugin does
+func (p *Plugin) Description() string {
+	return "Performs an HTTP request and asserts on the response"
+}
+
+// Version returns the plugin version
+func (p *Plugin) Version() string { return "1.0.0" }
+
+// Capabilities returns what features this plugin supports
+func (p *Plugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{
+		SupportsCancel:     true,
+		SupportsConcurrent: true,
+	}
+}
+
+// jsonAssertion checks the value found at a JSONPath in the response body
+type jsonAssertion struct {
+	path     string
+	segments []pathSegment
+	equals   interface{}
+	// exists only checks that the path resolves when no value is given
+	exists bool
+}
+
+// config is the parsed form of the plugin configuration
+type config struct {
+	method       string
+	url          string
+	headers      map[string]string
+	body         string
+	timeout      time.Duration
+	maxBodyBytes int64
+
+	statuses   []int
+	maxLatency time.Duration
+	jsonChecks []jsonAssertion
+}
+
+func parseConfig(raw map[string]interface{}) (*config, error) {
+	var (
+		c   config
+		err error
+	)
+	if c.method, err = plugin.String(raw, "method"); err != nil {
+		return nil, err
+	}
+	if c.method == "" {
+		c.method = http.MethodGet
+	}
+	c.method = strings.ToUpper(c.method)
+	if c.url, err = plugin.RequiredString(raw, "url"); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(c.url, "http://") && !strings.HasPrefix(c.url, "https://") {
+		return nil, plugin.NewConfigError("url", "must be an http:// or https:// URL")
+	}
+	if c.headers, err = plugin.StringMap(raw, "headers"); err != nil {
+		return nil, err
+	}
+	if c.body, err = plugin.String(raw, "body"); err != nil {
+		return nil, err
+	}
+	if c.timeout, err = plugin.Duration(raw, "timeout"); err != nil {
+		return nil, err
+	}
+	if c.timeout == 0 {
+		c.timeout = defaultTimeout
+	}
+	maxBody, ok, err := plugin.Int(raw, "max_body_bytes")
+	if err != nil {
+		return nil, err
+	}
+	c.maxBodyBytes = defaultMaxBodyBytes
+	if ok {
+		if maxBody < 0 {
+			return nil, plugin.NewConfigError("max_body_bytes", "must not be negative")
+		}
+		c.maxBodyBytes = maxBody
+	}
+
+	if err := c.parseAssertions(raw["assert"]); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *config) parseAssertions(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return plugin.NewConfigError("assert", "must be an object")
+	}
+
+	switch status := raw["status"].(type) {
+	case nil:
+	case []interface{}:
+		for i, item := range status {
+			n, ok := wholeNumber(item)
+			if !ok {
+				return plugin.NewConfigError(fmt.Sprintf("assert.status[%d]", i), "must be a whole number")
+			}
+			c.statuses = append(c.statuses, n)
+		}
+	default:
+		n, ok := wholeNumber(status)
+		if !ok {
+			return plugin.NewConfigError("assert.status", "must be a number or a list of numbers")
+		}
+		c.statuses = []int{n}
+	}
+
+	latency, err := plugin.Duration(raw, "max_latency")
+	if err != nil {
+		return plugin.NewConfigError("assert.max_latency", "must be a duration such as \"500ms\"")
+	}
+	c.maxLatency = latency
+
+	checks, ok := raw["json"]
+	if !ok || checks == nil {
+		return nil
+	}
+	list, ok := checks.([]interface{})
+	if !ok {
+		return plugin.NewConfigError("assert.json", "must be a list")
+	}
+	for i, item := range list {
+		field := fmt.Sprintf("assert.json[%d]", i)
+		check, ok := item.(map[string]interface{})
+		if !ok {
+			return plugin.NewConfigError(field, "must be an object")
+		}
+		path, err := plugin.RequiredString(check, "path")
+		if err != nil {
+			return plugin.NewConfigError(field+".path", "is required")
+		}
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return plugin.NewConfigError(field+".path", "%s", err.Error())
+		}
+		equals, hasEquals := check["equals"]
+		c.jsonChecks = append(c.jsonChecks, jsonAssertion{
+			path:     path,
+			segments: segments,
+			equals:   equals,
+			exists:   !hasEquals,
+		})
+	}
+	return nil
+}
+
+// Validate checks if the job configuration is valid for this plugin
+func (p *Plugin) Validate(raw map[string]interface{}) error {
+	_, err := parseConfig(raw)
+	return err
+}
+
+// Execute performs the request and evaluates the assertions
+func (p *Plugin) Execute(ctx context.Context, raw map[string]interface{}) (plugin.JobResult, error) {
+	c, err := parseConfig(raw)
+	if err != nil {
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var body io.Reader
+	if c.body != "" {
+		body = strings.NewReader(c.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, c.method, c.url, body)
+	if err != nil {
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+	for name, value := range c.headers {
+		resolved, err := plugin.ResolveSecrets(ctx, p.secrets, value)
+		if err != nil {
+			err = fmt.Errorf("header %s: %w", name, err)
+			return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+		}
+		req.Header.Set(name, resolved)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("request failed: %w", err)
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+	defer resp.Body.Close()
+
+	// Read one byte past the limit so truncation can be detected
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBodyBytes+1))
+	latency := time.Since(start)
+	if err != nil {
+		err = fmt.Errorf("failed to read response body: %w", err)
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+	truncated := int64(len(respBody)) > c.maxBodyBytes
+	if truncated {
+		respBody = respBody[:c.maxBodyBytes]
+	}
+
+	result := plugin.JobResult{
+		Output: string(respBody),
+		Metadata: map[string]interface{}{
+			"status_code":    resp.StatusCode,
+			"headers":        flattenHeaders(resp.Header),
+			"latency_ms":     latency.Milliseconds(),
+			"body_truncated": truncated,
+		},
+	}
+
+	failures := c.check(resp.StatusCode, latency, respBody, truncated)
+	if len(failures) > 0 {
+		result.ExitCode = 1
+		result.Error = strings.Join(failures, "\n")
+		return result, fmt.Errorf("%w: %s", ErrAssertionFailed, strings.Join(failures, "; "))
+	}
+	return result, nil
+}
+
+// Plan describes the request Execute would send. Secrets referenced by
+// header values must resolve; their values are masked.
+func (p *Plugin) Plan(ctx context.Context, raw map[string]interface{}) (plugin.Plan, error) {
+	c, err := parseConfig(raw)
+	if err != nil {
+		return plugin.Plan{}, err
+	}
+	details := map[string]interface{}{
+		"method":  c.method,
+		"url":     c.url,
+		"timeout": c.timeout.String(),
+	}
+	if len(c.headers) > 0 {
+		headers := make(map[string]string, len(c.headers))
+		for _, name := range slices.Sorted(maps.Keys(c.headers)) {
+			masked, err := plugin.MaskSecrets(ctx, p.secrets, c.headers[name])
+			if err != nil {
+				return plugin.Plan{}, plugin.NewConfigError("headers."+name, "%s", err.Error())
+			}
+			headers[name] = masked
+		}
+		details["headers"] = headers
+	}
+	if c.body != "" {
+		details["body"] = c.body
+	}
+	return plugin.Plan{Details: details}, nil
+}
+
+// check evaluates every assertion and returns a message per failure
+func (c *config) check(status int, latency time.Duration, body []byte, truncated bool) []string {
+	var failures []string
+
+	if len(c.statuses) > 0 {
+		matched := false
+		for _, want := range c.statuses {
+			if status == want {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			failures = append(failures, fmt.Sprintf("status: got %d, want one of %v", status, c.statuses))
+		}
+	} else if status >= 400 {
+		failures = append(failures, fmt.Sprintf("status: got %d", status))
+	}
+
+	if c.maxLatency > 0 && latency > c.maxLatency {
+		failures = append(failures, fmt.Sprintf("latency: %s exceeds %s", latency.Round(time.Millisecond), c.maxLatency))
+	}
+
+	if len(c.jsonChecks) == 0 {
+		return failures
+	}
+	if truncated {
+		return append(failures, "json: response body exceeds max_body_bytes and cannot be parsed")
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return append(failures, fmt.Sprintf("json: response is not valid JSON: %v", err))
+	}
+	for _, check := range c.jsonChecks {
+		got, ok := lookupJSONPath(doc, check.segments)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("json %s: path not found", check.path))
+			continue
+		}
+		if check.exists {
+			continue
+		}
+		if !jsonEqual(got, check.equals) {
+			failures = append(failures, fmt.Sprintf("json %s: got %s, want %s", check.path, toJSON(got), toJSON(check.equals)))
+		}
+	}
+	return failures
+}
+
+// jsonEqual compares two decoded JSON values by their canonical encoding,
+// so that 1 and 1.0 or differently ordered maps compare equal
+func jsonEqual(a, b interface{}) bool {
+	return bytes.Equal([]byte(toJSON(a)), []byte(toJSON(b)))
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// flattenHeaders converts response headers into a JSON-friendly map
+func flattenHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// wholeNumber converts a decoded JSON number to an int
+func wholeNumber(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		if n != float64(int(n)) {
+			return 0, false
+		}
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
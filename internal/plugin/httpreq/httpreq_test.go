@@ -0,0 +1,230 @@
+package httpreq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSecrets is an in-memory plugin.SecretResolver
+type staticSecrets map[string]string
+
+func (s staticSecrets) GetSecret(_ context.Context, key string) (string, error) {
+	v, ok := s[key]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return v, nil
+}
+
+func TestValidate(t *testing.T) {
+	p := New(nil)
+
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		wantField string
+	}{
+		{
+			name:   "minimal",
+			config: map[string]interface{}{"url": "http://example.com"},
+		},
+		{
+			name:      "missing url",
+			config:    map[string]interface{}{},
+			wantField: "url",
+		},
+		{
+			name:      "bad scheme",
+			config:    map[string]interface{}{"url": "ftp://example.com"},
+			wantField: "url",
+		},
+		{
+			name:      "bad timeout",
+			config:    map[string]interface{}{"url": "http://x", "timeout": "soon"},
+			wantField: "timeout",
+		},
+		{
+			name: "bad status list",
+			config: map[string]interface{}{
+				"url":    "http://x",
+				"assert": map[string]interface{}{"status": []interface{}{200.0, "ok"}},
+			},
+			wantField: "assert.status[1]",
+		},
+		{
+			name: "bad json path",
+			config: map[string]interface{}{
+				"url": "http://x",
+				"assert": map[string]interface{}{
+					"json": []interface{}{map[string]interface{}{"path": "status"}},
+				},
+			},
+			wantField: "assert.json[0].path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Validate(tt.config)
+			if tt.wantField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var cfgErr *plugin.ConfigError
+			require.ErrorAs(t, err, &cfgErr)
+			assert.Equal(t, tt.wantField, cfgErr.Field)
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status": "ok", "checks": [{"name": "db", "latency": 3}]}`))
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+			w.Header().Set("X-Method", r.Method)
+			w.Write(body)
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		case "/big":
+			w.Write([]byte(strings.Repeat("x", 100)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	t.Run("assertions pass", func(t *testing.T) {
+		result, err := New(nil).Execute(ctx, map[string]interface{}{
+			"url": server.URL + "/health",
+			"assert": map[string]interface{}{
+				"status": 200.0,
+				"json": []interface{}{
+					map[string]interface{}{"path": "$.status", "equals": "ok"},
+					map[string]interface{}{"path": "$.checks[0].latency", "equals": 3.0},
+					map[string]interface{}{"path": "$.checks[0].name"},
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, 200, result.Metadata["status_code"])
+		assert.Equal(t, "application/json", result.Metadata["headers"].(map[string]string)["Content-Type"])
+		assert.Contains(t, result.Output, `"status": "ok"`)
+	})
+
+	t.Run("secret-backed header and body", func(t *testing.T) {
+		result, err := New(staticSecrets{"token": "abc123"}).Execute(ctx, map[string]interface{}{
+			"method":  "post",
+			"url":     server.URL + "/echo",
+			"headers": map[string]interface{}{"Authorization": "Bearer ${secret:token}"},
+			"body":    "payload",
+		})
+		require.NoError(t, err)
+		headers := result.Metadata["headers"].(map[string]string)
+		assert.Equal(t, "Bearer abc123", headers["X-Auth"])
+		assert.Equal(t, "POST", headers["X-Method"])
+		assert.Equal(t, "payload", result.Output)
+	})
+
+	t.Run("missing secret fails before the request", func(t *testing.T) {
+		_, err := New(staticSecrets{}).Execute(ctx, map[string]interface{}{
+			"url":     server.URL + "/echo",
+			"headers": map[string]interface{}{"Authorization": "Bearer ${secret:token}"},
+		})
+		assert.ErrorContains(t, err, "Authorization")
+	})
+
+	t.Run("status and json assertions fail", func(t *testing.T) {
+		result, err := New(nil).Execute(ctx, map[string]interface{}{
+			"url": server.URL + "/health",
+			"assert": map[string]interface{}{
+				"status": []interface{}{201.0, 202.0},
+				"json": []interface{}{
+					map[string]interface{}{"path": "$.status", "equals": "degraded"},
+					map[string]interface{}{"path": "$.missing"},
+				},
+			},
+		})
+		assert.ErrorIs(t, err, ErrAssertionFailed)
+		assert.Equal(t, 1, result.ExitCode)
+		assert.Contains(t, result.Error, "status: got 200")
+		assert.Contains(t, result.Error, `json $.status: got "ok", want "degraded"`)
+		assert.Contains(t, result.Error, "json $.missing: path not found")
+	})
+
+	t.Run("error status without assertions fails", func(t *testing.T) {
+		_, err := New(nil).Execute(ctx, map[string]interface{}{"url": server.URL + "/nope"})
+		assert.ErrorIs(t, err, ErrAssertionFailed)
+	})
+
+	t.Run("latency assertion", func(t *testing.T) {
+		_, err := New(nil).Execute(ctx, map[string]interface{}{
+			"url":    server.URL + "/slow",
+			"assert": map[string]interface{}{"max_latency": "1ms"},
+		})
+		assert.ErrorIs(t, err, ErrAssertionFailed)
+		assert.ErrorContains(t, err, "latency")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		result, err := New(nil).Execute(ctx, map[string]interface{}{
+			"url":     server.URL + "/slow",
+			"timeout": "5ms",
+		})
+		assert.Error(t, err)
+		assert.Equal(t, -1, result.ExitCode)
+	})
+
+	t.Run("body is truncated", func(t *testing.T) {
+		result, err := New(nil).Execute(ctx, map[string]interface{}{
+			"url":            server.URL + "/big",
+			"max_body_bytes": 10.0,
+		})
+		require.NoError(t, err)
+		assert.Len(t, result.Output, 10)
+		assert.Equal(t, true, result.Metadata["body_truncated"])
+	})
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	config := map[string]interface{}{
+		"method":  "post",
+		"url":     "http://billing.internal/api/reconcile",
+		"headers": map[string]interface{}{"Authorization": "Bearer ${secret:billing-token}"},
+		"body":    `{"dry_run": false}`,
+	}
+
+	plan, err := New(staticSecrets{"billing-token": "s3cr3t"}).Plan(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"method":  "POST",
+		"url":     "http://billing.internal/api/reconcile",
+		"timeout": "30s",
+		"headers": map[string]string{"Authorization": "Bearer " + plugin.SecretMask},
+		"body":    `{"dry_run": false}`,
+	}, plan.Details)
+
+	_, err = New(staticSecrets{}).Plan(ctx, config)
+	var cerr *plugin.ConfigError
+	require.ErrorAs(t, err, &cerr)
+	assert.Equal(t, "headers.Authorization", cerr.Field)
+}
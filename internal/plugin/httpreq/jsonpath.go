@@ -0,0 +1,83 @@
+package httpreq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a parsed JSONPath expression: either an
+// object key or an array index
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath parses the subset of JSONPath used by assertions:
+// $.a.b, $.items[0].name and $['key with spaces'].
+func parseJSONPath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath must start with $: %q", path)
+	}
+	rest := path[1:]
+	var segments []pathSegment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in JSONPath %q", path)
+			}
+			segments = append(segments, pathSegment{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated [ in JSONPath %q", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("invalid index %q in JSONPath %q", inner, path)
+			}
+			segments = append(segments, pathSegment{index: idx, isIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected %q in JSONPath %q", rest[0], path)
+		}
+	}
+	return segments, nil
+}
+
+// lookupJSONPath evaluates a parsed path against a decoded JSON document
+func lookupJSONPath(doc interface{}, segments []pathSegment) (interface{}, bool) {
+	current := doc
+	for _, seg := range segments {
+		if seg.isIndex {
+			list, ok := current.([]interface{})
+			if !ok || seg.index >= len(list) {
+				return nil, false
+			}
+			current = list[seg.index]
+			continue
+		}
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[seg.key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,50 @@
+package httpreq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"status": "ok",
+		"items": [{"name": "first"}, {"name": "second"}],
+		"odd key": {"nested": true}
+	}`), &doc))
+
+	tests := []struct {
+		path    string
+		want    interface{}
+		found   bool
+		wantErr bool
+	}{
+		{path: "$.status", want: "ok", found: true},
+		{path: "$.items[1].name", want: "second", found: true},
+		{path: "$['odd key'].nested", want: true, found: true},
+		{path: "$.items[5]", found: false},
+		{path: "$.status.missing", found: false},
+		{path: "status", wantErr: true},
+		{path: "$.items[x]", wantErr: true},
+		{path: "$..status", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			segments, err := parseJSONPath(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			got, ok := lookupJSONPath(doc, segments)
+			assert.Equal(t, tt.found, ok)
+			if tt.found {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
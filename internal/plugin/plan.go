@@ -0,0 +1,24 @@
+package plugin
+
+import "context"
+
+// Plan describes what a plugin would do for a configuration without doing
+// it, as shown by dry runs. Secret values are masked with SecretMask.
+type Plan struct {
+	// Command is the command line of the process the plugin would start
+	Command []string `json:"command,omitempty"`
+	// Dir is the working directory of the process
+	Dir string `json:"dir,omitempty"`
+	// Env holds the variables added to the environment of the process
+	Env map[string]string `json:"env,omitempty"`
+	// Details describes the plugin's work beyond the process, e.g. the HTTP
+	// request it would send
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Planner is implemented by plugins that can describe their work for dry
+// runs. Plan is called with a valid configuration and a context carrying
+// the run's Execution, like Execute; it must not have side effects.
+type Planner interface {
+	Plan(ctx context.Context, config map[string]interface{}) (Plan, error)
+}
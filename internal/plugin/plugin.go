@@ -0,0 +1,61 @@
+/*
+Package plugin defines the extension point used to execute jobs.
+
+A Plugin knows how to validate and run one kind of job configuration
+(an inline script, an HTTP call, a SQL statement, ...). Plugins are
+looked up by name through a PluginRegistry. See docs/PLUGIN_FRAMEWORK.md
+for the overall design.
+*/
+package plugin
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrPluginNotFound = errors.New("plugin not found")
+	ErrPluginExists   = errors.New("plugin already registered")
+	ErrInvalidConfig  = errors.New("invalid plugin configuration")
+	ErrNonZeroExit    = errors.New("process exited with non-zero status")
+)
+
+// Plugin is implemented by every job execution engine
+type Plugin interface {
+	// Name returns the unique identifier for this plugin
+	Name() string
+
+	// Description provides details about what the plugin does
+	Description() string
+
+	// Version returns the plugin version
+	Version() string
+
+	// Validate checks if the job configuration is valid for this plugin
+	Validate(config map[string]interface{}) error
+
+	// Execute runs the job with the given configuration. A non-nil error
+	// means the job failed; the returned JobResult is still populated with
+	// whatever output was captured.
+	Execute(ctx context.Context, config map[string]interface{}) (JobResult, error)
+
+	// Capabilities returns what features this plugin supports
+	Capabilities() PluginCapabilities
+}
+
+// PluginCapabilities describes optional plugin features
+type PluginCapabilities struct {
+	SupportsCancel     bool `json:"supports_cancel"`
+	SupportsProgress   bool `json:"supports_progress"`
+	SupportsConcurrent bool `json:"supports_concurrent"`
+}
+
+// JobResult is the outcome of a plugin execution
+type JobResult struct {
+	ExitCode int                    `json:"exit_code"`
+	Output   string                 `json:"output"`
+	Error    string                 `json:"error"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Artifacts lists files written by the plugin, relative to its workdir
+	Artifacts []string `json:"artifacts,omitempty"`
+}
@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/terminal"
+)
+
+// processWaitDelay bounds how long we wait for output pipes to close after
+// the process has been killed on context cancellation
+const processWaitDelay = 5 * time.Second
+
+// Process describes an external command run on behalf of a plugin
+type Process struct {
+	Path string
+	Args []string
+	Dir  string
+	Env  map[string]string
+}
+
+// RunProcess runs an external command, capturing stdout and stderr into the
+// returned JobResult. A non-zero exit status is reported as ErrNonZeroExit.
+// When ctx carries an Execution, its environment is applied below p.Env,
+// its workspace is used if p.Dir is empty and the process is started in its
+// sandbox under its resource limits. Output is also copied to the
+// execution's Stdout and Stderr writers and the execution's Stdin file, if
+// any, is streamed into the process. Under the execution's terminal, if any,
+// both output streams are merged into stdout and input comes from the
+// terminal. A process stopped by a limit is reported as a *limits.Error.
+func RunProcess(ctx context.Context, p Process) (JobResult, error) {
+	e := ExecutionFrom(ctx)
+	env := os.Environ()
+	var (
+		lim              limits.Limits
+		liveOut, liveErr io.Writer
+		term             *terminal.Terminal
+		stdin            string
+	)
+	if e != nil {
+		env = mergeEnv(env, e.Env)
+		lim = e.Limits
+		liveOut, liveErr = e.Stdout, e.Stderr
+		term = e.Terminal
+		stdin = e.Stdin
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	cmd := exec.CommandContext(ctx, p.Path, p.Args...)
+	cmd.Dir = WorkDir(ctx, p.Dir)
+	cmd.Env = mergeEnv(env, p.Env)
+	cmd.WaitDelay = processWaitDelay
+	if e != nil && cmd.Err == nil {
+		if _, err := sandbox.Prepare(cmd, e.Sandbox, lim); err != nil {
+			return JobResult{ExitCode: -1, Error: err.Error()}, err
+		}
+	}
+
+	output := &outputLimit{max: lim.OutputBytes, exceeded: func() { cancel(errOutputLimit) }}
+	var (
+		stdout, stderr bytes.Buffer
+		err            error
+	)
+	if term != nil {
+		err = term.Run(cmd, output.writer(tee(&stdout, liveOut)))
+	} else {
+		if stdin != "" {
+			f, err := os.Open(stdin)
+			if err != nil {
+				return JobResult{ExitCode: -1, Error: err.Error()}, fmt.Errorf("failed to open stdin: %w", err)
+			}
+			defer f.Close()
+			cmd.Stdin = f
+		}
+		cmd.Stdout = output.writer(tee(&stdout, liveOut))
+		cmd.Stderr = output.writer(tee(&stderr, liveErr))
+		err = cmd.Run()
+	}
+	result := JobResult{
+		Output: stdout.String(),
+		Error:  stderr.String(),
+	}
+	if err == nil {
+		return result, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		if cause := context.Cause(ctx); cause == errOutputLimit {
+			return result, &limits.Error{Reason: limits.ReasonOutput, Err: cause}
+		}
+		if ctx.Err() != nil {
+			return result, context.Cause(ctx)
+		}
+		err = fmt.Errorf("%w: %d", ErrNonZeroExit, result.ExitCode)
+		if reason := limits.Classify(lim, exitErr.ProcessState, result.Error); reason != "" {
+			return result, &limits.Error{Reason: reason, Err: err}
+		}
+		return result, err
+	}
+	result.ExitCode = -1
+	return result, fmt.Errorf("failed to run %s: %w", p.Path, err)
+}
+
+var errOutputLimit = errors.New("process output exceeded the limit")
+
+// outputLimit caps the combined size of the streams written through its
+// writers. Once the cap is reached further output is discarded and
+// exceeded is called.
+type outputLimit struct {
+	max      int64
+	exceeded func()
+
+	mu      sync.Mutex
+	written int64
+}
+
+func (o *outputLimit) writer(w io.Writer) io.Writer {
+	if o.max <= 0 {
+		return w
+	}
+	return writerFunc(func(p []byte) (int, error) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		remaining := o.max - o.written
+		if int64(len(p)) <= remaining {
+			n, err := w.Write(p)
+			o.written += int64(n)
+			return n, err
+		}
+		if remaining > 0 {
+			n, err := w.Write(p[:remaining])
+			o.written += int64(n)
+			if err != nil {
+				return n, err
+			}
+		}
+		o.exceeded()
+		return len(p), nil
+	})
+}
+
+// tee copies writes to live as well as w. Errors writing to live are
+// ignored so that a failing log stream does not fail the process.
+func tee(w io.Writer, live io.Writer) io.Writer {
+	if live == nil {
+		return w
+	}
+	return writerFunc(func(p []byte) (int, error) {
+		_, _ = live.Write(p)
+		return w.Write(p)
+	})
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// mergeEnv overlays extra variables on top of a KEY=VALUE environment list
+func mergeEnv(base []string, extra map[string]string) []string {
+	if len(extra) == 0 {
+		return base
+	}
+	env := make([]string, 0, len(base)+len(extra))
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, overridden := extra[key]; !overridden {
+			env = append(env, kv)
+		}
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+extra[k])
+	}
+	return env
+}
@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/klauern/gopher-tower/internal/sandbox"
+	"github.com/klauern/gopher-tower/internal/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunProcess(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	t.Run("non-zero exit", func(t *testing.T) {
+		result, err := RunProcess(context.Background(), Process{
+			Path: sh,
+			Args: []string{"-c", "echo out; echo err >&2; exit 3"},
+		})
+		assert.ErrorIs(t, err, ErrNonZeroExit)
+		assert.Equal(t, 3, result.ExitCode)
+		assert.Equal(t, "out\n", result.Output)
+		assert.Equal(t, "err\n", result.Error)
+	})
+
+	t.Run("execution workdir and env", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx := WithExecution(context.Background(), &Execution{
+			WorkDir: dir,
+			Env:     map[string]string{"HOME": filepath.Join(dir, "home"), "LEVEL": "run"},
+		})
+		result, err := RunProcess(ctx, Process{
+			Path: sh,
+			Args: []string{"-c", "echo \"$PWD $HOME $LEVEL\""},
+			Env:  map[string]string{"LEVEL": "process"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, dir+" "+filepath.Join(dir, "home")+" process\n", result.Output)
+	})
+
+	t.Run("sandboxed process", func(t *testing.T) {
+		dir, outside := t.TempDir(), t.TempDir()
+		policy := &sandbox.Policy{Writable: []string{dir}}
+		if sandbox.Plan(*policy).LandlockABI == 0 {
+			t.Skip("landlock unavailable")
+		}
+		ctx := WithExecution(context.Background(), &Execution{WorkDir: dir, Sandbox: policy})
+		_, err := RunProcess(ctx, Process{Path: sh, Args: []string{"-c", "touch inside"}})
+		require.NoError(t, err)
+		_, err = RunProcess(ctx, Process{Path: sh, Args: []string{"-c", "touch " + filepath.Join(outside, "file")}})
+		assert.ErrorIs(t, err, ErrNonZeroExit)
+		assert.NoFileExists(t, filepath.Join(outside, "file"))
+	})
+
+	t.Run("live output", func(t *testing.T) {
+		var liveOut, liveErr bytes.Buffer
+		ctx := WithExecution(context.Background(), &Execution{
+			WorkDir: t.TempDir(),
+			Stdout:  &liveOut,
+			Stderr:  &liveErr,
+		})
+		result, err := RunProcess(ctx, Process{Path: sh, Args: []string{"-c", "echo out; echo err >&2"}})
+		require.NoError(t, err)
+		assert.Equal(t, result.Output, liveOut.String())
+		assert.Equal(t, result.Error, liveErr.String())
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		dir := t.TempDir()
+		stdin := filepath.Join(dir, "stdin")
+		require.NoError(t, os.WriteFile(stdin, []byte("gopher\n"), 0o644))
+		ctx := WithExecution(context.Background(), &Execution{WorkDir: dir, Stdin: stdin})
+		for range 2 {
+			// Every process reads the whole input
+			result, err := RunProcess(ctx, Process{Path: sh, Args: []string{"-c", `read name; echo "hello $name"`}})
+			require.NoError(t, err)
+			assert.Equal(t, "hello gopher\n", result.Output)
+		}
+	})
+
+	t.Run("terminal", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("pseudo-terminals are only supported on Linux")
+		}
+		ctx := WithExecution(context.Background(), &Execution{
+			WorkDir:  t.TempDir(),
+			Terminal: terminal.New(),
+		})
+		result, err := RunProcess(ctx, Process{Path: sh, Args: []string{"-c", "test -t 0 && echo out; echo err >&2; exit 3"}})
+		assert.ErrorIs(t, err, ErrNonZeroExit)
+		assert.Equal(t, 3, result.ExitCode)
+		assert.Equal(t, "out\r\nerr\r\n", result.Output, "both streams go through the terminal")
+		assert.Empty(t, result.Error)
+	})
+
+	t.Run("explicit dir wins over workspace", func(t *testing.T) {
+		dir, other := t.TempDir(), t.TempDir()
+		ctx := WithExecution(context.Background(), &Execution{WorkDir: dir})
+		result, err := RunProcess(ctx, Process{Path: sh, Args: []string{"-c", "pwd"}, Dir: other})
+		require.NoError(t, err)
+		assert.Equal(t, other+"\n", result.Output)
+	})
+}
+
+func TestRunProcessLimits(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	run := func(l limits.Limits, path string, args ...string) (JobResult, error) {
+		ctx := WithExecution(context.Background(), &Execution{WorkDir: t.TempDir(), Limits: l})
+		return RunProcess(ctx, Process{Path: path, Args: args})
+	}
+
+	t.Run("output", func(t *testing.T) {
+		result, err := run(limits.Limits{OutputBytes: 1000}, sh, "-c", "while :; do echo yes; done")
+		assert.Equal(t, limits.ReasonOutput, limits.ReasonOf(err))
+		assert.Len(t, result.Output, 1000)
+	})
+
+	t.Run("cpu", func(t *testing.T) {
+		_, err := run(limits.Limits{CPUSeconds: 1}, sh, "-c", "while :; do :; done")
+		assert.Equal(t, limits.ReasonCPU, limits.ReasonOf(err))
+		assert.ErrorIs(t, err, ErrNonZeroExit)
+	})
+
+	t.Run("file size", func(t *testing.T) {
+		_, err := run(limits.Limits{FileSizeBytes: 1024}, sh, "-c", "head -c 100000 /dev/zero > big")
+		assert.Equal(t, limits.ReasonFileSize, limits.ReasonOf(err))
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		python, err := exec.LookPath("python3")
+		if err != nil {
+			t.Skip("python3 not available")
+		}
+		_, err = run(limits.Limits{MemoryBytes: 256 << 20}, python, "-c", "x = bytearray(1 << 30)")
+		assert.Equal(t, limits.ReasonOOM, limits.ReasonOf(err))
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		result, err := run(limits.Limits{CPUSeconds: 10, OutputBytes: 100, OpenFiles: 64}, sh, "-c", "echo ok")
+		require.NoError(t, err)
+		assert.Equal(t, "ok\n", result.Output)
+	})
+
+	t.Run("unrelated failure", func(t *testing.T) {
+		_, err := run(limits.Limits{MemoryBytes: 256 << 20}, sh, "-c", "exit 3")
+		assert.ErrorIs(t, err, ErrNonZeroExit)
+		assert.Empty(t, limits.ReasonOf(err))
+	})
+}
+
+func TestMergeEnv(t *testing.T) {
+	env := mergeEnv([]string{"A=1", "B=2"}, map[string]string{"B": "3", "C": "4"})
+	assert.Equal(t, []string{"A=1", "B=3", "C=4"}, env)
+	assert.Equal(t, []string{"A=1"}, mergeEnv([]string{"A=1"}, nil))
+}
@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// PluginRegistry keeps track of the available plugins
+type PluginRegistry interface {
+	// Register adds a new plugin to the registry
+	Register(plugin Plugin) error
+
+	// Get retrieves a plugin by name
+	Get(name string) (Plugin, error)
+
+	// List returns all registered plugins
+	List() []Plugin
+
+	// Unregister removes a plugin from the registry
+	Unregister(name string) error
+}
+
+// registry implements the PluginRegistry interface
+type registry struct {
+	mu      sync.RWMutex
+	plugins map[string]Plugin
+}
+
+// NewRegistry creates an empty plugin registry
+func NewRegistry() PluginRegistry {
+	return &registry{plugins: make(map[string]Plugin)}
+}
+
+// Register adds a new plugin to the registry
+func (r *registry) Register(p Plugin) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.plugins[p.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrPluginExists, p.Name())
+	}
+	r.plugins[p.Name()] = p
+	return nil
+}
+
+// Get retrieves a plugin by name
+func (r *registry) Get(name string) (Plugin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.plugins[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, name)
+	}
+	return p, nil
+}
+
+// List returns all registered plugins sorted by name
+func (r *registry) List() []Plugin {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plugins := make([]Plugin, 0, len(r.plugins))
+	for _, p := range r.plugins {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name() < plugins[j].Name()
+	})
+	return plugins
+}
+
+// Unregister removes a plugin from the registry
+func (r *registry) Unregister(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.plugins[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrPluginNotFound, name)
+	}
+	delete(r.plugins, name)
+	return nil
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlugin is a minimal Plugin used by the registry tests
+type fakePlugin struct {
+	name string
+}
+
+func (f *fakePlugin) Name() string                          { return f.name }
+func (f *fakePlugin) Description() string                   { return "fake" }
+func (f *fakePlugin) Version() string                       { return "0.0.1" }
+func (f *fakePlugin) Validate(map[string]interface{}) error { return nil }
+func (f *fakePlugin) Capabilities() PluginCapabilities      { return PluginCapabilities{} }
+func (f *fakePlugin) Execute(context.Context, map[string]interface{}) (JobResult, error) {
+	return JobResult{}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	require.NoError(t, r.Register(&fakePlugin{name: "b"}))
+	require.NoError(t, r.Register(&fakePlugin{name: "a"}))
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		err := r.Register(&fakePlugin{name: "a"})
+		assert.ErrorIs(t, err, ErrPluginExists)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		p, err := r.Get("a")
+		require.NoError(t, err)
+		assert.Equal(t, "a", p.Name())
+
+		_, err = r.Get("missing")
+		assert.ErrorIs(t, err, ErrPluginNotFound)
+	})
+
+	t.Run("list is sorted", func(t *testing.T) {
+		plugins := r.List()
+		require.Len(t, plugins, 2)
+		assert.Equal(t, "a", plugins[0].Name())
+		assert.Equal(t, "b", plugins[1].Name())
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		require.NoError(t, r.Unregister("a"))
+		assert.ErrorIs(t, r.Unregister("a"), ErrPluginNotFound)
+		assert.Len(t, r.List(), 1)
+	})
+}
@@ -0,0 +1,225 @@
+// Package script provides a plugin that runs an inline script body with
+// sh, bash or python3
+package script
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/klauern/gopher-tower/internal/plugin"
+)
+
+// Name is the registry name of the script plugin
+const Name = "script"
+
+// interpreter describes how a supported interpreter is invoked
+type interpreter struct {
+	// ext is the extension given to the temporary script file
+	ext string
+	// shell marks interpreters that get `set -euo pipefail` semantics
+	shell bool
+}
+
+var interpreters = map[string]interpreter{
+	"sh":      {ext: ".sh", shell: true},
+	"bash":    {ext: ".sh", shell: true},
+	"python3": {ext: ".py"},
+}
+
+// Plugin runs a script body given inline in the job configuration.
+//
+// Example config:
+//
+//	{
+//		"interpreter": "bash",
+//		"body": "echo hello\ncurl -fsS http://localhost/health | jq .status",
+//		"args": ["first-arg"],
+//		"env": {"GREETING": "hi"},
+//		"workdir": "/path/to/workspace"
+//	}
+//
+// The body is written verbatim to a private temporary file so that line
+// numbers in interpreter error messages match the configured body. Shell
+// options are passed on the command line instead of being prepended to the
+// script for the same reason.
+type Plugin struct {
+	// lookPath resolves interpreter binaries, overridable in tests
+	lookPath func(string) (string, error)
+
+	pipefailOnce sync.Once
+	shPipefail   bool
+}
+
+// New creates a script plugin
+func New() *Plugin {
+	return &Plugin{lookPath: exec.LookPath}
+}
+
+// Name returns the unique identifier for this plugin
+func (p *Plugin) Name() string { return Name }
+
+// Description provides details about what the plugin does
+func (p *Plugin) Description() string {
+	return "Runs an inline sh, bash or python3 script"
+}
+
+// Version returns the plugin version
+func (p *Plugin) Version() string { return "1.0.0" }
+
+// Capabilities returns what features this plugin supports
+func (p *Plugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{
+		SupportsCancel:     true,
+		SupportsConcurrent: true,
+	}
+}
+
+// config is the parsed form of the plugin configuration
+type config struct {
+	interpreter string
+	body        string
+	args        []string
+	env         map[string]string
+	workdir     string
+}
+
+func parseConfig(raw map[string]interface{}) (*config, error) {
+	var (
+		c   config
+		err error
+	)
+	if c.interpreter, err = plugin.RequiredString(raw, "interpreter"); err != nil {
+		return nil, err
+	}
+	if _, ok := interpreters[c.interpreter]; !ok {
+		return nil, plugin.NewConfigError("interpreter", "must be one of sh, bash, python3")
+	}
+	if c.body, err = plugin.RequiredString(raw, "body"); err != nil {
+		return nil, err
+	}
+	if c.args, err = plugin.StringSlice(raw, "args"); err != nil {
+		return nil, err
+	}
+	if c.env, err = plugin.StringMap(raw, "env"); err != nil {
+		return nil, err
+	}
+	if c.workdir, err = plugin.String(raw, "workdir"); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks if the job configuration is valid for this plugin
+func (p *Plugin) Validate(raw map[string]interface{}) error {
+	_, err := parseConfig(raw)
+	return err
+}
+
+// Execute writes the script body to a private temporary file and runs it
+func (p *Plugin) Execute(ctx context.Context, raw map[string]interface{}) (plugin.JobResult, error) {
+	c, err := parseConfig(raw)
+	if err != nil {
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+
+	path, err := p.lookPath(c.interpreter)
+	if err != nil {
+		err = fmt.Errorf("interpreter %s not available: %w", c.interpreter, err)
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+
+	workdir := plugin.WorkDir(ctx, c.workdir)
+	scriptPath, cleanup, err := writeScript(workdir, c.body, interpreters[c.interpreter].ext)
+	if err != nil {
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+	defer cleanup()
+
+	args := append(p.interpreterArgs(c.interpreter), scriptPath)
+	return plugin.RunProcess(ctx, plugin.Process{
+		Path: path,
+		Args: append(args, c.args...),
+		Dir:  workdir,
+		Env:  c.env,
+	})
+}
+
+// Plan describes the process Execute would start. The script file is
+// created at execution time in a private directory, shown as .script-*.
+func (p *Plugin) Plan(ctx context.Context, raw map[string]interface{}) (plugin.Plan, error) {
+	c, err := parseConfig(raw)
+	if err != nil {
+		return plugin.Plan{}, err
+	}
+	path, err := p.lookPath(c.interpreter)
+	if err != nil {
+		// The run may be executed by an agent providing the interpreter
+		path = c.interpreter
+	}
+
+	workdir := plugin.WorkDir(ctx, c.workdir)
+	scriptDir := workdir
+	if scriptDir == "" {
+		scriptDir = os.TempDir()
+	}
+	command := append([]string{path}, p.interpreterArgs(c.interpreter)...)
+	command = append(command, filepath.Join(scriptDir, ".script-*", "script"+interpreters[c.interpreter].ext))
+	return plugin.Plan{
+		Command: append(command, c.args...),
+		Dir:     workdir,
+		Env:     c.env,
+		Details: map[string]interface{}{"interpreter": c.interpreter, "body": c.body},
+	}, nil
+}
+
+// interpreterArgs returns the flags that give shells strict-mode semantics
+func (p *Plugin) interpreterArgs(name string) []string {
+	switch name {
+	case "bash":
+		return []string{"-e", "-u", "-o", "pipefail"}
+	case "sh":
+		if p.supportsPipefail() {
+			return []string{"-e", "-u", "-o", "pipefail"}
+		}
+		return []string{"-e", "-u"}
+	default:
+		return nil
+	}
+}
+
+// supportsPipefail reports whether the system sh understands `-o pipefail`.
+// Older dash releases, the default /bin/sh on Debian, reject the option.
+func (p *Plugin) supportsPipefail() bool {
+	p.pipefailOnce.Do(func() {
+		path, err := p.lookPath("sh")
+		if err != nil {
+			return
+		}
+		p.shPipefail = exec.Command(path, "-o", "pipefail", "-c", ":").Run() == nil
+	})
+	return p.shPipefail
+}
+
+// writeScript stores the body in a private directory inside dir (or the
+// system temp directory when dir is empty) and returns a cleanup function
+func writeScript(dir, body, ext string) (string, func(), error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	privateDir, err := os.MkdirTemp(dir, ".script-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create script directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(privateDir) }
+
+	path := filepath.Join(privateDir, "script"+ext)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write script: %w", err)
+	}
+	return path, cleanup, nil
+}
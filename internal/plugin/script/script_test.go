@@ -0,0 +1,178 @@
+package script
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireInterpreter(t *testing.T, name string) {
+	t.Helper()
+	if _, err := exec.LookPath(name); err != nil {
+		t.Skipf("%s not available", name)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "valid bash",
+			config: map[string]interface{}{"interpreter": "bash", "body": "echo hi"},
+		},
+		{
+			name:    "missing interpreter",
+			config:  map[string]interface{}{"body": "echo hi"},
+			wantErr: "interpreter",
+		},
+		{
+			name:    "unsupported interpreter",
+			config:  map[string]interface{}{"interpreter": "ruby", "body": "puts 1"},
+			wantErr: "interpreter",
+		},
+		{
+			name:    "missing body",
+			config:  map[string]interface{}{"interpreter": "sh"},
+			wantErr: "body",
+		},
+		{
+			name: "bad args",
+			config: map[string]interface{}{
+				"interpreter": "sh",
+				"body":        "echo hi",
+				"args":        "not-a-list",
+			},
+			wantErr: "args",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Validate(tt.config)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var cfgErr *plugin.ConfigError
+			require.ErrorAs(t, err, &cfgErr)
+			assert.Equal(t, tt.wantErr, cfgErr.Field)
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("bash with args and env", func(t *testing.T) {
+		requireInterpreter(t, "bash")
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"interpreter": "bash",
+			"body":        "echo \"$GREETING $1\"",
+			"args":        []interface{}{"world"},
+			"env":         map[string]interface{}{"GREETING": "hello"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, "hello world\n", result.Output)
+	})
+
+	t.Run("bash pipefail", func(t *testing.T) {
+		requireInterpreter(t, "bash")
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"interpreter": "bash",
+			"body":        "false | true\necho unreachable",
+		})
+		assert.ErrorIs(t, err, plugin.ErrNonZeroExit)
+		assert.NotEqual(t, 0, result.ExitCode)
+		assert.NotContains(t, result.Output, "unreachable")
+	})
+
+	t.Run("sh unset variable", func(t *testing.T) {
+		requireInterpreter(t, "sh")
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"interpreter": "sh",
+			"body":        "echo \"$DEFINITELY_NOT_SET_VARIABLE\"\necho unreachable",
+		})
+		assert.ErrorIs(t, err, plugin.ErrNonZeroExit)
+		assert.NotContains(t, result.Output, "unreachable")
+	})
+
+	t.Run("line numbers are preserved", func(t *testing.T) {
+		requireInterpreter(t, "bash")
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"interpreter": "bash",
+			"body":        "echo one\necho two\nno-such-command-here\n",
+		})
+		assert.Error(t, err)
+		assert.Contains(t, result.Error, "line 3")
+	})
+
+	t.Run("python traceback line numbers", func(t *testing.T) {
+		requireInterpreter(t, "python3")
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"interpreter": "python3",
+			"body":        "x = 1\n\nraise ValueError('bad')\n",
+		})
+		assert.Error(t, err)
+		assert.Contains(t, result.Error, "line 3")
+	})
+
+	t.Run("script file is private and removed", func(t *testing.T) {
+		requireInterpreter(t, "sh")
+		dir := t.TempDir()
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"interpreter": "sh",
+			"body":        "stat -c %a \"$0\"",
+			"workdir":     dir,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "600\n", result.Output)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("missing interpreter binary", func(t *testing.T) {
+		p := New()
+		p.lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+		_, err := p.Execute(context.Background(), map[string]interface{}{
+			"interpreter": "bash",
+			"body":        "echo hi",
+		})
+		assert.ErrorIs(t, err, exec.ErrNotFound)
+	})
+}
+
+func TestPlan(t *testing.T) {
+	p := New()
+	p.lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	workdir := t.TempDir()
+	ctx := plugin.WithExecution(context.Background(), &plugin.Execution{WorkDir: workdir})
+
+	plan, err := p.Plan(ctx, map[string]interface{}{
+		"interpreter": "bash",
+		"body":        "echo $GREETING",
+		"args":        []interface{}{"first"},
+		"env":         map[string]interface{}{"GREETING": "hi"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/usr/bin/bash", "-e", "-u", "-o", "pipefail", workdir + "/.script-*/script.sh", "first"}, plan.Command)
+	assert.Equal(t, workdir, plan.Dir)
+	assert.Equal(t, map[string]string{"GREETING": "hi"}, plan.Env)
+	assert.Equal(t, "echo $GREETING", plan.Details["body"])
+	entries, err := os.ReadDir(workdir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "planning must not write the script")
+
+	_, err = p.Plan(ctx, map[string]interface{}{"interpreter": "ruby", "body": "puts 1"})
+	assert.ErrorIs(t, err, plugin.ErrInvalidConfig)
+}
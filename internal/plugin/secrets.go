@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrSecretsUnavailable is returned when a configuration references a
+// secret but the plugin was created without a SecretResolver
+var ErrSecretsUnavailable = errors.New("secret store not configured")
+
+// SecretResolver looks up secret values by key. keymanager.SecretManager
+// satisfies this interface.
+type SecretResolver interface {
+	GetSecret(ctx context.Context, key string) (string, error)
+}
+
+// secretRefPattern matches references of the form ${secret:NAME}. Names
+// never contain path separators, which the key manager rejects as keys.
+var secretRefPattern = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.\-]+)\}`)
+
+// SecretRefs returns the names of all secrets referenced in s
+func SecretRefs(s string) []string {
+	matches := secretRefPattern.FindAllStringSubmatch(s, -1)
+	refs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		refs = append(refs, m[1])
+	}
+	return refs
+}
+
+// ResolveSecrets replaces every ${secret:NAME} reference in s with the
+// value returned by the resolver
+func ResolveSecrets(ctx context.Context, resolver SecretResolver, s string) (string, error) {
+	var resolveErr error
+	resolved := secretRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if resolveErr != nil {
+			return ref
+		}
+		if resolver == nil {
+			resolveErr = ErrSecretsUnavailable
+			return ref
+		}
+		name := secretRefPattern.FindStringSubmatch(ref)[1]
+		value, err := resolver.GetSecret(ctx, name)
+		if err != nil {
+			resolveErr = fmt.Errorf("failed to resolve secret %q: %w", name, err)
+			return ref
+		}
+		return value
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// SecretMask stands in for secret values shown to users, e.g. by dry runs
+const SecretMask = "********"
+
+// MaskSecrets checks that every ${secret:NAME} reference in s resolves and
+// replaces it with SecretMask, so that s can be shown without disclosing
+// the secrets
+func MaskSecrets(ctx context.Context, resolver SecretResolver, s string) (string, error) {
+	if resolver == nil {
+		return ResolveSecrets(ctx, nil, s)
+	}
+	return ResolveSecrets(ctx, maskingResolver{resolver}, s)
+}
+
+// maskingResolver resolves secrets to SecretMask once they are known to
+// exist
+type maskingResolver struct {
+	SecretResolver
+}
+
+func (r maskingResolver) GetSecret(ctx context.Context, key string) (string, error) {
+	if _, err := r.SecretResolver.GetSecret(ctx, key); err != nil {
+		return "", err
+	}
+	return SecretMask, nil
+}
@@ -0,0 +1,78 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSecrets is an in-memory SecretResolver
+type mapSecrets map[string]string
+
+func (m mapSecrets) GetSecret(_ context.Context, key string) (string, error) {
+	v, ok := m[key]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return v, nil
+}
+
+func TestResolveSecrets(t *testing.T) {
+	ctx := context.Background()
+	secrets := mapSecrets{"api-token": "s3cr3t", "user": "bob"}
+
+	t.Run("replaces references", func(t *testing.T) {
+		got, err := ResolveSecrets(ctx, secrets, "Bearer ${secret:api-token} for ${secret:user}")
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer s3cr3t for bob", got)
+	})
+
+	t.Run("no references", func(t *testing.T) {
+		got, err := ResolveSecrets(ctx, nil, "plain value")
+		require.NoError(t, err)
+		assert.Equal(t, "plain value", got)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := ResolveSecrets(ctx, secrets, "${secret:nope}")
+		assert.ErrorContains(t, err, "nope")
+	})
+
+	t.Run("no resolver", func(t *testing.T) {
+		_, err := ResolveSecrets(ctx, nil, "${secret:api-token}")
+		assert.ErrorIs(t, err, ErrSecretsUnavailable)
+	})
+
+	t.Run("refs", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b.c"}, SecretRefs("${secret:a} and ${secret:b.c}"))
+		assert.Empty(t, SecretRefs("none"))
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		// Names with path separators are no references and left as they are
+		for _, s := range []string{"${secret:team/token}", `${secret:team\token}`, "${secret:../token}", "${secret:}"} {
+			assert.Empty(t, SecretRefs(s), s)
+			got, err := ResolveSecrets(ctx, secrets, s)
+			require.NoError(t, err)
+			assert.Equal(t, s, got)
+		}
+	})
+}
+
+func TestMaskSecrets(t *testing.T) {
+	ctx := context.Background()
+	secrets := mapSecrets{"api-token": "s3cr3t"}
+
+	got, err := MaskSecrets(ctx, secrets, "Bearer ${secret:api-token}")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer "+SecretMask, got)
+
+	_, err = MaskSecrets(ctx, secrets, "${secret:nope}")
+	assert.ErrorContains(t, err, "nope")
+
+	_, err = MaskSecrets(ctx, nil, "${secret:api-token}")
+	assert.ErrorIs(t, err, ErrSecretsUnavailable)
+}
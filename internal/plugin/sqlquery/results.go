@@ -0,0 +1,133 @@
+package sqlquery
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// resultSet is a fully read query result
+type resultSet struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+func readResultSet(rows *sql.Rows) (*resultSet, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	rs := &resultSet{columns: columns, rows: make([][]interface{}, 0)}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			// SQLite TEXT may come back as []byte; keep artifacts readable
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		rs.rows = append(rs.rows, values)
+	}
+	return rs, rows.Err()
+}
+
+// write stores the result set in the requested formats and returns the
+// artifact paths relative to workdir
+func (rs *resultSet) write(workdir string, index int, formats []string) ([]string, error) {
+	dir := filepath.Join(workdir, resultsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	var artifacts []string
+	for _, format := range formats {
+		name := filepath.Join(resultsDir, fmt.Sprintf("statement-%d.%s", index, format))
+		var err error
+		switch format {
+		case "csv":
+			err = rs.writeCSV(filepath.Join(workdir, name))
+		case "json":
+			err = rs.writeJSON(filepath.Join(workdir, name))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		artifacts = append(artifacts, name)
+	}
+	return artifacts, nil
+}
+
+func (rs *resultSet) writeCSV(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(rs.columns); err != nil {
+		return err
+	}
+	record := make([]string, len(rs.columns))
+	for _, row := range rs.rows {
+		for i, v := range row {
+			record[i] = formatValue(v)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (rs *resultSet) writeJSON(path string) error {
+	records := make([]map[string]interface{}, len(rs.rows))
+	for i, row := range rs.rows {
+		record := make(map[string]interface{}, len(rs.columns))
+		for j, col := range rs.columns {
+			record[col] = row[j]
+		}
+		records[i] = record
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// formatValue renders a scanned column value for CSV output
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,415 @@
+// Package sqlquery provides a plugin that runs SQL statements against a
+// SQLite database inside a single transaction
+package sqlquery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/klauern/gopher-tower/internal/plugin"
+	_ "modernc.org/sqlite"
+)
+
+// Name is the registry name of the SQL plugin
+const Name = "sql"
+
+// resultsDir is the directory, relative to the workdir, that receives
+// captured result sets
+const resultsDir = "sql-results"
+
+// ErrAssertionFailed is returned when a statement's row count does not
+// match its assertion
+var ErrAssertionFailed = errors.New("sql assertion failed")
+
+// Plugin runs a list of SQL statements against a SQLite database.
+//
+// Example config:
+//
+//	{
+//		"dsn": "/var/lib/app/app.db",
+//		"read_only": false,
+//		"statements": [
+//			"DELETE FROM sessions WHERE expires_at < datetime('now')",
+//			{"sql": "SELECT count(*) AS n FROM sessions", "expect_rows": 1},
+//			{"sql": "SELECT * FROM audit WHERE level = 'error'", "max_rows": 0}
+//		],
+//		"formats": ["csv", "json"],
+//		"workdir": "/path/to/workspace"
+//	}
+//
+// All statements run in one transaction, which is rolled back if any
+// statement or assertion fails. Result sets of row-returning statements are
+// written to <workdir>/sql-results/ in the requested formats. With
+// read_only set, the connection runs with PRAGMA query_only so any write is
+// refused by SQLite itself.
+type Plugin struct{}
+
+// New creates a SQL plugin
+func New() *Plugin {
+	return &Plugin{}
+}
+
+// Name returns the unique identifier for this plugin
+func (p *Plugin) Name() string { return Name }
+
+// Description provides details about what the plugin does
+func (p *Plugin) Description() string {
+	return "Runs SQL statements against a SQLite database in a transaction"
+}
+
+// Version returns the plugin version
+func (p *Plugin) Version() string { return "1.0.0" }
+
+// Capabilities returns what features this plugin supports
+func (p *Plugin) Capabilities() plugin.PluginCapabilities {
+	return plugin.PluginCapabilities{
+		SupportsCancel:     true,
+		SupportsConcurrent: true,
+	}
+}
+
+// statement is one SQL statement with optional row count assertions
+type statement struct {
+	sql        string
+	expectRows *int64
+	minRows    *int64
+	maxRows    *int64
+}
+
+// config is the parsed form of the plugin configuration
+type config struct {
+	dsn        string
+	readOnly   bool
+	statements []statement
+	formats    []string
+	workdir    string
+}
+
+func parseConfig(raw map[string]interface{}) (*config, error) {
+	var (
+		c   config
+		err error
+	)
+	if c.dsn, err = plugin.RequiredString(raw, "dsn"); err != nil {
+		return nil, err
+	}
+	if c.readOnly, err = plugin.Bool(raw, "read_only"); err != nil {
+		return nil, err
+	}
+	if c.workdir, err = plugin.String(raw, "workdir"); err != nil {
+		return nil, err
+	}
+	if c.formats, err = plugin.StringSlice(raw, "formats"); err != nil {
+		return nil, err
+	}
+	if c.formats == nil {
+		c.formats = []string{"csv", "json"}
+	}
+	for i, f := range c.formats {
+		if f != "csv" && f != "json" {
+			return nil, plugin.NewConfigError(fmt.Sprintf("formats[%d]", i), "must be csv or json")
+		}
+	}
+
+	list, ok := raw["statements"].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, plugin.NewConfigError("statements", "must be a non-empty list")
+	}
+	for i, item := range list {
+		stmt, err := parseStatement(fmt.Sprintf("statements[%d]", i), item)
+		if err != nil {
+			return nil, err
+		}
+		c.statements = append(c.statements, stmt)
+	}
+	return &c, nil
+}
+
+func parseStatement(field string, item interface{}) (statement, error) {
+	switch v := item.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return statement{}, plugin.NewConfigError(field, "must not be empty")
+		}
+		return statement{sql: v}, nil
+	case map[string]interface{}:
+		query, err := plugin.RequiredString(v, "sql")
+		if err != nil {
+			return statement{}, plugin.NewConfigError(field+".sql", "is required")
+		}
+		stmt := statement{sql: query}
+		for key, dst := range map[string]**int64{
+			"expect_rows": &stmt.expectRows,
+			"min_rows":    &stmt.minRows,
+			"max_rows":    &stmt.maxRows,
+		} {
+			n, ok, err := plugin.Int(v, key)
+			if err != nil {
+				return statement{}, plugin.NewConfigError(field+"."+key, "must be a whole number")
+			}
+			if ok {
+				*dst = &n
+			}
+		}
+		return stmt, nil
+	default:
+		return statement{}, plugin.NewConfigError(field, "must be a string or an object")
+	}
+}
+
+// Validate checks if the job configuration is valid for this plugin
+func (p *Plugin) Validate(raw map[string]interface{}) error {
+	_, err := parseConfig(raw)
+	return err
+}
+
+// Execute runs the statements in a transaction
+func (p *Plugin) Execute(ctx context.Context, raw map[string]interface{}) (plugin.JobResult, error) {
+	c, err := parseConfig(raw)
+	if err != nil {
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+
+	db, err := sql.Open("sqlite", c.connectionDSN())
+	if err != nil {
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: c.readOnly})
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		return plugin.JobResult{ExitCode: -1, Error: err.Error()}, err
+	}
+
+	workdir := plugin.WorkDir(ctx, c.workdir)
+	result := plugin.JobResult{}
+	var (
+		output    strings.Builder
+		summaries []map[string]interface{}
+	)
+	// fail rolls back and reports the statements that ran before err
+	fail := func(exitCode int, err error) (plugin.JobResult, error) {
+		_ = tx.Rollback()
+		result.ExitCode = exitCode
+		result.Output = output.String()
+		result.Error = err.Error()
+		result.Metadata = map[string]interface{}{"statements": summaries}
+		return result, err
+	}
+	for i, stmt := range c.statements {
+		summary, rs, err := runStatement(ctx, tx, stmt)
+		if err == nil {
+			err = stmt.check(summary.rows)
+		}
+		if err != nil {
+			return fail(1, fmt.Errorf("statement %d: %w", i, err))
+		}
+
+		entry := map[string]interface{}{"index": i, "rows": summary.rows}
+		if rs != nil {
+			entry["columns"] = rs.columns
+			if workdir != "" {
+				files, err := rs.write(workdir, i, c.formats)
+				if err != nil {
+					return fail(-1, err)
+				}
+				result.Artifacts = append(result.Artifacts, files...)
+			}
+			fmt.Fprintf(&output, "statement %d: %d row(s) returned\n", i, summary.rows)
+		} else {
+			fmt.Fprintf(&output, "statement %d: %d row(s) affected\n", i, summary.rows)
+		}
+		summaries = append(summaries, entry)
+	}
+
+	if c.readOnly {
+		err = tx.Rollback()
+	} else {
+		err = tx.Commit()
+	}
+	if err != nil {
+		return fail(-1, fmt.Errorf("failed to finish transaction: %w", err))
+	}
+
+	result.Output = output.String()
+	result.Metadata = map[string]interface{}{"statements": summaries}
+	return result, nil
+}
+
+// Plan describes the statements Execute would run and where their result
+// sets would be written
+func (p *Plugin) Plan(ctx context.Context, raw map[string]interface{}) (plugin.Plan, error) {
+	c, err := parseConfig(raw)
+	if err != nil {
+		return plugin.Plan{}, err
+	}
+	statements := make([]string, len(c.statements))
+	for i, stmt := range c.statements {
+		statements[i] = stmt.sql
+	}
+	details := map[string]interface{}{
+		"dsn":        c.connectionDSN(),
+		"read_only":  c.readOnly,
+		"statements": statements,
+	}
+	if workdir := plugin.WorkDir(ctx, c.workdir); workdir != "" {
+		details["results_dir"] = filepath.Join(workdir, resultsDir)
+		details["formats"] = c.formats
+	}
+	return plugin.Plan{Details: details}, nil
+}
+
+// connectionDSN adds the query_only pragma to the DSN in read-only mode so
+// it applies to every pooled connection
+func (c *config) connectionDSN() string {
+	if !c.readOnly {
+		return c.dsn
+	}
+	sep := "?"
+	if strings.Contains(c.dsn, "?") {
+		sep = "&"
+	}
+	return c.dsn + sep + "_pragma=query_only(1)"
+}
+
+// statementSummary describes the effect of one statement
+type statementSummary struct {
+	rows int64
+}
+
+// returnsRows reports whether a statement produces a result set: a query,
+// or a write with a RETURNING clause
+func returnsRows(query string) bool {
+	words := keywords(query)
+	if len(words) == 0 {
+		return false
+	}
+	if words[0] == "WITH" {
+		// The common table expressions are parenthesized and skipped by
+		// keywords; the main statement starts at its first keyword
+		i := slices.IndexFunc(words, func(w string) bool {
+			switch w {
+			case "SELECT", "VALUES", "INSERT", "UPDATE", "DELETE", "REPLACE":
+				return true
+			}
+			return false
+		})
+		if i < 0 {
+			return false
+		}
+		words = words[i:]
+	}
+	switch words[0] {
+	case "SELECT", "PRAGMA", "EXPLAIN", "VALUES":
+		return true
+	case "INSERT", "UPDATE", "DELETE", "REPLACE":
+		return slices.Contains(words[1:], "RETURNING")
+	default:
+		return false
+	}
+}
+
+// keywords returns the bare words of a statement outside parentheses in
+// upper case, skipping comments, string literals and quoted identifiers
+func keywords(query string) []string {
+	var words []string
+	depth := 0
+	for i := 0; i < len(query); {
+		rest := query[i:]
+		switch c := query[i]; {
+		case strings.HasPrefix(rest, "--"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				return words
+			}
+			i += end + 1
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return words
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			// a doubled quote inside a literal scans as two adjacent literals
+			end := strings.IndexByte(rest[1:], closing)
+			if end < 0 {
+				return words
+			}
+			i += end + 2
+		case isWordByte(c):
+			j := i
+			for j < len(query) && isWordByte(query[j]) {
+				j++
+			}
+			if depth == 0 {
+				words = append(words, strings.ToUpper(query[i:j]))
+			}
+			i = j
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	return words
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func runStatement(ctx context.Context, tx *sql.Tx, stmt statement) (statementSummary, *resultSet, error) {
+	if !returnsRows(stmt.sql) {
+		res, err := tx.ExecContext(ctx, stmt.sql)
+		if err != nil {
+			return statementSummary{}, nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return statementSummary{}, nil, err
+		}
+		return statementSummary{rows: n}, nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt.sql)
+	if err != nil {
+		return statementSummary{}, nil, err
+	}
+	defer rows.Close()
+
+	rs, err := readResultSet(rows)
+	if err != nil {
+		return statementSummary{}, nil, err
+	}
+	return statementSummary{rows: int64(len(rs.rows))}, rs, nil
+}
+
+// check evaluates the row count assertions of a statement
+func (s statement) check(rows int64) error {
+	if s.expectRows != nil && rows != *s.expectRows {
+		return fmt.Errorf("%w: got %d row(s), want %d", ErrAssertionFailed, rows, *s.expectRows)
+	}
+	if s.minRows != nil && rows < *s.minRows {
+		return fmt.Errorf("%w: got %d row(s), want at least %d", ErrAssertionFailed, rows, *s.minRows)
+	}
+	if s.maxRows != nil && rows > *s.maxRows {
+		return fmt.Errorf("%w: got %d row(s), want at most %d", ErrAssertionFailed, rows, *s.maxRows)
+	}
+	return nil
+}
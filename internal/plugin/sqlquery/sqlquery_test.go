@@ -0,0 +1,289 @@
+package sqlquery
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauern/gopher-tower/internal/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDB creates a SQLite database with a small fixture table
+func setupDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, price REAL);
+		INSERT INTO items (name, price) VALUES ('apple', 1.5), ('pear', 2), ('plum', NULL);
+	`)
+	require.NoError(t, err)
+	return path
+}
+
+func countItems(t *testing.T, path string) int {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM items").Scan(&n))
+	return n
+}
+
+func TestValidate(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		wantField string
+	}{
+		{
+			name: "valid",
+			config: map[string]interface{}{
+				"dsn":        "test.db",
+				"statements": []interface{}{"SELECT 1", map[string]interface{}{"sql": "SELECT 2", "expect_rows": 1.0}},
+			},
+		},
+		{
+			name:      "missing dsn",
+			config:    map[string]interface{}{"statements": []interface{}{"SELECT 1"}},
+			wantField: "dsn",
+		},
+		{
+			name:      "no statements",
+			config:    map[string]interface{}{"dsn": "test.db", "statements": []interface{}{}},
+			wantField: "statements",
+		},
+		{
+			name: "statement without sql",
+			config: map[string]interface{}{
+				"dsn":        "test.db",
+				"statements": []interface{}{"SELECT 1", map[string]interface{}{"expect_rows": 1.0}},
+			},
+			wantField: "statements[1].sql",
+		},
+		{
+			name: "bad format",
+			config: map[string]interface{}{
+				"dsn":        "test.db",
+				"statements": []interface{}{"SELECT 1"},
+				"formats":    []interface{}{"xml"},
+			},
+			wantField: "formats[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Validate(tt.config)
+			if tt.wantField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var cfgErr *plugin.ConfigError
+			require.ErrorAs(t, err, &cfgErr)
+			assert.Equal(t, tt.wantField, cfgErr.Field)
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes and captures results", func(t *testing.T) {
+		dbPath := setupDB(t)
+		workdir := t.TempDir()
+
+		result, err := New().Execute(ctx, map[string]interface{}{
+			"dsn": dbPath,
+			"statements": []interface{}{
+				"DELETE FROM items WHERE name = 'plum'",
+				map[string]interface{}{"sql": "SELECT name, price FROM items ORDER BY id", "expect_rows": 2.0},
+			},
+			"workdir": workdir,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, countItems(t, dbPath))
+		assert.Contains(t, result.Output, "statement 0: 1 row(s) affected")
+		assert.Contains(t, result.Output, "statement 1: 2 row(s) returned")
+		assert.Equal(t, []string{
+			filepath.Join(resultsDir, "statement-1.csv"),
+			filepath.Join(resultsDir, "statement-1.json"),
+		}, result.Artifacts)
+
+		csvData, err := os.ReadFile(filepath.Join(workdir, resultsDir, "statement-1.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "name,price\napple,1.5\npear,2\n", string(csvData))
+
+		jsonData, err := os.ReadFile(filepath.Join(workdir, resultsDir, "statement-1.json"))
+		require.NoError(t, err)
+		var records []map[string]interface{}
+		require.NoError(t, json.Unmarshal(jsonData, &records))
+		require.Len(t, records, 2)
+		assert.Equal(t, "apple", records[0]["name"])
+	})
+
+	t.Run("assertion failure rolls back", func(t *testing.T) {
+		dbPath := setupDB(t)
+
+		result, err := New().Execute(ctx, map[string]interface{}{
+			"dsn": dbPath,
+			"statements": []interface{}{
+				"DELETE FROM items",
+				map[string]interface{}{"sql": "SELECT * FROM items", "min_rows": 1.0},
+			},
+		})
+		assert.ErrorIs(t, err, ErrAssertionFailed)
+		assert.Equal(t, 1, result.ExitCode)
+		assert.Contains(t, result.Error, "statement 1")
+		assert.Equal(t, 3, countItems(t, dbPath))
+	})
+
+	t.Run("affected rows assertion", func(t *testing.T) {
+		dbPath := setupDB(t)
+
+		_, err := New().Execute(ctx, map[string]interface{}{
+			"dsn": dbPath,
+			"statements": []interface{}{
+				map[string]interface{}{"sql": "UPDATE items SET price = 0", "max_rows": 2.0},
+			},
+		})
+		assert.ErrorIs(t, err, ErrAssertionFailed)
+	})
+
+	t.Run("read only refuses writes", func(t *testing.T) {
+		dbPath := setupDB(t)
+
+		result, err := New().Execute(ctx, map[string]interface{}{
+			"dsn":       dbPath,
+			"read_only": true,
+			"statements": []interface{}{
+				"SELECT count(*) FROM items",
+				"DELETE FROM items",
+			},
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, result.ExitCode)
+		assert.Equal(t, 3, countItems(t, dbPath))
+	})
+
+	t.Run("read only allows queries", func(t *testing.T) {
+		dbPath := setupDB(t)
+
+		result, err := New().Execute(ctx, map[string]interface{}{
+			"dsn":        dbPath,
+			"read_only":  true,
+			"statements": []interface{}{"SELECT * FROM items"},
+			"formats":    []interface{}{"json"},
+			"workdir":    t.TempDir(),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(resultsDir, "statement-0.json")}, result.Artifacts)
+	})
+
+	t.Run("captures returning and commented queries", func(t *testing.T) {
+		dbPath := setupDB(t)
+		workdir := t.TempDir()
+
+		result, err := New().Execute(ctx, map[string]interface{}{
+			"dsn": dbPath,
+			"statements": []interface{}{
+				"DELETE FROM items WHERE price IS NULL RETURNING name",
+				"-- remaining items\n/* cheapest first */ SELECT name FROM items ORDER BY price",
+			},
+			"formats": []interface{}{"csv"},
+			"workdir": workdir,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result.Output, "statement 0: 1 row(s) returned")
+		assert.Contains(t, result.Output, "statement 1: 2 row(s) returned")
+		assert.Equal(t, 2, countItems(t, dbPath))
+
+		csvData, err := os.ReadFile(filepath.Join(workdir, resultsDir, "statement-0.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "name\nplum\n", string(csvData))
+	})
+
+	t.Run("artifact failure keeps output", func(t *testing.T) {
+		dbPath := setupDB(t)
+		workdir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(workdir, resultsDir), nil, 0o644))
+
+		result, err := New().Execute(ctx, map[string]interface{}{
+			"dsn": dbPath,
+			"statements": []interface{}{
+				"DELETE FROM items WHERE name = 'plum'",
+				"SELECT * FROM items",
+			},
+			"workdir": workdir,
+		})
+		assert.Error(t, err)
+		assert.Equal(t, -1, result.ExitCode)
+		assert.Contains(t, result.Output, "statement 0: 1 row(s) affected")
+		assert.Len(t, result.Metadata["statements"], 1)
+		assert.Equal(t, 3, countItems(t, dbPath))
+	})
+
+	t.Run("invalid sql", func(t *testing.T) {
+		_, err := New().Execute(ctx, map[string]interface{}{
+			"dsn":        setupDB(t),
+			"statements": []interface{}{"SELEKT nonsense"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestReturnsRows(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT 1", true},
+		{"  with x AS (SELECT 1) SELECT * FROM x", true},
+		{"WITH old AS (SELECT id FROM items) DELETE FROM items WHERE id IN (SELECT id FROM old)", false},
+		{"WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 3) INSERT INTO items (id) SELECT i FROM n", false},
+		{"WITH x AS (SELECT 1), y AS NOT MATERIALIZED (SELECT 2) UPDATE items SET price = 0 RETURNING id", true},
+		{"-- comment\nSELECT 1", true},
+		{"/* select */ DELETE FROM items", false},
+		{"INSERT INTO items (name) VALUES ('a') RETURNING id", true},
+		{"update items set price = 0 returning *", true},
+		{"UPDATE items SET name = 'returning'", false},
+		{"DELETE FROM \"returning\"", false},
+		{"DELETE FROM items -- RETURNING id", false},
+		{"CREATE TABLE t (id INTEGER)", false},
+		{"-- only a comment", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, returnsRows(tt.query), tt.query)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	workdir := t.TempDir()
+	ctx := plugin.WithExecution(context.Background(), &plugin.Execution{WorkDir: workdir})
+
+	plan, err := New().Plan(ctx, map[string]interface{}{
+		"dsn":        "/var/lib/app/app.db",
+		"read_only":  true,
+		"statements": []interface{}{"SELECT 1", map[string]interface{}{"sql": "SELECT 2", "expect_rows": 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"dsn":         "/var/lib/app/app.db?_pragma=query_only(1)",
+		"read_only":   true,
+		"statements":  []string{"SELECT 1", "SELECT 2"},
+		"results_dir": filepath.Join(workdir, resultsDir),
+		"formats":     []string{"csv", "json"},
+	}, plan.Details)
+}
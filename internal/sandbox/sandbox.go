@@ -0,0 +1,63 @@
+/*
+Package sandbox isolates the processes started for a job.
+
+On Linux a sandboxed process runs in new user, PID, mount and network
+namespaces when unprivileged user namespaces are available, and landlock
+limits its filesystem access to the run's workspace plus read-only system
+directories. Each mechanism is applied independently: when the kernel lacks
+support for one of them the process still starts, the missing protection
+is logged as a warning and listed in the recorded Profile.
+
+The same mechanism applies resource limits (see package limits) with
+setrlimit, whether or not the process is isolated.
+
+Isolation and limits are applied by re-executing the current binary as a
+small shim that enters the sandbox and then execs the real command, so a
+process is never started half-restricted.
+*/
+package sandbox
+
+// Modes recorded in Profile.Mode
+const (
+	ModeNone               = "none"
+	ModeNamespaces         = "namespaces"
+	ModeLandlock           = "landlock"
+	ModeNamespacesLandlock = "namespaces+landlock"
+)
+
+// DefaultReadOnly lists the system directories a sandboxed process may read
+// and execute from
+var DefaultReadOnly = []string{"/bin", "/sbin", "/usr", "/lib", "/lib32", "/lib64", "/etc", "/opt", "/proc"}
+
+// Policy describes the isolation requested for a job's processes
+type Policy struct {
+	// Writable lists directories the process may read and modify,
+	// typically the run workspace
+	Writable []string
+	// ReadOnly lists directories readable in addition to DefaultReadOnly
+	ReadOnly []string
+}
+
+// Profile records the isolation actually applied to a run
+type Profile struct {
+	Mode        string   `json:"mode"`
+	Namespaces  []string `json:"namespaces,omitempty"`
+	LandlockABI int      `json:"landlock_abi,omitempty"`
+	Writable    []string `json:"writable,omitempty"`
+	ReadOnly    []string `json:"read_only,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// mode derives the profile mode from the applied mechanisms
+func (p Profile) mode() string {
+	switch {
+	case len(p.Namespaces) > 0 && p.LandlockABI > 0:
+		return ModeNamespacesLandlock
+	case len(p.Namespaces) > 0:
+		return ModeNamespaces
+	case p.LandlockABI > 0:
+		return ModeLandlock
+	default:
+		return ModeNone
+	}
+}
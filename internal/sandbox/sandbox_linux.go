@@ -0,0 +1,378 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// envShimSpec carries the shimSpec of a sandboxed process
+	envShimSpec = "GOPHER_TOWER_SANDBOX"
+	// envShimProbe makes the shim check that it can set up its namespaces
+	// and exit; used to detect support
+	envShimProbe = "GOPHER_TOWER_SANDBOX_PROBE"
+	shimArg0     = "gopher-tower-sandbox"
+	// shimExitCode is returned when the sandbox cannot be entered,
+	// mirroring the shell's "cannot execute" status
+	shimExitCode = 126
+)
+
+const cloneFlags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWPID | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET
+
+// namespaceNames lists the namespaces created by cloneFlags
+var namespaceNames = []string{"user", "pid", "mount", "network"}
+
+// Landlock access rights by the ABI version that introduced them
+const (
+	readOnlyAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR
+	fileAccess     = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE | unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+	abi1Access = readOnlyAccess | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG | unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_FIFO | unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM
+	// deviceAccess allows using, but not creating, device nodes such as /dev/null
+	deviceAccess = unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR | unix.LANDLOCK_ACCESS_FS_TRUNCATE | unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+)
+
+// devicePaths are writable but restricted to deviceAccess
+var devicePaths = []string{"/dev"}
+
+// handledAccess returns the filesystem rights known to a landlock ABI
+func handledAccess(abi int) uint64 {
+	access := uint64(abi1Access)
+	if abi >= 2 {
+		access |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi >= 3 {
+		access |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	if abi >= 5 {
+		access |= unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+	}
+	return access
+}
+
+func init() {
+	if os.Getenv(envShimProbe) != "" {
+		if err := mountProc(); err != nil {
+			shimFail(err)
+		}
+		os.Exit(0)
+	}
+	if spec := os.Getenv(envShimSpec); spec != "" {
+		runShim(spec)
+	}
+}
+
+// support describes the sandboxing features of the running kernel
+type support struct {
+	namespaces  error
+	landlockABI int
+	landlock    error
+}
+
+var (
+	detectOnce sync.Once
+	detected   support
+)
+
+// detect probes the kernel once and logs a warning for every missing
+// feature
+func detect() support {
+	detectOnce.Do(func() {
+		detected.landlockABI, detected.landlock = landlockABI()
+		if detected.landlock != nil {
+			log.Printf("Warning: sandbox: landlock unavailable (%v); filesystem access of sandboxed jobs will not be restricted", detected.landlock)
+		}
+		detected.namespaces = probeNamespaces()
+		if detected.namespaces != nil {
+			log.Printf("Warning: sandbox: user namespaces unavailable (%v); sandboxed jobs will share the host's PID, mount and network namespaces", detected.namespaces)
+		}
+	})
+	return detected
+}
+
+func landlockABI() (int, error) {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0, errno
+	}
+	if abi < 1 {
+		return 0, errors.New("unknown landlock ABI")
+	}
+	return int(abi), nil
+}
+
+// probeNamespaces starts the shim in probe mode inside new namespaces
+func probeNamespaces() error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(self)
+	cmd.Args = []string{shimArg0}
+	cmd.Env = []string{envShimProbe + "=1"}
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+	setNamespaces(cmd.SysProcAttr)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return errors.New(strings.TrimSpace(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+func setNamespaces(attr *syscall.SysProcAttr) {
+	attr.Cloneflags |= cloneFlags
+	// Map the server's user to root inside the namespace so the shim may
+	// mount a fresh /proc; outside it still has no extra privileges.
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+	attr.GidMappingsEnableSetgroups = false
+}
+
+// Plan returns the profile Prepare will apply for a policy on this host
+func Plan(policy Policy) Profile {
+	s := detect()
+	p := Profile{
+		Writable: policy.Writable,
+		ReadOnly: append(append([]string{}, DefaultReadOnly...), policy.ReadOnly...),
+	}
+	if s.namespaces == nil {
+		p.Namespaces = namespaceNames
+	} else {
+		p.Warnings = append(p.Warnings, fmt.Sprintf("namespaces not applied: %v", s.namespaces))
+	}
+	if s.landlock == nil {
+		p.LandlockABI = s.landlockABI
+	} else {
+		p.Warnings = append(p.Warnings, fmt.Sprintf("landlock not applied: %v", s.landlock))
+	}
+	p.Mode = p.mode()
+	return p
+}
+
+// shimSpec tells the shim how to enter the sandbox and what to run
+type shimSpec struct {
+	Path        string      `json:"path"`
+	Args        []string    `json:"args"`
+	Namespaces  bool        `json:"namespaces,omitempty"`
+	LandlockABI int         `json:"landlock_abi,omitempty"`
+	Writable    []string    `json:"writable,omitempty"`
+	ReadOnly    []string    `json:"read_only,omitempty"`
+	Rlimits     []shimLimit `json:"rlimits,omitempty"`
+}
+
+// shimLimit is a resource limit set by the shim before exec
+type shimLimit struct {
+	Resource int    `json:"resource"`
+	Cur      uint64 `json:"cur"`
+	Max      uint64 `json:"max"`
+}
+
+// rlimits converts limits to the setrlimit calls made by the shim
+func rlimits(l limits.Limits) []shimLimit {
+	var result []shimLimit
+	add := func(resource int, cur, max int64) {
+		if cur > 0 {
+			result = append(result, shimLimit{Resource: resource, Cur: uint64(cur), Max: uint64(max)})
+		}
+	}
+	// The soft CPU limit sends SIGXCPU; the hard limit a second later kills
+	add(unix.RLIMIT_CPU, l.CPUSeconds, l.CPUSeconds+1)
+	add(unix.RLIMIT_AS, l.MemoryBytes, l.MemoryBytes)
+	add(unix.RLIMIT_NOFILE, l.OpenFiles, l.OpenFiles)
+	add(unix.RLIMIT_NPROC, l.Processes, l.Processes)
+	add(unix.RLIMIT_FSIZE, l.FileSizeBytes, l.FileSizeBytes)
+	return result
+}
+
+// Prepare rewrites cmd so that it runs inside the sandbox described by
+// policy, if not nil, and under the setrlimit limits in rl. It returns the
+// applied sandbox profile, or nil without a policy. Prepare must be called
+// after cmd's Path, Args and Env are final and before it is started.
+func Prepare(cmd *exec.Cmd, policy *Policy, rl limits.Limits) (*Profile, error) {
+	spec := shimSpec{Path: cmd.Path, Args: cmd.Args, Rlimits: rlimits(rl)}
+	var profile *Profile
+	if policy != nil {
+		p := Plan(*policy)
+		profile = &p
+		spec.Namespaces = len(p.Namespaces) > 0
+		spec.LandlockABI = p.LandlockABI
+		spec.Writable = p.Writable
+		// The command's own directory must stay executable
+		spec.ReadOnly = append(append([]string{}, p.ReadOnly...), filepath.Dir(cmd.Path))
+	}
+	if !spec.Namespaces && spec.LandlockABI == 0 && len(spec.Rlimits) == 0 {
+		return profile, nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return profile, fmt.Errorf("failed to locate sandbox shim: %w", err)
+	}
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return profile, err
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env, envShimSpec+"="+string(encoded))
+	cmd.Path = self
+	cmd.Args = []string{shimArg0}
+	if spec.Namespaces {
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		setNamespaces(cmd.SysProcAttr)
+	}
+	return profile, nil
+}
+
+// runShim enters the sandbox described by the encoded spec and replaces
+// the process with the sandboxed command. It never returns.
+func runShim(encoded string) {
+	// Landlock and no_new_privs apply to the calling thread, which must be
+	// the one calling execve
+	runtime.LockOSThread()
+
+	var spec shimSpec
+	if err := json.Unmarshal([]byte(encoded), &spec); err != nil {
+		shimFail(fmt.Errorf("invalid sandbox spec: %w", err))
+	}
+	os.Unsetenv(envShimSpec)
+
+	if spec.Namespaces {
+		if err := mountProc(); err != nil {
+			shimFail(err)
+		}
+	}
+	for _, l := range spec.Rlimits {
+		if err := setRlimit(l); err != nil {
+			shimFail(err)
+		}
+	}
+	if spec.LandlockABI > 0 {
+		if err := restrictFilesystem(spec); err != nil {
+			shimFail(err)
+		}
+	}
+	shimFail(syscall.Exec(spec.Path, spec.Args, os.Environ()))
+}
+
+func shimFail(err error) {
+	fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+	os.Exit(shimExitCode)
+}
+
+// setRlimit lowers a resource limit, never raising it above the current
+// hard limit
+func setRlimit(l shimLimit) error {
+	var current unix.Rlimit
+	if err := unix.Getrlimit(l.Resource, &current); err != nil {
+		return fmt.Errorf("failed to read resource limit %d: %w", l.Resource, err)
+	}
+	limit := unix.Rlimit{Cur: min(l.Cur, current.Max), Max: min(l.Max, current.Max)}
+	if err := unix.Setrlimit(l.Resource, &limit); err != nil {
+		return fmt.Errorf("failed to set resource limit %d: %w", l.Resource, err)
+	}
+	return nil
+}
+
+// mountProc replaces /proc with one matching the new PID namespace
+func mountProc() error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	if err := unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("failed to mount /proc: %w", err)
+	}
+	return nil
+}
+
+// restrictFilesystem applies a landlock ruleset allowing only the spec's
+// paths to the current thread
+func restrictFilesystem(spec shimSpec) error {
+	handled := handledAccess(spec.LandlockABI)
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to create landlock ruleset: %w", errno)
+	}
+	ruleset := int(fd)
+	defer unix.Close(ruleset)
+
+	rules := []struct {
+		paths  []string
+		access uint64
+	}{
+		{spec.ReadOnly, readOnlyAccess},
+		{devicePaths, deviceAccess},
+		{spec.Writable, handled},
+	}
+	for _, rule := range rules {
+		for _, path := range rule.paths {
+			if err := addPathRule(ruleset, path, rule.access&handled); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(ruleset), 0, 0); errno != 0 {
+		return fmt.Errorf("failed to enforce landlock ruleset: %w", errno)
+	}
+	return nil
+}
+
+// addPathRule grants access beneath path; missing paths are skipped
+func addPathRule(ruleset int, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer unix.Close(fd)
+
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+		// Directory rights are rejected on files
+		access &= fileAccess
+	}
+
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH,
+		uintptr(unsafe.Pointer(&rule)), 0, 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to add landlock rule for %s: %w", path, errno)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package sandbox
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run starts sh -c script inside a sandbox allowing writes to dir only
+func run(t *testing.T, dir, script string) (*Profile, string, error) {
+	t.Helper()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	cmd := exec.Command(sh, "-c", script)
+	cmd.Dir = dir
+	profile, err := Prepare(cmd, &Policy{Writable: []string{dir}}, limits.Limits{})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	return profile, out.String(), err
+}
+
+func TestPlan(t *testing.T) {
+	profile := Plan(Policy{Writable: []string{"/work"}, ReadOnly: []string{"/data"}})
+	assert.Equal(t, []string{"/work"}, profile.Writable)
+	assert.Contains(t, profile.ReadOnly, "/usr")
+	assert.Contains(t, profile.ReadOnly, "/data")
+	assert.Equal(t, profile.mode(), profile.Mode)
+
+	s := detect()
+	if s.namespaces != nil || s.landlock != nil {
+		assert.NotEmpty(t, profile.Warnings, "missing features must be reported")
+	}
+}
+
+func TestLandlock(t *testing.T) {
+	if detect().landlock != nil {
+		t.Skipf("landlock unavailable: %v", detect().landlock)
+	}
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	profile, out, err := run(t, dir, "echo ok > inside.txt && cat inside.txt")
+	require.NoError(t, err, out)
+	assert.Equal(t, "ok\n", out)
+	assert.Positive(t, profile.LandlockABI)
+
+	_, out, err = run(t, dir, "echo nope > "+filepath.Join(outside, "outside.txt"))
+	assert.Error(t, err)
+	assert.Contains(t, strings.ToLower(out), "permission denied")
+	assert.NoFileExists(t, filepath.Join(outside, "outside.txt"))
+
+	_, out, err = run(t, dir, "echo ok > /dev/null && cat /etc/hostname >/dev/null || true")
+	assert.NoError(t, err, out)
+}
+
+func TestNamespaces(t *testing.T) {
+	if err := detect().namespaces; err != nil {
+		t.Skipf("namespaces unavailable: %v", err)
+	}
+	dir := t.TempDir()
+
+	profile, out, err := run(t, dir, "echo $$; ls /proc | grep -c '^[0-9]'")
+	require.NoError(t, err, out)
+	assert.Contains(t, profile.Namespaces, "pid")
+	lines := strings.Fields(out)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "1", lines[0], "process must be PID 1 of its namespace")
+	visible, err := strconv.Atoi(lines[1])
+	require.NoError(t, err)
+	assert.LessOrEqual(t, visible, 3, "only the shell and its pipeline must be visible")
+}
+
+func TestPrepareKeepsEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	cmd := exec.Command(sh, "-c", "echo \"$GREETING ${"+envShimSpec+":-unset}\"")
+	cmd.Env = append(os.Environ(), "GREETING=hello")
+	_, err = Prepare(cmd, &Policy{Writable: []string{dir}}, limits.Limits{})
+	require.NoError(t, err)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "hello unset\n", string(out))
+}
+
+func TestRlimits(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	cmd := exec.Command(sh, "-c", "ulimit -n; ulimit -t")
+	profile, err := Prepare(cmd, nil, limits.Limits{OpenFiles: 64, CPUSeconds: 5})
+	require.NoError(t, err)
+	assert.Nil(t, profile, "limits alone do not isolate")
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "64\n5\n", string(out))
+
+	untouched := exec.Command(sh, "-c", "true")
+	_, err = Prepare(untouched, nil, limits.Limits{Timeout: limits.Duration(time.Second)})
+	require.NoError(t, err)
+	assert.Equal(t, sh, untouched.Path, "commands without rlimits run directly")
+}
@@ -0,0 +1,39 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"log"
+	"os/exec"
+	"sync"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+)
+
+var warnOnce, limitsOnce sync.Once
+
+// Plan returns the profile Prepare will apply for a policy on this host
+func Plan(policy Policy) Profile {
+	warnOnce.Do(func() {
+		log.Printf("Warning: sandbox: sandboxing is only supported on Linux; sandboxed jobs run unrestricted")
+	})
+	return Profile{
+		Mode:     ModeNone,
+		Warnings: []string{"sandboxing is only supported on Linux"},
+	}
+}
+
+// Prepare leaves cmd unchanged; sandboxing and resource limits are only
+// supported on Linux
+func Prepare(cmd *exec.Cmd, policy *Policy, rl limits.Limits) (*Profile, error) {
+	if rl.HasRlimits() {
+		limitsOnce.Do(func() {
+			log.Printf("Warning: sandbox: resource limits are only enforced on Linux")
+		})
+	}
+	if policy == nil {
+		return nil, nil
+	}
+	p := Plan(*policy)
+	return &p, nil
+}
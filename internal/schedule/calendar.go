@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/limits"
+)
+
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+// Calendar is a set of blackout windows. Fire times of schedules naming the
+// calendar are skipped while they fall into one of its windows.
+type Calendar struct {
+	// Timezone is the IANA time zone the windows are evaluated in; UTC
+	// when empty
+	Timezone string   `json:"timezone,omitempty"`
+	Windows  []Window `json:"windows"`
+}
+
+// Window is a blackout window: either a one-off range of days from From to
+// To, both included, or a recurring window starting at the fire times of
+// Cron and lasting Duration
+type Window struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	Cron     string          `json:"cron,omitempty"`
+	Duration limits.Duration `json:"duration,omitempty"`
+}
+
+// Validate checks the calendar
+func (c *Calendar) Validate() error {
+	if _, err := loadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	if len(c.Windows) == 0 {
+		return fmt.Errorf("%w: windows are required", ErrInvalidCalendar)
+	}
+	for i, w := range c.Windows {
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("%w: windows[%d]: %v", ErrInvalidCalendar, i, err)
+		}
+	}
+	return nil
+}
+
+func (w *Window) validate() error {
+	switch {
+	case w.Cron != "":
+		if w.From != "" || w.To != "" {
+			return errors.New("from and to cannot be combined with cron")
+		}
+		if _, err := ParseCron(w.Cron); err != nil {
+			return err
+		}
+		if w.Duration <= 0 {
+			return errors.New("duration must be positive")
+		}
+	case w.From != "" || w.To != "":
+		if w.Duration != 0 {
+			return errors.New("duration requires cron")
+		}
+		from, err := time.Parse(time.DateOnly, w.From)
+		if err != nil {
+			return fmt.Errorf("from must be a date (%s)", time.DateOnly)
+		}
+		to, err := time.Parse(time.DateOnly, w.To)
+		if err != nil {
+			return fmt.Errorf("to must be a date (%s)", time.DateOnly)
+		}
+		if to.Before(from) {
+			return errors.New("to is before from")
+		}
+	default:
+		return errors.New("either from and to or cron is required")
+	}
+	return nil
+}
+
+// Blocks reports whether t falls into one of the calendar's windows. The
+// calendar must be valid.
+func (c *Calendar) Blocks(t time.Time) bool {
+	loc, err := loadLocation(c.Timezone)
+	if err != nil {
+		return false
+	}
+	t = t.In(loc)
+	day := t.Format(time.DateOnly)
+	for _, w := range c.Windows {
+		if w.Cron == "" {
+			// Dates in the same format compare like strings
+			if w.From <= day && day <= w.To {
+				return true
+			}
+			continue
+		}
+		cron, err := ParseCron(w.Cron)
+		if err != nil {
+			continue
+		}
+		// t is blocked by a window starting within Duration before it
+		if start := cron.Next(t.Add(-time.Duration(w.Duration))); !start.IsZero() && !start.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarQuerier is the subset of db.Queries LoadCalendars uses
+type CalendarQuerier interface {
+	ListCalendars(ctx context.Context) ([]db.Calendar, error)
+}
+
+// LoadCalendars returns the stored calendars by name. Invalid calendars
+// are logged and left out.
+func LoadCalendars(ctx context.Context, queries CalendarQuerier) (map[string]*Calendar, error) {
+	rows, err := queries.ListCalendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	calendars := make(map[string]*Calendar, len(rows))
+	for _, row := range rows {
+		c, err := DecodeCalendar(row.Timezone.String, []byte(row.Windows))
+		if err != nil {
+			log.Printf("Calendar %s: %v", row.Name, err)
+			continue
+		}
+		calendars[row.Name] = c
+	}
+	return calendars, nil
+}
+
+// DecodeCalendar parses and validates a stored calendar
+func DecodeCalendar(timezone string, windows []byte) (*Calendar, error) {
+	c := Calendar{Timezone: timezone}
+	if err := json.Unmarshal(windows, &c.Windows); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarValidate(t *testing.T) {
+	valid := []Calendar{
+		{Windows: []Window{{From: "2025-12-20", To: "2026-01-02"}}},
+		{Timezone: "Europe/Berlin", Windows: []Window{
+			{From: "2025-12-24", To: "2025-12-24"},
+			{Cron: "0 18 * * fri", Duration: limits.Duration(62 * time.Hour)},
+		}},
+	}
+	for _, c := range valid {
+		assert.NoError(t, c.Validate(), c)
+	}
+
+	invalid := map[string]Calendar{
+		"no windows":            {},
+		"unknown time zone":     {Timezone: "Mars/Olympus", Windows: []Window{{From: "2025-12-20", To: "2025-12-21"}}},
+		"empty window":          {Windows: []Window{{}}},
+		"missing to":            {Windows: []Window{{From: "2025-12-20"}}},
+		"not a date":            {Windows: []Window{{From: "2025-12-20T00:00:00Z", To: "2025-12-21"}}},
+		"reversed range":        {Windows: []Window{{From: "2025-12-21", To: "2025-12-20"}}},
+		"range with duration":   {Windows: []Window{{From: "2025-12-20", To: "2025-12-21", Duration: limits.Duration(time.Hour)}}},
+		"cron without duration": {Windows: []Window{{Cron: "0 18 * * fri"}}},
+		"cron with range":       {Windows: []Window{{Cron: "0 18 * * fri", Duration: limits.Duration(time.Hour), From: "2025-12-20", To: "2025-12-21"}}},
+		"bad cron":              {Windows: []Window{{Cron: "0 18 * *", Duration: limits.Duration(time.Hour)}}},
+	}
+	for name, c := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, c.Validate(), ErrInvalidCalendar)
+		})
+	}
+}
+
+func TestCalendarBlocks(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation(time.DateTime, s, berlin)
+		require.NoError(t, err)
+		return v
+	}
+	c, err := DecodeCalendar("Europe/Berlin", []byte(`[
+		{"from": "2025-12-20", "to": "2026-01-02"},
+		{"cron": "0 18 * * fri", "duration": "62h"}
+	]`))
+	require.NoError(t, err)
+
+	// The one-off window covers both of its days in Berlin
+	assert.True(t, c.Blocks(at("2025-12-20 00:00:00")))
+	assert.True(t, c.Blocks(at("2026-01-02 23:59:00")))
+	// January 2, 2026 is a Friday, so the weekend window follows
+	assert.True(t, c.Blocks(at("2026-01-03 00:00:00")))
+	assert.False(t, c.Blocks(at("2026-01-05 12:00:00")))
+	// 23:30 UTC on December 19 is already December 20 in Berlin
+	assert.True(t, c.Blocks(time.Date(2025, 12, 19, 23, 30, 0, 0, time.UTC)))
+
+	// The weekend window runs from Friday 18:00 to Monday 08:00
+	assert.False(t, c.Blocks(at("2025-06-06 17:59:00")))
+	assert.True(t, c.Blocks(at("2025-06-06 18:00:00")))
+	assert.True(t, c.Blocks(at("2025-06-08 12:00:00")))
+	assert.True(t, c.Blocks(at("2025-06-09 07:59:00")))
+	assert.False(t, c.Blocks(at("2025-06-09 08:00:00")))
+
+	_, err = DecodeCalendar("", []byte(`{"from": "2025-12-20"}`))
+	assert.ErrorIs(t, err, ErrInvalidCalendar)
+}
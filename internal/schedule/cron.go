@@ -0,0 +1,248 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchYears bounds the search for the next fire time, so expressions
+// that never match, like "0 0 30 2 *", end the search
+const searchYears = 5
+
+// Cron is a parsed cron expression with the five standard fields: minute,
+// hour, day of month, month and day of week
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day fields were "*". When
+	// both are restricted, a day matches if either of them matches.
+	domStar, dowStar bool
+}
+
+// field describes the range and names of one cron field
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// macros are the shorthands accepted in place of the five fields
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression: five fields separated by spaces,
+// each "*", a value, a range "a-b" or a comma-separated list of them,
+// optionally with a step ("*/15", "1-10/2"). Months and days of the week
+// may be given by their three-letter English names. The macros @yearly,
+// @monthly, @weekly, @daily and @hourly are accepted as well.
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron expression %q must have 5 fields", ErrInvalidSchedule, expr)
+	}
+
+	var (
+		c   Cron
+		err error
+	)
+	for _, f := range []struct {
+		dst  *uint64
+		spec string
+		def  field
+	}{
+		{&c.minute, fields[0], minuteField},
+		{&c.hour, fields[1], hourField},
+		{&c.dom, fields[2], domField},
+		{&c.month, fields[3], monthField},
+		{&c.dow, fields[4], dowField},
+	} {
+		if *f.dst, err = parseField(f.spec, f.def); err != nil {
+			return nil, err
+		}
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	return &c, nil
+}
+
+// parseField parses one field into a bit set of the values it matches
+func parseField(spec string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(spec, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%w: %s range %q is reversed", ErrInvalidSchedule, f.name, rng)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			// "5/15" runs from 5 to the end of the range
+			hi = lo
+			if hasStep {
+				hi = f.max
+			}
+		}
+
+		n := 1
+		if hasStep {
+			var err error
+			if n, err = strconv.Atoi(step); err != nil || n < 1 {
+				return 0, fmt.Errorf("%w: invalid %s step %q", ErrInvalidSchedule, f.name, step)
+			}
+		}
+		for v := lo; v <= hi; v += n {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a number or name of a field
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: invalid %s %q", ErrInvalidSchedule, f.name, s)
+	}
+	return v, nil
+}
+
+// matchesDay reports whether the date of t matches the day fields
+func (c *Cron) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dow
+	case c.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first time after t matching the expression on the wall
+// clock of t's location, or the zero time when there is none within the
+// next few years. Across daylight saving time changes, wall clock times
+// skipped when clocks go forward fire once, at the end of the gap, and wall
+// clock times repeated when clocks go back fire once, at their first
+// occurrence.
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	wall := wallClock(t)
+	for {
+		if wall = c.nextWall(wall); wall.IsZero() {
+			return time.Time{}
+		}
+		if at := resolve(wall, loc); at.After(t) {
+			return at
+		}
+	}
+}
+
+// nextWall returns the first wall clock time after wall matching the
+// expression. Wall clock times are carried as UTC times, which have no
+// gaps or repeats.
+func (c *Cron) nextWall(wall time.Time) time.Time {
+	t := wall.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// wallClock returns the wall clock time of t as a UTC time
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// resolve returns the first instant at which the clock in loc shows wall,
+// or the end of the gap when the clock skips it
+func resolve(wall time.Time, loc *time.Location) time.Time {
+	// The offsets in effect a day before and after cover any transition
+	// around wall
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(24 * time.Hour).In(loc).Zone()
+	early, late := wall.Add(-time.Duration(before)*time.Second), wall.Add(-time.Duration(after)*time.Second)
+	if late.Before(early) {
+		early, late = late, early
+	}
+	for _, at := range []time.Time{early, late} {
+		if wallClock(at.In(loc)).Equal(wall) {
+			return at.In(loc)
+		}
+	}
+
+	// wall falls into a gap, which ends when the offset of the earlier
+	// candidate gives way to the one of the later candidate
+	_, gapOffset := early.In(loc).Zone()
+	lo, hi := early.Unix(), late.Unix()
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if _, offset := time.Unix(mid, 0).In(loc).Zone(); offset == gapOffset {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return time.Unix(hi, 0).In(loc)
+}
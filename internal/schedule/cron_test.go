@@ -0,0 +1,113 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 0-6,22,23 1-31/2 jan-jun mon-fri",
+		"5/10 * * * SUN",
+		"0 0 * * 7",
+		"@daily",
+		"@Hourly",
+	}
+	for _, expr := range valid {
+		_, err := ParseCron(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	invalid := map[string]string{
+		"empty":          "",
+		"too few fields": "* * * *",
+		"too many":       "* * * * * *",
+		"out of range":   "60 * * * *",
+		"zero day":       "0 0 0 * *",
+		"reversed range": "0 5-1 * * *",
+		"zero step":      "*/0 * * * *",
+		"bad name":       "0 0 * foo *",
+		"unknown macro":  "@reboot",
+	}
+	for name, expr := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseCron(expr)
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return v
+	}
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"* * * * *", "2025-06-01T12:00:30Z", "2025-06-01T12:01:00Z"},
+		{"*/15 * * * *", "2025-06-01T12:00:00Z", "2025-06-01T12:15:00Z"},
+		{"0 2 * * *", "2025-06-01T02:00:00Z", "2025-06-02T02:00:00Z"},
+		{"30 9 * * mon-fri", "2025-06-06T10:00:00Z", "2025-06-09T09:30:00Z"},
+		{"0 0 31 * *", "2025-04-01T00:00:00Z", "2025-05-31T00:00:00Z"},
+		{"0 0 29 2 *", "2025-01-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"@yearly", "2025-06-01T00:00:00Z", "2026-01-01T00:00:00Z"},
+		// Sunday as 7
+		{"0 0 * * 7", "2025-06-02T00:00:00Z", "2025-06-08T00:00:00Z"},
+		// Restricted day of month and day of week match either
+		{"0 0 13 * fri", "2025-06-01T00:00:00Z", "2025-06-06T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, at(tt.want), c.Next(at(tt.after)), tt.expr)
+	}
+
+	never, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(at("2025-01-01T00:00:00Z")).IsZero())
+}
+
+func TestCronNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	local := func(day, clock string, offset int) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.DateTime, day+" "+clock)
+		require.NoError(t, err)
+		return v.Add(-time.Duration(offset) * time.Hour)
+	}
+	next := func(expr string, after time.Time) time.Time {
+		t.Helper()
+		c, err := ParseCron(expr)
+		require.NoError(t, err)
+		at := c.Next(after.In(ny))
+		assert.Equal(t, ny, at.Location())
+		return at
+	}
+
+	// Clocks go forward from 02:00 EST (-5) to 03:00 EDT (-4) on March 9,
+	// 2025: a daily run at 09:00 keeps its wall clock time
+	assert.True(t, local("2025-03-09", "09:00:00", -4).Equal(next("0 9 * * *", local("2025-03-08", "09:00:00", -5))))
+	// Times in the gap fire once, when it ends
+	assert.True(t, local("2025-03-09", "03:00:00", -4).Equal(next("30 2 * * *", local("2025-03-08", "02:30:00", -5))))
+	assert.True(t, local("2025-03-09", "03:00:00", -4).Equal(next("*/15 * * * *", local("2025-03-09", "01:45:00", -5))))
+	assert.True(t, local("2025-03-09", "03:15:00", -4).Equal(next("*/15 * * * *", local("2025-03-09", "03:00:00", -4))))
+	assert.True(t, local("2025-03-10", "02:30:00", -4).Equal(next("30 2 * * *", local("2025-03-09", "03:00:00", -4))))
+
+	// Clocks go back from 02:00 EDT (-4) to 01:00 EST (-5) on November 2,
+	// 2025: repeated times fire the first time around only
+	assert.True(t, local("2025-11-02", "01:30:00", -4).Equal(next("30 1 * * *", local("2025-11-01", "01:30:00", -4))))
+	assert.True(t, local("2025-11-03", "01:30:00", -5).Equal(next("30 1 * * *", local("2025-11-02", "01:30:00", -4))))
+	assert.True(t, local("2025-11-02", "02:00:00", -5).Equal(next("0 * * * *", local("2025-11-02", "01:00:00", -4))))
+	// An hourly schedule running in the repeated hour waits for 02:00
+	assert.True(t, local("2025-11-02", "02:00:00", -5).Equal(next("0 * * * *", local("2025-11-02", "01:00:00", -5))))
+}
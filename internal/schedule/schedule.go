@@ -0,0 +1,250 @@
+/*
+Package schedule starts runs of jobs at the fire times of a cron schedule.
+
+A job's schedule is a cron expression with an overlap policy and a catch-up
+policy:
+
+	{"cron": "0,30 9-17 * * 1-5", "timezone": "Europe/Berlin", "overlap": "skip", "catch_up": "latest"}
+
+Cron expressions have the five standard fields (minute, hour, day of month,
+month, day of week) and are evaluated on the wall clock of the schedule's
+IANA time zone, UTC by default. When clocks go forward, the wall clock
+times skipped fire once, when the new time starts; when clocks go back, the
+wall clock times repeated fire once, the first time around.
+
+A schedule may name blackout calendars (see Calendar). Its fire times are
+skipped while they fall into a window of one of them, such as a change
+freeze over the holidays or every weekend.
+
+The overlap policy decides what happens when a fire time is reached while
+a run of the job is still in flight:
+
+  - allow (the default) starts another run next to it;
+  - skip records the fire time as a skipped run;
+  - queue holds the fire time back and starts its run once the run in
+    flight finished. Only one fire time is held back; further ones are
+    skipped while it waits.
+  - replace cancels the run in flight and starts a new one.
+
+The catch-up policy decides what happens to fire times missed while no
+scheduler was running, e.g. because the server was down:
+
+  - none (the default) records them as skipped runs;
+  - latest starts one run for the most recent missed fire time and records
+    the earlier ones as skipped;
+  - all starts a run for every missed fire time within catch_up_window of
+    the current time and records the earlier ones as skipped. It requires
+    the allow overlap policy, since the others would drop or cancel all
+    but one of the runs.
+
+A fire time counts as missed when it is handled more than MissedAfter late.
+Skipped fire times are recorded in the job's run history as runs with the
+status "skipped" and the failure reason "blackout", "overlap" or "missed";
+runs started for missed fire times are marked as caught up. At most
+MaxCatchUp missed fire times are considered; the oldest ones beyond that
+are dropped and recorded as a single skipped run whose dropped_fire_times
+counts them.
+
+The last fire time handled is stored with the job, so fire times missed
+while the server was down are found after a restart, and several servers
+sharing a database handle each fire time once. Changing a job's schedule
+starts over from the time of the change.
+*/
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	// Schedules may name any IANA time zone, even on hosts without a zone
+	// database
+	_ "time/tzdata"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Overlap policies
+const (
+	OverlapAllow   = "allow"
+	OverlapSkip    = "skip"
+	OverlapQueue   = "queue"
+	OverlapReplace = "replace"
+)
+
+// Catch-up policies
+const (
+	CatchUpNone   = "none"
+	CatchUpLatest = "latest"
+	CatchUpAll    = "all"
+)
+
+// Failure reasons of skipped runs
+const (
+	// ReasonBlackout marks fire times within a window of one of the
+	// schedule's calendars
+	ReasonBlackout = "blackout"
+	// ReasonOverlap marks fire times skipped because a run of the job was
+	// in flight
+	ReasonOverlap = "overlap"
+	// ReasonMissed marks fire times missed while no scheduler was running
+	// that were not caught up
+	ReasonMissed = "missed"
+)
+
+// Schedule is the cron schedule of a job
+type Schedule struct {
+	// Cron is the cron expression of the fire times
+	Cron string `json:"cron"`
+	// Timezone is the IANA time zone Cron is evaluated in; UTC when empty
+	Timezone string `json:"timezone,omitempty"`
+	// Calendars name the blackout calendars suppressing fire times
+	Calendars []string `json:"calendars,omitempty"`
+	// Overlap is the overlap policy; OverlapAllow when empty
+	Overlap string `json:"overlap,omitempty"`
+	// CatchUp is the catch-up policy; CatchUpNone when empty
+	CatchUp string `json:"catch_up,omitempty"`
+	// CatchUpWindow is how far back missed fire times are caught up with
+	// the CatchUpAll policy
+	CatchUpWindow limits.Duration `json:"catch_up_window,omitempty"`
+}
+
+// Validate checks the schedule
+func (s *Schedule) Validate() error {
+	if s.Cron == "" {
+		return fmt.Errorf("%w: cron is required", ErrInvalidSchedule)
+	}
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return err
+	}
+	loc, err := loadLocation(s.Timezone)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	if c.Next(time.Now().In(loc)).IsZero() {
+		return fmt.Errorf("%w: cron expression %q never fires", ErrInvalidSchedule, s.Cron)
+	}
+	for i, name := range s.Calendars {
+		if name == "" {
+			return fmt.Errorf("%w: calendar names must not be empty", ErrInvalidSchedule)
+		}
+		if slices.Contains(s.Calendars[:i], name) {
+			return fmt.Errorf("%w: calendar %q is named twice", ErrInvalidSchedule, name)
+		}
+	}
+
+	switch s.overlap() {
+	case OverlapAllow, OverlapSkip, OverlapQueue, OverlapReplace:
+	default:
+		return fmt.Errorf("%w: unknown overlap policy %q", ErrInvalidSchedule, s.Overlap)
+	}
+	switch s.catchUp() {
+	case CatchUpNone, CatchUpLatest:
+		if s.CatchUpWindow != 0 {
+			return fmt.Errorf("%w: catch_up_window requires the %s catch-up policy", ErrInvalidSchedule, CatchUpAll)
+		}
+	case CatchUpAll:
+		if s.CatchUpWindow <= 0 {
+			return fmt.Errorf("%w: catch_up_window must be positive", ErrInvalidSchedule)
+		}
+		if s.overlap() != OverlapAllow {
+			return fmt.Errorf("%w: the %s catch-up policy requires the %s overlap policy", ErrInvalidSchedule, CatchUpAll, OverlapAllow)
+		}
+	default:
+		return fmt.Errorf("%w: unknown catch-up policy %q", ErrInvalidSchedule, s.CatchUp)
+	}
+	return nil
+}
+
+func (s *Schedule) overlap() string {
+	if s.Overlap == "" {
+		return OverlapAllow
+	}
+	return s.Overlap
+}
+
+func (s *Schedule) catchUp() string {
+	if s.CatchUp == "" {
+		return CatchUpNone
+	}
+	return s.CatchUp
+}
+
+// Next returns the first fire time after t in the schedule's time zone, or
+// the zero time when the schedule does not fire again
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := loadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	return c.Next(t.In(loc)), nil
+}
+
+// Blackout returns the first of the schedule's calendars that blocks t, or
+// "" when none does. Calendars missing from calendars block nothing.
+func (s *Schedule) Blackout(t time.Time, calendars map[string]*Calendar) string {
+	for _, name := range s.Calendars {
+		if c, ok := calendars[name]; ok && c.Blocks(t) {
+			return name
+		}
+	}
+	return ""
+}
+
+// FireTime is an upcoming fire time of a schedule
+type FireTime struct {
+	At time.Time `json:"at"`
+	// Blackout names the calendar that skips the fire time, if any
+	Blackout string `json:"blackout,omitempty"`
+}
+
+// Preview returns the next n fire times of the schedule after t, including
+// those skipped by its calendars
+func (s *Schedule) Preview(t time.Time, n int, calendars map[string]*Calendar) ([]FireTime, error) {
+	var times []FireTime
+	for len(times) < n {
+		next, err := s.Next(t)
+		if err != nil {
+			return nil, err
+		}
+		if next.IsZero() {
+			break
+		}
+		times = append(times, FireTime{At: next, Blackout: s.Blackout(next, calendars)})
+		t = next
+	}
+	return times, nil
+}
+
+// loadLocation returns the time zone named by an IANA name, or UTC for ""
+func loadLocation(name string) (*time.Location, error) {
+	switch name {
+	case "":
+		return time.UTC, nil
+	case "Local":
+		// The server's time zone is no IANA name
+		return nil, fmt.Errorf("unknown time zone %s", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// Decode parses and validates a stored schedule
+func Decode(data []byte) (*Schedule, error) {
+	var s Schedule
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleValidate(t *testing.T) {
+	valid := []Schedule{
+		{Cron: "@daily"},
+		{Cron: "*/5 * * * *", Overlap: OverlapSkip, CatchUp: CatchUpLatest},
+		{Cron: "0 * * * *", Overlap: OverlapQueue},
+		{Cron: "0 * * * *", Overlap: OverlapReplace, CatchUp: CatchUpNone},
+		{Cron: "0 * * * *", CatchUp: CatchUpAll, CatchUpWindow: limits.Duration(6 * time.Hour)},
+		{Cron: "0 9 * * mon-fri", Timezone: "America/New_York", Calendars: []string{"holidays", "freeze"}},
+	}
+	for _, sched := range valid {
+		assert.NoError(t, sched.Validate(), sched)
+	}
+
+	invalid := map[string]Schedule{
+		"no cron":                 {},
+		"bad cron":                {Cron: "0 0 * *"},
+		"never fires":             {Cron: "0 0 30 2 *"},
+		"unknown time zone":       {Cron: "@daily", Timezone: "Mars/Olympus"},
+		"server time zone":        {Cron: "@daily", Timezone: "Local"},
+		"empty calendar name":     {Cron: "@daily", Calendars: []string{""}},
+		"calendar named twice":    {Cron: "@daily", Calendars: []string{"freeze", "freeze"}},
+		"unknown overlap":         {Cron: "@daily", Overlap: "wait"},
+		"unknown catch-up":        {Cron: "@daily", CatchUp: "some"},
+		"all without window":      {Cron: "@daily", CatchUp: CatchUpAll},
+		"window without all":      {Cron: "@daily", CatchUp: CatchUpLatest, CatchUpWindow: limits.Duration(time.Hour)},
+		"all without overlapping": {Cron: "@daily", Overlap: OverlapSkip, CatchUp: CatchUpAll, CatchUpWindow: limits.Duration(time.Hour)},
+	}
+	for name, sched := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, sched.Validate(), ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	sched, err := Decode([]byte(`{"cron": "@hourly", "catch_up": "all", "catch_up_window": "2h"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Schedule{Cron: "@hourly", CatchUp: CatchUpAll, CatchUpWindow: limits.Duration(2 * time.Hour)}, sched)
+	assert.Equal(t, OverlapAllow, sched.overlap())
+
+	_, err = Decode([]byte(`{"cron": "@hourly", "catch_up_window": "soon"}`))
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = Decode([]byte(`{"overlap": "skip"}`))
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+}
+
+func TestScheduleDue(t *testing.T) {
+	sched := Schedule{Cron: "*/10 * * * *"}
+	checked := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	due, dropped, _, err := sched.due(checked, checked.Add(25*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{checked.Add(10 * time.Minute), checked.Add(20 * time.Minute)}, due)
+	assert.Zero(t, dropped)
+
+	// Fire times exactly at now are due
+	due, _, _, err = sched.due(checked, checked.Add(10*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{checked.Add(10 * time.Minute)}, due)
+
+	// Only the most recent MaxCatchUp fire times are kept
+	now := checked.Add(time.Duration(MaxCatchUp+5) * 10 * time.Minute)
+	due, dropped, lastDropped, err := sched.due(checked, now)
+	require.NoError(t, err)
+	assert.Len(t, due, MaxCatchUp)
+	assert.Equal(t, 5, dropped)
+	assert.Equal(t, checked.Add(50*time.Minute), lastDropped)
+	assert.Equal(t, now, due[len(due)-1])
+}
+
+func TestSchedulePreview(t *testing.T) {
+	sched := Schedule{Cron: "0 9 * * *", Timezone: "Europe/Berlin", Calendars: []string{"freeze", "missing"}}
+	freeze := &Calendar{Windows: []Window{{From: "2025-03-31", To: "2025-03-31"}}}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// Clocks in Berlin go forward on March 30, 2025, so 09:00 moves from
+	// 08:00 to 07:00 UTC
+	times, err := sched.Preview(time.Date(2025, 3, 29, 12, 0, 0, 0, time.UTC), 3, map[string]*Calendar{"freeze": freeze})
+	require.NoError(t, err)
+	require.Len(t, times, 3)
+	assert.Equal(t, []FireTime{
+		{At: time.Date(2025, 3, 30, 9, 0, 0, 0, berlin)},
+		{At: time.Date(2025, 3, 31, 9, 0, 0, 0, berlin), Blackout: "freeze"},
+		{At: time.Date(2025, 4, 1, 9, 0, 0, 0, berlin)},
+	}, times)
+	assert.Equal(t, 7, times[0].At.UTC().Hour())
+}
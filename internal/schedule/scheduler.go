@@ -0,0 +1,247 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+)
+
+const (
+	// DefaultInterval is how often the Scheduler checks for due fire times
+	DefaultInterval = 15 * time.Second
+	// MissedAfter is how late a fire time may be handled before it counts
+	// as missed and is subject to the catch-up policy
+	MissedAfter = time.Minute
+	// MaxCatchUp is the number of due fire times handled per job at once;
+	// older ones are dropped and recorded as a single skipped run
+	MaxCatchUp = 100
+)
+
+// Querier is the subset of db.Queries the Scheduler uses
+type Querier interface {
+	ListScheduledJobs(ctx context.Context) ([]db.ListScheduledJobsRow, error)
+	ListCalendars(ctx context.Context) ([]db.Calendar, error)
+	AdvanceJobSchedule(ctx context.Context, arg db.AdvanceJobScheduleParams) (int64, error)
+	SetJobScheduleQueued(ctx context.Context, arg db.SetJobScheduleQueuedParams) (int64, error)
+	CountActiveJobRuns(ctx context.Context, jobID string) (int64, error)
+}
+
+// Starter starts, skips and cancels runs of jobs; it is implemented by
+// executor.Executor
+type Starter interface {
+	StartRun(ctx context.Context, jobID string, opts executor.RunOptions) (db.JobRun, error)
+	SkipRun(ctx context.Context, jobID string, opts executor.RunOptions, reason string) (db.JobRun, error)
+	CancelRuns(ctx context.Context, jobID string) (int, error)
+}
+
+// Scheduler starts the runs of every job with a schedule
+type Scheduler struct {
+	queries  Querier
+	runs     Starter
+	interval time.Duration
+}
+
+// NewScheduler creates a Scheduler checking for due fire times every
+// interval, or DefaultInterval when it is zero
+func NewScheduler(queries Querier, runs Starter, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Scheduler{queries: queries, runs: runs, interval: interval}
+}
+
+// Run handles due fire times until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Tick(ctx, time.Now().UTC()); err != nil {
+			log.Printf("Schedules: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick handles the fire times of every job's schedule that are due at now
+// and starts the runs held back by the queue overlap policy whose job has
+// no run in flight anymore
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) error {
+	rows, err := s.queries.ListScheduledJobs(ctx)
+	if err != nil {
+		return err
+	}
+	calendars, err := LoadCalendars(ctx, s.queries)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := s.tick(ctx, row, calendars, now); err != nil {
+			log.Printf("Schedule of job %s: %v", row.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) tick(ctx context.Context, row db.ListScheduledJobsRow, calendars map[string]*Calendar, now time.Time) error {
+	sched, err := Decode([]byte(row.Schedule.String))
+	if err != nil {
+		return err
+	}
+	if !row.ScheduleCheckedAt.Valid {
+		// A new or changed schedule starts firing from now on
+		_, err := s.advance(ctx, row, now)
+		return err
+	}
+	if err := s.startQueued(ctx, row); err != nil {
+		return err
+	}
+
+	due, dropped, lastDropped, err := sched.due(row.ScheduleCheckedAt.Time, now)
+	if err != nil || len(due) == 0 {
+		return err
+	}
+	// Only the scheduler advancing the schedule handles its fire times
+	if ok, err := s.advance(ctx, row, now); !ok || err != nil {
+		return err
+	}
+	if dropped > 0 {
+		opts := executor.RunOptions{ScheduledFor: lastDropped, DroppedFireTimes: dropped}
+		if err := s.skip(ctx, row.ID, opts, ReasonMissed); err != nil {
+			log.Printf("Schedule of job %s: dropped %d missed fire time(s): %v", row.ID, dropped, err)
+		}
+	}
+	for _, name := range sched.Calendars {
+		if _, ok := calendars[name]; !ok {
+			log.Printf("Schedule of job %s: calendar %s does not exist", row.ID, name)
+		}
+	}
+	for i, t := range due {
+		if err := s.fire(ctx, row.ID, sched, calendars, t, now, i == len(due)-1); err != nil {
+			log.Printf("Schedule of job %s at %s: %v", row.ID, t.Format(time.RFC3339), err)
+		}
+	}
+	return nil
+}
+
+// due returns the fire times after checked up to and including now, at
+// most MaxCatchUp of them, and the number of older ones dropped along with
+// the most recent of those
+func (s *Schedule) due(checked, now time.Time) (due []time.Time, dropped int, lastDropped time.Time, err error) {
+	for t := checked; ; {
+		next, err := s.Next(t)
+		if err != nil {
+			return nil, 0, time.Time{}, err
+		}
+		if next.IsZero() || next.After(now) {
+			return due, dropped, lastDropped, nil
+		}
+		if len(due) == MaxCatchUp {
+			lastDropped = due[0]
+			due = append(due[:0], due[1:]...)
+			dropped++
+		}
+		due = append(due, next.UTC())
+		t = next
+	}
+}
+
+// advance records that the fire times of a job's schedule up to now were
+// handled. It reports false when another scheduler handled them or the
+// schedule changed since row was read.
+func (s *Scheduler) advance(ctx context.Context, row db.ListScheduledJobsRow, now time.Time) (bool, error) {
+	n, err := s.queries.AdvanceJobSchedule(ctx, db.AdvanceJobScheduleParams{
+		CheckedAt: sql.NullTime{Time: now, Valid: true},
+		ID:        row.ID,
+		Schedule:  row.Schedule,
+		Previous:  row.ScheduleCheckedAt,
+	})
+	return n > 0, err
+}
+
+// startQueued starts the run held back for a job once it has no run in
+// flight anymore
+func (s *Scheduler) startQueued(ctx context.Context, row db.ListScheduledJobsRow) error {
+	if !row.ScheduleQueuedFor.Valid {
+		return nil
+	}
+	if active, err := s.queries.CountActiveJobRuns(ctx, row.ID); err != nil || active > 0 {
+		return err
+	}
+	n, err := s.queries.SetJobScheduleQueued(ctx, db.SetJobScheduleQueuedParams{
+		ID:       row.ID,
+		Previous: row.ScheduleQueuedFor,
+	})
+	if err != nil || n == 0 {
+		return err
+	}
+	_, err = s.runs.StartRun(ctx, row.ID, executor.RunOptions{ScheduledFor: row.ScheduleQueuedFor.Time})
+	return err
+}
+
+// fire handles one due fire time of a job's schedule; last marks the most
+// recent one
+func (s *Scheduler) fire(ctx context.Context, jobID string, sched *Schedule, calendars map[string]*Calendar, t, now time.Time, last bool) error {
+	opts := executor.RunOptions{ScheduledFor: t}
+	if sched.Blackout(t, calendars) != "" {
+		return s.skip(ctx, jobID, opts, ReasonBlackout)
+	}
+	if now.Sub(t) > MissedAfter {
+		switch sched.catchUp() {
+		case CatchUpLatest:
+			if !last {
+				return s.skip(ctx, jobID, opts, ReasonMissed)
+			}
+		case CatchUpAll:
+			if now.Sub(t) > time.Duration(sched.CatchUpWindow) {
+				return s.skip(ctx, jobID, opts, ReasonMissed)
+			}
+		default:
+			return s.skip(ctx, jobID, opts, ReasonMissed)
+		}
+		opts.CaughtUp = true
+	}
+
+	switch sched.overlap() {
+	case OverlapSkip, OverlapQueue:
+		active, err := s.queries.CountActiveJobRuns(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if active == 0 {
+			break
+		}
+		if sched.overlap() == OverlapQueue {
+			// Hold the fire time back unless another one already waits
+			n, err := s.queries.SetJobScheduleQueued(ctx, db.SetJobScheduleQueuedParams{
+				QueuedFor: sql.NullTime{Time: t, Valid: true},
+				ID:        jobID,
+			})
+			if err != nil || n > 0 {
+				return err
+			}
+		}
+		return s.skip(ctx, jobID, opts, ReasonOverlap)
+	case OverlapReplace:
+		if _, err := s.runs.CancelRuns(ctx, jobID); err != nil {
+			return err
+		}
+	}
+	_, err := s.runs.StartRun(ctx, jobID, opts)
+	return err
+}
+
+func (s *Scheduler) skip(ctx context.Context, jobID string, opts executor.RunOptions, reason string) error {
+	if _, err := s.runs.SkipRun(ctx, jobID, opts, reason); err != nil {
+		return fmt.Errorf("failed to record skipped run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/db/migrate"
+	"github.com/klauern/gopher-tower/internal/db/migrations"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// fakeRuns records the runs it starts and skips as pending and skipped
+// runs, so they show up in the job's history and count as in flight
+type fakeRuns struct {
+	queries   *db.Queries
+	cancelled int
+}
+
+func (r *fakeRuns) StartRun(ctx context.Context, jobID string, opts executor.RunOptions) (db.JobRun, error) {
+	number, err := r.queries.GetNextRunNumber(ctx, jobID)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	return r.queries.CreateJobRun(ctx, db.CreateJobRunParams{
+		ID:           uuid.New().String(),
+		JobID:        jobID,
+		RunNumber:    number,
+		Status:       executor.StatusPending,
+		ScheduledFor: sql.NullTime{Time: opts.ScheduledFor, Valid: true},
+		CaughtUp:     opts.CaughtUp,
+	})
+}
+
+func (r *fakeRuns) SkipRun(ctx context.Context, jobID string, opts executor.RunOptions, reason string) (db.JobRun, error) {
+	number, err := r.queries.GetNextRunNumber(ctx, jobID)
+	if err != nil {
+		return db.JobRun{}, err
+	}
+	return r.queries.CreateSkippedJobRun(ctx, db.CreateSkippedJobRunParams{
+		ID:               uuid.New().String(),
+		JobID:            jobID,
+		RunNumber:        number,
+		FailureReason:    db.StringToNullString(reason),
+		ScheduledFor:     sql.NullTime{Time: opts.ScheduledFor, Valid: true},
+		CaughtUp:         opts.CaughtUp,
+		DroppedFireTimes: int64(opts.DroppedFireTimes),
+	})
+}
+
+func (r *fakeRuns) CancelRuns(ctx context.Context, jobID string) (int, error) {
+	cancelled, err := r.queries.CancelJobRuns(ctx, jobID)
+	r.cancelled += len(cancelled)
+	return len(cancelled), err
+}
+
+func setupQueries(t *testing.T) *db.Queries {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	require.NoError(t, migrate.MigrateDB(path, migrations.Files))
+	conn, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return db.New(conn)
+}
+
+func createJob(t *testing.T, queries *db.Queries, sched Schedule) db.Job {
+	t.Helper()
+	data, err := json.Marshal(sched)
+	require.NoError(t, err)
+	job, err := queries.CreateJob(context.Background(), db.CreateJobParams{
+		ID:       uuid.New().String(),
+		Name:     "nightly",
+		Status:   "pending",
+		Schedule: db.StringToNullString(string(data)),
+	})
+	require.NoError(t, err)
+	return job
+}
+
+// history describes the runs of a job, oldest first, as "status hh:mm"
+// followed by the failure reason or whether the run caught up
+func history(t *testing.T, queries *db.Queries, jobID string) []string {
+	t.Helper()
+	runs, err := queries.ListJobRunsByJob(context.Background(), db.ListJobRunsByJobParams{JobID: jobID, Limit: 100})
+	require.NoError(t, err)
+	slices.Reverse(runs)
+	var entries []string
+	for _, run := range runs {
+		entry := fmt.Sprintf("%s %s", run.Status, run.ScheduledFor.Time.UTC().Format("15:04"))
+		if run.FailureReason.Valid {
+			entry += " " + run.FailureReason.String
+		}
+		if run.CaughtUp {
+			entry += " caught up"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestSchedulerTick(t *testing.T) {
+	ctx := context.Background()
+	// The schedule is first checked at noon
+	noon := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(clock string) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.DateTime, "2025-06-01 "+clock)
+		require.NoError(t, err)
+		return v
+	}
+	setup := func(t *testing.T, sched Schedule) (*db.Queries, *fakeRuns, *Scheduler, db.Job) {
+		queries := setupQueries(t)
+		runs := &fakeRuns{queries: queries}
+		s := NewScheduler(queries, runs, 0)
+		job := createJob(t, queries, sched)
+		require.NoError(t, s.Tick(ctx, noon))
+		return queries, runs, s, job
+	}
+
+	t.Run("fires due times once", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "*/10 * * * *"})
+		assert.Empty(t, history(t, queries, job.ID))
+
+		require.NoError(t, s.Tick(ctx, at("12:10:05")))
+		require.NoError(t, s.Tick(ctx, at("12:10:20")))
+		// A second scheduler sharing the database does not fire it again
+		require.NoError(t, NewScheduler(queries, &fakeRuns{queries: queries}, 0).Tick(ctx, at("12:10:35")))
+		assert.Equal(t, []string{"pending 12:10"}, history(t, queries, job.ID))
+	})
+
+	t.Run("skips missed fire times", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "*/10 * * * *"})
+		require.NoError(t, s.Tick(ctx, at("12:30:30")))
+		assert.Equal(t, []string{"skipped 12:10 missed", "skipped 12:20 missed", "pending 12:30"}, history(t, queries, job.ID))
+	})
+
+	t.Run("records dropped fire times as one skipped run", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "* * * * *"})
+		require.NoError(t, s.Tick(ctx, noon.Add(time.Duration(MaxCatchUp+5)*time.Minute)))
+		runs, err := queries.ListJobRunsByJob(ctx, db.ListJobRunsByJobParams{JobID: job.ID, Limit: MaxCatchUp + 10})
+		require.NoError(t, err)
+		require.Len(t, runs, MaxCatchUp+1)
+		dropped := runs[len(runs)-1]
+		assert.Equal(t, executor.StatusSkipped, dropped.Status)
+		assert.Equal(t, ReasonMissed, dropped.FailureReason.String)
+		assert.Equal(t, int64(5), dropped.DroppedFireTimes)
+		assert.True(t, at("12:05:00").Equal(dropped.ScheduledFor.Time))
+		assert.Zero(t, runs[0].DroppedFireTimes)
+	})
+
+	t.Run("catches up on the latest missed fire time", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "*/10 * * * *", CatchUp: CatchUpLatest})
+		require.NoError(t, s.Tick(ctx, at("12:35:00")))
+		assert.Equal(t, []string{"skipped 12:10 missed", "skipped 12:20 missed", "pending 12:30 caught up"}, history(t, queries, job.ID))
+	})
+
+	t.Run("catches up on all missed fire times within the window", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "*/10 * * * *", CatchUp: CatchUpAll, CatchUpWindow: limits.Duration(15 * time.Minute)})
+		require.NoError(t, s.Tick(ctx, at("12:35:00")))
+		assert.Equal(t, []string{"skipped 12:10 missed", "pending 12:20 caught up", "pending 12:30 caught up"}, history(t, queries, job.ID))
+	})
+
+	t.Run("skips fire times while a run is in flight", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "*/10 * * * *", Overlap: OverlapSkip})
+		require.NoError(t, s.Tick(ctx, at("12:10:05")))
+		require.NoError(t, s.Tick(ctx, at("12:20:05")))
+		assert.Equal(t, []string{"pending 12:10", "skipped 12:20 overlap"}, history(t, queries, job.ID))
+	})
+
+	t.Run("queues one fire time while a run is in flight", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "*/10 * * * *", Overlap: OverlapQueue})
+		require.NoError(t, s.Tick(ctx, at("12:10:05")))
+		require.NoError(t, s.Tick(ctx, at("12:20:05")))
+		require.NoError(t, s.Tick(ctx, at("12:30:05")))
+		assert.Equal(t, []string{"pending 12:10", "skipped 12:30 overlap"}, history(t, queries, job.ID))
+
+		// The queued fire time starts once the run in flight finished
+		_, err := queries.CancelJobRuns(ctx, job.ID)
+		require.NoError(t, err)
+		require.NoError(t, s.Tick(ctx, at("12:30:20")))
+		require.NoError(t, s.Tick(ctx, at("12:30:35")))
+		assert.Equal(t, []string{"cancelled 12:10", "skipped 12:30 overlap", "pending 12:20"}, history(t, queries, job.ID))
+	})
+
+	t.Run("replaces the run in flight", func(t *testing.T) {
+		queries, runs, s, job := setup(t, Schedule{Cron: "*/10 * * * *", Overlap: OverlapReplace})
+		require.NoError(t, s.Tick(ctx, at("12:10:05")))
+		require.NoError(t, s.Tick(ctx, at("12:20:05")))
+		assert.Equal(t, 1, runs.cancelled)
+		assert.Equal(t, []string{"cancelled 12:10", "pending 12:20"}, history(t, queries, job.ID))
+	})
+
+	t.Run("skips fire times in blackout windows", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{
+			Cron:      "*/10 * * * *",
+			Timezone:  "America/New_York",
+			Calendars: []string{"lunch"},
+		})
+		// 12:15 to 12:25 UTC is 08:15 to 08:25 in New York
+		_, err := queries.CreateCalendar(ctx, db.CreateCalendarParams{
+			Name:     "lunch",
+			Timezone: db.StringToNullString("America/New_York"),
+			Windows:  `[{"cron": "15 8 * * *", "duration": "10m"}]`,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, s.Tick(ctx, at("12:10:05")))
+		require.NoError(t, s.Tick(ctx, at("12:20:05")))
+		require.NoError(t, s.Tick(ctx, at("12:30:05")))
+		assert.Equal(t, []string{"pending 12:10", "skipped 12:20 blackout", "pending 12:30"}, history(t, queries, job.ID))
+	})
+
+	t.Run("changing the schedule starts over", func(t *testing.T) {
+		queries, _, s, job := setup(t, Schedule{Cron: "*/10 * * * *"})
+		data, err := json.Marshal(Schedule{Cron: "*/5 * * * *"})
+		require.NoError(t, err)
+		_, err = queries.UpdateJob(ctx, db.UpdateJobParams{
+			ID:       job.ID,
+			Name:     job.Name,
+			Status:   job.Status,
+			Schedule: db.StringToNullString(string(data)),
+		})
+		require.NoError(t, err)
+
+		// Fire times before the change was picked up are not handled
+		require.NoError(t, s.Tick(ctx, at("12:30:00")))
+		require.NoError(t, s.Tick(ctx, at("12:35:05")))
+		assert.Equal(t, []string{"pending 12:35"}, history(t, queries, job.ID))
+	})
+}
@@ -0,0 +1,145 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Cache keeps a bare mirror of every repository checked out through it
+type Cache struct {
+	root string
+
+	mu sync.Mutex
+	// locks serializes fetches into the same mirror
+	locks map[string]*sync.Mutex
+}
+
+// NewCache creates a cache keeping its mirrors under root, creating root if
+// needed
+func NewCache(root string) (*Cache, error) {
+	if root == "" {
+		return nil, fmt.Errorf("%w: cache root is required", ErrInvalidSource)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Cache{root: root, locks: map[string]*sync.Mutex{}}, nil
+}
+
+// Root returns the directory holding the mirrors
+func (c *Cache) Root() string {
+	return c.root
+}
+
+// Checkout updates the mirror of the source's repository, checks the ref
+// out into the source's path below dir and returns the commit SHA it
+// resolved to
+func (c *Cache) Checkout(ctx context.Context, src Source, dir string) (string, error) {
+	if err := src.Validate(); err != nil {
+		return "", err
+	}
+	mirror, sha, err := c.fetch(ctx, src)
+	if err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(dir, src.Path)
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return "", err
+	}
+	// The checkout is a standalone clone of the mirror whose origin points
+	// at the repository itself
+	if _, err := git(ctx, "", "clone", "--quiet", "--no-checkout", "--", mirror, dest); err != nil {
+		return "", err
+	}
+	if _, err := git(ctx, dest, "remote", "set-url", "origin", src.Repository); err != nil {
+		return "", err
+	}
+	if _, err := git(ctx, dest, "checkout", "--quiet", "--detach", sha); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// fetch brings the mirror of a repository up to date, cloning it on first
+// use, and resolves the source's ref in it
+func (c *Cache) fetch(ctx context.Context, src Source) (string, string, error) {
+	sum := sha256.Sum256([]byte(src.Repository))
+	key := hex.EncodeToString(sum[:8])
+	mirror := filepath.Join(c.root, key+".git")
+
+	lock := c.lock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := os.Stat(filepath.Join(mirror, "HEAD")); err == nil {
+		if _, err := git(ctx, mirror, "fetch", "--quiet", "--prune", "origin"); err != nil {
+			return "", "", err
+		}
+	} else {
+		// Clone next to the final location so that an interrupted clone
+		// never leaves a broken mirror behind
+		tmp, err := os.MkdirTemp(c.root, key+".tmp-")
+		if err != nil {
+			return "", "", err
+		}
+		defer os.RemoveAll(tmp)
+		if _, err := git(ctx, "", "clone", "--quiet", "--mirror", "--", src.Repository, tmp); err != nil {
+			return "", "", err
+		}
+		if err := os.RemoveAll(mirror); err != nil {
+			return "", "", err
+		}
+		if err := os.Rename(tmp, mirror); err != nil {
+			return "", "", err
+		}
+	}
+
+	out, err := git(ctx, mirror, "rev-parse", "--verify", "--quiet", "--end-of-options", src.ref()+"^{commit}")
+	if err != nil {
+		return "", "", fmt.Errorf("%w: ref %q not found in %s", ErrCheckout, src.ref(), src.Repository)
+	}
+	return mirror, strings.TrimSpace(out), nil
+}
+
+func (c *Cache) lock(key string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[key] = l
+	}
+	return l
+}
+
+// git runs a git command in dir and returns its standard output. Failures
+// wrap ErrCheckout along with git's error output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never wait for credentials on a terminal nobody is watching
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%w: git %s: %s", ErrCheckout, args[0], msg)
+	}
+	return stdout.String(), nil
+}
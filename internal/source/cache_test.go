@@ -0,0 +1,137 @@
+package source
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepo is a local repository with a main branch, a feature branch and
+// a tag
+type testRepo struct {
+	t    *testing.T
+	path string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	r := &testRepo{t: t, path: filepath.Join(t.TempDir(), "repo")}
+	r.git("init", "--quiet", "--initial-branch=main", r.path)
+	r.commit("README", "v1")
+	r.git("-C", r.path, "tag", "v1")
+	r.git("-C", r.path, "checkout", "--quiet", "-b", "feature")
+	r.commit("README", "feature")
+	r.git("-C", r.path, "checkout", "--quiet", "main")
+	return r
+}
+
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func (r *testRepo) commit(name, content string) string {
+	r.t.Helper()
+	require.NoError(r.t, os.WriteFile(filepath.Join(r.path, name), []byte(content), 0o644))
+	r.git("-C", r.path, "add", name)
+	r.git("-C", r.path, "commit", "--quiet", "-m", content)
+	return r.git("-C", r.path, "rev-parse", "HEAD")
+}
+
+func (r *testRepo) sha(ref string) string {
+	return r.git("-C", r.path, "rev-parse", ref)
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCheckout(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	bare := filepath.Join(t.TempDir(), "bare.git")
+	repo.git("clone", "--quiet", "--bare", repo.path, bare)
+
+	tests := []struct {
+		name    string
+		src     Source
+		want    string
+		content string
+	}{
+		{name: "default branch", src: Source{Repository: repo.path}, want: repo.sha("main"), content: "v1"},
+		{name: "branch", src: Source{Repository: repo.path, Ref: "feature"}, want: repo.sha("feature"), content: "feature"},
+		{name: "tag", src: Source{Repository: repo.path, Ref: "v1"}, want: repo.sha("v1"), content: "v1"},
+		{name: "commit", src: Source{Repository: repo.path, Ref: repo.sha("feature")}, want: repo.sha("feature"), content: "feature"},
+		{name: "file URL", src: Source{Repository: "file://" + repo.path, Ref: "feature", Path: "src"}, want: repo.sha("feature"), content: "feature"},
+		{name: "bare repository", src: Source{Repository: bare, Ref: "feature"}, want: repo.sha("feature"), content: "feature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := NewCache(t.TempDir())
+			require.NoError(t, err)
+			dir := t.TempDir()
+
+			sha, err := cache.Checkout(ctx, tt.src, dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sha)
+			assert.Equal(t, tt.content, readFile(t, filepath.Join(dir, tt.src.Path, "README")))
+			assert.Equal(t, tt.src.Repository, repo.git("-C", filepath.Join(dir, tt.src.Path), "remote", "get-url", "origin"))
+		})
+	}
+
+	t.Run("unknown ref", func(t *testing.T) {
+		cache, err := NewCache(t.TempDir())
+		require.NoError(t, err)
+		_, err = cache.Checkout(ctx, Source{Repository: repo.path, Ref: "missing"}, t.TempDir())
+		assert.ErrorIs(t, err, ErrCheckout)
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		cache, err := NewCache(t.TempDir())
+		require.NoError(t, err)
+		_, err = cache.Checkout(ctx, Source{Repository: filepath.Join(t.TempDir(), "missing")}, t.TempDir())
+		assert.ErrorIs(t, err, ErrCheckout)
+		entries, err := os.ReadDir(cache.Root())
+		require.NoError(t, err)
+		assert.Empty(t, entries, "failed clones leave nothing behind")
+	})
+}
+
+func TestCheckoutReusesMirror(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	cache, err := NewCache(t.TempDir())
+	require.NoError(t, err)
+	src := Source{Repository: repo.path}
+
+	first, err := cache.Checkout(ctx, src, t.TempDir())
+	require.NoError(t, err)
+
+	second := repo.commit("README", "v2")
+	dir := t.TempDir()
+	sha, err := cache.Checkout(ctx, src, dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, sha)
+	assert.Equal(t, second, sha, "the mirror is fetched before every checkout")
+	assert.Equal(t, "v2", readFile(t, filepath.Join(dir, "README")))
+
+	mirrors, err := filepath.Glob(filepath.Join(cache.Root(), "*.git"))
+	require.NoError(t, err)
+	assert.Len(t, mirrors, 1)
+}
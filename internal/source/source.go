@@ -0,0 +1,121 @@
+/*
+Package source checks out the repository a job runs code from.
+
+A job's source names a git repository, a ref and a path:
+
+	{"repository": "https://github.com/acme/etl.git", "ref": "v1.4.0", "path": "src"}
+
+Before every run the repository is checked out at the ref into the given
+path of the run's working directory, and the commit the ref resolved to is
+recorded with the run. The ref may be a branch, a tag or a commit SHA; the
+repository's default branch (HEAD) is used when it is empty.
+
+Repositories are fetched through a Cache of bare mirrors, so that a run
+only transfers what changed since the previous one. Besides remote URLs,
+file:// URLs and absolute paths of local repositories, bare or not, are
+supported, which keeps jobs runnable without network access.
+*/
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidSource = errors.New("invalid source")
+	ErrCheckout      = errors.New("checkout failed")
+)
+
+// Environment variables exposing the checkout to a run's processes
+const (
+	SHAEnv = "GOPHER_TOWER_SOURCE_SHA"
+	DirEnv = "GOPHER_TOWER_SOURCE_DIR"
+)
+
+// DefaultRef is checked out when a source names no ref
+const DefaultRef = "HEAD"
+
+// schemes lists the URL schemes repositories may be fetched over
+var schemes = map[string]bool{"file": true, "http": true, "https": true, "ssh": true, "git": true}
+
+// Source is the repository a job's runs check out
+type Source struct {
+	// Repository is a URL (https, ssh, git or file), an scp-like address
+	// such as git@host:repo.git, or the absolute path of a local repository
+	Repository string `json:"repository"`
+	// Ref is the branch, tag or commit to check out; DefaultRef when empty
+	Ref string `json:"ref,omitempty"`
+	// Path is the directory of the checkout relative to the working
+	// directory; the working directory itself when empty
+	Path string `json:"path,omitempty"`
+}
+
+// Validate checks the source without contacting the repository
+func (s *Source) Validate() error {
+	if err := validRepository(s.Repository); err != nil {
+		return err
+	}
+	if s.Ref != "" && (strings.HasPrefix(s.Ref, "-") || strings.Contains(s.Ref, "..") || strings.ContainsFunc(s.Ref, invalidRefRune)) {
+		return fmt.Errorf("%w: invalid ref %q", ErrInvalidSource, s.Ref)
+	}
+	if s.Path != "" && !filepath.IsLocal(s.Path) {
+		return fmt.Errorf("%w: path must be relative to the working directory", ErrInvalidSource)
+	}
+	return nil
+}
+
+// ref returns the ref to check out
+func (s *Source) ref() string {
+	if s.Ref == "" {
+		return DefaultRef
+	}
+	return s.Ref
+}
+
+func validRepository(repo string) error {
+	switch {
+	case repo == "":
+		return fmt.Errorf("%w: repository is required", ErrInvalidSource)
+	case strings.HasPrefix(repo, "-"), strings.ContainsFunc(repo, unicode.IsControl):
+		return fmt.Errorf("%w: invalid repository %q", ErrInvalidSource, repo)
+	case strings.Contains(repo, "::"):
+		// <transport>::<address> invokes a remote helper
+		return fmt.Errorf("%w: unsupported repository %q", ErrInvalidSource, repo)
+	case filepath.IsAbs(repo):
+		return nil
+	}
+	if i := strings.Index(repo, "://"); i > 0 {
+		u, err := url.Parse(repo)
+		if err != nil || !schemes[u.Scheme] {
+			return fmt.Errorf("%w: unsupported repository URL %q", ErrInvalidSource, repo)
+		}
+		return nil
+	}
+	// scp-like syntax: [user@]host:path
+	if host, _, ok := strings.Cut(repo, ":"); ok && host != "" && !strings.Contains(host, "/") {
+		return nil
+	}
+	return fmt.Errorf("%w: repository must be a URL or an absolute path", ErrInvalidSource)
+}
+
+func invalidRefRune(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`~:?*[\`, r)
+}
+
+// Decode parses and validates a stored source
+func Decode(data []byte) (*Source, error) {
+	var s Source
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSource, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,40 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     Source
+		wantErr bool
+	}{
+		{name: "https", src: Source{Repository: "https://github.com/acme/etl.git", Ref: "v1.4.0", Path: "src"}},
+		{name: "scp-like", src: Source{Repository: "git@github.com:acme/etl.git", Ref: "release/2025"}},
+		{name: "file URL", src: Source{Repository: "file:///srv/git/etl.git"}},
+		{name: "local path", src: Source{Repository: "/srv/git/etl.git", Path: "a/b"}},
+		{name: "missing repository", src: Source{}, wantErr: true},
+		{name: "relative path", src: Source{Repository: "srv/git/etl.git"}, wantErr: true},
+		{name: "option injection", src: Source{Repository: "--upload-pack=touch /tmp/x"}, wantErr: true},
+		{name: "unsupported scheme", src: Source{Repository: "ext::sh -c touch% /tmp/x"}, wantErr: true},
+		{name: "ftp", src: Source{Repository: "ftp://example.com/etl.git"}, wantErr: true},
+		{name: "ref option", src: Source{Repository: "/srv/git/etl.git", Ref: "--output=/tmp/x"}, wantErr: true},
+		{name: "ref range", src: Source{Repository: "/srv/git/etl.git", Ref: "main..dev"}, wantErr: true},
+		{name: "ref with space", src: Source{Repository: "/srv/git/etl.git", Ref: "main dev"}, wantErr: true},
+		{name: "path escapes", src: Source{Repository: "/srv/git/etl.git", Path: "../etc"}, wantErr: true},
+		{name: "absolute path", src: Source{Repository: "/srv/git/etl.git", Path: "/etc"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.src.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSource)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Package templates renders job definitions from parameterized templates.
+
+A template declares typed parameters and a body in which ${{ params.x }}
+placeholders stand for parameter values:
+
+	parameters:
+	[
+		{"name": "env", "type": "enum", "values": ["staging", "prod"], "default": "staging"},
+		{"name": "shards", "type": "int", "min": 1, "max": 16, "default": 4},
+		{"name": "dry_run", "type": "bool"}
+	]
+
+	body:
+	{
+		"name": "export-${{ params.env }}",
+		"plugin": "script",
+		"config": {
+			"interpreter": "sh",
+			"body": "./export.sh --shards ${{ params.shards }}",
+			"args": ["${{ params.env }}"],
+			"env": {"DRY_RUN": "${{ params.dry_run }}"}
+		}
+	}
+
+Placeholders are substituted in every string of the body. A string that
+consists of a single placeholder takes the parameter's typed value, so
+"${{ params.shards }}" renders as the number 4 rather than "4".
+*/
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+)
+
+var (
+	ErrInvalidTemplate = errors.New("invalid template")
+	ErrInvalidParams   = errors.New("invalid parameters")
+)
+
+// ParamType is the type of a template parameter
+type ParamType string
+
+const (
+	TypeString ParamType = "string"
+	TypeInt    ParamType = "int"
+	TypeBool   ParamType = "bool"
+	TypeEnum   ParamType = "enum"
+)
+
+// paramName matches valid parameter names
+var paramName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Param declares a template parameter
+type Param struct {
+	Name        string    `json:"name"`
+	Type        ParamType `json:"type"`
+	Description string    `json:"description,omitempty"`
+	// Default is used when the parameter is not given. Parameters without
+	// a default are required.
+	Default interface{} `json:"default,omitempty"`
+	// Values lists the allowed values of enum parameters
+	Values []string `json:"values,omitempty"`
+	// Pattern is a regular expression string values must match
+	Pattern string `json:"pattern,omitempty"`
+	// Min and Max bound int values
+	Min *int64 `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+}
+
+// ValidateParams checks parameter declarations, including their defaults
+func ValidateParams(params []Param) error {
+	seen := map[string]bool{}
+	for _, p := range params {
+		if !paramName.MatchString(p.Name) {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrInvalidTemplate, p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("%w: duplicate parameter %q", ErrInvalidTemplate, p.Name)
+		}
+		seen[p.Name] = true
+
+		switch p.Type {
+		case TypeString, TypeInt, TypeBool:
+		case TypeEnum:
+			if len(p.Values) == 0 {
+				return fmt.Errorf("%w: enum parameter %q has no values", ErrInvalidTemplate, p.Name)
+			}
+		default:
+			return fmt.Errorf("%w: parameter %q has unknown type %q", ErrInvalidTemplate, p.Name, p.Type)
+		}
+		if p.Pattern != "" {
+			if p.Type != TypeString {
+				return fmt.Errorf("%w: parameter %q: only string parameters take a pattern", ErrInvalidTemplate, p.Name)
+			}
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("%w: parameter %q: %v", ErrInvalidTemplate, p.Name, err)
+			}
+		}
+		if (p.Min != nil || p.Max != nil) && p.Type != TypeInt {
+			return fmt.Errorf("%w: parameter %q: only int parameters take min and max", ErrInvalidTemplate, p.Name)
+		}
+		if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+			return fmt.Errorf("%w: parameter %q: min exceeds max", ErrInvalidTemplate, p.Name)
+		}
+		if p.Default != nil {
+			if _, err := p.coerce(p.Default); err != nil {
+				return fmt.Errorf("%w: default of %v", ErrInvalidTemplate, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve validates the given parameter values against their declarations
+// and fills in defaults. Values are returned as string, int64 or bool.
+func Resolve(params []Param, given map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(params))
+	for name := range given {
+		if !slices.ContainsFunc(params, func(p Param) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("%w: unknown parameter %q", ErrInvalidParams, name)
+		}
+	}
+	for _, p := range params {
+		v, ok := given[p.Name]
+		if !ok || v == nil {
+			if p.Default == nil {
+				return nil, fmt.Errorf("%w: parameter %q is required", ErrInvalidParams, p.Name)
+			}
+			v = p.Default
+		}
+		value, err := p.coerce(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+		}
+		values[p.Name] = value
+	}
+	return values, nil
+}
+
+// coerce converts a JSON-decoded value to the parameter's type and checks
+// its constraints
+func (p Param) coerce(v interface{}) (interface{}, error) {
+	switch p.Type {
+	case TypeString, TypeEnum:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q must be a string", p.Name)
+		}
+		if p.Type == TypeEnum && !slices.Contains(p.Values, s) {
+			return nil, fmt.Errorf("parameter %q must be one of %v", p.Name, p.Values)
+		}
+		if p.Pattern != "" && !regexp.MustCompile(p.Pattern).MatchString(s) {
+			return nil, fmt.Errorf("parameter %q must match %s", p.Name, p.Pattern)
+		}
+		return s, nil
+	case TypeInt:
+		n, ok := toInt(v)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q must be an integer", p.Name)
+		}
+		if p.Min != nil && n < *p.Min {
+			return nil, fmt.Errorf("parameter %q must be at least %d", p.Name, *p.Min)
+		}
+		if p.Max != nil && n > *p.Max {
+			return nil, fmt.Errorf("parameter %q must be at most %d", p.Name, *p.Max)
+		}
+		return n, nil
+	case TypeBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q must be a boolean", p.Name)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("parameter %q has unknown type %q", p.Name, p.Type)
+}
+
+// toInt converts integral JSON numbers to int64
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > 1<<53 {
+			return 0, false
+		}
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
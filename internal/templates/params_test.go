@@ -0,0 +1,94 @@
+package templates
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func int64Ptr(n int64) *int64 { return &n }
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []Param
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			params: []Param{
+				{Name: "env", Type: TypeEnum, Values: []string{"staging", "prod"}, Default: "staging"},
+				{Name: "shards", Type: TypeInt, Min: int64Ptr(1), Max: int64Ptr(16), Default: float64(4)},
+				{Name: "dry_run", Type: TypeBool},
+				{Name: "branch", Type: TypeString, Pattern: `^[a-z-]+$`},
+			},
+		},
+		{name: "invalid name", params: []Param{{Name: "my-param", Type: TypeString}}, wantErr: true},
+		{name: "duplicate", params: []Param{{Name: "a", Type: TypeString}, {Name: "a", Type: TypeInt}}, wantErr: true},
+		{name: "unknown type", params: []Param{{Name: "a", Type: "float"}}, wantErr: true},
+		{name: "enum without values", params: []Param{{Name: "a", Type: TypeEnum}}, wantErr: true},
+		{name: "pattern on int", params: []Param{{Name: "a", Type: TypeInt, Pattern: "x"}}, wantErr: true},
+		{name: "invalid pattern", params: []Param{{Name: "a", Type: TypeString, Pattern: "("}}, wantErr: true},
+		{name: "min above max", params: []Param{{Name: "a", Type: TypeInt, Min: int64Ptr(2), Max: int64Ptr(1)}}, wantErr: true},
+		{name: "invalid default", params: []Param{{Name: "a", Type: TypeEnum, Values: []string{"x"}, Default: "y"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParams(tt.params)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTemplate)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	params := []Param{
+		{Name: "env", Type: TypeEnum, Values: []string{"staging", "prod"}, Default: "staging"},
+		{Name: "shards", Type: TypeInt, Min: int64Ptr(1), Max: int64Ptr(16), Default: float64(4)},
+		{Name: "dry_run", Type: TypeBool, Default: false},
+		{Name: "branch", Type: TypeString, Pattern: `^[a-z-]+$`},
+	}
+
+	tests := []struct {
+		name    string
+		given   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			given: `{"branch": "main"}`,
+			want:  map[string]interface{}{"env": "staging", "shards": int64(4), "dry_run": false, "branch": "main"},
+		},
+		{
+			name:  "given values",
+			given: `{"env": "prod", "shards": 16, "dry_run": true, "branch": "release-x"}`,
+			want:  map[string]interface{}{"env": "prod", "shards": int64(16), "dry_run": true, "branch": "release-x"},
+		},
+		{name: "missing required", given: `{}`, wantErr: true},
+		{name: "unknown parameter", given: `{"branch": "main", "region": "eu"}`, wantErr: true},
+		{name: "not in enum", given: `{"branch": "main", "env": "dev"}`, wantErr: true},
+		{name: "fractional int", given: `{"branch": "main", "shards": 1.5}`, wantErr: true},
+		{name: "int above max", given: `{"branch": "main", "shards": 17}`, wantErr: true},
+		{name: "string for bool", given: `{"branch": "main", "dry_run": "yes"}`, wantErr: true},
+		{name: "pattern mismatch", given: `{"branch": "Main"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var given map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.given), &given))
+			got, err := Resolve(params, given)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidParams)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
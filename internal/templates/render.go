@@ -0,0 +1,115 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// placeholder matches ${{ params.name }}
+var placeholder = regexp.MustCompile(`\$\{\{\s*params\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Check verifies that every placeholder in a JSON-decoded body refers to a
+// declared parameter
+func Check(params []Param, body interface{}) error {
+	declared := make(map[string]bool, len(params))
+	for _, p := range params {
+		declared[p.Name] = true
+	}
+	var err error
+	walkStrings(body, func(s string) {
+		for _, m := range placeholder.FindAllStringSubmatch(s, -1) {
+			if !declared[m[1]] && err == nil {
+				err = fmt.Errorf("%w: undeclared parameter %q", ErrInvalidTemplate, m[1])
+			}
+		}
+	})
+	return err
+}
+
+// Render substitutes parameter values into a JSON-decoded body, returning a
+// new body. values are typically the result of Resolve.
+func Render(body interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := body.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			rendered, err := Render(elem, values)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = rendered
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			rendered, err := Render(elem, values)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	case string:
+		return renderString(v, values)
+	}
+	return body, nil
+}
+
+// renderString substitutes the placeholders of a string. A string that is
+// a single placeholder keeps the value's type.
+func renderString(s string, values map[string]interface{}) (interface{}, error) {
+	if m := placeholder.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		name := s[m[2]:m[3]]
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: no value for parameter %q", ErrInvalidParams, name)
+		}
+		return value, nil
+	}
+
+	var missing string
+	out := placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		name := placeholder.FindStringSubmatch(match)[1]
+		value, ok := values[name]
+		if !ok {
+			missing = name
+			return match
+		}
+		return format(value)
+	})
+	if missing != "" {
+		return nil, fmt.Errorf("%w: no value for parameter %q", ErrInvalidParams, missing)
+	}
+	return out, nil
+}
+
+// format renders a parameter value inside a string
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// walkStrings calls fn for every string value in a JSON-decoded value
+func walkStrings(v interface{}, fn func(string)) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, elem := range v {
+			walkStrings(elem, fn)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			walkStrings(elem, fn)
+		}
+	case string:
+		fn(v)
+	}
+}
@@ -0,0 +1,68 @@
+package templates
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func TestCheck(t *testing.T) {
+	params := []Param{{Name: "env", Type: TypeString}}
+
+	assert.NoError(t, Check(params, decode(t, `{"name": "deploy-${{ params.env }}", "config": {"args": ["${{params.env}}"]}}`)))
+	assert.ErrorIs(t, Check(params, decode(t, `{"config": {"env": {"X": "${{ params.region }}"}}}`)), ErrInvalidTemplate)
+}
+
+func TestRender(t *testing.T) {
+	values := map[string]interface{}{"env": "prod", "shards": int64(4), "dry_run": true}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "embedded placeholders are formatted",
+			body: `{"name": "export-${{ params.env }}", "config": {"body": "./export.sh --shards ${{ params.shards }} --dry-run=${{params.dry_run}}"}}`,
+			want: `{"name": "export-prod", "config": {"body": "./export.sh --shards 4 --dry-run=true"}}`,
+		},
+		{
+			name: "lone placeholders keep their type",
+			body: `{"config": {"shards": "${{ params.shards }}", "args": ["${{ params.env }}", 1], "env": {"DRY_RUN": "${{ params.dry_run }}"}}}`,
+			want: `{"config": {"shards": 4, "args": ["prod", 1], "env": {"DRY_RUN": true}}}`,
+		},
+		{
+			name: "other text is kept",
+			body: `{"sandbox": true, "config": {"body": "echo ${HOME} {{ params.env }}"}}`,
+			want: `{"sandbox": true, "config": {"body": "echo ${HOME} {{ params.env }}"}}`,
+		},
+		{
+			name:    "missing value",
+			body:    `{"name": "x-${{ params.region }}"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(decode(t, tt.body), values)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidParams)
+				return
+			}
+			require.NoError(t, err)
+			data, err := json.Marshal(got)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(data))
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package terminal
+
+import (
+	"os"
+	"os/exec"
+)
+
+func openPTY() (*os.File, *os.File, error) {
+	return nil, nil, ErrUnsupported
+}
+
+func setSize(*os.File, Size) error {
+	return ErrUnsupported
+}
+
+func setControllingTerminal(*exec.Cmd) {}
@@ -0,0 +1,275 @@
+/*
+Package terminal runs the processes of interactive jobs under a
+pseudo-terminal that users can attach to.
+
+A Terminal belongs to one run. Run starts a process with a fresh pty as its
+controlling terminal and standard streams. Everything the process writes is
+kept in a bounded backlog and broadcast to the attached clients, which may
+type input and resize the terminal. A client attaching late first receives
+the backlog, so it sees a prompt written before it attached.
+
+Pseudo-terminals are only supported on Linux; elsewhere Run fails with
+ErrUnsupported.
+*/
+package terminal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnsupported = errors.New("pseudo-terminals are not supported on this platform")
+	ErrNotRunning  = errors.New("no process is running in the terminal")
+	ErrBusy        = errors.New("a process is already running in the terminal")
+	ErrClosed      = errors.New("terminal is closed")
+)
+
+// BacklogSize bounds the output kept for clients attaching late
+const BacklogSize = 64 << 10
+
+// clientBuffer is the number of output chunks buffered per client; clients
+// falling further behind are detached
+const clientBuffer = 256
+
+// drainTimeout bounds how long Run waits for output once the process
+// exited, e.g. when a background child keeps the terminal open
+const drainTimeout = 5 * time.Second
+
+// defaultTerm is set as TERM for processes whose environment has none
+const defaultTerm = "xterm-256color"
+
+// Size is the size of a terminal in characters
+type Size struct {
+	Cols uint16 `json:"cols"`
+	Rows uint16 `json:"rows"`
+}
+
+// DefaultSize is the size of a terminal nobody resized
+var DefaultSize = Size{Cols: 80, Rows: 24}
+
+// Terminal is the pseudo-terminal of a run
+type Terminal struct {
+	mu       sync.Mutex
+	pty      *os.File
+	size     Size
+	backlog  []byte
+	clients  map[*Client]struct{}
+	closed   bool
+	exitCode int
+	done     chan struct{}
+}
+
+// New creates a terminal of DefaultSize
+func New() *Terminal {
+	return &Terminal{
+		size:    DefaultSize,
+		clients: make(map[*Client]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Run starts cmd with a new pty as its controlling terminal, standard
+// input, output and error, and waits for it like cmd.Run. The process's
+// output is copied to out as well as to the attached clients.
+func (t *Terminal) Run(cmd *exec.Cmd, out io.Writer) error {
+	ptm, pts, err := openPTY()
+	if err != nil {
+		return err
+	}
+	defer ptm.Close()
+
+	t.mu.Lock()
+	switch {
+	case t.closed:
+		err = ErrClosed
+	case t.pty != nil:
+		err = ErrBusy
+	default:
+		err = setSize(ptm, t.size)
+		t.pty = ptm
+	}
+	t.mu.Unlock()
+	if err != nil {
+		pts.Close()
+		return err
+	}
+	defer func() {
+		t.mu.Lock()
+		t.pty = nil
+		t.mu.Unlock()
+	}()
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = pts, pts, pts
+	setControllingTerminal(cmd)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	if !slices.ContainsFunc(cmd.Env, func(kv string) bool { return strings.HasPrefix(kv, "TERM=") }) {
+		cmd.Env = append(cmd.Env, "TERM="+defaultTerm)
+	}
+
+	err = cmd.Start()
+	// Only the child holds the terminal side from now on, so reading ptm
+	// ends once it and its children exited
+	pts.Close()
+	if err != nil {
+		return err
+	}
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		t.copyOutput(ptm, out)
+	}()
+	err = cmd.Wait()
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		ptm.Close()
+		<-drained
+	}
+	return err
+}
+
+// copyOutput copies the output of the pty to out and the clients until
+// the pty is closed
+func (t *Terminal) copyOutput(ptm *os.File, out io.Writer) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := ptm.Read(buf)
+		if n > 0 {
+			chunk := append([]byte(nil), buf[:n]...)
+			t.broadcast(chunk)
+			_, _ = out.Write(chunk)
+		}
+		if err != nil {
+			// Reading a pty whose other side was closed fails with EIO
+			return
+		}
+	}
+}
+
+// broadcast records output in the backlog and hands it to every client
+func (t *Terminal) broadcast(chunk []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.backlog = append(t.backlog, chunk...)
+	if excess := len(t.backlog) - BacklogSize; excess > 0 {
+		t.backlog = append(t.backlog[:0:0], t.backlog[excess:]...)
+	}
+	for c := range t.clients {
+		select {
+		case c.output <- chunk:
+		default:
+			t.detach(c)
+		}
+	}
+}
+
+// Write sends input to the running process
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	ptm := t.pty
+	t.mu.Unlock()
+	if ptm == nil {
+		return 0, ErrNotRunning
+	}
+	return ptm.Write(p)
+}
+
+// Resize changes the size of the terminal, now and for processes started
+// later
+func (t *Terminal) Resize(size Size) error {
+	if size.Cols == 0 || size.Rows == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.size = size
+	if t.pty == nil {
+		return nil
+	}
+	return setSize(t.pty, size)
+}
+
+// Size returns the size of the terminal
+func (t *Terminal) Size() Size {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.size
+}
+
+// Attach registers a client receiving the terminal's output. It returns
+// the backlog, which precedes everything sent to the client.
+func (t *Terminal) Attach() (*Client, []byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, nil, ErrClosed
+	}
+	c := &Client{t: t, output: make(chan []byte, clientBuffer)}
+	t.clients[c] = struct{}{}
+	return c, append([]byte(nil), t.backlog...), nil
+}
+
+// detach unregisters a client. The caller must hold t.mu.
+func (t *Terminal) detach(c *Client) {
+	if _, ok := t.clients[c]; ok {
+		delete(t.clients, c)
+		close(c.output)
+	}
+}
+
+// Close ends the terminal once its run finished with exitCode and detaches
+// all clients
+func (t *Terminal) Close(exitCode int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	t.exitCode = exitCode
+	for c := range t.clients {
+		t.detach(c)
+	}
+	close(t.done)
+}
+
+// Done is closed once the terminal is closed
+func (t *Terminal) Done() <-chan struct{} {
+	return t.done
+}
+
+// ExitCode returns the exit code the terminal was closed with
+func (t *Terminal) ExitCode() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exitCode
+}
+
+// Client is a user attached to a terminal
+type Client struct {
+	t      *Terminal
+	output chan []byte
+}
+
+// Output delivers the terminal's output. It is closed when the client is
+// detached: when the terminal closes, when Detach is called or when the
+// client falls too far behind.
+func (c *Client) Output() <-chan []byte {
+	return c.output
+}
+
+// Detach stops delivering output to the client
+func (c *Client) Detach() {
+	c.t.mu.Lock()
+	defer c.t.mu.Unlock()
+	c.t.detach(c)
+}
@@ -0,0 +1,129 @@
+package terminal
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// readUntil collects a client's output until it contains want
+func readUntil(t *testing.T, c *Client, got *strings.Builder, want string) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for !strings.Contains(got.String(), want) {
+		select {
+		case chunk, ok := <-c.Output():
+			require.True(t, ok, "client detached before %q, got %q", want, got.String())
+			got.Write(chunk)
+		case <-deadline:
+			t.Fatalf("timed out waiting for %q, got %q", want, got.String())
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminals are only supported on Linux")
+	}
+
+	t.Run("interactive process", func(t *testing.T) {
+		term := New()
+		require.NoError(t, term.Resize(Size{Cols: 100, Rows: 40}))
+		client, _, err := term.Attach()
+		require.NoError(t, err)
+
+		var out syncBuffer
+		done := make(chan error, 1)
+		go func() {
+			cmd := exec.Command("sh", "-c", `test -t 0 && stty size; printf 'name? '; read name; echo "hello $name"`)
+			done <- term.Run(cmd, &out)
+		}()
+
+		var got strings.Builder
+		readUntil(t, client, &got, "name? ")
+		assert.Contains(t, got.String(), "40 100", "the size applies to the pty")
+		_, err = term.Write([]byte("gopher\n"))
+		require.NoError(t, err)
+		readUntil(t, client, &got, "hello gopher")
+
+		require.NoError(t, <-done)
+		assert.Contains(t, out.String(), "hello gopher")
+		_, err = term.Write([]byte("late\n"))
+		assert.ErrorIs(t, err, ErrNotRunning)
+	})
+
+	t.Run("exit status", func(t *testing.T) {
+		err := New().Run(exec.Command("sh", "-c", "exit 3"), &syncBuffer{})
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+
+	t.Run("late clients get the backlog", func(t *testing.T) {
+		term := New()
+		require.NoError(t, term.Run(exec.Command("echo", "before"), &syncBuffer{}))
+		client, backlog, err := term.Attach()
+		require.NoError(t, err)
+		assert.Contains(t, string(backlog), "before")
+
+		term.Close(0)
+		_, ok := <-client.Output()
+		assert.False(t, ok, "closing detaches clients")
+		_, _, err = term.Attach()
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.ErrorIs(t, term.Run(exec.Command("true"), &syncBuffer{}), ErrClosed)
+	})
+}
+
+func TestBacklog(t *testing.T) {
+	term := New()
+	term.broadcast(bytes.Repeat([]byte("a"), BacklogSize))
+	term.broadcast([]byte("tail"))
+	_, backlog, err := term.Attach()
+	require.NoError(t, err)
+	assert.Len(t, backlog, BacklogSize)
+	assert.True(t, bytes.HasSuffix(backlog, []byte("tail")))
+}
+
+func TestSlowClient(t *testing.T) {
+	term := New()
+	slow, _, err := term.Attach()
+	require.NoError(t, err)
+	for i := 0; i <= clientBuffer; i++ {
+		term.broadcast([]byte("x"))
+	}
+	n := 0
+	for range slow.Output() {
+		n++
+	}
+	assert.Equal(t, clientBuffer, n, "a client falling behind is detached")
+
+	term.Close(7)
+	<-term.Done()
+	assert.Equal(t, 7, term.ExitCode())
+}
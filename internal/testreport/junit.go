@@ -0,0 +1,211 @@
+package testreport
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// suite is a <testsuite> element, or the <testsuites> root
+type suite struct {
+	XMLName xml.Name
+	Name    string     `xml:"name,attr"`
+	Cases   []testCase `xml:"testcase"`
+	Suites  []suite    `xml:"testsuite"`
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *problem `xml:"failure"`
+	Error     *problem `xml:"error"`
+	Skipped   *problem `xml:"skipped"`
+}
+
+// problem is a <failure>, <error> or <skipped> element
+type problem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Parse reads the test cases of a JUnit XML report
+func Parse(r io.Reader) ([]Result, error) {
+	var root suite
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReport, err)
+	}
+	var results []Result
+	switch root.XMLName.Local {
+	case "testsuites":
+		for _, s := range root.Suites {
+			results = s.collect("", results)
+		}
+	case "testsuite":
+		results = root.collect("", results)
+	default:
+		return nil, fmt.Errorf("%w: unexpected root element <%s>", ErrInvalidReport, root.XMLName.Local)
+	}
+	return results, nil
+}
+
+// collect appends the test cases of a suite and its nested suites to
+// results. Suites without a name take the name of their parent.
+func (s *suite) collect(parent string, results []Result) []Result {
+	name := s.Name
+	if name == "" {
+		name = parent
+	}
+	for _, c := range s.Cases {
+		results = append(results, c.result(name))
+	}
+	for i := range s.Suites {
+		results = s.Suites[i].collect(name, results)
+	}
+	return results
+}
+
+func (c *testCase) result(suite string) Result {
+	r := Result{
+		Suite:     suite,
+		Name:      c.Name,
+		Classname: c.Classname,
+		Duration:  parseTime(c.Time),
+		Status:    StatusPassed,
+	}
+	switch {
+	case c.Error != nil:
+		r.Status, r.Message = StatusError, c.Error.message()
+	case c.Failure != nil:
+		r.Status, r.Message = StatusFailed, c.Failure.message()
+	case c.Skipped != nil:
+		r.Status, r.Message = StatusSkipped, c.Skipped.message()
+	}
+	return r
+}
+
+// message returns the message attribute of a problem, falling back to its
+// text, truncated to MaxMessageLength bytes
+func (p *problem) message() string {
+	msg := strings.TrimSpace(p.Message)
+	if msg == "" {
+		msg = strings.TrimSpace(p.Text)
+	}
+	if len(msg) > MaxMessageLength {
+		msg = strings.ToValidUTF8(msg[:MaxMessageLength], "")
+	}
+	return msg
+}
+
+// parseTime parses a duration in seconds, which some tools write with
+// thousands separators; unparsable durations count as zero
+func parseTime(s string) float64 {
+	d, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Collect parses the reports matching patterns in a run's working
+// directory. Reports are read through an os.Root, so patterns cannot
+// reach outside the directory, not even through symbolic links. Reports
+// that cannot be read or parsed are skipped and reported in the returned
+// error along with the results of the others; at most MaxResults test
+// cases are returned.
+func Collect(workDir string, patterns []string) ([]Result, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	root, err := os.OpenRoot(workDir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	fsys := root.FS()
+
+	var (
+		results []Result
+		errs    []error
+	)
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, name := range matches {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			found, err := parseFile(root, name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			results = append(results, found...)
+		}
+	}
+	if len(results) > MaxResults {
+		errs = append(errs, fmt.Errorf("reports contain %d test cases, only the first %d are kept", len(results), MaxResults))
+		results = results[:MaxResults]
+	}
+	return results, errors.Join(errs...)
+}
+
+func parseFile(root *os.Root, name string) ([]Result, error) {
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+	if info.Size() > MaxReportSize {
+		return nil, fmt.Errorf("report exceeds %d bytes", MaxReportSize)
+	}
+	return Parse(io.LimitReader(f, MaxReportSize))
+}
+
+// Summary counts the test cases of a run by status
+type Summary struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Errors  int `json:"errors"`
+	Skipped int `json:"skipped"`
+	// Duration is the sum of the durations of the test cases, in seconds
+	Duration float64 `json:"duration"`
+}
+
+// Summarize counts test cases by status
+func Summarize(results []Result) Summary {
+	var s Summary
+	for _, r := range results {
+		s.Total++
+		s.Duration += r.Duration
+		switch r.Status {
+		case StatusPassed:
+			s.Passed++
+		case StatusFailed:
+			s.Failed++
+		case StatusError:
+			s.Errors++
+		case StatusSkipped:
+			s.Skipped++
+		}
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+/*
+Package testreport parses the JUnit XML test reports written by job runs.
+
+A job declares where its runs write reports as a list of glob patterns,
+slash-separated and relative to the run's working directory:
+
+	["reports/*.xml", "build/test-results/junit.xml"]
+
+After every run, whether it succeeded or not, the files matching the
+patterns are parsed (see Collect) and every test case they report is
+recorded with its suite, name, class name, duration, status and failure
+message. Reports that cannot be read or parsed are skipped; they never
+change the outcome of a run.
+
+Both the <testsuites> and the bare <testsuite> layouts are understood,
+including nested suites. A test case is "failed" when it has a <failure>
+element, "error" when it has an <error> element, "skipped" when it has a
+<skipped> element and "passed" otherwise.
+*/
+package testreport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+var (
+	ErrInvalidReports = errors.New("invalid test reports")
+	ErrInvalidReport  = errors.New("not a JUnit XML report")
+)
+
+// Limits of a job's report declarations and of the reports of a run
+const (
+	MaxPatterns = 16
+	// MaxReportSize bounds the size of a single report file
+	MaxReportSize = 16 << 20
+	// MaxResults bounds the test cases recorded per run
+	MaxResults = 10000
+	// MaxMessageLength bounds the failure message kept per test case
+	MaxMessageLength = 4096
+)
+
+// Statuses of a test case
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusError   = "error"
+	StatusSkipped = "skipped"
+)
+
+// Result is a test case reported by a run
+type Result struct {
+	Suite     string `json:"suite"`
+	Name      string `json:"name"`
+	Classname string `json:"classname,omitempty"`
+	// Duration is in seconds
+	Duration float64 `json:"duration"`
+	Status   string  `json:"status"`
+	// Message describes the failure, error or reason for skipping
+	Message string `json:"message,omitempty"`
+}
+
+// IsFailure reports whether a status counts as failing: failed or error
+func IsFailure(status string) bool {
+	return status == StatusFailed || status == StatusError
+}
+
+// Validate checks a job's report patterns
+func Validate(patterns []string) error {
+	if len(patterns) > MaxPatterns {
+		return fmt.Errorf("%w: at most %d patterns are allowed", ErrInvalidReports, MaxPatterns)
+	}
+	for _, p := range patterns {
+		if p == "" || p == "." || !fs.ValidPath(p) {
+			return fmt.Errorf("%w: pattern %q must be a slash-separated path relative to the working directory", ErrInvalidReports, p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("%w: invalid pattern %q", ErrInvalidReports, p)
+		}
+	}
+	return nil
+}
+
+// Decode parses and validates stored report patterns
+func Decode(data []byte) ([]string, error) {
+	var patterns []string
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReports, err)
+	}
+	if err := Validate(patterns); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
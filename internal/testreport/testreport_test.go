@@ -0,0 +1,124 @@
+package testreport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const report = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="pkg/math" tests="4">
+    <testcase name="TestAdd" classname="math" time="0.012"/>
+    <testcase name="TestDiv" classname="math" time="1,000.5">
+      <failure message="division by zero">stack trace</failure>
+    </testcase>
+    <testcase name="TestMod" classname="math">
+      <error>panic: runtime error</error>
+    </testcase>
+    <testcase name="TestPow" classname="math" time="0">
+      <skipped message="slow"/>
+    </testcase>
+    <testsuite>
+      <testcase name="TestNested" time="bogus"/>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "globs", patterns: []string{"reports/*.xml", "junit.xml"}},
+		{name: "empty", patterns: []string{""}, wantErr: true},
+		{name: "dot", patterns: []string{"."}, wantErr: true},
+		{name: "absolute", patterns: []string{"/tmp/*.xml"}, wantErr: true},
+		{name: "escapes", patterns: []string{"../*.xml"}, wantErr: true},
+		{name: "invalid glob", patterns: []string{"reports/[.xml"}, wantErr: true},
+		{name: "too many", patterns: make([]string, MaxPatterns+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.patterns)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidReports)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	patterns, err := Decode([]byte(`["out/*.xml"]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"out/*.xml"}, patterns)
+
+	_, err = Decode([]byte(`{}`))
+	assert.ErrorIs(t, err, ErrInvalidReports)
+}
+
+func TestParse(t *testing.T) {
+	results, err := Parse(strings.NewReader(report))
+	require.NoError(t, err)
+	assert.Equal(t, []Result{
+		{Suite: "pkg/math", Name: "TestAdd", Classname: "math", Duration: 0.012, Status: StatusPassed},
+		{Suite: "pkg/math", Name: "TestDiv", Classname: "math", Duration: 1000.5, Status: StatusFailed, Message: "division by zero"},
+		{Suite: "pkg/math", Name: "TestMod", Classname: "math", Status: StatusError, Message: "panic: runtime error"},
+		{Suite: "pkg/math", Name: "TestPow", Classname: "math", Status: StatusSkipped, Message: "slow"},
+		{Suite: "pkg/math", Name: "TestNested", Status: StatusPassed},
+	}, results)
+
+	results, err = Parse(strings.NewReader(`<testsuite name="s"><testcase name="a"/></testsuite>`))
+	require.NoError(t, err)
+	assert.Equal(t, []Result{{Suite: "s", Name: "a", Status: StatusPassed}}, results)
+
+	_, err = Parse(strings.NewReader(`<html></html>`))
+	assert.ErrorIs(t, err, ErrInvalidReport)
+	_, err = Parse(strings.NewReader(`not xml`))
+	assert.ErrorIs(t, err, ErrInvalidReport)
+}
+
+func TestParseTruncatesMessages(t *testing.T) {
+	long := strings.Repeat("x", MaxMessageLength+10)
+	results, err := Parse(strings.NewReader(`<testsuite name="s"><testcase name="a"><failure>` + long + `</failure></testcase></testsuite>`))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Len(t, results[0].Message, MaxMessageLength)
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "reports"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "reports", "a.xml"), []byte(report), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "reports", "b.xml"), []byte("garbage"), 0o644))
+	outside := filepath.Join(t.TempDir(), "c.xml")
+	require.NoError(t, os.WriteFile(outside, []byte(report), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "reports", "c.xml")))
+
+	// Overlapping patterns read a report once
+	results, err := Collect(dir, []string{"reports/*.xml", "reports/a.xml"})
+	assert.Len(t, results, 5)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidReport)
+	assert.Contains(t, err.Error(), "reports/b.xml")
+	assert.Contains(t, err.Error(), "reports/c.xml")
+
+	results, err = Collect(dir, []string{"missing/*.xml"})
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestSummarize(t *testing.T) {
+	results, err := Parse(strings.NewReader(report))
+	require.NoError(t, err)
+	s := Summarize(results)
+	assert.Equal(t, Summary{Total: 5, Passed: 2, Failed: 1, Errors: 1, Skipped: 1, Duration: 1000.512}, s)
+}
@@ -0,0 +1,217 @@
+package watch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/executor"
+)
+
+// DefaultSyncInterval is how often the Manager picks up changed triggers
+const DefaultSyncInterval = 30 * time.Second
+
+// Querier is the subset of db.Queries the Manager uses
+type Querier interface {
+	ListFileTriggers(ctx context.Context) ([]db.ListFileTriggersRow, error)
+	ClaimTriggeredFile(ctx context.Context, arg db.ClaimTriggeredFileParams) (db.TriggeredFile, error)
+	SetTriggeredFileRun(ctx context.Context, arg db.SetTriggeredFileRunParams) error
+	DeleteTriggeredFile(ctx context.Context, arg db.DeleteTriggeredFileParams) error
+}
+
+// Starter starts runs of jobs; it is implemented by executor.Executor
+type Starter interface {
+	StartRun(ctx context.Context, jobID string, opts executor.RunOptions) (db.JobRun, error)
+}
+
+// Manager watches the directories of every job with a file trigger
+type Manager struct {
+	queries  Querier
+	starter  Starter
+	interval time.Duration
+
+	mu       sync.Mutex
+	watchers map[string]*watcher
+}
+
+// watcher watches the directory of one job
+type watcher struct {
+	trigger Trigger
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewManager creates a Manager syncing the jobs' triggers every interval,
+// or DefaultSyncInterval when it is zero
+func NewManager(queries Querier, starter Starter, interval time.Duration) *Manager {
+	if interval <= 0 {
+		interval = DefaultSyncInterval
+	}
+	return &Manager{
+		queries:  queries,
+		starter:  starter,
+		interval: interval,
+		watchers: make(map[string]*watcher),
+	}
+}
+
+// Run watches the jobs' directories until ctx is done. Triggers added,
+// changed or removed are picked up within the sync interval, and watchers
+// that failed, e.g. because their directory did not exist, are restarted.
+func (m *Manager) Run(ctx context.Context) {
+	defer m.stopAll()
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		if err := m.Sync(ctx); err != nil {
+			log.Printf("File triggers: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync starts watching the directories of new and changed triggers and
+// stops watching those of removed ones
+func (m *Manager) Sync(ctx context.Context) error {
+	rows, err := m.queries.ListFileTriggers(ctx)
+	if err != nil {
+		return err
+	}
+	triggers := make(map[string]Trigger, len(rows))
+	for _, row := range rows {
+		t, err := Decode([]byte(row.FileTrigger.String))
+		if err != nil {
+			log.Printf("File trigger of job %s: %v", row.ID, err)
+			continue
+		}
+		triggers[row.ID] = *t
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for jobID, w := range m.watchers {
+		if t, ok := triggers[jobID]; !ok || t != w.trigger {
+			w.cancel()
+			delete(m.watchers, jobID)
+		}
+	}
+	for jobID, t := range triggers {
+		if _, ok := m.watchers[jobID]; !ok {
+			m.watchers[jobID] = m.watch(ctx, jobID, t)
+		}
+	}
+	return nil
+}
+
+// watch starts watching the directory of a job
+func (m *Manager) watch(ctx context.Context, jobID string, t Trigger) *watcher {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watcher{trigger: t, cancel: cancel, done: make(chan struct{})}
+	batches := make(chan []File)
+	go func() {
+		defer close(batches)
+		if err := Watch(ctx, t, batches); err != nil {
+			log.Printf("File trigger of job %s: %v", jobID, err)
+		}
+	}()
+	go func() {
+		defer close(w.done)
+		for files := range batches {
+			if _, err := m.Trigger(ctx, jobID, files); err != nil {
+				log.Printf("File trigger of job %s: %v", jobID, err)
+			}
+		}
+		// Forget a failed watcher so that the next sync restarts it
+		m.mu.Lock()
+		if m.watchers[jobID] == w {
+			delete(m.watchers, jobID)
+		}
+		m.mu.Unlock()
+	}()
+	return w
+}
+
+// stopAll stops every watcher and waits for them to finish
+func (m *Manager) stopAll() {
+	m.mu.Lock()
+	watchers := make([]*watcher, 0, len(m.watchers))
+	for jobID, w := range m.watchers {
+		w.cancel()
+		watchers = append(watchers, w)
+		delete(m.watchers, jobID)
+	}
+	m.mu.Unlock()
+	for _, w := range watchers {
+		<-w.done
+	}
+}
+
+// Trigger starts a run of a job with the files that have not triggered one
+// yet and records them. It returns the files the run was started with, or
+// nil when all of them triggered before. Files are released again when the
+// run cannot be started.
+func (m *Manager) Trigger(ctx context.Context, jobID string, files []File) ([]File, error) {
+	var claimed []File
+	for _, f := range files {
+		_, err := m.queries.ClaimTriggeredFile(ctx, db.ClaimTriggeredFileParams{
+			JobID:   jobID,
+			Path:    f.Path,
+			Size:    f.Size,
+			ModTime: f.ModTime.UnixNano(),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			m.release(jobID, claimed)
+			return nil, err
+		}
+		claimed = append(claimed, f)
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	inputs, err := Inputs(claimed)
+	if err != nil {
+		m.release(jobID, claimed)
+		return nil, err
+	}
+	run, err := m.starter.StartRun(ctx, jobID, executor.RunOptions{Inputs: inputs})
+	if err != nil {
+		m.release(jobID, claimed)
+		return nil, err
+	}
+	for _, f := range claimed {
+		if err := m.queries.SetTriggeredFileRun(ctx, db.SetTriggeredFileRunParams{
+			RunID: db.StringToNullString(run.ID),
+			JobID: jobID,
+			Path:  f.Path,
+		}); err != nil {
+			log.Printf("Failed to record the run of triggered file %s: %v", f.Path, err)
+		}
+	}
+	return claimed, nil
+}
+
+// release forgets files claimed for a run that was not started, so that
+// they trigger again
+func (m *Manager) release(jobID string, files []File) {
+	for _, f := range files {
+		// The claim is released even when ctx is done
+		if err := m.queries.DeleteTriggeredFile(context.Background(), db.DeleteTriggeredFileParams{
+			JobID: jobID,
+			Path:  f.Path,
+		}); err != nil {
+			log.Printf("Failed to release triggered file %s: %v", f.Path, err)
+		}
+	}
+}
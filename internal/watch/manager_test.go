@@ -0,0 +1,155 @@
+package watch
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauern/gopher-tower/internal/db"
+	"github.com/klauern/gopher-tower/internal/db/migrate"
+	"github.com/klauern/gopher-tower/internal/db/migrations"
+	"github.com/klauern/gopher-tower/internal/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// fakeStarter records the runs it is asked to start
+type fakeStarter struct {
+	err  error
+	runs chan string
+}
+
+func newFakeStarter() *fakeStarter {
+	return &fakeStarter{runs: make(chan string, 16)}
+}
+
+func (s *fakeStarter) StartRun(_ context.Context, jobID string, opts executor.RunOptions) (db.JobRun, error) {
+	if s.err != nil {
+		return db.JobRun{}, s.err
+	}
+	s.runs <- string(opts.Inputs)
+	return db.JobRun{ID: uuid.New().String(), JobID: jobID}, nil
+}
+
+func setupQueries(t *testing.T) *db.Queries {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	require.NoError(t, migrate.MigrateDB(path, migrations.Files))
+	conn, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return db.New(conn)
+}
+
+func createJob(t *testing.T, queries *db.Queries, trigger string) db.Job {
+	t.Helper()
+	job, err := queries.CreateJob(context.Background(), db.CreateJobParams{
+		ID:          uuid.New().String(),
+		Name:        "ingest",
+		Status:      "pending",
+		FileTrigger: db.StringToNullString(trigger),
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func TestManagerTrigger(t *testing.T) {
+	ctx := context.Background()
+	queries := setupQueries(t)
+	job := createJob(t, queries, "")
+	starter := newFakeStarter()
+	m := NewManager(queries, starter, 0)
+	modTime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	a := File{Path: "/srv/drop/a.csv", Size: 10, ModTime: modTime}
+	b := File{Path: "/srv/drop/b.csv", Size: 20, ModTime: modTime}
+
+	started, err := m.Trigger(ctx, job.ID, []File{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, []File{a, b}, started)
+	assert.JSONEq(t, `{"files": ["/srv/drop/a.csv", "/srv/drop/b.csv"]}`, <-starter.runs)
+
+	// Files that triggered a run do not trigger again, even through a new
+	// manager after a restart
+	m = NewManager(queries, starter, 0)
+	started, err = m.Trigger(ctx, job.ID, []File{a, b})
+	require.NoError(t, err)
+	assert.Nil(t, started)
+
+	// A replaced file triggers again
+	a.Size = 11
+	started, err = m.Trigger(ctx, job.ID, []File{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, []File{a}, started)
+	assert.JSONEq(t, `{"files": ["/srv/drop/a.csv"]}`, <-starter.runs)
+
+	recorded, err := queries.ListTriggeredFiles(ctx, job.ID)
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	for _, f := range recorded {
+		assert.True(t, f.RunID.Valid, f.Path)
+	}
+
+	// Files are released when the run cannot be started
+	starter.err = executor.ErrShuttingDown
+	c := File{Path: "/srv/drop/c.csv", Size: 30, ModTime: modTime}
+	_, err = m.Trigger(ctx, job.ID, []File{c})
+	assert.ErrorIs(t, err, executor.ErrShuttingDown)
+	starter.err = nil
+	started, err = m.Trigger(ctx, job.ID, []File{c})
+	require.NoError(t, err)
+	assert.Equal(t, []File{c}, started)
+}
+
+func TestManagerRun(t *testing.T) {
+	queries := setupQueries(t)
+	dir := t.TempDir()
+	n, err := watchDir(dir, make(chan string))
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	n.Close()
+	data, err := json.Marshal(Trigger{Path: dir, Glob: "*.csv", Debounce: 1, Settle: 1})
+	require.NoError(t, err)
+	job := createJob(t, queries, string(data))
+	starter := newFakeStarter()
+	m := NewManager(queries, starter, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	path := filepath.Join(dir, "a.csv")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+	select {
+	case inputs := <-starter.runs:
+		assert.JSONEq(t, `{"files": [`+string(mustJSON(t, path))+`]}`, inputs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no run was started")
+	}
+	recorded, err := queries.ListTriggeredFiles(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, path, recorded[0].Path)
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,85 @@
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// notifyMask selects the events of files being created, written or moved
+// into a directory and of the directory itself going away
+const notifyMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_ATTRIB |
+	unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// watchDir reports the names of the files changing in dir on changed until
+// the returned closer is closed. An empty name means that events were lost
+// or the directory itself changed, so it must be scanned again.
+func watchDir(dir string, changed chan<- string) (io.Closer, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+	if _, err := unix.InotifyAddWatch(fd, dir, notifyMask); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	// The non-blocking descriptor is read through the runtime poller, so
+	// closing the file ends a pending read
+	n := &notifier{f: os.NewFile(uintptr(fd), "inotify"), done: make(chan struct{})}
+	go n.read(changed)
+	return n, nil
+}
+
+type notifier struct {
+	f    *os.File
+	done chan struct{}
+	once sync.Once
+}
+
+func (n *notifier) Close() error {
+	var err error
+	n.once.Do(func() {
+		close(n.done)
+		err = n.f.Close()
+	})
+	return err
+}
+
+// read decodes the events of the inotify instance until it is closed
+func (n *notifier) read(changed chan<- string) {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		size, err := n.f.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= size; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			end := off + unix.SizeofInotifyEvent + int(event.Len)
+			if end > size {
+				break
+			}
+			var name string
+			switch {
+			case event.Mask&(unix.IN_Q_OVERFLOW|unix.IN_DELETE_SELF|unix.IN_MOVE_SELF|unix.IN_IGNORED) != 0:
+				// Rescan
+			case event.Mask&unix.IN_ISDIR != 0 || event.Len == 0:
+				off = end
+				continue
+			default:
+				name = string(bytes.TrimRight(buf[off+unix.SizeofInotifyEvent:end], "\x00"))
+			}
+			off = end
+			select {
+			case changed <- name:
+			case <-n.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watch
+
+import "io"
+
+func watchDir(string, chan<- string) (io.Closer, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,141 @@
+/*
+Package watch starts runs of jobs when files land in a directory.
+
+A job's file trigger names a directory, a glob matched against the names of
+the files in it, a debounce interval and a settle interval:
+
+	{"path": "/srv/drop/invoices", "glob": "*.csv", "debounce": "10s", "settle": "5s"}
+
+The directory is watched with inotify. A matching file counts once it
+settled: its size and modification time did not change for the settle
+interval, so a file still being copied in is not picked up half-written.
+Settled files are collected until no further file settled for the debounce
+interval and then start a single run, which receives their absolute paths
+as the "files" input:
+
+	{"files": ["/srv/drop/invoices/2025-06-01.csv", "/srv/drop/invoices/2025-06-02.csv"]}
+
+Jobs with a file trigger must therefore declare a "files" property in their
+input schema. Files present when watching starts are treated like new ones.
+
+Every file that started a run is recorded in the triggered_files table
+along with its size and modification time, so it is not picked up again
+after a restart. A recorded file triggers again once it is replaced by one
+of a different size or modification time. Subdirectories are not watched.
+
+Watching is only supported on Linux; elsewhere the Manager logs
+ErrUnsupported for every job with a file trigger.
+*/
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+)
+
+var (
+	ErrInvalidTrigger = errors.New("invalid file trigger")
+	ErrUnsupported    = errors.New("watching directories is not supported on this platform")
+)
+
+// FilesInput is the input receiving the paths of the files starting a run
+const FilesInput = "files"
+
+// Defaults of the intervals a trigger leaves unset
+const (
+	DefaultGlob     = "*"
+	DefaultDebounce = 5 * time.Second
+	DefaultSettle   = 2 * time.Second
+)
+
+// Trigger is the directory whose files start runs of a job
+type Trigger struct {
+	// Path is the absolute path of the watched directory
+	Path string `json:"path"`
+	// Glob is matched against the names of the files in the directory;
+	// DefaultGlob when empty
+	Glob string `json:"glob,omitempty"`
+	// Debounce is how long to wait for further files before starting a
+	// run; DefaultDebounce when zero
+	Debounce limits.Duration `json:"debounce,omitempty"`
+	// Settle is how long a file must stay unchanged before it counts;
+	// DefaultSettle when zero
+	Settle limits.Duration `json:"settle,omitempty"`
+}
+
+// Validate checks the trigger without accessing the directory
+func (t *Trigger) Validate() error {
+	if t.Path == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidTrigger)
+	}
+	if !filepath.IsAbs(t.Path) {
+		return fmt.Errorf("%w: path must be absolute", ErrInvalidTrigger)
+	}
+	if strings.ContainsRune(t.Glob, '/') {
+		return fmt.Errorf("%w: glob must match file names, not paths", ErrInvalidTrigger)
+	}
+	if _, err := filepath.Match(t.Glob, ""); err != nil {
+		return fmt.Errorf("%w: invalid glob %q", ErrInvalidTrigger, t.Glob)
+	}
+	if t.Debounce < 0 {
+		return fmt.Errorf("%w: debounce must not be negative", ErrInvalidTrigger)
+	}
+	if t.Settle < 0 {
+		return fmt.Errorf("%w: settle must not be negative", ErrInvalidTrigger)
+	}
+	return nil
+}
+
+// Matches reports whether a file name matches the trigger's glob
+func (t *Trigger) Matches(name string) bool {
+	ok, _ := filepath.Match(t.glob(), name)
+	return ok
+}
+
+func (t *Trigger) glob() string {
+	if t.Glob == "" {
+		return DefaultGlob
+	}
+	return t.Glob
+}
+
+func (t *Trigger) debounce() time.Duration {
+	if t.Debounce == 0 {
+		return DefaultDebounce
+	}
+	return time.Duration(t.Debounce)
+}
+
+func (t *Trigger) settle() time.Duration {
+	if t.Settle == 0 {
+		return DefaultSettle
+	}
+	return time.Duration(t.Settle)
+}
+
+// Decode parses and validates a stored trigger
+func Decode(data []byte) (*Trigger, error) {
+	var t Trigger
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrigger, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Inputs returns the inputs of a run started by files
+func Inputs(files []File) (json.RawMessage, error) {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	return json.Marshal(map[string][]string{FilesInput: paths})
+}
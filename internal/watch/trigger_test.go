@@ -0,0 +1,62 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerValidate(t *testing.T) {
+	valid := []Trigger{
+		{Path: "/srv/drop"},
+		{Path: "/srv/drop", Glob: "invoice-[0-9]*.csv", Debounce: limits.Duration(time.Minute), Settle: limits.Duration(time.Second)},
+	}
+	for _, trigger := range valid {
+		assert.NoError(t, trigger.Validate(), trigger)
+	}
+
+	invalid := map[string]Trigger{
+		"no path":           {},
+		"relative path":     {Path: "drop"},
+		"glob with a slash": {Path: "/srv", Glob: "drop/*.csv"},
+		"bad glob":          {Path: "/srv/drop", Glob: "[a-"},
+		"negative debounce": {Path: "/srv/drop", Debounce: limits.Duration(-time.Second)},
+		"negative settle":   {Path: "/srv/drop", Settle: limits.Duration(-time.Second)},
+	}
+	for name, trigger := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, trigger.Validate(), ErrInvalidTrigger)
+		})
+	}
+}
+
+func TestTriggerMatches(t *testing.T) {
+	all := Trigger{Path: "/srv/drop"}
+	assert.True(t, all.Matches("report.csv"))
+
+	csv := Trigger{Path: "/srv/drop", Glob: "*.csv"}
+	assert.True(t, csv.Matches("report.csv"))
+	assert.False(t, csv.Matches("report.csv.part"))
+}
+
+func TestDecode(t *testing.T) {
+	trigger, err := Decode([]byte(`{"path": "/srv/drop", "glob": "*.csv", "debounce": "10s"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Trigger{Path: "/srv/drop", Glob: "*.csv", Debounce: limits.Duration(10 * time.Second)}, trigger)
+	assert.Equal(t, 10*time.Second, trigger.debounce())
+	assert.Equal(t, DefaultSettle, trigger.settle())
+
+	_, err = Decode([]byte(`{"path": "/srv/drop", "settle": "soon"}`))
+	assert.ErrorIs(t, err, ErrInvalidTrigger)
+	_, err = Decode([]byte(`{"glob": "*"}`))
+	assert.ErrorIs(t, err, ErrInvalidTrigger)
+}
+
+func TestInputs(t *testing.T) {
+	data, err := Inputs([]File{{Path: "/srv/drop/a.csv"}, {Path: "/srv/drop/b.csv"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"files": ["/srv/drop/a.csv", "/srv/drop/b.csv"]}`, string(data))
+}
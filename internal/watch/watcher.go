@@ -0,0 +1,123 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// File is a settled file matched by a trigger
+type File struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Bounds of the interval files are checked for settling at
+const (
+	minPoll = 50 * time.Millisecond
+	maxPoll = time.Second
+)
+
+// candidate is a matching file that has not settled yet
+type candidate struct {
+	size    int64
+	modTime time.Time
+	// since is when the file was last seen changing
+	since time.Time
+}
+
+// Watch watches the trigger's directory and sends the files settling in
+// it on out, in batches of the files that settled within the debounce
+// interval of each other. Files already in the directory are sent too.
+// It returns when ctx is done or the directory can no longer be read.
+func Watch(ctx context.Context, t Trigger, out chan<- []File) error {
+	changed := make(chan string, 64)
+	n, err := watchDir(t.Path, changed)
+	if err != nil {
+		return err
+	}
+	defer n.Close()
+
+	settle, debounce := t.settle(), t.debounce()
+	poll := min(max(min(settle, debounce)/4, minPoll), maxPoll)
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	candidates := make(map[string]*candidate)
+	// Scanning after the watch was added catches files created in between
+	scan := func() error {
+		entries, err := os.ReadDir(t.Path)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() && t.Matches(e.Name()) {
+				observe(candidates, filepath.Join(t.Path, e.Name()), time.Now())
+			}
+		}
+		return nil
+	}
+	if err := scan(); err != nil {
+		return err
+	}
+
+	var ready []File
+	var lastReady time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case name := <-changed:
+			if name == "" {
+				if err := scan(); err != nil {
+					return err
+				}
+			} else if t.Matches(name) {
+				// A file changing again must settle again
+				path := filepath.Join(t.Path, name)
+				ready = slices.DeleteFunc(ready, func(f File) bool { return f.Path == path })
+				observe(candidates, path, time.Now())
+			}
+		case now := <-ticker.C:
+			for path, c := range candidates {
+				info, err := os.Stat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					delete(candidates, path)
+					continue
+				}
+				if info.Size() != c.size || !info.ModTime().Equal(c.modTime) {
+					c.size, c.modTime, c.since = info.Size(), info.ModTime(), now
+					continue
+				}
+				if now.Sub(c.since) >= settle {
+					delete(candidates, path)
+					ready = append(ready, File{Path: path, Size: c.size, ModTime: c.modTime})
+					lastReady = now
+				}
+			}
+			if len(ready) > 0 && now.Sub(lastReady) >= debounce {
+				slices.SortFunc(ready, func(a, b File) int { return strings.Compare(a.Path, b.Path) })
+				select {
+				case out <- ready:
+				case <-ctx.Done():
+					return nil
+				}
+				ready = nil
+			}
+		}
+	}
+}
+
+// observe records that a file changed at now, restarting its settle
+// interval
+func observe(candidates map[string]*candidate, path string, now time.Time) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	candidates[path] = &candidate{size: info.Size(), modTime: info.ModTime(), since: now}
+}
@@ -0,0 +1,103 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauern/gopher-tower/internal/limits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTrigger watches a new directory with short intervals
+func testTrigger(t *testing.T) Trigger {
+	t.Helper()
+	return Trigger{
+		Path:     t.TempDir(),
+		Glob:     "*.csv",
+		Debounce: limits.Duration(200 * time.Millisecond),
+		Settle:   limits.Duration(200 * time.Millisecond),
+	}
+}
+
+// startWatch watches the trigger's directory until the test ends
+func startWatch(t *testing.T, trigger Trigger) <-chan []File {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan []File)
+	errs := make(chan error, 1)
+	go func() { errs <- Watch(ctx, trigger, out) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-errs)
+	})
+	select {
+	case err := <-errs:
+		if errors.Is(err, ErrUnsupported) {
+			t.Skip(err)
+		}
+		require.NoError(t, err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return out
+}
+
+func receive(t *testing.T, out <-chan []File) []string {
+	t.Helper()
+	select {
+	case files := <-out:
+		paths := make([]string, len(files))
+		for i, f := range files {
+			paths[i] = filepath.Base(f.Path)
+		}
+		return paths
+	case <-time.After(5 * time.Second):
+		t.Fatal("no files were reported")
+		return nil
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Run("existing and new files", func(t *testing.T) {
+		trigger := testTrigger(t)
+		require.NoError(t, os.WriteFile(filepath.Join(trigger.Path, "a.csv"), []byte("a"), 0o644))
+		out := startWatch(t, trigger)
+
+		require.NoError(t, os.WriteFile(filepath.Join(trigger.Path, "b.csv"), []byte("b"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(trigger.Path, "c.txt"), []byte("c"), 0o644))
+		require.NoError(t, os.Mkdir(filepath.Join(trigger.Path, "d.csv"), 0o755))
+		// Files written elsewhere and moved into place
+		require.NoError(t, os.WriteFile(filepath.Join(trigger.Path, "e.part"), []byte("e"), 0o644))
+		require.NoError(t, os.Rename(filepath.Join(trigger.Path, "e.part"), filepath.Join(trigger.Path, "e.csv")))
+		assert.Equal(t, []string{"a.csv", "b.csv", "e.csv"}, receive(t, out))
+	})
+
+	t.Run("growing file", func(t *testing.T) {
+		trigger := testTrigger(t)
+		out := startWatch(t, trigger)
+
+		f, err := os.Create(filepath.Join(trigger.Path, "big.csv"))
+		require.NoError(t, err)
+		defer f.Close()
+		start := time.Now()
+		for range 5 {
+			_, err := f.WriteString("row\n")
+			require.NoError(t, err)
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.Equal(t, []string{"big.csv"}, receive(t, out))
+		// The file only settled once the writes stopped
+		assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond+time.Duration(trigger.Settle))
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		trigger := testTrigger(t)
+		trigger.Path = filepath.Join(trigger.Path, "missing")
+		err := Watch(context.Background(), trigger, make(chan []File))
+		assert.Error(t, err)
+	})
+}
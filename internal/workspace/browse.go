@@ -0,0 +1,31 @@
+package workspace
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Open opens a file or directory of the workspace for reading. name is a
+// slash-separated path relative to the workspace root; paths that would
+// leave the workspace, including through symlinks, are rejected.
+func (w *Workspace) Open(name string) (*os.File, error) {
+	root, err := os.OpenRoot(w.Path)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	defer root.Close()
+
+	return root.Open(CleanPath(name))
+}
+
+// CleanPath normalizes a slash-separated workspace path, returning "." for
+// the workspace root
+func CleanPath(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return filepath.FromSlash(name)
+}
@@ -0,0 +1,461 @@
+/*
+Package workspace manages the per-run working directories of job executions.
+
+Every run gets its own directory under a configurable root:
+
+	<root>/<job id>/<run id>/
+		work/  working directory of the job
+		home/  HOME of the job's processes
+		tmp/   TMPDIR of the job's processes
+		inputs.json  inputs the run was started with, if any
+		stdin        standard input of the run's processes, if any
+
+Once a run finishes its workspace is kept or removed according to the
+configured retention Policy. A janitor periodically re-applies the policy
+and enforces a disk quota across all retained workspaces, removing the
+oldest finished ones first.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("workspace not found")
+	ErrInvalidID     = errors.New("invalid workspace id")
+	ErrInvalidConfig = errors.New("invalid workspace configuration")
+)
+
+// Policy decides which workspaces are retained after a run finishes
+type Policy string
+
+const (
+	// PolicyDelete removes the workspace as soon as the run finishes
+	PolicyDelete Policy = "delete"
+	// PolicyKeepOnFailure keeps workspaces of failed runs only
+	PolicyKeepOnFailure Policy = "keep_on_failure"
+	// PolicyKeepLast keeps the workspaces of the last KeepLast runs per job
+	PolicyKeepLast Policy = "keep_last"
+)
+
+const (
+	DefaultKeepLast        = 5
+	DefaultJanitorInterval = 5 * time.Minute
+)
+
+// Subdirectories created inside every workspace
+const (
+	workDir = "work"
+	homeDir = "home"
+	tmpDir  = "tmp"
+)
+
+// Names of the files holding a run's inputs and standard input
+const (
+	inputsFile = "inputs.json"
+	stdinFile  = "stdin"
+)
+
+// Config configures a Manager
+type Config struct {
+	// Root is the directory holding all workspaces
+	Root string
+	// Policy is the retention policy, PolicyKeepOnFailure by default
+	Policy Policy
+	// KeepLast is the number of workspaces kept per job by PolicyKeepLast
+	KeepLast int
+	// MaxBytes caps the total size of all workspaces; 0 disables the quota
+	MaxBytes int64
+	// JanitorInterval is how often Run sweeps the workspace root
+	JanitorInterval time.Duration
+}
+
+// Workspace is the directory tree of a single run
+type Workspace struct {
+	JobID string
+	RunID string
+	// Path is the workspace root, containing work/, home/ and tmp/
+	Path string
+}
+
+// Dir returns the working directory of the run
+func (w *Workspace) Dir() string {
+	return filepath.Join(w.Path, workDir)
+}
+
+// Env returns the variables pointing HOME and TMPDIR into the workspace
+func (w *Workspace) Env() map[string]string {
+	return map[string]string{
+		"HOME":   filepath.Join(w.Path, homeDir),
+		"TMPDIR": filepath.Join(w.Path, tmpDir),
+	}
+}
+
+// InputsPath returns the path of the file holding the inputs of the run
+func (w *Workspace) InputsPath() string {
+	return filepath.Join(w.Path, inputsFile)
+}
+
+// WriteInputs writes the inputs of the run to the workspace and returns the
+// path of the file
+func (w *Workspace) WriteInputs(data []byte) (string, error) {
+	path := w.InputsPath()
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WriteStdin writes the standard input of the run, read from r, to the
+// workspace and returns the path of the file
+func (w *Workspace) WriteStdin(r io.Reader) (string, error) {
+	path := filepath.Join(w.Path, stdinFile)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// Manager creates, retains and removes workspaces
+type Manager struct {
+	cfg Config
+
+	mu sync.Mutex
+	// active holds the paths of workspaces whose run has not finished yet;
+	// the janitor never touches them
+	active map[string]struct{}
+}
+
+// NewManager creates a workspace manager, creating the root if needed
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.Root == "" {
+		return nil, fmt.Errorf("%w: root is required", ErrInvalidConfig)
+	}
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = PolicyKeepOnFailure
+	case PolicyDelete, PolicyKeepOnFailure, PolicyKeepLast:
+	default:
+		return nil, fmt.Errorf("%w: unknown policy %q", ErrInvalidConfig, cfg.Policy)
+	}
+	if cfg.KeepLast < 0 || cfg.MaxBytes < 0 || cfg.JanitorInterval < 0 {
+		return nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidConfig)
+	}
+	if cfg.KeepLast == 0 {
+		cfg.KeepLast = DefaultKeepLast
+	}
+	if cfg.JanitorInterval == 0 {
+		cfg.JanitorInterval = DefaultJanitorInterval
+	}
+
+	root, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace root: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create workspace root: %w", err)
+	}
+	cfg.Root = root
+
+	return &Manager{cfg: cfg, active: make(map[string]struct{})}, nil
+}
+
+// Root returns the absolute workspace root
+func (m *Manager) Root() string {
+	return m.cfg.Root
+}
+
+// Create lays out a fresh workspace for a run and marks it active. Retries
+// and requeued runs keep their run ID, so a workspace retained from an
+// earlier attempt is cleared first.
+func (m *Manager) Create(jobID, runID string) (*Workspace, error) {
+	ws, err := m.workspace(jobID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the workspace active before creating it so the janitor never
+	// sweeps a half-built directory
+	m.mu.Lock()
+	m.active[ws.Path] = struct{}{}
+	m.mu.Unlock()
+
+	if err := m.layout(ws); err != nil {
+		_ = os.RemoveAll(ws.Path)
+		m.mu.Lock()
+		delete(m.active, ws.Path)
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return ws, nil
+}
+
+// layout replaces anything at the workspace path with empty directories
+func (m *Manager) layout(ws *Workspace) error {
+	if err := os.RemoveAll(ws.Path); err != nil {
+		return err
+	}
+	for _, dir := range []string{workDir, homeDir, tmpDir} {
+		if err := os.MkdirAll(filepath.Join(ws.Path, dir), 0o700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plan returns the workspace a run would get, without creating it
+func (m *Manager) Plan(jobID, runID string) (*Workspace, error) {
+	return m.workspace(jobID, runID)
+}
+
+// Open returns a retained workspace
+func (m *Manager) Open(jobID, runID string) (*Workspace, error) {
+	ws, err := m.workspace(jobID, runID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(ws.Path)
+	if err != nil || !info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return ws, nil
+}
+
+// Release marks the run of a workspace as finished and applies the
+// retention policy
+func (m *Manager) Release(ws *Workspace, succeeded bool) error {
+	m.mu.Lock()
+	delete(m.active, ws.Path)
+	m.mu.Unlock()
+
+	switch {
+	case m.cfg.Policy == PolicyDelete,
+		m.cfg.Policy == PolicyKeepOnFailure && succeeded:
+		return os.RemoveAll(ws.Path)
+	}
+
+	// Record the finish time; retention and the quota evict by age
+	now := time.Now()
+	if err := os.Chtimes(ws.Path, now, now); err != nil {
+		return err
+	}
+	if m.cfg.Policy == PolicyKeepLast {
+		return m.pruneJob(ws.JobID)
+	}
+	return nil
+}
+
+// Run sweeps the workspace root every JanitorInterval until ctx is done
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.JanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Sweep(); err != nil {
+				log.Printf("Workspace janitor: %v", err)
+			}
+		}
+	}
+}
+
+// Sweep re-applies the retention policy to every job and then removes the
+// oldest finished workspaces until the disk quota is met
+func (m *Manager) Sweep() error {
+	entries, err := m.finished()
+	if err != nil {
+		return err
+	}
+
+	if m.cfg.Policy == PolicyKeepLast {
+		perJob := make(map[string]int)
+		kept := entries[:0]
+		// entries are sorted newest first
+		for _, e := range entries {
+			if perJob[e.ws.JobID] < m.cfg.KeepLast {
+				perJob[e.ws.JobID]++
+				kept = append(kept, e)
+				continue
+			}
+			if _, err := m.removeFinished(e.ws.Path); err != nil {
+				return err
+			}
+		}
+		entries = kept
+	}
+
+	if m.cfg.MaxBytes == 0 {
+		return nil
+	}
+	total, err := dirSize(m.cfg.Root)
+	if err != nil {
+		return err
+	}
+	for i := len(entries) - 1; i >= 0 && total > m.cfg.MaxBytes; i-- {
+		removed, err := m.removeFinished(entries[i].ws.Path)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			continue
+		}
+		log.Printf("Workspace janitor: removed %s to stay under quota", entries[i].ws.Path)
+		total -= entries[i].size
+	}
+	return nil
+}
+
+// entry is a finished workspace found on disk
+type entry struct {
+	ws      *Workspace
+	modTime time.Time
+	size    int64
+}
+
+// finished lists all workspaces that are not active, newest first
+func (m *Manager) finished() ([]entry, error) {
+	jobs, err := os.ReadDir(m.cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace root: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var entries []entry
+	for _, job := range jobs {
+		if !job.IsDir() {
+			continue
+		}
+		jobEntries, err := m.finishedForJob(job.Name())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, jobEntries...)
+	}
+	sortNewestFirst(entries)
+	return entries, nil
+}
+
+// finishedForJob lists the finished workspaces of one job. The caller
+// must hold m.mu.
+func (m *Manager) finishedForJob(jobID string) ([]entry, error) {
+	runs, err := os.ReadDir(filepath.Join(m.cfg.Root, jobID))
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	for _, run := range runs {
+		if !run.IsDir() {
+			continue
+		}
+		ws := &Workspace{JobID: jobID, RunID: run.Name(), Path: filepath.Join(m.cfg.Root, jobID, run.Name())}
+		if _, busy := m.active[ws.Path]; busy {
+			continue
+		}
+		info, err := run.Info()
+		if err != nil {
+			return nil, err
+		}
+		size, err := dirSize(ws.Path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{ws: ws, modTime: info.ModTime(), size: size})
+	}
+	return entries, nil
+}
+
+// pruneJob removes all but the newest KeepLast finished workspaces of a job
+func (m *Manager) pruneJob(jobID string) error {
+	m.mu.Lock()
+	entries, err := m.finishedForJob(jobID)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	sortNewestFirst(entries)
+	for i := m.cfg.KeepLast; i < len(entries); i++ {
+		if _, err := m.removeFinished(entries[i].ws.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFinished removes a workspace unless its run became active again
+// since it was listed; it reports whether the workspace was removed. The
+// lock is held across the removal so a concurrent Create for the same run
+// waits for it instead of having its new workspace deleted.
+func (m *Manager) removeFinished(path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, busy := m.active[path]; busy {
+		return false, nil
+	}
+	return true, os.RemoveAll(path)
+}
+
+// workspace builds the workspace for a run after checking that the IDs
+// cannot escape the root
+func (m *Manager) workspace(jobID, runID string) (*Workspace, error) {
+	if !validID(jobID) || !validID(runID) {
+		return nil, ErrInvalidID
+	}
+	return &Workspace{
+		JobID: jobID,
+		RunID: runID,
+		Path:  filepath.Join(m.cfg.Root, jobID, runID),
+	}, nil
+}
+
+func validID(id string) bool {
+	return id != "" && id != "." && id != ".." && filepath.Base(id) == id
+}
+
+func sortNewestFirst(entries []entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].modTime.After(entries[j].modTime)
+	})
+}
+
+// dirSize returns the total size of the regular files below dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files may disappear while a run is writing to its workspace
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,228 @@
+package workspace
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newManager(t *testing.T, cfg Config) *Manager {
+	t.Helper()
+	cfg.Root = t.TempDir()
+	m, err := NewManager(cfg)
+	require.NoError(t, err)
+	return m
+}
+
+// finishRun creates a workspace, writes size bytes into it and releases it
+func finishRun(t *testing.T, m *Manager, jobID, runID string, size int, succeeded bool) *Workspace {
+	t.Helper()
+	ws, err := m.Create(jobID, runID)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir(), "data"), make([]byte, size), 0o644))
+	require.NoError(t, m.Release(ws, succeeded))
+	// Make finish times strictly ordered regardless of timer resolution
+	time.Sleep(10 * time.Millisecond)
+	return ws
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestNewManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "defaults", cfg: Config{Root: t.TempDir()}},
+		{name: "missing root", cfg: Config{}, wantErr: true},
+		{name: "unknown policy", cfg: Config{Root: t.TempDir(), Policy: "forever"}, wantErr: true},
+		{name: "negative quota", cfg: Config{Root: t.TempDir(), MaxBytes: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewManager(tt.cfg)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidConfig)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, PolicyKeepOnFailure, m.cfg.Policy)
+			assert.Equal(t, DefaultKeepLast, m.cfg.KeepLast)
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	m := newManager(t, Config{})
+	ws, err := m.Create("job", "run")
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(m.Root(), "job", "run"), ws.Path)
+	for _, dir := range []string{ws.Dir(), ws.Env()["HOME"], ws.Env()["TMPDIR"]} {
+		assert.DirExists(t, dir)
+		assert.True(t, filepath.IsAbs(dir))
+	}
+
+	path, err := ws.WriteInputs([]byte(`{"region":"eu-west-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(ws.Path, "inputs.json"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"region":"eu-west-1"}`, string(data))
+
+	path, err = ws.WriteStdin(strings.NewReader("yes\n"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(ws.Path, "stdin"), path)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "yes\n", string(data))
+
+	for _, id := range []string{"", ".", "..", "a/b"} {
+		_, err := m.Create("job", id)
+		assert.ErrorIs(t, err, ErrInvalidID, id)
+	}
+
+	// A workspace retained from an earlier attempt is replaced
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir(), "stale"), nil, 0o644))
+	again, err := m.Create("job", "run")
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(again.Dir(), "stale"))
+	assert.NoFileExists(t, filepath.Join(again.Path, "inputs.json"))
+	assert.DirExists(t, again.Dir())
+
+	// A failed create leaves nothing marked active
+	require.NoError(t, os.WriteFile(filepath.Join(m.Root(), "blocked"), nil, 0o644))
+	_, err = m.Create("blocked", "run")
+	assert.Error(t, err)
+	assert.NotContains(t, m.active, filepath.Join(m.Root(), "blocked", "run"))
+}
+
+func TestRelease(t *testing.T) {
+	t.Run("delete", func(t *testing.T) {
+		m := newManager(t, Config{Policy: PolicyDelete})
+		ws := finishRun(t, m, "job", "failed", 1, false)
+		assert.False(t, exists(ws.Path))
+	})
+
+	t.Run("keep on failure", func(t *testing.T) {
+		m := newManager(t, Config{Policy: PolicyKeepOnFailure})
+		ok := finishRun(t, m, "job", "ok", 1, true)
+		failed := finishRun(t, m, "job", "failed", 1, false)
+		assert.False(t, exists(ok.Path))
+		assert.True(t, exists(failed.Path))
+
+		_, err := m.Open("job", "ok")
+		assert.ErrorIs(t, err, ErrNotFound)
+		opened, err := m.Open("job", "failed")
+		require.NoError(t, err)
+		assert.Equal(t, failed.Path, opened.Path)
+	})
+
+	t.Run("keep last", func(t *testing.T) {
+		m := newManager(t, Config{Policy: PolicyKeepLast, KeepLast: 2})
+		first := finishRun(t, m, "job", "1", 1, true)
+		second := finishRun(t, m, "job", "2", 1, false)
+		third := finishRun(t, m, "job", "3", 1, true)
+		other := finishRun(t, m, "other", "1", 1, true)
+
+		assert.False(t, exists(first.Path))
+		assert.True(t, exists(second.Path))
+		assert.True(t, exists(third.Path))
+		assert.True(t, exists(other.Path))
+	})
+}
+
+func TestSweep(t *testing.T) {
+	t.Run("quota removes oldest finished workspaces", func(t *testing.T) {
+		m := newManager(t, Config{Policy: PolicyKeepLast, KeepLast: 10, MaxBytes: 250})
+		oldest := finishRun(t, m, "a", "1", 100, false)
+		middle := finishRun(t, m, "b", "1", 100, false)
+		newest := finishRun(t, m, "a", "2", 100, false)
+		active, err := m.Create("c", "1")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(active.Dir(), "data"), make([]byte, 100), 0o644))
+
+		require.NoError(t, m.Sweep())
+		assert.False(t, exists(oldest.Path))
+		assert.False(t, exists(middle.Path))
+		assert.True(t, exists(newest.Path))
+		assert.True(t, exists(active.Path), "active workspaces are never removed")
+	})
+
+	t.Run("keep last is re-applied", func(t *testing.T) {
+		m := newManager(t, Config{Policy: PolicyKeepLast, KeepLast: 5})
+		first := finishRun(t, m, "job", "1", 1, true)
+		second := finishRun(t, m, "job", "2", 1, true)
+		m.cfg.KeepLast = 1
+
+		require.NoError(t, m.Sweep())
+		assert.False(t, exists(first.Path))
+		assert.True(t, exists(second.Path))
+	})
+
+	t.Run("workspaces recreated after listing are kept", func(t *testing.T) {
+		m := newManager(t, Config{Policy: PolicyKeepLast, KeepLast: 1})
+		finishRun(t, m, "job", "1", 1, false)
+		entries, err := m.finished()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		// A retry of the run creates its workspace again before the
+		// janitor gets to remove it
+		retry, err := m.Create("job", "1")
+		require.NoError(t, err)
+		removed, err := m.removeFinished(entries[0].ws.Path)
+		require.NoError(t, err)
+		assert.False(t, removed)
+		assert.True(t, exists(retry.Dir()))
+	})
+}
+
+func TestOpen(t *testing.T) {
+	m := newManager(t, Config{})
+	ws, err := m.Create("job", "run")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir(), "out.txt"), []byte("hello"), 0o644))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(ws.Dir(), "escape")))
+
+	f, err := ws.Open("/work/../work/out.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	// Cleaned to etc/passwd inside the workspace, which does not exist
+	_, err = ws.Open("../../../etc/passwd")
+	assert.Error(t, err)
+	_, err = ws.Open("work/escape")
+	assert.Error(t, err)
+
+	dir, err := ws.Open("")
+	require.NoError(t, err)
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"work", "home", "tmp"}, names)
+}
+
+func TestPlan(t *testing.T) {
+	m := newManager(t, Config{})
+	ws, err := m.Plan("job-1", "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(m.Root(), "job-1", "run-1"), ws.Path)
+	assert.False(t, exists(ws.Path))
+
+	_, err = m.Plan("job-1", "..")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}